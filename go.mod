@@ -4,8 +4,6 @@ go 1.22
 
 require (
 	github.com/google/go-cmp v0.7.0
-	github.com/in-toto/in-toto-golang v0.9.0
-	github.com/owenrumney/go-sarif v1.1.1
 	gopkg.in/yaml.v3 v3.0.1
 )
 
@@ -14,16 +12,10 @@ require (
 	github.com/kr/pretty v0.3.0 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/rogpeppe/go-internal v1.9.0 // indirect
-	github.com/secure-systems-lab/go-securesystemslib v0.6.0 // indirect
-	github.com/zclconf/go-cty v1.10.0 // indirect
-	golang.org/x/crypto v0.17.0 // indirect
-	golang.org/x/text v0.14.0 // indirect
 	gopkg.in/check.v1 v1.0.0-20201130134442-10cb98267c6c // indirect
 )
 
 require (
 	github.com/package-url/packageurl-go v0.1.3
-	github.com/shibumi/go-pathspec v1.3.0 // indirect
 	github.com/stretchr/testify v1.11.1
-	golang.org/x/sys v0.15.0 // indirect
 )
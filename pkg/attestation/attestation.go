@@ -7,8 +7,13 @@ package attestation
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"sort"
+	"strings"
+
+	"github.com/package-url/packageurl-go"
 
 	intoto "github.com/in-toto/in-toto-golang/in_toto"
 
@@ -22,13 +27,19 @@ type Attestation struct {
 }
 
 func New() *Attestation {
+	return NewWithOptions(nil)
+}
+
+// NewWithOptions returns a new attestation whose predicate is created with
+// the options, see vex.NewWithOptions.
+func NewWithOptions(opts *vex.DocumentOptions) *Attestation {
 	return &Attestation{
 		StatementHeader: intoto.StatementHeader{
 			Type:          intoto.StatementInTotoV01,
 			PredicateType: vex.TypeURI,
 			Subject:       []intoto.Subject{},
 		},
-		Predicate: vex.New(),
+		Predicate: vex.NewWithOptions(opts),
 	}
 }
 
@@ -55,3 +66,95 @@ func (att *Attestation) AddSubjects(subs []intoto.Subject) error {
 	att.Subject = append(att.Subject, subs...)
 	return nil
 }
+
+// intotoAlgorithms maps the OpenVEX hash algorithms to the names of the
+// in-toto digest set. Algorithms without an in-toto name are not used in
+// subjects.
+var intotoAlgorithms = map[vex.Algorithm]string{
+	vex.MD5:        "md5",
+	vex.SHA1:       "sha1",
+	vex.SHA256:     "sha256",
+	vex.SHA384:     "sha384",
+	vex.SHA512:     "sha512",
+	vex.SHA3224:    "sha3_224",
+	vex.SHA3256:    "sha3_256",
+	vex.SHA3384:    "sha3_384",
+	vex.SHA3512:    "sha3_512",
+	vex.BLAKE2S256: "blake2s",
+	vex.BLAKE2B512: "blake2b",
+}
+
+// Wrap returns an attestation with the document as its predicate. The
+// subjects of the attestation are derived from the products of the document
+// statements, see Subjects. Wrap fails if none of the products is identified
+// by a digest as in-toto statements require at least one subject.
+func Wrap(doc *vex.VEX) (*Attestation, error) {
+	subjects := Subjects(doc)
+	if len(subjects) == 0 {
+		return nil, errors.New("no products in the document are identified by a digest")
+	}
+	att := New()
+	att.Predicate = *doc
+	if err := att.AddSubjects(subjects); err != nil {
+		return nil, err
+	}
+	return att, nil
+}
+
+// Subjects returns the in-toto subjects of the products in the document
+// statements. Digests are taken from the product hashes and from the version
+// of OCI purls. Subjects are named after the product ID, or its purl when it
+// has no ID, and are sorted by name. Products without digests are skipped.
+func Subjects(doc *vex.VEX) []intoto.Subject {
+	digests := map[string]map[string]string{}
+	for i := range doc.Statements {
+		for j := range doc.Statements[i].Products {
+			c := &doc.Statements[i].Products[j].Component
+			name := c.ID
+			if name == "" {
+				name = c.Identifiers[vex.PURL]
+			}
+			if name == "" {
+				continue
+			}
+			for algo, h := range componentDigests(c) {
+				if _, ok := digests[name]; !ok {
+					digests[name] = map[string]string{}
+				}
+				digests[name][algo] = h
+			}
+		}
+	}
+
+	ret := []intoto.Subject{}
+	for name, d := range digests {
+		ret = append(ret, intoto.Subject{Name: name, Digest: d})
+	}
+	sort.Slice(ret, func(i, j int) bool { return ret[i].Name < ret[j].Name })
+	return ret
+}
+
+// componentDigests returns the digests identifying the component keyed by
+// their in-toto algorithm name.
+func componentDigests(c *vex.Component) map[string]string {
+	ret := map[string]string{}
+	for algo, h := range c.Hashes {
+		if name, ok := intotoAlgorithms[algo]; ok && h != "" {
+			ret[name] = strings.ToLower(string(h))
+		}
+	}
+
+	for _, id := range []string{c.ID, c.Identifiers[vex.PURL]} {
+		if !strings.HasPrefix(id, "pkg:oci/") {
+			continue
+		}
+		p, err := packageurl.FromString(id)
+		if err != nil {
+			continue
+		}
+		if algo, h, ok := strings.Cut(p.Version, ":"); ok && algo == "sha256" && h != "" {
+			ret["sha256"] = strings.ToLower(h)
+		}
+	}
+	return ret
+}
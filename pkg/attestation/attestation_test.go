@@ -7,6 +7,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"testing"
+	"time"
 
 	intoto "github.com/in-toto/in-toto-golang/in_toto"
 	"github.com/stretchr/testify/require"
@@ -61,3 +62,46 @@ func TestAddSubjects(t *testing.T) {
 	require.Contains(t, err.Error(), "subject test3 has no digests")
 	require.Len(t, att.Subject, 2) // Length should not change
 }
+
+func TestWrap(t *testing.T) {
+	doc := vex.New()
+	doc.Statements = []vex.Statement{
+		{
+			Vulnerability: vex.Vulnerability{Name: "CVE-2023-1234"},
+			Products: []vex.Product{
+				{Component: vex.Component{
+					ID:     "pkg:apk/wolfi/git@2.41.0-r1",
+					Hashes: map[vex.Algorithm]vex.Hash{vex.SHA256: "ABC123", vex.BLAKE3: "ignored"},
+				}},
+				{Component: vex.Component{ID: "pkg:oci/alpine@sha256:def456"}},
+				{Component: vex.Component{ID: "pkg:apk/wolfi/bash@1.0.0"}},
+			},
+			Status: vex.StatusFixed,
+		},
+	}
+
+	att, err := Wrap(&doc)
+	require.NoError(t, err)
+	require.Equal(t, vex.TypeURI, att.PredicateType)
+	require.Equal(t, doc.ID, att.Predicate.ID)
+	require.Equal(t, []intoto.Subject{
+		{Name: "pkg:apk/wolfi/git@2.41.0-r1", Digest: map[string]string{"sha256": "abc123"}},
+		{Name: "pkg:oci/alpine@sha256:def456", Digest: map[string]string{"sha256": "def456"}},
+	}, att.Subject)
+
+	doc.Statements[0].Products = doc.Statements[0].Products[2:]
+	_, err = Wrap(&doc)
+	require.Error(t, err)
+}
+
+func TestNewWithOptions(t *testing.T) {
+	clock := vex.FixedClock(time.Date(2023, 1, 8, 18, 2, 3, 0, time.UTC))
+	var outputs []string
+	for range 2 {
+		var b bytes.Buffer
+		require.NoError(t, NewWithOptions(&vex.DocumentOptions{Clock: clock}).ToJSON(&b))
+		outputs = append(outputs, b.String())
+	}
+	require.Equal(t, outputs[0], outputs[1])
+	require.Contains(t, outputs[0], `"timestamp": "2023-01-08T18:02:03Z"`)
+}
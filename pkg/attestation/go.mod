@@ -0,0 +1,26 @@
+module github.com/openvex/go-vex/pkg/attestation
+
+go 1.22
+
+require (
+	github.com/in-toto/in-toto-golang v0.9.0
+	github.com/openvex/go-vex v0.4.0
+	github.com/package-url/packageurl-go v0.1.3
+	github.com/stretchr/testify v1.11.1
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/secure-systems-lab/go-securesystemslib v0.6.0 // indirect
+	github.com/shibumi/go-pathspec v1.3.0 // indirect
+	golang.org/x/crypto v0.17.0 // indirect
+	golang.org/x/sys v0.15.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+// The attestation module is tagged together with the core module and requires
+// the core release it is tagged with, v0.4.0 being the first one with the
+// core APIs it uses (vex.DocumentOptions). Builds inside the repository use
+// the core packages in the same tree. See "Releasing" in the README.
+replace github.com/openvex/go-vex => ../..
@@ -0,0 +1,99 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package attestation
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	intoto "github.com/in-toto/in-toto-golang/in_toto"
+
+	"github.com/openvex/go-vex/pkg/vex"
+)
+
+// PayloadType is the DSSE payload type of in-toto statements.
+const PayloadType = "application/vnd.in-toto+json"
+
+// ErrNotVEX is returned when parsing an attestation whose predicate is not
+// an OpenVEX document.
+var ErrNotVEX = errors.New("attestation predicate is not an OpenVEX document")
+
+// envelope is a DSSE envelope. Signatures are not verified when parsing
+// attestations so they are not decoded.
+type envelope struct {
+	PayloadType string `json:"payloadType"`
+	Payload     string `json:"payload"`
+}
+
+// rawStatement is an in-toto statement with its predicate kept as JSON so it
+// can be parsed by the vex package, upgrading older documents.
+type rawStatement struct {
+	intoto.StatementHeader
+	Predicate json.RawMessage `json:"predicate"`
+}
+
+// Parse reads an attestation from a DSSE envelope or from a bare in-toto
+// statement. The predicate is parsed as an OpenVEX document, Parse fails
+// with ErrNotVEX if the predicate type is not OpenVEX. Envelope signatures
+// are not verified.
+func Parse(data []byte) (*Attestation, error) {
+	env := &envelope{}
+	if err := json.Unmarshal(data, env); err != nil {
+		return nil, fmt.Errorf("decoding attestation: %w", err)
+	}
+
+	if env.PayloadType != "" {
+		if env.PayloadType != PayloadType {
+			return nil, fmt.Errorf("unsupported envelope payload type %q", env.PayloadType)
+		}
+		payload, err := base64.StdEncoding.DecodeString(env.Payload)
+		if err != nil {
+			return nil, fmt.Errorf("decoding envelope payload: %w", err)
+		}
+		data = payload
+	}
+
+	stmt := &rawStatement{}
+	if err := json.Unmarshal(data, stmt); err != nil {
+		return nil, fmt.Errorf("decoding in-toto statement: %w", err)
+	}
+	if !strings.HasPrefix(stmt.PredicateType, vex.TypeURI) {
+		return nil, fmt.Errorf("predicate type %q: %w", stmt.PredicateType, ErrNotVEX)
+	}
+
+	doc, err := vex.Parse(stmt.Predicate)
+	if err != nil {
+		return nil, fmt.Errorf("parsing predicate: %w", err)
+	}
+	return &Attestation{StatementHeader: stmt.StatementHeader, Predicate: *doc}, nil
+}
+
+// ReadDocuments extracts the OpenVEX documents from a stream of attestations,
+// such as the envelopes downloaded from a registry, one after the other.
+// Attestations with other predicate types are skipped.
+func ReadDocuments(r io.Reader) ([]*vex.VEX, error) {
+	ret := []*vex.VEX{}
+	dec := json.NewDecoder(r)
+	for i := 0; ; i++ {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			if errors.Is(err, io.EOF) {
+				return ret, nil
+			}
+			return nil, fmt.Errorf("reading attestation #%d: %w", i, err)
+		}
+		att, err := Parse(raw)
+		if errors.Is(err, ErrNotVEX) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parsing attestation #%d: %w", i, err)
+		}
+		ret = append(ret, &att.Predicate)
+	}
+}
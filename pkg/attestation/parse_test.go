@@ -0,0 +1,79 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package attestation
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/openvex/go-vex/pkg/vex"
+)
+
+func TestParse(t *testing.T) {
+	att := New()
+	att.Predicate.ID = "https://openvex.dev/docs/example"
+	att.Predicate.Author = "Chainguard"
+	var b bytes.Buffer
+	require.NoError(t, att.ToJSON(&b))
+	statement := b.Bytes()
+
+	envelope := func(payloadType string, payload []byte) []byte {
+		return []byte(fmt.Sprintf(
+			`{"payloadType": %q, "payload": %q, "signatures": [{"keyid": "", "sig": "c2ln"}]}`,
+			payloadType, base64.StdEncoding.EncodeToString(payload),
+		))
+	}
+	other, err := json.Marshal(map[string]any{
+		"_type": "https://in-toto.io/Statement/v0.1", "predicateType": "https://slsa.dev/provenance/v1", "predicate": map[string]any{},
+	})
+	require.NoError(t, err)
+
+	for m, tc := range map[string]struct {
+		data       []byte
+		shouldErr  bool
+		errorIsNot bool
+	}{
+		"statement":         {data: statement},
+		"envelope":          {data: envelope(PayloadType, statement)},
+		"other payload":     {data: envelope("application/json", statement), shouldErr: true},
+		"other predicate":   {data: envelope(PayloadType, other), shouldErr: true, errorIsNot: true},
+		"invalid json":      {data: []byte(`{"payloadType"`), shouldErr: true},
+		"invalid predicate": {data: []byte(`{"predicateType": "https://openvex.dev/ns", "predicate": "doc"}`), shouldErr: true},
+	} {
+		parsed, err := Parse(tc.data)
+		if tc.shouldErr {
+			require.Error(t, err, m)
+			if tc.errorIsNot {
+				require.ErrorIs(t, err, ErrNotVEX, m)
+			}
+			continue
+		}
+		require.NoError(t, err, m)
+		require.Equal(t, vex.TypeURI, parsed.PredicateType, m)
+		require.Equal(t, "Chainguard", parsed.Predicate.Author, m)
+		require.Equal(t, att.Predicate.ID, parsed.Predicate.ID, m)
+	}
+}
+
+func TestReadDocuments(t *testing.T) {
+	att := New()
+	att.Predicate.Author = "Chainguard"
+	var b bytes.Buffer
+	require.NoError(t, att.ToJSON(&b))
+	b.WriteString(`{"_type": "https://in-toto.io/Statement/v0.1", "predicateType": "https://slsa.dev/provenance/v1", "predicate": {}}` + "\n")
+	require.NoError(t, att.ToJSON(&b))
+
+	docs, err := ReadDocuments(&b)
+	require.NoError(t, err)
+	require.Len(t, docs, 2)
+	require.Equal(t, "Chainguard", docs[1].Author)
+
+	_, err = ReadDocuments(bytes.NewReader([]byte(`{"predicateType": "https://openvex.dev/ns", "predicate": "doc"}`)))
+	require.Error(t, err)
+}
@@ -0,0 +1,201 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package convert implements a pipeline to convert feeds of advisories in
+// mixed formats (OpenVEX, CSAF, CycloneDX) into a single OpenVEX corpus.
+package convert
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/openvex/go-vex/pkg/vex"
+)
+
+// Options control the behavior of the conversion runner.
+type Options struct {
+	// Workers is the number of documents converted concurrently.
+	Workers int
+
+	// QuarantineDir is an optional directory where documents that fail to
+	// convert are copied along with a file describing the error.
+	QuarantineDir string
+
+	// MergeOptions are the options used to merge the converted documents.
+	MergeOptions vex.MergeOptions
+}
+
+// DefaultOptions are the options used by New.
+var DefaultOptions = Options{
+	Workers: 4,
+}
+
+// Runner converts advisories to OpenVEX in parallel.
+type Runner struct {
+	Options Options
+}
+
+// New returns a runner with the default options.
+func New() *Runner {
+	return &Runner{Options: DefaultOptions}
+}
+
+// Failure records a document that could not be converted.
+type Failure struct {
+	Path string
+	Err  error
+}
+
+// Result captures the outcome of a conversion run.
+type Result struct {
+	// Documents maps the paths of the converted files to their OpenVEX
+	// documents.
+	Documents map[string]*vex.VEX
+
+	// Failures lists the files that failed to convert, sorted by path.
+	Failures []Failure
+
+	// Merged is the consolidated corpus of all converted documents. It is
+	// nil if no document was converted.
+	Merged *vex.VEX
+}
+
+// RunDirectory converts all the JSON files found in the directory tree at
+// root.
+func (r *Runner) RunDirectory(ctx context.Context, root string) (*Result, error) {
+	paths := []string{}
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && filepath.Ext(path) == ".json" {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("walking directory: %w", err)
+	}
+	return r.Run(ctx, paths)
+}
+
+// Run converts the documents at paths. Documents that fail to convert are
+// quarantined and reported in the result without aborting the run. The
+// converted documents are merged into a single document.
+func (r *Runner) Run(ctx context.Context, paths []string) (*Result, error) {
+	workers := r.Options.Workers
+	if workers < 1 {
+		workers = 1
+	}
+
+	result := &Result{
+		Documents: map[string]*vex.VEX{},
+		Failures:  []Failure{},
+	}
+
+	var mtx sync.Mutex
+	var wg sync.WaitGroup
+	ch := make(chan string)
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range ch {
+				doc, err := vex.Open(path)
+				mtx.Lock()
+				if err != nil {
+					result.Failures = append(result.Failures, Failure{Path: path, Err: err})
+				} else {
+					result.Documents[path] = doc
+				}
+				mtx.Unlock()
+			}
+		}()
+	}
+
+	for _, path := range paths {
+		if ctx.Err() != nil {
+			break
+		}
+		ch <- path
+	}
+	close(ch)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("converting documents: %w", err)
+	}
+
+	sort.Slice(result.Failures, func(i, j int) bool {
+		return result.Failures[i].Path < result.Failures[j].Path
+	})
+
+	if r.Options.QuarantineDir != "" {
+		if err := quarantine(r.Options.QuarantineDir, result.Failures); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(result.Documents) == 0 {
+		return result, nil
+	}
+
+	// Merge in a stable order to get reproducible output
+	converted := make([]string, 0, len(result.Documents))
+	for path := range result.Documents {
+		converted = append(converted, path)
+	}
+	sort.Strings(converted)
+	docs := make([]*vex.VEX, 0, len(converted))
+	for _, path := range converted {
+		docs = append(docs, result.Documents[path])
+	}
+
+	mergeOpts := r.Options.MergeOptions
+	merged, err := vex.MergeDocumentsWithOptions(&mergeOpts, docs)
+	if err != nil {
+		return nil, fmt.Errorf("merging converted documents: %w", err)
+	}
+	result.Merged = merged
+	return result, nil
+}
+
+// quarantine copies the failed documents to dir, writing the conversion
+// error next to each one in a file with the .error extension.
+func quarantine(dir string, failures []Failure) error {
+	if len(failures) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("creating quarantine directory: %w", err)
+	}
+
+	for _, f := range failures {
+		name := filepath.Base(f.Path)
+		for i := 1; ; i++ {
+			if _, err := os.Stat(filepath.Join(dir, name)); errors.Is(err, fs.ErrNotExist) {
+				break
+			}
+			name = fmt.Sprintf("%d-%s", i, filepath.Base(f.Path))
+		}
+
+		// The file may be unreadable, the error file records why
+		if data, err := os.ReadFile(f.Path); err == nil { //nolint:gosec // This is supposed to open user-specified paths
+			if err := os.WriteFile(filepath.Join(dir, name), data, 0o600); err != nil {
+				return fmt.Errorf("quarantining %s: %w", f.Path, err)
+			}
+		}
+
+		msg := fmt.Sprintf("source: %s\nerror: %s\n", f.Path, f.Err)
+		if err := os.WriteFile(filepath.Join(dir, name+".error"), []byte(msg), 0o600); err != nil {
+			return fmt.Errorf("writing quarantine error for %s: %w", f.Path, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package convert
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunDirectory(t *testing.T) {
+	feed := t.TempDir()
+	for src, dst := range map[string]string{
+		"../vex/testdata/v020-1.vex.json": "openvex.json",
+		"../vex/testdata/v0.0.1.json":     "legacy/openvex-v001.json",
+		"../vex/testdata/csaf.json":       "csaf/advisory.json",
+		"../vex/testdata/cyclonedx.json":  "cyclonedx.json",
+	} {
+		data, err := os.ReadFile(src)
+		require.NoError(t, err)
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(feed, dst)), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(feed, dst), data, 0o600))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(feed, "broken.json"), []byte(`{"@context": `), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(feed, "csaf", "broken.json"), []byte(`{"other": "format"}`), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(feed, "README.md"), []byte(`# feed`), 0o600))
+
+	quarantineDir := filepath.Join(t.TempDir(), "quarantine")
+	runner := New()
+	runner.Options.QuarantineDir = quarantineDir
+	runner.Options.MergeOptions.DocumentID = "https://example.com/vex/corpus"
+
+	result, err := runner.RunDirectory(context.Background(), feed)
+	require.NoError(t, err)
+	require.Len(t, result.Documents, 4)
+	require.Len(t, result.Failures, 2)
+	require.Equal(t, filepath.Join(feed, "broken.json"), result.Failures[0].Path)
+	require.Error(t, result.Failures[0].Err)
+
+	require.NotNil(t, result.Merged)
+	require.Equal(t, "https://example.com/vex/corpus", result.Merged.ID)
+	require.NotEmpty(t, result.Merged.Statements)
+
+	// Both failed files are named broken.json, the second gets a prefix
+	entries, err := os.ReadDir(quarantineDir)
+	require.NoError(t, err)
+	names := []string{}
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	require.ElementsMatch(t, []string{"broken.json", "broken.json.error", "1-broken.json", "1-broken.json.error"}, names)
+
+	msg, err := os.ReadFile(filepath.Join(quarantineDir, "broken.json.error"))
+	require.NoError(t, err)
+	require.Contains(t, string(msg), "error:")
+}
+
+func TestRunNoDocuments(t *testing.T) {
+	result, err := New().Run(context.Background(), []string{"testdata/non-existent.json"})
+	require.NoError(t, err)
+	require.Nil(t, result.Merged)
+	require.Len(t, result.Failures, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = New().Run(ctx, []string{"testdata/non-existent.json"})
+	require.Error(t, err)
+}
@@ -6,10 +6,21 @@ package csaf
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"time"
 )
 
+const (
+	// Version is the version of the CSAF specification of the documents
+	// written by this package.
+	Version = "2.0"
+
+	// CategoryVEX is the document category of documents conforming to the
+	// CSAF VEX profile.
+	CategoryVEX = "csaf_vex"
+)
+
 // CSAF is a Common Security Advisory Framework Version 2.0 document.
 //
 // https://docs.oasis-open.org/csaf/csaf/v2.0/os/csaf-v2.0-os.html
@@ -31,36 +42,81 @@ type CSAF struct {
 	Vulnerabilities []Vulnerability `json:"vulnerabilities"`
 
 	// Notes holds notes associated with the whole document.
-	// https://docs.oasis-open.org/csaf/csaf/v2.0/os/csaf-v2.0-os.html#3217-document-property---notes
-	Notes []Note `json:"notes"`
+	//
+	// Deprecated: CSAF documents have no notes at the top level, the notes of
+	// the document are in DocumentMetadata.Notes.
+	Notes []Note `json:"notes,omitempty"`
 }
 
 // DocumentMetadata contains metadata about the CSAF document itself.
 //
 // https://docs.oasis-open.org/csaf/csaf/v2.0/os/csaf-v2.0-os.html#321-document-property
 type DocumentMetadata struct {
-	Title      string      `json:"title"`
-	Tracking   Tracking    `json:"tracking"`
-	References []Reference `json:"references"`
-	Publisher  Publisher   `json:"publisher"`
+	Category        string           `json:"category,omitempty"`
+	CSAFVersion     string           `json:"csaf_version,omitempty"`
+	Title           string           `json:"title"`
+	Lang            string           `json:"lang,omitempty"`
+	Notes           []Note           `json:"notes,omitempty"`
+	Tracking        Tracking         `json:"tracking"`
+	References      []Reference      `json:"references,omitempty"`
+	Publisher       Publisher        `json:"publisher"`
+	Acknowledgments []Acknowledgment `json:"acknowledgments,omitempty"`
 }
 
 // Document references holds a list of references associated with the whole document.
 //
 // https://docs.oasis-open.org/csaf/csaf/v2.0/os/csaf-v2.0-os.html#3219-document-property---references
 type Reference struct {
-	Category string `json:"category"`
+	Category string `json:"category,omitempty"`
 	Summary  string `json:"summary"`
 	URL      string `json:"url"`
 }
 
+// Acknowledgment recognizes the parties that contributed to the document or
+// to the handling of a vulnerability.
+//
+// https://docs.oasis-open.org/csaf/csaf/v2.0/os/csaf-v2.0-os.html#3211-acknowledgments-type
+type Acknowledgment struct {
+	Names        []string `json:"names,omitempty"`
+	Organization string   `json:"organization,omitempty"`
+	Summary      string   `json:"summary,omitempty"`
+	URLs         []string `json:"urls,omitempty"`
+}
+
 // Tracking contains information used to track the CSAF document through its lifecycle.
 //
 // https://docs.oasis-open.org/csaf/csaf/v2.0/os/csaf-v2.0-os.html#32112-document-property---tracking
 type Tracking struct {
-	ID                 string    `json:"id"`
-	CurrentReleaseDate time.Time `json:"current_release_date"`
-	InitialReleaseDate time.Time `json:"initial_release_date"`
+	ID                 string     `json:"id"`
+	CurrentReleaseDate time.Time  `json:"current_release_date"`
+	Generator          *Generator `json:"generator,omitempty"`
+	InitialReleaseDate time.Time  `json:"initial_release_date"`
+	RevisionHistory    []Revision `json:"revision_history,omitempty"`
+	Status             string     `json:"status,omitempty"`
+	Version            string     `json:"version,omitempty"`
+}
+
+// Generator describes the tool that generated the CSAF document.
+//
+// https://docs.oasis-open.org/csaf/csaf/v2.0/os/csaf-v2.0-os.html#321123-document-property---tracking---generator
+type Generator struct {
+	Date   *time.Time `json:"date,omitempty"`
+	Engine Engine     `json:"engine"`
+}
+
+// Engine is the name and version of the generator engine.
+type Engine struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+// Revision is an entry in the revision history of the document.
+//
+// https://docs.oasis-open.org/csaf/csaf/v2.0/os/csaf-v2.0-os.html#321126-document-property---tracking---revision-history
+type Revision struct {
+	Date    time.Time `json:"date"`
+	Number  string    `json:"number"`
+	Summary string    `json:"summary"`
 }
 
 // Publisher provides information on the publishing entity.
@@ -68,8 +124,8 @@ type Tracking struct {
 // https://docs.oasis-open.org/csaf/csaf/v2.0/os/csaf-v2.0-os.html#3218-document-property---publisher
 type Publisher struct {
 	Category         string `json:"category"`
-	ContactDetails   string `json:"contact_details"`
-	IssuingAuthority string `json:"issuing_authority"`
+	ContactDetails   string `json:"contact_details,omitempty"`
+	IssuingAuthority string `json:"issuing_authority,omitempty"`
 	Name             string `json:"name"`
 	Namespace        string `json:"namespace"`
 }
@@ -81,55 +137,61 @@ type Vulnerability struct {
 	// MITRE standard Common Vulnerabilities and Exposures (CVE) tracking number for the vulnerability.
 	//
 	// https://docs.oasis-open.org/csaf/csaf/v2.0/os/csaf-v2.0-os.html#3232-vulnerabilities-property---cve
-	CVE string `json:"cve"`
+	CVE string `json:"cve,omitempty"`
 
 	// List of IDs represents a list of unique labels or tracking IDs for the vulnerability (if such information exists).
 	//
 	// https://docs.oasis-open.org/csaf/csaf/v2.0/os/csaf-v2.0-os.html#3236-vulnerabilities-property---ids
-	IDs []TrackingID `json:"ids"`
+	IDs []TrackingID `json:"ids,omitempty"`
 
 	// Provide details on the status of the referenced product related to the vulnerability.
 	//
 	// https://docs.oasis-open.org/csaf/csaf/v2.0/os/csaf-v2.0-os.html#3239-vulnerabilities-property---product-status
-	ProductStatus map[string][]string `json:"product_status"`
+	ProductStatus map[string][]string `json:"product_status,omitempty"`
 
 	// Provide details of threats associated with a vulnerability.
 	//
 	// https://docs.oasis-open.org/csaf/csaf/v2.0/os/csaf-v2.0-os.html#32314-vulnerabilities-property---threats
-	Threats []ThreatData `json:"threats"`
+	Threats []ThreatData `json:"threats,omitempty"`
 
 	// Provide details of remediations associated with a Vulnerability
 	//
 	// https://docs.oasis-open.org/csaf/csaf/v2.0/os/csaf-v2.0-os.html#32312-vulnerabilities-property---remediations
-	Remediations []RemediationData `json:"remediations"`
+	Remediations []RemediationData `json:"remediations,omitempty"`
 
 	// Machine readable flags for products related to vulnerability
 	//
 	// https://docs.oasis-open.org/csaf/csaf/v2.0/os/csaf-v2.0-os.html#3235-vulnerabilities-property---flags
-	Flags []Flag `json:"flags"`
+	Flags []Flag `json:"flags,omitempty"`
 
 	// Vulnerability references holds a list of references associated with this vulnerability item.
 	//
 	// https://docs.oasis-open.org/csaf/csaf/v2.0/os/csaf-v2.0-os.html#32310-vulnerabilities-property---references
-	References []Reference `json:"references"`
+	References []Reference `json:"references,omitempty"`
 
-	ReleaseDate time.Time `json:"release_date"`
+	// Acknowledgments recognize the parties that contributed to the handling
+	// of the vulnerability.
+	//
+	// https://docs.oasis-open.org/csaf/csaf/v2.0/os/csaf-v2.0-os.html#3231-vulnerabilities-property---acknowledgments
+	Acknowledgments []Acknowledgment `json:"acknowledgments,omitempty"`
+
+	ReleaseDate time.Time `json:"release_date,omitempty"`
 
 	// Notes holds notes associated with the Vulnerability object.
 	// https://docs.oasis-open.org/csaf/csaf/v2.0/os/csaf-v2.0-os.html#3238-vulnerabilities-property---notes
-	Notes []Note `json:"notes"`
+	Notes []Note `json:"notes,omitempty"`
 
 	// Scores holds the scores associated with the Vulnerability object.
 	// https://docs.oasis-open.org/csaf/csaf/v2.0/os/csaf-v2.0-os.html#32313-vulnerabilities-property---scores
-	// Currently only CVSS v3 is supported.
-	Scores []Score `json:"scores"`
+	// CVSS v2 and v3 scores are supported.
+	Scores []Score `json:"scores,omitempty"`
 }
 
 type Note struct {
 	Category string `json:"category"`
 	Text     string `json:"text"`
-	Title    string `json:"title"`
-	Audience string `json:"audience"`
+	Title    string `json:"title,omitempty"`
+	Audience string `json:"audience,omitempty"`
 }
 
 // Every ID item with the two mandatory properties System Name (system_name) and Text (text) contains a single unique label or tracking ID for the vulnerability.
@@ -146,7 +208,7 @@ type TrackingID struct {
 type ThreatData struct {
 	Category   string   `json:"category"`
 	Details    string   `json:"details"`
-	ProductIDs []string `json:"product_ids"`
+	ProductIDs []string `json:"product_ids,omitempty"`
 }
 
 // RemediationData contains information about how to remediate a vulnerability for a set of products.
@@ -156,11 +218,11 @@ type RemediationData struct {
 	Category     string      `json:"category"`
 	Date         time.Time   `json:"date"`
 	Details      string      `json:"details"`
-	Entitlements []string    `json:"entitlements"`
-	GroupIDs     []string    `json:"group_ids"`
-	ProductIDs   []string    `json:"product_ids"`
+	Entitlements []string    `json:"entitlements,omitempty"`
+	GroupIDs     []string    `json:"group_ids,omitempty"`
+	ProductIDs   []string    `json:"product_ids,omitempty"`
 	Restart      RestartData `json:"restart_required"`
-	URL          string      `json:"url"`
+	URL          string      `json:"url,omitempty"`
 }
 
 // Remediation instructions for restart of affected software.
@@ -168,7 +230,7 @@ type RemediationData struct {
 // https://docs.oasis-open.org/csaf/csaf/v2.0/os/csaf-v2.0-os.html#323127-vulnerabilities-property---remediations---restart-required
 type RestartData struct {
 	Category string `json:"category"`
-	Details  string `json:"details"`
+	Details  string `json:"details,omitempty"`
 }
 
 // Machine readable flags for products related to the Vulnerability
@@ -177,8 +239,8 @@ type RestartData struct {
 type Flag struct {
 	Label      string    `json:"label"`
 	Date       time.Time `json:"date"`
-	GroupIDs   []string  `json:"group_ids"`
-	ProductIDs []string  `json:"product_ids"`
+	GroupIDs   []string  `json:"group_ids,omitempty"`
+	ProductIDs []string  `json:"product_ids,omitempty"`
 }
 
 // ProductBranch is a recursive struct that contains information about a product and
@@ -186,11 +248,11 @@ type Flag struct {
 //
 // https://docs.oasis-open.org/csaf/csaf/v2.0/os/csaf-v2.0-os.html#3221-product-tree-property---branches
 type ProductBranch struct {
-	Category      string          `json:"category"`
-	Name          string          `json:"name"`
-	Branches      []ProductBranch `json:"branches"`
+	Category      string          `json:"category,omitempty"`
+	Name          string          `json:"name,omitempty"`
+	Branches      []ProductBranch `json:"branches,omitempty"`
 	Product       Product         `json:"product,omitempty"`
-	Relationships []Relationship  `json:"relationships"`
+	Relationships []Relationship  `json:"relationships,omitempty"`
 }
 
 // Relationship establishes a link between two existing full_product_name_t elements, allowing
@@ -210,7 +272,7 @@ type Relationship struct {
 type Product struct {
 	Name                 string            `json:"name"`
 	ID                   string            `json:"product_id"`
-	IdentificationHelper map[string]string `json:"product_identification_helper"`
+	IdentificationHelper map[string]string `json:"product_identification_helper,omitempty"`
 }
 
 // Score contains score information tied to the listed products.
@@ -225,39 +287,41 @@ type Score struct {
 // CVSSV2 describes CVSSv2.0 specification as defined here:
 //   - https://www.first.org/cvss/cvss-v2.0.json
 type CVSSV2 struct {
-	AccessVector               string  `json:"accessVector"`
-	AccessComplexity           string  `json:"accessComplexity"`
-	Authentication             string  `json:"authentication"`
-	ConfidentialityImpact      string  `json:"confidentialityImpact"`
-	IntegrityImpact            string  `json:"integrityImpact"`
-	AvailabilityImpact         string  `json:"availabilityImpact"`
+	Version                    string  `json:"version"`
+	VectorString               string  `json:"vectorString"`
+	AccessVector               string  `json:"accessVector,omitempty"`
+	AccessComplexity           string  `json:"accessComplexity,omitempty"`
+	Authentication             string  `json:"authentication,omitempty"`
+	ConfidentialityImpact      string  `json:"confidentialityImpact,omitempty"`
+	IntegrityImpact            string  `json:"integrityImpact,omitempty"`
+	AvailabilityImpact         string  `json:"availabilityImpact,omitempty"`
 	BaseScore                  float64 `json:"baseScore"`
-	Exploitability             string  `json:"exploitability"`
-	RemediationLevel           string  `json:"remediationLevel"`
-	ReportConfidence           string  `json:"reportConfidence"`
-	TemporalScore              float64 `json:"temporalScore"`
-	CollateralDamagePotential  string  `json:"collateralDamagePotential"`
-	TargetDistribution         string  `json:"targetDistribution"`
-	ConfidentialityRequirement string  `json:"confidentialityRequirement"`
-	IntegrityRequirement       string  `json:"integrityRequirement"`
-	AvailabilityRequirement    string  `json:"availabilityRequirement"`
-	EnvironmentalScore         float64 `json:"environmentalScore"`
+	Exploitability             string  `json:"exploitability,omitempty"`
+	RemediationLevel           string  `json:"remediationLevel,omitempty"`
+	ReportConfidence           string  `json:"reportConfidence,omitempty"`
+	TemporalScore              float64 `json:"temporalScore,omitempty"`
+	CollateralDamagePotential  string  `json:"collateralDamagePotential,omitempty"`
+	TargetDistribution         string  `json:"targetDistribution,omitempty"`
+	ConfidentialityRequirement string  `json:"confidentialityRequirement,omitempty"`
+	IntegrityRequirement       string  `json:"integrityRequirement,omitempty"`
+	AvailabilityRequirement    string  `json:"availabilityRequirement,omitempty"`
+	EnvironmentalScore         float64 `json:"environmentalScore,omitempty"`
 }
 
 // CVSSV3 describes both the CVSSv3.0 and CVSSv3.1 specifications as defined here:
 //   - https://www.first.org/cvss/cvss-v3.0.json
 //   - https://www.first.org/cvss/cvss-v3.1.json
 type CVSSV3 struct {
-	AttackComplexity      string  `json:"attackComplexity"`
-	AttackVector          string  `json:"attackVector"`
-	AvailabilityImpact    string  `json:"availabilityImpact"`
+	AttackComplexity      string  `json:"attackComplexity,omitempty"`
+	AttackVector          string  `json:"attackVector,omitempty"`
+	AvailabilityImpact    string  `json:"availabilityImpact,omitempty"`
 	BaseScore             float64 `json:"baseScore"`
 	BaseSeverity          string  `json:"baseSeverity"`
-	ConfidentialityImpact string  `json:"confidentialityImpact"`
-	IntegrityImpact       string  `json:"integrityImpact"`
-	PrivilegesRequired    string  `json:"privilegesRequired"`
-	Scope                 string  `json:"scope"`
-	UserInteraction       string  `json:"userInteraction"`
+	ConfidentialityImpact string  `json:"confidentialityImpact,omitempty"`
+	IntegrityImpact       string  `json:"integrityImpact,omitempty"`
+	PrivilegesRequired    string  `json:"privilegesRequired,omitempty"`
+	Scope                 string  `json:"scope,omitempty"`
+	UserInteraction       string  `json:"userInteraction,omitempty"`
 	VectorString          string  `json:"vectorString"`
 	Version               string  `json:"version"`
 }
@@ -280,6 +344,90 @@ func Open(path string) (*CSAF, error) {
 	return csafDoc, nil
 }
 
+// Parse parses the data of a CSAF document.
+func Parse(data []byte) (*CSAF, error) {
+	csafDoc := &CSAF{}
+	if err := json.Unmarshal(data, csafDoc); err != nil {
+		return nil, fmt.Errorf("csaf: failed to decode document: %w", err)
+	}
+
+	return csafDoc, nil
+}
+
+// ToJSON serializes the CSAF document to JSON and writes it to the passed
+// writer.
+func (csafDoc *CSAF) ToJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+
+	if err := enc.Encode(csafDoc); err != nil {
+		return fmt.Errorf("csaf: encoding document: %w", err)
+	}
+	return nil
+}
+
+// MarshalJSON overrides the branch marshaling function to omit the product
+// when the branch has none, as is the case for the product tree root and
+// intermediate branches.
+func (branch *ProductBranch) MarshalJSON() ([]byte, error) {
+	type alias ProductBranch
+	var product *Product
+	if branch.Product.ID != "" || branch.Product.Name != "" || len(branch.Product.IdentificationHelper) > 0 {
+		product = &branch.Product
+	}
+
+	return json.Marshal(&struct {
+		*alias
+		Product *Product `json:"product,omitempty"`
+	}{
+		alias:   (*alias)(branch),
+		Product: product,
+	})
+}
+
+// MarshalJSON overrides the remediation marshaling function to omit the
+// restart data when it is not set.
+func (rd *RemediationData) MarshalJSON() ([]byte, error) {
+	type alias RemediationData
+	var restart *RestartData
+	if rd.Restart.Category != "" {
+		restart = &rd.Restart
+	}
+
+	return json.Marshal(&struct {
+		*alias
+		Restart *RestartData `json:"restart_required,omitempty"`
+	}{
+		alias:   (*alias)(rd),
+		Restart: restart,
+	})
+}
+
+// MarshalJSON overrides the score marshaling function to omit the CVSS
+// versions the score does not have.
+func (s *Score) MarshalJSON() ([]byte, error) {
+	type alias Score
+	var v2 *CVSSV2
+	var v3 *CVSSV3
+	if s.CVSSV2 != (CVSSV2{}) {
+		v2 = &s.CVSSV2
+	}
+	if s.CVSSV3 != (CVSSV3{}) {
+		v3 = &s.CVSSV3
+	}
+
+	return json.Marshal(&struct {
+		*alias
+		CVSSV2 *CVSSV2 `json:"cvss_v2,omitempty"`
+		CVSSV3 *CVSSV3 `json:"cvss_v3,omitempty"`
+	}{
+		alias:  (*alias)(s),
+		CVSSV2: v2,
+		CVSSV3: v3,
+	})
+}
+
 // FirstProductName returns the first product name in the product tree
 // or an empty string if no product name is found.
 func (csafDoc *CSAF) FirstProductName() string {
@@ -330,7 +478,8 @@ func (branch *ProductBranch) FindFirstProductName() string {
 	return ""
 }
 
-// FindProductIdentifier recursively searches for the first product identifier in the tree
+// FindProductIdentifier recursively searches for the first product identifier in the tree.
+// Use FindProductsByIdentifier to get all the products with the identifier.
 func (branch *ProductBranch) FindProductIdentifier(helperType, helperValue string) *Product {
 	if len(branch.Product.IdentificationHelper) != 0 {
 		for k := range branch.Product.IdentificationHelper {
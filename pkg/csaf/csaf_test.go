@@ -4,6 +4,7 @@
 package csaf
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -33,6 +34,10 @@ func TestOpenRHAdvisory(t *testing.T) {
 
 	require.Equal(t, "https://bugzilla.redhat.com/show_bug.cgi?id=1794290", doc.Vulnerabilities[0].IDs[0].Text)
 
+	// Acknowledgments and references
+	require.Equal(t, []string{"Peter Turschmid", "Raphael Norwitz", "Felipe Franciosi"}, doc.Vulnerabilities[0].Acknowledgments[0].Names)
+	require.Equal(t, "self", doc.Document.References[0].Category)
+
 	// Publisher
 	require.Equal(t, "vendor", doc.Document.Publisher.Category)
 	require.Equal(t, "https://access.redhat.com/security/team/contact/", doc.Document.Publisher.ContactDetails)
@@ -94,3 +99,25 @@ func TestAddEmptyProduct(t *testing.T) {
 
 	require.Empty(t, list)
 }
+
+func TestParse(t *testing.T) {
+	doc, err := Parse([]byte(`{"document": {"title": "Example", "tracking": {"id": "2022-EVD-UC-01-NA-001"}}}`))
+	require.NoError(t, err)
+	require.Equal(t, "Example", doc.Document.Title)
+	require.Equal(t, "2022-EVD-UC-01-NA-001", doc.Document.Tracking.ID)
+
+	_, err = Parse([]byte(`not json`))
+	require.Error(t, err)
+}
+
+func TestScoreMarshalJSON(t *testing.T) {
+	data, err := json.Marshal([]Score{{
+		CVSSV3:     CVSSV3{Version: "3.1", VectorString: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:H", BaseScore: 7.5},
+		ProductIDs: []string{"CSAFPID-0001"},
+	}})
+	require.NoError(t, err)
+	scores := []map[string]any{}
+	require.NoError(t, json.Unmarshal(data, &scores))
+	require.Contains(t, scores[0], "cvss_v3")
+	require.NotContains(t, scores[0], "cvss_v2")
+}
@@ -0,0 +1,59 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package csaf
+
+import (
+	"slices"
+	"sort"
+)
+
+// FindProductsByIdentifier returns all the products of the tree with an
+// identification helper of the type set to the value, including the
+// products defined by relationships. Unlike FindProductIdentifier, products
+// listed in several branches are all returned, in the order of the tree.
+func (branch *ProductBranch) FindProductsByIdentifier(helperType, value string) []Product {
+	ret := []Product{}
+	branch.walkProducts(func(p *Product) {
+		if v, ok := p.IdentificationHelper[helperType]; ok && v == value {
+			ret = append(ret, *p)
+		}
+	})
+	return ret
+}
+
+// ProductIdentifiers returns the values of the identification helpers of a
+// product ID by helper type, eg its purls and CPEs. A product ID listed in
+// several branches gets the helpers of all of them, without duplicates and
+// sorted. It returns an empty map if the product has no helpers.
+func (branch *ProductBranch) ProductIdentifiers(productID string) map[string][]string {
+	ret := map[string][]string{}
+	branch.walkProducts(func(p *Product) {
+		if p.ID != productID {
+			return
+		}
+		for t, v := range p.IdentificationHelper {
+			if v != "" && !slices.Contains(ret[t], v) {
+				ret[t] = append(ret[t], v)
+			}
+		}
+	})
+	for t := range ret {
+		sort.Strings(ret[t])
+	}
+	return ret
+}
+
+// walkProducts calls fn with every product of the branch and its nested
+// branches followed by the products defined by the relationships.
+func (branch *ProductBranch) walkProducts(fn func(*Product)) {
+	if branch.Product.ID != "" || len(branch.Product.IdentificationHelper) > 0 {
+		fn(&branch.Product)
+	}
+	for i := range branch.Branches {
+		branch.Branches[i].walkProducts(fn)
+	}
+	for i := range branch.Relationships {
+		fn(&branch.Relationships[i].FullProductName)
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package csaf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindProductsByIdentifier(t *testing.T) {
+	// The same package listed under two product streams
+	tree := ProductBranch{
+		Branches: []ProductBranch{
+			{
+				Category: "product_name", Name: "AppStream",
+				Branches: []ProductBranch{
+					{Category: "product_version", Name: "qemu-kvm", Product: Product{
+						ID: "AppStream:qemu-kvm", IdentificationHelper: map[string]string{"purl": "pkg:rpm/redhat/qemu-kvm@4.1.0"},
+					}},
+				},
+			},
+			{
+				Category: "product_name", Name: "CRB",
+				Branches: []ProductBranch{
+					{Category: "product_version", Name: "qemu-kvm", Product: Product{
+						ID: "CRB:qemu-kvm", IdentificationHelper: map[string]string{"purl": "pkg:rpm/redhat/qemu-kvm@4.1.0"},
+					}},
+					{Category: "product_version", Name: "qemu-kvm", Product: Product{
+						ID: "AppStream:qemu-kvm", IdentificationHelper: map[string]string{"cpe": "cpe:/a:redhat:enterprise_linux:8::appstream"},
+					}},
+				},
+			},
+		},
+		Relationships: []Relationship{
+			{
+				Category:            RelationshipDefaultComponentOf,
+				FullProductName:     Product{ID: "rhel-8:qemu-kvm", IdentificationHelper: map[string]string{"purl": "pkg:rpm/redhat/qemu-kvm@4.1.0?distro=rhel-8"}},
+				ProductRef:          "CRB:qemu-kvm",
+				RelatesToProductRef: "rhel-8",
+			},
+		},
+	}
+
+	for m, tc := range map[string]struct {
+		helper   string
+		value    string
+		expected []string
+	}{
+		"duplicates":   {"purl", "pkg:rpm/redhat/qemu-kvm@4.1.0", []string{"AppStream:qemu-kvm", "CRB:qemu-kvm"}},
+		"relationship": {"purl", "pkg:rpm/redhat/qemu-kvm@4.1.0?distro=rhel-8", []string{"rhel-8:qemu-kvm"}},
+		"cpe":          {"cpe", "cpe:/a:redhat:enterprise_linux:8::appstream", []string{"AppStream:qemu-kvm"}},
+		"other type":   {"cpe", "pkg:rpm/redhat/qemu-kvm@4.1.0", []string{}},
+		"not found":    {"purl", "pkg:rpm/redhat/libvirt@4.5.0", []string{}},
+	} {
+		ids := []string{}
+		for _, p := range tree.FindProductsByIdentifier(tc.helper, tc.value) {
+			ids = append(ids, p.ID)
+		}
+		require.Equal(t, tc.expected, ids, m)
+	}
+
+	require.Equal(t, map[string][]string{
+		"purl": {"pkg:rpm/redhat/qemu-kvm@4.1.0"},
+		"cpe":  {"cpe:/a:redhat:enterprise_linux:8::appstream"},
+	}, tree.ProductIdentifiers("AppStream:qemu-kvm"))
+	require.Empty(t, tree.ProductIdentifiers("rhel-8"))
+}
+
+func TestFindProductsByIdentifierDocument(t *testing.T) {
+	doc, err := Open("testdata/csaf.json")
+	require.NoError(t, err)
+
+	prods := doc.ProductTree.FindProductsByIdentifier("purl", "pkg:maven/@1.3.4")
+	require.Len(t, prods, 1)
+	require.Equal(t, "CSAFPID-0001", prods[0].ID)
+	require.Equal(t, []string{"pkg:maven/@1.3.4"}, doc.ProductTree.ProductIdentifiers("CSAFPID-0001")["purl"])
+}
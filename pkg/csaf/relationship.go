@@ -0,0 +1,144 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package csaf
+
+import (
+	"fmt"
+)
+
+// Relationship categories defined by the CSAF specification.
+//
+// https://docs.oasis-open.org/csaf/csaf/v2.0/os/csaf-v2.0-os.html#32241-product-tree-property---relationships---category
+const (
+	RelationshipDefaultComponentOf  = "default_component_of"
+	RelationshipExternalComponentOf = "external_component_of"
+	RelationshipInstalledOn         = "installed_on"
+	RelationshipInstalledWith       = "installed_with"
+	RelationshipOptionalComponentOf = "optional_component_of"
+)
+
+// relationshipNames are the formats of the names of the products defined by
+// each relationship category.
+var relationshipNames = map[string]string{
+	RelationshipDefaultComponentOf:  "%s as component of %s",
+	RelationshipExternalComponentOf: "%s as external component of %s",
+	RelationshipInstalledOn:         "%s installed on %s",
+	RelationshipInstalledWith:       "%s installed with %s",
+	RelationshipOptionalComponentOf: "%s as optional component of %s",
+}
+
+// RelationshipCategories returns the relationship categories defined by the
+// CSAF specification.
+func RelationshipCategories() []string {
+	return []string{
+		RelationshipDefaultComponentOf, RelationshipExternalComponentOf,
+		RelationshipInstalledOn, RelationshipInstalledWith, RelationshipOptionalComponentOf,
+	}
+}
+
+// AddRelationship adds a relationship of a category between two products of
+// the tree and returns the ID of the product it defines. The product
+// reference and the product it relates to must be defined in the branches or
+// by another relationship of the tree.
+//
+// The defined product is named after both products and its ID is the
+// product reference joined to the related product with a colon, the
+// category is appended when that ID is already taken. Adding a relationship
+// already in the tree returns the ID of its product.
+//
+// AddRelationship indexes the whole tree on every call, use a
+// RelationshipIndex to add many relationships.
+func (branch *ProductBranch) AddRelationship(category, productRef, relatesToRef string) (string, error) {
+	return NewRelationshipIndex(branch).Add(category, productRef, relatesToRef)
+}
+
+// relationshipKey identifies a relationship by its category and products.
+type relationshipKey struct {
+	category, productRef, relatesToRef string
+}
+
+// RelationshipIndex adds relationships to a product tree like
+// AddRelationship, indexing the product IDs of the tree only once. Products
+// added to the branches of the tree after creating the index are not known
+// to it.
+type RelationshipIndex struct {
+	branch        *ProductBranch
+	ids           map[string]struct{}
+	relationships map[relationshipKey]string
+}
+
+// NewRelationshipIndex indexes the products and relationships of the tree.
+func NewRelationshipIndex(branch *ProductBranch) *RelationshipIndex {
+	ri := &RelationshipIndex{
+		branch:        branch,
+		ids:           map[string]struct{}{},
+		relationships: map[relationshipKey]string{},
+	}
+	branch.collectProductIDs(ri.ids)
+	for i := range branch.Relationships {
+		r := &branch.Relationships[i]
+		key := relationshipKey{r.Category, r.ProductRef, r.RelatesToProductRef}
+		if _, ok := ri.relationships[key]; !ok {
+			ri.relationships[key] = r.FullProductName.ID
+		}
+		ri.ids[r.FullProductName.ID] = struct{}{}
+	}
+	return ri
+}
+
+// Add adds a relationship to the tree, see ProductBranch.AddRelationship.
+func (ri *RelationshipIndex) Add(category, productRef, relatesToRef string) (string, error) {
+	format, ok := relationshipNames[category]
+	if !ok {
+		return "", fmt.Errorf("invalid relationship category %q", category)
+	}
+
+	key := relationshipKey{category, productRef, relatesToRef}
+	if id, ok := ri.relationships[key]; ok {
+		return id, nil
+	}
+	for _, ref := range []string{productRef, relatesToRef} {
+		if _, ok := ri.ids[ref]; !ok {
+			return "", fmt.Errorf("product %q is not defined in the product tree", ref)
+		}
+	}
+
+	id := productRef + ":" + relatesToRef
+	if _, ok := ri.ids[id]; ok {
+		id += ":" + category
+	}
+	ri.branch.Relationships = append(ri.branch.Relationships, Relationship{
+		Category:            category,
+		FullProductName:     Product{Name: fmt.Sprintf(format, productRef, relatesToRef), ID: id},
+		ProductRef:          productRef,
+		RelatesToProductRef: relatesToRef,
+	})
+	ri.relationships[key] = id
+	ri.ids[id] = struct{}{}
+	return id, nil
+}
+
+// collectProductIDs adds the IDs of the products in the branch and its
+// subbranches to ids.
+func (branch *ProductBranch) collectProductIDs(ids map[string]struct{}) {
+	if branch.Product.ID != "" {
+		ids[branch.Product.ID] = struct{}{}
+	}
+	for i := range branch.Branches {
+		branch.Branches[i].collectProductIDs(ids)
+	}
+}
+
+// RelationshipsOf returns the relationships of the tree where the product is
+// the product reference or the product it relates to.
+func (branch *ProductBranch) RelationshipsOf(productID string) []Relationship {
+	ret := []Relationship{}
+	for i := range branch.Relationships {
+		r := &branch.Relationships[i]
+		if r.ProductRef == productID || r.RelatesToProductRef == productID {
+			ret = append(ret, *r)
+		}
+	}
+	return ret
+}
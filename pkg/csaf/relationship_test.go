@@ -0,0 +1,78 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package csaf
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddRelationship(t *testing.T) {
+	tree := ProductBranch{
+		Branches: []ProductBranch{
+			{Category: "product_name", Name: "rhel-8", Product: Product{Name: "RHEL 8", ID: "rhel-8"}},
+			{Category: "product_name", Name: "qemu-kvm", Product: Product{Name: "qemu-kvm", ID: "qemu-kvm"}},
+			{Category: "product_name", Name: "libvirt", Product: Product{Name: "libvirt", ID: "libvirt"}},
+		},
+	}
+
+	// Cases run in order, later ones relate to the products added before
+	for _, tc := range []struct {
+		name      string
+		category  string
+		product   string
+		relatesTo string
+		expected  string
+		mustErr   bool
+	}{
+		{"component", RelationshipDefaultComponentOf, "qemu-kvm", "rhel-8", "qemu-kvm:rhel-8", false},
+		{"existing", RelationshipDefaultComponentOf, "qemu-kvm", "rhel-8", "qemu-kvm:rhel-8", false},
+		{"other category", RelationshipInstalledOn, "qemu-kvm", "rhel-8", "qemu-kvm:rhel-8:installed_on", false},
+		{"relationship", RelationshipInstalledWith, "libvirt", "qemu-kvm:rhel-8", "libvirt:qemu-kvm:rhel-8", false},
+		{"invalid category", "bundled_with", "qemu-kvm", "rhel-8", "", true},
+		{"undefined product", RelationshipDefaultComponentOf, "bash", "rhel-8", "", true},
+		{"undefined related", RelationshipOptionalComponentOf, "qemu-kvm", "rhel-9", "", true},
+	} {
+		id, err := tree.AddRelationship(tc.category, tc.product, tc.relatesTo)
+		if tc.mustErr {
+			require.Error(t, err, tc.name)
+			continue
+		}
+		require.NoError(t, err, tc.name)
+		require.Equal(t, tc.expected, id, tc.name)
+	}
+
+	tree.Relationships = nil
+	id, err := tree.AddRelationship(RelationshipDefaultComponentOf, "qemu-kvm", "rhel-8")
+	require.NoError(t, err)
+	_, err = tree.AddRelationship(RelationshipDefaultComponentOf, "qemu-kvm", "rhel-8")
+	require.NoError(t, err)
+	_, err = tree.AddRelationship(RelationshipExternalComponentOf, "libvirt", id)
+	require.NoError(t, err)
+	require.Len(t, tree.Relationships, 2)
+	require.Equal(t, "qemu-kvm as component of rhel-8", tree.Relationships[0].FullProductName.Name)
+	require.Equal(t, "libvirt as external component of qemu-kvm:rhel-8", tree.Relationships[1].FullProductName.Name)
+
+	require.Len(t, tree.RelationshipsOf("qemu-kvm"), 1)
+	require.Len(t, tree.RelationshipsOf(id), 1)
+	require.Empty(t, tree.RelationshipsOf("bash"))
+
+	// Relationships are written and read back
+	var b bytes.Buffer
+	doc := CSAF{ProductTree: tree}
+	require.NoError(t, doc.ToJSON(&b))
+	parsed, err := Parse(b.Bytes())
+	require.NoError(t, err)
+	require.Equal(t, tree.Relationships, parsed.ProductTree.Relationships)
+}
+
+func TestRelationshipCategories(t *testing.T) {
+	categories := RelationshipCategories()
+	require.Len(t, categories, 5)
+	for _, c := range categories {
+		require.Contains(t, relationshipNames, c)
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package csaf
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrProductNotFound is returned when resolving a product ID that is not
+// defined in the product tree.
+var ErrProductNotFound = errors.New("product not found in the product tree")
+
+// ResolvedProduct is a product of the tree with its relationships resolved.
+// Products defined in the branches have no relationship, products defined
+// by a relationship link to the products it combines.
+type ResolvedProduct struct {
+	// Product is the product. Products listed in several branches get the
+	// name of the first one and the identification helpers of all of them.
+	Product Product
+
+	// Relationship is the relationship defining the product, nil for
+	// products defined in the branches.
+	Relationship *Relationship
+
+	// Component is the product_reference of the relationship, the
+	// product that is part of, or installed on, the other.
+	Component *ResolvedProduct
+
+	// RelatesTo is the relates_to_product_reference of the relationship.
+	RelatesTo *ResolvedProduct
+}
+
+// Platform returns the product at the end of the chain of related products,
+// eg the distribution in "component of package installed on distribution".
+// It returns the product itself when it is not defined by a relationship.
+func (rp *ResolvedProduct) Platform() *ResolvedProduct {
+	p := rp
+	for p.RelatesTo != nil {
+		p = p.RelatesTo
+	}
+	return p
+}
+
+// Base returns the product at the end of the chain of components, that is
+// the product defined in the branches that the relationships place in the
+// platform.
+func (rp *ResolvedProduct) Base() *ResolvedProduct {
+	p := rp
+	for p.Component != nil {
+		p = p.Component
+	}
+	return p
+}
+
+// ProductResolver resolves the products of a product tree, following the
+// relationships that define products in terms of others. The tree is
+// indexed when the resolver is created and must not be modified while it
+// is used.
+type ProductResolver struct {
+	products      map[string]*Product
+	relationships map[string]*Relationship
+	resolved      map[string]*ResolvedProduct
+}
+
+// NewProductResolver returns a resolver for the products of the tree.
+func NewProductResolver(tree *ProductBranch) *ProductResolver {
+	r := &ProductResolver{
+		products:      map[string]*Product{},
+		relationships: map[string]*Relationship{},
+		resolved:      map[string]*ResolvedProduct{},
+	}
+	var walk func(*ProductBranch)
+	walk = func(b *ProductBranch) {
+		if p := &b.Product; p.ID != "" {
+			r.addProduct(p)
+		}
+		for i := range b.Branches {
+			walk(&b.Branches[i])
+		}
+	}
+	walk(tree)
+	for i := range tree.Relationships {
+		rel := &tree.Relationships[i]
+		if _, ok := r.relationships[rel.FullProductName.ID]; !ok && rel.FullProductName.ID != "" {
+			r.relationships[rel.FullProductName.ID] = rel
+		}
+	}
+	return r
+}
+
+// addProduct indexes a branch product, merging the identification helpers
+// of products listed more than once.
+func (r *ProductResolver) addProduct(p *Product) {
+	existing, ok := r.products[p.ID]
+	if !ok {
+		cp := Product{Name: p.Name, ID: p.ID}
+		existing = &cp
+		r.products[p.ID] = existing
+	}
+	if existing.Name == "" {
+		existing.Name = p.Name
+	}
+	for t, v := range p.IdentificationHelper {
+		if _, ok := existing.IdentificationHelper[t]; ok || v == "" {
+			continue
+		}
+		if existing.IdentificationHelper == nil {
+			existing.IdentificationHelper = map[string]string{}
+		}
+		existing.IdentificationHelper[t] = v
+	}
+}
+
+// Resolve returns the product with the ID. Products defined by
+// relationships are resolved recursively. It returns an error wrapping
+// ErrProductNotFound if the product, or a product it references, is not
+// defined in the tree and an error if the relationships form a cycle.
+func (r *ProductResolver) Resolve(productID string) (*ResolvedProduct, error) {
+	return r.resolve(productID, map[string]struct{}{})
+}
+
+func (r *ProductResolver) resolve(productID string, seen map[string]struct{}) (*ResolvedProduct, error) {
+	if rp, ok := r.resolved[productID]; ok {
+		return rp, nil
+	}
+	if p, ok := r.products[productID]; ok {
+		rp := &ResolvedProduct{Product: *p}
+		r.resolved[productID] = rp
+		return rp, nil
+	}
+	rel, ok := r.relationships[productID]
+	if !ok {
+		return nil, fmt.Errorf("resolving %q: %w", productID, ErrProductNotFound)
+	}
+	if _, ok := seen[productID]; ok {
+		return nil, fmt.Errorf("resolving %q: relationships form a cycle", productID)
+	}
+	seen[productID] = struct{}{}
+
+	component, err := r.resolve(rel.ProductRef, seen)
+	if err != nil {
+		return nil, fmt.Errorf("resolving %q: %w", productID, err)
+	}
+	relatesTo, err := r.resolve(rel.RelatesToProductRef, seen)
+	if err != nil {
+		return nil, fmt.Errorf("resolving %q: %w", productID, err)
+	}
+	rp := &ResolvedProduct{
+		Product:      rel.FullProductName,
+		Relationship: rel,
+		Component:    component,
+		RelatesTo:    relatesTo,
+	}
+	r.resolved[productID] = rp
+	return rp, nil
+}
+
+// ResolveProduct resolves a product of the tree through its relationships.
+// Use a ProductResolver to resolve several products of the same tree.
+func (branch *ProductBranch) ResolveProduct(productID string) (*ResolvedProduct, error) {
+	return NewProductResolver(branch).Resolve(productID)
+}
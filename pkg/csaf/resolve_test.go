@@ -0,0 +1,69 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package csaf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveProduct(t *testing.T) {
+	tree := ProductBranch{
+		Branches: []ProductBranch{
+			{Product: Product{ID: "rhel-8", Name: "Red Hat Enterprise Linux 8"}},
+			{Product: Product{ID: "qemu-kvm", IdentificationHelper: map[string]string{"purl": "pkg:rpm/redhat/qemu-kvm@4.1.0"}}},
+			{Product: Product{ID: "qemu-kvm", Name: "qemu-kvm", IdentificationHelper: map[string]string{"cpe": "cpe:/a:redhat:qemu-kvm:4.1.0"}}},
+			{Product: Product{ID: "seabios"}},
+		},
+	}
+	for _, rel := range [][3]string{
+		{RelationshipDefaultComponentOf, "qemu-kvm", "rhel-8"},
+		{RelationshipInstalledWith, "seabios", "qemu-kvm:rhel-8"},
+	} {
+		_, err := tree.AddRelationship(rel[0], rel[1], rel[2])
+		require.NoError(t, err)
+	}
+	tree.Relationships = append(tree.Relationships,
+		Relationship{Category: RelationshipInstalledOn, FullProductName: Product{ID: "missing"}, ProductRef: "qemu-kvm", RelatesToProductRef: "rhel-9"},
+		Relationship{Category: RelationshipInstalledOn, FullProductName: Product{ID: "loop"}, ProductRef: "qemu-kvm", RelatesToProductRef: "loop"},
+	)
+
+	r := NewProductResolver(&tree)
+
+	// Branch products merge the helpers of all their entries
+	rp, err := r.Resolve("qemu-kvm")
+	require.NoError(t, err)
+	require.Nil(t, rp.Relationship)
+	require.Equal(t, "qemu-kvm", rp.Product.Name)
+	require.Equal(t, map[string]string{
+		"purl": "pkg:rpm/redhat/qemu-kvm@4.1.0", "cpe": "cpe:/a:redhat:qemu-kvm:4.1.0",
+	}, rp.Product.IdentificationHelper)
+	require.Same(t, rp, rp.Platform())
+	require.Same(t, rp, rp.Base())
+
+	rp, err = r.Resolve("qemu-kvm:rhel-8")
+	require.NoError(t, err)
+	require.Equal(t, RelationshipDefaultComponentOf, rp.Relationship.Category)
+	require.Equal(t, "qemu-kvm", rp.Component.Product.ID)
+	require.Equal(t, "rhel-8", rp.RelatesTo.Product.ID)
+
+	// Nested relationships resolve to the platform and the base product
+	rp, err = tree.ResolveProduct("seabios:qemu-kvm:rhel-8")
+	require.NoError(t, err)
+	require.Equal(t, "qemu-kvm:rhel-8", rp.RelatesTo.Product.ID)
+	require.Equal(t, "rhel-8", rp.Platform().Product.ID)
+	require.Equal(t, "seabios", rp.Base().Product.ID)
+
+	for m, id := range map[string]string{
+		"unknown product":   "bash",
+		"unknown reference": "missing",
+	} {
+		_, err := r.Resolve(id)
+		require.ErrorIs(t, err, ErrProductNotFound, m)
+	}
+
+	_, err = r.Resolve("loop")
+	require.ErrorContains(t, err, "cycle")
+}
@@ -0,0 +1,289 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package csaf
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/openvex/go-vex/pkg/internal/jsonschema"
+)
+
+// Profile is a CSAF profile, the set of requirements a document must meet
+// according to its category.
+//
+// https://docs.oasis-open.org/csaf/csaf/v2.0/os/csaf-v2.0-os.html#4-profiles
+type Profile string
+
+const (
+	// ProfileBase applies to documents of any other category.
+	ProfileBase Profile = "csaf_base"
+
+	// ProfileSecurityIncidentResponse is the profile of security incident
+	// responses.
+	ProfileSecurityIncidentResponse Profile = "csaf_security_incident_response"
+
+	// ProfileInformationalAdvisory is the profile of informational
+	// advisories.
+	ProfileInformationalAdvisory Profile = "csaf_informational_advisory"
+
+	// ProfileSecurityAdvisory is the profile of security advisories.
+	ProfileSecurityAdvisory Profile = "csaf_security_advisory"
+
+	// ProfileVEX is the profile of VEX documents.
+	ProfileVEX Profile = CategoryVEX
+)
+
+// schemaFiles holds the JSON schemas documents are validated against. The
+// main schema is the official CSAF 2.0 schema, csaf_json_schema.json, when
+// it is vendored with make update-csaf-schemas. Until then it is
+// csaf_structure_schema.json, a schema of the structure of the documents
+// modeled by this package. The rest are the FIRST CVSS schemas they
+// reference.
+//
+//go:embed schema/*.json
+var schemaFiles embed.FS
+
+const (
+	// officialSchema is the file name of the OASIS CSAF 2.0 schema.
+	officialSchema = "csaf_json_schema.json"
+
+	// structureSchema is the file name of the structure schema used when
+	// the official schema is not vendored.
+	structureSchema = "csaf_structure_schema.json"
+)
+
+// schema is the parsed main schema.
+var schema = parseSchema()
+
+// parseSchema parses the main embedded schema, resolving its references to
+// the other embedded schemas.
+func parseSchema() *jsonschema.Schema {
+	entries, err := schemaFiles.ReadDir("schema")
+	if err != nil {
+		panic(fmt.Sprintf("reading CSAF schemas: %v", err))
+	}
+
+	schemas := map[string][]byte{}
+	for _, e := range entries {
+		data, err := schemaFiles.ReadFile(path.Join("schema", e.Name()))
+		if err != nil {
+			panic(fmt.Sprintf("reading CSAF schemas: %v", err))
+		}
+		schemas[e.Name()] = data
+	}
+
+	mainSchema := structureSchema
+	if _, ok := schemas[officialSchema]; ok {
+		mainSchema = officialSchema
+	}
+
+	resources := [][]byte{}
+	for name, data := range schemas {
+		if name != officialSchema && name != structureSchema {
+			resources = append(resources, data)
+		}
+	}
+	return jsonschema.MustParse("CSAF", schemas[mainSchema], resources...)
+}
+
+// ValidationError is a violation of the document structure or of a profile
+// requirement found in a document.
+type ValidationError struct {
+	// Pointer is the JSON pointer to the offending value
+	Pointer string
+
+	// Message describes the violation
+	Message string
+}
+
+// Error returns the pointer and the message of the violation.
+func (e *ValidationError) Error() string {
+	pointer := e.Pointer
+	if pointer == "" {
+		pointer = "(document)"
+	}
+	return fmt.Sprintf("%s: %s", pointer, e.Message)
+}
+
+// ConformanceError is returned when a document does not have the structure
+// of a CSAF document or does not meet the requirements of its profile. It lists all the
+// violations found.
+type ConformanceError struct {
+	Profile Profile
+	Errors  []*ValidationError
+}
+
+// Error returns the messages of all the violations.
+func (e *ConformanceError) Error() string {
+	msgs := []string{}
+	for _, err := range e.Errors {
+		msgs = append(msgs, err.Error())
+	}
+	return fmt.Sprintf("document does not conform to CSAF %s profile %s: %s", Version, e.Profile, strings.Join(msgs, "; "))
+}
+
+// Unwrap returns the violations.
+func (e *ConformanceError) Unwrap() []error {
+	ret := []error{}
+	for _, err := range e.Errors {
+		ret = append(ret, err)
+	}
+	return ret
+}
+
+// DetectProfile returns the profile of the document, read from its
+// category. Documents of unknown categories get the base profile.
+func DetectProfile(doc *CSAF) Profile {
+	switch p := Profile(doc.Document.Category); p {
+	case ProfileSecurityIncidentResponse, ProfileInformationalAdvisory, ProfileSecurityAdvisory, ProfileVEX:
+		return p
+	default:
+		return ProfileBase
+	}
+}
+
+// ValidateBytes checks the structure of a serialized document and the
+// requirements of the profile of its category, see Validate. If the
+// document does not conform, the returned error is a *ConformanceError
+// listing the violations.
+func ValidateBytes(data []byte) error {
+	raw, err := jsonschema.Decode(data)
+	if err != nil {
+		return fmt.Errorf("csaf: %w", err)
+	}
+	doc, err := Parse(data)
+	if err != nil {
+		return err
+	}
+	return conformance(doc, raw, DetectProfile(doc))
+}
+
+// Validate checks the structure of the document and that it meets the
+// requirements of the profile of its category. If it does not, the returned
+// error is a *ConformanceError listing the violations.
+//
+// The structure is checked against the embedded schemas, see schemaFiles.
+// CVSS scores are checked against the CVSS schemas. Until the official CSAF
+// 2.0 schema is vendored, the rest of the document is checked against a
+// schema of the properties modeled by this package, so documents passing
+// the check may still be invalid CSAF.
+func Validate(doc *CSAF) error {
+	return ValidateProfile(doc, DetectProfile(doc))
+}
+
+// ValidateProfile checks the structure of the document, see Validate, and
+// that it meets the requirements of a profile, eg to check that a document meets
+// the VEX profile before converting it to OpenVEX.
+func ValidateProfile(doc *CSAF, profile Profile) error {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("csaf: marshaling document: %w", err)
+	}
+	raw, err := jsonschema.Decode(data)
+	if err != nil {
+		return fmt.Errorf("csaf: %w", err)
+	}
+
+	// The product tree and the vulnerabilities are always serialized,
+	// drop them when they are empty as if they were not set.
+	if obj, ok := raw.(map[string]any); ok {
+		if tree, ok := obj["product_tree"].(map[string]any); ok && len(tree) == 0 {
+			delete(obj, "product_tree")
+		}
+		if obj["vulnerabilities"] == nil {
+			delete(obj, "vulnerabilities")
+		}
+	}
+	return conformance(doc, raw, profile)
+}
+
+// conformance returns a *ConformanceError if the serialized document raw
+// violates the embedded schemas or the document the requirements of the
+// profile.
+func conformance(doc *CSAF, raw any, profile Profile) error {
+	errs := []*ValidationError{}
+	for _, v := range schema.Validate(raw) {
+		errs = append(errs, &ValidationError{Pointer: v.Pointer, Message: v.Message})
+	}
+	errs = append(errs, profileErrors(doc, profile)...)
+	if len(errs) > 0 {
+		return &ConformanceError{Profile: profile, Errors: errs}
+	}
+	return nil
+}
+
+// profileErrors returns the violations of the requirements of the profile.
+//
+// https://docs.oasis-open.org/csaf/csaf/v2.0/os/csaf-v2.0-os.html#4-profiles
+func profileErrors(doc *CSAF, profile Profile) []*ValidationError {
+	errs := []*ValidationError{}
+	fail := func(pointer, format string, args ...any) {
+		errs = append(errs, &ValidationError{Pointer: pointer, Message: fmt.Sprintf(format, args...)})
+	}
+
+	switch profile {
+	case ProfileSecurityIncidentResponse, ProfileInformationalAdvisory:
+		if !slices.ContainsFunc(doc.Document.Notes, func(n Note) bool {
+			return slices.Contains([]string{"description", "details", "general", "summary"}, n.Category)
+		}) {
+			fail("/document/notes", "must have a note of category description, details, general or summary")
+		}
+		if !slices.ContainsFunc(doc.Document.References, func(r Reference) bool { return r.Category == "external" }) {
+			fail("/document/references", "must have a reference of category external")
+		}
+		if profile == ProfileInformationalAdvisory && len(doc.Vulnerabilities) > 0 {
+			fail("/vulnerabilities", "must not be set in an informational advisory")
+		}
+	case ProfileSecurityAdvisory, ProfileVEX:
+		if len(doc.ProductTree.Branches) == 0 && len(doc.ProductTree.Relationships) == 0 {
+			fail("/product_tree", "must define the products")
+		}
+		if len(doc.Vulnerabilities) == 0 {
+			fail("/vulnerabilities", "must have at least one vulnerability")
+		}
+	case ProfileBase:
+	}
+	if profile != ProfileVEX {
+		return errs
+	}
+
+	for i := range doc.Vulnerabilities {
+		v := &doc.Vulnerabilities[i]
+		pointer := "/vulnerabilities/" + strconv.Itoa(i)
+		if v.CVE == "" && len(v.IDs) == 0 {
+			fail(pointer, "must have a cve or ids")
+		}
+		if len(v.Notes) == 0 {
+			fail(pointer+"/notes", "must have at least one note")
+		}
+		if !slices.ContainsFunc([]string{"fixed", "known_affected", "known_not_affected", "under_investigation"}, func(s string) bool {
+			return len(v.ProductStatus[s]) > 0
+		}) {
+			fail(pointer+"/product_status", "must have fixed, known_affected, known_not_affected or under_investigation products")
+		}
+
+		// Not affected products need an impact statement and affected
+		// products an action statement.
+		for _, id := range v.ProductStatus["known_not_affected"] {
+			if !slices.ContainsFunc(v.Flags, func(f Flag) bool { return slices.Contains(f.ProductIDs, id) }) &&
+				!slices.ContainsFunc(v.Threats, func(t ThreatData) bool {
+					return t.Category == "impact" && slices.Contains(t.ProductIDs, id)
+				}) {
+				fail(pointer+"/product_status/known_not_affected", "product %q has no flag or impact threat", id)
+			}
+		}
+		for _, id := range v.ProductStatus["known_affected"] {
+			if !slices.ContainsFunc(v.Remediations, func(r RemediationData) bool { return slices.Contains(r.ProductIDs, id) }) {
+				fail(pointer+"/product_status/known_affected", "product %q has no remediation", id)
+			}
+		}
+	}
+	return errs
+}
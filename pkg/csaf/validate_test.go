@@ -0,0 +1,169 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package csaf
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateBytes(t *testing.T) {
+	data, err := os.ReadFile("testdata/rhsa-2020_1358.json")
+	require.NoError(t, err)
+	require.NoError(t, ValidateBytes(data))
+
+	// The example VEX document nests branches in a branch with a product
+	// and has affected products without remediations.
+	data, err = os.ReadFile("testdata/csaf.json")
+	require.NoError(t, err)
+	err = ValidateBytes(data)
+	var confErr *ConformanceError
+	require.ErrorAs(t, err, &confErr)
+	require.Equal(t, ProfileVEX, confErr.Profile)
+	pointers := []string{}
+	for _, e := range confErr.Errors {
+		pointers = append(pointers, e.Pointer)
+	}
+	require.Equal(t, []string{
+		"/product_tree/branches/0/branches/0",
+		"/vulnerabilities/0/product_status/known_affected",
+		"/vulnerabilities/1/product_status/known_affected",
+	}, pointers)
+
+	require.ErrorContains(t, ValidateBytes([]byte(`{"document": `)), "parsing document")
+}
+
+func TestDetectProfile(t *testing.T) {
+	for category, expected := range map[string]Profile{
+		"csaf_vex":                        ProfileVEX,
+		"csaf_security_advisory":          ProfileSecurityAdvisory,
+		"csaf_informational_advisory":     ProfileInformationalAdvisory,
+		"csaf_security_incident_response": ProfileSecurityIncidentResponse,
+		"csaf_base":                       ProfileBase,
+		"generic_advisory":                ProfileBase,
+	} {
+		doc := &CSAF{Document: DocumentMetadata{Category: category}}
+		require.Equal(t, expected, DetectProfile(doc), category)
+	}
+}
+
+func TestValidateProfile(t *testing.T) {
+	ts := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	newDoc := func() *CSAF {
+		return &CSAF{
+			Document: DocumentMetadata{
+				Category:    CategoryVEX,
+				CSAFVersion: Version,
+				Title:       "Example",
+				Publisher:   Publisher{Category: "vendor", Name: "Example", Namespace: "https://example.com"},
+				Tracking: Tracking{
+					ID: "EXAMPLE-1", CurrentReleaseDate: ts, InitialReleaseDate: ts, Status: "final", Version: "1",
+					RevisionHistory: []Revision{{Date: ts, Number: "1", Summary: "Initial"}},
+				},
+			},
+			ProductTree: ProductBranch{Branches: []ProductBranch{
+				{Category: "product_name", Name: "app", Product: Product{Name: "app", ID: "app"}},
+			}},
+			Vulnerabilities: []Vulnerability{{
+				CVE:           "CVE-2023-1234",
+				Notes:         []Note{{Category: "summary", Text: "Example"}},
+				ProductStatus: map[string][]string{"known_not_affected": {"app"}},
+				Flags:         []Flag{{Label: "vulnerable_code_not_present", Date: ts, ProductIDs: []string{"app"}}},
+			}},
+		}
+	}
+	require.NoError(t, Validate(newDoc()))
+
+	for m, tc := range map[string]struct {
+		modify   func(*CSAF)
+		profile  Profile
+		pointers []string
+	}{
+		"no notes": {
+			modify:   func(d *CSAF) { d.Vulnerabilities[0].Notes = nil },
+			profile:  ProfileVEX,
+			pointers: []string{"/vulnerabilities/0/notes"},
+		},
+		"no impact statement": {
+			modify:   func(d *CSAF) { d.Vulnerabilities[0].Flags = nil },
+			profile:  ProfileVEX,
+			pointers: []string{"/vulnerabilities/0/product_status/known_not_affected"},
+		},
+		"affected without remediation": {
+			modify: func(d *CSAF) {
+				d.Vulnerabilities[0].ProductStatus = map[string][]string{"known_affected": {"app"}}
+			},
+			profile:  ProfileVEX,
+			pointers: []string{"/vulnerabilities/0/product_status/known_affected"},
+		},
+		"only recommended": {
+			modify: func(d *CSAF) {
+				d.Vulnerabilities[0].ProductStatus = map[string][]string{"recommended": {"app"}}
+			},
+			profile:  ProfileVEX,
+			pointers: []string{"/vulnerabilities/0/product_status"},
+		},
+		"no vulnerabilities": {
+			modify:   func(d *CSAF) { d.Vulnerabilities = nil },
+			profile:  ProfileVEX,
+			pointers: []string{"/vulnerabilities"},
+		},
+		"no vulnerabilities in base profile": {
+			modify:  func(d *CSAF) { d.Vulnerabilities = nil; d.ProductTree = ProductBranch{} },
+			profile: ProfileBase,
+		},
+		"schema violation": {
+			modify:   func(d *CSAF) { d.Vulnerabilities[0].CVE = "CVE-1" },
+			profile:  ProfileBase,
+			pointers: []string{"/vulnerabilities/0/cve"},
+		},
+		"valid scores": {
+			modify: func(d *CSAF) {
+				d.Vulnerabilities[0].Scores = []Score{{
+					CVSSV2: CVSSV2{Version: "2.0", VectorString: "AV:N/AC:L/Au:N/C:P/I:N/A:N", BaseScore: 5},
+					CVSSV3: CVSSV3{
+						Version: "3.1", VectorString: "CVSS:3.1/AV:N/AC:H/PR:N/UI:N/S:U/C:N/I:N/A:H",
+						BaseScore: 5.9, BaseSeverity: "MEDIUM", AttackVector: "NETWORK",
+					},
+					ProductIDs: []string{"app"},
+				}}
+			},
+			profile: ProfileVEX,
+		},
+		"invalid scores": {
+			modify: func(d *CSAF) {
+				d.Vulnerabilities[0].Scores = []Score{{
+					CVSSV2:     CVSSV2{Version: "2.0", VectorString: "AV:N/AC:L/Au:N/C:P/I:N/A:N", BaseScore: 11},
+					CVSSV3:     CVSSV3{Version: "3.1", VectorString: "CVSS:3.0/AV:N", BaseScore: 5.9, BaseSeverity: "MEDIUM"},
+					ProductIDs: []string{"app"},
+				}}
+			},
+			profile:  ProfileVEX,
+			pointers: []string{"/vulnerabilities/0/scores/0/cvss_v2/baseScore", "/vulnerabilities/0/scores/0/cvss_v3"},
+		},
+		"informational advisory": {
+			modify:   func(*CSAF) {},
+			profile:  ProfileInformationalAdvisory,
+			pointers: []string{"/document/notes", "/document/references", "/vulnerabilities"},
+		},
+	} {
+		doc := newDoc()
+		tc.modify(doc)
+		err := ValidateProfile(doc, tc.profile)
+		if len(tc.pointers) == 0 {
+			require.NoError(t, err, m)
+			continue
+		}
+		var confErr *ConformanceError
+		require.ErrorAs(t, err, &confErr, m)
+		pointers := []string{}
+		for _, e := range confErr.Errors {
+			pointers = append(pointers, e.Pointer)
+		}
+		require.Equal(t, tc.pointers, pointers, m)
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cyclonedx
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// SpecVersion is the version of the CycloneDX specification of the
+// documents written by the library.
+const SpecVersion = "1.5"
+
+// BOM is a CycloneDX document. Only the fields needed to read and write VEX
+// data are defined.
+//
+// https://cyclonedx.org/docs/1.5/json/
+type BOM struct {
+	BOMFormat       string          `json:"bomFormat"`
+	SpecVersion     string          `json:"specVersion"`
+	SerialNumber    string          `json:"serialNumber,omitempty"`
+	Version         int             `json:"version,omitempty"`
+	Metadata        Metadata        `json:"metadata"`
+	Components      []Component     `json:"components,omitempty"`
+	Vulnerabilities []Vulnerability `json:"vulnerabilities,omitempty"`
+}
+
+// Metadata holds data about the BOM itself.
+//
+// https://cyclonedx.org/docs/1.5/json/#metadata
+type Metadata struct {
+	Timestamp *time.Time `json:"timestamp,omitempty"`
+	Component *Component `json:"component,omitempty"`
+	Supplier  *Entity    `json:"supplier,omitempty"`
+}
+
+// Entity is an organization or individual.
+type Entity struct {
+	Name string   `json:"name,omitempty"`
+	URL  []string `json:"url,omitempty"`
+}
+
+// Component is a piece of software described in the BOM.
+//
+// https://cyclonedx.org/docs/1.5/json/#components
+type Component struct {
+	BOMRef     string      `json:"bom-ref,omitempty"`
+	Type       string      `json:"type,omitempty"`
+	Name       string      `json:"name,omitempty"`
+	Version    string      `json:"version,omitempty"`
+	Purl       string      `json:"purl,omitempty"`
+	CPE        string      `json:"cpe,omitempty"`
+	Hashes     []Hash      `json:"hashes,omitempty"`
+	Components []Component `json:"components,omitempty"`
+}
+
+// Hash is a hash of a component.
+type Hash struct {
+	Algorithm string `json:"alg,omitempty"`
+	Content   string `json:"content,omitempty"`
+}
+
+// Vulnerability is a vulnerability and its analysis in the affected
+// components.
+//
+// https://cyclonedx.org/docs/1.5/json/#vulnerabilities
+type Vulnerability struct {
+	BOMRef      string      `json:"bom-ref,omitempty"`
+	ID          string      `json:"id,omitempty"`
+	Source      *Source     `json:"source,omitempty"`
+	References  []Reference `json:"references,omitempty"`
+	Ratings     []Rating    `json:"ratings,omitempty"`
+	Description string      `json:"description,omitempty"`
+	Published   *time.Time  `json:"published,omitempty"`
+	Updated     *time.Time  `json:"updated,omitempty"`
+	Analysis    *Analysis   `json:"analysis,omitempty"`
+	Affects     []Affect    `json:"affects,omitempty"`
+}
+
+// Source is the database a vulnerability is tracked in.
+type Source struct {
+	Name string `json:"name,omitempty"`
+	URL  string `json:"url,omitempty"`
+}
+
+// Reference links the vulnerability to the same one in other sources.
+type Reference struct {
+	ID     string  `json:"id,omitempty"`
+	Source *Source `json:"source,omitempty"`
+}
+
+// Rating is a severity rating of the vulnerability.
+//
+// https://cyclonedx.org/docs/1.5/json/#vulnerabilities_items_ratings
+type Rating struct {
+	Source   *Source `json:"source,omitempty"`
+	Score    float64 `json:"score,omitempty"`
+	Severity string  `json:"severity,omitempty"`
+	Method   string  `json:"method,omitempty"`
+	Vector   string  `json:"vector,omitempty"`
+}
+
+// Analysis is the impact analysis of a vulnerability.
+//
+// https://cyclonedx.org/docs/1.5/json/#vulnerabilities_items_analysis
+type Analysis struct {
+	State         string     `json:"state,omitempty"`
+	Justification string     `json:"justification,omitempty"`
+	Response      []string   `json:"response,omitempty"`
+	Detail        string     `json:"detail,omitempty"`
+	FirstIssued   *time.Time `json:"firstIssued,omitempty"`
+	LastUpdated   *time.Time `json:"lastUpdated,omitempty"`
+}
+
+// Affect references a component affected by the vulnerability.
+type Affect struct {
+	Ref string `json:"ref,omitempty"`
+}
+
+// Open reads and parses a given file path and returns a CycloneDX document
+// or an error if the file could not be opened or parsed.
+func Open(path string) (*BOM, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // This is supposed to open user-specified paths
+	if err != nil {
+		return nil, fmt.Errorf("cyclonedx: failed to open document: %w", err)
+	}
+	return Parse(data)
+}
+
+// Parse decodes a CycloneDX document from its JSON data.
+func Parse(data []byte) (*BOM, error) {
+	bom := &BOM{}
+	if err := json.Unmarshal(data, bom); err != nil {
+		return nil, fmt.Errorf("cyclonedx: failed to decode document: %w", err)
+	}
+
+	if bom.BOMFormat != "CycloneDX" {
+		return nil, fmt.Errorf("cyclonedx: unexpected bomFormat %q", bom.BOMFormat)
+	}
+
+	return bom, nil
+}
+
+// ToJSON serializes the BOM to JSON and writes it to the passed writer.
+func (bom *BOM) ToJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+
+	if err := enc.Encode(bom); err != nil {
+		return fmt.Errorf("cyclonedx: encoding document: %w", err)
+	}
+	return nil
+}
+
+// ListComponents returns a flat list of all components in the BOM,
+// including the metadata component and nested components.
+func (bom *BOM) ListComponents() []Component {
+	ret := []Component{}
+	var walk func([]Component)
+	walk = func(components []Component) {
+		for i := range components {
+			ret = append(ret, components[i])
+			walk(components[i].Components)
+		}
+	}
+	if bom.Metadata.Component != nil {
+		walk([]Component{*bom.Metadata.Component})
+	}
+	walk(bom.Components)
+	return ret
+}
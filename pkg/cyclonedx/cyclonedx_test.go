@@ -0,0 +1,37 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package cyclonedx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpen(t *testing.T) {
+	bom, err := Open("testdata/vex.json")
+	require.NoError(t, err)
+	require.Equal(t, "1.5", bom.SpecVersion)
+	require.Len(t, bom.Vulnerabilities, 2)
+	require.Equal(t, "code_not_reachable", bom.Vulnerabilities[0].Analysis.Justification)
+	require.Equal(t, "jackson", bom.Vulnerabilities[0].Affects[0].Ref)
+
+	components := bom.ListComponents()
+	require.Len(t, components, 2)
+	require.Equal(t, "pkg:maven/com.fasterxml.jackson.core/jackson-databind@2.10.0", components[1].Purl)
+
+	_, err = Open("testdata/non-existent.json")
+	require.Error(t, err)
+}
+
+func TestParse(t *testing.T) {
+	bom, err := Parse([]byte(`{"bomFormat":"CycloneDX","specVersion":"1.5","vulnerabilities":[{"id":"CVE-2023-1234"}]}`))
+	require.NoError(t, err)
+	require.Len(t, bom.Vulnerabilities, 1)
+
+	_, err = Parse([]byte(`{"bomFormat":"SPDX"}`))
+	require.Error(t, err)
+	_, err = Parse([]byte(`not json`))
+	require.Error(t, err)
+}
@@ -0,0 +1,10 @@
+/*
+Copyright 2023 The OpenVEX Authors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package cyclonedx provides a library to read the vulnerability data in
+// CycloneDX VEX documents (JSON format).
+//
+// https://cyclonedx.org/docs/1.5/json/#vulnerabilities
+package cyclonedx
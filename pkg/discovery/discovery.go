@@ -0,0 +1,330 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package discovery looks up the VEX documents published for a package. A
+// package URL is mapped to the locations where its documents are expected
+// to be found (the well-known path of its source repository, VEX Hub style
+// repositories and, for container images, the OCI registry) and the
+// documents found in them are fetched.
+package discovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/package-url/packageurl-go"
+
+	"github.com/openvex/go-vex/pkg/fetch"
+	"github.com/openvex/go-vex/pkg/oci"
+	"github.com/openvex/go-vex/pkg/tracing"
+	"github.com/openvex/go-vex/pkg/vex"
+)
+
+// DefaultWellKnownPath is the path, relative to the root of a source
+// repository, where its VEX document is looked up.
+const DefaultWellKnownPath = ".well-known/openvex.json"
+
+// DefaultRepositories are the VEX Hub style repositories searched when no
+// others are set with WithRepositories.
+var DefaultRepositories = []string{
+	"https://raw.githubusercontent.com/aquasecurity/vexhub/main",
+}
+
+// LocationType is the kind of place where a document is looked up.
+type LocationType string
+
+const (
+	// LocationWellKnown is the well-known path of the package's source
+	// repository.
+	LocationWellKnown LocationType = "well-known"
+
+	// LocationRepository is the package directory of a VEX Hub style
+	// repository, where documents are stored under
+	// pkg/<type>/<namespace>/<name>/<subpath>/vex.json.
+	LocationRepository LocationType = "repository"
+
+	// LocationOCI is an image in an OCI registry, the documents are the ones
+	// attached to it (see oci.Discover).
+	LocationOCI LocationType = "oci"
+)
+
+// Location is a candidate location of the VEX documents of a package.
+type Location struct {
+	// Type is the kind of location.
+	Type LocationType
+
+	// URL is the URL of the document or, for OCI locations, the image
+	// reference.
+	URL string
+}
+
+// Option configures the discovery.
+type Option func(*options)
+
+// options holds the configuration set by the Option functions.
+type options struct {
+	repositories  []string
+	wellKnownPath string
+	fetchOptions  []fetch.Option
+	discoverer    oci.Discoverer
+	github        bool
+	githubAPIURL  string
+	githubToken   string
+}
+
+// WithRepositories sets the base URLs of the VEX Hub style repositories
+// searched, replacing DefaultRepositories. Calling it without URLs disables
+// the repository lookups.
+func WithRepositories(urls ...string) Option {
+	return func(o *options) { o.repositories = urls }
+}
+
+// WithWellKnownPath sets the path looked up in the source repositories. An
+// empty path disables the well-known lookups.
+func WithWellKnownPath(path string) Option {
+	return func(o *options) { o.wellKnownPath = path }
+}
+
+// WithFetchOptions configures the fetcher used to download the documents,
+// eg to set a cache directory or authenticate the requests.
+func WithFetchOptions(opts ...fetch.Option) Option {
+	return func(o *options) { o.fetchOptions = append(o.fetchOptions, opts...) }
+}
+
+// WithDiscoverer looks up the documents of OCI images with the discoverer
+// instead of oci.Discover.
+func WithDiscoverer(d oci.Discoverer) Option {
+	return func(o *options) { o.discoverer = d }
+}
+
+// WithGitHub also looks up the documents published in the GitHub
+// repository of the package, see FromGitHub.
+func WithGitHub() Option {
+	return func(o *options) { o.github = true }
+}
+
+// WithGitHubAPIURL sets the base URL of the GitHub API, eg to use a GitHub
+// Enterprise Server. It defaults to DefaultGitHubAPIURL.
+func WithGitHubAPIURL(apiURL string) Option {
+	return func(o *options) { o.githubAPIURL = apiURL }
+}
+
+// WithGitHubToken authenticates the GitHub API requests with the token.
+// The token is not sent to any other host.
+func WithGitHubToken(token string) Option {
+	return func(o *options) { o.githubToken = token }
+}
+
+// newOptions returns the options with the defaults applied.
+func newOptions(opts []Option) *options {
+	o := &options{
+		repositories:  DefaultRepositories,
+		wellKnownPath: DefaultWellKnownPath,
+		discoverer:    oci.Discover,
+		githubAPIURL:  DefaultGitHubAPIURL,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// ForPurl looks up the VEX documents of the package in all the candidate
+// locations returned by Locations and returns the ones found. Locations
+// without documents are skipped. When some locations cannot be read, the
+// documents found in the others are returned along with an error joining
+// the failures.
+//
+// When the WithGitHub option is set, the documents published in the GitHub
+// repository of the package are returned too.
+func ForPurl(ctx context.Context, packageURL string, opts ...Option) (docs []*vex.VEX, err error) {
+	ctx, span := tracing.Start(ctx, "discovery.ForPurl", tracing.Attr("purl", packageURL))
+	defer func() { span.End(err) }()
+
+	p, err := packageurl.FromString(packageURL)
+	if err != nil {
+		return nil, fmt.Errorf("parsing package URL: %w", err)
+	}
+	o := newOptions(opts)
+	locations, err := locations(&p, o)
+	if err != nil {
+		return nil, err
+	}
+
+	fetcher := fetch.New(o.fetchOptions...)
+	docs = []*vex.VEX{}
+	errs := []error{}
+	for _, l := range locations {
+		switch l.Type {
+		case LocationOCI:
+			found, err := o.discoverer(ctx, l.URL)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("discovering documents of %s: %w", l.URL, err))
+				continue
+			}
+			docs = append(docs, found...)
+		default:
+			doc, err := fetcher.Fetch(ctx, l.URL)
+			if errors.Is(err, fetch.ErrNotFound) {
+				continue
+			}
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			docs = append(docs, doc)
+		}
+	}
+
+	if o.github {
+		if host, _ := sourceRepository(&p); host == "github.com" {
+			found, err := o.githubDocuments(ctx, &p)
+			if err != nil {
+				errs = append(errs, err)
+			}
+			docs = append(docs, found...)
+		}
+	}
+	return docs, errors.Join(errs...)
+}
+
+// Locations returns the candidate locations of the VEX documents of the
+// package, in the order they are looked up by ForPurl.
+func Locations(packageURL string, opts ...Option) ([]Location, error) {
+	p, err := packageurl.FromString(packageURL)
+	if err != nil {
+		return nil, fmt.Errorf("parsing package URL: %w", err)
+	}
+	return locations(&p, newOptions(opts))
+}
+
+// locations implements Locations with the options.
+func locations(p *packageurl.PackageURL, o *options) ([]Location, error) {
+	locations := []Location{}
+	if p.Type == packageurl.TypeOCI {
+		ref, err := imageReference(p)
+		if err != nil {
+			return nil, err
+		}
+		locations = append(locations, Location{Type: LocationOCI, URL: ref})
+	}
+
+	if o.wellKnownPath != "" {
+		if raw := rawFileURL(p, strings.TrimPrefix(o.wellKnownPath, "/")); raw != "" {
+			locations = append(locations, Location{Type: LocationWellKnown, URL: raw})
+		}
+	}
+
+	for _, base := range o.repositories {
+		locations = append(locations, Location{
+			Type: LocationRepository,
+			URL:  strings.TrimSuffix(base, "/") + "/" + repositoryPath(p),
+		})
+	}
+	return locations, nil
+}
+
+// repositoryPath returns the path of the document of the package in a VEX
+// Hub style repository.
+func repositoryPath(p *packageurl.PackageURL) string {
+	segments := []string{"pkg", p.Type}
+	for _, part := range []string{p.Namespace, p.Name, p.Subpath} {
+		for _, s := range strings.Split(part, "/") {
+			if s != "" {
+				segments = append(segments, url.PathEscape(s))
+			}
+		}
+	}
+	return strings.Join(append(segments, "vex.json"), "/")
+}
+
+// imageReference returns the image reference of an OCI package URL. The
+// image repository is read from the repository_url qualifier and defaults to
+// Docker Hub. The purl version is the image digest, when the purl has no
+// version the tag qualifier is used.
+func imageReference(p *packageurl.PackageURL) (string, error) {
+	qualifiers := p.Qualifiers.Map()
+	repo := qualifiers["repository_url"]
+	if repo == "" {
+		repo = "docker.io/library/" + p.Name
+	}
+	repo = strings.TrimPrefix(strings.TrimPrefix(repo, "https://"), "http://")
+
+	switch {
+	case p.Version != "":
+		return repo + "@" + p.Version, nil
+	case qualifiers["tag"] != "":
+		return repo + ":" + qualifiers["tag"], nil
+	default:
+		return "", errors.New("OCI package URL has no digest or tag")
+	}
+}
+
+// sourceRepository returns the host and path of the source repository of
+// the package or empty strings if it is not known. The repository is read
+// from the vcs_url qualifier or, for GitHub, GitLab, Bitbucket and Go
+// packages, from the purl itself.
+func sourceRepository(p *packageurl.PackageURL) (host, path string) {
+	if vcs := p.Qualifiers.Map()["vcs_url"]; vcs != "" {
+		_, vcs, _ = strings.Cut(vcs, "+") // git+https://...
+		if !strings.Contains(vcs, "://") {
+			vcs = "https://" + vcs
+		}
+		if u, err := url.Parse(vcs); err == nil {
+			path, _, _ = strings.Cut(strings.Trim(u.Path, "/"), "@")
+			return u.Host, strings.TrimSuffix(path, ".git")
+		}
+	}
+
+	switch p.Type {
+	case packageurl.TypeGithub:
+		return "github.com", p.Namespace + "/" + p.Name
+	case packageurl.TypeGitlab:
+		return "gitlab.com", p.Namespace + "/" + p.Name
+	case packageurl.TypeBitbucket:
+		return "bitbucket.org", p.Namespace + "/" + p.Name
+	case packageurl.TypeGolang:
+		host, path, _ := strings.Cut(p.Namespace+"/"+p.Name, "/")
+		return host, path
+	}
+	return "", ""
+}
+
+// splitRepository splits a repository path into the owner/name slug of the
+// repository and the directory inside it. The slug is empty if the path
+// does not have an owner and a name.
+func splitRepository(path string) (repo, dir string) {
+	parts := strings.SplitN(path, "/", 3)
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return "", ""
+	}
+	if len(parts) == 3 {
+		dir = parts[2]
+	}
+	return parts[0] + "/" + parts[1], dir
+}
+
+// rawFileURL returns the URL to download a file from the default branch of
+// the package's source repository or an empty string if the repository is
+// unknown or not hosted on a supported forge.
+func rawFileURL(p *packageurl.PackageURL, file string) string {
+	host, path := sourceRepository(p)
+	repo, _ := splitRepository(path)
+	if repo == "" {
+		return ""
+	}
+
+	switch host {
+	case "github.com":
+		return "https://raw.githubusercontent.com/" + repo + "/HEAD/" + file
+	case "gitlab.com":
+		return "https://gitlab.com/" + repo + "/-/raw/HEAD/" + file
+	case "bitbucket.org":
+		return "https://bitbucket.org/" + repo + "/raw/HEAD/" + file
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,171 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package discovery
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/openvex/go-vex/pkg/fetch"
+	"github.com/openvex/go-vex/pkg/vex"
+)
+
+func TestLocations(t *testing.T) {
+	for m, tc := range map[string]struct {
+		purl      string
+		opts      []Option
+		expected  []Location
+		shouldErr bool
+	}{
+		"github": {
+			purl: "pkg:github/openvex/vexctl@v0.2.0",
+			expected: []Location{
+				{Type: LocationWellKnown, URL: "https://raw.githubusercontent.com/openvex/vexctl/HEAD/.well-known/openvex.json"},
+				{Type: LocationRepository, URL: "https://raw.githubusercontent.com/aquasecurity/vexhub/main/pkg/github/openvex/vexctl/vex.json"},
+			},
+		},
+		"golang submodule": {
+			purl: "pkg:golang/github.com/openvex/go-vex/pkg@v0.2.5",
+			opts: []Option{WithRepositories("https://hub.example.com/")},
+			expected: []Location{
+				{Type: LocationWellKnown, URL: "https://raw.githubusercontent.com/openvex/go-vex/HEAD/.well-known/openvex.json"},
+				{Type: LocationRepository, URL: "https://hub.example.com/pkg/golang/github.com/openvex/go-vex/pkg/vex.json"},
+			},
+		},
+		"vcs_url qualifier": {
+			purl: "pkg:npm/%40scope/name@1.0.0?vcs_url=git%2Bhttps://gitlab.com/group/project.git",
+			opts: []Option{WithRepositories("https://hub.example.com")},
+			expected: []Location{
+				{Type: LocationWellKnown, URL: "https://gitlab.com/group/project/-/raw/HEAD/.well-known/openvex.json"},
+				{Type: LocationRepository, URL: "https://hub.example.com/pkg/npm/@scope/name/vex.json"},
+			},
+		},
+		"no source repository": {
+			purl: "pkg:pypi/requests@2.31.0",
+			opts: []Option{WithRepositories("https://hub.example.com")},
+			expected: []Location{
+				{Type: LocationRepository, URL: "https://hub.example.com/pkg/pypi/requests/vex.json"},
+			},
+		},
+		"oci with repository": {
+			purl: "pkg:oci/vexctl@sha256%3Aabcd?repository_url=ghcr.io/openvex/vexctl",
+			opts: []Option{WithRepositories()},
+			expected: []Location{
+				{Type: LocationOCI, URL: "ghcr.io/openvex/vexctl@sha256:abcd"},
+			},
+		},
+		"oci tag on docker hub": {
+			purl: "pkg:oci/debian?tag=bookworm",
+			opts: []Option{WithRepositories()},
+			expected: []Location{
+				{Type: LocationOCI, URL: "docker.io/library/debian:bookworm"},
+			},
+		},
+		"well-known disabled": {
+			purl:     "pkg:github/openvex/vexctl",
+			opts:     []Option{WithRepositories(), WithWellKnownPath("")},
+			expected: []Location{},
+		},
+		"oci without version": {
+			purl:      "pkg:oci/debian",
+			shouldErr: true,
+		},
+		"invalid purl": {
+			purl:      "openvex/vexctl",
+			shouldErr: true,
+		},
+	} {
+		locations, err := Locations(tc.purl, tc.opts...)
+		if tc.shouldErr {
+			require.Error(t, err, m)
+			continue
+		}
+		require.NoError(t, err, m)
+		require.Equal(t, tc.expected, locations, m)
+	}
+}
+
+// rewriteTransport sends all requests to a test server, keeping the
+// original host in the path.
+type rewriteTransport struct {
+	target *url.URL
+}
+
+func (rt rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Path = "/" + req.URL.Host + req.URL.Path
+	req.URL.Scheme, req.URL.Host = rt.target.Scheme, rt.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestForPurl(t *testing.T) {
+	data, err := os.ReadFile("../fetch/testdata/openvex.json")
+	require.NoError(t, err)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/raw.githubusercontent.com/openvex/vexctl/HEAD/.well-known/openvex.json", func(w http.ResponseWriter, _ *http.Request) {
+		w.Write(data) //nolint:errcheck
+	})
+	mux.HandleFunc("/hub.example.com/pkg/github/openvex/vexctl/vex.json", func(w http.ResponseWriter, _ *http.Request) {
+		w.Write(data) //nolint:errcheck
+	})
+	mux.HandleFunc("/broken.example.com/", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	target, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+	transport := WithFetchOptions(fetch.WithTransport(rewriteTransport{target: target}))
+
+	// Found in the well-known path and the repository
+	docs, err := ForPurl(context.Background(), "pkg:github/openvex/vexctl",
+		transport, WithRepositories("https://hub.example.com", "https://empty.example.com"),
+	)
+	require.NoError(t, err)
+	require.Len(t, docs, 2)
+
+	// Missing documents are skipped
+	docs, err = ForPurl(context.Background(), "pkg:pypi/requests",
+		transport, WithRepositories("https://empty.example.com"),
+	)
+	require.NoError(t, err)
+	require.Empty(t, docs)
+
+	// Failures are returned with the documents found
+	docs, err = ForPurl(context.Background(), "pkg:github/openvex/vexctl",
+		transport, WithRepositories("https://broken.example.com"),
+	)
+	require.Error(t, err)
+	require.Len(t, docs, 1)
+
+	// OCI images are looked up with the discoverer
+	var ref string
+	docs, err = ForPurl(context.Background(), "pkg:oci/vexctl@sha256%3Aabcd?repository_url=ghcr.io/openvex/vexctl",
+		transport, WithRepositories(),
+		WithDiscoverer(func(_ context.Context, r string) ([]*vex.VEX, error) {
+			ref = r
+			return []*vex.VEX{{}}, nil
+		}),
+	)
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	require.Equal(t, "ghcr.io/openvex/vexctl@sha256:abcd", ref)
+
+	_, err = ForPurl(context.Background(), "pkg:oci/vexctl@sha256%3Aabcd",
+		WithRepositories(),
+		WithDiscoverer(func(context.Context, string) ([]*vex.VEX, error) {
+			return nil, errors.New("registry unavailable")
+		}),
+	)
+	require.Error(t, err)
+}
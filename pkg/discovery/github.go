@@ -0,0 +1,367 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package discovery
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/package-url/packageurl-go"
+
+	"github.com/openvex/go-vex/pkg/fetch"
+	"github.com/openvex/go-vex/pkg/tracing"
+	"github.com/openvex/go-vex/pkg/vex"
+)
+
+// DefaultGitHubAPIURL is the base URL of the GitHub API.
+const DefaultGitHubAPIURL = "https://api.github.com"
+
+// GitHubDirectory is the directory of a repository where FromGitHub looks
+// up the published documents.
+const GitHubDirectory = ".vex"
+
+// githubRelease is the part of a GitHub release used to find documents.
+type githubRelease struct {
+	Assets []struct {
+		Name               string `json:"name"`
+		BrowserDownloadURL string `json:"browser_download_url"`
+	} `json:"assets"`
+}
+
+// githubContent is an entry of a directory listing of the contents API.
+type githubContent struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	DownloadURL string `json:"download_url"`
+}
+
+// githubAdvisory is a repository security advisory.
+type githubAdvisory struct {
+	GHSAID      string     `json:"ghsa_id"`
+	CVEID       string     `json:"cve_id"`
+	Summary     string     `json:"summary"`
+	PublishedAt *time.Time `json:"published_at"`
+	UpdatedAt   *time.Time `json:"updated_at"`
+	CVSS        *struct {
+		VectorString string  `json:"vector_string"`
+		Score        float64 `json:"score"`
+	} `json:"cvss"`
+	Vulnerabilities []struct {
+		Package struct {
+			Ecosystem string `json:"ecosystem"`
+			Name      string `json:"name"`
+		} `json:"package"`
+		VulnerableVersionRange string `json:"vulnerable_version_range"`
+		PatchedVersions        string `json:"patched_versions"`
+	} `json:"vulnerabilities"`
+}
+
+// FromGitHub returns the VEX documents published in the GitHub repository
+// of a pkg:github or pkg:golang package, or of a package with a GitHub
+// vcs_url qualifier. Documents are looked up in:
+//
+//   - the assets of the release tagged with the package version, or of the
+//     latest release when the purl has no version. Assets named vex.json,
+//     openvex.json or ending in .vex.json or .openvex.json are fetched.
+//   - the JSON files in the .vex directory of the repository, at the tag of
+//     the package version or in the default branch.
+//   - the published security advisories of the repository, returned as a
+//     document with an affected statement for each advisory that applies to
+//     the package version.
+//
+// As with ForPurl, documents found are returned along with an error joining
+// the lookups that failed.
+func FromGitHub(ctx context.Context, packageURL string, opts ...Option) (docs []*vex.VEX, err error) {
+	ctx, span := tracing.Start(ctx, "discovery.FromGitHub", tracing.Attr("purl", packageURL))
+	defer func() { span.End(err) }()
+
+	p, err := packageurl.FromString(packageURL)
+	if err != nil {
+		return nil, fmt.Errorf("parsing package URL: %w", err)
+	}
+	return newOptions(opts).githubDocuments(ctx, &p)
+}
+
+// githubClient looks up documents in a GitHub repository.
+type githubClient struct {
+	// api sends the API requests, files downloads the documents. Only the
+	// API requests carry the GitHub token.
+	api     *fetch.Fetcher
+	files   *fetch.Fetcher
+	baseURL string
+
+	// repo is the owner/name slug of the repository, dir the directory
+	// of the package in it.
+	repo string
+	dir  string
+}
+
+// githubDocuments implements FromGitHub with the options.
+func (o *options) githubDocuments(ctx context.Context, p *packageurl.PackageURL) ([]*vex.VEX, error) {
+	host, path := sourceRepository(p)
+	repo, dir := splitRepository(path)
+	if host != "github.com" || repo == "" {
+		return nil, fmt.Errorf("package %s is not hosted in GitHub", p.ToString())
+	}
+
+	apiOptions := append([]fetch.Option{}, o.fetchOptions...)
+	apiOptions = append(apiOptions,
+		fetch.WithHeader("Accept", "application/vnd.github+json"),
+		fetch.WithHeader("X-GitHub-Api-Version", "2022-11-28"),
+	)
+	if o.githubToken != "" {
+		apiOptions = append(apiOptions, fetch.WithBearerToken(o.githubToken))
+	}
+	gc := &githubClient{
+		api:     fetch.New(apiOptions...),
+		files:   fetch.New(o.fetchOptions...),
+		baseURL: strings.TrimSuffix(o.githubAPIURL, "/"),
+		repo:    repo,
+		dir:     dir,
+	}
+
+	docs := []*vex.VEX{}
+	errs := []error{}
+	for _, lookup := range []func(context.Context, *packageurl.PackageURL) ([]*vex.VEX, error){
+		gc.releaseDocuments, gc.directoryDocuments, gc.advisoryDocuments,
+	} {
+		found, err := lookup(ctx, p)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		docs = append(docs, found...)
+	}
+	return docs, errors.Join(errs...)
+}
+
+// tag returns the git tag of the package version. Go modules in a
+// subdirectory of the repository are tagged with the directory as prefix.
+func (gc *githubClient) tag(p *packageurl.PackageURL) string {
+	if p.Version == "" {
+		return ""
+	}
+	version := strings.TrimSuffix(p.Version, "+incompatible")
+	if p.Type == packageurl.TypeGolang && gc.dir != "" {
+		return gc.dir + "/" + version
+	}
+	return version
+}
+
+// get reads an API endpoint into v. It returns false if the endpoint
+// returned not found.
+func (gc *githubClient) get(ctx context.Context, endpoint string, v any) (bool, error) {
+	doc, err := gc.api.Get(ctx, gc.baseURL+"/repos/"+gc.repo+endpoint)
+	if errors.Is(err, fetch.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if err := json.Unmarshal(doc.Data, v); err != nil {
+		return false, fmt.Errorf("decoding GitHub API response: %w", err)
+	}
+	return true, nil
+}
+
+// fetchAll downloads and parses the documents at the URLs.
+func (gc *githubClient) fetchAll(ctx context.Context, urls []string) ([]*vex.VEX, error) {
+	docs := []*vex.VEX{}
+	errs := []error{}
+	for _, u := range urls {
+		doc, err := gc.files.Fetch(ctx, u)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		docs = append(docs, doc)
+	}
+	return docs, errors.Join(errs...)
+}
+
+// isVEXAsset returns true if a release asset name looks like a VEX
+// document.
+func isVEXAsset(name string) bool {
+	name = strings.ToLower(name)
+	return name == "vex.json" || name == "openvex.json" ||
+		strings.HasSuffix(name, ".vex.json") || strings.HasSuffix(name, ".openvex.json")
+}
+
+// releaseDocuments returns the documents attached to the release of the
+// package version.
+func (gc *githubClient) releaseDocuments(ctx context.Context, p *packageurl.PackageURL) ([]*vex.VEX, error) {
+	endpoint := "/releases/latest"
+	if tag := gc.tag(p); tag != "" {
+		endpoint = "/releases/tags/" + url.PathEscape(tag)
+	}
+
+	release := githubRelease{}
+	found, err := gc.get(ctx, endpoint, &release)
+	if err != nil {
+		return nil, fmt.Errorf("reading release of %s: %w", gc.repo, err)
+	}
+	if !found {
+		return nil, nil
+	}
+
+	urls := []string{}
+	for _, asset := range release.Assets {
+		if isVEXAsset(asset.Name) {
+			urls = append(urls, asset.BrowserDownloadURL)
+		}
+	}
+	return gc.fetchAll(ctx, urls)
+}
+
+// directoryDocuments returns the documents in the VEX directory of the
+// repository.
+func (gc *githubClient) directoryDocuments(ctx context.Context, p *packageurl.PackageURL) ([]*vex.VEX, error) {
+	endpoint := "/contents/" + GitHubDirectory
+	if tag := gc.tag(p); tag != "" {
+		endpoint += "?ref=" + url.QueryEscape(tag)
+	}
+
+	contents := []githubContent{}
+	found, err := gc.get(ctx, endpoint, &contents)
+	if err != nil {
+		return nil, fmt.Errorf("listing %s directory of %s: %w", GitHubDirectory, gc.repo, err)
+	}
+	if !found {
+		return nil, nil
+	}
+
+	urls := []string{}
+	for _, c := range contents {
+		if c.Type == "file" && strings.HasSuffix(strings.ToLower(c.Name), ".json") && c.DownloadURL != "" {
+			urls = append(urls, c.DownloadURL)
+		}
+	}
+	return gc.fetchAll(ctx, urls)
+}
+
+// advisoryDocuments returns a document with the published security
+// advisories of the repository that apply to the package.
+func (gc *githubClient) advisoryDocuments(ctx context.Context, p *packageurl.PackageURL) ([]*vex.VEX, error) {
+	advisories := []githubAdvisory{}
+	found, err := gc.get(ctx, "/security-advisories?state=published&per_page=100", &advisories)
+	if err != nil {
+		return nil, fmt.Errorf("reading security advisories of %s: %w", gc.repo, err)
+	}
+	if !found {
+		return nil, nil
+	}
+
+	doc := advisoriesDocument(gc.repo, p, advisories)
+	if doc == nil {
+		return nil, nil
+	}
+	return []*vex.VEX{doc}, nil
+}
+
+// advisoriesDocument converts the advisories of a repository into a VEX
+// document with an affected statement for each advisory applying to the
+// package. Advisories are matched by their vulnerable version range: when
+// the purl has a version, only the advisories affecting it are included,
+// otherwise the statement product carries the range in a vers qualifier. It
+// returns nil if no advisory applies. The document is timestamped with the
+// last update of its advisories.
+func advisoriesDocument(repo string, p *packageurl.PackageURL, advisories []githubAdvisory) *vex.VEX {
+	doc := vex.New()
+	doc.ID = "https://github.com/" + repo + "/security/advisories"
+	doc.Author = vex.Author{Name: "GitHub Security Advisories", URI: doc.ID}.String()
+
+	var updated *time.Time
+	for i := range advisories {
+		a := &advisories[i]
+		for _, v := range a.Vulnerabilities {
+			if p.Type == packageurl.TypeGolang && v.Package.Name != "" && v.Package.Name != p.Namespace+"/"+p.Name {
+				continue
+			}
+			product, ok := advisoryProduct(p, v.VulnerableVersionRange)
+			if !ok {
+				continue
+			}
+
+			stmt := vex.Statement{
+				Vulnerability: vex.Vulnerability{
+					Name:        vex.VulnerabilityID(a.GHSAID),
+					Description: a.Summary,
+				},
+				Timestamp: a.PublishedAt,
+				Products:  []vex.Product{{Component: vex.Component{ID: product}}},
+				Status:    vex.StatusAffected,
+			}
+			if a.CVEID != "" {
+				stmt.Vulnerability.Aliases = []vex.VulnerabilityID{vex.VulnerabilityID(a.CVEID)}
+			}
+			if a.CVSS != nil && a.CVSS.VectorString != "" {
+				if cvss, err := vex.ParseCVSS(a.CVSS.VectorString); err == nil {
+					stmt.Vulnerability.Ratings = []vex.Rating{{
+						Method:   cvss.Method(),
+						Vector:   a.CVSS.VectorString,
+						Score:    a.CVSS.Score,
+						Severity: vex.SeverityFromScore(cvss.Method(), a.CVSS.Score),
+						Source:   "GitHub",
+					}}
+				}
+			}
+			if v.PatchedVersions != "" {
+				stmt.Remediations = []vex.Remediation{{
+					Category: vex.RemediationVendorFix,
+					Details:  "Update to " + v.PatchedVersions,
+				}}
+			} else {
+				stmt.Remediations = []vex.Remediation{{Category: vex.RemediationNoneAvailable}}
+			}
+			stmt.ActionStatement = vex.RemediationsActionStatement(stmt.Remediations)
+
+			if a.UpdatedAt != nil && (updated == nil || a.UpdatedAt.After(*updated)) {
+				updated = a.UpdatedAt
+			}
+			doc.Statements = append(doc.Statements, stmt)
+		}
+	}
+
+	if len(doc.Statements) == 0 {
+		return nil
+	}
+	if updated != nil {
+		doc.Timestamp = updated
+	}
+	return &doc
+}
+
+// advisoryProduct returns the product ID of the package for a GitHub
+// vulnerable version range (eg ">= 1.0.0, < 1.2.3"). It returns false when
+// the purl has a version outside of the range.
+func advisoryProduct(p *packageurl.PackageURL, versionRange string) (string, bool) {
+	constraints := []string{}
+	for _, c := range strings.Split(versionRange, ",") {
+		if c = strings.ReplaceAll(c, " ", ""); c != "" {
+			constraints = append(constraints, c)
+		}
+	}
+	if len(constraints) == 0 {
+		return p.ToString(), true
+	}
+	vers := "vers:" + p.Type + "/" + strings.Join(constraints, "|")
+
+	if p.Version != "" {
+		vr, err := vex.ParseVersionRange(vers)
+		if err != nil || !vr.Contains(p.Version) {
+			return "", false
+		}
+		return p.ToString(), true
+	}
+
+	qualifiers := p.Qualifiers.Map()
+	qualifiers[vex.VersQualifier] = vers
+	return packageurl.NewPackageURL(
+		p.Type, p.Namespace, p.Name, "", packageurl.QualifiersFromMap(qualifiers), p.Subpath,
+	).ToString(), true
+}
@@ -0,0 +1,154 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package discovery
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/package-url/packageurl-go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/openvex/go-vex/pkg/fetch"
+	"github.com/openvex/go-vex/pkg/vex"
+)
+
+const testAdvisories = `[{
+	"ghsa_id": "GHSA-aaaa-bbbb-cccc",
+	"cve_id": "CVE-2023-1234",
+	"summary": "Bad things happen",
+	"published_at": "2023-06-01T00:00:00Z",
+	"updated_at": "2023-06-02T00:00:00Z",
+	"cvss": {"vector_string": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", "score": 9.8},
+	"vulnerabilities": [{
+		"package": {"ecosystem": "go", "name": "github.com/openvex/go-vex"},
+		"vulnerable_version_range": ">= 0.2.0, < 0.2.5",
+		"patched_versions": "0.2.5"
+	}]
+}, {
+	"ghsa_id": "GHSA-dddd-eeee-ffff",
+	"summary": "Other module",
+	"vulnerabilities": [{
+		"package": {"ecosystem": "go", "name": "github.com/openvex/go-vex/other"},
+		"vulnerable_version_range": "< 1.0.0"
+	}]
+}]`
+
+func TestAdvisoriesDocument(t *testing.T) {
+	advisories := []githubAdvisory{}
+	require.NoError(t, json.Unmarshal([]byte(testAdvisories), &advisories))
+
+	for m, tc := range map[string]struct {
+		purl     string
+		affected bool
+		version  string
+		vers     string
+	}{
+		"affected version": {
+			purl:     "pkg:golang/github.com/openvex/go-vex@v0.2.1",
+			affected: true,
+			version:  "v0.2.1",
+		},
+		"fixed version": {
+			purl: "pkg:golang/github.com/openvex/go-vex@v0.2.5",
+		},
+		"no version": {
+			purl:     "pkg:golang/github.com/openvex/go-vex",
+			affected: true,
+			vers:     "vers:golang/>=0.2.0|<0.2.5",
+		},
+	} {
+		p, err := packageurl.FromString(tc.purl)
+		require.NoError(t, err, m)
+
+		doc := advisoriesDocument("openvex/go-vex", &p, advisories)
+		if !tc.affected {
+			require.Nil(t, doc, m)
+			continue
+		}
+		require.NotNil(t, doc, m)
+		require.Len(t, doc.Statements, 1, m)
+
+		stmt := &doc.Statements[0]
+		require.Equal(t, vex.StatusAffected, stmt.Status, m)
+		require.NoError(t, stmt.Validate(), m)
+		product, err := packageurl.FromString(stmt.Products[0].ID)
+		require.NoError(t, err, m)
+		require.Equal(t, tc.version, product.Version, m)
+		require.Equal(t, tc.vers, product.Qualifiers.Map()[vex.VersQualifier], m)
+		require.Equal(t, vex.VulnerabilityID("GHSA-aaaa-bbbb-cccc"), stmt.Vulnerability.Name, m)
+		require.Equal(t, []vex.VulnerabilityID{"CVE-2023-1234"}, stmt.Vulnerability.Aliases, m)
+		require.Len(t, stmt.Vulnerability.Ratings, 1, m)
+		require.Equal(t, vex.SeverityCritical, stmt.Vulnerability.Ratings[0].Severity, m)
+		require.Equal(t, "vendor_fix: Update to 0.2.5", stmt.ActionStatement, m)
+		require.Equal(t, "2023-06-02T00:00:00Z", doc.Timestamp.Format("2006-01-02T15:04:05Z07:00"), m)
+	}
+}
+
+func TestFromGitHub(t *testing.T) {
+	data, err := os.ReadFile("../fetch/testdata/openvex.json")
+	require.NoError(t, err)
+
+	var authorized []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api.example.com/repos/openvex/go-vex/releases/tags/v0.2.1", func(w http.ResponseWriter, r *http.Request) {
+		authorized = append(authorized, r.Header.Get("Authorization"))
+		w.Write([]byte(`{"assets": [
+			{"name": "go-vex.openvex.json", "browser_download_url": "https://github.com/openvex/go-vex/releases/download/v0.2.1/go-vex.openvex.json"},
+			{"name": "checksums.txt", "browser_download_url": "https://github.com/openvex/go-vex/releases/download/v0.2.1/checksums.txt"}
+		]}`)) //nolint:errcheck
+	})
+	mux.HandleFunc("/api.example.com/repos/openvex/go-vex/contents/.vex", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "v0.2.1", r.URL.Query().Get("ref"))
+		w.Write([]byte(`[
+			{"name": "go-vex.json", "type": "file", "download_url": "https://raw.githubusercontent.com/openvex/go-vex/v0.2.1/.vex/go-vex.json"},
+			{"name": "README.md", "type": "file", "download_url": "https://raw.githubusercontent.com/openvex/go-vex/v0.2.1/.vex/README.md"}
+		]`)) //nolint:errcheck
+	})
+	mux.HandleFunc("/api.example.com/repos/openvex/go-vex/security-advisories", func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte(testAdvisories)) //nolint:errcheck
+	})
+	mux.HandleFunc("/github.com/openvex/go-vex/releases/download/v0.2.1/go-vex.openvex.json", func(w http.ResponseWriter, r *http.Request) {
+		authorized = append(authorized, r.Header.Get("Authorization"))
+		w.Write(data) //nolint:errcheck
+	})
+	mux.HandleFunc("/raw.githubusercontent.com/openvex/go-vex/v0.2.1/.vex/go-vex.json", func(w http.ResponseWriter, _ *http.Request) {
+		w.Write(data) //nolint:errcheck
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	target, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+	opts := []Option{
+		WithFetchOptions(fetch.WithTransport(rewriteTransport{target: target})),
+		WithGitHubAPIURL("https://api.example.com"),
+		WithGitHubToken("secret"),
+	}
+
+	docs, err := FromGitHub(context.Background(), "pkg:golang/github.com/openvex/go-vex@v0.2.1", opts...)
+	require.NoError(t, err)
+	require.Len(t, docs, 3)
+	require.Equal(t, []string{"Bearer secret", ""}, authorized)
+
+	// ForPurl includes the GitHub documents when enabled
+	docs, err = ForPurl(context.Background(), "pkg:golang/github.com/openvex/go-vex@v0.2.1",
+		append(opts, WithGitHub(), WithRepositories())...,
+	)
+	require.NoError(t, err)
+	require.Len(t, docs, 3)
+
+	// Nothing published in the repository
+	docs, err = FromGitHub(context.Background(), "pkg:github/openvex/vexctl", opts...)
+	require.NoError(t, err)
+	require.Empty(t, docs)
+
+	_, err = FromGitHub(context.Background(), "pkg:npm/left-pad@1.0.0", opts...)
+	require.Error(t, err)
+}
@@ -0,0 +1,314 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package enrich
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openvex/go-vex/pkg/vex"
+)
+
+// VulnerabilityData is the information about a vulnerability returned by
+// a Source.
+type VulnerabilityData struct {
+	// Description is a short description of the vulnerability.
+	Description string
+
+	// Aliases lists other identifiers of the vulnerability.
+	Aliases []vex.VulnerabilityID
+
+	// Severity is the qualitative severity rating of the vulnerability.
+	Severity string
+
+	// CVSSVector is the CVSS vector string of the vulnerability.
+	CVSSVector string
+
+	// CVSSScore is the CVSS base score of the vulnerability, zero if it is
+	// not known.
+	CVSSScore float64
+}
+
+// Source is a database that can be queried for vulnerability information.
+type Source interface {
+	// Name returns a string identifying the source.
+	Name() string
+
+	// Fetch looks up a vulnerability by its identifier. If the source does not
+	// know about the vulnerability or does not handle its identifier type,
+	// Fetch returns nil and no error.
+	Fetch(ctx context.Context, id string) (*VulnerabilityData, error)
+}
+
+// Options controls the behavior of the enricher.
+type Options struct {
+	// Workers is the number of vulnerabilities looked up concurrently.
+	Workers int
+
+	// RequestsPerSecond limits the rate of queries sent to the sources. Zero
+	// disables rate limiting.
+	RequestsPerSecond float64
+}
+
+// DefaultOptions are the options used by New.
+var DefaultOptions = Options{
+	Workers:           4,
+	RequestsPerSecond: 5,
+}
+
+// Enricher fills missing vulnerability data in VEX documents by querying
+// a list of sources. It implements vex.Enricher.
+type Enricher struct {
+	Options Options
+	Sources []Source
+}
+
+// New returns an enricher that queries the specified sources in order.
+func New(sources ...Source) *Enricher {
+	return &Enricher{
+		Options: DefaultOptions,
+		Sources: sources,
+	}
+}
+
+// Enrichment records the data filled in a vulnerability and the sources
+// that provided it.
+type Enrichment struct {
+	vex.Enrichment
+
+	// Sources lists the names of the sources that provided data.
+	Sources []string
+}
+
+// Report captures the results of an enrichment run.
+type Report struct {
+	// Enriched lists the vulnerabilities that got new data.
+	Enriched []Enrichment
+
+	// Errors maps the vulnerabilities that could not be looked up to the
+	// errors returned by the sources.
+	Errors map[vex.VulnerabilityID]error
+}
+
+// Enrich fills the missing vulnerability data of the document like
+// vex.VEX.Enrich and reports the sources that provided the data of each
+// vulnerability and the ones that failed.
+func (e *Enricher) Enrich(ctx context.Context, doc *vex.VEX) (*Report, error) {
+	if len(e.Sources) == 0 {
+		return nil, errors.New("no enrichment sources defined")
+	}
+
+	// The errors of the sources are listed in the report
+	recorder := &recordingEnricher{enricher: e}
+	enrichments, _ := doc.Enrich(ctx, recorder)
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("enriching document: %w", err)
+	}
+
+	report := &Report{
+		Enriched: []Enrichment{},
+		Errors:   map[vex.VulnerabilityID]error{},
+	}
+	for _, enrichment := range enrichments {
+		report.Enriched = append(report.Enriched, Enrichment{
+			Enrichment: enrichment,
+			Sources:    recorder.results[enrichment.Vulnerability].sources,
+		})
+	}
+	for id, res := range recorder.results {
+		if len(res.errs) > 0 {
+			report.Errors[id] = errors.Join(res.errs...)
+		}
+	}
+	return report, nil
+}
+
+// LookupVulnerabilities queries the sources for the vulnerabilities. It
+// implements vex.Enricher. Descriptions and ratings are taken from the
+// first source that returns them while aliases from all sources are
+// combined. Vulnerabilities are looked up concurrently.
+func (e *Enricher) LookupVulnerabilities(ctx context.Context, names []vex.VulnerabilityID) (map[vex.VulnerabilityID]*vex.Vulnerability, error) {
+	if len(e.Sources) == 0 {
+		return nil, errors.New("no enrichment sources defined")
+	}
+	return vulnerabilities(e.lookupAll(ctx, names))
+}
+
+// recordingEnricher looks up vulnerabilities with the enricher and keeps the
+// results of the sources for the report.
+type recordingEnricher struct {
+	enricher *Enricher
+	results  map[vex.VulnerabilityID]*lookup
+}
+
+func (re *recordingEnricher) LookupVulnerabilities(ctx context.Context, names []vex.VulnerabilityID) (map[vex.VulnerabilityID]*vex.Vulnerability, error) {
+	re.results = re.enricher.lookupAll(ctx, names)
+	return vulnerabilities(re.results)
+}
+
+// lookupAll queries the sources for the vulnerabilities concurrently.
+func (e *Enricher) lookupAll(ctx context.Context, names []vex.VulnerabilityID) map[vex.VulnerabilityID]*lookup {
+	lim := newLimiter(e.Options.RequestsPerSecond)
+	defer lim.stop()
+
+	workers := e.Options.Workers
+	if workers < 1 {
+		workers = 1
+	}
+
+	var mtx sync.Mutex
+	var wg sync.WaitGroup
+	results := map[vex.VulnerabilityID]*lookup{}
+	ch := make(chan vex.VulnerabilityID)
+
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range ch {
+				res := e.lookup(ctx, lim, string(id))
+				mtx.Lock()
+				results[id] = res
+				mtx.Unlock()
+			}
+		}()
+	}
+
+	for _, id := range names {
+		ch <- id
+	}
+	close(ch)
+	wg.Wait()
+	return results
+}
+
+// lookup is the combined data returned by the sources for a vulnerability.
+type lookup struct {
+	description string
+	aliases     []vex.VulnerabilityID
+	rating      *vex.Rating
+	sources     []string
+	errs        []error
+}
+
+// lookup queries the sources for a vulnerability.
+func (e *Enricher) lookup(ctx context.Context, lim *limiter, id string) *lookup {
+	res := &lookup{}
+	for _, s := range e.Sources {
+		if err := lim.wait(ctx); err != nil {
+			res.errs = append(res.errs, err)
+			return res
+		}
+
+		data, err := s.Fetch(ctx, id)
+		if err != nil {
+			res.errs = append(res.errs, fmt.Errorf("%s: %w", s.Name(), err))
+			continue
+		}
+		if data == nil {
+			continue
+		}
+
+		res.sources = append(res.sources, s.Name())
+		if res.description == "" {
+			res.description = data.Description
+		}
+		if res.rating == nil {
+			res.rating = rating(s.Name(), data)
+		}
+		res.aliases = append(res.aliases, data.Aliases...)
+	}
+	return res
+}
+
+// vulnerabilities returns the data of the looked up vulnerabilities and the
+// errors of the sources that failed, joined.
+func vulnerabilities(results map[vex.VulnerabilityID]*lookup) (map[vex.VulnerabilityID]*vex.Vulnerability, error) {
+	ret := map[vex.VulnerabilityID]*vex.Vulnerability{}
+	errs := []error{}
+	for id, res := range results {
+		if len(res.errs) > 0 {
+			errs = append(errs, fmt.Errorf("%s: %w", id, errors.Join(res.errs...)))
+		}
+		if len(res.sources) == 0 {
+			continue
+		}
+		v := &vex.Vulnerability{Name: id, Description: res.description}
+		for _, alias := range res.aliases {
+			if !containsID(v.Aliases, alias) {
+				v.Aliases = append(v.Aliases, alias)
+			}
+		}
+		if res.rating != nil {
+			v.Ratings = []vex.Rating{*res.rating}
+		}
+		ret[id] = v
+	}
+	return ret, errors.Join(errs...)
+}
+
+// rating returns the rating of the severity data returned by a source, nil
+// if there is none. Vectors that are not valid CVSS vectors are dropped.
+func rating(source string, data *VulnerabilityData) *vex.Rating {
+	r := &vex.Rating{
+		Method:   vex.RatingOther,
+		Score:    data.CVSSScore,
+		Severity: strings.ToLower(data.Severity),
+		Source:   source,
+	}
+	if cvss, err := vex.ParseCVSS(data.CVSSVector); err == nil {
+		r.Method, r.Vector = cvss.Method(), data.CVSSVector
+	}
+	if r.Vector == "" && r.Score == 0 && r.Severity == "" {
+		return nil
+	}
+	if r.Severity == "" && r.Score > 0 {
+		r.Severity = vex.SeverityFromScore(r.Method, r.Score)
+	}
+	return r
+}
+
+func containsID(list []vex.VulnerabilityID, id vex.VulnerabilityID) bool {
+	for _, i := range list {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
+
+// limiter spaces requests to the sources to honor the rate limit.
+type limiter struct {
+	ticker *time.Ticker
+}
+
+func newLimiter(rps float64) *limiter {
+	if rps <= 0 {
+		return &limiter{}
+	}
+	return &limiter{ticker: time.NewTicker(time.Duration(float64(time.Second) / rps))}
+}
+
+// wait blocks until the next request can be sent.
+func (l *limiter) wait(ctx context.Context) error {
+	if l.ticker == nil {
+		return ctx.Err()
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-l.ticker.C:
+		return nil
+	}
+}
+
+func (l *limiter) stop() {
+	if l.ticker != nil {
+		l.ticker.Stop()
+	}
+}
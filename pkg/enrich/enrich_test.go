@@ -0,0 +1,268 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package enrich
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/openvex/go-vex/pkg/vex"
+)
+
+type fakeSource struct {
+	name string
+	data map[string]*VulnerabilityData
+}
+
+func (fs *fakeSource) Name() string { return fs.name }
+
+func (fs *fakeSource) Fetch(_ context.Context, id string) (*VulnerabilityData, error) {
+	if id == "CVE-0000-0000" {
+		return nil, errors.New("source is down")
+	}
+	return fs.data[id], nil
+}
+
+func TestEnrich(t *testing.T) {
+	doc := &vex.VEX{
+		Statements: []vex.Statement{
+			{Vulnerability: vex.Vulnerability{Name: "CVE-2023-1255"}, Status: vex.StatusFixed},
+			{Vulnerability: vex.Vulnerability{Name: "CVE-2023-1255"}, Status: vex.StatusAffected},
+			{
+				Vulnerability: vex.Vulnerability{
+					Name: "CVE-2023-2650", Description: "Already here", Aliases: []vex.VulnerabilityID{"GHSA-1234"},
+				},
+				Status: vex.StatusFixed,
+			},
+			{Vulnerability: vex.Vulnerability{Name: "CVE-2023-9999"}, Status: vex.StatusFixed},
+			{Vulnerability: vex.Vulnerability{Name: "CVE-0000-0000"}, Status: vex.StatusFixed},
+		},
+	}
+
+	first := &fakeSource{name: "first", data: map[string]*VulnerabilityData{
+		"CVE-2023-1255": {Description: "Input buffer over-read in AES-XTS", Aliases: []vex.VulnerabilityID{"GHSA-aaaa"}},
+		"CVE-2023-2650": {Description: "Should not be used"},
+	}}
+	second := &fakeSource{name: "second", data: map[string]*VulnerabilityData{
+		"CVE-2023-1255": {Description: "Second description", Aliases: []vex.VulnerabilityID{"CVE-2023-1255", "DSA-1111"}},
+	}}
+
+	e := New(first, second)
+	e.Options.RequestsPerSecond = 0
+	report, err := e.Enrich(context.Background(), doc)
+	require.NoError(t, err)
+
+	require.Len(t, report.Enriched, 1)
+	require.Equal(t, vex.VulnerabilityID("CVE-2023-1255"), report.Enriched[0].Vulnerability)
+	require.True(t, report.Enriched[0].Description)
+	require.Equal(t, []string{"first", "second"}, report.Enriched[0].Sources)
+	require.Equal(t, []vex.VulnerabilityID{"GHSA-aaaa", "DSA-1111"}, report.Enriched[0].Aliases)
+
+	for _, i := range []int{0, 1} {
+		require.Equal(t, "Input buffer over-read in AES-XTS", doc.Statements[i].Vulnerability.Description)
+		require.Equal(t, []vex.VulnerabilityID{"GHSA-aaaa", "DSA-1111"}, doc.Statements[i].Vulnerability.Aliases)
+	}
+	require.Equal(t, "Already here", doc.Statements[2].Vulnerability.Description)
+	require.Empty(t, doc.Statements[3].Vulnerability.Description)
+
+	require.Len(t, report.Errors, 1)
+	require.Error(t, report.Errors["CVE-0000-0000"])
+
+	_, err = New().Enrich(context.Background(), doc)
+	require.Error(t, err)
+}
+
+func TestEnrichSeverity(t *testing.T) {
+	doc := &vex.VEX{
+		Statements: []vex.Statement{
+			{
+				Vulnerability: vex.Vulnerability{
+					Name: "CVE-2023-1255", Description: "Already here", Aliases: []vex.VulnerabilityID{"GHSA-aaaa"},
+				},
+				Status: vex.StatusFixed,
+			},
+			{
+				Vulnerability: vex.Vulnerability{
+					Name: "CVE-2023-2650", Description: "Already here", Aliases: []vex.VulnerabilityID{"GHSA-bbbb"},
+					Ratings: []vex.Rating{{Severity: vex.SeverityLow}},
+				},
+				Status: vex.StatusFixed,
+			},
+		},
+	}
+
+	source := &fakeSource{name: "source", data: map[string]*VulnerabilityData{
+		"CVE-2023-1255": {Severity: "MEDIUM", CVSSVector: "CVSS:3.1/AV:N/AC:H/PR:N/UI:N/S:U/C:N/I:N/A:H", CVSSScore: 5.9},
+		"CVE-2023-2650": {Severity: "HIGH"},
+	}}
+
+	e := New(source)
+	e.Options.RequestsPerSecond = 0
+	report, err := e.Enrich(context.Background(), doc)
+	require.NoError(t, err)
+
+	require.Len(t, report.Enriched, 1)
+	require.True(t, report.Enriched[0].Ratings)
+	require.False(t, report.Enriched[0].Description)
+	require.Equal(t, []vex.Rating{{
+		Method: vex.RatingCVSSv31, Vector: "CVSS:3.1/AV:N/AC:H/PR:N/UI:N/S:U/C:N/I:N/A:H",
+		Score: 5.9, Severity: vex.SeverityMedium, Source: "source",
+	}}, doc.Statements[0].Vulnerability.Ratings)
+	require.Empty(t, doc.Statements[0].Annotations)
+	require.Equal(t, []vex.Rating{{Severity: vex.SeverityLow}}, doc.Statements[1].Vulnerability.Ratings)
+
+	// The enricher completes documents through vex.VEX.Enrich
+	doc.Statements[0].Vulnerability.Ratings = nil
+	var enricher vex.Enricher = e
+	enrichments, err := doc.Enrich(context.Background(), enricher)
+	require.NoError(t, err)
+	require.Len(t, enrichments, 1)
+	require.Len(t, doc.Statements[0].Vulnerability.Ratings, 1)
+
+	// Severities without a score or vector are rated with the other method
+	require.Equal(t, &vex.Rating{Method: vex.RatingOther, Severity: vex.SeverityHigh, Source: "source"}, rating("source", &VulnerabilityData{Severity: "HIGH"}))
+	require.Nil(t, rating("source", &VulnerabilityData{Description: "No severity"}))
+}
+
+func TestSources(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/osv/CVE-2023-1255":
+			fmt.Fprint(w, `{"id":"CVE-2023-1255","summary":"OSV summary","aliases":["GHSA-aaaa"],`+
+				`"severity":[{"type":"CVSS_V3","score":"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:H"}],"database_specific":{"severity":"HIGH"}}`)
+		case "/nvd":
+			if r.URL.Query().Get("cveId") != "CVE-2023-1255" {
+				http.NotFound(w, r)
+				return
+			}
+			fmt.Fprint(w, `{"vulnerabilities":[{"cve":{"descriptions":[{"lang":"es","value":"Descripción"},{"lang":"en","value":"NVD description"}],`+
+				`"metrics":{"cvssMetricV31":[`+
+				`{"type":"Secondary","cvssData":{"vectorString":"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H","baseScore":9.8,"baseSeverity":"CRITICAL"}},`+
+				`{"type":"Primary","cvssData":{"vectorString":"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:H","baseScore":7.5,"baseSeverity":"HIGH"}}],`+
+				`"cvssMetricV2":[{"type":"Primary","cvssData":{"vectorString":"AV:N/AC:L/Au:N/C:N/I:N/A:P","baseScore":5.0},"baseSeverity":"MEDIUM"}]}}}]}`)
+		case "/ghsa/GHSA-aaaa":
+			require.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+			fmt.Fprint(w, `{"summary":"GHSA summary","severity":"high","identifiers":[{"type":"GHSA","value":"GHSA-aaaa"},{"type":"CVE","value":"CVE-2023-1255"}],`+
+				`"cvss":{"vector_string":"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:H","score":7.5}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+
+	osv := &OSV{URL: srv.URL + "/osv/"}
+	data, err := osv.Fetch(ctx, "CVE-2023-1255")
+	require.NoError(t, err)
+	require.Equal(t, "OSV summary", data.Description)
+	require.Equal(t, []vex.VulnerabilityID{"GHSA-aaaa"}, data.Aliases)
+	require.Equal(t, "HIGH", data.Severity)
+	require.Equal(t, "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:H", data.CVSSVector)
+
+	data, err = osv.Fetch(ctx, "CVE-2000-0001")
+	require.NoError(t, err)
+	require.Nil(t, data)
+
+	var resolver vex.AliasResolver = osv
+	aliases, err := resolver.Aliases(ctx, "CVE-2023-1255")
+	require.NoError(t, err)
+	require.Equal(t, []vex.VulnerabilityID{"GHSA-aaaa"}, aliases)
+
+	aliases, err = resolver.Aliases(ctx, "CVE-2000-0001")
+	require.NoError(t, err)
+	require.Empty(t, aliases)
+
+	nvd := &NVD{URL: srv.URL + "/nvd"}
+	data, err = nvd.Fetch(ctx, "CVE-2023-1255")
+	require.NoError(t, err)
+	require.Equal(t, "NVD description", data.Description)
+	require.Equal(t, "HIGH", data.Severity)
+	require.Equal(t, "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:H", data.CVSSVector)
+	require.InDelta(t, 7.5, data.CVSSScore, 0.001)
+
+	data, err = nvd.Fetch(ctx, "GHSA-aaaa")
+	require.NoError(t, err)
+	require.Nil(t, data)
+
+	ghsa := &GHSA{URL: srv.URL + "/ghsa/", Token: "token"}
+	data, err = ghsa.Fetch(ctx, "GHSA-aaaa")
+	require.NoError(t, err)
+	require.Equal(t, "GHSA summary", data.Description)
+	require.Equal(t, []vex.VulnerabilityID{"CVE-2023-1255"}, data.Aliases)
+	require.Equal(t, "high", data.Severity)
+	require.InDelta(t, 7.5, data.CVSSScore, 0.001)
+
+	bad := &OSV{URL: srv.URL + "/broken/"}
+	srv.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	_, err = bad.Fetch(ctx, "CVE-2023-1255")
+	require.Error(t, err)
+}
+
+func TestFeeds(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/osv/query":
+			require.Equal(t, http.MethodPost, r.Method)
+			req := struct {
+				Package struct {
+					Purl string `json:"purl"`
+				} `json:"package"`
+				PageToken string `json:"page_token"`
+			}{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			if req.Package.Purl == "pkg:golang/example.com/lib@v1.0.0" {
+				if req.PageToken == "" {
+					fmt.Fprint(w, `{"vulns":[{"id":"GO-2023-0001","aliases":["CVE-2023-0001","GHSA-aaaa"]}],"next_page_token":"2"}`)
+					return
+				}
+				require.Equal(t, "2", req.PageToken)
+				fmt.Fprint(w, `{"vulns":[{"id":"GHSA-aaaa","aliases":["GO-2023-0001"]},{"id":"GO-2023-0002"}]}`)
+				return
+			}
+			fmt.Fprint(w, `{}`)
+		case "/nvd":
+			if r.URL.Query().Get("cpeName") != "cpe:2.3:a:example:app:1.0:*:*:*:*:*:*:*" {
+				fmt.Fprint(w, `{"vulnerabilities":[]}`)
+				return
+			}
+			fmt.Fprint(w, `{"vulnerabilities":[{"cve":{"id":"CVE-2023-1255"}}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	identifiers := []string{
+		"pkg:golang/example.com/lib@v1.0.0", "pkg:golang/example.com/other@v1.0.0",
+		"cpe:2.3:a:example:app:1.0:*:*:*:*:*:*:*", "sha256:abc",
+	}
+
+	var feed vex.Feed = &OSV{QueryURL: srv.URL + "/osv/query"}
+	vulns, err := feed.Vulnerabilities(ctx, identifiers)
+	require.NoError(t, err)
+	require.Equal(t, []vex.VulnerabilityID{"GO-2023-0001", "CVE-2023-0001", "GHSA-aaaa", "GO-2023-0002"}, vulns)
+
+	feed = &NVD{URL: srv.URL + "/nvd"}
+	vulns, err = feed.Vulnerabilities(ctx, identifiers)
+	require.NoError(t, err)
+	require.Equal(t, []vex.VulnerabilityID{"CVE-2023-1255"}, vulns)
+
+	feed = &OSV{QueryURL: srv.URL + "/broken"}
+	srv.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	_, err = feed.Vulnerabilities(ctx, identifiers)
+	require.Error(t, err)
+}
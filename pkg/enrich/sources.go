@@ -0,0 +1,392 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package enrich
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/openvex/go-vex/pkg/scheduler"
+	"github.com/openvex/go-vex/pkg/tracing"
+	"github.com/openvex/go-vex/pkg/vex"
+)
+
+const (
+	// DefaultOSVURL is the endpoint of the OSV vulnerabilities API.
+	DefaultOSVURL = "https://api.osv.dev/v1/vulns/"
+
+	// DefaultOSVQueryURL is the endpoint of the OSV API that lists the
+	// vulnerabilities affecting a package.
+	DefaultOSVQueryURL = "https://api.osv.dev/v1/query"
+
+	// DefaultNVDURL is the endpoint of the NVD CVE API 2.0.
+	DefaultNVDURL = "https://services.nvd.nist.gov/rest/json/cves/2.0"
+
+	// DefaultGHSAURL is the endpoint of the GitHub global advisories API.
+	DefaultGHSAURL = "https://api.github.com/advisories/"
+)
+
+// getJSON fetches a URL and decodes the JSON response into v. It returns
+// false if the server responds with 404.
+func getJSON(ctx context.Context, client *http.Client, u string, headers map[string]string, v any) (found bool, err error) {
+	return requestJSON(ctx, client, http.MethodGet, u, headers, nil, v)
+}
+
+// postJSON sends the JSON encoding of body to a URL and decodes the JSON
+// response into v. It returns false if the server responds with 404.
+func postJSON(ctx context.Context, client *http.Client, u string, body, v any) (found bool, err error) {
+	return requestJSON(ctx, client, http.MethodPost, u, map[string]string{"Content-Type": "application/json"}, body, v)
+}
+
+// requestJSON sends a request with an optional JSON body and decodes the
+// JSON response into v. It returns false if the server responds with 404.
+func requestJSON(ctx context.Context, client *http.Client, method, u string, headers map[string]string, body, v any) (found bool, err error) {
+	ctx, span := tracing.Start(ctx, "enrich.Fetch", tracing.Attr("url", u))
+	defer func() { span.End(err) }()
+
+	var reqBody io.Reader = http.NoBody
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return false, fmt.Errorf("encoding request: %w", err)
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, reqBody)
+	if err != nil {
+		return false, fmt.Errorf("creating request: %w", err)
+	}
+	for k, val := range headers {
+		req.Header.Set(k, val)
+	}
+
+	if client == nil {
+		client = scheduler.Client()
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("querying %s: %w", u, err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("querying %s: http status %d", u, resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return false, fmt.Errorf("decoding response: %w", err)
+	}
+	return true, nil
+}
+
+// OSV is a source that looks up vulnerabilities in the OSV database. It is
+// also a vex.Feed listing the vulnerabilities of packages and a
+// vex.AliasResolver.
+type OSV struct {
+	URL      string
+	QueryURL string
+	Client   *http.Client
+}
+
+// NewOSV returns a source that queries the public OSV API.
+func NewOSV() *OSV {
+	return &OSV{URL: DefaultOSVURL, QueryURL: DefaultOSVQueryURL}
+}
+
+// Name returns the name of the source.
+func (*OSV) Name() string { return "osv" }
+
+// Fetch looks up the vulnerability in OSV. Any identifier type is supported.
+func (osv *OSV) Fetch(ctx context.Context, id string) (*VulnerabilityData, error) {
+	resp := struct {
+		Summary  string   `json:"summary"`
+		Details  string   `json:"details"`
+		Aliases  []string `json:"aliases"`
+		Severity []struct {
+			Type  string `json:"type"`
+			Score string `json:"score"`
+		} `json:"severity"`
+		DatabaseSpecific struct {
+			Severity string `json:"severity"`
+		} `json:"database_specific"`
+	}{}
+
+	found, err := getJSON(ctx, osv.Client, osv.URL+url.PathEscape(id), nil, &resp)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	data := &VulnerabilityData{Description: resp.Summary, Severity: resp.DatabaseSpecific.Severity}
+	if data.Description == "" {
+		data.Description = resp.Details
+	}
+	for _, sev := range resp.Severity {
+		if strings.HasPrefix(sev.Type, "CVSS_") {
+			data.CVSSVector = sev.Score
+			break
+		}
+	}
+	for _, a := range resp.Aliases {
+		data.Aliases = append(data.Aliases, vex.VulnerabilityID(a))
+	}
+	return data, nil
+}
+
+// Aliases returns the aliases OSV lists for the vulnerability, which link
+// CVEs, GHSAs and the advisory IDs of language and distribution databases.
+// Vulnerabilities unknown to OSV have no aliases.
+func (osv *OSV) Aliases(ctx context.Context, id vex.VulnerabilityID) ([]vex.VulnerabilityID, error) {
+	data, err := osv.Fetch(ctx, string(id))
+	if err != nil || data == nil {
+		return nil, err
+	}
+	return data.Aliases, nil
+}
+
+// Vulnerabilities returns the vulnerabilities OSV lists for the packages
+// identified by purls, with their aliases so they match statements using
+// any of the identifiers. Paginated results are followed to the last page.
+// Other identifiers are ignored.
+func (osv *OSV) Vulnerabilities(ctx context.Context, identifiers []string) ([]vex.VulnerabilityID, error) {
+	u := osv.QueryURL
+	if u == "" {
+		u = DefaultOSVQueryURL
+	}
+
+	ret := []vex.VulnerabilityID{}
+	seen := map[string]struct{}{}
+	add := func(id string) {
+		if _, ok := seen[id]; ok || id == "" {
+			return
+		}
+		seen[id] = struct{}{}
+		ret = append(ret, vex.VulnerabilityID(id))
+	}
+	for _, id := range identifiers {
+		if !strings.HasPrefix(id, "pkg:") {
+			continue
+		}
+		token := ""
+		for {
+			req := map[string]any{"package": map[string]string{"purl": id}}
+			if token != "" {
+				req["page_token"] = token
+			}
+			resp := struct {
+				Vulns []struct {
+					ID      string   `json:"id"`
+					Aliases []string `json:"aliases"`
+				} `json:"vulns"`
+				NextPageToken string `json:"next_page_token"`
+			}{}
+			if _, err := postJSON(ctx, osv.Client, u, req, &resp); err != nil {
+				return nil, err
+			}
+			for _, v := range resp.Vulns {
+				add(v.ID)
+				for _, a := range v.Aliases {
+					add(a)
+				}
+			}
+			if resp.NextPageToken == "" || resp.NextPageToken == token {
+				break
+			}
+			token = resp.NextPageToken
+		}
+	}
+	return ret, nil
+}
+
+// NVD is a source that looks up CVEs in the National Vulnerability Database.
+// It is also a vex.Feed listing the CVEs of products identified by CPEs.
+type NVD struct {
+	URL    string
+	APIKey string
+	Client *http.Client
+}
+
+// NewNVD returns a source that queries the public NVD API.
+func NewNVD() *NVD {
+	return &NVD{URL: DefaultNVDURL}
+}
+
+// Name returns the name of the source.
+func (*NVD) Name() string { return "nvd" }
+
+// Fetch looks up a CVE in the NVD. Identifiers other than CVEs are ignored.
+func (nvd *NVD) Fetch(ctx context.Context, id string) (*VulnerabilityData, error) {
+	if !strings.HasPrefix(strings.ToUpper(id), "CVE-") {
+		return nil, nil
+	}
+
+	resp := struct {
+		Vulnerabilities []struct {
+			CVE struct {
+				Descriptions []struct {
+					Lang  string `json:"lang"`
+					Value string `json:"value"`
+				} `json:"descriptions"`
+				Metrics map[string][]nvdMetric `json:"metrics"`
+			} `json:"cve"`
+		} `json:"vulnerabilities"`
+	}{}
+
+	headers := map[string]string{}
+	if nvd.APIKey != "" {
+		headers["apiKey"] = nvd.APIKey
+	}
+
+	found, err := getJSON(ctx, nvd.Client, nvd.URL+"?cveId="+url.QueryEscape(id), headers, &resp)
+	if err != nil || !found || len(resp.Vulnerabilities) == 0 {
+		return nil, err
+	}
+
+	cve := &resp.Vulnerabilities[0].CVE
+	data := &VulnerabilityData{}
+	for _, d := range cve.Descriptions {
+		if d.Lang == "en" {
+			data.Description = d.Value
+			break
+		}
+	}
+
+	// Use the newest CVSS version, preferring the primary metric
+	for _, version := range []string{"cvssMetricV40", "cvssMetricV31", "cvssMetricV30", "cvssMetricV2"} {
+		metrics := cve.Metrics[version]
+		if len(metrics) == 0 {
+			continue
+		}
+		m := metrics[0]
+		for i := range metrics {
+			if metrics[i].Type == "Primary" {
+				m = metrics[i]
+				break
+			}
+		}
+		data.CVSSVector = m.CVSSData.VectorString
+		data.CVSSScore = m.CVSSData.BaseScore
+		data.Severity = m.CVSSData.BaseSeverity
+		if data.Severity == "" {
+			// CVSS v2 metrics have the severity outside the CVSS data
+			data.Severity = m.BaseSeverity
+		}
+		break
+	}
+
+	if data.Description == "" && data.CVSSVector == "" {
+		return nil, nil
+	}
+	return data, nil
+}
+
+// nvdMetric is a CVSS metric in the NVD API responses.
+type nvdMetric struct {
+	Type     string `json:"type"`
+	CVSSData struct {
+		VectorString string  `json:"vectorString"`
+		BaseScore    float64 `json:"baseScore"`
+		BaseSeverity string  `json:"baseSeverity"`
+	} `json:"cvssData"`
+	BaseSeverity string `json:"baseSeverity"`
+}
+
+// Vulnerabilities returns the CVEs the NVD lists for the products identified
+// by CPE 2.3 names. Other identifiers are ignored.
+func (nvd *NVD) Vulnerabilities(ctx context.Context, identifiers []string) ([]vex.VulnerabilityID, error) {
+	headers := map[string]string{}
+	if nvd.APIKey != "" {
+		headers["apiKey"] = nvd.APIKey
+	}
+
+	ret := []vex.VulnerabilityID{}
+	for _, id := range identifiers {
+		if !strings.HasPrefix(strings.ToLower(id), "cpe:2.3:") {
+			continue
+		}
+		resp := struct {
+			Vulnerabilities []struct {
+				CVE struct {
+					ID string `json:"id"`
+				} `json:"cve"`
+			} `json:"vulnerabilities"`
+		}{}
+		if _, err := getJSON(ctx, nvd.Client, nvd.URL+"?cpeName="+url.QueryEscape(id), headers, &resp); err != nil {
+			return nil, err
+		}
+		for _, v := range resp.Vulnerabilities {
+			ret = append(ret, vex.VulnerabilityID(v.CVE.ID))
+		}
+	}
+	return ret, nil
+}
+
+// GHSA is a source that looks up GitHub security advisories.
+type GHSA struct {
+	URL    string
+	Token  string
+	Client *http.Client
+}
+
+// NewGHSA returns a source that queries the GitHub advisories API.
+func NewGHSA() *GHSA {
+	return &GHSA{URL: DefaultGHSAURL}
+}
+
+// Name returns the name of the source.
+func (*GHSA) Name() string { return "ghsa" }
+
+// Fetch looks up a GitHub advisory. Identifiers other than GHSA IDs are
+// ignored.
+func (ghsa *GHSA) Fetch(ctx context.Context, id string) (*VulnerabilityData, error) {
+	if !strings.HasPrefix(strings.ToUpper(id), "GHSA-") {
+		return nil, nil
+	}
+
+	resp := struct {
+		Summary     string `json:"summary"`
+		Severity    string `json:"severity"`
+		Identifiers []struct {
+			Value string `json:"value"`
+		} `json:"identifiers"`
+		CVSS struct {
+			VectorString string  `json:"vector_string"`
+			Score        float64 `json:"score"`
+		} `json:"cvss"`
+	}{}
+
+	headers := map[string]string{"Accept": "application/vnd.github+json"}
+	if ghsa.Token != "" {
+		headers["Authorization"] = "Bearer " + ghsa.Token
+	}
+
+	found, err := getJSON(ctx, ghsa.Client, ghsa.URL+url.PathEscape(id), headers, &resp)
+	if err != nil || !found {
+		return nil, err
+	}
+
+	data := &VulnerabilityData{
+		Description: resp.Summary,
+		Severity:    resp.Severity,
+		CVSSVector:  resp.CVSS.VectorString,
+		CVSSScore:   resp.CVSS.Score,
+	}
+	for _, i := range resp.Identifiers {
+		if !strings.EqualFold(i.Value, id) {
+			data.Aliases = append(data.Aliases, vex.VulnerabilityID(i.Value))
+		}
+	}
+	return data, nil
+}
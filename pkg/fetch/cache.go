@@ -0,0 +1,109 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package fetch
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// cache stores fetched documents on disk. Each document is kept in a file
+// named after the SHA-256 digest of its URL, next to a JSON file with its
+// validators.
+type cache struct {
+	dir string
+}
+
+// cacheEntry is the metadata stored with a cached document.
+type cacheEntry struct {
+	URL          string `json:"url"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
+// paths returns the paths of the data and metadata files of a URL.
+func (c *cache) paths(rawURL string) (data, meta string) {
+	sum := sha256.Sum256([]byte(rawURL))
+	name := hex.EncodeToString(sum[:])
+	return filepath.Join(c.dir, name), filepath.Join(c.dir, name+".json")
+}
+
+// get returns the cached document of the URL or nil if it is not cached.
+// Documents cached without validators cannot be revalidated and are
+// ignored.
+func (c *cache) get(rawURL string) (*Document, error) {
+	dataPath, metaPath := c.paths(rawURL)
+	meta, err := os.ReadFile(metaPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading cache entry: %w", err)
+	}
+
+	entry := cacheEntry{}
+	if err := json.Unmarshal(meta, &entry); err != nil || entry.URL != rawURL {
+		// Corrupt entries are ignored and overwritten by the next fetch
+		return nil, nil //nolint:nilerr
+	}
+	if entry.ETag == "" && entry.LastModified == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(dataPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading cached document: %w", err)
+	}
+	return &Document{URL: rawURL, Data: data, ETag: entry.ETag, LastModified: entry.LastModified}, nil
+}
+
+// put stores a document in the cache. Files are written to a temporary
+// file first and renamed so readers never see partial entries.
+func (c *cache) put(doc *Document) error {
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return fmt.Errorf("creating cache directory: %w", err)
+	}
+
+	meta, err := json.Marshal(cacheEntry{URL: doc.URL, ETag: doc.ETag, LastModified: doc.LastModified})
+	if err != nil {
+		return fmt.Errorf("marshaling cache entry: %w", err)
+	}
+
+	dataPath, metaPath := c.paths(doc.URL)
+	if err := writeFileAtomic(dataPath, doc.Data); err != nil {
+		return fmt.Errorf("writing cached document: %w", err)
+	}
+	if err := writeFileAtomic(metaPath, meta); err != nil {
+		return fmt.Errorf("writing cache entry: %w", err)
+	}
+	return nil
+}
+
+// writeFileAtomic writes the data to a temporary file in the directory of
+// path and renames it to path.
+func writeFileAtomic(path string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name()) //nolint:errcheck // Already renamed on success
+
+	if _, err := f.Write(data); err != nil {
+		f.Close() //nolint:errcheck,gosec
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
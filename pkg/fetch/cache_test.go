@@ -0,0 +1,106 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package fetch
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheRevalidation(t *testing.T) {
+	h := newVEXHandler(t)
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	dir := t.TempDir()
+	f := New(WithCacheDir(filepath.Join(dir, "cache")))
+	u := srv.URL + "/vex.json"
+
+	res, err := f.Get(context.Background(), u)
+	require.NoError(t, err)
+	require.False(t, res.Cached)
+
+	// The second fetch is conditional and served from the cache
+	res, err = f.Get(context.Background(), u)
+	require.NoError(t, err)
+	require.True(t, res.Cached)
+	require.Equal(t, h.data, res.Data)
+	require.Equal(t, int32(2), h.requests.Load())
+	require.Equal(t, int32(1), h.downloads.Load())
+
+	// The cache is shared by fetchers using the same directory
+	doc, err := New(WithCacheDir(filepath.Join(dir, "cache"))).Fetch(context.Background(), u)
+	require.NoError(t, err)
+	require.Len(t, doc.Statements, 1)
+	require.Equal(t, int32(1), h.downloads.Load())
+
+	// Changed documents are downloaded again
+	h.etag = `"v2"`
+	res, err = f.Get(context.Background(), u)
+	require.NoError(t, err)
+	require.False(t, res.Cached)
+	require.Equal(t, `"v2"`, res.ETag)
+	require.Equal(t, int32(2), h.downloads.Load())
+}
+
+func TestCacheLastModified(t *testing.T) {
+	lastModified := time.Date(2023, 7, 17, 0, 0, 0, 0, time.UTC).Format(http.TimeFormat)
+	data, err := os.ReadFile("testdata/openvex.json")
+	require.NoError(t, err)
+	downloads := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-Modified-Since") == lastModified {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		downloads++
+		w.Header().Set("Last-Modified", lastModified)
+		w.Write(data) //nolint:errcheck
+	}))
+	defer srv.Close()
+
+	f := New(WithCacheDir(t.TempDir()))
+	for range 3 {
+		_, err := f.Fetch(context.Background(), srv.URL)
+		require.NoError(t, err)
+	}
+	require.Equal(t, 1, downloads)
+}
+
+func TestCacheEntries(t *testing.T) {
+	c := &cache{dir: t.TempDir()}
+	doc, err := c.get("https://example.com/vex.json")
+	require.NoError(t, err)
+	require.Nil(t, doc)
+
+	// Documents without validators cannot be revalidated
+	require.NoError(t, c.put(&Document{URL: "https://example.com/vex.json", Data: []byte("{}")}))
+	doc, err = c.get("https://example.com/vex.json")
+	require.NoError(t, err)
+	require.Nil(t, doc)
+
+	require.NoError(t, c.put(&Document{URL: "https://example.com/vex.json", Data: []byte("{}"), ETag: `"abc"`}))
+	doc, err = c.get("https://example.com/vex.json")
+	require.NoError(t, err)
+	require.Equal(t, &Document{URL: "https://example.com/vex.json", Data: []byte("{}"), ETag: `"abc"`}, doc)
+
+	// Corrupt entries are ignored
+	_, meta := c.paths("https://example.com/vex.json")
+	require.NoError(t, os.WriteFile(meta, []byte("not json"), 0o600))
+	doc, err = c.get("https://example.com/vex.json")
+	require.NoError(t, err)
+	require.Nil(t, doc)
+
+	// No temporary files are left behind
+	entries, err := os.ReadDir(c.dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+}
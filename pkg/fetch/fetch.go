@@ -0,0 +1,239 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package fetch retrieves VEX documents published over HTTP(S). Responses
+// can be stored in an on-disk cache which is revalidated with conditional
+// requests (ETag and If-Modified-Since) so unchanged documents are not
+// downloaded again.
+package fetch
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/openvex/go-vex/pkg/scheduler"
+	"github.com/openvex/go-vex/pkg/tracing"
+	"github.com/openvex/go-vex/pkg/vex"
+)
+
+// DefaultMaxSize caps the size of the fetched documents when no other limit
+// is set with WithMaxSize.
+const DefaultMaxSize = 32 << 20
+
+// ErrNotFound is returned when the server does not have the document.
+var ErrNotFound = errors.New("document not found")
+
+// Option configures a Fetcher.
+type Option func(*options)
+
+// options holds the configuration set by the Option functions.
+type options struct {
+	cacheDir  string
+	transport http.RoundTripper
+	tlsConfig *tls.Config
+	headers   map[string]string
+	username  string
+	password  string
+	token     string
+	maxSize   int64
+}
+
+// WithCacheDir stores the fetched documents in the directory and revalidates
+// them on later fetches instead of downloading them again.
+func WithCacheDir(dir string) Option {
+	return func(o *options) { o.cacheDir = dir }
+}
+
+// WithTransport sends the requests with the transport. By default requests
+// go through the library scheduler.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(o *options) { o.transport = rt }
+}
+
+// WithTLSConfig connects to servers with the TLS configuration, eg to trust
+// a private CA or to authenticate with a client certificate. It is ignored
+// when a transport is set with WithTransport.
+func WithTLSConfig(cfg *tls.Config) Option {
+	return func(o *options) { o.tlsConfig = cfg }
+}
+
+// WithBasicAuth authenticates the requests with a username and password.
+func WithBasicAuth(username, password string) Option {
+	return func(o *options) { o.username, o.password = username, password }
+}
+
+// WithBearerToken authenticates the requests with a bearer token.
+func WithBearerToken(token string) Option {
+	return func(o *options) { o.token = token }
+}
+
+// WithHeader adds a header to all the requests.
+func WithHeader(key, value string) Option {
+	return func(o *options) {
+		if o.headers == nil {
+			o.headers = map[string]string{}
+		}
+		o.headers[key] = value
+	}
+}
+
+// WithMaxSize caps the size of the fetched documents.
+func WithMaxSize(size int64) Option {
+	return func(o *options) { o.maxSize = size }
+}
+
+// Fetcher downloads documents from HTTP(S) URLs.
+type Fetcher struct {
+	opts   options
+	client *http.Client
+	cache  *cache
+}
+
+// New returns a fetcher configured with the options.
+func New(opts ...Option) *Fetcher {
+	f := &Fetcher{opts: options{maxSize: DefaultMaxSize}}
+	for _, opt := range opts {
+		opt(&f.opts)
+	}
+
+	switch {
+	case f.opts.transport != nil:
+		f.client = &http.Client{Transport: f.opts.transport}
+	case f.opts.tlsConfig != nil:
+		t := http.DefaultTransport.(*http.Transport).Clone() //nolint:forcetypeassert // The default transport is always a *http.Transport
+		t.TLSClientConfig = f.opts.tlsConfig
+		f.client = &http.Client{Transport: t}
+	default:
+		f.client = scheduler.Client()
+	}
+
+	if f.opts.cacheDir != "" {
+		f.cache = &cache{dir: f.opts.cacheDir}
+	}
+	return f
+}
+
+// Document is a document fetched from a URL.
+type Document struct {
+	// URL is the URL the document was fetched from.
+	URL string
+
+	// Data is the contents of the document.
+	Data []byte
+
+	// ETag and LastModified are the validators returned by the server.
+	ETag         string
+	LastModified string
+
+	// Cached is true when the server reported that the cached copy of the
+	// document is still current and it was not downloaded again.
+	Cached bool
+}
+
+// Get downloads the document at the URL. When the fetcher has a cache and
+// holds a copy of the document, the request is conditional and the cached
+// copy is returned if the server reports it has not been modified.
+func (f *Fetcher) Get(ctx context.Context, rawURL string) (doc *Document, err error) {
+	ctx, span := tracing.Start(ctx, "fetch.Get", tracing.Attr("url", rawURL))
+	defer func() { span.End(err) }()
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("parsing URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("unsupported URL scheme %q", u.Scheme)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	for k, v := range f.opts.headers {
+		req.Header.Set(k, v)
+	}
+	switch {
+	case f.opts.token != "":
+		req.Header.Set("Authorization", "Bearer "+f.opts.token)
+	case f.opts.username != "" || f.opts.password != "":
+		req.SetBasicAuth(f.opts.username, f.opts.password)
+	}
+
+	var cached *Document
+	if f.cache != nil {
+		cached, err = f.cache.get(rawURL)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if cached != nil {
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+
+	res, err := f.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching %s: %w", rawURL, err)
+	}
+	defer res.Body.Close() //nolint:errcheck
+
+	switch {
+	case res.StatusCode == http.StatusNotModified && cached != nil:
+		cached.Cached = true
+		return cached, nil
+	case res.StatusCode == http.StatusNotFound:
+		return nil, fmt.Errorf("fetching %s: %w", rawURL, ErrNotFound)
+	case res.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("fetching %s: http status %d", rawURL, res.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(res.Body, f.opts.maxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", rawURL, err)
+	}
+	if int64(len(data)) > f.opts.maxSize {
+		return nil, fmt.Errorf("document at %s is larger than %d bytes", rawURL, f.opts.maxSize)
+	}
+
+	doc = &Document{
+		URL:          rawURL,
+		Data:         data,
+		ETag:         res.Header.Get("ETag"),
+		LastModified: res.Header.Get("Last-Modified"),
+	}
+	if f.cache != nil {
+		if err := f.cache.put(doc); err != nil {
+			return nil, err
+		}
+	}
+	return doc, nil
+}
+
+// Fetch downloads the document at the URL and parses it. OpenVEX, CSAF and
+// CycloneDX documents are supported, see vex.ParseAny.
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string) (*vex.VEX, error) {
+	doc, err := f.Get(ctx, rawURL)
+	if err != nil {
+		return nil, err
+	}
+	v, err := vex.ParseAny(doc.Data)
+	if err != nil {
+		return nil, fmt.Errorf("parsing document from %s: %w", rawURL, err)
+	}
+	return v, nil
+}
+
+// Fetch downloads and parses the document at the URL with a fetcher
+// configured with the options.
+func Fetch(ctx context.Context, rawURL string, opts ...Option) (*vex.VEX, error) {
+	return New(opts...).Fetch(ctx, rawURL)
+}
@@ -0,0 +1,158 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package fetch
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// vexHandler serves the test document with an ETag. It counts the requests
+// and the full responses sent.
+type vexHandler struct {
+	data      []byte
+	etag      string
+	requests  atomic.Int32
+	downloads atomic.Int32
+
+	// authorized checks the credentials of the requests
+	authorized func(r *http.Request) bool
+}
+
+func (h *vexHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.requests.Add(1)
+	switch r.URL.Path {
+	case "/vex.json":
+	case "/text":
+		w.Write([]byte("not a VEX document")) //nolint:errcheck
+		return
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	if h.authorized != nil && !h.authorized(r) {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	if h.etag != "" && r.Header.Get("If-None-Match") == h.etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	h.downloads.Add(1)
+	if h.etag != "" {
+		w.Header().Set("ETag", h.etag)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(h.data) //nolint:errcheck
+}
+
+func newVEXHandler(t *testing.T) *vexHandler {
+	t.Helper()
+	data, err := os.ReadFile("testdata/openvex.json")
+	require.NoError(t, err)
+	return &vexHandler{data: data, etag: `"v1"`}
+}
+
+func TestFetch(t *testing.T) {
+	h := newVEXHandler(t)
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	doc, err := Fetch(context.Background(), srv.URL+"/vex.json")
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com/vex/fetch-1", doc.ID)
+	require.Len(t, doc.Statements, 1)
+
+	// Without a cache, documents are always downloaded
+	res, err := New().Get(context.Background(), srv.URL+"/vex.json")
+	require.NoError(t, err)
+	require.False(t, res.Cached)
+	require.Equal(t, `"v1"`, res.ETag)
+	require.Equal(t, int32(2), h.downloads.Load())
+
+	_, err = Fetch(context.Background(), srv.URL+"/missing")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	for m, tc := range map[string]struct {
+		url  string
+		opts []Option
+	}{
+		"unsupported scheme": {url: "file:///etc/passwd"},
+		"invalid url":        {url: "http://[::1"},
+		"not found":          {url: srv.URL + "/missing"},
+		"too large":          {url: srv.URL + "/vex.json", opts: []Option{WithMaxSize(10)}},
+		"not a document":     {url: srv.URL + "/text"},
+	} {
+		t.Run(m, func(t *testing.T) {
+			_, err := Fetch(context.Background(), tc.url, tc.opts...)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestFetchAuthentication(t *testing.T) {
+	for m, tc := range map[string]struct {
+		opts       []Option
+		authorized func(r *http.Request) bool
+	}{
+		"basic auth": {
+			opts: []Option{WithBasicAuth("user", "s3cret")},
+			authorized: func(r *http.Request) bool {
+				user, pass, ok := r.BasicAuth()
+				return ok && user == "user" && pass == "s3cret"
+			},
+		},
+		"bearer token": {
+			opts:       []Option{WithBearerToken("t0ken")},
+			authorized: func(r *http.Request) bool { return r.Header.Get("Authorization") == "Bearer t0ken" },
+		},
+		"header": {
+			opts:       []Option{WithHeader("X-Api-Key", "k3y")},
+			authorized: func(r *http.Request) bool { return r.Header.Get("X-Api-Key") == "k3y" },
+		},
+	} {
+		t.Run(m, func(t *testing.T) {
+			h := newVEXHandler(t)
+			h.authorized = tc.authorized
+			srv := httptest.NewServer(h)
+			defer srv.Close()
+
+			_, err := Fetch(context.Background(), srv.URL+"/vex.json")
+			require.Error(t, err)
+
+			doc, err := Fetch(context.Background(), srv.URL+"/vex.json", tc.opts...)
+			require.NoError(t, err)
+			require.Len(t, doc.Statements, 1)
+		})
+	}
+}
+
+func TestFetchTLS(t *testing.T) {
+	h := newVEXHandler(t)
+	srv := httptest.NewTLSServer(h)
+	defer srv.Close()
+
+	// The test server certificate is not trusted by default
+	_, err := Fetch(context.Background(), srv.URL+"/vex.json")
+	require.Error(t, err)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(srv.Certificate())
+	doc, err := Fetch(context.Background(), srv.URL+"/vex.json", WithTLSConfig(&tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}))
+	require.NoError(t, err)
+	require.Len(t, doc.Statements, 1)
+
+	// Custom transports take precedence
+	doc, err = Fetch(context.Background(), srv.URL+"/vex.json", WithTransport(srv.Client().Transport))
+	require.NoError(t, err)
+	require.Len(t, doc.Statements, 1)
+}
@@ -0,0 +1,429 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package jsonschema validates JSON documents against the subset of the JSON
+// schema vocabulary used by the schemas embedded in go-vex.
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Violation is a value of a document that does not conform to the schema.
+type Violation struct {
+	// Pointer is the JSON pointer to the offending value
+	Pointer string
+
+	// Message describes the violation
+	Message string
+}
+
+// Schema is a parsed JSON schema. References are resolved within the
+// schema or, when they point to another document, against the schemas it
+// was parsed with, identified by their $id.
+type Schema struct {
+	root      map[string]any
+	resources map[string]map[string]any
+	mutex     sync.Mutex
+	patterns  map[string]*regexp.Regexp
+}
+
+// MustParse parses a JSON schema and the schemas it references and panics
+// if any of them is invalid or if a reference can't be resolved. It is
+// meant to parse the schemas embedded in the binary.
+func MustParse(name string, data []byte, resources ...[]byte) *Schema {
+	s := &Schema{resources: map[string]map[string]any{}, patterns: map[string]*regexp.Regexp{}}
+	for _, r := range append([][]byte{data}, resources...) {
+		doc := map[string]any{}
+		if err := json.Unmarshal(r, &doc); err != nil {
+			panic(fmt.Sprintf("parsing %s schema: %v", name, err))
+		}
+		if s.root == nil {
+			s.root = doc
+		}
+		if id, ok := doc["$id"].(string); ok {
+			s.resources[strings.TrimSuffix(id, "#")] = doc
+		}
+	}
+	for _, doc := range append([]map[string]any{s.root}, mapValues(s.resources)...) {
+		if err := s.checkRefs(doc, doc); err != nil {
+			panic(fmt.Sprintf("parsing %s schema: %v", name, err))
+		}
+	}
+	return s
+}
+
+// Decode parses a JSON document to be validated. Numbers are decoded as
+// json.Number.
+func Decode(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("parsing document: %w", err)
+	}
+	return doc, nil
+}
+
+// Validate returns the violations of the schema by a document decoded with
+// Decode.
+func (s *Schema) Validate(doc any) []Violation {
+	v := &validator{schema: s, root: s.root}
+	v.validate(s.root, doc, "")
+	return v.errs
+}
+
+// validator records the violations of a schema.
+type validator struct {
+	schema *Schema
+	errs   []Violation
+
+	// root is the schema document local references are resolved in
+	root map[string]any
+}
+
+// fail records a violation.
+func (v *validator) fail(pointer, format string, args ...any) {
+	v.errs = append(v.errs, Violation{Pointer: pointer, Message: fmt.Sprintf(format, args...)})
+}
+
+// valid returns true if the value conforms to the schema without recording
+// the violations.
+func (v *validator) valid(s map[string]any, value any, pointer string) bool {
+	sub := &validator{schema: v.schema, root: v.root}
+	sub.validate(s, value, pointer)
+	return len(sub.errs) == 0
+}
+
+// validate records the violations of the schema by the value.
+func (v *validator) validate(s map[string]any, value any, pointer string) {
+	if ref, ok := s["$ref"].(string); ok {
+		root, node, err := v.schema.resolveRef(v.root, ref)
+		if err != nil {
+			panic(err.Error()) // Checked by MustParse
+		}
+		prev := v.root
+		v.root = root
+		v.validate(node, value, pointer)
+		v.root = prev
+	}
+
+	if t, ok := s["type"].(string); ok && !typeMatches(t, value) {
+		v.fail(pointer, "must be of type %s", t)
+		return
+	}
+
+	if c, ok := s["const"]; ok && !equal(c, value) {
+		v.fail(pointer, "must be %v", c)
+	}
+	if enum, ok := s["enum"].([]any); ok {
+		if !slices.ContainsFunc(enum, func(e any) bool { return equal(e, value) }) {
+			v.fail(pointer, "must be one of %v", enum)
+		}
+	}
+
+	for _, sub := range schemaList(s["allOf"]) {
+		v.validate(sub, value, pointer)
+	}
+	if anyOf := schemaList(s["anyOf"]); len(anyOf) > 0 {
+		if !slices.ContainsFunc(anyOf, func(sub map[string]any) bool { return v.valid(sub, value, pointer) }) {
+			v.fail(pointer, "%s", describeAnyOf(anyOf))
+		}
+	}
+	if oneOf := schemaList(s["oneOf"]); len(oneOf) > 0 {
+		matches := 0
+		for _, sub := range oneOf {
+			if v.valid(sub, value, pointer) {
+				matches++
+			}
+		}
+		if matches != 1 {
+			v.fail(pointer, "must match exactly one of the allowed schemas")
+		}
+	}
+	if cond, ok := s["if"].(map[string]any); ok {
+		if v.valid(cond, value, pointer) {
+			if then, ok := s["then"].(map[string]any); ok {
+				v.validate(then, value, pointer)
+			}
+		} else if els, ok := s["else"].(map[string]any); ok {
+			v.validate(els, value, pointer)
+		}
+	}
+
+	switch val := value.(type) {
+	case map[string]any:
+		v.validateObject(s, val, pointer)
+	case []any:
+		v.validateArray(s, val, pointer)
+	case string:
+		v.validateString(s, val, pointer)
+	case json.Number:
+		if min, ok := s["minimum"].(float64); ok {
+			if n, err := val.Float64(); err == nil && n < min {
+				v.fail(pointer, "must be at least %v", min)
+			}
+		}
+		if max, ok := s["maximum"].(float64); ok {
+			if n, err := val.Float64(); err == nil && n > max {
+				v.fail(pointer, "must be at most %v", max)
+			}
+		}
+	}
+}
+
+// validateObject checks the object keywords of the schema.
+func (v *validator) validateObject(s map[string]any, obj map[string]any, pointer string) {
+	for _, r := range schemaStrings(s["required"]) {
+		if _, ok := obj[r]; !ok {
+			v.fail(pointer, "missing required property %q", r)
+		}
+	}
+	if min, ok := s["minProperties"].(float64); ok && float64(len(obj)) < min {
+		v.fail(pointer, "must have at least %v properties", min)
+	}
+
+	props, _ := s["properties"].(map[string]any) //nolint:errcheck // nil when not defined
+	keys := []string{}
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if ps, ok := props[k].(map[string]any); ok {
+			v.validate(ps, obj[k], pointer+"/"+EscapePointer(k))
+			continue
+		}
+		switch additional := s["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				v.fail(pointer+"/"+EscapePointer(k), "property is not allowed")
+			}
+		case map[string]any:
+			v.validate(additional, obj[k], pointer+"/"+EscapePointer(k))
+		}
+	}
+}
+
+// validateArray checks the array keywords of the schema.
+func (v *validator) validateArray(s map[string]any, arr []any, pointer string) {
+	if min, ok := s["minItems"].(float64); ok && float64(len(arr)) < min {
+		v.fail(pointer, "must have at least %v items", min)
+	}
+	if unique, ok := s["uniqueItems"].(bool); ok && unique {
+		for i := range arr {
+			for j := range i {
+				if reflect.DeepEqual(arr[i], arr[j]) {
+					v.fail(pointer+"/"+strconv.Itoa(i), "duplicates item %d", j)
+					break
+				}
+			}
+		}
+	}
+	if items, ok := s["items"].(map[string]any); ok {
+		for i := range arr {
+			v.validate(items, arr[i], pointer+"/"+strconv.Itoa(i))
+		}
+	}
+}
+
+// validateString checks the string keywords of the schema.
+func (v *validator) validateString(s map[string]any, str, pointer string) {
+	if min, ok := s["minLength"].(float64); ok && float64(len([]rune(str))) < min {
+		v.fail(pointer, "must be at least %v characters long", min)
+	}
+	if max, ok := s["maxLength"].(float64); ok && float64(len([]rune(str))) > max {
+		v.fail(pointer, "must be at most %v characters long", max)
+	}
+	if pattern, ok := s["pattern"].(string); ok && !v.schema.pattern(pattern).MatchString(str) {
+		v.fail(pointer, "must match the pattern %s", pattern)
+	}
+
+	switch s["format"] {
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339, str); err != nil {
+			v.fail(pointer, "must be a RFC 3339 date-time")
+		}
+	case "uri", "iri":
+		if u, err := url.Parse(str); err != nil || u.Scheme == "" {
+			v.fail(pointer, "must be an absolute %s", s["format"])
+		}
+	}
+}
+
+// pattern returns the compiled regular expression of a pattern keyword.
+func (s *Schema) pattern(pattern string) *regexp.Regexp {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	re, ok := s.patterns[pattern]
+	if !ok {
+		re = regexp.MustCompile(pattern)
+		s.patterns[pattern] = re
+	}
+	return re
+}
+
+// resolveRef returns the schema document and the definition referenced by
+// a $ref found in the root document. References to other documents are
+// looked up by their $id.
+func (s *Schema) resolveRef(root map[string]any, ref string) (doc, node map[string]any, err error) {
+	base, fragment, _ := strings.Cut(ref, "#")
+	doc = root
+	if base != "" {
+		if doc = s.resources[base]; doc == nil {
+			return nil, nil, fmt.Errorf("schema reference %q points to an unknown document", ref)
+		}
+	}
+
+	var n any = doc
+	if fragment = strings.TrimPrefix(fragment, "/"); fragment != "" {
+		for _, part := range strings.Split(fragment, "/") {
+			m, ok := n.(map[string]any)
+			if !ok {
+				return nil, nil, fmt.Errorf("invalid schema reference %q", ref)
+			}
+			n = m[strings.NewReplacer("~1", "/", "~0", "~").Replace(part)]
+		}
+	}
+	node, ok := n.(map[string]any)
+	if !ok {
+		return nil, nil, fmt.Errorf("invalid schema reference %q", ref)
+	}
+	return doc, node, nil
+}
+
+// checkRefs returns an error if a $ref in the node, part of the root
+// document, can't be resolved.
+func (s *Schema) checkRefs(root map[string]any, node any) error {
+	switch n := node.(type) {
+	case map[string]any:
+		if ref, ok := n["$ref"].(string); ok {
+			if _, _, err := s.resolveRef(root, ref); err != nil {
+				return err
+			}
+		}
+		for k, v := range n {
+			if k == "enum" || k == "const" {
+				continue
+			}
+			if err := s.checkRefs(root, v); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for _, item := range n {
+			if err := s.checkRefs(root, item); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// mapValues returns the values of a map of schemas sorted by their key.
+func mapValues(m map[string]map[string]any) []map[string]any {
+	keys := []string{}
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	ret := []map[string]any{}
+	for _, k := range keys {
+		ret = append(ret, m[k])
+	}
+	return ret
+}
+
+// typeMatches returns true if the value is of the JSON schema type.
+func typeMatches(t string, value any) bool {
+	switch t {
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "number":
+		_, ok := value.(json.Number)
+		return ok
+	case "integer":
+		n, ok := value.(json.Number)
+		if !ok {
+			return false
+		}
+		_, err := n.Int64()
+		return err == nil
+	default:
+		return value == nil
+	}
+}
+
+// equal compares a value from the schema with a value of the document,
+// which decodes numbers as json.Number.
+func equal(schemaValue, value any) bool {
+	if n, ok := value.(json.Number); ok {
+		f, err := n.Float64()
+		return err == nil && reflect.DeepEqual(schemaValue, f)
+	}
+	return reflect.DeepEqual(schemaValue, value)
+}
+
+// describeAnyOf returns the message of a value not matching any of the
+// schemas. Lists of required properties are described by their names.
+func describeAnyOf(schemas []map[string]any) string {
+	names := []string{}
+	for _, s := range schemas {
+		required := schemaStrings(s["required"])
+		if len(s) != 1 || len(required) != 1 {
+			return "must match at least one of the allowed schemas"
+		}
+		names = append(names, required[0])
+	}
+	return fmt.Sprintf("must have at least one of the properties [%s]", strings.Join(names, ", "))
+}
+
+// schemaList returns a list of subschemas.
+func schemaList(node any) []map[string]any {
+	list, _ := node.([]any) //nolint:errcheck // nil when not defined
+	ret := []map[string]any{}
+	for _, n := range list {
+		if m, ok := n.(map[string]any); ok {
+			ret = append(ret, m)
+		}
+	}
+	return ret
+}
+
+// schemaStrings returns a list of strings from the schema.
+func schemaStrings(node any) []string {
+	list, _ := node.([]any) //nolint:errcheck // nil when not defined
+	ret := []string{}
+	for _, n := range list {
+		if s, ok := n.(string); ok {
+			ret = append(ret, s)
+		}
+	}
+	return ret
+}
+
+// EscapePointer escapes a property name to be used in a JSON pointer.
+func EscapePointer(s string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(s)
+}
@@ -0,0 +1,98 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package jsonschema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	s := MustParse("test", []byte(`{
+		"type": "object",
+		"required": ["name"],
+		"properties": {
+			"name": {"$ref": "#/$defs/name"},
+			"count": {"type": "integer", "minimum": 1, "maximum": 10},
+			"kind": {"oneOf": [{"const": "a"}, {"const": "b"}]}
+		},
+		"additionalProperties": false,
+		"$defs": {
+			"name": {"type": "string", "pattern": "^[a-z]+$", "maxLength": 5}
+		}
+	}`))
+
+	for m, tc := range map[string]struct {
+		doc      string
+		pointers []string
+	}{
+		"valid":          {`{"name": "abc", "count": 3, "kind": "a"}`, []string{}},
+		"missing":        {`{}`, []string{""}},
+		"pattern":        {`{"name": "ABC"}`, []string{"/name"}},
+		"max length":     {`{"name": "abcdef"}`, []string{"/name"}},
+		"range":          {`{"name": "abc", "count": 11}`, []string{"/count"}},
+		"one of":         {`{"name": "abc", "kind": "c"}`, []string{"/kind"}},
+		"not allowed":    {`{"name": "abc", "a/b": true}`, []string{"/a~1b"}},
+		"type":           {`[]`, []string{""}},
+		"integer number": {`{"name": "abc", "count": 1.5}`, []string{"/count"}},
+	} {
+		doc, err := Decode([]byte(tc.doc))
+		require.NoError(t, err, m)
+		pointers := []string{}
+		for _, v := range s.Validate(doc) {
+			pointers = append(pointers, v.Pointer)
+		}
+		require.Equal(t, tc.pointers, pointers, m)
+	}
+
+	_, err := Decode([]byte(`{"name": `))
+	require.ErrorContains(t, err, "parsing document")
+}
+
+func TestExternalReferences(t *testing.T) {
+	score := []byte(`{
+		"$id": "https://example.com/score.json",
+		"type": "object",
+		"required": ["value"],
+		"properties": {"value": {"$ref": "#/definitions/value"}},
+		"definitions": {"value": {"type": "number", "minimum": 0, "maximum": 10}}
+	}`)
+	s := MustParse("test", []byte(`{
+		"type": "object",
+		"properties": {
+			"score": {"$ref": "https://example.com/score.json"},
+			"value": {"$ref": "https://example.com/score.json#/definitions/value"}
+		}
+	}`), score)
+
+	for m, tc := range map[string]struct {
+		doc      string
+		pointers []string
+	}{
+		"valid":        {`{"score": {"value": 5}, "value": 1}`, []string{}},
+		"document ref": {`{"score": {"value": 11}}`, []string{"/score/value"}},
+		"missing":      {`{"score": {}}`, []string{"/score"}},
+		"fragment ref": {`{"value": -1}`, []string{"/value"}},
+	} {
+		doc, err := Decode([]byte(tc.doc))
+		require.NoError(t, err, m)
+		pointers := []string{}
+		for _, v := range s.Validate(doc) {
+			pointers = append(pointers, v.Pointer)
+		}
+		require.Equal(t, tc.pointers, pointers, m)
+	}
+
+	require.Panics(t, func() {
+		MustParse("test", []byte(`{"properties": {"score": {"$ref": "https://example.com/score.json"}}}`))
+	})
+	require.Panics(t, func() {
+		MustParse("test", []byte(`{"properties": {"score": {"$ref": "#/$defs/missing"}}}`))
+	})
+}
+
+func TestEscapePointer(t *testing.T) {
+	require.Equal(t, "a~1b~0c", EscapePointer("a/b~c"))
+}
@@ -0,0 +1,311 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package oci
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/openvex/go-vex/pkg/tracing"
+	"github.com/openvex/go-vex/pkg/vex"
+)
+
+const (
+	// MediaTypeOpenVEX is the artifact type of the VEX documents attached
+	// to images and the media type of the layer holding the document.
+	MediaTypeOpenVEX = "application/vnd.openvex+json"
+
+	// MediaTypeEmptyJSON is the media type of the empty config blob of
+	// artifact manifests.
+	MediaTypeEmptyJSON = "application/vnd.oci.empty.v1+json"
+
+	// AnnotationCreated is the manifest annotation recording the creation
+	// time of the artifact.
+	AnnotationCreated = "org.opencontainers.image.created"
+)
+
+// emptyJSON is the contents of the empty config blob.
+var emptyJSON = []byte("{}")
+
+// AttachOptions control how VEX documents are attached to images.
+type AttachOptions struct {
+	// OS and Arch attach the document to the image built for the platform
+	// when the reference points to a multi-arch image index. When empty,
+	// the document is attached to the image, or index, the reference points
+	// to.
+	OS   string
+	Arch string
+
+	// Annotations are added to the artifact manifest
+	Annotations map[string]string
+
+	// DryRun resolves the image and builds the artifact without pushing
+	// anything to the registry. Attach returns the diff of the document
+	// against the version already attached to the image.
+	DryRun bool
+}
+
+// AttachResult is the outcome of attaching a document to an image.
+type AttachResult struct {
+	// Descriptor is the descriptor of the pushed artifact manifest or, in
+	// dry runs, of the manifest that would be pushed.
+	Descriptor Descriptor
+
+	// Diff is only set in dry runs. It compares the document with the
+	// latest version of the document with the same ID attached to the
+	// image. If there is none, all the statements are listed as added.
+	Diff *vex.DocumentDiff
+}
+
+// artifactManifest is an OCI image manifest describing an artifact.
+type artifactManifest struct {
+	SchemaVersion int               `json:"schemaVersion"`
+	MediaType     string            `json:"mediaType"`
+	ArtifactType  string            `json:"artifactType"`
+	Config        Descriptor        `json:"config"`
+	Layers        []Descriptor      `json:"layers"`
+	Subject       *Descriptor       `json:"subject,omitempty"`
+	Annotations   map[string]string `json:"annotations,omitempty"`
+}
+
+// Attach pushes the VEX document to the registry of the image reference as
+// an OCI 1.1 artifact whose subject is the image digest, so that it is
+// listed by the referrers API of the image. It returns the descriptor of the
+// pushed artifact manifest or, in dry runs, the changes the document
+// introduces, so they can be reviewed before publishing.
+//
+// The artifact manifest is annotated with the document timestamp. When the
+// registry does not acknowledge the subject of the artifact, as registries
+// without the referrers API do, the artifact is listed in the index under
+// the referrers tag of the image (sha256-<digest>), which Discover reads
+// on those registries. The registry client is configured with the options,
+// as in Discover: pushing usually requires authenticating with
+// WithAuthenticator or WithKeychain.
+func Attach(ctx context.Context, ref string, doc *vex.VEX, opts *AttachOptions, clientOpts ...Option) (res *AttachResult, err error) {
+	ctx, span := tracing.Start(ctx, "oci.Attach", tracing.Attr("reference", ref))
+	defer func() { span.End(err) }()
+
+	if opts == nil {
+		opts = &AttachOptions{}
+	}
+	rc := newOptions(clientOpts).client()
+	parsed, err := ParseReference(ref)
+	if err != nil {
+		return nil, fmt.Errorf("parsing image reference: %w", err)
+	}
+
+	subject, err := attachSubject(ctx, rc, &parsed, opts)
+	if err != nil {
+		return nil, fmt.Errorf("resolving %s: %w", ref, err)
+	}
+
+	var b bytes.Buffer
+	if err := doc.ToJSON(&b); err != nil {
+		return nil, fmt.Errorf("serializing document: %w", err)
+	}
+
+	config, layer := newDescriptor(MediaTypeEmptyJSON, emptyJSON), newDescriptor(MediaTypeOpenVEX, b.Bytes())
+	if !opts.DryRun {
+		if err := rc.pushBlob(ctx, &parsed, &config, emptyJSON); err != nil {
+			return nil, err
+		}
+		if err := rc.pushBlob(ctx, &parsed, &layer, b.Bytes()); err != nil {
+			return nil, err
+		}
+	}
+
+	manifest := artifactManifest{
+		SchemaVersion: 2,
+		MediaType:     MediaTypeOCIManifest,
+		ArtifactType:  MediaTypeOpenVEX,
+		Config:        config,
+		Layers:        []Descriptor{layer},
+		Subject:       &subject,
+		Annotations:   map[string]string{},
+	}
+	if doc.Timestamp != nil {
+		manifest.Annotations[AnnotationCreated] = doc.Timestamp.UTC().Format(time.RFC3339)
+	}
+	for k, v := range opts.Annotations {
+		manifest.Annotations[k] = v
+	}
+
+	data, err := json.Marshal(&manifest)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling artifact manifest: %w", err)
+	}
+	res = &AttachResult{Descriptor: newDescriptor(MediaTypeOCIManifest, data)}
+	if opts.DryRun {
+		attached, err := rc.attachedDocuments(ctx, &parsed, subject.Digest)
+		if err != nil {
+			return nil, err
+		}
+		res.Diff = vex.Diff(latestVersion(attached, doc.ID), doc)
+		return res, nil
+	}
+	hasReferrers, err := rc.pushManifest(ctx, &parsed, res.Descriptor.Digest, &res.Descriptor, data)
+	if err != nil {
+		return nil, err
+	}
+	if !hasReferrers {
+		desc := res.Descriptor
+		desc.ArtifactType = manifest.ArtifactType
+		desc.Annotations = manifest.Annotations
+		if err := rc.updateReferrersTag(ctx, &parsed, subject.Digest, &desc); err != nil {
+			return nil, fmt.Errorf("updating referrers tag of %s: %w", subject.Digest, err)
+		}
+	}
+	return res, nil
+}
+
+// latestVersion returns the document with the ID with the highest version
+// or, among equal versions, the latest timestamp. It returns nil when no
+// document has the ID.
+func latestVersion(docs []*vex.VEX, id string) *vex.VEX {
+	var latest *vex.VEX
+	for _, d := range docs {
+		if d.ID != id {
+			continue
+		}
+		if latest == nil || d.Version > latest.Version ||
+			(d.Version == latest.Version && d.Timestamp != nil && (latest.Timestamp == nil || d.Timestamp.After(*latest.Timestamp))) {
+			latest = d
+		}
+	}
+	return latest
+}
+
+// newDescriptor returns the descriptor of the content.
+func newDescriptor(mediaType string, data []byte) Descriptor {
+	sum := sha256.Sum256(data)
+	return Descriptor{MediaType: mediaType, Digest: "sha256:" + hex.EncodeToString(sum[:]), Size: int64(len(data))}
+}
+
+// attachSubject returns the descriptor of the image to attach documents to.
+func attachSubject(ctx context.Context, rc *registryClient, ref *Reference, opts *AttachOptions) (Descriptor, error) {
+	desc, data, err := rc.getManifest(ctx, ref)
+	if err != nil {
+		return Descriptor{}, err
+	}
+	if !isIndex(desc.MediaType) || opts.OS == "" || opts.Arch == "" {
+		return desc, nil
+	}
+
+	m, err := platformManifest(&desc, data, opts.OS, opts.Arch)
+	if err != nil {
+		return Descriptor{}, err
+	}
+	return Descriptor{MediaType: m.MediaType, Digest: m.Digest, Size: m.Size}, nil
+}
+
+// pushBlob uploads a blob to the repository of the reference in a single
+// monolithic upload.
+func (rc *registryClient) pushBlob(ctx context.Context, ref *Reference, desc *Descriptor, data []byte) error {
+	res, err := rc.do(ctx, ref, &registryRequest{
+		method: http.MethodPost, url: rc.baseURL(ref) + "/blobs/uploads/", push: true,
+	})
+	if err != nil {
+		return fmt.Errorf("starting blob upload: %w", err)
+	}
+	res.Body.Close() //nolint:errcheck
+	if res.StatusCode != http.StatusAccepted {
+		return fmt.Errorf("starting blob upload: http status %d", res.StatusCode)
+	}
+
+	location, err := uploadURL(res)
+	if err != nil {
+		return err
+	}
+	q := location.Query()
+	q.Set("digest", desc.Digest)
+	location.RawQuery = q.Encode()
+
+	res, err = rc.do(ctx, ref, &registryRequest{
+		method: http.MethodPut, url: location.String(), contentType: "application/octet-stream",
+		body: data, push: true,
+	})
+	if err != nil {
+		return fmt.Errorf("uploading blob: %w", err)
+	}
+	res.Body.Close() //nolint:errcheck
+	if res.StatusCode != http.StatusCreated {
+		return fmt.Errorf("uploading blob %s: http status %d", desc.Digest, res.StatusCode)
+	}
+	return nil
+}
+
+// uploadURL returns the location of a blob upload session, resolving it
+// against the request URL as registries may return relative locations.
+func uploadURL(res *http.Response) (*url.URL, error) {
+	location := res.Header.Get("Location")
+	if location == "" {
+		return nil, errors.New("registry did not return an upload location")
+	}
+	u, err := res.Request.URL.Parse(location)
+	if err != nil {
+		return nil, fmt.Errorf("parsing upload location: %w", err)
+	}
+	return u, nil
+}
+
+// pushManifest uploads a manifest under the reference, a digest or a tag.
+// It returns true if the registry acknowledged the subject of the manifest
+// with the OCI-Subject header, meaning it lists the manifest in the
+// referrers API.
+func (rc *registryClient) pushManifest(ctx context.Context, ref *Reference, reference string, desc *Descriptor, data []byte) (bool, error) {
+	res, err := rc.do(ctx, ref, &registryRequest{
+		method: http.MethodPut, url: rc.baseURL(ref) + "/manifests/" + reference, contentType: desc.MediaType,
+		body: data, push: true,
+	})
+	if err != nil {
+		return false, fmt.Errorf("pushing manifest: %w", err)
+	}
+	res.Body.Close() //nolint:errcheck
+	if res.StatusCode != http.StatusCreated {
+		return false, fmt.Errorf("pushing manifest %s: http status %d", reference, res.StatusCode)
+	}
+	return res.Header.Get("OCI-Subject") != "", nil
+}
+
+// updateReferrersTag adds the descriptor to the index stored under the
+// referrers tag of the subject digest, following the referrers tag schema
+// of registries without the referrers API.
+func (rc *registryClient) updateReferrersTag(ctx context.Context, ref *Reference, subject string, desc *Descriptor) error {
+	tagRef := Reference{Registry: ref.Registry, Repository: ref.Repository, Tag: tagFromDigest(subject)}
+	idx := index{}
+	_, data, err := rc.getManifest(ctx, &tagRef)
+	switch {
+	case errors.Is(err, errNotFound):
+	case err != nil:
+		return err
+	default:
+		if err := json.Unmarshal(data, &idx); err != nil {
+			return fmt.Errorf("parsing referrers index: %w", err)
+		}
+	}
+
+	for _, m := range idx.Manifests {
+		if m.Digest == desc.Digest {
+			return nil
+		}
+	}
+	idx.SchemaVersion = 2
+	idx.MediaType = MediaTypeOCIIndex
+	idx.Manifests = append(idx.Manifests, *desc)
+
+	data, err = json.Marshal(&idx)
+	if err != nil {
+		return fmt.Errorf("marshaling referrers index: %w", err)
+	}
+	_, err = rc.pushManifest(ctx, ref, tagRef.Tag, &Descriptor{MediaType: MediaTypeOCIIndex}, data)
+	return err
+}
@@ -0,0 +1,247 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package oci
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/openvex/go-vex/pkg/vex"
+)
+
+// pushRegistry starts a registry serving an image index under the
+// test/image:v1 tag that accepts pushes authenticated with user:pass. When
+// referrersAPI is true it serves the pushed artifacts through the referrers
+// API, otherwise it behaves like registries without it. It returns the
+// registry host, the index digest and the pushed contents by digest or tag.
+func pushRegistry(t *testing.T, referrersAPI bool) (host, digest string, pushed map[string][]byte) {
+	t.Helper()
+	idx := fmt.Sprintf(`{"schemaVersion":2,"mediaType":%q,"manifests":[`+
+		`{"mediaType":%q,"digest":%q,"size":100,"platform":{"os":"linux","architecture":"amd64"}}]}`,
+		MediaTypeOCIIndex, MediaTypeOCIManifest, testAmd64Digest,
+	)
+	sum := sha256.Sum256([]byte(idx))
+	digest = "sha256:" + hex.EncodeToString(sum[:])
+	pushed = map[string][]byte{}
+	mediaTypes := map[string]string{}
+	var mutex sync.Mutex
+
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			if user, pass, ok := r.BasicAuth(); !ok || user != "user" || pass != "pass" ||
+				r.URL.Query().Get("scope") != "repository:test/image:pull,push" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			fmt.Fprint(w, `{"token":"push"}`)
+			return
+		}
+		if r.Method != http.MethodGet && r.Header.Get("Authorization") != "Bearer push" {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="test"`, srv.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/v2/test/image/manifests/v1":
+			w.Header().Set("Content-Type", MediaTypeOCIIndex)
+			fmt.Fprint(w, idx)
+		case r.Method == http.MethodPost && r.URL.Path == "/v2/test/image/blobs/uploads/":
+			w.Header().Set("Location", "/v2/test/image/blobs/uploads/session?state=1")
+			w.WriteHeader(http.StatusAccepted)
+		case r.Method == http.MethodPut && r.URL.Path == "/v2/test/image/blobs/uploads/session":
+			require.Equal(t, "1", r.URL.Query().Get("state"))
+			data, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			pushed[r.URL.Query().Get("digest")] = data
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/v2/test/image/referrers/") && referrersAPI:
+			subject := strings.TrimPrefix(r.URL.Path, "/v2/test/image/referrers/")
+			referrers := index{MediaType: MediaTypeOCIIndex, Manifests: []Descriptor{}}
+			for digest, data := range pushed {
+				manifest := artifactManifest{}
+				if json.Unmarshal(data, &manifest) == nil && manifest.Subject != nil && manifest.Subject.Digest == subject {
+					desc := newDescriptor(MediaTypeOCIManifest, data)
+					desc.ArtifactType = manifest.ArtifactType
+					require.Equal(t, digest, desc.Digest)
+					referrers.Manifests = append(referrers.Manifests, desc)
+				}
+			}
+			w.Header().Set("Content-Type", MediaTypeOCIIndex)
+			require.NoError(t, json.NewEncoder(w).Encode(&referrers))
+		case r.Method == http.MethodGet && pushed[path.Base(r.URL.Path)] != nil:
+			if strings.Contains(r.URL.Path, "/manifests/") {
+				w.Header().Set("Content-Type", mediaTypes[path.Base(r.URL.Path)])
+			}
+			w.Write(pushed[path.Base(r.URL.Path)]) //nolint:errcheck
+		case r.Method == http.MethodPut && strings.HasPrefix(r.URL.Path, "/v2/test/image/manifests/"):
+			reference := strings.TrimPrefix(r.URL.Path, "/v2/test/image/manifests/")
+			data, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			pushed[reference] = data
+			mediaTypes[reference] = r.Header.Get("Content-Type")
+			manifest := artifactManifest{}
+			if referrersAPI && json.Unmarshal(data, &manifest) == nil && manifest.Subject != nil {
+				w.Header().Set("OCI-Subject", manifest.Subject.Digest)
+			}
+			w.WriteHeader(http.StatusCreated)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return strings.TrimPrefix(srv.URL, "http://"), digest, pushed
+}
+
+func TestAttach(t *testing.T) {
+	host, digest, pushed := pushRegistry(t, true)
+	ts := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	doc := vex.New()
+	doc.ID = "https://openvex.dev/docs/example"
+	doc.Timestamp = &ts
+	doc.Statements = []vex.Statement{{
+		Vulnerability: vex.Vulnerability{Name: "CVE-2023-1234"},
+		Products:      []vex.Product{{Component: vex.Component{ID: "pkg:oci/image"}}},
+		Status:        vex.StatusUnderInvestigation,
+	}}
+
+	// Dry runs do not push anything and list the document changes
+	dryRun, err := Attach(context.Background(), host+"/test/image:v1", &doc, &AttachOptions{DryRun: true, Annotations: map[string]string{"purpose": "test"}})
+	require.NoError(t, err)
+	require.Equal(t, MediaTypeOCIManifest, dryRun.Descriptor.MediaType)
+	require.Len(t, dryRun.Diff.Added, 1)
+	require.Empty(t, dryRun.Diff.Changed)
+	require.Empty(t, pushed)
+
+	// Pushing requires credentials
+	_, err = Attach(context.Background(), host+"/test/image:v1", &doc, nil)
+	require.Error(t, err)
+
+	creds := &Credentials{Username: "user", Password: "pass"}
+	for m, tc := range map[string]struct {
+		opts    *AttachOptions
+		subject string
+	}{
+		"index":    {opts: &AttachOptions{Annotations: map[string]string{"purpose": "test"}}, subject: digest},
+		"platform": {opts: &AttachOptions{OS: "linux", Arch: "amd64"}, subject: testAmd64Digest},
+	} {
+		res, err := Attach(context.Background(), host+"/test/image:v1", &doc, tc.opts, WithAuthenticator(creds))
+		require.NoError(t, err, m)
+		require.Nil(t, res.Diff, m)
+		desc := res.Descriptor
+		if tc.opts.OS == "" {
+			require.Equal(t, dryRun.Descriptor, desc, m)
+		}
+		require.Equal(t, MediaTypeOCIManifest, desc.MediaType, m)
+
+		manifest := artifactManifest{}
+		require.NoError(t, json.Unmarshal(pushed[desc.Digest], &manifest), m)
+		require.Equal(t, MediaTypeOpenVEX, manifest.ArtifactType, m)
+		require.Equal(t, tc.subject, manifest.Subject.Digest, m)
+		require.Equal(t, "2023-01-01T00:00:00Z", manifest.Annotations[AnnotationCreated], m)
+		for k, v := range tc.opts.Annotations {
+			require.Equal(t, v, manifest.Annotations[k], m)
+		}
+		require.Equal(t, emptyJSON, pushed[manifest.Config.Digest], m)
+
+		require.Len(t, manifest.Layers, 1, m)
+		attached, err := vex.Parse(pushed[manifest.Layers[0].Digest])
+		require.NoError(t, err, m)
+		require.Equal(t, doc.ID, attached.ID, m)
+	}
+
+	_, err = Attach(context.Background(), host+"/test/image:v1", &doc, &AttachOptions{OS: "linux", Arch: "s390x"}, WithAuthenticator(creds))
+	require.Error(t, err)
+
+	// Tokens obtained with credentials are not reused by anonymous calls
+	_, err = Attach(context.Background(), host+"/test/image:v1", &doc, nil)
+	require.Error(t, err)
+
+	// Dry runs diff against the attached version of the document
+	update := vex.New()
+	update.ID = doc.ID
+	update.Version = 2
+	update.Timestamp = &ts
+	update.Statements = []vex.Statement{doc.Statements[0]}
+	update.Statements[0].Status = vex.StatusFixed
+	dryRun, err = Attach(context.Background(), host+"/test/image:v1", &update, &AttachOptions{DryRun: true})
+	require.NoError(t, err)
+	require.Empty(t, dryRun.Diff.Added)
+	require.Len(t, dryRun.Diff.Changed, 1)
+	require.Equal(t, vex.StatusUnderInvestigation, dryRun.Diff.Changed[0].Old.Status)
+	require.Equal(t, vex.StatusFixed, dryRun.Diff.Changed[0].New.Status)
+
+	// Registry options configure the client
+	clear(pushed)
+	res, err := Attach(context.Background(), host+"/test/image:v1", &doc, nil, WithKeychain(testKeychain{host: creds}))
+	require.NoError(t, err)
+	require.Contains(t, pushed, res.Descriptor.Digest)
+
+	rt := &redirectTransport{host: host}
+	_, err = Attach(context.Background(), "registry.example.com/test/image:v1", &doc, nil,
+		WithTransport(rt), WithInsecure(), WithKeychain(testKeychain{"registry.example.com": creds}))
+	require.NoError(t, err)
+	require.NotEmpty(t, rt.schemes)
+	require.Equal(t, "http", rt.schemes[0])
+}
+
+func TestAttachReferrersTag(t *testing.T) {
+	host, digest, pushed := pushRegistry(t, false)
+	creds := &Credentials{Username: "user", Password: "pass"}
+	ts := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	newDoc := func(id string) *vex.VEX {
+		doc := vex.New()
+		doc.ID = id
+		doc.Timestamp = &ts
+		doc.Statements = []vex.Statement{{
+			Vulnerability: vex.Vulnerability{Name: "CVE-2023-1234"},
+			Products:      []vex.Product{{Component: vex.Component{ID: "pkg:oci/image"}}},
+			Status:        vex.StatusUnderInvestigation,
+		}}
+		return &doc
+	}
+
+	first, err := Attach(context.Background(), host+"/test/image:v1", newDoc("https://example.com/vex/1"), nil, WithAuthenticator(creds))
+	require.NoError(t, err)
+	_, err = Attach(context.Background(), host+"/test/image:v1", newDoc("https://example.com/vex/1"), nil, WithAuthenticator(creds))
+	require.NoError(t, err)
+	_, err = Attach(context.Background(), host+"/test/image:v1", newDoc("https://example.com/vex/2"), nil, WithAuthenticator(creds))
+	require.NoError(t, err)
+
+	tag := tagFromDigest(digest)
+	require.Contains(t, pushed, tag)
+	idx := index{}
+	require.NoError(t, json.Unmarshal(pushed[tag], &idx))
+	require.Equal(t, 2, idx.SchemaVersion)
+	require.Equal(t, MediaTypeOCIIndex, idx.MediaType)
+	require.Len(t, idx.Manifests, 2)
+	require.Equal(t, first.Descriptor.Digest, idx.Manifests[0].Digest)
+	require.Equal(t, MediaTypeOpenVEX, idx.Manifests[0].ArtifactType)
+	require.Equal(t, "2023-01-01T00:00:00Z", idx.Manifests[0].Annotations[AnnotationCreated])
+
+	// Discover finds the documents through the referrers tag
+	docs, err := Discover(context.Background(), host+"/test/image:v1")
+	require.NoError(t, err)
+	ids := []string{}
+	for _, d := range docs {
+		ids = append(ids, d.ID)
+	}
+	require.ElementsMatch(t, []string{"https://example.com/vex/1", "https://example.com/vex/2"}, ids)
+}
@@ -0,0 +1,117 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package oci
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/openvex/go-vex/pkg/vex"
+)
+
+var digestOnlyRegex = regexp.MustCompile(`^sha256:([a-f0-9]{64})$`)
+
+// CompleteOptions control how the products of a document are completed.
+type CompleteOptions struct {
+	// OS and Arch select the image to add when a reference points to a
+	// multi-arch image index. When empty, only the index is added.
+	OS   string
+	Arch string
+}
+
+// CompleteProducts walks the products of the document statements looking for
+// components identified only by a bare image reference (eg alpine:3.18) or
+// by a digest (sha256:...). Digests are added to the component hashes and
+// image references are resolved in their registry to add the image purl and
+// digest. If the image is multi-arch, a product for the image of the
+// selected os/arch is appended to the statement. The registry client is
+// configured with the client options, as in Resolve.
+func CompleteProducts(ctx context.Context, doc *vex.VEX, opts CompleteOptions, clientOpts ...Option) error {
+	cache := map[string]*Resolved{}
+	for i := range doc.Statements {
+		stmt := &doc.Statements[i]
+		extra := []vex.Product{}
+		for j := range stmt.Products {
+			product := &stmt.Products[j]
+			completeDigestOnly(&product.Component)
+			if !isBareReference(&product.Component) {
+				continue
+			}
+
+			resolved, ok := cache[product.ID]
+			if !ok {
+				var err error
+				resolved, err = Resolve(ctx, product.ID, opts.OS, opts.Arch, clientOpts...)
+				if err != nil {
+					return fmt.Errorf("completing product %q: %w", product.ID, err)
+				}
+				cache[product.ID] = resolved
+			}
+
+			addDigest(&product.Component, &resolved.Reference, resolved.Digest, nil)
+			if resolved.ArchDigest != "" {
+				archProduct := vex.Product{
+					Subcomponents: product.Subcomponents,
+				}
+				addDigest(&archProduct.Component, &resolved.Reference, resolved.ArchDigest, map[string]string{
+					"arch": resolved.Arch, "os": resolved.OS,
+				})
+				archProduct.ID = archProduct.Identifiers[vex.PURL]
+				extra = append(extra, archProduct)
+			}
+		}
+		stmt.Products = append(stmt.Products, extra...)
+	}
+	return nil
+}
+
+// isBareReference returns true if the component is only identified by an
+// image reference string.
+func isBareReference(component *vex.Component) bool {
+	if component.ID == "" || len(component.Identifiers) > 0 || len(component.Hashes) > 0 {
+		return false
+	}
+	if strings.HasPrefix(component.ID, "pkg:") || strings.Contains(component.ID, "://") ||
+		digestOnlyRegex.MatchString(component.ID) {
+		return false
+	}
+	// Require a tag, digest or path to avoid treating any word as an image
+	if !strings.ContainsAny(component.ID, ":/@") {
+		return false
+	}
+	_, err := ParseReference(component.ID)
+	return err == nil
+}
+
+// completeDigestOnly adds the hash of components identified by a digest.
+func completeDigestOnly(component *vex.Component) {
+	m := digestOnlyRegex.FindStringSubmatch(component.ID)
+	if m == nil {
+		return
+	}
+	if component.Hashes == nil {
+		component.Hashes = map[vex.Algorithm]vex.Hash{}
+	}
+	if _, ok := component.Hashes[vex.SHA256]; !ok {
+		component.Hashes[vex.SHA256] = vex.Hash(m[1])
+	}
+}
+
+// addDigest records the image digest in the component hashes and sets its
+// purl. The purl is left without the repository and tag qualifiers so that
+// it matches the more specific purls generated by other tools.
+func addDigest(component *vex.Component, ref *Reference, digest string, qualifiers map[string]string) {
+	if component.Identifiers == nil {
+		component.Identifiers = map[vex.IdentifierType]string{}
+	}
+	if component.Hashes == nil {
+		component.Hashes = map[vex.Algorithm]vex.Hash{}
+	}
+	component.Identifiers[vex.PURL] = digestPurl(ref.ImageName(), digest, qualifiers)
+	if hash, ok := strings.CutPrefix(digest, "sha256:"); ok {
+		component.Hashes[vex.SHA256] = vex.Hash(hash)
+	}
+}
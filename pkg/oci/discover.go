@@ -0,0 +1,248 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package oci
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/openvex/go-vex/pkg/tracing"
+	"github.com/openvex/go-vex/pkg/vex"
+)
+
+const (
+	// MediaTypeDSSEEnvelope is the media type of the layers of cosign
+	// attestations.
+	MediaTypeDSSEEnvelope = "application/vnd.dsse.envelope.v1+json"
+
+	// payloadTypeInToto is the DSSE payload type of in-toto statements.
+	payloadTypeInToto = "application/vnd.in-toto+json"
+)
+
+// maxDocumentSize caps the size of the documents read from registries.
+const maxDocumentSize = 32 << 20
+
+// Discover returns the VEX documents attached to the image the reference
+// points to. Documents are looked up in the OpenVEX artifacts listed by the
+// referrers API of the registry, pushed by Attach. When the registry does
+// not support the referrers API, the referrers tag schema is used instead.
+// The OpenVEX predicates of the in-toto attestations found under the cosign
+// attestation tag (sha256-<digest>.att) are returned too. Attestation
+// signatures are not verified.
+//
+// Discover can be used as the Discoverer of an inventory evaluation, use
+// NewDiscoverer to configure its registry calls.
+func Discover(ctx context.Context, ref string) ([]*vex.VEX, error) {
+	return discover(ctx, ref, &options{})
+}
+
+// discover implements Discover with the options.
+func discover(ctx context.Context, ref string, o *options) (docs []*vex.VEX, err error) {
+	ctx, span := tracing.Start(ctx, "oci.Discover", tracing.Attr("reference", ref))
+	defer func() { span.End(err) }()
+	rc := o.client()
+
+	parsed, err := ParseReference(ref)
+	if err != nil {
+		return nil, fmt.Errorf("parsing image reference: %w", err)
+	}
+	desc, _, err := rc.getManifest(ctx, &parsed)
+	if err != nil {
+		return nil, fmt.Errorf("resolving %s: %w", ref, err)
+	}
+
+	docs, err = rc.attachedDocuments(ctx, &parsed, desc.Digest)
+	if err != nil {
+		return nil, err
+	}
+
+	// Look for attestations following the cosign tag convention
+	attRef := Reference{Registry: parsed.Registry, Repository: parsed.Repository, Tag: tagFromDigest(desc.Digest) + ".att"}
+	found, err := rc.artifactDocuments(ctx, &attRef, "", MediaTypeDSSEEnvelope)
+	if err != nil && !errors.Is(err, errNotFound) {
+		return nil, fmt.Errorf("reading attestations of %s: %w", desc.Digest, err)
+	}
+	docs = append(docs, found...)
+	return docs, nil
+}
+
+// attachedDocuments returns the documents in the OpenVEX artifacts whose
+// subject is the digest.
+func (rc *registryClient) attachedDocuments(ctx context.Context, ref *Reference, digest string) ([]*vex.VEX, error) {
+	referrers, err := rc.referrers(ctx, ref, digest)
+	if err != nil {
+		return nil, fmt.Errorf("listing referrers of %s: %w", digest, err)
+	}
+
+	docs := []*vex.VEX{}
+	for _, r := range referrers {
+		if r.ArtifactType != MediaTypeOpenVEX {
+			continue
+		}
+		found, err := rc.artifactDocuments(ctx, ref, r.Digest, MediaTypeOpenVEX)
+		if err != nil {
+			return nil, fmt.Errorf("reading artifact %s: %w", r.Digest, err)
+		}
+		docs = append(docs, found...)
+	}
+	return docs, nil
+}
+
+// tagFromDigest returns the tag of the fallback schemas for content
+// attached to the digest, eg sha256-abc123...
+func tagFromDigest(digest string) string {
+	return strings.Replace(digest, ":", "-", 1)
+}
+
+// referrers lists the descriptors of the manifests whose subject is the
+// digest. Registries without the referrers API are queried using the
+// referrers tag schema.
+func (rc *registryClient) referrers(ctx context.Context, ref *Reference, digest string) ([]Descriptor, error) {
+	res, err := rc.do(ctx, ref, &registryRequest{
+		method: http.MethodGet,
+		url:    rc.baseURL(ref) + "/referrers/" + digest + "?" + url.Values{"artifactType": {MediaTypeOpenVEX}}.Encode(),
+		accept: []string{MediaTypeOCIIndex},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close() //nolint:errcheck
+
+	var data []byte
+	switch res.StatusCode {
+	case http.StatusOK:
+		data, err = readLimited(res.Body, maxManifestSize)
+		if err != nil {
+			return nil, fmt.Errorf("reading referrers: %w", err)
+		}
+	case http.StatusNotFound:
+		tagRef := Reference{Registry: ref.Registry, Repository: ref.Repository, Tag: tagFromDigest(digest)}
+		_, data, err = rc.getManifest(ctx, &tagRef)
+		if errors.Is(err, errNotFound) {
+			return []Descriptor{}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("listing referrers: http status %d", res.StatusCode)
+	}
+
+	idx := index{}
+	if err := json.Unmarshal(data, &idx); err != nil {
+		return nil, fmt.Errorf("parsing referrers index: %w", err)
+	}
+	return idx.Manifests, nil
+}
+
+// artifactDocuments reads the VEX documents in the layers of the manifest
+// with the media type. Documents are read as they are from OpenVEX layers
+// and from the predicate of the in-toto statements in DSSE envelopes. The
+// manifest is addressed by its digest or, if empty, by the reference tag.
+func (rc *registryClient) artifactDocuments(ctx context.Context, ref *Reference, digest, mediaType string) ([]*vex.VEX, error) {
+	manifestRef := *ref
+	if digest != "" {
+		manifestRef.Tag, manifestRef.Digest = "", digest
+	}
+	_, data, err := rc.getManifest(ctx, &manifestRef)
+	if err != nil {
+		return nil, err
+	}
+	manifest := artifactManifest{}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("parsing manifest: %w", err)
+	}
+
+	docs := []*vex.VEX{}
+	for _, layer := range manifest.Layers {
+		if layer.MediaType != mediaType {
+			continue
+		}
+		blob, err := rc.getBlob(ctx, ref, layer.Digest)
+		if err != nil {
+			return nil, err
+		}
+
+		if mediaType == MediaTypeDSSEEnvelope {
+			blob, err = envelopePredicate(blob)
+			if err != nil {
+				return nil, fmt.Errorf("reading attestation %s: %w", layer.Digest, err)
+			}
+			if blob == nil {
+				continue
+			}
+		}
+
+		doc, err := vex.Parse(blob)
+		if err != nil {
+			return nil, fmt.Errorf("parsing document %s: %w", layer.Digest, err)
+		}
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
+
+// envelopePredicate returns the predicate of the in-toto statement in a
+// DSSE envelope or nil if the predicate is not an OpenVEX document.
+func envelopePredicate(data []byte) ([]byte, error) {
+	env := struct {
+		PayloadType string `json:"payloadType"`
+		Payload     string `json:"payload"`
+	}{}
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, fmt.Errorf("decoding envelope: %w", err)
+	}
+	if env.PayloadType != payloadTypeInToto {
+		return nil, nil
+	}
+	payload, err := base64.StdEncoding.DecodeString(env.Payload)
+	if err != nil {
+		return nil, fmt.Errorf("decoding envelope payload: %w", err)
+	}
+
+	statement := struct {
+		PredicateType string          `json:"predicateType"`
+		Predicate     json.RawMessage `json:"predicate"`
+	}{}
+	if err := json.Unmarshal(payload, &statement); err != nil {
+		return nil, fmt.Errorf("decoding in-toto statement: %w", err)
+	}
+	if !strings.HasPrefix(statement.PredicateType, vex.TypeURI) {
+		return nil, nil
+	}
+	return statement.Predicate, nil
+}
+
+// getBlob fetches a blob from the repository of the reference, checking
+// its digest.
+func (rc *registryClient) getBlob(ctx context.Context, ref *Reference, digest string) ([]byte, error) {
+	res, err := rc.do(ctx, ref, &registryRequest{method: http.MethodGet, url: rc.baseURL(ref) + "/blobs/" + digest})
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close() //nolint:errcheck
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching blob %s: http status %d", digest, res.StatusCode)
+	}
+
+	data, err := readLimited(res.Body, maxDocumentSize)
+	if err != nil {
+		return nil, fmt.Errorf("reading blob: %w", err)
+	}
+	if strings.HasPrefix(digest, "sha256:") {
+		sum := sha256.Sum256(data)
+		if "sha256:"+hex.EncodeToString(sum[:]) != digest {
+			return nil, fmt.Errorf("blob does not match digest %s", digest)
+		}
+	}
+	return data, nil
+}
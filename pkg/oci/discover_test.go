@@ -0,0 +1,122 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package oci
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/openvex/go-vex/pkg/vex"
+)
+
+// discoverRegistry starts a registry serving an image under test/image:v1
+// with an attached OpenVEX artifact and a cosign attestation. When
+// referrersAPI is false, the registry lists referrers with the referrers
+// tag schema. It returns the registry host.
+func discoverRegistry(t *testing.T, referrersAPI bool) string {
+	t.Helper()
+	content := map[string][]byte{}
+	add := func(mediaType string, data []byte) Descriptor {
+		sum := sha256.Sum256(data)
+		desc := Descriptor{MediaType: mediaType, Digest: "sha256:" + hex.EncodeToString(sum[:]), Size: int64(len(data))}
+		content[desc.Digest] = data
+		return desc
+	}
+	document := func(id string) []byte {
+		doc := vex.New()
+		doc.ID = id
+		var b bytes.Buffer
+		require.NoError(t, doc.ToJSON(&b))
+		return b.Bytes()
+	}
+	manifest := func(artifactType string, subject *Descriptor, layers ...Descriptor) Descriptor {
+		data, err := json.Marshal(&artifactManifest{
+			SchemaVersion: 2, MediaType: MediaTypeOCIManifest, ArtifactType: artifactType,
+			Config: add(MediaTypeEmptyJSON, emptyJSON), Layers: layers, Subject: subject,
+		})
+		require.NoError(t, err)
+		return add(MediaTypeOCIManifest, data)
+	}
+
+	image := manifest("", nil)
+	artifact := manifest(MediaTypeOpenVEX, &image, add(MediaTypeOpenVEX, document("https://example.com/attached")))
+	artifact.ArtifactType = MediaTypeOpenVEX
+
+	envelope := func(predicateType string, predicate []byte) Descriptor {
+		statement := fmt.Sprintf(`{"_type":"https://in-toto.io/Statement/v0.1","predicateType":%q,"subject":[],"predicate":%s}`, predicateType, predicate)
+		data, err := json.Marshal(map[string]any{
+			"payloadType": payloadTypeInToto, "payload": base64.StdEncoding.EncodeToString([]byte(statement)), "signatures": []any{},
+		})
+		require.NoError(t, err)
+		return add(MediaTypeDSSEEnvelope, data)
+	}
+	att := manifest("", nil,
+		envelope(vex.TypeURI, document("https://example.com/attestation")),
+		envelope("https://slsa.dev/provenance/v1", []byte("{}")),
+	)
+
+	referrers, err := json.Marshal(&index{MediaType: MediaTypeOCIIndex, Manifests: []Descriptor{artifact}})
+	require.NoError(t, err)
+	tags := map[string]string{"v1": image.Digest, tagFromDigest(image.Digest) + ".att": att.Digest}
+	if !referrersAPI {
+		tags[tagFromDigest(image.Digest)] = add(MediaTypeOCIIndex, referrers).Digest
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/v2/test/image/")
+		switch {
+		case strings.HasPrefix(path, "referrers/") && referrersAPI:
+			require.Equal(t, MediaTypeOpenVEX, r.URL.Query().Get("artifactType"))
+			if strings.TrimPrefix(path, "referrers/") == image.Digest {
+				w.Header().Set("Content-Type", MediaTypeOCIIndex)
+				w.Write(referrers) //nolint:errcheck
+				return
+			}
+		case strings.HasPrefix(path, "manifests/"):
+			id := strings.TrimPrefix(path, "manifests/")
+			if digest, ok := tags[id]; ok {
+				id = digest
+			}
+			if data, ok := content[id]; ok {
+				w.Write(data) //nolint:errcheck
+				return
+			}
+		case strings.HasPrefix(path, "blobs/"):
+			if data, ok := content[strings.TrimPrefix(path, "blobs/")]; ok {
+				w.Write(data) //nolint:errcheck
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	t.Cleanup(srv.Close)
+	return strings.TrimPrefix(srv.URL, "http://")
+}
+
+func TestDiscover(t *testing.T) {
+	for m, referrersAPI := range map[string]bool{"referrers api": true, "referrers tag": false} {
+		host := discoverRegistry(t, referrersAPI)
+		docs, err := Discover(context.Background(), host+"/test/image:v1")
+		require.NoError(t, err, m)
+		ids := []string{}
+		for _, doc := range docs {
+			ids = append(ids, doc.ID)
+		}
+		require.Equal(t, []string{"https://example.com/attached", "https://example.com/attestation"}, ids, m)
+
+		_, err = Discover(context.Background(), host+"/test/image:missing")
+		require.Error(t, err, m)
+	}
+}
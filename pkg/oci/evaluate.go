@@ -0,0 +1,84 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package oci
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/openvex/go-vex/pkg/vex"
+)
+
+// Evaluation is the result of evaluating an image against a vulnerability
+// feed and the VEX documents that apply to it.
+type Evaluation struct {
+	ImageReport
+
+	// Vulnerabilities lists the vulnerabilities the feed reports for the
+	// image, sorted by their identifier.
+	Vulnerabilities []vex.VulnerabilityID
+}
+
+// Outstanding returns the vulnerabilities reported by the feed that remain
+// exploitable after applying the VEX statements: those without statements
+// and those whose effective status is affected or under investigation.
+func (e *Evaluation) Outstanding() []vex.VulnerabilityID {
+	ret := []vex.VulnerabilityID{}
+	for _, vuln := range e.Vulnerabilities {
+		if status := e.Status(vuln); status != vex.StatusNotAffected && status != vex.StatusFixed {
+			ret = append(ret, vuln)
+		}
+	}
+	return ret
+}
+
+// EvaluateImage resolves an image reference, asks the feed for the
+// vulnerabilities affecting the image and applies the trusted VEX documents
+// in the options, and those found by its Discover function, to them. The
+// outstanding vulnerabilities of the image are returned by the Outstanding
+// method of the evaluation. The registry client is configured with the
+// client options, as in EvaluateInventory.
+func EvaluateImage(ctx context.Context, ref string, feed vex.Feed, opts *InventoryOptions, clientOpts ...Option) (*Evaluation, error) {
+	if feed == nil {
+		return nil, errors.New("a vulnerability feed is required to evaluate an image")
+	}
+	if opts == nil {
+		opts = &DefaultInventoryOptions
+	}
+
+	eval := &Evaluation{
+		ImageReport: ImageReport{
+			Reference:  ref,
+			Statements: map[vex.VulnerabilityID]*vex.Statement{},
+		},
+	}
+
+	statements, err := eval.resolve(ctx, opts, clientOpts)
+	if err != nil {
+		return nil, fmt.Errorf("evaluating %s: %w", ref, err)
+	}
+
+	vulns, err := feed.Vulnerabilities(ctx, eval.identifiers())
+	if err != nil {
+		return nil, fmt.Errorf("querying %s feed: %w", feed.Name(), err)
+	}
+
+	seen := map[vex.VulnerabilityID]struct{}{}
+	eval.Vulnerabilities = []vex.VulnerabilityID{}
+	for _, v := range vulns {
+		if _, ok := seen[v]; ok {
+			continue
+		}
+		seen[v] = struct{}{}
+		eval.Vulnerabilities = append(eval.Vulnerabilities, v)
+	}
+	sort.Slice(eval.Vulnerabilities, func(i, j int) bool {
+		return eval.Vulnerabilities[i] < eval.Vulnerabilities[j]
+	})
+
+	eval.apply(statements, eval.Vulnerabilities)
+	return eval, nil
+}
@@ -0,0 +1,77 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package oci
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/openvex/go-vex/pkg/vex"
+)
+
+// fakeFeed reports its vulnerabilities for software with the identifier.
+type fakeFeed struct {
+	identifier string
+	vulns      []vex.VulnerabilityID
+	err        error
+}
+
+func (*fakeFeed) Name() string { return "fake" }
+
+func (f *fakeFeed) Vulnerabilities(_ context.Context, identifiers []string) ([]vex.VulnerabilityID, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	if !slices.Contains(identifiers, f.identifier) {
+		return nil, nil
+	}
+	return f.vulns, nil
+}
+
+func TestEvaluateImage(t *testing.T) {
+	host, _ := testRegistry(t)
+	ref := host + "/test/image:v1"
+	feed := &fakeFeed{
+		identifier: strings.TrimPrefix(testAmd64Digest, "sha256:"),
+		vulns:      []vex.VulnerabilityID{"CVE-2023-0003", "CVE-2023-0001", "CVE-2023-0002", "CVE-2023-0001"},
+	}
+
+	doc := vex.New()
+	doc.Statements = []vex.Statement{
+		{
+			Vulnerability: vex.Vulnerability{Name: "CVE-2023-0001"},
+			Products:      []vex.Product{{Component: vex.Component{ID: feed.identifier}}},
+			Status:        vex.StatusNotAffected,
+			Justification: vex.ComponentNotPresent,
+		},
+		{
+			Vulnerability:   vex.Vulnerability{Name: "CVE-2023-0002"},
+			Products:        []vex.Product{{Component: vex.Component{ID: feed.identifier}}},
+			Status:          vex.StatusAffected,
+			ActionStatement: "Update the image",
+		},
+	}
+
+	eval, err := EvaluateImage(context.Background(), ref, feed, &InventoryOptions{
+		OS: "linux", Arch: "amd64", Documents: []*vex.VEX{&doc},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []vex.VulnerabilityID{"CVE-2023-0001", "CVE-2023-0002", "CVE-2023-0003"}, eval.Vulnerabilities)
+	require.Equal(t, vex.StatusNotAffected, eval.Status("CVE-2023-0001"))
+	require.Equal(t, []vex.VulnerabilityID{"CVE-2023-0002", "CVE-2023-0003"}, eval.Outstanding())
+
+	_, err = EvaluateImage(context.Background(), ref, &fakeFeed{err: errors.New("feed is down")}, nil)
+	require.Error(t, err)
+
+	_, err = EvaluateImage(context.Background(), host+"/test/missing:v1", feed, nil)
+	require.Error(t, err)
+
+	_, err = EvaluateImage(context.Background(), ref, nil, nil)
+	require.Error(t, err)
+}
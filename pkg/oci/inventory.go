@@ -0,0 +1,195 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package oci
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/openvex/go-vex/pkg/vex"
+)
+
+// Discoverer returns the VEX documents attached to an image.
+type Discoverer func(ctx context.Context, ref string) ([]*vex.VEX, error)
+
+// InventoryOptions control the evaluation of an image inventory.
+type InventoryOptions struct {
+	// OS and Arch select the image to evaluate when a reference points to a
+	// multi-arch image index.
+	OS   string
+	Arch string
+
+	// Workers is the number of images resolved concurrently.
+	Workers int
+
+	// Documents are VEX documents evaluated for all images.
+	Documents []*vex.VEX
+
+	// Discover is an optional function to look up the documents attached
+	// to each image in the registry.
+	Discover Discoverer
+}
+
+// DefaultInventoryOptions are the options used by EvaluateInventory when
+// none are passed.
+var DefaultInventoryOptions = InventoryOptions{
+	OS:      "linux",
+	Arch:    "amd64",
+	Workers: 4,
+}
+
+// ImageReport captures the evaluation of an image in the inventory.
+type ImageReport struct {
+	// Reference is the image reference as found in the inventory.
+	Reference string
+
+	// Identifiers is the bundle of identifiers of the image.
+	Identifiers IdentifiersBundle
+
+	// Statements maps the evaluated vulnerabilities to the effective
+	// statement for the image. Vulnerabilities without statements are not
+	// listed.
+	Statements map[vex.VulnerabilityID]*vex.Statement
+
+	// Error records any failure resolving the image or discovering its
+	// documents.
+	Error error
+}
+
+// Status returns the effective status of a vulnerability in the image or an
+// empty status if no statement applies to it.
+func (r *ImageReport) Status(vuln vex.VulnerabilityID) vex.Status {
+	if stmt, ok := r.Statements[vuln]; ok {
+		return stmt.Status
+	}
+	return ""
+}
+
+// EvaluateInventory resolves the identifiers of a list of image references,
+// discovers their attached VEX documents and computes the effective statement
+// of each vulnerability for every image. Images that fail to resolve are
+// reported with their error and evaluation of the rest continues. The
+// registry client resolving the images is configured with the client
+// options, as in Resolve; use NewDiscoverer to configure the Discover
+// function of the options.
+func EvaluateInventory(ctx context.Context, refs []string, vulns []vex.VulnerabilityID, opts *InventoryOptions, clientOpts ...Option) ([]ImageReport, error) {
+	if opts == nil {
+		opts = &DefaultInventoryOptions
+	}
+
+	workers := opts.Workers
+	if workers < 1 {
+		workers = 1
+	}
+
+	reports := make([]ImageReport, len(refs))
+	ch := make(chan int)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range ch {
+				reports[i] = evaluateImage(ctx, refs[i], vulns, opts, clientOpts)
+			}
+		}()
+	}
+
+	for i := range refs {
+		ch <- i
+	}
+	close(ch)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("evaluating inventory: %w", err)
+	}
+	return reports, nil
+}
+
+// evaluateImage resolves an image and computes its effective statements.
+func evaluateImage(ctx context.Context, ref string, vulns []vex.VulnerabilityID, opts *InventoryOptions, clientOpts []Option) ImageReport {
+	report := ImageReport{
+		Reference:  ref,
+		Statements: map[vex.VulnerabilityID]*vex.Statement{},
+	}
+
+	statements, err := report.resolve(ctx, opts, clientOpts)
+	if err != nil {
+		report.Error = err
+		return report
+	}
+	report.apply(statements, vulns)
+	return report
+}
+
+// resolve looks up the identifiers of the image and returns the statements
+// of the documents evaluated for it, sorted by date.
+func (r *ImageReport) resolve(ctx context.Context, opts *InventoryOptions, clientOpts []Option) ([]vex.Statement, error) {
+	resolved, err := Resolve(ctx, r.Reference, opts.OS, opts.Arch, clientOpts...)
+	if err != nil {
+		return nil, err
+	}
+	r.Identifiers = resolved.Bundle()
+
+	docs := opts.Documents
+	if opts.Discover != nil {
+		attached, err := opts.Discover(ctx, r.Reference)
+		if err != nil {
+			return nil, fmt.Errorf("discovering documents: %w", err)
+		}
+		docs = append(append([]*vex.VEX{}, docs...), attached...)
+	}
+
+	// Extract the statements to carry over the document dates
+	statements := []vex.Statement{}
+	for _, doc := range docs {
+		for _, stmt := range doc.ExtractStatements() {
+			statements = append(statements, *stmt)
+		}
+	}
+	vex.SortStatements(statements, time.Time{})
+	return statements, nil
+}
+
+// identifiers returns all the identifiers and hashes of the image.
+func (r *ImageReport) identifiers() []string {
+	identifiers := []string{}
+	for _, ids := range r.Identifiers.Identifiers {
+		identifiers = append(identifiers, ids...)
+	}
+	for _, hashes := range r.Identifiers.Hashes {
+		for _, h := range hashes {
+			identifiers = append(identifiers, string(h))
+		}
+	}
+	return identifiers
+}
+
+// apply records the latest of the sorted statements that applies to the
+// image for each vulnerability.
+func (r *ImageReport) apply(statements []vex.Statement, vulns []vex.VulnerabilityID) {
+	identifiers := r.identifiers()
+	for _, vuln := range vulns {
+		for i := len(statements) - 1; i >= 0; i-- {
+			if statementMatchesAny(&statements[i], string(vuln), identifiers) {
+				r.Statements[vuln] = &statements[i]
+				break
+			}
+		}
+	}
+}
+
+// statementMatchesAny returns true if the statement applies to the
+// vulnerability in any of the identifiers.
+func statementMatchesAny(stmt *vex.Statement, vuln string, identifiers []string) bool {
+	for _, id := range identifiers {
+		if stmt.Matches(vuln, id, nil) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package oci
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/openvex/go-vex/pkg/vex"
+)
+
+func TestEvaluateInventory(t *testing.T) {
+	host, digest := testRegistry(t)
+	now := time.Now()
+	before := now.Add(-time.Hour)
+
+	shared := vex.New()
+	shared.Timestamp = &before
+	shared.Statements = []vex.Statement{
+		{
+			Vulnerability: vex.Vulnerability{Name: "CVE-2023-0001"},
+			Products:      []vex.Product{{Component: vex.Component{ID: "pkg:oci/image@" + strings.Replace(digest, ":", "%3A", 1)}}},
+			Status:        vex.StatusUnderInvestigation,
+		},
+	}
+
+	attached := vex.New()
+	attached.Timestamp = &now
+	attached.Statements = []vex.Statement{
+		{
+			Vulnerability: vex.Vulnerability{Name: "CVE-2023-0001"},
+			Products:      []vex.Product{{Component: vex.Component{ID: strings.TrimPrefix(testAmd64Digest, "sha256:")}}},
+			Status:        vex.StatusNotAffected,
+			Justification: vex.ComponentNotPresent,
+		},
+	}
+
+	discover := func(_ context.Context, ref string) ([]*vex.VEX, error) {
+		if strings.HasSuffix(ref, ":v1") {
+			return []*vex.VEX{&attached}, nil
+		}
+		return nil, errors.New("no referrers")
+	}
+
+	reports, err := EvaluateInventory(
+		context.Background(),
+		[]string{host + "/test/image:v1", host + "/test/image@" + digest, host + "/test/missing:v1"},
+		[]vex.VulnerabilityID{"CVE-2023-0001", "CVE-2023-0002"},
+		&InventoryOptions{OS: "linux", Arch: "amd64", Workers: 2, Documents: []*vex.VEX{&shared}, Discover: discover},
+	)
+	require.NoError(t, err)
+	require.Len(t, reports, 3)
+
+	// The attached document is newer and overrides the shared one
+	require.NoError(t, reports[0].Error)
+	require.Equal(t, vex.StatusNotAffected, reports[0].Status("CVE-2023-0001"))
+	require.Equal(t, vex.Status(""), reports[0].Status("CVE-2023-0002"))
+
+	// Discovery failed for the image referenced by digest
+	require.Error(t, reports[1].Error)
+
+	require.Error(t, reports[2].Error)
+	require.Empty(t, reports[2].Statements)
+}
@@ -0,0 +1,197 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package oci derives VEX product identifiers from container image
+// references, resolving them against the image registry, and attaches VEX
+// documents to images.
+package oci
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/openvex/go-vex/pkg/vex"
+)
+
+// IdentifiersBundle groups the software identifiers and hashes that point to
+// an image.
+type IdentifiersBundle struct {
+	Identifiers map[vex.IdentifierType][]string
+	Hashes      map[vex.Algorithm][]vex.Hash
+}
+
+// Resolved is the result of resolving an image reference in its registry.
+type Resolved struct {
+	// Reference is the parsed image reference
+	Reference Reference
+
+	// Digest is the digest of the image (or image index) the reference
+	// points to.
+	Digest string
+
+	// ArchDigest is the digest of the image built for the requested
+	// platform when the reference points to an index.
+	ArchDigest string
+
+	// OS and Arch record the platform of ArchDigest
+	OS   string
+	Arch string
+}
+
+var defaultClient = newRegistryClient()
+
+// Resolve fetches the image reference from its registry and returns the
+// digest of the image and, if the reference points to an image index, the
+// digest of the image built for os/arch. If os or arch are empty, the
+// architecture digest is not looked up. The options configure the
+// registry client or, with WithOffline and WithManifest, resolve the
+// reference without fetching it.
+func Resolve(ctx context.Context, refString, os, arch string, opts ...Option) (*Resolved, error) {
+	ref, err := ParseReference(refString)
+	if err != nil {
+		return nil, fmt.Errorf("parsing image reference: %w", err)
+	}
+
+	desc, data, err := newOptions(opts).getManifest(ctx, &ref)
+	if err != nil {
+		return nil, fmt.Errorf("resolving %s: %w", refString, err)
+	}
+
+	resolved := &Resolved{
+		Reference: ref,
+		Digest:    desc.Digest,
+	}
+
+	if !isIndex(desc.MediaType) || os == "" || arch == "" {
+		return resolved, nil
+	}
+
+	m, err := platformManifest(&desc, data, os, arch)
+	if err != nil {
+		return nil, err
+	}
+	resolved.ArchDigest = m.Digest
+	resolved.OS = os
+	resolved.Arch = arch
+	return resolved, nil
+}
+
+// platformManifest returns the descriptor of the image built for os/arch
+// listed in an image index. The architecture may include a variant, eg
+// "arm64/v8".
+func platformManifest(desc *Descriptor, data []byte, os, arch string) (Descriptor, error) {
+	idx := index{}
+	if err := json.Unmarshal(data, &idx); err != nil {
+		return Descriptor{}, fmt.Errorf("parsing image index: %w", err)
+	}
+
+	archName, variant, _ := strings.Cut(arch, "/")
+	for _, m := range idx.Manifests {
+		if m.Platform == nil || m.Platform.OS != os || m.Platform.Architecture != archName {
+			continue
+		}
+		if variant != "" && m.Platform.Variant != variant {
+			continue
+		}
+		return m, nil
+	}
+
+	return Descriptor{}, fmt.Errorf("image index %s has no image for %s/%s", desc.Digest, os, arch)
+}
+
+// GenerateReferenceIdentifiers reads an image reference string and
+// generates a list of identifiers that can be used to match an entry
+// in a VEX document. The identifiers include purl variants pointing to
+// the image digest and, when the image is multi-arch, to the digest of
+// the image for os/arch. Use the options to set the context of the
+// registry calls and to authenticate to private registries. Air-gapped
+// callers can generate the identifiers of references with a digest
+// without any registry calls using WithOffline.
+func GenerateReferenceIdentifiers(refString, os, arch string, opts ...Option) (IdentifiersBundle, error) {
+	ctx := newOptions(opts).context(context.Background())
+	resolved, err := Resolve(ctx, refString, os, arch, opts...)
+	if err != nil {
+		return IdentifiersBundle{}, err
+	}
+	return resolved.Bundle(), nil
+}
+
+// Bundle returns the identifiers and hashes of the resolved image.
+func (r *Resolved) Bundle() IdentifiersBundle {
+	bundle := IdentifiersBundle{
+		Identifiers: map[vex.IdentifierType][]string{vex.PURL: {}},
+		Hashes:      map[vex.Algorithm][]vex.Hash{},
+	}
+
+	digests := []string{r.Digest}
+	if r.ArchDigest != "" {
+		digests = append(digests, r.ArchDigest)
+	}
+
+	for i, d := range digests {
+		algo, hash, ok := strings.Cut(d, ":")
+		if ok && algo == "sha256" {
+			bundle.Hashes[vex.SHA256] = append(bundle.Hashes[vex.SHA256], vex.Hash(hash))
+		}
+
+		platform := map[string]string{}
+		if i > 0 {
+			platform["arch"] = r.Arch
+			platform["os"] = r.OS
+		}
+		bundle.Identifiers[vex.PURL] = append(
+			bundle.Identifiers[vex.PURL], purlVariants(&r.Reference, d, platform)...,
+		)
+	}
+
+	return bundle
+}
+
+// purlVariants returns the purls of the image digest with all combinations
+// of the repository_url and tag qualifiers. The platform qualifiers, if any,
+// are added to all variants.
+func purlVariants(ref *Reference, digest string, platform map[string]string) []string {
+	ret := []string{}
+	for _, withRepo := range []bool{false, true} {
+		for _, withTag := range []bool{false, true} {
+			if withTag && ref.Tag == "" {
+				continue
+			}
+			qualifiers := map[string]string{}
+			for k, v := range platform {
+				qualifiers[k] = v
+			}
+			if withRepo {
+				qualifiers["repository_url"] = ref.Name()
+			}
+			if withTag {
+				qualifiers["tag"] = ref.Tag
+			}
+			ret = append(ret, digestPurl(ref.ImageName(), digest, qualifiers))
+		}
+	}
+	return ret
+}
+
+// digestPurl builds an oci purl of the image name at digest.
+func digestPurl(name, digest string, qualifiers map[string]string) string {
+	purl := fmt.Sprintf("pkg:oci/%s@%s", name, url.QueryEscape(digest))
+	keys := []string{}
+	for k := range qualifiers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := []string{}
+	for _, k := range keys {
+		pairs = append(pairs, k+"="+url.QueryEscape(qualifiers[k]))
+	}
+	if len(pairs) > 0 {
+		purl += "?" + strings.Join(pairs, "&")
+	}
+	return purl
+}
@@ -0,0 +1,279 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package oci
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/openvex/go-vex/pkg/tracing"
+	"github.com/openvex/go-vex/pkg/vex"
+)
+
+const (
+	testAmd64Digest = "sha256:f0a3ab0ef1a2b4c7d6e5a8b9c0d1e2f3a4b5c6d7e8f9a0b1c2d3e4f5a6b7c8d9"
+	testArm64Digest = "sha256:a1b2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f90"
+)
+
+// testRegistry starts a registry serving a multi-arch index under the
+// test/image:v1 tag. It returns the registry host and the index digest.
+func testRegistry(t *testing.T) (string, string) {
+	t.Helper()
+	idx := fmt.Sprintf(`{"schemaVersion":2,"mediaType":%q,"manifests":[`+
+		`{"mediaType":%q,"digest":%q,"size":100,"platform":{"os":"linux","architecture":"amd64"}},`+
+		`{"mediaType":%q,"digest":%q,"size":100,"platform":{"os":"linux","architecture":"arm64","variant":"v8"}}]}`,
+		MediaTypeOCIIndex, MediaTypeOCIManifest, testAmd64Digest, MediaTypeOCIManifest, testArm64Digest,
+	)
+	sum := sha256.Sum256([]byte(idx))
+	digest := "sha256:" + hex.EncodeToString(sum[:])
+
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/token":
+			fmt.Fprint(w, `{"token":"abc"}`)
+			return
+		case r.Header.Get("Authorization") != "Bearer abc":
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="test"`, srv.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		case r.URL.Path == "/v2/test/image/manifests/v1", r.URL.Path == "/v2/test/image/manifests/"+digest:
+			w.Header().Set("Content-Type", MediaTypeOCIIndex)
+			fmt.Fprint(w, idx)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	t.Cleanup(srv.Close)
+	return strings.TrimPrefix(srv.URL, "http://"), digest
+}
+
+func TestParseReference(t *testing.T) {
+	for testCase, tc := range map[string]struct {
+		ref       string
+		expected  Reference
+		shouldErr bool
+	}{
+		"docker hub short name": {
+			ref:      "alpine",
+			expected: Reference{Registry: DefaultRegistry, Repository: "library/alpine", Tag: "latest"},
+		},
+		"docker hub with tag": {
+			ref:      "docker.io/chainguard/curl:8.1",
+			expected: Reference{Registry: DefaultRegistry, Repository: "chainguard/curl", Tag: "8.1"},
+		},
+		"registry with port": {
+			ref:      "localhost:5000/test/image",
+			expected: Reference{Registry: "localhost:5000", Repository: "test/image", Tag: "latest"},
+		},
+		"digest": {
+			ref:      "cgr.dev/chainguard/git@" + testAmd64Digest,
+			expected: Reference{Registry: "cgr.dev", Repository: "chainguard/git", Digest: testAmd64Digest},
+		},
+		"tag and digest": {
+			ref:      "cgr.dev/chainguard/git:latest@" + testAmd64Digest,
+			expected: Reference{Registry: "cgr.dev", Repository: "chainguard/git", Tag: "latest", Digest: testAmd64Digest},
+		},
+		"empty":          {ref: "", shouldErr: true},
+		"invalid digest": {ref: "alpine@sha256:xyz", shouldErr: true},
+		"uppercase":      {ref: "Alpine:latest", shouldErr: true},
+	} {
+		ref, err := ParseReference(tc.ref)
+		if tc.shouldErr {
+			require.Error(t, err, testCase)
+			continue
+		}
+		require.NoError(t, err, testCase)
+		require.Equal(t, tc.expected, ref, testCase)
+	}
+}
+
+func TestGenerateReferenceIdentifiers(t *testing.T) {
+	host, digest := testRegistry(t)
+
+	bundle, err := GenerateReferenceIdentifiers(host+"/test/image:v1", "linux", "arm64/v8")
+	require.NoError(t, err)
+	require.Equal(t, []vex.Hash{
+		vex.Hash(strings.TrimPrefix(digest, "sha256:")), vex.Hash(strings.TrimPrefix(testArm64Digest, "sha256:")),
+	}, bundle.Hashes[vex.SHA256])
+	require.Len(t, bundle.Identifiers[vex.PURL], 8)
+	require.Contains(t, bundle.Identifiers[vex.PURL], "pkg:oci/image@"+strings.Replace(digest, ":", "%3A", 1))
+	require.Contains(t, bundle.Identifiers[vex.PURL], fmt.Sprintf(
+		"pkg:oci/image@%s?arch=arm64%%2Fv8&os=linux&repository_url=%s%%2Ftest%%2Fimage&tag=v1",
+		strings.Replace(testArm64Digest, ":", "%3A", 1), strings.Replace(host, ":", "%3A", 1),
+	))
+
+	// Without a platform only the index is listed
+	bundle, err = GenerateReferenceIdentifiers(host+"/test/image@"+digest, "", "")
+	require.NoError(t, err)
+	require.Len(t, bundle.Hashes[vex.SHA256], 1)
+	require.Len(t, bundle.Identifiers[vex.PURL], 2)
+
+	_, err = GenerateReferenceIdentifiers(host+"/test/image:v1", "windows", "amd64")
+	require.Error(t, err)
+
+	_, err = GenerateReferenceIdentifiers(host+"/test/missing:v1", "", "")
+	require.Error(t, err)
+}
+
+func TestParseChallengeParams(t *testing.T) {
+	require.Equal(t, map[string]string{
+		"realm":   "https://auth.example.com/token",
+		"service": "registry.example.com",
+		"scope":   "repository:test/image:pull,push",
+	}, parseChallengeParams(`realm="https://auth.example.com/token",service="registry.example.com",scope="repository:test/image:pull,push"`))
+}
+
+func TestAuthenticateRealm(t *testing.T) {
+	host, _ := testRegistry(t)
+	creds := &Credentials{Username: "user", Password: "pass"}
+	for m, tc := range map[string]struct {
+		registry string
+		realm    string
+		insecure bool
+		mustFail bool
+		query    url.Values
+	}{
+		"https":                   {realm: "https://auth.example.com/token"},
+		"http":                    {realm: "http://auth.example.com/token", mustFail: true},
+		"http insecure":           {realm: "http://auth.example.com/token", insecure: true},
+		"localhost":               {registry: "localhost:5000", realm: "http://127.0.0.1/token"},
+		"localhost realm":         {realm: "http://127.0.0.1/token", mustFail: true},
+		"other scheme":            {realm: "ftp://auth.example.com/token", insecure: true, mustFail: true},
+		"relative":                {realm: "/token", mustFail: true},
+		"unparseable":             {realm: "https://auth.example.com/%zz", mustFail: true},
+		"registry host over http": {realm: "http://registry.example.com/token", mustFail: true},
+		"realm with query": {
+			realm: "https://auth.example.com/token?account=user",
+			query: url.Values{"account": {"user"}, "service": {"test"}, "scope": {"repository:test/image:pull"}},
+		},
+	} {
+		registry := tc.registry
+		if registry == "" {
+			registry = "registry.example.com"
+		}
+		ref := &Reference{Registry: registry, Repository: "test/image"}
+		rt := &redirectTransport{host: host}
+		rc := newRegistryClient()
+		rc.client = &http.Client{Transport: rt}
+		rc.insecure = tc.insecure
+		rc.keychain = staticKeychain{creds: creds}
+		err := rc.authenticate(context.Background(), ref, fmt.Sprintf(`Bearer realm=%q,service="test"`, tc.realm), &registryRequest{})
+		if tc.mustFail {
+			require.Error(t, err, m)
+			require.Empty(t, rt.schemes, m)
+			continue
+		}
+		require.NoError(t, err, m)
+		require.Len(t, rt.schemes, 1, m)
+		if tc.query != nil {
+			require.Equal(t, tc.query, rt.queries[0], m)
+		}
+	}
+}
+
+func TestAuthenticateTokenSizeLimit(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprintf(w, `{"token":%q}`, strings.Repeat("a", maxTokenResponseSize))
+	}))
+	t.Cleanup(srv.Close)
+	host := strings.TrimPrefix(srv.URL, "http://")
+
+	rc := newRegistryClient()
+	err := rc.authenticate(
+		context.Background(), &Reference{Registry: host, Repository: "test/image"},
+		fmt.Sprintf(`Bearer realm="%s/token",service="test"`, srv.URL), &registryRequest{},
+	)
+	require.ErrorContains(t, err, "larger than")
+}
+
+func TestGetManifestSizeLimit(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", MediaTypeOCIManifest)
+		size := maxManifestSize
+		if strings.HasSuffix(r.URL.Path, "/large") {
+			size++
+		}
+		manifest := fmt.Sprintf(`{"mediaType":%q,"layers":[]}`, MediaTypeOCIManifest)
+		fmt.Fprint(w, manifest+strings.Repeat(" ", size-len(manifest)))
+	}))
+	t.Cleanup(srv.Close)
+	host := strings.TrimPrefix(srv.URL, "http://")
+
+	rc := newRegistryClient()
+	_, data, err := rc.getManifest(context.Background(), &Reference{Registry: host, Repository: "test/image", Tag: "fits"})
+	require.NoError(t, err)
+	require.Equal(t, maxManifestSize, len(data))
+
+	_, _, err = rc.getManifest(context.Background(), &Reference{Registry: host, Repository: "test/image", Tag: "large"})
+	require.ErrorContains(t, err, "larger than")
+}
+
+func TestCompleteProducts(t *testing.T) {
+	host, digest := testRegistry(t)
+	indexHash := strings.TrimPrefix(digest, "sha256:")
+	armHash := strings.TrimPrefix(testArm64Digest, "sha256:")
+
+	doc := vex.New()
+	doc.Statements = []vex.Statement{
+		{
+			Vulnerability: vex.Vulnerability{Name: "CVE-2023-1234"},
+			Products: []vex.Product{
+				{Component: vex.Component{ID: host + "/test/image:v1"}},
+				{Component: vex.Component{ID: "sha256:" + armHash}},
+				{Component: vex.Component{ID: "pkg:apk/wolfi/git@2.41.0-r0"}},
+			},
+			Status: vex.StatusNotAffected,
+		},
+	}
+
+	require.NoError(t, CompleteProducts(context.Background(), &doc, CompleteOptions{OS: "linux", Arch: "arm64"}))
+	products := doc.Statements[0].Products
+	require.Len(t, products, 4)
+
+	require.Equal(t, host+"/test/image:v1", products[0].ID)
+	require.Equal(t, vex.Hash(indexHash), products[0].Hashes[vex.SHA256])
+	require.Equal(t, "pkg:oci/image@sha256%3A"+indexHash, products[0].Identifiers[vex.PURL])
+
+	require.Equal(t, map[vex.Algorithm]vex.Hash{vex.SHA256: vex.Hash(armHash)}, products[1].Hashes)
+	require.Empty(t, products[2].Hashes)
+	require.Equal(t, "pkg:oci/image@sha256%3A"+armHash+"?arch=arm64&os=linux", products[3].ID)
+
+	// Documents now match the identifiers generated by tools
+	require.Len(t, doc.Matches("CVE-2023-1234", fmt.Sprintf(
+		"pkg:oci/image@sha256%%3A%s?repository_url=%s%%2Ftest%%2Fimage&tag=v1", indexHash, host,
+	), nil), 1)
+	require.Len(t, doc.Matches("CVE-2023-1234", armHash, nil), 1)
+
+	doc.Statements[0].Products = []vex.Product{{Component: vex.Component{ID: host + "/test/missing:v1"}}}
+	require.Error(t, CompleteProducts(context.Background(), &doc, CompleteOptions{}))
+}
+
+type spanRecorder struct {
+	names []string
+}
+
+func (r *spanRecorder) Start(ctx context.Context, name string, _ ...tracing.Attribute) (context.Context, tracing.Span) {
+	r.names = append(r.names, name)
+	return ctx, r
+}
+
+func (r *spanRecorder) End(error) {}
+
+func TestResolveTracing(t *testing.T) {
+	host, _ := testRegistry(t)
+	r := &spanRecorder{}
+	_, err := Resolve(tracing.ContextWithTracer(context.Background(), r), host+"/test/image:v1", "", "")
+	require.NoError(t, err)
+	require.Equal(t, []string{"oci.GetManifest"}, r.names)
+}
@@ -0,0 +1,150 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package oci
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/openvex/go-vex/pkg/vex"
+)
+
+// ErrDigestRequired is returned when resolving a reference offline that
+// has no digest.
+var ErrDigestRequired = errors.New("offline resolution requires a reference with a digest")
+
+// Option configures how the functions of the package talk to registries.
+type Option func(*options)
+
+// options holds the configuration set by the Option functions.
+type options struct {
+	ctx       context.Context //nolint:containedctx // Used by functions that do not take a context
+	keychain  Keychain
+	transport http.RoundTripper
+	insecure  bool
+	offline   bool
+
+	// manifest is a pre-fetched manifest of the reference
+	manifest []byte
+}
+
+// Keychain returns the credentials to authenticate to a registry.
+type Keychain interface {
+	// Resolve returns the credentials for the registry host, or nil to
+	// access it anonymously.
+	Resolve(registry string) (*Credentials, error)
+}
+
+// staticKeychain returns the same credentials for all registries.
+type staticKeychain struct {
+	creds *Credentials
+}
+
+func (k staticKeychain) Resolve(string) (*Credentials, error) {
+	return k.creds, nil
+}
+
+// WithContext sets the context of the registry calls of functions that do
+// not take one, such as GenerateReferenceIdentifiers, so they can be
+// canceled or time out.
+func WithContext(ctx context.Context) Option {
+	return func(o *options) { o.ctx = ctx }
+}
+
+// WithAuthenticator authenticates all registry calls with the credentials.
+func WithAuthenticator(creds *Credentials) Option {
+	return func(o *options) { o.keychain = staticKeychain{creds: creds} }
+}
+
+// WithKeychain authenticates registry calls with the credentials the
+// keychain returns for each registry.
+func WithKeychain(kc Keychain) Option {
+	return func(o *options) { o.keychain = kc }
+}
+
+// WithTransport sends the registry requests with the transport.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(o *options) { o.transport = rt }
+}
+
+// WithInsecure accesses registries over plain http. Registries running on
+// localhost are always accessed over http.
+func WithInsecure() Option {
+	return func(o *options) { o.insecure = true }
+}
+
+// WithOffline resolves references without contacting their registry. The
+// reference must include a digest; the platform digest is only looked up
+// when the image index is passed using WithManifest.
+func WithOffline() Option {
+	return func(o *options) { o.offline = true }
+}
+
+// WithManifest resolves the reference using a manifest (or image index)
+// already fetched from the registry instead of fetching it. If the
+// reference has a digest, the manifest must match it.
+func WithManifest(data []byte) Option {
+	return func(o *options) { o.manifest = data }
+}
+
+// newOptions applies the options.
+func newOptions(opts []Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// context returns the context set in the options or ctx if there is none.
+func (o *options) context(ctx context.Context) context.Context {
+	if o.ctx != nil {
+		return o.ctx
+	}
+	return ctx
+}
+
+// client returns the registry client configured by the options. Calls
+// without options share the default client and its token cache.
+func (o *options) client() *registryClient {
+	if o.transport == nil && o.keychain == nil && !o.insecure {
+		return defaultClient
+	}
+	rc := newRegistryClient()
+	if o.transport != nil {
+		rc.client = &http.Client{Transport: o.transport}
+	}
+	rc.keychain = o.keychain
+	rc.insecure = o.insecure
+	return rc
+}
+
+// getManifest returns the descriptor and contents of the manifest of the
+// reference, using the pre-fetched manifest or, when offline, only the
+// reference digest.
+func (o *options) getManifest(ctx context.Context, ref *Reference) (Descriptor, []byte, error) {
+	switch {
+	case o.manifest != nil:
+		desc, err := manifestDescriptor(ref, "", o.manifest)
+		if err != nil {
+			return Descriptor{}, nil, err
+		}
+		return desc, o.manifest, nil
+	case o.offline:
+		if ref.Digest == "" {
+			return Descriptor{}, nil, ErrDigestRequired
+		}
+		return Descriptor{Digest: ref.Digest}, nil, nil
+	}
+	return o.client().getManifest(ctx, ref)
+}
+
+// NewDiscoverer returns a Discoverer that looks up the documents attached
+// to images like Discover, configured with the options.
+func NewDiscoverer(opts ...Option) Discoverer {
+	return func(ctx context.Context, ref string) ([]*vex.VEX, error) {
+		return discover(ctx, ref, newOptions(opts))
+	}
+}
@@ -0,0 +1,210 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package oci
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/openvex/go-vex/pkg/cyclonedx"
+	"github.com/openvex/go-vex/pkg/vex"
+)
+
+// redirectTransport sends all requests to a test server, recording the
+// schemes of the original requests.
+type redirectTransport struct {
+	host    string
+	schemes []string
+	queries []url.Values
+}
+
+func (rt *redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt.schemes = append(rt.schemes, req.URL.Scheme)
+	rt.queries = append(rt.queries, req.URL.Query())
+	req = req.Clone(req.Context())
+	req.URL.Scheme, req.URL.Host = "http", rt.host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// testKeychain returns credentials for a single registry.
+type testKeychain map[string]*Credentials
+
+func (kc testKeychain) Resolve(registry string) (*Credentials, error) {
+	if creds, ok := kc[registry]; ok {
+		return creds, nil
+	}
+	return nil, errors.New("unknown registry")
+}
+
+// privateRegistry starts a registry serving the test/image:v1 index that
+// requires user:pass credentials. Bearer registries authenticate them in
+// their token endpoint, the rest challenge for basic authentication.
+func privateRegistry(t *testing.T, bearer bool) string {
+	t.Helper()
+	host, _ := testRegistry(t)
+
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		authenticated := ok && user == "user" && pass == "pass"
+		switch {
+		case r.URL.Path == "/token" && authenticated:
+			fmt.Fprint(w, `{"token":"private"}`)
+		case r.URL.Path == "/token":
+			w.WriteHeader(http.StatusUnauthorized)
+		case bearer && r.Header.Get("Authorization") != "Bearer private":
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="test"`, srv.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+		case !bearer && !authenticated:
+			w.Header().Set("WWW-Authenticate", `Basic realm="test"`)
+			w.WriteHeader(http.StatusUnauthorized)
+		default:
+			// Serve the image from the public test registry
+			req := r.Clone(r.Context())
+			req.RequestURI = ""
+			req.URL.Scheme, req.URL.Host = "http", host
+			req.Header.Set("Authorization", "Bearer abc")
+			res, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer res.Body.Close() //nolint:errcheck
+			w.Header().Set("Content-Type", res.Header.Get("Content-Type"))
+			w.WriteHeader(res.StatusCode)
+			_, err = io.Copy(w, res.Body)
+			require.NoError(t, err)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return strings.TrimPrefix(srv.URL, "http://")
+}
+
+func TestOptions(t *testing.T) {
+	creds := &Credentials{Username: "user", Password: "pass"}
+	for m, bearer := range map[string]bool{"bearer": true, "basic": false} {
+		host := privateRegistry(t, bearer)
+		ref := host + "/test/image:v1"
+
+		_, err := GenerateReferenceIdentifiers(ref, "", "")
+		require.Error(t, err, m)
+
+		bundle, err := GenerateReferenceIdentifiers(ref, "", "", WithAuthenticator(creds))
+		require.NoError(t, err, m)
+		require.NotEmpty(t, bundle.Identifiers, m)
+
+		_, err = GenerateReferenceIdentifiers(ref, "", "", WithKeychain(testKeychain{host: creds}))
+		require.NoError(t, err, m)
+
+		_, err = GenerateReferenceIdentifiers(ref, "", "", WithKeychain(testKeychain{}))
+		require.Error(t, err, m)
+	}
+
+	// Canceled contexts stop the registry calls
+	host, _ := testRegistry(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := GenerateReferenceIdentifiers(host+"/test/image:v1", "", "", WithContext(ctx))
+	require.ErrorIs(t, err, context.Canceled)
+
+	// Insecure registries are accessed over http
+	for _, tc := range []struct {
+		opts   []Option
+		scheme string
+	}{
+		{scheme: "https"},
+		{opts: []Option{WithInsecure()}, scheme: "http"},
+	} {
+		rt := &redirectTransport{host: host}
+		_, err := GenerateReferenceIdentifiers("registry.example.com/test/image:v1", "", "", append(tc.opts, WithTransport(rt))...)
+		if tc.scheme == "https" {
+			// The auth realm of the test registry is served over plain
+			// http, https registries refuse to use it
+			require.ErrorContains(t, err, "insecure realm")
+		} else {
+			require.NoError(t, err)
+		}
+		require.NotEmpty(t, rt.schemes)
+		require.Equal(t, tc.scheme, rt.schemes[0])
+	}
+}
+
+func TestOffline(t *testing.T) {
+	idx := fmt.Sprintf(`{"schemaVersion":2,"mediaType":%q,"manifests":[`+
+		`{"mediaType":%q,"digest":%q,"size":100,"platform":{"os":"linux","architecture":"arm64","variant":"v8"}}]}`,
+		MediaTypeOCIIndex, MediaTypeOCIManifest, testArm64Digest,
+	)
+	sum := sha256.Sum256([]byte(idx))
+	digest := "sha256:" + hex.EncodeToString(sum[:])
+	// Nothing listens on the registry, all calls would fail
+	ref := "registry.invalid/test/image:v1@" + digest
+
+	for m, tc := range map[string]struct {
+		ref       string
+		opts      []Option
+		hashes    int
+		shouldErr bool
+		errIs     error
+	}{
+		"digest":            {ref: ref, opts: []Option{WithOffline()}, hashes: 1},
+		"no digest":         {ref: "registry.invalid/test/image:v1", opts: []Option{WithOffline()}, shouldErr: true, errIs: ErrDigestRequired},
+		"manifest":          {ref: ref, opts: []Option{WithManifest([]byte(idx))}, hashes: 2},
+		"manifest tag":      {ref: "registry.invalid/test/image:v1", opts: []Option{WithManifest([]byte(idx))}, hashes: 2},
+		"offline manifest":  {ref: ref, opts: []Option{WithOffline(), WithManifest([]byte(idx))}, hashes: 2},
+		"manifest mismatch": {ref: "registry.invalid/test/image@" + testAmd64Digest, opts: []Option{WithManifest([]byte(idx))}, shouldErr: true},
+	} {
+		bundle, err := GenerateReferenceIdentifiers(tc.ref, "linux", "arm64", tc.opts...)
+		if tc.shouldErr {
+			require.Error(t, err, m)
+			if tc.errIs != nil {
+				require.ErrorIs(t, err, tc.errIs, m)
+			}
+			continue
+		}
+		require.NoError(t, err, m)
+		require.Len(t, bundle.Hashes[vex.SHA256], tc.hashes, m)
+		require.Equal(t, vex.Hash(strings.TrimPrefix(digest, "sha256:")), bundle.Hashes[vex.SHA256][0], m)
+	}
+}
+
+func TestOptionsEntryPoints(t *testing.T) {
+	host := privateRegistry(t, true)
+	ref := host + "/test/image:v1"
+	auth := WithAuthenticator(&Credentials{Username: "user", Password: "pass"})
+	ctx := context.Background()
+
+	for m, call := range map[string]func(opts ...Option) error{
+		"CompleteProducts": func(opts ...Option) error {
+			doc := vex.New()
+			doc.Statements = []vex.Statement{{Products: []vex.Product{{Component: vex.Component{ID: ref}}}}}
+			return CompleteProducts(ctx, &doc, CompleteOptions{}, opts...)
+		},
+		"BuildProduct": func(opts ...Option) error {
+			_, err := BuildProduct(ctx, ref, &cyclonedx.BOM{}, ProductOptions{}, opts...)
+			return err
+		},
+		"EvaluateInventory": func(opts ...Option) error {
+			reports, err := EvaluateInventory(ctx, []string{ref}, nil, &InventoryOptions{}, opts...)
+			if err != nil {
+				return err
+			}
+			return reports[0].Error
+		},
+		"EvaluateImage": func(opts ...Option) error {
+			_, err := EvaluateImage(ctx, ref, &fakeFeed{}, &InventoryOptions{}, opts...)
+			return err
+		},
+	} {
+		require.Error(t, call(), m)
+		require.NoError(t, call(auth), m)
+	}
+}
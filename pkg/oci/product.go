@@ -0,0 +1,94 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package oci
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/openvex/go-vex/pkg/cyclonedx"
+	"github.com/openvex/go-vex/pkg/vex"
+)
+
+// ProductOptions control how the product of a container image is built.
+type ProductOptions struct {
+	// OS and Arch select the image described by the SBOM when the reference
+	// points to a multi-arch image index. When empty, the index is used.
+	OS   string
+	Arch string
+}
+
+// skippedComponentTypes are the CycloneDX component types that are not
+// added as subcomponents of an image product.
+var skippedComponentTypes = map[string]struct{}{
+	"container": {},
+	"file":      {},
+}
+
+// BuildProduct resolves an image reference in its registry and builds a
+// product with the image as its component and the packages listed in the
+// image SBOM as its subcomponents. The registry client is configured with
+// the client options, as in Resolve.
+func BuildProduct(ctx context.Context, refString string, bom *cyclonedx.BOM, opts ProductOptions, clientOpts ...Option) (*vex.Product, error) {
+	resolved, err := Resolve(ctx, refString, opts.OS, opts.Arch, clientOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return resolved.Product(bom)
+}
+
+// Product builds a product for the resolved image. The product component is
+// identified by the image purl and digest (the platform image if it was
+// resolved) and the packages in the SBOM that have a purl or a hash are
+// added as subcomponents. The component describing the image in the SBOM
+// metadata is not added as a subcomponent.
+func (r *Resolved) Product(bom *cyclonedx.BOM) (*vex.Product, error) {
+	if bom == nil {
+		return nil, fmt.Errorf("no SBOM provided for %s", r.Reference.String())
+	}
+
+	product := &vex.Product{}
+	if r.ArchDigest != "" {
+		addDigest(&product.Component, &r.Reference, r.ArchDigest, map[string]string{
+			"arch": r.Arch, "os": r.OS,
+		})
+	} else {
+		addDigest(&product.Component, &r.Reference, r.Digest, nil)
+	}
+	product.ID = product.Identifiers[vex.PURL]
+
+	metadataRef := ""
+	if bom.Metadata.Component != nil {
+		metadataRef = bom.Metadata.Component.BOMRef
+	}
+
+	seen := map[string]struct{}{}
+	bomComponents := bom.ListComponents()
+	for i := range bomComponents {
+		c := &bomComponents[i]
+		if _, ok := skippedComponentTypes[c.Type]; ok {
+			continue
+		}
+		if metadataRef != "" && c.BOMRef == metadataRef {
+			continue
+		}
+		if c.Purl == "" && len(c.Hashes) == 0 {
+			continue
+		}
+
+		component := vex.ComponentFromCycloneDX(c)
+		if _, ok := seen[component.ID]; ok {
+			continue
+		}
+		seen[component.ID] = struct{}{}
+		product.Subcomponents = append(product.Subcomponents, vex.Subcomponent{Component: component})
+	}
+
+	sort.Slice(product.Subcomponents, func(i, j int) bool {
+		return product.Subcomponents[i].ID < product.Subcomponents[j].ID
+	})
+
+	return product, nil
+}
@@ -0,0 +1,68 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package oci
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/openvex/go-vex/pkg/cyclonedx"
+	"github.com/openvex/go-vex/pkg/vex"
+)
+
+func TestBuildProduct(t *testing.T) {
+	host, _ := testRegistry(t)
+
+	bom := &cyclonedx.BOM{
+		BOMFormat: "CycloneDX",
+		Metadata: cyclonedx.Metadata{
+			Component: &cyclonedx.Component{BOMRef: "image", Type: "container", Name: "test/image"},
+		},
+		Components: []cyclonedx.Component{
+			{BOMRef: "os", Type: "operating-system", Name: "alpine"},
+			{BOMRef: "busybox", Type: "library", Purl: "pkg:apk/alpine/busybox@1.36.1-r2?arch=aarch64&distro=alpine-3.18"},
+			{
+				BOMRef: "app", Type: "application", Purl: "pkg:golang/example.com/app@v1.0.0",
+				Hashes: []cyclonedx.Hash{{Algorithm: "SHA-256", Content: strings.Repeat("a", 64)}},
+				Components: []cyclonedx.Component{
+					{BOMRef: "dep", Type: "library", Purl: "pkg:golang/golang.org/x/net@v0.17.0"},
+				},
+			},
+			{BOMRef: "dup", Type: "library", Purl: "pkg:golang/golang.org/x/net@v0.17.0"},
+			{BOMRef: "etc-file", Type: "file", Name: "/etc/passwd", Hashes: []cyclonedx.Hash{{Algorithm: "SHA-1", Content: strings.Repeat("b", 40)}}},
+		},
+	}
+
+	product, err := BuildProduct(context.Background(), host+"/test/image:v1", bom, ProductOptions{OS: "linux", Arch: "arm64/v8"})
+	require.NoError(t, err)
+	require.Equal(t, vex.Hash(strings.TrimPrefix(testArm64Digest, "sha256:")), product.Hashes[vex.SHA256])
+	require.Equal(t, product.ID, product.Identifiers[vex.PURL])
+	require.Contains(t, product.ID, "arch=arm64%2Fv8")
+
+	ids := []string{}
+	for _, sc := range product.Subcomponents {
+		ids = append(ids, sc.ID)
+	}
+	require.Equal(t, []string{
+		"pkg:apk/alpine/busybox@1.36.1-r2?arch=aarch64&distro=alpine-3.18",
+		"pkg:golang/example.com/app@v1.0.0",
+		"pkg:golang/golang.org/x/net@v0.17.0",
+	}, ids)
+	require.Equal(t, vex.Hash(strings.Repeat("a", 64)), product.Subcomponents[1].Hashes[vex.SHA256])
+	require.NoError(t, product.Validate())
+
+	stmt := vex.Statement{
+		Vulnerability: vex.Vulnerability{Name: "CVE-2023-44487"},
+		Products:      []vex.Product{*product},
+		Status:        vex.StatusNotAffected,
+		Justification: vex.VulnerableCodeNotInExecutePath,
+	}
+	require.True(t, stmt.Matches("CVE-2023-44487", product.ID, []string{"pkg:golang/golang.org/x/net@v0.17.0"}))
+
+	_, err = BuildProduct(context.Background(), host+"/test/image:v1", nil, ProductOptions{})
+	require.Error(t, err)
+}
@@ -0,0 +1,131 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package oci
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	// DefaultRegistry is the registry used when a reference does not
+	// specify one.
+	DefaultRegistry = "index.docker.io"
+
+	// DefaultTag is the tag used when a reference does not specify a tag
+	// or digest.
+	DefaultTag = "latest"
+)
+
+var (
+	repositoryRegex = regexp.MustCompile(`^[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*(?:/[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*)*$`)
+	tagRegex        = regexp.MustCompile(`^[\w][\w.-]{0,127}$`)
+	digestRegex     = regexp.MustCompile(`^[a-z0-9]+(?:[.+_-][a-z0-9]+)*:[a-fA-F0-9]{32,}$`)
+)
+
+// Reference points to an image in a container registry.
+type Reference struct {
+	// Registry is the hostname (and optional port) of the registry.
+	Registry string
+
+	// Repository is the path of the image in the registry.
+	Repository string
+
+	// Tag is the image tag. It is empty when the reference has a digest
+	// but no tag.
+	Tag string
+
+	// Digest is the content digest of the image, eg sha256:abc123...
+	Digest string
+}
+
+// ParseReference parses an image reference string such as
+// "cgr.dev/chainguard/curl:latest" or "alpine@sha256:...". References
+// without a registry default to Docker Hub and references without a tag
+// or digest default to the latest tag.
+func ParseReference(ref string) (Reference, error) {
+	if ref == "" {
+		return Reference{}, errors.New("image reference is empty")
+	}
+
+	r := Reference{}
+	name := ref
+	if i := strings.Index(name, "@"); i >= 0 {
+		r.Digest = name[i+1:]
+		name = name[:i]
+		if !digestRegex.MatchString(r.Digest) {
+			return Reference{}, fmt.Errorf("invalid digest %q in reference", r.Digest)
+		}
+	}
+
+	// The tag is separated with a colon after the last slash, a colon
+	// before it marks the registry port.
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		r.Tag = name[i+1:]
+		name = name[:i]
+		if !tagRegex.MatchString(r.Tag) {
+			return Reference{}, fmt.Errorf("invalid tag %q in reference", r.Tag)
+		}
+	}
+
+	r.Registry = DefaultRegistry
+	r.Repository = name
+	if first, rest, ok := strings.Cut(name, "/"); ok &&
+		(strings.ContainsAny(first, ".:") || first == "localhost") {
+		r.Registry = first
+		r.Repository = rest
+	}
+
+	if r.Registry == DefaultRegistry || r.Registry == "docker.io" {
+		r.Registry = DefaultRegistry
+		if !strings.Contains(r.Repository, "/") {
+			r.Repository = "library/" + r.Repository
+		}
+	}
+
+	if !repositoryRegex.MatchString(r.Repository) {
+		return Reference{}, fmt.Errorf("invalid repository %q in reference", r.Repository)
+	}
+
+	if r.Tag == "" && r.Digest == "" {
+		r.Tag = DefaultTag
+	}
+
+	return r, nil
+}
+
+// Name returns the fully qualified repository name, eg
+// index.docker.io/library/alpine.
+func (r *Reference) Name() string {
+	return r.Registry + "/" + r.Repository
+}
+
+// ImageName returns the last component of the repository path, used as the
+// package name in purls.
+func (r *Reference) ImageName() string {
+	return r.Repository[strings.LastIndex(r.Repository, "/")+1:]
+}
+
+// String returns the fully qualified reference.
+func (r *Reference) String() string {
+	s := r.Name()
+	if r.Tag != "" {
+		s += ":" + r.Tag
+	}
+	if r.Digest != "" {
+		s += "@" + r.Digest
+	}
+	return s
+}
+
+// identifier returns the tag or digest used to address the image manifest,
+// preferring the digest if set.
+func (r *Reference) identifier() string {
+	if r.Digest != "" {
+		return r.Digest
+	}
+	return r.Tag
+}
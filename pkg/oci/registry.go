@@ -0,0 +1,378 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package oci
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/openvex/go-vex/pkg/scheduler"
+	"github.com/openvex/go-vex/pkg/tracing"
+)
+
+const (
+	MediaTypeOCIIndex           = "application/vnd.oci.image.index.v1+json"
+	MediaTypeOCIManifest        = "application/vnd.oci.image.manifest.v1+json"
+	MediaTypeDockerManifestList = "application/vnd.docker.distribution.manifest.list.v2+json"
+	MediaTypeDockerManifest     = "application/vnd.docker.distribution.manifest.v2+json"
+)
+
+// maxManifestSize caps the size of the manifests read from registries.
+const maxManifestSize = 4 << 20
+
+// maxTokenResponseSize caps the size of the token responses read from auth
+// realms.
+const maxTokenResponseSize = 1 << 20
+
+// errNotFound is returned when the registry does not have the content.
+var errNotFound = errors.New("not found")
+
+// Descriptor describes content stored in a registry.
+type Descriptor struct {
+	MediaType    string            `json:"mediaType"`
+	ArtifactType string            `json:"artifactType,omitempty"`
+	Digest       string            `json:"digest"`
+	Size         int64             `json:"size"`
+	Platform     *Platform         `json:"platform,omitempty"`
+	Annotations  map[string]string `json:"annotations,omitempty"`
+}
+
+// Platform is the os and architecture an image is built for.
+type Platform struct {
+	OS           string `json:"os"`
+	Architecture string `json:"architecture"`
+	Variant      string `json:"variant,omitempty"`
+}
+
+// index is the subset of an image index (or docker manifest list) we need.
+type index struct {
+	SchemaVersion int          `json:"schemaVersion,omitempty"`
+	MediaType     string       `json:"mediaType"`
+	Manifests     []Descriptor `json:"manifests"`
+}
+
+// isIndex returns true if the media type is an image index or manifest list.
+func isIndex(mediaType string) bool {
+	return mediaType == MediaTypeOCIIndex || mediaType == MediaTypeDockerManifestList
+}
+
+// registryClient is a minimal client of the OCI distribution API.
+type registryClient struct {
+	client *http.Client
+	mutex  sync.Mutex
+
+	// tokens caches the authorization header of each repository
+	tokens map[string]string
+
+	// keychain provides the credentials of requests without them
+	keychain Keychain
+
+	// insecure accesses all registries over plain http
+	insecure bool
+}
+
+func newRegistryClient() *registryClient {
+	return &registryClient{
+		client: scheduler.Client(),
+		tokens: map[string]string{},
+	}
+}
+
+// baseURL returns the API endpoint of the reference registry. Registries
+// running on localhost, or all of them when the client is insecure, are
+// accessed over plain http.
+func (rc *registryClient) baseURL(ref *Reference) string {
+	host, _, _ := strings.Cut(ref.Registry, ":")
+	scheme := "https"
+	if rc.allowsHTTP(host) {
+		scheme = "http"
+	}
+	return fmt.Sprintf("%s://%s/v2/%s", scheme, ref.Registry, ref.Repository)
+}
+
+// allowsHTTP returns true if the host can be accessed over plain http: when
+// the client is insecure or the host is localhost.
+func (rc *registryClient) allowsHTTP(host string) bool {
+	return rc.insecure || host == "localhost" || host == "127.0.0.1" || host == "::1"
+}
+
+// getManifest fetches the manifest pointed to by the reference and returns
+// its descriptor and raw contents.
+func (rc *registryClient) getManifest(ctx context.Context, ref *Reference) (desc Descriptor, data []byte, err error) {
+	ctx, span := tracing.Start(ctx, "oci.GetManifest", tracing.Attr("reference", ref.String()))
+	defer func() { span.End(err) }()
+
+	res, err := rc.do(ctx, ref, &registryRequest{
+		method: http.MethodGet,
+		url:    rc.baseURL(ref) + "/manifests/" + ref.identifier(),
+		accept: []string{MediaTypeOCIIndex, MediaTypeDockerManifestList, MediaTypeOCIManifest, MediaTypeDockerManifest},
+	})
+	if err != nil {
+		return Descriptor{}, nil, err
+	}
+	defer res.Body.Close() //nolint:errcheck
+
+	if res.StatusCode == http.StatusNotFound {
+		return Descriptor{}, nil, fmt.Errorf("fetching manifest of %s: %w", ref.String(), errNotFound)
+	}
+	if res.StatusCode != http.StatusOK {
+		return Descriptor{}, nil, fmt.Errorf("fetching manifest of %s: http status %d", ref.String(), res.StatusCode)
+	}
+
+	data, err = readLimited(res.Body, maxManifestSize)
+	if err != nil {
+		return Descriptor{}, nil, fmt.Errorf("reading manifest: %w", err)
+	}
+
+	desc, err = manifestDescriptor(ref, res.Header.Get("Content-Type"), data)
+	if err != nil {
+		return Descriptor{}, nil, err
+	}
+	return desc, data, nil
+}
+
+// readLimited reads the body up to the limit. Bodies larger than the limit
+// are an error instead of being truncated.
+func readLimited(body io.Reader, limit int64) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(body, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("content is larger than %d bytes", limit)
+	}
+	return data, nil
+}
+
+// manifestDescriptor returns the descriptor of the manifest data fetched
+// for the reference, checking it matches the reference digest. When the
+// media type is missing or generic, the one embedded in the manifest is
+// used.
+func manifestDescriptor(ref *Reference, mediaType string, data []byte) (Descriptor, error) {
+	sum := sha256.Sum256(data)
+	desc := Descriptor{
+		MediaType: mediaType,
+		Digest:    "sha256:" + hex.EncodeToString(sum[:]),
+		Size:      int64(len(data)),
+	}
+
+	if ref.Digest != "" && strings.HasPrefix(ref.Digest, "sha256:") && ref.Digest != desc.Digest {
+		return Descriptor{}, fmt.Errorf("manifest digest %s does not match reference digest %s", desc.Digest, ref.Digest)
+	}
+
+	if !isIndex(desc.MediaType) && desc.MediaType != MediaTypeOCIManifest && desc.MediaType != MediaTypeDockerManifest {
+		idx := index{}
+		if err := json.Unmarshal(data, &idx); err == nil && idx.MediaType != "" {
+			desc.MediaType = idx.MediaType
+		}
+	}
+
+	return desc, nil
+}
+
+// Credentials are used to request tokens from the registry, for example to
+// push content. Requests are anonymous when no credentials are set.
+type Credentials struct {
+	Username string
+	Password string
+}
+
+// registryRequest is a request to the registry API. The body is kept in
+// memory so the request can be sent again after authenticating.
+type registryRequest struct {
+	method      string
+	url         string
+	accept      []string
+	contentType string
+	body        []byte
+
+	// push requests a token that allows pushing to the repository when the
+	// challenge does not specify a scope.
+	push bool
+}
+
+// do performs a request against the registry, authenticating when the
+// registry challenges the request.
+func (rc *registryClient) do(ctx context.Context, ref *Reference, r *registryRequest) (*http.Response, error) {
+	res, err := rc.send(ctx, ref, r)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusUnauthorized {
+		return res, nil
+	}
+
+	challenge := res.Header.Get("WWW-Authenticate")
+	res.Body.Close() //nolint:errcheck
+	if err := rc.authenticate(ctx, ref, challenge, r); err != nil {
+		return nil, fmt.Errorf("authenticating to %s: %w", ref.Registry, err)
+	}
+	return rc.send(ctx, ref, r)
+}
+
+func (rc *registryClient) send(ctx context.Context, ref *Reference, r *registryRequest) (*http.Response, error) {
+	var body io.Reader = http.NoBody
+	if r.body != nil {
+		body = bytes.NewReader(r.body)
+	}
+	req, err := http.NewRequestWithContext(ctx, r.method, r.url, body)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	if len(r.accept) > 0 {
+		req.Header.Set("Accept", strings.Join(r.accept, ", "))
+	}
+	if r.contentType != "" {
+		req.Header.Set("Content-Type", r.contentType)
+	}
+
+	rc.mutex.Lock()
+	auth := rc.tokens[ref.Name()]
+	rc.mutex.Unlock()
+	if auth != "" {
+		req.Header.Set("Authorization", auth)
+	}
+
+	res, err := rc.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("calling registry: %w", err)
+	}
+	return res, nil
+}
+
+// authenticate answers the challenge of the registry and caches the
+// authorization for the repository. Bearer challenges are answered with a
+// token from their realm, anonymous unless the keychain has credentials for
+// the registry. Basic challenges require credentials.
+func (rc *registryClient) authenticate(ctx context.Context, ref *Reference, challenge string, r *registryRequest) error {
+	var creds *Credentials
+	if rc.keychain != nil {
+		var err error
+		if creds, err = rc.keychain.Resolve(ref.Registry); err != nil {
+			return fmt.Errorf("resolving credentials: %w", err)
+		}
+	}
+
+	scheme, params, _ := strings.Cut(challenge, " ")
+	switch {
+	case strings.EqualFold(scheme, "basic") && creds != nil:
+		req := http.Request{Header: http.Header{}}
+		req.SetBasicAuth(creds.Username, creds.Password)
+		rc.mutex.Lock()
+		rc.tokens[ref.Name()] = req.Header.Get("Authorization")
+		rc.mutex.Unlock()
+		return nil
+	case !strings.EqualFold(scheme, "bearer"):
+		return fmt.Errorf("unsupported auth challenge %q", challenge)
+	}
+
+	values := parseChallengeParams(params)
+	realm := values["realm"]
+	if realm == "" {
+		return errors.New("auth challenge has no realm")
+	}
+	// Don't leak the credentials to plain http realms, unless the registry
+	// itself is accessed over plain http
+	realmURL, err := url.Parse(realm)
+	if err != nil {
+		return fmt.Errorf("parsing auth realm: %w", err)
+	}
+	registryHost, _, _ := strings.Cut(ref.Registry, ":")
+	if realmURL.Scheme != "https" && (realmURL.Scheme != "http" || !rc.allowsHTTP(registryHost)) {
+		return fmt.Errorf("refusing to authenticate to insecure realm %q", realm)
+	}
+
+	// Realms may have their own query parameters
+	q := realmURL.Query()
+	if values["service"] != "" {
+		q.Set("service", values["service"])
+	}
+	scope := values["scope"]
+	if scope == "" {
+		actions := "pull"
+		if r.push {
+			actions = "pull,push"
+		}
+		scope = fmt.Sprintf("repository:%s:%s", ref.Repository, actions)
+	}
+	q.Set("scope", scope)
+
+	realmURL.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, realmURL.String(), http.NoBody)
+	if err != nil {
+		return fmt.Errorf("creating token request: %w", err)
+	}
+	if creds != nil {
+		req.SetBasicAuth(creds.Username, creds.Password)
+	}
+	res, err := rc.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("requesting token: %w", err)
+	}
+	defer res.Body.Close() //nolint:errcheck
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("requesting token: http status %d", res.StatusCode)
+	}
+
+	data, err := readLimited(res.Body, maxTokenResponseSize)
+	if err != nil {
+		return fmt.Errorf("reading token response: %w", err)
+	}
+	tokenResponse := struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}{}
+	if err := json.Unmarshal(data, &tokenResponse); err != nil {
+		return fmt.Errorf("decoding token response: %w", err)
+	}
+
+	token := tokenResponse.Token
+	if token == "" {
+		token = tokenResponse.AccessToken
+	}
+	if token == "" {
+		return errors.New("token response has no token")
+	}
+
+	rc.mutex.Lock()
+	rc.tokens[ref.Name()] = "Bearer " + token
+	rc.mutex.Unlock()
+	return nil
+}
+
+// parseChallengeParams parses the comma separated key="value" pairs of an
+// authentication challenge. Quoted values may contain commas.
+func parseChallengeParams(params string) map[string]string {
+	ret := map[string]string{}
+	for params != "" {
+		k, rest, ok := strings.Cut(strings.TrimLeft(params, " ,"), "=")
+		if !ok {
+			break
+		}
+		var v string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.Index(rest[1:], `"`)
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			v = rest[1 : end+1]
+			params = rest[min(end+2, len(rest)):]
+		} else {
+			v, params, _ = strings.Cut(rest, ",")
+		}
+		ret[strings.ToLower(strings.TrimSpace(k))] = v
+	}
+	return ret
+}
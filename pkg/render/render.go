@@ -0,0 +1,231 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package render generates human readable reports of OpenVEX documents, such
+// as Markdown or HTML summaries listing the status of the products affected
+// by each vulnerability.
+package render
+
+import (
+	_ "embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/openvex/go-vex/pkg/vex"
+)
+
+// Report formats supported by Render.
+const (
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+)
+
+//go:embed templates/markdown.tmpl
+var markdownTemplate string
+
+//go:embed templates/html.tmpl
+var htmlTemplate string
+
+// Options control how the reports are rendered.
+type Options struct {
+	// Template replaces the builtin template of the format. It is executed
+	// with a Report value. HTML templates use the html/template package
+	// and are escaped accordingly.
+	Template string
+
+	// Funcs are functions added to the template, they override the
+	// builtin ones with the same name.
+	Funcs map[string]any
+
+	// Language is the BCP 47 tag of the language of the status and
+	// justification labels, looked up in the catalogs registered with
+	// vex.RegisterCatalog. Defaults to the document language or, if it has
+	// none, to vex.DefaultLanguage.
+	Language string
+}
+
+// Report is the data passed to the templates.
+type Report struct {
+	// Document is the rendered VEX document.
+	Document *vex.VEX
+
+	// Summary has the aggregate counts of the document.
+	Summary vex.Summary
+
+	// Vulnerabilities lists the vulnerabilities in the document sorted
+	// by name.
+	Vulnerabilities []Vulnerability
+}
+
+// Vulnerability groups the entries of a vulnerability in the report.
+type Vulnerability struct {
+	Name        string
+	Aliases     []string
+	Description string
+
+	// Entries list the products in the statements about the vulnerability,
+	// in the order they appear in the document.
+	Entries []Entry
+}
+
+// Entry is the status of a product in a statement.
+type Entry struct {
+	Product         string
+	Subcomponents   []string
+	Status          vex.Status
+	Justification   vex.Justification
+	ImpactStatement string
+	ActionStatement string
+	Timestamp       *time.Time
+}
+
+// Render writes a report of the document in the specified format.
+func Render(w io.Writer, doc *vex.VEX, format string) error {
+	return RenderWithOptions(w, doc, format, nil)
+}
+
+// RenderWithOptions works like Render but renders the report with the
+// passed options.
+func RenderWithOptions(w io.Writer, doc *vex.VEX, format string, opts *Options) error {
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	lang := opts.Language
+	if lang == "" {
+		lang = doc.Lang
+	}
+	if lang == "" {
+		lang = vex.DefaultLanguage
+	}
+
+	funcs := map[string]any{
+		"cell":  markdownCell,
+		"date":  formatDate,
+		"join":  strings.Join,
+		"label": labeler(lang),
+	}
+	for name, f := range opts.Funcs {
+		funcs[name] = f
+	}
+
+	var tmpl interface {
+		Execute(io.Writer, any) error
+	}
+	switch format {
+	case FormatMarkdown:
+		text := markdownTemplate
+		if opts.Template != "" {
+			text = opts.Template
+		}
+		t, err := template.New(format).Funcs(funcs).Parse(text)
+		if err != nil {
+			return fmt.Errorf("parsing template: %w", err)
+		}
+		tmpl = t
+	case FormatHTML:
+		text := htmlTemplate
+		if opts.Template != "" {
+			text = opts.Template
+		}
+		t, err := htmltemplate.New(format).Funcs(funcs).Parse(text)
+		if err != nil {
+			return fmt.Errorf("parsing template: %w", err)
+		}
+		tmpl = t
+	default:
+		return fmt.Errorf("unsupported report format %q", format)
+	}
+
+	if err := tmpl.Execute(w, NewReport(doc)); err != nil {
+		return fmt.Errorf("rendering report: %w", err)
+	}
+	return nil
+}
+
+// NewReport builds the report data of a document.
+func NewReport(doc *vex.VEX) Report {
+	report := Report{
+		Document: doc,
+		Summary:  doc.Summary(),
+	}
+
+	vulns := map[string]*Vulnerability{}
+	for i := range doc.Statements {
+		stmt := &doc.Statements[i]
+		name := string(stmt.Vulnerability.Name)
+		vuln, ok := vulns[name]
+		if !ok {
+			vuln = &Vulnerability{Name: name}
+			vulns[name] = vuln
+		}
+		if vuln.Description == "" {
+			vuln.Description = stmt.Vulnerability.Description
+		}
+		for _, alias := range stmt.Vulnerability.Aliases {
+			if !slices.Contains(vuln.Aliases, string(alias)) {
+				vuln.Aliases = append(vuln.Aliases, string(alias))
+			}
+		}
+
+		for j := range stmt.Products {
+			entry := Entry{
+				Product:         stmt.Products[j].ID,
+				Status:          stmt.Status,
+				Justification:   stmt.Justification,
+				ImpactStatement: stmt.ImpactStatement,
+				ActionStatement: stmt.ActionStatement,
+				Timestamp:       stmt.Timestamp,
+			}
+			for _, sc := range stmt.Products[j].Subcomponents {
+				entry.Subcomponents = append(entry.Subcomponents, sc.ID)
+			}
+			vuln.Entries = append(vuln.Entries, entry)
+		}
+	}
+
+	for _, vuln := range vulns {
+		report.Vulnerabilities = append(report.Vulnerabilities, *vuln)
+	}
+	sort.Slice(report.Vulnerabilities, func(i, j int) bool {
+		return report.Vulnerabilities[i].Name < report.Vulnerabilities[j].Name
+	})
+	return report
+}
+
+// markdownCell escapes a string to be used in a Markdown table cell.
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.ReplaceAll(strings.TrimSpace(s), "\n", "<br>")
+}
+
+// labeler returns the label template function, which renders statuses and
+// justifications with their labels in the language. Other values are
+// printed as they are.
+func labeler(lang string) func(any) string {
+	return func(v any) string {
+		switch v := v.(type) {
+		case vex.Status:
+			return v.Label(lang)
+		case vex.Justification:
+			return v.Label(lang)
+		default:
+			return fmt.Sprint(v)
+		}
+	}
+}
+
+// formatDate formats the dates in the reports.
+func formatDate(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
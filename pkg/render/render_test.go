@@ -0,0 +1,99 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package render
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/openvex/go-vex/pkg/vex"
+)
+
+func testDocument() *vex.VEX {
+	ts := time.Date(2023, 6, 1, 10, 0, 0, 0, time.UTC)
+	doc := vex.NewWithOptions(&vex.DocumentOptions{Clock: vex.FixedClock(ts)})
+	doc.ID = "https://example.com/vex/1"
+	doc.Author = "Wolfi J Inkinson"
+	doc.Statements = []vex.Statement{
+		{
+			Vulnerability:   vex.Vulnerability{Name: "CVE-2023-0002", Aliases: []vex.VulnerabilityID{"GHSA-xxxx-xxxx-xxxx"}},
+			Products:        []vex.Product{{Component: vex.Component{ID: "pkg:apk/wolfi/curl@8.1.0-r0"}}},
+			Status:          vex.StatusAffected,
+			ActionStatement: "Update to 8.1.1",
+		},
+		{
+			Vulnerability: vex.Vulnerability{Name: "CVE-2023-0001", Description: "A <vulnerability>"},
+			Products: []vex.Product{{
+				Component:     vex.Component{ID: "pkg:apk/wolfi/git@2.41.0-r0"},
+				Subcomponents: []vex.Subcomponent{{Component: vex.Component{ID: "pkg:apk/wolfi/openssl@3.1.2-r0"}}},
+			}},
+			Status:          vex.StatusNotAffected,
+			Justification:   vex.VulnerableCodeNotPresent,
+			ImpactStatement: "The code is not compiled in | see\nthe build flags",
+		},
+	}
+	return &doc
+}
+
+func TestNewReport(t *testing.T) {
+	report := NewReport(testDocument())
+	require.Equal(t, 2, report.Summary.Statements)
+	require.Len(t, report.Vulnerabilities, 2)
+	require.Equal(t, "CVE-2023-0001", report.Vulnerabilities[0].Name)
+	require.Equal(t, "A <vulnerability>", report.Vulnerabilities[0].Description)
+	require.Equal(t, []string{"pkg:apk/wolfi/openssl@3.1.2-r0"}, report.Vulnerabilities[0].Entries[0].Subcomponents)
+	require.Equal(t, []string{"GHSA-xxxx-xxxx-xxxx"}, report.Vulnerabilities[1].Aliases)
+}
+
+func TestRender(t *testing.T) {
+	doc := testDocument()
+
+	var md bytes.Buffer
+	require.NoError(t, Render(&md, doc, FormatMarkdown))
+	require.Contains(t, md.String(), "- **Document:** https://example.com/vex/1\n")
+	require.Contains(t, md.String(), "- **Timestamp:** 2023-06-01T10:00:00Z\n")
+	require.Contains(t, md.String(), "| pkg:apk/wolfi/git@2.41.0-r0 (pkg:apk/wolfi/openssl@3.1.2-r0) | Not affected | Vulnerable code not present | The code is not compiled in \\| see<br>the build flags |\n")
+	require.Contains(t, md.String(), "| pkg:apk/wolfi/curl@8.1.0-r0 | Affected |  | Update to 8.1.1 |\n")
+	require.Less(t, strings.Index(md.String(), "## CVE-2023-0001"), strings.Index(md.String(), "## CVE-2023-0002"))
+
+	var html bytes.Buffer
+	require.NoError(t, Render(&html, doc, FormatHTML))
+	require.Contains(t, html.String(), "<h2>CVE-2023-0001</h2>")
+	require.Contains(t, html.String(), "<p>A &lt;vulnerability&gt;</p>")
+	require.Contains(t, html.String(), "<td>Not affected</td>")
+
+	var buf bytes.Buffer
+	require.Error(t, Render(&buf, doc, "pdf"))
+}
+
+func TestRenderWithOptions(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, RenderWithOptions(&buf, testDocument(), FormatMarkdown, &Options{
+		Template: `{{ range .Vulnerabilities }}{{ shout .Name }};{{ end }}`,
+		Funcs:    map[string]any{"shout": func(s string) string { return s + "!" }},
+	}))
+	require.Equal(t, "CVE-2023-0001!;CVE-2023-0002!;", buf.String())
+
+	// Labels are rendered in the language of the options or the document
+	doc := testDocument()
+	doc.Lang = "de"
+	for m, tc := range map[string]struct {
+		lang     string
+		expected string
+	}{
+		"document": {"", "<td>Nicht betroffen</td><td>Verwundbarer Code nicht vorhanden</td>"},
+		"option":   {"es-MX", "<td>No afectado</td><td>El código vulnerable no está presente</td>"},
+	} {
+		buf.Reset()
+		require.NoError(t, RenderWithOptions(&buf, doc, FormatHTML, &Options{Language: tc.lang}), m)
+		require.Contains(t, buf.String(), tc.expected, m)
+	}
+
+	require.Error(t, RenderWithOptions(&buf, testDocument(), FormatHTML, &Options{Template: `{{ .Missing`}))
+	require.Error(t, RenderWithOptions(&buf, testDocument(), FormatHTML, &Options{Template: `{{ .Missing }}`}))
+}
@@ -0,0 +1,120 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package repo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/openvex/go-vex/pkg/vex"
+)
+
+// FormatVersion is the version of the repository layout written by this
+// package. It is recorded in the repository index and checked by Open,
+// which upgrades repositories written by older releases.
+const FormatVersion = 3
+
+// ErrUnsupportedFormat is returned when opening a repository written by a
+// newer release of the package.
+var ErrUnsupportedFormat = errors.New("unsupported repository format")
+
+// migrations upgrade the repository layout, migrations[v] moves it from
+// format v to v+1. The combined index is rebuilt after the migrations run.
+var migrations = []func(*Repository) error{
+	// Format 0 repositories only lack the version in the index.
+	func(*Repository) error { return nil },
+
+	// Format 1 indexes list the products by the value of their canonical
+	// key, without its type.
+	(*Repository).rekeyCollections,
+
+	// Format 2 indexes don't flag the documents with wildcard products.
+	(*Repository).rekeyCollections,
+}
+
+// repositoryIndex is the combined index stored at the repository root. It
+// is a vex.Index with the format version of the repository. Indexes
+// without a version are format 0.
+type repositoryIndex struct {
+	FormatVersion int `json:"format_version"`
+	vex.Index
+}
+
+// loadRepositoryIndex reads the repository index from the file.
+func loadRepositoryIndex(file string) (*repositoryIndex, error) {
+	data, err := os.ReadFile(file) //nolint:gosec // Reads the index of the opened repository
+	if err != nil {
+		return nil, fmt.Errorf("reading index file: %w", err)
+	}
+	index := &repositoryIndex{}
+	if err := json.Unmarshal(data, index); err != nil {
+		return nil, fmt.Errorf("parsing index: %w", err)
+	}
+	return index, nil
+}
+
+// migrate upgrades a repository of an older format to FormatVersion. It
+// returns ErrUnsupportedFormat if the repository is newer than the
+// package.
+func (r *Repository) migrate(version int) error {
+	switch {
+	case version > FormatVersion || version < 0:
+		return fmt.Errorf("%w: version %d, supported up to %d", ErrUnsupportedFormat, version, FormatVersion)
+	case version == FormatVersion:
+		return nil
+	}
+	for v := version; v < FormatVersion; v++ {
+		if err := migrations[v](r); err != nil {
+			return fmt.Errorf("migrating repository from format %d: %w", v, err)
+		}
+	}
+	if err := r.reindex(); err != nil {
+		return fmt.Errorf("migrating repository: %w", err)
+	}
+	return nil
+}
+
+// rekeyCollections recomputes the products and wildcard flag of the entries
+// in the indexes of the collections from the stored documents.
+func (r *Repository) rekeyCollections() error {
+	dirs, err := os.ReadDir(r.dir)
+	if err != nil {
+		return fmt.Errorf("reading repository directory: %w", err)
+	}
+	for _, d := range dirs {
+		if !d.IsDir() {
+			continue
+		}
+		dir := filepath.Join(r.dir, d.Name())
+		collection, err := vex.LoadIndex(filepath.Join(dir, IndexFile))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		stored, err := vex.GenerateIndex(dir)
+		if err != nil {
+			return err
+		}
+		entries := map[string]*vex.IndexEntry{}
+		for i := range stored.Documents {
+			entries[stored.Documents[i].Location] = &stored.Documents[i]
+		}
+		for i := range collection.Documents {
+			if e, ok := entries[collection.Documents[i].Location]; ok {
+				collection.Documents[i].Products = e.Products
+				collection.Documents[i].Wildcard = e.Wildcard
+			}
+		}
+		if err := writeIndex(filepath.Join(dir, IndexFile), collection); err != nil {
+			return err
+		}
+	}
+	return nil
+}
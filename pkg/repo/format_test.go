@@ -0,0 +1,87 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package repo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/openvex/go-vex/pkg/vex"
+)
+
+func TestOpenFormats(t *testing.T) {
+	// A format 0 repository: a synced collection and a combined index
+	// without a version.
+	old := t.TempDir()
+	writeDocument(t, filepath.Join(old, "wolfi", "git.json"), "https://example.com/vex/git", 1, "CVE-2023-0001", "pkg:apk/wolfi/git@2.41.0")
+	publish(t, filepath.Join(old, "wolfi"))
+	require.NoError(t, os.WriteFile(filepath.Join(old, IndexFile), []byte(`{"timestamp":null,"documents":[]}`), 0o600))
+
+	r, err := Open(old)
+	require.NoError(t, err)
+	require.Len(t, r.Index().Documents, 1)
+	require.Equal(t, "wolfi/git.json", r.Index().Documents[0].Location)
+	statements, err := r.Matches("CVE-2023-0001", "pkg:apk/wolfi/git@2.41.0", nil)
+	require.NoError(t, err)
+	require.Len(t, statements, 1)
+
+	index, err := loadRepositoryIndex(filepath.Join(old, IndexFile))
+	require.NoError(t, err)
+	require.Equal(t, FormatVersion, index.FormatVersion)
+	require.Len(t, index.Documents, 1)
+
+	// Reopening a migrated repository keeps the index
+	r, err = Open(old)
+	require.NoError(t, err)
+	require.Len(t, r.Index().Documents, 1)
+
+	// Format 1 collection indexes list untyped product keys
+	untyped := t.TempDir()
+	writeDocument(t, filepath.Join(untyped, "wolfi", "git.json"), "https://example.com/vex/git", 1, "CVE-2023-0001", "pkg:apk/wolfi/git@2.41.0")
+	publish(t, filepath.Join(untyped, "wolfi"))
+	collection, err := vex.LoadIndex(filepath.Join(untyped, "wolfi", IndexFile))
+	require.NoError(t, err)
+	collection.Documents[0].Products = []string{"pkg:apk/wolfi/git@2.41.0"}
+	require.NoError(t, writeIndex(filepath.Join(untyped, "wolfi", IndexFile), collection))
+	require.NoError(t, writeIndex(filepath.Join(untyped, IndexFile), &repositoryIndex{FormatVersion: 1, Index: *collection}))
+
+	r, err = Open(untyped)
+	require.NoError(t, err)
+	require.Len(t, r.Relevant("", "pkg:apk/wolfi/git@2.41.0"), 1)
+	require.Equal(t, []string{"purl:pkg:apk/wolfi/git@2.41.0"}, r.Index().Documents[0].Products)
+
+	// Format 2 collection indexes don't flag wildcard products
+	unflagged := t.TempDir()
+	writeDocument(t, filepath.Join(unflagged, "wolfi", "all.json"), "https://example.com/vex/all", 1, "CVE-2023-0001", "*")
+	publish(t, filepath.Join(unflagged, "wolfi"))
+	collection, err = vex.LoadIndex(filepath.Join(unflagged, "wolfi", IndexFile))
+	require.NoError(t, err)
+	require.True(t, collection.Documents[0].Wildcard)
+	collection.Documents[0].Wildcard = false
+	require.NoError(t, writeIndex(filepath.Join(unflagged, "wolfi", IndexFile), collection))
+	require.NoError(t, writeIndex(filepath.Join(unflagged, IndexFile), &repositoryIndex{FormatVersion: 2, Index: *collection}))
+
+	r, err = Open(unflagged)
+	require.NoError(t, err)
+	require.Len(t, r.Relevant("", "pkg:apk/wolfi/git@2.41.0"), 1)
+	require.True(t, r.Index().Documents[0].Wildcard)
+
+	// Repositories from newer releases are rejected
+	future := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(future, IndexFile), []byte(`{"format_version":99,"documents":[]}`), 0o600))
+	_, err = Open(future)
+	require.ErrorIs(t, err, ErrUnsupportedFormat)
+
+	// New repositories are written with the current format
+	fresh := t.TempDir()
+	r, err = Open(fresh)
+	require.NoError(t, err)
+	require.NoError(t, r.reindex())
+	index, err = loadRepositoryIndex(filepath.Join(fresh, IndexFile))
+	require.NoError(t, err)
+	require.Equal(t, FormatVersion, index.FormatVersion)
+}
@@ -0,0 +1,202 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package repo manages a local repository of VEX documents mirrored from
+// remote collections. Collections are published as a directory of OpenVEX
+// documents with an index generated by vex.GenerateIndex; a repository
+// keeps a copy of each collection in a subdirectory, updates it with the
+// documents that changed since the last sync and serves lookups by
+// vulnerability and product without network access.
+package repo
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/package-url/packageurl-go"
+
+	"github.com/openvex/go-vex/pkg/fetch"
+	"github.com/openvex/go-vex/pkg/vex"
+)
+
+// IndexFile is the name of the index file of collections and repositories.
+const IndexFile = "index.json"
+
+// Option configures a repository.
+type Option func(*options)
+
+// options holds the configuration set by the Option functions.
+type options struct {
+	fetchOptions []fetch.Option
+	clock        vex.Clock
+}
+
+// WithFetchOptions configures the fetcher used to download the collections
+// when syncing, eg to authenticate the requests.
+func WithFetchOptions(opts ...fetch.Option) Option {
+	return func(o *options) { o.fetchOptions = append(o.fetchOptions, opts...) }
+}
+
+// WithClock sets the clock used to timestamp the repository index. It
+// defaults to the current time.
+func WithClock(clock vex.Clock) Option {
+	return func(o *options) { o.clock = clock }
+}
+
+// Repository is a local directory holding mirrored VEX collections. Each
+// collection is stored in a subdirectory named after its source, with the
+// index of the collection. The combined index of all the collections is
+// kept at the repository root.
+type Repository struct {
+	dir     string
+	opts    options
+	index   *vex.Index
+	vulns   map[string][]int
+	purls   map[string][]int
+	unkeyed []int
+}
+
+// Open opens the repository in the directory, creating it if it does not
+// exist. Repositories written by older releases are migrated to the
+// current FormatVersion, newer ones are rejected with ErrUnsupportedFormat.
+func Open(dir string, opts ...Option) (*Repository, error) {
+	r := &Repository{dir: dir}
+	for _, opt := range opts {
+		opt(&r.opts)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating repository directory: %w", err)
+	}
+
+	index, err := loadRepositoryIndex(filepath.Join(dir, IndexFile))
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		index = &repositoryIndex{FormatVersion: FormatVersion, Index: vex.Index{Documents: []vex.IndexEntry{}}}
+	case err != nil:
+		return nil, fmt.Errorf("loading repository index: %w", err)
+	}
+	r.setIndex(&index.Index)
+	if err := r.migrate(index.FormatVersion); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Dir returns the directory of the repository.
+func (r *Repository) Dir() string {
+	return r.dir
+}
+
+// Index returns the combined index of the collections in the repository.
+// Document locations are relative to the repository directory.
+func (r *Repository) Index() *vex.Index {
+	return r.index
+}
+
+// setIndex replaces the repository index and rebuilds the lookup tables.
+func (r *Repository) setIndex(index *vex.Index) {
+	r.index = index
+	r.vulns = map[string][]int{}
+	r.purls = map[string][]int{}
+	r.unkeyed = []int{}
+	for i := range index.Documents {
+		entry := &index.Documents[i]
+		for _, v := range entry.Vulnerabilities {
+			local := string(vex.VulnerabilityID(v).Local())
+			r.vulns[local] = append(r.vulns[local], i)
+		}
+
+		if entry.Wildcard {
+			r.unkeyed = append(r.unkeyed, i)
+			continue
+		}
+		keyed := false
+		for _, p := range entry.Products {
+			purl, ok := strings.CutPrefix(p, vex.KeyTypePurl+":")
+			if !ok {
+				continue
+			}
+			if key := packageKey(purl); key != "" {
+				r.purls[key] = append(r.purls[key], i)
+				keyed = true
+			}
+		}
+		if !keyed {
+			r.unkeyed = append(r.unkeyed, i)
+		}
+	}
+}
+
+// packageKey returns the purl of a product without its version, qualifiers
+// and subpath, or an empty string if the product is not a purl.
+func packageKey(product string) string {
+	if !strings.HasPrefix(product, "pkg:") {
+		return ""
+	}
+	p, err := packageurl.FromString(product)
+	if err != nil {
+		return ""
+	}
+	return packageurl.NewPackageURL(p.Type, p.Namespace, p.Name, "", nil, "").ToString()
+}
+
+// candidates returns the index entries that may match the vulnerability
+// and product, using the lookup tables to avoid scanning the whole index.
+// Documents without purl products, or with wildcard products, are always
+// candidates for products.
+func (r *Repository) candidates(vulnID, product string) []vex.IndexEntry {
+	var positions []int
+	switch key := packageKey(product); {
+	case vulnID != "":
+		positions = r.vulns[string(vex.VulnerabilityID(vulnID).Local())]
+	case key != "":
+		positions = append(append([]int{}, r.purls[key]...), r.unkeyed...)
+	default:
+		return r.index.Documents
+	}
+
+	seen := map[int]struct{}{}
+	ret := make([]vex.IndexEntry, 0, len(positions))
+	for _, i := range positions {
+		if _, ok := seen[i]; ok {
+			continue
+		}
+		seen[i] = struct{}{}
+		ret = append(ret, r.index.Documents[i])
+	}
+	return ret
+}
+
+// Relevant returns the index entries of the documents in the repository
+// that may contain statements about the vulnerability and product. An
+// empty vulnerability or product matches any document.
+func (r *Repository) Relevant(vulnID, product string) []vex.IndexEntry {
+	sub := &vex.Index{Documents: r.candidates(vulnID, product)}
+	return sub.Relevant(vulnID, product)
+}
+
+// Documents reads the documents in the repository relevant to the
+// vulnerability and product. Each document is verified against the digest
+// recorded when it was synced.
+func (r *Repository) Documents(vulnID, product string) ([]*vex.VEX, error) {
+	sub := &vex.Index{Documents: r.candidates(vulnID, product)}
+	return sub.Fetch(vex.DirectoryFetcher(r.dir), vulnID, product)
+}
+
+// Matches returns the statements of the documents in the repository that
+// apply to the vulnerability, product and subcomponents.
+func (r *Repository) Matches(vulnID, product string, subcomponents []string) ([]vex.Statement, error) {
+	docs, err := r.Documents(vulnID, product)
+	if err != nil {
+		return nil, err
+	}
+	ret := []vex.Statement{}
+	for _, doc := range docs {
+		ret = append(ret, doc.Matches(vulnID, product, subcomponents)...)
+	}
+	return ret, nil
+}
@@ -0,0 +1,68 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package repo
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/openvex/go-vex/pkg/vex"
+)
+
+// serve publishes a directory over HTTP for the duration of the test.
+func serve(t *testing.T, dir string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.FileServer(http.Dir(dir)))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestLookups(t *testing.T) {
+	remote := t.TempDir()
+	writeDocument(t, filepath.Join(remote, "git.json"), "https://example.com/vex/git", 1, "CVE-2023-0001", "pkg:apk/wolfi/git@2.41.0")
+	writeDocument(t, filepath.Join(remote, "curl.json"), "https://example.com/vex/curl", 1, "CVE-2023-0002", "pkg:apk/wolfi/curl@8.1.0")
+	writeDocument(t, filepath.Join(remote, "image.json"), "https://example.com/vex/image", 1, "CVE-2023-0002", "sha256:1234567890abcdef1234567890abcdef1234567890abcdef1234567890abcdef")
+	writeDocument(t, filepath.Join(remote, "all.json"), "https://example.com/vex/all", 1, "CVE-2023-0003", "*")
+	publish(t, remote)
+
+	r, err := Open(t.TempDir())
+	require.NoError(t, err)
+	_, err = r.Sync(context.Background(), Source{Name: "wolfi", URL: serve(t, remote).URL})
+	require.NoError(t, err)
+
+	for m, tc := range map[string]struct {
+		vuln     string
+		product  string
+		expected []string
+	}{
+		"by vulnerability":      {"CVE-2023-0002", "", []string{"wolfi/curl.json", "wolfi/image.json"}},
+		"by vulnerability iri":  {"https://nvd.nist.gov/vuln/detail/CVE-2023-0001", "", []string{"wolfi/git.json"}},
+		"by purl":               {"", "pkg:apk/wolfi/git@2.41.0", []string{"wolfi/all.json", "wolfi/git.json"}},
+		"by vulnerability purl": {"CVE-2023-0002", "pkg:apk/wolfi/curl@8.1.0", []string{"wolfi/curl.json"}},
+		"wildcard product":      {"CVE-2023-0003", "pkg:apk/wolfi/git@2.41.0", []string{"wolfi/all.json"}},
+		"other version":         {"", "pkg:apk/wolfi/git@2.42.0", []string{"wolfi/all.json"}},
+		"unknown vulnerability": {"CVE-2023-9999", "", []string{}},
+		"everything":            {"", "", []string{"wolfi/all.json", "wolfi/curl.json", "wolfi/git.json", "wolfi/image.json"}},
+	} {
+		locations := []string{}
+		for _, entry := range r.Relevant(tc.vuln, tc.product) {
+			locations = append(locations, entry.Location)
+		}
+		require.ElementsMatch(t, tc.expected, locations, m)
+
+		docs, err := r.Documents(tc.vuln, tc.product)
+		require.NoError(t, err, m)
+		require.Len(t, docs, len(tc.expected), m)
+	}
+
+	statements, err := r.Matches("CVE-2023-0001", "pkg:apk/wolfi/git@2.41.0", nil)
+	require.NoError(t, err)
+	require.Len(t, statements, 1)
+	require.Equal(t, vex.StatusNotAffected, statements[0].Status)
+}
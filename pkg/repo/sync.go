@@ -0,0 +1,274 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package repo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/openvex/go-vex/pkg/fetch"
+	"github.com/openvex/go-vex/pkg/tracing"
+	"github.com/openvex/go-vex/pkg/vex"
+)
+
+// Source is a remote collection of VEX documents.
+type Source struct {
+	// Name identifies the collection in the repository. It is the name of
+	// the subdirectory where the collection is stored.
+	Name string
+
+	// URL is the base URL of the collection. Its index is read from
+	// URL/index.json and the document locations are relative to it.
+	URL string
+}
+
+// SyncResult summarizes the changes to a collection made by a sync.
+type SyncResult struct {
+	// Source is the name of the collection.
+	Source string
+
+	// Added, Updated, Removed and Unchanged count the documents of the
+	// collection in each case.
+	Added     int
+	Updated   int
+	Removed   int
+	Unchanged int
+}
+
+// Sync mirrors the collections into the repository. Only the documents
+// that are new or that changed since the last sync are downloaded, and the
+// documents no longer in a collection are deleted. Downloaded documents
+// are verified against the digest in the collection index.
+//
+// A document is updated when its version is higher than the local copy or,
+// with the same version, when it was updated later. Remote documents older
+// than the local copy are not synced so a stale mirror cannot roll the
+// repository back.
+//
+// Sync continues with the other documents and collections when one fails
+// and returns an error joining all the failures.
+func (r *Repository) Sync(ctx context.Context, sources ...Source) (results []SyncResult, err error) {
+	ctx, span := tracing.Start(ctx, "repo.Sync")
+	defer func() { span.End(err) }()
+
+	fetcher := fetch.New(r.opts.fetchOptions...)
+	results = []SyncResult{}
+	errs := []error{}
+	for _, src := range sources {
+		res, err := r.syncSource(ctx, fetcher, src)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("syncing %s: %w", src.Name, err))
+		}
+		results = append(results, res)
+	}
+
+	if err := r.reindex(); err != nil {
+		errs = append(errs, err)
+	}
+	return results, errors.Join(errs...)
+}
+
+// syncSource mirrors a collection into its subdirectory.
+func (r *Repository) syncSource(ctx context.Context, fetcher *fetch.Fetcher, src Source) (SyncResult, error) {
+	res := SyncResult{Source: src.Name}
+	if src.Name == "" || strings.ContainsAny(src.Name, `/\`) || !filepath.IsLocal(src.Name) {
+		return res, fmt.Errorf("invalid source name %q", src.Name)
+	}
+	base := strings.TrimSuffix(src.URL, "/")
+	dir := filepath.Join(r.dir, src.Name)
+
+	doc, err := fetcher.Get(ctx, base+"/"+IndexFile)
+	if err != nil {
+		return res, fmt.Errorf("fetching collection index: %w", err)
+	}
+	remote := &vex.Index{}
+	if err := json.Unmarshal(doc.Data, remote); err != nil {
+		return res, fmt.Errorf("parsing collection index: %w", err)
+	}
+
+	local, err := vex.LoadIndex(filepath.Join(dir, IndexFile))
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		local = &vex.Index{}
+	case err != nil:
+		return res, err
+	}
+	current := map[string]*vex.IndexEntry{}
+	for i := range local.Documents {
+		current[local.Documents[i].Location] = &local.Documents[i]
+	}
+
+	synced := &vex.Index{Timestamp: remote.Timestamp, Documents: []vex.IndexEntry{}}
+	errs := []error{}
+	for i := range remote.Documents {
+		entry := &remote.Documents[i]
+		if !filepath.IsLocal(filepath.FromSlash(entry.Location)) {
+			errs = append(errs, fmt.Errorf("document location %q is outside of the collection", entry.Location))
+			continue
+		}
+
+		prev, ok := current[entry.Location]
+		delete(current, entry.Location)
+		if ok {
+			if _, err := os.Stat(filepath.Join(dir, filepath.FromSlash(entry.Location))); err != nil {
+				ok = false
+			}
+		}
+		if ok && (prev.Digest == entry.Digest || !supersedes(entry, prev)) {
+			synced.Documents = append(synced.Documents, *prev)
+			res.Unchanged++
+			continue
+		}
+
+		if err := downloadDocument(ctx, fetcher, base, dir, entry); err != nil {
+			errs = append(errs, err)
+			if ok {
+				synced.Documents = append(synced.Documents, *prev)
+			}
+			continue
+		}
+		synced.Documents = append(synced.Documents, *entry)
+		if ok {
+			res.Updated++
+		} else {
+			res.Added++
+		}
+	}
+
+	for location := range current {
+		if err := os.Remove(filepath.Join(dir, filepath.FromSlash(location))); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			errs = append(errs, fmt.Errorf("removing %s: %w", location, err))
+			continue
+		}
+		res.Removed++
+	}
+
+	if err := writeIndex(filepath.Join(dir, IndexFile), synced); err != nil {
+		errs = append(errs, err)
+	}
+	return res, errors.Join(errs...)
+}
+
+// supersedes returns true if the remote index entry of a document replaces
+// the local one. Documents are compared by version and then by their last
+// update. Changes without a new version or update date are synced.
+func supersedes(remote, local *vex.IndexEntry) bool {
+	switch {
+	case remote.Version != local.Version:
+		return remote.Version > local.Version
+	case remote.LastUpdated != nil && local.LastUpdated != nil && !remote.LastUpdated.Equal(*local.LastUpdated):
+		return remote.LastUpdated.After(*local.LastUpdated)
+	default:
+		return true
+	}
+}
+
+// downloadDocument fetches a document of a collection, verifies it against
+// its index entry and stores it in the collection directory.
+func downloadDocument(ctx context.Context, fetcher *fetch.Fetcher, base, dir string, entry *vex.IndexEntry) error {
+	doc, err := fetcher.Get(ctx, base+"/"+entry.Location)
+	if err != nil {
+		return err
+	}
+	parsed, err := vex.Parse(doc.Data)
+	if err != nil {
+		return fmt.Errorf("parsing %s: %w", entry.Location, err)
+	}
+	if err := parsed.VerifyDigest(entry.Digest); err != nil {
+		return fmt.Errorf("verifying %s: %w", entry.Location, err)
+	}
+	if err := writeFile(filepath.Join(dir, filepath.FromSlash(entry.Location)), doc.Data); err != nil {
+		return fmt.Errorf("writing %s: %w", entry.Location, err)
+	}
+	return nil
+}
+
+// reindex rebuilds the combined repository index from the indexes of the
+// collections.
+func (r *Repository) reindex() error {
+	dirs, err := os.ReadDir(r.dir)
+	if err != nil {
+		return fmt.Errorf("reading repository directory: %w", err)
+	}
+
+	now := time.Now()
+	if r.opts.clock != nil {
+		now = r.opts.clock.Now()
+	}
+	index := &vex.Index{Timestamp: &now, Documents: []vex.IndexEntry{}}
+	for _, d := range dirs {
+		if !d.IsDir() {
+			continue
+		}
+		collection, err := vex.LoadIndex(filepath.Join(r.dir, d.Name(), IndexFile))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		for i := range collection.Documents {
+			entry := collection.Documents[i]
+			entry.Location = path.Join(d.Name(), entry.Location)
+			index.Documents = append(index.Documents, entry)
+		}
+	}
+	sort.Slice(index.Documents, func(i, j int) bool {
+		return index.Documents[i].Location < index.Documents[j].Location
+	})
+
+	if err := writeIndex(filepath.Join(r.dir, IndexFile), &repositoryIndex{FormatVersion: FormatVersion, Index: *index}); err != nil {
+		return err
+	}
+	r.setIndex(index)
+	return nil
+}
+
+// writeIndex serializes an index to the file.
+func writeIndex(file string, index any) error {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(index); err != nil {
+		return fmt.Errorf("encoding index: %w", err)
+	}
+	if err := writeFile(file, b.Bytes()); err != nil {
+		return fmt.Errorf("writing index: %w", err)
+	}
+	return nil
+}
+
+// writeFile writes the data to a temporary file next to the file and
+// renames it so readers never see partial files. Missing directories are
+// created.
+func writeFile(file string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(file), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name()) //nolint:errcheck // Already renamed on success
+
+	if _, err := f.Write(data); err != nil {
+		f.Close() //nolint:errcheck,gosec
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), file)
+}
@@ -0,0 +1,141 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package repo
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/openvex/go-vex/pkg/vex"
+)
+
+// writeDocument writes an OpenVEX document with a statement about the
+// vulnerability and product to the file.
+func writeDocument(t *testing.T, file, id string, version int, vuln, product string) {
+	t.Helper()
+	ts := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+	doc := vex.New()
+	doc.ID = id
+	doc.Author = "Example Corp"
+	doc.Version = version
+	doc.Timestamp = &ts
+	doc.Statements = []vex.Statement{{
+		Vulnerability: vex.Vulnerability{Name: vex.VulnerabilityID(vuln)},
+		Products:      []vex.Product{{Component: vex.Component{ID: product}}},
+		Status:        vex.StatusNotAffected,
+		Justification: vex.ComponentNotPresent,
+	}}
+
+	require.NoError(t, os.MkdirAll(filepath.Dir(file), 0o755))
+	f, err := os.Create(file)
+	require.NoError(t, err)
+	defer f.Close()
+	require.NoError(t, doc.ToJSON(f))
+}
+
+// publish regenerates the index of a collection directory.
+func publish(t *testing.T, dir string) {
+	t.Helper()
+	index, err := vex.GenerateIndex(dir)
+	require.NoError(t, err)
+	f, err := os.Create(filepath.Join(dir, IndexFile))
+	require.NoError(t, err)
+	defer f.Close()
+	require.NoError(t, index.ToJSON(f))
+}
+
+func TestSync(t *testing.T) {
+	remote := t.TempDir()
+	srv := serve(t, remote)
+
+	writeDocument(t, filepath.Join(remote, "a.json"), "https://example.com/vex/a", 1, "CVE-2023-0001", "pkg:apk/wolfi/git@2.41.0")
+	writeDocument(t, filepath.Join(remote, "sub", "b.json"), "https://example.com/vex/b", 1, "CVE-2023-0002", "pkg:golang/example.com/module@v1.0.0")
+	publish(t, remote)
+
+	r, err := Open(t.TempDir())
+	require.NoError(t, err)
+	src := Source{Name: "example", URL: srv.URL}
+
+	results, err := r.Sync(context.Background(), src)
+	require.NoError(t, err)
+	require.Equal(t, []SyncResult{{Source: "example", Added: 2}}, results)
+	require.Len(t, r.Index().Documents, 2)
+	require.Equal(t, "example/a.json", r.Index().Documents[0].Location)
+	require.FileExists(t, filepath.Join(r.Dir(), "example", "sub", "b.json"))
+
+	// Nothing changed
+	results, err = r.Sync(context.Background(), src)
+	require.NoError(t, err)
+	require.Equal(t, []SyncResult{{Source: "example", Unchanged: 2}}, results)
+
+	// A new version of a, b removed and c added
+	writeDocument(t, filepath.Join(remote, "a.json"), "https://example.com/vex/a", 2, "CVE-2023-0003", "pkg:apk/wolfi/git@2.41.0")
+	require.NoError(t, os.Remove(filepath.Join(remote, "sub", "b.json")))
+	writeDocument(t, filepath.Join(remote, "c.json"), "https://example.com/vex/c", 1, "CVE-2023-0004", "pkg:apk/wolfi/curl@8.1.0")
+	publish(t, remote)
+
+	results, err = r.Sync(context.Background(), src)
+	require.NoError(t, err)
+	require.Equal(t, []SyncResult{{Source: "example", Added: 1, Updated: 1, Removed: 1}}, results)
+	require.NoFileExists(t, filepath.Join(r.Dir(), "example", "sub", "b.json"))
+
+	// Older versions do not roll back the local copy
+	writeDocument(t, filepath.Join(remote, "a.json"), "https://example.com/vex/a", 1, "CVE-2023-0001", "pkg:apk/wolfi/git@2.41.0")
+	publish(t, remote)
+	results, err = r.Sync(context.Background(), src)
+	require.NoError(t, err)
+	require.Equal(t, []SyncResult{{Source: "example", Unchanged: 2}}, results)
+	require.Len(t, r.Relevant("CVE-2023-0003", ""), 1)
+
+	// Reopening loads the synced index
+	reopened, err := Open(r.Dir())
+	require.NoError(t, err)
+	require.Equal(t, r.Index().Documents, reopened.Index().Documents)
+
+	// Invalid sources are reported without stopping the sync
+	results, err = r.Sync(context.Background(), Source{Name: "../escape", URL: srv.URL}, src)
+	require.Error(t, err)
+	require.Len(t, results, 2)
+	require.Equal(t, 2, results[1].Unchanged)
+}
+
+func TestSupersedes(t *testing.T) {
+	t1 := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+	t2 := t1.Add(time.Hour)
+	for m, tc := range map[string]struct {
+		remote, local vex.IndexEntry
+		expected      bool
+	}{
+		"higher version": {vex.IndexEntry{Version: 2}, vex.IndexEntry{Version: 1}, true},
+		"lower version":  {vex.IndexEntry{Version: 1}, vex.IndexEntry{Version: 2}, false},
+		"later update":   {vex.IndexEntry{Version: 1, LastUpdated: &t2}, vex.IndexEntry{Version: 1, LastUpdated: &t1}, true},
+		"earlier update": {vex.IndexEntry{Version: 1, LastUpdated: &t1}, vex.IndexEntry{Version: 1, LastUpdated: &t2}, false},
+		"same version":   {vex.IndexEntry{Version: 1}, vex.IndexEntry{Version: 1}, true},
+	} {
+		require.Equal(t, tc.expected, supersedes(&tc.remote, &tc.local), m)
+	}
+}
+
+func TestSyncWithClock(t *testing.T) {
+	remote := t.TempDir()
+	srv := serve(t, remote)
+	writeDocument(t, filepath.Join(remote, "a.json"), "https://example.com/vex/a", 1, "CVE-2023-0001", "pkg:apk/wolfi/git@2.41.0")
+	publish(t, remote)
+
+	now := time.Date(2023, 7, 1, 0, 0, 0, 0, time.UTC)
+	r, err := Open(t.TempDir(), WithClock(vex.FixedClock(now)))
+	require.NoError(t, err)
+	_, err = r.Sync(context.Background(), Source{Name: "example", URL: srv.URL})
+	require.NoError(t, err)
+	require.Equal(t, now, *r.Index().Timestamp)
+
+	index, err := loadRepositoryIndex(filepath.Join(r.Dir(), IndexFile))
+	require.NoError(t, err)
+	require.True(t, now.Equal(*index.Timestamp))
+}
@@ -0,0 +1,24 @@
+module github.com/openvex/go-vex/pkg/sarif
+
+go 1.22
+
+require (
+	github.com/openvex/go-vex v0.4.0
+	github.com/owenrumney/go-sarif v1.1.1
+	github.com/stretchr/testify v1.11.1
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/package-url/packageurl-go v0.1.3 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/zclconf/go-cty v1.10.0 // indirect
+	golang.org/x/text v0.14.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+// The SARIF module is tagged together with the core module and requires
+// the core release it is tagged with, v0.4.0 being the first one with the
+// core APIs it uses (vex.DocumentOptions). Builds inside the repository use
+// the core packages in the same tree. See "Releasing" in the README.
+replace github.com/openvex/go-vex => ../..
@@ -0,0 +1,212 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package sarif
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	gosarif "github.com/owenrumney/go-sarif/sarif"
+
+	"github.com/openvex/go-vex/pkg/vex"
+)
+
+const (
+	// SuppressionKindExternal is the SARIF kind of suppressions recorded
+	// outside of the scanned sources, such as in a VEX document.
+	SuppressionKindExternal = "external"
+
+	// SuppressionStatusAccepted is the SARIF status of suppressions that
+	// are in effect.
+	SuppressionStatusAccepted = "accepted"
+)
+
+// Properties added to the suppressions to trace them to the VEX statement.
+const (
+	PropertyStatus        = "openvex.status"
+	PropertyJustification = "openvex.justification"
+	PropertyStatement     = "openvex.statement"
+	PropertyDocument      = "openvex.document"
+)
+
+// resultPurlProperty is the result property read as the purl of the
+// affected package.
+const resultPurlProperty = "purl"
+
+// vulnPrefix extracts the vulnerability identifier from rule IDs that
+// append the package name to it, eg CVE-2023-1234-curl.
+var vulnPrefix = regexp.MustCompile(`^(CVE-\d{4}-\d+|GHSA(-[23456789cfghjmpqrvwx]{4}){3})`)
+
+// SuppressOptions configure Suppress.
+type SuppressOptions struct {
+	// Product is the identifier of the scanned product, for example the
+	// purl of a container image. When set, the packages of the results are
+	// matched as subcomponents of the product. Otherwise, they are matched
+	// as products.
+	Product string
+}
+
+// Suppress returns a copy of the report where the results covered by a
+// not_affected or fixed statement of the VEX document carry an external,
+// accepted suppression justified by the statement. The input report is not
+// modified.
+//
+// The vulnerability of a result is its rule ID, or the CVE or GHSA the
+// rule ID starts with. The affected packages are the purls found in the
+// result "purl" property and in the URIs and fully qualified names of the
+// result locations. Results listing several packages are only suppressed
+// when the statements cover all of them.
+func Suppress(report *Report, doc *vex.VEX, opts *SuppressOptions) (*Report, error) {
+	if opts == nil {
+		opts = &SuppressOptions{}
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		return nil, fmt.Errorf("copying report: %w", err)
+	}
+	ret := New()
+	if err := json.Unmarshal(data, ret); err != nil {
+		return nil, fmt.Errorf("copying report: %w", err)
+	}
+
+	for _, run := range ret.Runs {
+		for _, result := range run.Results {
+			if result.RuleID == nil {
+				continue
+			}
+			stmt := coveringStatement(doc, *result.RuleID, resultPurls(run, result), opts)
+			if stmt == nil {
+				continue
+			}
+			result.Suppressions = append(result.Suppressions, newSuppression(doc, stmt))
+		}
+	}
+	return ret, nil
+}
+
+// coveringStatement returns the statement that sets the effective not_affected
+// or fixed status of the vulnerability in all the packages, or nil if the
+// vulnerability is not resolved in any of them.
+func coveringStatement(doc *vex.VEX, ruleID string, purls []string, opts *SuppressOptions) *vex.Statement {
+	vulns := []string{ruleID}
+	if prefix := vulnPrefix.FindString(ruleID); prefix != "" && prefix != ruleID {
+		vulns = append(vulns, prefix)
+	}
+
+	type query struct {
+		product       string
+		subcomponents []string
+	}
+	queries := []query{}
+	switch {
+	case opts.Product != "" && len(purls) == 0:
+		queries = append(queries, query{product: opts.Product})
+	case opts.Product != "":
+		for _, p := range purls {
+			queries = append(queries, query{product: opts.Product, subcomponents: []string{p}})
+		}
+	default:
+		for _, p := range purls {
+			queries = append(queries, query{product: p})
+		}
+	}
+	if len(queries) == 0 {
+		return nil
+	}
+
+	var covering *vex.Statement
+	for _, q := range queries {
+		var latest *vex.Statement
+		for _, vuln := range vulns {
+			// Queries without subcomponents must not match statements
+			// about specific subcomponents of the product.
+			matches := doc.MatchesWithOptions(vuln, q.product, q.subcomponents, &vex.MatchOptions{
+				Subcomponents: vex.SubcomponentsStrict,
+			})
+			if len(matches) > 0 {
+				latest = &matches[len(matches)-1].Statement
+				break
+			}
+		}
+		if latest == nil || (latest.Status != vex.StatusNotAffected && latest.Status != vex.StatusFixed) {
+			return nil
+		}
+		if covering == nil {
+			covering = latest
+		}
+	}
+	return covering
+}
+
+// resultPurls returns the purls of the packages a result is about.
+func resultPurls(run *gosarif.Run, result *gosarif.Result) []string {
+	seen := map[string]struct{}{}
+	ret := []string{}
+	add := func(s *string) {
+		if s == nil || !strings.HasPrefix(*s, "pkg:") {
+			return
+		}
+		if _, ok := seen[*s]; ok {
+			return
+		}
+		seen[*s] = struct{}{}
+		ret = append(ret, *s)
+	}
+
+	if p, ok := result.Properties[resultPurlProperty].(string); ok {
+		add(&p)
+	}
+	for _, loc := range result.Locations {
+		if loc == nil {
+			continue
+		}
+		if loc.PhysicalLocation != nil && loc.PhysicalLocation.ArtifactLocation != nil {
+			al := loc.PhysicalLocation.ArtifactLocation
+			add(al.URI)
+			if al.Index != nil && int(*al.Index) < len(run.Artifacts) {
+				if a := run.Artifacts[*al.Index]; a != nil && a.Location != nil {
+					add(a.Location.URI)
+				}
+			}
+		}
+		for _, ll := range loc.LogicalLocations {
+			if ll != nil {
+				add(ll.FullyQualifiedName)
+			}
+		}
+	}
+	return ret
+}
+
+// newSuppression returns the suppression recording the statement.
+func newSuppression(doc *vex.VEX, stmt *vex.Statement) *gosarif.Suppression {
+	justification := string(stmt.Status)
+	if stmt.Justification != "" {
+		justification += ": " + string(stmt.Justification)
+	}
+	if stmt.ImpactStatement != "" {
+		justification += ": " + stmt.ImpactStatement
+	}
+	if stmt.StatusNotes != "" {
+		justification += " (" + stmt.StatusNotes + ")"
+	}
+
+	s := gosarif.NewSuppression(SuppressionKindExternal).
+		WithStatus(SuppressionStatusAccepted).
+		WithJustifcation(justification)
+	s.Properties = gosarif.Properties{PropertyStatus: string(stmt.Status)}
+	if stmt.Justification != "" {
+		s.Properties[PropertyJustification] = string(stmt.Justification)
+	}
+	if stmt.ID != "" {
+		s.Properties[PropertyStatement] = stmt.ID
+	}
+	if doc.ID != "" {
+		s.Properties[PropertyDocument] = doc.ID
+	}
+	return s
+}
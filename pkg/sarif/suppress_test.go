@@ -0,0 +1,169 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package sarif
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/openvex/go-vex/pkg/vex"
+)
+
+const testReport = `{
+  "version": "2.1.0",
+  "$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+  "runs": [{
+    "tool": {"driver": {"name": "scanner"}},
+    "artifacts": [{"location": {"uri": "pkg:apk/wolfi/openssl@3.1.0"}}],
+    "results": [
+      {
+        "ruleId": "CVE-2023-1234",
+        "message": {"text": "not affected"},
+        "properties": {"purl": "pkg:apk/wolfi/git@2.39.0"}
+      },
+      {
+        "ruleId": "CVE-2023-5678-curl",
+        "message": {"text": "fixed"},
+        "locations": [{"physicalLocation": {"artifactLocation": {"uri": "pkg:apk/wolfi/curl@8.1.0"}}}]
+      },
+      {
+        "ruleId": "CVE-2023-9999",
+        "message": {"text": "affected"},
+        "locations": [{"physicalLocation": {"artifactLocation": {"index": 0}}}]
+      },
+      {
+        "ruleId": "CVE-2023-1234",
+        "message": {"text": "not covered"},
+        "properties": {"purl": "pkg:apk/wolfi/bash@5.2"}
+      },
+      {
+        "message": {"text": "no rule"},
+        "properties": {"purl": "pkg:apk/wolfi/git@2.39.0"}
+      }
+    ]
+  }]
+}`
+
+func testSuppressDocument(t *testing.T, product string) *vex.VEX {
+	t.Helper()
+	products := func(purl string) string {
+		if product == "" {
+			return `[{"@id": "` + purl + `"}]`
+		}
+		return `[{"@id": "` + product + `", "subcomponents": [{"@id": "` + purl + `"}]}]`
+	}
+	doc, err := vex.Parse([]byte(`{
+  "@context": "https://openvex.dev/ns/v0.2.0",
+  "@id": "https://openvex.dev/docs/example/vex-9fb3463de1b57",
+  "author": "Wolfi J Inkinson",
+  "timestamp": "2023-01-08T18:02:03Z",
+  "version": 1,
+  "statements": [
+    {
+      "@id": "stmt-1",
+      "vulnerability": {"name": "CVE-2023-1234"},
+      "products": ` + products("pkg:apk/wolfi/git@2.39.0") + `,
+      "status": "not_affected",
+      "justification": "vulnerable_code_not_in_execute_path"
+    },
+    {
+      "vulnerability": {"name": "CVE-2023-5678"},
+      "products": ` + products("pkg:apk/wolfi/curl@8.1.0") + `,
+      "status": "fixed"
+    },
+    {
+      "vulnerability": {"name": "CVE-2023-9999"},
+      "products": ` + products("pkg:apk/wolfi/openssl@3.1.0") + `,
+      "status": "affected",
+      "action_statement": "Upgrade openssl"
+    }
+  ]
+}`))
+	require.NoError(t, err)
+	return doc
+}
+
+func TestSuppress(t *testing.T) {
+	for m, tc := range map[string]struct {
+		product string
+		opts    *SuppressOptions
+		expect  []bool
+	}{
+		"packages as products": {
+			expect: []bool{true, true, false, false, false},
+		},
+		"packages as subcomponents": {
+			product: "pkg:oci/wolfi-base@sha256:3f8f3d7a",
+			opts:    &SuppressOptions{Product: "pkg:oci/wolfi-base@sha256:3f8f3d7a"},
+			expect:  []bool{true, true, false, false, false},
+		},
+		"subcomponent statements without product": {
+			product: "pkg:oci/wolfi-base@sha256:3f8f3d7a",
+			expect:  []bool{false, false, false, false, false},
+		},
+	} {
+		t.Run(m, func(t *testing.T) {
+			report := New()
+			require.NoError(t, json.Unmarshal([]byte(testReport), report))
+			doc := testSuppressDocument(t, tc.product)
+
+			suppressed, err := Suppress(report, doc, tc.opts)
+			require.NoError(t, err, m)
+
+			require.Len(t, suppressed.Runs, 1)
+			require.Len(t, suppressed.Runs[0].Results, len(tc.expect))
+			for i, result := range suppressed.Runs[0].Results {
+				if !tc.expect[i] {
+					require.Empty(t, result.Suppressions, "result #%d", i)
+					continue
+				}
+				require.Len(t, result.Suppressions, 1, "result #%d", i)
+			}
+
+			// The input report is not modified
+			for _, result := range report.Runs[0].Results {
+				require.Empty(t, result.Suppressions)
+			}
+		})
+	}
+}
+
+func TestSuppressionFields(t *testing.T) {
+	report := New()
+	require.NoError(t, json.Unmarshal([]byte(testReport), report))
+	doc := testSuppressDocument(t, "")
+
+	suppressed, err := Suppress(report, doc, nil)
+	require.NoError(t, err)
+
+	s := suppressed.Runs[0].Results[0].Suppressions[0]
+	require.Equal(t, SuppressionKindExternal, s.Kind)
+	require.NotNil(t, s.Status)
+	require.Equal(t, SuppressionStatusAccepted, *s.Status)
+	require.NotNil(t, s.Justification)
+	require.Equal(t, "not_affected: vulnerable_code_not_in_execute_path", *s.Justification)
+	require.Equal(t, "not_affected", s.Properties[PropertyStatus])
+	require.Equal(t, "vulnerable_code_not_in_execute_path", s.Properties[PropertyJustification])
+	require.Equal(t, "stmt-1", s.Properties[PropertyStatement])
+	require.Equal(t, "https://openvex.dev/docs/example/vex-9fb3463de1b57", s.Properties[PropertyDocument])
+
+	s = suppressed.Runs[0].Results[1].Suppressions[0]
+	require.NotNil(t, s.Justification)
+	require.Equal(t, "fixed", *s.Justification)
+	require.Equal(t, "fixed", s.Properties[PropertyStatus])
+	require.NotContains(t, s.Properties, PropertyJustification)
+	require.NotContains(t, s.Properties, PropertyStatement)
+}
+
+func TestResultPurls(t *testing.T) {
+	report := New()
+	require.NoError(t, json.Unmarshal([]byte(testReport), report))
+	run := report.Runs[0]
+
+	require.Equal(t, []string{"pkg:apk/wolfi/git@2.39.0"}, resultPurls(run, run.Results[0]))
+	require.Equal(t, []string{"pkg:apk/wolfi/curl@8.1.0"}, resultPurls(run, run.Results[1]))
+	require.Equal(t, []string{"pkg:apk/wolfi/openssl@3.1.0"}, resultPurls(run, run.Results[2]))
+}
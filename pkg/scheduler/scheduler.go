@@ -0,0 +1,342 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package scheduler centralizes the outbound HTTP requests of the library
+// (registry lookups, vulnerability databases, discovery endpoints) behind a
+// transport that enforces a global rate limit, caps the concurrent requests
+// sent to each host and deduplicates identical requests in flight.
+package scheduler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMaxSharedBody is the default cap of the size of the response bodies
+// buffered to share them among deduplicated requests.
+const DefaultMaxSharedBody = 32 << 20
+
+// DefaultSharedTimeout is the default time limit of the requests shared by
+// deduplicated callers, including reading their response.
+const DefaultSharedTimeout = 5 * time.Minute
+
+// ErrBodyTooLarge is returned when the response to a deduplicated request
+// is larger than the bodies the scheduler buffers to share.
+var ErrBodyTooLarge = errors.New("response body is too large to share")
+
+// Options control the limits enforced by a Scheduler.
+type Options struct {
+	// RequestsPerSecond is the global rate limit for all requests. Zero
+	// disables rate limiting.
+	RequestsPerSecond float64
+
+	// MaxPerHost caps the number of concurrent requests to a single host.
+	// Zero means no limit.
+	MaxPerHost int
+
+	// Transport performs the actual requests. If nil, the default http
+	// transport is used.
+	Transport http.RoundTripper
+
+	// MaxSharedBody caps the size of the response bodies of deduplicated
+	// requests, which are buffered to share them. Larger responses fail
+	// with ErrBodyTooLarge instead of being truncated. Zero means
+	// DefaultMaxSharedBody.
+	MaxSharedBody int64
+
+	// SharedTimeout limits the time a deduplicated request, including
+	// reading its response, may take. Shared requests run detached from
+	// the context of their callers so a stalled one would otherwise block
+	// all of them. Zero means DefaultSharedTimeout.
+	SharedTimeout time.Duration
+}
+
+// DefaultOptions are the options of the default scheduler.
+var DefaultOptions = Options{
+	RequestsPerSecond: 20,
+	MaxPerHost:        8,
+}
+
+// Scheduler is an http.RoundTripper that schedules requests honoring the
+// configured limits. Concurrent GET and HEAD requests to the same URL with
+// the same credentials are sent only once and the response is shared.
+type Scheduler struct {
+	opts Options
+
+	rateMutex sync.Mutex
+	next      time.Time
+
+	hostsMutex sync.Mutex
+	hosts      map[string]chan struct{}
+
+	callsMutex sync.Mutex
+	calls      map[string]*call
+}
+
+// call is a request in flight shared by deduplicated requests.
+type call struct {
+	done   chan struct{}
+	status int
+	proto  string
+	header http.Header
+	body   []byte
+	err    error
+}
+
+// New returns a new scheduler with the specified options.
+func New(opts Options) *Scheduler {
+	return &Scheduler{
+		opts:  opts,
+		hosts: map[string]chan struct{}{},
+		calls: map[string]*call{},
+	}
+}
+
+var (
+	defaultMutex     sync.RWMutex
+	defaultScheduler = New(DefaultOptions)
+)
+
+// Default returns the scheduler used by the library for its outbound
+// requests.
+func Default() *Scheduler {
+	defaultMutex.RLock()
+	defer defaultMutex.RUnlock()
+	return defaultScheduler
+}
+
+// SetDefault replaces the scheduler used by the library.
+func SetDefault(s *Scheduler) {
+	defaultMutex.Lock()
+	defer defaultMutex.Unlock()
+	defaultScheduler = s
+}
+
+// Client returns an http client that sends its requests through the default
+// scheduler. The scheduler is looked up on every request, so the client
+// honors later calls to SetDefault.
+func Client() *http.Client {
+	return &http.Client{Transport: defaultTransport{}}
+}
+
+type defaultTransport struct{}
+
+func (defaultTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return Default().RoundTrip(req)
+}
+
+// Client returns an http client that sends its requests through the
+// scheduler.
+func (s *Scheduler) Client() *http.Client {
+	return &http.Client{Transport: s}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (s *Scheduler) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !dedupable(req) {
+		return s.send(req)
+	}
+
+	key := requestKey(req)
+	s.callsMutex.Lock()
+	c, inFlight := s.calls[key]
+	if !inFlight {
+		c = &call{done: make(chan struct{})}
+		s.calls[key] = c
+	}
+	s.callsMutex.Unlock()
+
+	// The shared request runs detached from the context of the caller that
+	// started it so canceling one caller does not fail the others. It gets
+	// its own deadline instead.
+	if !inFlight {
+		timeout := s.opts.SharedTimeout
+		if timeout <= 0 {
+			timeout = DefaultSharedTimeout
+		}
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(req.Context()), timeout)
+		go func() {
+			defer cancel()
+			s.lead(req.Clone(ctx), key, c)
+		}()
+	}
+
+	select {
+	case <-c.done:
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	}
+
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", c.status, http.StatusText(c.status)),
+		StatusCode:    c.status,
+		Proto:         c.proto,
+		Header:        c.header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(c.body)),
+		ContentLength: int64(len(c.body)),
+		Request:       req,
+	}, nil
+}
+
+// lead performs the request on behalf of all the deduplicated callers and
+// buffers the response to share it.
+func (s *Scheduler) lead(req *http.Request, key string, c *call) {
+	defer func() {
+		s.callsMutex.Lock()
+		delete(s.calls, key)
+		s.callsMutex.Unlock()
+		close(c.done)
+	}()
+
+	res, err := s.send(req)
+	if err != nil {
+		c.err = err
+		return
+	}
+	defer res.Body.Close() //nolint:errcheck
+
+	maxSize := s.opts.MaxSharedBody
+	if maxSize <= 0 {
+		maxSize = DefaultMaxSharedBody
+	}
+	c.body, c.err = io.ReadAll(io.LimitReader(res.Body, maxSize+1))
+	if c.err == nil && int64(len(c.body)) > maxSize {
+		c.body, c.err = nil, fmt.Errorf("%w: %s is larger than %d bytes", ErrBodyTooLarge, req.URL, maxSize)
+	}
+	c.status = res.StatusCode
+	c.proto = res.Proto
+	c.header = res.Header
+}
+
+// send waits for the rate limiter and a free slot for the host and sends
+// the request.
+func (s *Scheduler) send(req *http.Request) (*http.Response, error) {
+	if err := s.wait(req.Context()); err != nil {
+		return nil, err
+	}
+
+	var slot chan struct{}
+	if s.opts.MaxPerHost > 0 {
+		slot = s.hostSlot(req.URL.Host)
+		select {
+		case slot <- struct{}{}:
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+		// Release the slot if the request fails, the body releases it
+		// otherwise
+		defer func() {
+			if slot != nil {
+				<-slot
+			}
+		}()
+	}
+
+	transport := s.opts.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	res, err := transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Hold the host slot until the body is closed
+	if s.opts.MaxPerHost > 0 {
+		held := slot
+		res.Body = &slotBody{ReadCloser: res.Body, release: func() { <-held }}
+		slot = nil
+	}
+	return res, nil
+}
+
+// slotBody is a response body that releases the host slot of its request
+// when closed.
+type slotBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+// Close closes the body and releases the host slot.
+func (b *slotBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
+
+// hostSlot returns the semaphore that caps the requests to a host.
+func (s *Scheduler) hostSlot(host string) chan struct{} {
+	s.hostsMutex.Lock()
+	defer s.hostsMutex.Unlock()
+	slot, ok := s.hosts[host]
+	if !ok {
+		slot = make(chan struct{}, s.opts.MaxPerHost)
+		s.hosts[host] = slot
+	}
+	return slot
+}
+
+// wait blocks until the global rate limit allows a new request.
+func (s *Scheduler) wait(ctx context.Context) error {
+	if s.opts.RequestsPerSecond <= 0 {
+		return nil
+	}
+	interval := time.Duration(float64(time.Second) / s.opts.RequestsPerSecond)
+
+	s.rateMutex.Lock()
+	now := time.Now()
+	if s.next.Before(now) {
+		s.next = now
+	}
+	delay := s.next.Sub(now)
+	s.next = s.next.Add(interval)
+	s.rateMutex.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// dedupable returns true if the request can be shared with identical
+// requests in flight.
+func dedupable(req *http.Request) bool {
+	return (req.Method == http.MethodGet || req.Method == http.MethodHead) &&
+		(req.Body == nil || req.Body == http.NoBody)
+}
+
+// requestKey identifies identical requests. All the headers are part of the
+// key as they may change the response (credentials, API keys, media types).
+func requestKey(req *http.Request) string {
+	keys := make([]string, 0, len(req.Header))
+	for k := range req.Header {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(req.Method + " " + req.URL.String())
+	for _, k := range keys {
+		b.WriteString("\n" + k + ": " + strings.Join(req.Header[k], ", "))
+	}
+	return b.String()
+}
@@ -0,0 +1,305 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeduplication(t *testing.T) {
+	var hits atomic.Int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		<-release
+		fmt.Fprintf(w, "hello %s", r.Header.Get("Authorization"))
+	}))
+	defer srv.Close()
+
+	client := New(Options{}).Client()
+	var wg sync.WaitGroup
+	bodies := make([]string, 5)
+	for i := range bodies {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, srv.URL, http.NoBody)
+			if err != nil {
+				return
+			}
+			// The last request has different credentials
+			if i == len(bodies)-1 {
+				req.Header.Set("Authorization", "Bearer other")
+			}
+			res, err := client.Do(req)
+			if err != nil {
+				return
+			}
+			defer res.Body.Close() //nolint:errcheck
+			data, err := io.ReadAll(res.Body)
+			if err == nil {
+				bodies[i] = string(data)
+			}
+		}()
+	}
+
+	// Let the requests reach the scheduler before releasing the server
+	for deadline := time.Now().Add(time.Second); hits.Load() < 2 && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	require.Equal(t, int32(2), hits.Load())
+	for i := range 4 {
+		require.Equal(t, "hello ", bodies[i])
+	}
+	require.Equal(t, "hello Bearer other", bodies[4])
+}
+
+func TestDeduplicationCancel(t *testing.T) {
+	release := make(chan struct{})
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		hits.Add(1)
+		<-release
+		fmt.Fprint(w, "shared")
+	}))
+	defer srv.Close()
+
+	s := New(Options{})
+	leaderCtx, cancel := context.WithCancel(context.Background())
+	leaderErr := make(chan error)
+	go func() {
+		req, err := http.NewRequestWithContext(leaderCtx, http.MethodGet, srv.URL, http.NoBody)
+		if err == nil {
+			_, err = s.RoundTrip(req)
+		}
+		leaderErr <- err
+	}()
+	for deadline := time.Now().Add(time.Second); hits.Load() < 1 && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+	}
+
+	// The follower joins the call started by the first request
+	followerRes := make(chan string)
+	go func() {
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, srv.URL, http.NoBody)
+		if err != nil {
+			followerRes <- ""
+			return
+		}
+		res, err := s.RoundTrip(req)
+		if err != nil {
+			followerRes <- err.Error()
+			return
+		}
+		defer res.Body.Close() //nolint:errcheck
+		data, err := io.ReadAll(res.Body)
+		if err != nil {
+			followerRes <- err.Error()
+			return
+		}
+		followerRes <- string(data)
+	}()
+	time.Sleep(20 * time.Millisecond)
+
+	// Canceling the first caller does not fail the shared request
+	cancel()
+	require.ErrorIs(t, <-leaderErr, context.Canceled)
+	close(release)
+	require.Equal(t, "shared", <-followerRes)
+	require.Equal(t, int32(1), hits.Load())
+}
+
+func TestMaxSharedBody(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, r.URL.Path)
+	}))
+	defer srv.Close()
+
+	s := New(Options{MaxSharedBody: 5})
+	for path, tooLarge := range map[string]bool{"/abc": false, "/abcd": false, "/abcdef": true} {
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, srv.URL+path, http.NoBody)
+		require.NoError(t, err)
+		res, err := s.RoundTrip(req)
+		if tooLarge {
+			require.ErrorIs(t, err, ErrBodyTooLarge, path)
+			continue
+		}
+		require.NoError(t, err, path)
+		data, err := io.ReadAll(res.Body)
+		res.Body.Close() //nolint:errcheck
+		require.NoError(t, err, path)
+		require.Equal(t, path, string(data))
+	}
+}
+
+func TestMaxPerHost(t *testing.T) {
+	var current, peak atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := current.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		current.Add(-1)
+		fmt.Fprint(w, r.URL.Path)
+	}))
+	defer srv.Close()
+
+	client := New(Options{MaxPerHost: 2}).Client()
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Different paths to avoid deduplication
+			req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, fmt.Sprintf("%s/%d", srv.URL, i), http.NoBody)
+			if err != nil {
+				return
+			}
+			if res, err := client.Do(req); err == nil {
+				res.Body.Close() //nolint:errcheck
+			}
+		}()
+	}
+	wg.Wait()
+	require.LessOrEqual(t, peak.Load(), int32(2))
+}
+
+func TestRateLimit(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, r.URL.Path)
+	}))
+	defer srv.Close()
+
+	s := New(Options{RequestsPerSecond: 50})
+	start := time.Now()
+	for i := range 4 {
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, fmt.Sprintf("%s/%d", srv.URL, i), http.NoBody)
+		require.NoError(t, err)
+		res, err := s.Client().Do(req)
+		require.NoError(t, err)
+		res.Body.Close() //nolint:errcheck
+	}
+	// Four requests at 50 rps take at least three intervals of 20ms
+	require.GreaterOrEqual(t, time.Since(start), 60*time.Millisecond)
+
+	// Waiting for the limiter honors the request context
+	s = New(Options{RequestsPerSecond: 0.1})
+	require.NoError(t, s.wait(context.Background()))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	require.Error(t, s.wait(ctx))
+}
+
+func TestDefault(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, "ok")
+	}))
+	defer srv.Close()
+
+	var used atomic.Bool
+	custom := New(Options{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		used.Store(true)
+		return http.DefaultTransport.RoundTrip(req)
+	})})
+	previous := Default()
+	SetDefault(custom)
+	defer SetDefault(previous)
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, srv.URL, http.NoBody)
+	require.NoError(t, err)
+	res, err := Client().Do(req)
+	require.NoError(t, err)
+	res.Body.Close() //nolint:errcheck
+	require.True(t, used.Load())
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestMaxPerHostStreamsBody(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, r.URL.Path)
+	}))
+	defer srv.Close()
+
+	s := New(Options{MaxPerHost: 1})
+	get := func(path string) (*http.Response, error) {
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, srv.URL+path, http.NoBody)
+		require.NoError(t, err)
+		return s.RoundTrip(req)
+	}
+
+	// The body is not buffered, the response holds the host slot until it
+	// is closed
+	res, err := get("/first")
+	require.NoError(t, err)
+
+	second := make(chan string, 1)
+	go func() {
+		res, err := get("/second")
+		if err != nil {
+			second <- err.Error()
+			return
+		}
+		defer res.Body.Close() //nolint:errcheck
+		data, err := io.ReadAll(res.Body)
+		if err != nil {
+			second <- err.Error()
+			return
+		}
+		second <- string(data)
+	}()
+
+	select {
+	case <-second:
+		t.Fatal("request sent before the host slot was released")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	data, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.Equal(t, "/first", string(data))
+	require.NoError(t, res.Body.Close())
+	require.NoError(t, res.Body.Close())
+	require.Equal(t, "/second", <-second)
+}
+
+func TestSharedTimeout(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	s := New(Options{SharedTimeout: 50 * time.Millisecond})
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, srv.URL, http.NoBody)
+	require.NoError(t, err)
+	_, err = s.RoundTrip(req)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,10 @@
+/*
+Copyright 2023 The OpenVEX Authors
+SPDX-License-Identifier: Apache-2.0
+*/
+
+// Package spdx provides a library to read the packages described in SPDX
+// 2.x documents (JSON format).
+//
+// https://spdx.github.io/spdx-spec/v2.3/
+package spdx
@@ -0,0 +1,180 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package spdx
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// DocumentID is the SPDX identifier of the document itself.
+const DocumentID = "SPDXRef-DOCUMENT"
+
+// RelationshipDescribes is the relationship type of the elements described
+// by the document.
+const RelationshipDescribes = "DESCRIBES"
+
+// Reference categories and types of the package external references.
+const (
+	CategoryPackageManager = "PACKAGE-MANAGER"
+	CategorySecurity       = "SECURITY"
+	ReferenceTypePurl      = "purl"
+	ReferenceTypeCPE22     = "cpe22Type"
+	ReferenceTypeCPE23     = "cpe23Type"
+)
+
+// Document is an SPDX document. Only the fields needed to match its
+// packages to VEX data are defined.
+//
+// https://spdx.github.io/spdx-spec/v2.3/document-creation-information/
+type Document struct {
+	SPDXVersion          string                `json:"spdxVersion"`
+	ID                   string                `json:"SPDXID"`
+	Name                 string                `json:"name,omitempty"`
+	Namespace            string                `json:"documentNamespace,omitempty"`
+	ExternalDocumentRefs []ExternalDocumentRef `json:"externalDocumentRefs,omitempty"`
+	DocumentDescribes    []string              `json:"documentDescribes,omitempty"`
+	Packages             []Package             `json:"packages,omitempty"`
+	Relationships        []Relationship        `json:"relationships,omitempty"`
+}
+
+// ExternalDocumentRef declares another SPDX document whose elements are
+// referenced as DocumentRef-name:SPDXRef-element.
+//
+// https://spdx.github.io/spdx-spec/v2.3/document-creation-information/#66-external-document-references-field
+type ExternalDocumentRef struct {
+	ID        string   `json:"externalDocumentId"`
+	Namespace string   `json:"spdxDocument"`
+	Checksum  Checksum `json:"checksum"`
+}
+
+// Package is a piece of software described in the document.
+//
+// https://spdx.github.io/spdx-spec/v2.3/package-information/
+type Package struct {
+	ID           string        `json:"SPDXID"`
+	Name         string        `json:"name,omitempty"`
+	VersionInfo  string        `json:"versionInfo,omitempty"`
+	Checksums    []Checksum    `json:"checksums,omitempty"`
+	ExternalRefs []ExternalRef `json:"externalRefs,omitempty"`
+}
+
+// Checksum is a hash of a package.
+type Checksum struct {
+	Algorithm string `json:"algorithm"`
+	Value     string `json:"checksumValue"`
+}
+
+// ExternalRef points to information about the package in other systems,
+// for example its purl or CPE.
+type ExternalRef struct {
+	Category string `json:"referenceCategory"`
+	Type     string `json:"referenceType"`
+	Locator  string `json:"referenceLocator"`
+}
+
+// Relationship links two elements of the document.
+type Relationship struct {
+	Element        string `json:"spdxElementId"`
+	Type           string `json:"relationshipType"`
+	RelatedElement string `json:"relatedSpdxElement"`
+}
+
+// Open reads and parses a given file path and returns an SPDX document or
+// an error if the file could not be opened or parsed.
+func Open(path string) (*Document, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // This is supposed to open user-specified paths
+	if err != nil {
+		return nil, fmt.Errorf("spdx: failed to open document: %w", err)
+	}
+	return Parse(data)
+}
+
+// Parse decodes an SPDX document from its JSON data.
+func Parse(data []byte) (*Document, error) {
+	doc := &Document{}
+	if err := json.Unmarshal(data, doc); err != nil {
+		return nil, fmt.Errorf("spdx: failed to decode document: %w", err)
+	}
+
+	if !strings.HasPrefix(doc.SPDXVersion, "SPDX-2.") {
+		return nil, fmt.Errorf("spdx: unsupported spdxVersion %q", doc.SPDXVersion)
+	}
+
+	return doc, nil
+}
+
+// DescribedPackages returns the packages the document is about, listed in
+// documentDescribes or related to the document with a DESCRIBES
+// relationship.
+func (doc *Document) DescribedPackages() []Package {
+	ids := map[string]struct{}{}
+	for _, id := range doc.DocumentDescribes {
+		ids[id] = struct{}{}
+	}
+	for _, r := range doc.Relationships {
+		if r.Element == DocumentID && r.Type == RelationshipDescribes {
+			ids[r.RelatedElement] = struct{}{}
+		}
+	}
+
+	ret := []Package{}
+	for i := range doc.Packages {
+		if _, ok := ids[doc.Packages[i].ID]; ok {
+			ret = append(ret, doc.Packages[i])
+		}
+	}
+	return ret
+}
+
+// ElementIRI returns the IRI of an element of the document: the document
+// namespace followed by # and the element ID. Elements of external
+// documents (DocumentRef-name:SPDXRef-element) get the namespace of the
+// referenced document. It returns an empty string if the document has no
+// namespace or the external document is not declared.
+func (doc *Document) ElementIRI(id string) string {
+	namespace := doc.Namespace
+	if ref, element, ok := strings.Cut(id, ":"); ok && strings.HasPrefix(ref, "DocumentRef-") {
+		namespace = ""
+		for _, ext := range doc.ExternalDocumentRefs {
+			if ext.ID == ref {
+				namespace = ext.Namespace
+				break
+			}
+		}
+		id = element
+	}
+	if namespace == "" {
+		return ""
+	}
+	return namespace + "#" + id
+}
+
+// Purl returns the purl of the package, if it has one.
+func (p *Package) Purl() string {
+	return p.externalRef(CategoryPackageManager, ReferenceTypePurl)
+}
+
+// CPE returns the CPE of the package, in the 2.3 format if the package
+// has one.
+func (p *Package) CPE() string {
+	if cpe := p.externalRef(CategorySecurity, ReferenceTypeCPE23); cpe != "" {
+		return cpe
+	}
+	return p.externalRef(CategorySecurity, ReferenceTypeCPE22)
+}
+
+// externalRef returns the locator of the first external reference of the
+// type. The category is compared leniently as older documents use
+// underscores instead of dashes.
+func (p *Package) externalRef(category, refType string) string {
+	for _, ref := range p.ExternalRefs {
+		if ref.Type == refType && strings.ReplaceAll(ref.Category, "_", "-") == category {
+			return ref.Locator
+		}
+	}
+	return ""
+}
@@ -0,0 +1,52 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package spdx
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpen(t *testing.T) {
+	doc, err := Open("testdata/sbom.spdx.json")
+	require.NoError(t, err)
+	require.Equal(t, "SPDX-2.3", doc.SPDXVersion)
+	require.Len(t, doc.Packages, 3)
+
+	described := doc.DescribedPackages()
+	require.Len(t, described, 1)
+	require.Equal(t, "SPDXRef-Package-image", described[0].ID)
+
+	require.Equal(t, "pkg:apk/wolfi/git@2.41.0-r0?arch=x86_64", doc.Packages[1].Purl())
+	require.Empty(t, doc.Packages[1].CPE())
+	require.Equal(t, "cpe:2.3:a:openssl:openssl:3.1.2:*:*:*:*:*:*:*", doc.Packages[2].CPE())
+
+	_, err = Open("testdata/non-existent.json")
+	require.Error(t, err)
+}
+
+func TestParse(t *testing.T) {
+	doc, err := Parse([]byte(`{"spdxVersion":"SPDX-2.2","SPDXID":"SPDXRef-DOCUMENT","documentDescribes":["SPDXRef-a"],"packages":[{"SPDXID":"SPDXRef-a"},{"SPDXID":"SPDXRef-b"}]}`))
+	require.NoError(t, err)
+	require.Len(t, doc.DescribedPackages(), 1)
+
+	_, err = Parse([]byte(`{"bomFormat":"CycloneDX"}`))
+	require.Error(t, err)
+	_, err = Parse([]byte(`not json`))
+	require.Error(t, err)
+}
+
+func TestElementIRI(t *testing.T) {
+	doc := &Document{
+		Namespace: "https://example.com/spdx/image",
+		ExternalDocumentRefs: []ExternalDocumentRef{
+			{ID: "DocumentRef-git", Namespace: "https://example.com/spdx/git"},
+		},
+	}
+	require.Equal(t, "https://example.com/spdx/image#SPDXRef-Package-a", doc.ElementIRI("SPDXRef-Package-a"))
+	require.Equal(t, "https://example.com/spdx/git#SPDXRef-Package-git", doc.ElementIRI("DocumentRef-git:SPDXRef-Package-git"))
+	require.Empty(t, doc.ElementIRI("DocumentRef-curl:SPDXRef-Package-curl"))
+	require.Empty(t, (&Document{}).ElementIRI("SPDXRef-Package-a"))
+}
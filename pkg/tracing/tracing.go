@@ -0,0 +1,83 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+// Package tracing defines the hooks used by the library to report spans
+// around slow operations such as registry calls, remote fetches and large
+// document parses. The interfaces are small enough to be implemented with
+// an adapter over OpenTelemetry or any other tracing library.
+package tracing
+
+import (
+	"context"
+	"sync"
+)
+
+// Attribute is a key/value pair attached to a span.
+type Attribute struct {
+	Key   string
+	Value string
+}
+
+// Attr returns a new span attribute.
+func Attr(key, value string) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Span is an operation being traced.
+type Span interface {
+	// End finishes the span, recording the error returned by the
+	// operation, if any.
+	End(err error)
+}
+
+// Tracer starts spans. The returned context must carry the new span so that
+// spans started from it are nested under it.
+type Tracer interface {
+	Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span)
+}
+
+type tracerKey struct{}
+
+var (
+	mutex         sync.RWMutex
+	defaultTracer Tracer = noopTracer{}
+)
+
+// SetDefault sets the tracer used when the context does not carry one.
+// Passing nil disables tracing.
+func SetDefault(t Tracer) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	if t == nil {
+		t = noopTracer{}
+	}
+	defaultTracer = t
+}
+
+// ContextWithTracer returns a copy of ctx that carries the tracer. Spans
+// started from the returned context use it instead of the default one.
+func ContextWithTracer(ctx context.Context, t Tracer) context.Context {
+	return context.WithValue(ctx, tracerKey{}, t)
+}
+
+// Start starts a span using the tracer in the context or, if it has none,
+// the default tracer.
+func Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span) {
+	t, ok := ctx.Value(tracerKey{}).(Tracer)
+	if !ok || t == nil {
+		mutex.RLock()
+		t = defaultTracer
+		mutex.RUnlock()
+	}
+	return t.Start(ctx, name, attrs...)
+}
+
+type noopTracer struct{}
+
+func (noopTracer) Start(ctx context.Context, _ string, _ ...Attribute) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+type noopSpan struct{}
+
+func (noopSpan) End(error) {}
@@ -0,0 +1,69 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type recordedSpan struct {
+	name   string
+	parent string
+	attrs  []Attribute
+	err    error
+	ended  bool
+}
+
+type spanKey struct{}
+
+type recorder struct {
+	spans []*recordedSpan
+}
+
+func (r *recorder) Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span) {
+	s := &recordedSpan{name: name, attrs: attrs}
+	if parent, ok := ctx.Value(spanKey{}).(*recordedSpan); ok {
+		s.parent = parent.name
+	}
+	r.spans = append(r.spans, s)
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+func (s *recordedSpan) End(err error) {
+	s.ended = true
+	s.err = err
+}
+
+func TestStart(t *testing.T) {
+	// Without a tracer spans are no-ops
+	ctx, span := Start(context.Background(), "noop")
+	require.NotNil(t, ctx)
+	span.End(nil)
+
+	// Default tracer
+	def := &recorder{}
+	SetDefault(def)
+	defer SetDefault(nil)
+	_, span = Start(context.Background(), "default", Attr("key", "value"))
+	span.End(nil)
+	require.Len(t, def.spans, 1)
+	require.Equal(t, []Attribute{{Key: "key", Value: "value"}}, def.spans[0].attrs)
+
+	// The context tracer takes precedence and spans nest
+	r := &recorder{}
+	ctx, parent := Start(ContextWithTracer(context.Background(), r), "parent")
+	_, child := Start(ctx, "child")
+	child.End(errors.New("failed"))
+	parent.End(nil)
+
+	require.Len(t, def.spans, 1)
+	require.Len(t, r.spans, 2)
+	require.Equal(t, "parent", r.spans[1].parent)
+	require.Error(t, r.spans[1].err)
+	require.True(t, r.spans[0].ended)
+}
@@ -0,0 +1,98 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+)
+
+// AliasResolver expands a vulnerability identifier into the identifiers of
+// the same vulnerability in other tracking systems, for example a CVE into
+// its GHSA and distribution advisory IDs. It lets statements match queries
+// for any of the aliases of their vulnerability, even when the statement
+// author did not list them. The enrich package has an implementation that
+// queries OSV.
+type AliasResolver interface {
+	// Aliases returns the other identifiers of the vulnerability. Unknown
+	// vulnerabilities have no aliases and return no error.
+	Aliases(ctx context.Context, id VulnerabilityID) ([]VulnerabilityID, error)
+}
+
+// memoizedAliasResolver caches the aliases returned by a resolver.
+type memoizedAliasResolver struct {
+	resolver AliasResolver
+	mutex    sync.Mutex
+	cache    map[VulnerabilityID][]VulnerabilityID
+}
+
+// MemoizeAliasResolver wraps an AliasResolver and returns a new one that
+// caches the aliases of each vulnerability. Failed lookups are not cached.
+// The returned resolver is safe for concurrent use as long as the wrapped
+// resolver is.
+func MemoizeAliasResolver(resolver AliasResolver) AliasResolver {
+	if resolver == nil {
+		return nil
+	}
+	return &memoizedAliasResolver{resolver: resolver, cache: map[VulnerabilityID][]VulnerabilityID{}}
+}
+
+// Aliases returns the cached aliases of the vulnerability, looking them up
+// with the wrapped resolver on the first call.
+func (m *memoizedAliasResolver) Aliases(ctx context.Context, id VulnerabilityID) ([]VulnerabilityID, error) {
+	m.mutex.Lock()
+	aliases, ok := m.cache[id]
+	m.mutex.Unlock()
+	if ok {
+		return aliases, nil
+	}
+
+	aliases, err := m.resolver.Aliases(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mutex.Lock()
+	m.cache[id] = aliases
+	m.mutex.Unlock()
+	return aliases, nil
+}
+
+// resolveAliases returns the aliases of the vulnerability identifier,
+// looking them up with the context of the options. If the resolver fails,
+// the error is passed to the AliasError handler of the options and the
+// vulnerability is considered to have no aliases.
+func resolveAliases(opts *MatchOptions, identifier string) []VulnerabilityID {
+	if opts.Aliases == nil || identifier == "" {
+		return nil
+	}
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	aliases, err := opts.Aliases.Aliases(ctx, VulnerabilityID(identifier).Local())
+	if err != nil {
+		err = fmt.Errorf("resolving aliases of %s: %w", identifier, err)
+		if opts.AliasError == nil {
+			slog.Warn("unable to resolve vulnerability aliases", "vulnerability", identifier, "error", err)
+			return nil
+		}
+		opts.AliasError(err)
+		return nil
+	}
+	return aliases
+}
+
+// matchesAny returns true if the vulnerability matches any of the
+// identifiers.
+func (v *Vulnerability) matchesAny(ids []VulnerabilityID) bool {
+	for _, id := range ids {
+		if v.Matches(string(id)) {
+			return true
+		}
+	}
+	return false
+}
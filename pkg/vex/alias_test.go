@@ -0,0 +1,136 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testAliases is an AliasResolver backed by a map that counts its lookups.
+type testAliases struct {
+	aliases map[VulnerabilityID][]VulnerabilityID
+	calls   int
+}
+
+func (ta *testAliases) Aliases(ctx context.Context, id VulnerabilityID) ([]VulnerabilityID, error) {
+	ta.calls++
+	if id == "CVE-0000-0000" {
+		return nil, errors.New("lookup failed")
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return ta.aliases[id], nil
+}
+
+func TestMatchAliases(t *testing.T) {
+	resolver := &testAliases{aliases: map[VulnerabilityID][]VulnerabilityID{
+		"CVE-2023-1234":       {"GHSA-abcd-efgh-ijkl", "DSA-5555-1"},
+		"GHSA-abcd-efgh-ijkl": {"CVE-2023-1234"},
+	}}
+	doc := New()
+	doc.Statements = []Statement{
+		{
+			Vulnerability: Vulnerability{Name: "GHSA-abcd-efgh-ijkl"},
+			Products:      []Product{{Component: Component{ID: "pkg:apk/wolfi/git@2.41.0-r1"}}},
+			Status:        StatusNotAffected,
+			Justification: ComponentNotPresent,
+		},
+		{
+			Vulnerability: Vulnerability{Name: "CVE-2023-5678"},
+			Products:      []Product{{Component: Component{ID: "pkg:apk/wolfi/git@2.41.0-r1"}}},
+			Status:        StatusAffected,
+		},
+	}
+
+	for m, tc := range map[string]struct {
+		vuln     string
+		resolver AliasResolver
+		expected int
+	}{
+		"no resolver":       {vuln: "CVE-2023-1234", expected: 0},
+		"direct match":      {vuln: "GHSA-abcd-efgh-ijkl", resolver: resolver, expected: 1},
+		"alias":             {vuln: "CVE-2023-1234", resolver: resolver, expected: 1},
+		"alias iri":         {vuln: "https://nvd.nist.gov/vuln/detail/CVE-2023-1234", resolver: resolver, expected: 1},
+		"unknown":           {vuln: "CVE-2023-9999", resolver: resolver, expected: 0},
+		"resolver failures": {vuln: "CVE-0000-0000", resolver: resolver, expected: 0},
+	} {
+		matches := doc.MatchesWithOptions(tc.vuln, "pkg:apk/wolfi/git@2.41.0-r1", nil, &MatchOptions{Aliases: tc.resolver})
+		require.Len(t, matches, tc.expected, m)
+	}
+
+	// Aliases are looked up once per query
+	resolver.calls = 0
+	doc.MatchesWithOptions("CVE-2023-1234", "pkg:apk/wolfi/git@2.41.0-r1", nil, &MatchOptions{Aliases: resolver})
+	require.Equal(t, 1, resolver.calls)
+
+	// Lookups use the context of the options and report their errors
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var errs []error
+	matches := doc.MatchesWithOptions("CVE-2023-1234", "pkg:apk/wolfi/git@2.41.0-r1", nil, &MatchOptions{
+		Aliases:    resolver,
+		Context:    ctx,
+		AliasError: func(err error) { errs = append(errs, err) },
+	})
+	require.Empty(t, matches)
+	require.NotEmpty(t, errs)
+	require.ErrorIs(t, errs[0], context.Canceled)
+	require.ErrorContains(t, errs[0], "CVE-2023-1234")
+}
+
+func TestMatchAllAliasErrors(t *testing.T) {
+	resolver := &testAliases{aliases: map[VulnerabilityID][]VulnerabilityID{"CVE-2023-1234": {"GHSA-abcd-efgh-ijkl"}}}
+	doc := New()
+	doc.Statements = []Statement{{
+		Vulnerability: Vulnerability{Name: "GHSA-abcd-efgh-ijkl"},
+		Products:      []Product{{Component: Component{ID: "pkg:apk/wolfi/git@2.41.0-r1"}}},
+		Status:        StatusNotAffected,
+		Justification: ComponentNotPresent,
+	}}
+
+	results, err := MatchAll(context.Background(), []*VEX{&doc}, []Query{
+		{Vulnerability: "CVE-2023-1234", Product: "pkg:apk/wolfi/git@2.41.0-r1"},
+	}, &MatchAllOptions{MatchOptions: MatchOptions{Aliases: resolver}})
+	require.NoError(t, err)
+	require.Len(t, results[0].Statements, 1)
+
+	queries := []Query{{Vulnerability: "CVE-0000-0000", Product: "pkg:apk/wolfi/git@2.41.0-r1"}}
+	_, err = MatchAll(context.Background(), []*VEX{&doc}, queries, &MatchAllOptions{MatchOptions: MatchOptions{Aliases: resolver}})
+	require.ErrorContains(t, err, "lookup failed")
+
+	// Handled errors don't fail the evaluation
+	var errs []error
+	results, err = MatchAll(context.Background(), []*VEX{&doc}, queries, &MatchAllOptions{MatchOptions: MatchOptions{
+		Aliases:    resolver,
+		AliasError: func(err error) { errs = append(errs, err) },
+	}})
+	require.NoError(t, err)
+	require.Empty(t, results[0].Statements)
+	require.Len(t, errs, 1)
+}
+
+func TestMemoizeAliasResolver(t *testing.T) {
+	require.Nil(t, MemoizeAliasResolver(nil))
+
+	resolver := &testAliases{aliases: map[VulnerabilityID][]VulnerabilityID{"CVE-2023-1234": {"GHSA-abcd-efgh-ijkl"}}}
+	memoized := MemoizeAliasResolver(resolver)
+	for range 2 {
+		aliases, err := memoized.Aliases(context.Background(), "CVE-2023-1234")
+		require.NoError(t, err)
+		require.Equal(t, []VulnerabilityID{"GHSA-abcd-efgh-ijkl"}, aliases)
+	}
+	require.Equal(t, 1, resolver.calls)
+
+	// Failures are not cached
+	for range 2 {
+		_, err := memoized.Aliases(context.Background(), "CVE-0000-0000")
+		require.Error(t, err)
+	}
+	require.Equal(t, 3, resolver.calls)
+}
@@ -0,0 +1,164 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// AuditSchemaVersion is the version of the audit record schema. It is
+// recorded in every audit record and will only change when the schema
+// changes in a backwards incompatible way.
+const AuditSchemaVersion = "1"
+
+// Decision is the outcome of applying VEX data to a finding.
+type Decision string
+
+const (
+	// DecisionSuppressed means the finding was waived by a not_affected or
+	// fixed statement.
+	DecisionSuppressed Decision = "suppressed"
+
+	// DecisionRetained means a statement applies to the finding but it
+	// does not waive it (affected or under_investigation).
+	DecisionRetained Decision = "retained"
+
+	// DecisionNoStatement means no statement applies to the finding.
+	DecisionNoStatement Decision = "no_statement"
+)
+
+// AuditRecord captures why a decision was made about a finding when
+// applying VEX data to it.
+type AuditRecord struct {
+	SchemaVersion  string        `json:"schema_version"`
+	Vulnerability  string        `json:"vulnerability"`
+	Product        string        `json:"product"`
+	Subcomponent   string        `json:"subcomponent,omitempty"`
+	Decision       Decision      `json:"decision"`
+	Status         Status        `json:"status,omitempty"`
+	Justification  Justification `json:"justification,omitempty"`
+	StatementID    string        `json:"statement_id,omitempty"`
+	DocumentID     string        `json:"document_id,omitempty"`
+	DocumentDigest string        `json:"document_digest,omitempty"`
+	Author         string        `json:"author,omitempty"`
+	Timestamp      time.Time     `json:"timestamp"`
+}
+
+// ApplyToFindings evaluates the findings against the documents and returns
+// an audit record for each one. See ApplyToFindingsWithOptions.
+func ApplyToFindings(findings []Finding, docs []*VEX) ([]AuditRecord, error) {
+	return ApplyToFindingsWithOptions(nil, findings, docs)
+}
+
+// AuditOptions configure the evaluation of findings.
+type AuditOptions struct {
+	// Clock sets the timestamp of the audit records. Defaults to the
+	// current time.
+	Clock Clock
+}
+
+// ApplyToFindingsWithOptions evaluates the findings against the documents
+// and returns an audit record for each one, timestamped with the time of
+// the options clock. The decision of each finding is
+// based on the latest statement that applies to it across all documents.
+func ApplyToFindingsWithOptions(opts *AuditOptions, findings []Finding, docs []*VEX) ([]AuditRecord, error) {
+	if opts == nil {
+		opts = &AuditOptions{}
+	}
+
+	digests := map[*VEX]string{}
+	for _, doc := range docs {
+		digest, err := doc.Digest(SHA256)
+		if err != nil {
+			return nil, fmt.Errorf("computing digest of %s: %w", doc.ID, err)
+		}
+		digests[doc] = digest
+	}
+
+	records := make([]AuditRecord, 0, len(findings))
+	for _, f := range findings {
+		record := AuditRecord{
+			SchemaVersion: AuditSchemaVersion,
+			Vulnerability: f.Vulnerability,
+			Product:       f.Product,
+			Subcomponent:  f.Subcomponent,
+			Decision:      DecisionNoStatement,
+		}
+
+		doc, stmt := latestStatement(f, docs)
+		if stmt != nil {
+			record.Decision = DecisionRetained
+			if stmt.Status == StatusNotAffected || stmt.Status == StatusFixed {
+				record.Decision = DecisionSuppressed
+			}
+			record.Status = stmt.Status
+			record.Justification = stmt.Justification
+			record.StatementID = stmt.ID
+			record.DocumentID = doc.ID
+			record.DocumentDigest = digests[doc]
+			record.Author, _ = doc.StatementAuthor(stmt)
+		}
+
+		record.Timestamp = clockNow(opts.Clock).UTC()
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// latestStatement returns the most recent statement applying to the finding
+// and the document where it was found.
+func latestStatement(f Finding, docs []*VEX) (doc *VEX, stmt *Statement) {
+	subcomponents := []string{}
+	if f.Subcomponent != "" {
+		subcomponents = append(subcomponents, f.Subcomponent)
+	}
+
+	var latest time.Time
+	for _, d := range docs {
+		matches := d.Matches(f.Vulnerability, f.Product, subcomponents)
+		if len(matches) == 0 {
+			continue
+		}
+
+		// Matches are sorted, the last one is the latest in the document
+		s := matches[len(matches)-1]
+		var ts time.Time
+		switch {
+		case s.Timestamp != nil:
+			ts = *s.Timestamp
+		case d.Timestamp != nil:
+			ts = *d.Timestamp
+		}
+
+		if stmt == nil || !ts.Before(latest) {
+			doc, stmt, latest = d, &s, ts
+		}
+	}
+	return doc, stmt
+}
+
+// AuditWriter writes audit records as JSON lines.
+type AuditWriter struct {
+	enc *json.Encoder
+}
+
+// NewAuditWriter returns an AuditWriter that writes to w.
+func NewAuditWriter(w io.Writer) *AuditWriter {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return &AuditWriter{enc: enc}
+}
+
+// Write writes the records, one JSON object per line.
+func (aw *AuditWriter) Write(records ...AuditRecord) error {
+	for i := range records {
+		if err := aw.enc.Encode(&records[i]); err != nil {
+			return fmt.Errorf("writing audit record: %w", err)
+		}
+	}
+	return nil
+}
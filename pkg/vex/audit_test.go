@@ -0,0 +1,98 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyToFindings(t *testing.T) {
+	now := time.Now()
+	before := now.Add(-time.Hour)
+
+	older := New()
+	older.ID = "https://example.com/vex/older"
+	older.Author = "Old Author"
+	older.Timestamp = &before
+	older.Statements = []Statement{
+		{
+			ID:            "https://example.com/vex/older#1",
+			Vulnerability: Vulnerability{Name: "CVE-2023-0001"},
+			Products:      []Product{{Component: Component{ID: "pkg:apk/wolfi/git@2.41.0-r0"}}},
+			Status:        StatusAffected,
+		},
+		{
+			Vulnerability: Vulnerability{Name: "CVE-2023-0002"},
+			Products:      []Product{{Component: Component{ID: "pkg:apk/wolfi/git@2.41.0-r0"}}},
+			Status:        StatusUnderInvestigation,
+		},
+	}
+
+	newer := New()
+	newer.ID = "https://example.com/vex/newer"
+	newer.Author = "New Author"
+	newer.Timestamp = &now
+	newer.Statements = []Statement{
+		{
+			ID:            "https://example.com/vex/newer#1",
+			Vulnerability: Vulnerability{Name: "CVE-2023-0001"},
+			Products:      []Product{{Component: Component{ID: "pkg:apk/wolfi/git@2.41.0-r0"}}},
+			Status:        StatusNotAffected,
+			Justification: VulnerableCodeNotPresent,
+		},
+	}
+
+	records, err := ApplyToFindings([]Finding{
+		{Vulnerability: "CVE-2023-0001", Product: "pkg:apk/wolfi/git@2.41.0-r0"},
+		{Vulnerability: "CVE-2023-0002", Product: "pkg:apk/wolfi/git@2.41.0-r0"},
+		{Vulnerability: "CVE-2023-0003", Product: "pkg:apk/wolfi/git@2.41.0-r0"},
+	}, []*VEX{&newer, &older})
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+
+	digest, err := newer.Digest(SHA256)
+	require.NoError(t, err)
+	require.Equal(t, DecisionSuppressed, records[0].Decision)
+	require.Equal(t, StatusNotAffected, records[0].Status)
+	require.Equal(t, VulnerableCodeNotPresent, records[0].Justification)
+	require.Equal(t, "https://example.com/vex/newer#1", records[0].StatementID)
+	require.Equal(t, "https://example.com/vex/newer", records[0].DocumentID)
+	require.Equal(t, digest, records[0].DocumentDigest)
+	require.Equal(t, "New Author", records[0].Author)
+	require.False(t, records[0].Timestamp.IsZero())
+
+	require.Equal(t, DecisionRetained, records[1].Decision)
+	require.Equal(t, "Old Author", records[1].Author)
+
+	require.Equal(t, DecisionNoStatement, records[2].Decision)
+	require.Empty(t, records[2].DocumentID)
+
+	var b bytes.Buffer
+	require.NoError(t, NewAuditWriter(&b).Write(records...))
+	scanner := bufio.NewScanner(&b)
+	lines := 0
+	for scanner.Scan() {
+		record := map[string]any{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+		require.Equal(t, AuditSchemaVersion, record["schema_version"])
+		lines++
+	}
+	require.Equal(t, 3, lines)
+}
+
+func TestApplyToFindingsWithOptions(t *testing.T) {
+	now := time.Date(2023, 6, 1, 12, 0, 0, 0, time.FixedZone("CEST", 2*60*60))
+	records, err := ApplyToFindingsWithOptions(&AuditOptions{Clock: FixedClock(now)}, []Finding{
+		{Vulnerability: "CVE-2023-0001", Product: "pkg:apk/wolfi/git@2.41.0-r0"},
+	}, nil)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	require.Equal(t, now.UTC(), records[0].Timestamp)
+}
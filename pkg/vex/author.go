@@ -0,0 +1,183 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Author is the structured form of the author of a document or statement.
+// The OpenVEX spec defines the author as a free form string; Author reads
+// and writes it in the form "Name (Organization) <URI>", where the
+// organization and the URI are optional.
+type Author struct {
+	// Name is the name of the person or organization.
+	Name string
+
+	// Organization is the organization the author belongs to.
+	Organization string
+
+	// URI identifies or locates the author, eg a website or an email
+	// address.
+	URI string
+}
+
+// ParseAuthor parses an author string. Strings not following the structured
+// form are returned as the author name.
+func ParseAuthor(s string) Author {
+	a := Author{}
+	s, a.URI = cutDelimited(strings.TrimSpace(s), '<', '>')
+	a.Name, a.Organization = cutDelimited(s, '(', ')')
+	return a
+}
+
+// String returns the author in the form used in the author fields.
+func (a Author) String() string {
+	s := a.Name
+	if a.Organization != "" {
+		s += " (" + a.Organization + ")"
+	}
+	if a.URI != "" {
+		s += " <" + a.URI + ">"
+	}
+	return strings.TrimSpace(s)
+}
+
+// Validate checks that the author has a name and that its URI, if set, is
+// a valid URI.
+func (a *Author) Validate() error {
+	if strings.TrimSpace(a.Name) == "" {
+		return errors.New("author has no name")
+	}
+	if a.URI != "" {
+		if _, err := url.Parse(a.URI); err != nil {
+			return fmt.Errorf("invalid author URI %q: %w", a.URI, err)
+		}
+	}
+	return nil
+}
+
+// Tooling is the structured form of the tool that generated a document. The
+// OpenVEX spec defines tooling as a free form string; Tooling reads and
+// writes it in the form "Name@Version <URI>", where the version and the URI
+// are optional.
+type Tooling struct {
+	// Name is the name of the tool.
+	Name string
+
+	// Version is the version of the tool.
+	Version string
+
+	// URI locates the tool, eg its repository.
+	URI string
+}
+
+// ParseTooling parses a tooling string. Strings not following the structured
+// form are returned as the tool name.
+func ParseTooling(s string) Tooling {
+	t := Tooling{}
+	s, t.URI = cutDelimited(strings.TrimSpace(s), '<', '>')
+	t.Name = s
+	if i := strings.LastIndex(s, "@"); i > 0 && !strings.Contains(s[i:], " ") {
+		t.Name, t.Version = s[:i], s[i+1:]
+	}
+	return t
+}
+
+// String returns the tooling in the form used in the tooling field.
+func (t Tooling) String() string {
+	s := t.Name
+	if t.Version != "" {
+		s += "@" + t.Version
+	}
+	if t.URI != "" {
+		s += " <" + t.URI + ">"
+	}
+	return strings.TrimSpace(s)
+}
+
+// Validate checks that the tooling has a name and that its URI, if set, is
+// a valid URI.
+func (t *Tooling) Validate() error {
+	if strings.TrimSpace(t.Name) == "" {
+		return errors.New("tooling has no name")
+	}
+	if t.URI != "" {
+		if _, err := url.Parse(t.URI); err != nil {
+			return fmt.Errorf("invalid tooling URI %q: %w", t.URI, err)
+		}
+	}
+	return nil
+}
+
+// cutDelimited splits a trailing "open value close" suffix from a string.
+// If the string does not end with a delimited value, it is returned as is.
+func cutDelimited(s string, open, closing byte) (before, value string) {
+	if !strings.HasSuffix(s, string(closing)) {
+		return s, ""
+	}
+	i := strings.LastIndexByte(s, open)
+	if i == -1 {
+		return s, ""
+	}
+	return strings.TrimSpace(s[:i]), strings.TrimSpace(s[i+1 : len(s)-1])
+}
+
+// AuthorDetails returns the structured form of the document author.
+func (m *Metadata) AuthorDetails() Author {
+	return ParseAuthor(m.Author)
+}
+
+// ToolingDetails returns the structured form of the document tooling.
+func (m *Metadata) ToolingDetails() Tooling {
+	return ParseTooling(m.Tooling)
+}
+
+// WithAuthor sets the document author and its role. It returns the document
+// to chain calls.
+func (vexDoc *VEX) WithAuthor(author Author, role string) *VEX {
+	vexDoc.Author = author.String()
+	vexDoc.AuthorRole = role
+	return vexDoc
+}
+
+// WithTooling sets the tool that generated the document. It returns the
+// document to chain calls.
+func (vexDoc *VEX) WithTooling(tooling Tooling) *VEX {
+	vexDoc.Tooling = tooling.String()
+	return vexDoc
+}
+
+// ValidateMetadata checks the author and tooling of the document. The
+// author is required by the spec, documents without one or still carrying
+// the DefaultAuthor placeholder are rejected, as are documents with a role
+// but no author. The author and tooling must be valid in their structured
+// form.
+func (vexDoc *VEX) ValidateMetadata() error {
+	switch strings.TrimSpace(vexDoc.Author) {
+	case "":
+		if vexDoc.AuthorRole != "" {
+			return errors.New("document author role is set but the document has no author")
+		}
+		return errors.New("document has no author")
+	case DefaultAuthor:
+		return errors.New("document author is not set, it has the default placeholder value")
+	}
+
+	author := vexDoc.AuthorDetails()
+	if err := author.Validate(); err != nil {
+		return fmt.Errorf("invalid document author: %w", err)
+	}
+
+	if vexDoc.Tooling != "" {
+		tooling := vexDoc.ToolingDetails()
+		if err := tooling.Validate(); err != nil {
+			return fmt.Errorf("invalid document tooling: %w", err)
+		}
+	}
+	return nil
+}
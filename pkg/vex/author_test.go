@@ -0,0 +1,94 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAuthor(t *testing.T) {
+	for m, tc := range map[string]struct {
+		author   string
+		expected Author
+	}{
+		"name":               {"Wolfi J Inkinson", Author{Name: "Wolfi J Inkinson"}},
+		"name and uri":       {"Jane Doe <jane@example.com>", Author{Name: "Jane Doe", URI: "jane@example.com"}},
+		"organization":       {"Jane Doe (Example Inc)", Author{Name: "Jane Doe", Organization: "Example Inc"}},
+		"all":                {" Jane Doe (Example Inc) <https://example.com/jane> ", Author{Name: "Jane Doe", Organization: "Example Inc", URI: "https://example.com/jane"}},
+		"unbalanced":         {"Jane Doe >", Author{Name: "Jane Doe >"}},
+		"parens in the name": {"Jane (Doe) Smith", Author{Name: "Jane (Doe) Smith"}},
+		"empty":              {"", Author{}},
+	} {
+		t.Run(m, func(t *testing.T) {
+			a := ParseAuthor(tc.author)
+			require.Equal(t, tc.expected, a)
+			require.Equal(t, a, ParseAuthor(a.String()))
+		})
+	}
+}
+
+func TestParseTooling(t *testing.T) {
+	for m, tc := range map[string]struct {
+		tooling  string
+		expected Tooling
+	}{
+		"name":        {"vexctl", Tooling{Name: "vexctl"}},
+		"version":     {"vexctl@v0.2.5", Tooling{Name: "vexctl", Version: "v0.2.5"}},
+		"module path": {"github.com/openvex/vexctl@v0.2.5 <https://github.com/openvex/vexctl>", Tooling{Name: "github.com/openvex/vexctl", Version: "v0.2.5", URI: "https://github.com/openvex/vexctl"}},
+		"scoped name": {"@openvex/cli@1.0.0", Tooling{Name: "@openvex/cli", Version: "1.0.0"}},
+		"free form":   {"Manually written @ Example Inc", Tooling{Name: "Manually written @ Example Inc"}},
+		"empty":       {"", Tooling{}},
+	} {
+		t.Run(m, func(t *testing.T) {
+			tl := ParseTooling(tc.tooling)
+			require.Equal(t, tc.expected, tl)
+			require.Equal(t, tl, ParseTooling(tl.String()))
+		})
+	}
+}
+
+func TestValidateMetadata(t *testing.T) {
+	for m, tc := range map[string]struct {
+		author    string
+		role      string
+		tooling   string
+		shouldErr bool
+	}{
+		"valid":                {"Jane Doe <https://example.com>", "Maintainer", "vexctl@v0.2.5", false},
+		"no tooling":           {"Jane Doe", "", "", false},
+		"missing author":       {"", "", "", true},
+		"role without author":  {"", "Maintainer", "", true},
+		"default author":       {DefaultAuthor, "", "", true},
+		"author without name":  {"<https://example.com>", "", "", true},
+		"invalid uri":          {"Jane Doe <%zz>", "", "", true},
+		"tooling without name": {"Jane Doe", "", "<https://example.com>", true},
+	} {
+		t.Run(m, func(t *testing.T) {
+			doc := New()
+			doc.Author, doc.AuthorRole, doc.Tooling = tc.author, tc.role, tc.tooling
+			err := doc.ValidateMetadata()
+			if tc.shouldErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestWithAuthor(t *testing.T) {
+	doc := New()
+	require.Error(t, doc.ValidateMetadata())
+
+	doc.WithAuthor(Author{Name: "Jane Doe", Organization: "Example Inc", URI: "https://example.com"}, "Supplier").
+		WithTooling(Tooling{Name: "vexctl", Version: "v0.2.5"})
+	require.Equal(t, "Jane Doe (Example Inc) <https://example.com>", doc.Author)
+	require.Equal(t, "Supplier", doc.AuthorRole)
+	require.Equal(t, "vexctl@v0.2.5", doc.Tooling)
+	require.Equal(t, "Example Inc", doc.AuthorDetails().Organization)
+	require.Equal(t, "v0.2.5", doc.ToolingDetails().Version)
+	require.NoError(t, doc.ValidateMetadata())
+}
@@ -0,0 +1,255 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// StatementFieldError reports a missing or invalid field of a statement
+// being built.
+type StatementFieldError struct {
+	Field  string // Name of the field in the JSON serialization
+	Reason string // Description of the problem
+}
+
+// Error returns the error message.
+func (e *StatementFieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Reason)
+}
+
+// StatementBuildError is returned when a statement fails to build. It
+// collects the errors of all the problematic fields.
+type StatementBuildError struct {
+	Errors []*StatementFieldError
+}
+
+// Error returns the messages of all the field errors.
+func (e *StatementBuildError) Error() string {
+	msgs := []string{}
+	for _, err := range e.Errors {
+		msgs = append(msgs, err.Error())
+	}
+	return "invalid statement: " + strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the field errors.
+func (e *StatementBuildError) Unwrap() []error {
+	ret := []error{}
+	for _, err := range e.Errors {
+		ret = append(ret, err)
+	}
+	return ret
+}
+
+// StatementBuilder builds statements field by field, checking that the
+// statement is complete and valid when it is built:
+//
+//	stmt, err := vex.NewStatement().
+//		WithVulnerability("CVE-2023-1234").
+//		WithProduct("pkg:oci/app@sha256%3A...", "pkg:golang/example.com/lib@v1.0.0").
+//		WithStatus(vex.StatusNotAffected).
+//		WithJustification(vex.VulnerableCodeNotInExecutePath).
+//		Build()
+type StatementBuilder struct {
+	stmt Statement
+	errs []*StatementFieldError
+}
+
+// NewStatement returns a new statement builder.
+func NewStatement() *StatementBuilder {
+	return &StatementBuilder{}
+}
+
+// fail records a field error.
+func (b *StatementBuilder) fail(field, format string, args ...any) {
+	b.errs = append(b.errs, &StatementFieldError{Field: field, Reason: fmt.Sprintf(format, args...)})
+}
+
+// WithVulnerability sets the name and aliases of the statement vulnerability.
+func (b *StatementBuilder) WithVulnerability(name string, aliases ...string) *StatementBuilder {
+	b.stmt.Vulnerability.Name = VulnerabilityID(name)
+	b.stmt.Vulnerability.Aliases = nil
+	for _, a := range aliases {
+		b.stmt.Vulnerability.Aliases = append(b.stmt.Vulnerability.Aliases, VulnerabilityID(a))
+	}
+	return b
+}
+
+// WithVulnerabilityDescription sets the description of the vulnerability.
+func (b *StatementBuilder) WithVulnerabilityDescription(description string) *StatementBuilder {
+	b.stmt.Vulnerability.Description = description
+	return b
+}
+
+// WithProduct adds a product to the statement identified by its IRI or purl,
+// optionally listing the subcomponents the statement applies to.
+func (b *StatementBuilder) WithProduct(id string, subcomponents ...string) *StatementBuilder {
+	if id == "" {
+		b.fail("products", "product identifier is empty")
+		return b
+	}
+	p := Product{Component: Component{ID: id}}
+	for _, s := range subcomponents {
+		if s == "" {
+			b.fail("subcomponents", "subcomponent identifier of %s is empty", id)
+			continue
+		}
+		p.Subcomponents = append(p.Subcomponents, Subcomponent{Component: Component{ID: s}})
+	}
+	b.stmt.Products = append(b.stmt.Products, p)
+	return b
+}
+
+// WithProducts adds fully specified products to the statement.
+func (b *StatementBuilder) WithProducts(products ...Product) *StatementBuilder {
+	b.stmt.Products = append(b.stmt.Products, products...)
+	return b
+}
+
+// WithStatus sets the statement status.
+func (b *StatementBuilder) WithStatus(status Status) *StatementBuilder {
+	b.stmt.Status = status
+	return b
+}
+
+// WithStatusNotes sets the notes explaining the status.
+func (b *StatementBuilder) WithStatusNotes(notes string) *StatementBuilder {
+	b.stmt.StatusNotes = notes
+	return b
+}
+
+// WithJustification sets the justification of a not_affected statement.
+func (b *StatementBuilder) WithJustification(justification Justification) *StatementBuilder {
+	b.stmt.Justification = justification
+	return b
+}
+
+// WithImpactStatement sets the impact statement of a not_affected statement.
+func (b *StatementBuilder) WithImpactStatement(impact string) *StatementBuilder {
+	b.stmt.ImpactStatement = impact
+	return b
+}
+
+// WithActionStatement sets the action statement of an affected statement.
+func (b *StatementBuilder) WithActionStatement(action string) *StatementBuilder {
+	b.stmt.ActionStatement = action
+	return b
+}
+
+// WithRemediation adds a structured remediation to an affected statement.
+func (b *StatementBuilder) WithRemediation(r Remediation) *StatementBuilder {
+	b.stmt.Remediations = append(b.stmt.Remediations, r)
+	return b
+}
+
+// WithTimestamp sets the statement timestamp. Statements built without a
+// timestamp inherit the timestamp of their document.
+func (b *StatementBuilder) WithTimestamp(ts time.Time) *StatementBuilder {
+	b.stmt.Timestamp = &ts
+	return b
+}
+
+// WithValidUntil sets the time when the statement stops applying, eg for a
+// temporary mitigation. See Statement.SetValidUntil.
+func (b *StatementBuilder) WithValidUntil(t time.Time) *StatementBuilder {
+	b.stmt.SetValidUntil(t)
+	return b
+}
+
+// WithAuthor sets the author of the statement and its role.
+func (b *StatementBuilder) WithAuthor(author, role string) *StatementBuilder {
+	b.stmt.Author = author
+	b.stmt.AuthorRole = role
+	return b
+}
+
+// Build checks the statement and returns it. The vulnerability, products and
+// status are required. A not_affected statement requires a justification
+// or an impact statement and an affected statement an action statement.
+// Fields that do not apply to the status must not be set. All problems are
+// returned in a *StatementBuildError.
+func (b *StatementBuilder) Build() (*Statement, error) {
+	if errs := b.check(); len(errs) > 0 {
+		return nil, &StatementBuildError{Errors: errs}
+	}
+
+	stmt := &Statement{}
+	b.stmt.DeepCopyInto(stmt)
+	return stmt, nil
+}
+
+// check returns the errors recorded while building the statement followed
+// by the errors of the statement fields.
+func (b *StatementBuilder) check() []*StatementFieldError {
+	errs := slices.Clone(b.errs)
+	fail := func(field, format string, args ...any) {
+		errs = append(errs, &StatementFieldError{Field: field, Reason: fmt.Sprintf(format, args...)})
+	}
+	stmt := &b.stmt
+
+	if stmt.Vulnerability.Name == "" && stmt.Vulnerability.ID == "" {
+		fail("vulnerability", "vulnerability is not set")
+	}
+	if len(stmt.Products) == 0 {
+		fail("products", "statement has no products")
+	}
+	for i := range stmt.Products {
+		if err := stmt.Products[i].Validate(); err != nil {
+			fail("products", "%s", err)
+		}
+	}
+	if stmt.AuthorRole != "" && stmt.Author == "" {
+		fail("openvex.dev/role", "role is set but the statement has no author")
+	}
+	if err := stmt.validateValidity(); err != nil {
+		fail("valid_until", "%s", err)
+	}
+
+	switch stmt.Status {
+	case "":
+		fail("status", "status is not set")
+		return errs
+	case StatusNotAffected:
+		if stmt.Justification == "" && stmt.ImpactStatement == "" {
+			fail("justification", "justification or impact statement required when using status %q", stmt.Status)
+		}
+		if stmt.Justification != "" && !stmt.Justification.Valid() {
+			fail("justification", "invalid justification %q, must be one of [%s]", stmt.Justification, strings.Join(Justifications(), ", "))
+		}
+	case StatusAffected:
+		if stmt.ActionStatement == "" {
+			fail("action_statement", "action statement required when using status %q", stmt.Status)
+		}
+	case StatusFixed, StatusUnderInvestigation:
+	default:
+		fail("status", "invalid status %q, must be one of [%s]", stmt.Status, strings.Join(Statuses(), ", "))
+		return errs
+	}
+
+	// Fields that do not apply to the status
+	if stmt.Status != StatusNotAffected {
+		if stmt.Justification != "" {
+			fail("justification", "justification should not be set when using status %q", stmt.Status)
+		}
+		if stmt.ImpactStatement != "" {
+			fail("impact_statement", "impact statement should not be set when using status %q", stmt.Status)
+		}
+	}
+	if stmt.Status != StatusAffected && stmt.ActionStatement != "" {
+		fail("action_statement", "action statement should not be set when using status %q", stmt.Status)
+	}
+	if stmt.Status != StatusAffected && len(stmt.Remediations) > 0 {
+		fail("openvex.dev/remediations", "remediations should not be set when using status %q", stmt.Status)
+	}
+	for i := range stmt.Remediations {
+		if err := stmt.Remediations[i].Validate(); err != nil {
+			fail("openvex.dev/remediations", "%s", err)
+		}
+	}
+	return errs
+}
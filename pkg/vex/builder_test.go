@@ -0,0 +1,122 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatementBuilder(t *testing.T) {
+	ts := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+	for m, tc := range map[string]struct {
+		builder *StatementBuilder
+		fields  []string
+	}{
+		"not affected": {
+			builder: NewStatement().
+				WithVulnerability("CVE-2023-1234", "GHSA-aaaa-bbbb-cccc").
+				WithProduct("pkg:oci/app", "pkg:golang/example.com/lib@v1.0.0").
+				WithStatus(StatusNotAffected).
+				WithJustification(VulnerableCodeNotInExecutePath).
+				WithTimestamp(ts),
+		},
+		"affected": {
+			builder: NewStatement().
+				WithVulnerability("CVE-2023-1234").
+				WithProduct("pkg:oci/app").
+				WithStatus(StatusAffected).
+				WithActionStatement("Update to 1.1").
+				WithRemediation(Remediation{Category: RemediationVendorFix, Details: "Update to 1.1", URL: "https://example.com/releases/1.1"}),
+		},
+		"empty": {
+			builder: NewStatement(),
+			fields:  []string{"vulnerability", "products", "status"},
+		},
+		"not affected without justification": {
+			builder: NewStatement().WithVulnerability("CVE-2023-1234").WithProduct("pkg:oci/app").WithStatus(StatusNotAffected),
+			fields:  []string{"justification"},
+		},
+		"invalid justification": {
+			builder: NewStatement().WithVulnerability("CVE-2023-1234").WithProduct("pkg:oci/app").
+				WithStatus(StatusNotAffected).WithJustification("because"),
+			fields: []string{"justification"},
+		},
+		"affected without action": {
+			builder: NewStatement().WithVulnerability("CVE-2023-1234").WithProduct("pkg:oci/app").WithStatus(StatusAffected),
+			fields:  []string{"action_statement"},
+		},
+		"fields not applying to status": {
+			builder: NewStatement().WithVulnerability("CVE-2023-1234").WithProduct("pkg:oci/app").
+				WithStatus(StatusFixed).WithImpactStatement("not used").WithActionStatement("none"),
+			fields: []string{"impact_statement", "action_statement"},
+		},
+		"invalid remediations": {
+			builder: NewStatement().WithVulnerability("CVE-2023-1234").WithProduct("pkg:oci/app").
+				WithStatus(StatusFixed).WithRemediation(Remediation{Category: "patch"}),
+			fields: []string{"openvex.dev/remediations", "openvex.dev/remediations"},
+		},
+		"invalid status": {
+			builder: NewStatement().WithVulnerability("CVE-2023-1234").WithProduct("pkg:oci/app").WithStatus("maybe"),
+			fields:  []string{"status"},
+		},
+		"empty identifiers": {
+			builder: NewStatement().WithVulnerability("CVE-2023-1234").WithProduct("").WithProduct("pkg:oci/app", "").
+				WithStatus(StatusUnderInvestigation),
+			fields: []string{"products", "subcomponents"},
+		},
+		"role without author": {
+			builder: NewStatement().WithVulnerability("CVE-2023-1234").WithProduct("pkg:oci/app").
+				WithStatus(StatusUnderInvestigation).WithAuthor("", "Supplier"),
+			fields: []string{"openvex.dev/role"},
+		},
+		"temporary mitigation": {
+			builder: NewStatement().WithVulnerability("CVE-2023-1234").WithProduct("pkg:oci/app").
+				WithStatus(StatusNotAffected).WithJustification(InlineMitigationsAlreadyExist).
+				WithTimestamp(ts).WithValidUntil(ts.AddDate(0, 1, 0)),
+		},
+		"validity before timestamp": {
+			builder: NewStatement().WithVulnerability("CVE-2023-1234").WithProduct("pkg:oci/app").
+				WithStatus(StatusUnderInvestigation).WithTimestamp(ts).WithValidUntil(ts.AddDate(0, -1, 0)),
+			fields: []string{"valid_until"},
+		},
+	} {
+		stmt, err := tc.builder.Build()
+		if len(tc.fields) == 0 {
+			require.NoError(t, err, m)
+			require.NoError(t, stmt.Validate(), m)
+			continue
+		}
+
+		require.Error(t, err, m)
+		var buildErr *StatementBuildError
+		require.ErrorAs(t, err, &buildErr, m)
+		fields := []string{}
+		for _, fe := range buildErr.Errors {
+			fields = append(fields, fe.Field)
+		}
+		require.Equal(t, tc.fields, fields, m)
+
+		var fieldErr *StatementFieldError
+		require.ErrorAs(t, err, &fieldErr, m)
+		require.Equal(t, tc.fields[0], fieldErr.Field, m)
+
+		// Building again returns the same errors
+		_, err2 := tc.builder.Build()
+		require.Equal(t, err.Error(), err2.Error(), m)
+	}
+}
+
+func TestStatementBuilderCopies(t *testing.T) {
+	b := NewStatement().WithVulnerability("CVE-2023-1234").WithProduct("pkg:oci/app").WithStatus(StatusFixed)
+	stmt1, err := b.Build()
+	require.NoError(t, err)
+
+	stmt2, err := b.WithProduct("pkg:oci/other").Build()
+	require.NoError(t, err)
+	require.Len(t, stmt1.Products, 1)
+	require.Len(t, stmt2.Products, 2)
+}
@@ -0,0 +1,70 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// LinkPrevious records the digest of the previous version of the document
+// in the PreviousDigest field, chaining both documents. The previous
+// document must have a lower version number.
+func (vexDoc *VEX) LinkPrevious(previous *VEX, algo Algorithm) error {
+	if previous == nil {
+		return errors.New("previous document is nil")
+	}
+
+	if previous.Version >= vexDoc.Version {
+		return fmt.Errorf(
+			"previous document version (%d) must be lower than the document version (%d)",
+			previous.Version, vexDoc.Version,
+		)
+	}
+
+	digest, err := previous.Digest(algo)
+	if err != nil {
+		return fmt.Errorf("computing previous document digest: %w", err)
+	}
+
+	vexDoc.PreviousDigest = digest
+	return nil
+}
+
+// VerifyChain walks a list of document versions, sorted from oldest to
+// newest, and checks that each document links to the digest of its
+// predecessor and that versions increase along the chain. The first
+// document is the root of the chain and its link is not checked.
+func VerifyChain(docs []*VEX) error {
+	for i := 1; i < len(docs); i++ {
+		prev, doc := docs[i-1], docs[i]
+
+		if doc.Version <= prev.Version {
+			return fmt.Errorf(
+				"document #%d version (%d) does not follow previous version (%d)", i, doc.Version, prev.Version,
+			)
+		}
+
+		if doc.PreviousDigest == "" {
+			return fmt.Errorf("document #%d (version %d) is not linked to its predecessor", i, doc.Version)
+		}
+
+		algo, _, ok := strings.Cut(doc.PreviousDigest, ":")
+		if !ok {
+			return fmt.Errorf("document #%d has an invalid previous digest %q", i, doc.PreviousDigest)
+		}
+
+		if err := prev.VerifyDigest(doc.PreviousDigest); err != nil {
+			if errors.Is(err, ErrDigestMismatch) {
+				return fmt.Errorf(
+					"document #%d (version %d) does not match the %s digest in its successor: %w",
+					i-1, prev.Version, algo, err,
+				)
+			}
+			return fmt.Errorf("verifying document #%d: %w", i-1, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyChain(t *testing.T) {
+	ts := time.Date(2023, 4, 17, 20, 34, 58, 0, time.UTC)
+	genDoc := func(version int, status Status) *VEX {
+		return &VEX{
+			Metadata: Metadata{
+				ID:        "https://example.com/vex-1",
+				Author:    "Wolfi J Inkinson",
+				Timestamp: &ts,
+				Version:   version,
+			},
+			Statements: []Statement{
+				{
+					Vulnerability: Vulnerability{Name: "CVE-2014-123456"},
+					Products:      []Product{{Component: Component{ID: "pkg:deb/pkg@1.0"}}},
+					Status:        status,
+				},
+			},
+		}
+	}
+
+	v1 := genDoc(1, StatusUnderInvestigation)
+	v2 := genDoc(2, StatusAffected)
+	v3 := genDoc(3, StatusFixed)
+
+	require.NoError(t, v2.LinkPrevious(v1, SHA256))
+	require.NoError(t, v3.LinkPrevious(v2, SHA512))
+	require.Error(t, v1.LinkPrevious(v3, SHA256))
+	require.Error(t, v1.LinkPrevious(nil, SHA256))
+
+	require.NoError(t, VerifyChain([]*VEX{v1, v2, v3}))
+	require.NoError(t, VerifyChain([]*VEX{v1}))
+
+	// Tampering with an old version breaks the chain
+	v1.Statements[0].Status = StatusNotAffected
+	require.ErrorIs(t, VerifyChain([]*VEX{v1, v2, v3}), ErrDigestMismatch)
+	v1.Statements[0].Status = StatusUnderInvestigation
+
+	// Missing versions break the chain
+	require.Error(t, VerifyChain([]*VEX{v1, v3}))
+
+	// Out of order
+	require.Error(t, VerifyChain([]*VEX{v2, v1}))
+
+	// Unlinked documents
+	v3.PreviousDigest = ""
+	require.Error(t, VerifyChain([]*VEX{v1, v2, v3}))
+}
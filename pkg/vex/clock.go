@@ -0,0 +1,54 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"log/slog"
+	"time"
+)
+
+// Clock is the source of the time used to timestamp new documents and
+// events. Functions that create them take a Clock in their options so that
+// tests and reproducible builds can produce the same output on every run.
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc adapts a function to the Clock interface.
+type ClockFunc func() time.Time
+
+// Now returns the time returned by the function.
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// FixedClock returns a Clock that always returns t.
+func FixedClock(t time.Time) Clock {
+	return ClockFunc(func() time.Time { return t })
+}
+
+// clockNow returns the time of the clock or, when it is nil, the current
+// time.
+func clockNow(c Clock) time.Time {
+	if c == nil {
+		return time.Now()
+	}
+	return c.Now()
+}
+
+// documentTime returns the time of the clock or, when it is nil, the time
+// set in SOURCE_DATE_EPOCH or the current time if it is not set.
+func documentTime(c Clock) time.Time {
+	if c != nil {
+		return c.Now()
+	}
+	t, err := DateFromEnv()
+	if err != nil {
+		slog.Warn(err.Error())
+	}
+	if t != nil {
+		return *t
+	}
+	return time.Now()
+}
@@ -0,0 +1,58 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewWithOptions(t *testing.T) {
+	fixed := time.Date(2023, 1, 8, 18, 2, 3, 0, time.UTC)
+	t.Setenv("SOURCE_DATE_EPOCH", "1000")
+
+	// The clock takes precedence over the environment
+	doc := NewWithOptions(&DocumentOptions{Clock: FixedClock(fixed)})
+	require.Equal(t, fixed, *doc.Timestamp)
+
+	doc = NewWithOptions(nil)
+	require.Equal(t, time.Unix(1000, 0), *doc.Timestamp)
+
+	calls := 0
+	doc = NewWithOptions(&DocumentOptions{Clock: ClockFunc(func() time.Time { calls++; return fixed })})
+	require.Equal(t, fixed, *doc.Timestamp)
+	require.Equal(t, 1, calls)
+}
+
+func TestClockReproducibleOutput(t *testing.T) {
+	doc, err := OpenJSON("testdata/v020-1.vex.json")
+	require.NoError(t, err)
+	clock := FixedClock(time.Date(2023, 1, 8, 18, 2, 3, 0, time.UTC))
+
+	for m, generate := range map[string]func() (*VEX, error){
+		"merge": func() (*VEX, error) {
+			return MergeDocumentsWithOptions(&MergeOptions{Clock: clock}, []*VEX{doc})
+		},
+		"triage": func() (*VEX, error) {
+			return GenerateTriageDocumentWithOptions(&TriageOptions{Clock: clock}, []Finding{
+				{Vulnerability: "CVE-2023-9999", Product: "pkg:apk/wolfi/bash@1.0.0"},
+			}, []*VEX{doc})
+		},
+	} {
+		outputs := []string{}
+		for range 2 {
+			newDoc, err := generate()
+			require.NoError(t, err, m)
+			require.Equal(t, clock.Now(), *newDoc.Timestamp, m)
+			var b bytes.Buffer
+			require.NoError(t, newDoc.ToJSON(&b), m)
+			outputs = append(outputs, b.String())
+			time.Sleep(time.Millisecond)
+		}
+		require.Equal(t, outputs[0], outputs[1], m)
+	}
+}
@@ -21,35 +21,55 @@ func getLegacyVersionParser(version string) legacyParser {
 	switch version {
 	case "v0.0.1":
 		return parse001
+	case "v0.1.0":
+		return parse010
 	default:
 		return nil
 	}
 }
 
 var parse001 = func(data []byte) (*VEX, error) {
+	return parseLegacy("v0.0.1", data)
+}
+
+var parse010 = func(data []byte) (*VEX, error) {
+	return parseLegacy("v0.1.0", data)
+}
+
+// parseLegacy reads documents of the versions before v0.2.0. The legacy
+// types accept both the old and new forms of the fields that changed
+// between versions, as documents in the wild mix them:
+//
+//   - The vulnerability can be a string with its name or an object.
+//   - Products and subcomponents can be strings with their IDs or objects.
+//   - Subcomponents can be listed in the statement, applying to all of its
+//     products, or in each product.
+//   - The document version can be a string or a number.
+func parseLegacy(version string, data []byte) (*VEX, error) {
 	oldVex := &vex001{}
 
 	if err := json.Unmarshal(data, oldVex); err != nil {
 		return nil, fmt.Errorf(
-			"decoding OpenVEX v0.0.1 in compatibility mode: %w", err,
+			"decoding OpenVEX %s in compatibility mode: %w", version, err,
 		)
 	}
 
 	newVex := New()
 
 	newVex.Timestamp = oldVex.Timestamp
+	newVex.LastUpdated = oldVex.LastUpdated
 	newVex.Author = oldVex.Author
 	newVex.AuthorRole = oldVex.AuthorRole
 	newVex.ID = oldVex.ID
 	newVex.Tooling = oldVex.Tooling
-	ver, err := strconv.Atoi(oldVex.Version)
-	if err == nil {
-		newVex.Version = ver
-	}
+	newVex.Supplier = oldVex.Supplier
+	newVex.Version = int(oldVex.Version)
 
 	// Transcode the statements
-	for _, oldStmt := range oldVex.Statements {
+	for i := range oldVex.Statements {
+		oldStmt := &oldVex.Statements[i]
 		newStmt := Statement{}
+		newStmt.ID = oldStmt.ID
 		newStmt.Status = Status(oldStmt.Status)
 		newStmt.StatusNotes = oldStmt.StatusNotes
 		newStmt.ActionStatement = oldStmt.ActionStatement
@@ -57,31 +77,27 @@ var parse001 = func(data []byte) (*VEX, error) {
 		newStmt.Justification = Justification(oldStmt.Justification)
 		newStmt.ImpactStatement = oldStmt.ImpactStatement
 		newStmt.Timestamp = oldStmt.Timestamp
+		newStmt.LastUpdated = oldStmt.LastUpdated
 
 		// Add the vulnerability
-		newStmt.Vulnerability = Vulnerability{
-			Name:        VulnerabilityID(oldStmt.Vulnerability),
-			Description: oldStmt.VulnDescription,
+		newStmt.Vulnerability = Vulnerability(oldStmt.Vulnerability)
+		if newStmt.Vulnerability.Description == "" {
+			newStmt.Vulnerability.Description = oldStmt.VulnDescription
 		}
 
-		// Transcode the products from the old statement
-		for _, productID := range oldStmt.Products {
-			newProduct := Product{
-				Component: Component{
-					ID: productID,
-				},
-				Subcomponents: []Subcomponent{},
+		// Transcode the products from the old statement, the statement
+		// subcomponents apply to all of them
+		for _, oldProduct := range oldStmt.Products {
+			newProduct := Product(oldProduct)
+			if newProduct.Subcomponents == nil {
+				newProduct.Subcomponents = []Subcomponent{}
 			}
 
 			for _, sc := range oldStmt.Subcomponents {
-				if sc == "" {
+				if sc.ID == "" && len(sc.Hashes) == 0 && len(sc.Identifiers) == 0 {
 					continue
 				}
-				newProduct.Subcomponents = append(newProduct.Subcomponents, Subcomponent{
-					Component: Component{
-						ID: sc,
-					},
-				})
+				newProduct.Subcomponents = append(newProduct.Subcomponents, Subcomponent(sc))
 			}
 			newStmt.Products = append(newStmt.Products, newProduct)
 		}
@@ -92,27 +108,82 @@ var parse001 = func(data []byte) (*VEX, error) {
 }
 
 type vex001 struct {
-	Context    string         `json:"@context"`
-	ID         string         `json:"@id"`
-	Author     string         `json:"author"`
-	AuthorRole string         `json:"role"`
-	Timestamp  *time.Time     `json:"timestamp"`
-	Version    string         `json:"version"`
-	Tooling    string         `json:"tooling,omitempty"`
-	Supplier   string         `json:"supplier,omitempty"`
-	Statements []statement001 `json:"statements"`
+	Context     string         `json:"@context"`
+	ID          string         `json:"@id"`
+	Author      string         `json:"author"`
+	AuthorRole  string         `json:"role"`
+	Timestamp   *time.Time     `json:"timestamp"`
+	LastUpdated *time.Time     `json:"last_updated,omitempty"`
+	Version     legacyVersion  `json:"version"`
+	Tooling     string         `json:"tooling,omitempty"`
+	Supplier    string         `json:"supplier,omitempty"`
+	Statements  []statement001 `json:"statements"`
 }
 
 type statement001 struct {
-	Vulnerability            string     `json:"vulnerability,omitempty"`
-	VulnDescription          string     `json:"vuln_description,omitempty"`
-	Timestamp                *time.Time `json:"timestamp,omitempty"`
-	Products                 []string   `json:"products,omitempty"`
-	Subcomponents            []string   `json:"subcomponents,omitempty"`
-	Status                   string     `json:"status"`
-	StatusNotes              string     `json:"status_notes,omitempty"`
-	Justification            string     `json:"justification,omitempty"`
-	ImpactStatement          string     `json:"impact_statement,omitempty"`
-	ActionStatement          string     `json:"action_statement,omitempty"`
-	ActionStatementTimestamp *time.Time `json:"action_statement_timestamp,omitempty"`
+	ID                       string               `json:"@id,omitempty"`
+	Vulnerability            legacyVulnerability  `json:"vulnerability,omitempty"`
+	VulnDescription          string               `json:"vuln_description,omitempty"`
+	Timestamp                *time.Time           `json:"timestamp,omitempty"`
+	LastUpdated              *time.Time           `json:"last_updated,omitempty"`
+	Products                 []legacyProduct      `json:"products,omitempty"`
+	Subcomponents            []legacySubcomponent `json:"subcomponents,omitempty"`
+	Status                   string               `json:"status"`
+	StatusNotes              string               `json:"status_notes,omitempty"`
+	Justification            string               `json:"justification,omitempty"`
+	ImpactStatement          string               `json:"impact_statement,omitempty"`
+	ActionStatement          string               `json:"action_statement,omitempty"`
+	ActionStatementTimestamp *time.Time           `json:"action_statement_timestamp,omitempty"`
+	Supplier                 string               `json:"supplier,omitempty"`
+}
+
+// legacyVersion is a document version encoded as a number or a string.
+// Strings that are not numbers decode as version 0.
+type legacyVersion int
+
+func (v *legacyVersion) UnmarshalJSON(data []byte) error {
+	if unquoted, err := strconv.Unquote(string(data)); err == nil {
+		data = []byte(unquoted)
+	}
+	if ver, err := strconv.Atoi(string(data)); err == nil {
+		*v = legacyVersion(ver)
+	}
+	return nil
+}
+
+// legacyVulnerability is a vulnerability encoded as its name or as an
+// object.
+type legacyVulnerability Vulnerability
+
+func (v *legacyVulnerability) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*v = legacyVulnerability{Name: VulnerabilityID(name)}
+		return nil
+	}
+	return json.Unmarshal(data, (*Vulnerability)(v))
+}
+
+// legacyProduct is a product encoded as its ID or as an object.
+type legacyProduct Product
+
+func (p *legacyProduct) UnmarshalJSON(data []byte) error {
+	var id string
+	if err := json.Unmarshal(data, &id); err == nil {
+		*p = legacyProduct{Component: Component{ID: id}}
+		return nil
+	}
+	return json.Unmarshal(data, (*Product)(p))
+}
+
+// legacySubcomponent is a subcomponent encoded as its ID or as an object.
+type legacySubcomponent Subcomponent
+
+func (sc *legacySubcomponent) UnmarshalJSON(data []byte) error {
+	var id string
+	if err := json.Unmarshal(data, &id); err == nil {
+		*sc = legacySubcomponent{Component: Component{ID: id}}
+		return nil
+	}
+	return json.Unmarshal(data, (*Subcomponent)(sc))
 }
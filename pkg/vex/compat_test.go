@@ -60,3 +60,90 @@ func TestParse001(t *testing.T) {
 		require.NoError(t, err, msg)
 	}
 }
+
+func TestParseLegacy(t *testing.T) {
+	doc, err := ParseVersioned([]byte(`{
+  "@context": "https://openvex.dev/ns/v0.1.0",
+  "@id": "https://example.com/vex/1",
+  "author": "Wolfi J Inkinson",
+  "timestamp": "2023-06-01T10:00:00Z",
+  "version": "2",
+  "supplier": "Chainguard",
+  "statements": [
+    {
+      "vulnerability": "CVE-2023-0001",
+      "vuln_description": "A vulnerability",
+      "products": ["pkg:apk/wolfi/git@2.41.0-r0"],
+      "subcomponents": ["pkg:apk/wolfi/openssl@3.1.2-r0"],
+      "status": "not_affected",
+      "justification": "vulnerable_code_not_present",
+      "impact_statement": "The vulnerable code is not compiled in"
+    },
+    {
+      "vulnerability": {"name": "CVE-2023-0002"},
+      "products": [{"@id": "pkg:apk/wolfi/curl@8.1.0-r0", "subcomponents": [{"@id": "pkg:apk/wolfi/zlib@1.2.13-r0"}]}],
+      "status": "affected",
+      "action_statement": "Update curl"
+    }
+  ]
+}`))
+	require.NoError(t, err)
+	require.Equal(t, ContextLocator(), doc.Context)
+	require.Equal(t, 2, doc.Version)
+	require.Equal(t, "Chainguard", doc.Supplier)
+	require.Len(t, doc.Statements, 2)
+
+	require.Equal(t, Vulnerability{Name: "CVE-2023-0001", Description: "A vulnerability"}, doc.Statements[0].Vulnerability)
+	require.Equal(t, []Product{{
+		Component:     Component{ID: "pkg:apk/wolfi/git@2.41.0-r0"},
+		Subcomponents: []Subcomponent{{Component: Component{ID: "pkg:apk/wolfi/openssl@3.1.2-r0"}}},
+	}}, doc.Statements[0].Products)
+	require.Equal(t, "The vulnerable code is not compiled in", doc.Statements[0].ImpactStatement)
+
+	require.Equal(t, VulnerabilityID("CVE-2023-0002"), doc.Statements[1].Vulnerability.Name)
+	require.Equal(t, []Product{{
+		Component:     Component{ID: "pkg:apk/wolfi/curl@8.1.0-r0"},
+		Subcomponents: []Subcomponent{{Component: Component{ID: "pkg:apk/wolfi/zlib@1.2.13-r0"}}},
+	}}, doc.Statements[1].Products)
+	require.Equal(t, "Update curl", doc.Statements[1].ActionStatement)
+}
+
+func TestParseStrict(t *testing.T) {
+	current, err := os.ReadFile("testdata/v0.2.0.json")
+	require.NoError(t, err)
+	legacy, err := os.ReadFile("testdata/v0.0.1.json")
+	require.NoError(t, err)
+	noVersion, err := os.ReadFile("testdata/v0.0.1-noversion.json")
+	require.NoError(t, err)
+	unknown := []byte(`{"@context": "https://openvex.dev/ns/v9.9.9", "statements": [{"vulnerability": {"name": "CVE-2023-0001"}, "status": "fixed"}]}`)
+	noContext := []byte(`{"statements": [{"vulnerability": {"name": "CVE-2023-0001"}, "status": "fixed"}]}`)
+
+	for m, tc := range map[string]struct {
+		data         []byte
+		strictErr    bool
+		versionedErr bool
+	}{
+		"current":    {current, false, false},
+		"v0.0.1":     {legacy, false, false},
+		"no version": {noVersion, false, false},
+		"unknown":    {unknown, true, false},
+		"no context": {noContext, true, false},
+		"invalid":    {[]byte(`not json`), true, true},
+	} {
+		doc, err := ParseStrict(tc.data)
+		if tc.strictErr {
+			require.Error(t, err, m)
+		} else {
+			require.NoError(t, err, m)
+			require.NotEmpty(t, doc.Statements, m)
+		}
+
+		doc, err = ParseVersioned(tc.data)
+		if tc.versionedErr {
+			require.Error(t, err, m)
+			continue
+		}
+		require.NoError(t, err, m)
+		require.NotEmpty(t, doc.Statements, m)
+	}
+}
@@ -5,7 +5,21 @@ SPDX-License-Identifier: Apache-2.0
 
 package vex
 
-import "strings"
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/package-url/packageurl-go"
+)
+
+// WildcardProduct is a component ID that matches any identifier. Statements
+// listing a wildcard product apply to all the products of the document
+// author, eg to assert that none of them are affected by a vulnerability.
+// As the product identifiers don't tell who made them, such a statement
+// would apply to the products of any vendor, so wildcard products are only
+// matched when enabled with MatchOptions.Wildcards.
+const WildcardProduct = "*"
 
 // Component abstracts the common construct shared by product and subcomponents
 // allowing OpenVEX statements to point to a piece of software by referencing it
@@ -34,39 +48,201 @@ type Component struct {
 }
 
 // Matches returns true if one of the components identifiers match a string.
-// All types except purl are checked string vs string. Purls are a special
-// case and can match from more generic to more specific.
-// Note that a future iterarion of this function will treat CPEs in the same
-// way.
+// Identifiers are checked string vs string unless their type was registered
+// with a matching function. Purls and CPEs are a special case and can match
+// from more generic to more specific, see PurlMatches and CPEMatches.
+// Components with the WildcardProduct ID don't match, see
+// MatchOptions.Wildcards.
 func (c *Component) Matches(identifier string) bool {
+	return c.matches(identifier, nil)
+}
+
+// matches implements Matches, comparing purls with the passed options. When
+// purlOpts is nil, purl identifiers are matched with the registered function.
+func (c *Component) matches(identifier string, purlOpts *PurlMatchOptions) bool {
+	_, ok := c.match(identifier, purlOpts)
+	return ok
+}
+
+// match works like matches but also returns which field of the component
+// matched the identifier. Identifiers and hashes are checked sorted by
+// type so the result is deterministic.
+func (c *Component) match(identifier string, purlOpts *PurlMatchOptions) (IdentifierMatch, bool) {
+	idMatch := IdentifierMatch{Kind: MatchByID, Type: identifierType(c.ID), Value: c.ID, Query: identifier}
+
 	// If we have an exact match in the ID, match
 	if c.ID == identifier && c.ID != "" {
-		return true
+		return idMatch, true
 	} else if strings.HasPrefix(c.ID, "pkg:") {
 		// ... but the identifier can be a purl. If it is, then do
 		// a purl comparison:
-		if PurlMatches(c.ID, identifier) {
-			return true
+		if PurlMatchesWithOptions(c.ID, identifier, purlOpts) {
+			return idMatch, true
 		}
+	} else if strings.HasPrefix(c.ID, "cpe:") && CPEMatches(c.ID, identifier) {
+		return idMatch, true
 	}
 
-	for t, id := range c.Identifiers {
+	types := make([]IdentifierType, 0, len(c.Identifiers))
+	for t := range c.Identifiers {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+
+	for _, t := range types {
+		id := c.Identifiers[t]
+		m := IdentifierMatch{Kind: MatchByIdentifier, Type: t, Value: id, Query: identifier}
 		if id == identifier {
-			return true
+			return m, true
 		}
 
-		if t == PURL && strings.HasPrefix(identifier, "pkg:") {
-			if PurlMatches(id, identifier) {
-				return true
+		if t == PURL && purlOpts != nil {
+			if strings.HasPrefix(identifier, "pkg:") && PurlMatchesWithOptions(id, identifier, purlOpts) {
+				return m, true
 			}
+			continue
+		}
+
+		if t.matches(id, identifier) {
+			return m, true
 		}
 	}
 
-	for _, hashVal := range c.Hashes {
-		if hashVal == Hash(identifier) {
-			return true
+	algos := make([]Algorithm, 0, len(c.Hashes))
+	for algo := range c.Hashes {
+		algos = append(algos, algo)
+	}
+	sort.Slice(algos, func(i, j int) bool { return algos[i] < algos[j] })
+
+	for _, algo := range algos {
+		if c.Hashes[algo] == Hash(identifier) {
+			return IdentifierMatch{Kind: MatchByHash, Algorithm: algo, Value: string(c.Hashes[algo]), Query: identifier}, true
+		}
+	}
+
+	return IdentifierMatch{}, false
+}
+
+// identifierType returns the type of a purl or CPE used as a component ID,
+// or an empty type for any other ID.
+func identifierType(id string) IdentifierType {
+	switch {
+	case strings.HasPrefix(id, "pkg:"):
+		return PURL
+	case strings.HasPrefix(id, "cpe:2.3:"):
+		return CPE23
+	case strings.HasPrefix(id, "cpe:/"):
+		return CPE22
+	default:
+		return ""
+	}
+}
+
+// toLower returns a copy of the component with its ID, identifiers and
+// hashes in lowercase.
+func (c *Component) toLower() Component {
+	lc := Component{
+		ID:       strings.ToLower(c.ID),
+		Supplier: c.Supplier,
+	}
+	if c.Identifiers != nil {
+		lc.Identifiers = make(map[IdentifierType]string, len(c.Identifiers))
+		for t, id := range c.Identifiers {
+			lc.Identifiers[t] = strings.ToLower(id)
+		}
+	}
+	if c.Hashes != nil {
+		lc.Hashes = make(map[Algorithm]Hash, len(c.Hashes))
+		for algo, h := range c.Hashes {
+			lc.Hashes[algo] = Hash(strings.ToLower(string(h)))
 		}
 	}
+	return lc
+}
 
+// hasDigest returns true if the component is identified by the digest. The
+// digest is compared against the component hashes and, for sha-256, against
+// the digest in the version of OCI purls.
+func (c *Component) hasDigest(algo Algorithm, hash string) bool {
+	hash = strings.ToLower(hash)
+	if h, ok := c.Hashes[algo]; ok && strings.ToLower(string(h)) == hash {
+		return true
+	}
+	if algo != SHA256 {
+		return false
+	}
+
+	for _, id := range []string{c.ID, c.Identifiers[PURL]} {
+		if !strings.HasPrefix(id, "pkg:oci/") {
+			continue
+		}
+		p, err := packageurl.FromString(id)
+		if err == nil && strings.ToLower(p.Version) == "sha256:"+hash {
+			return true
+		}
+	}
 	return false
 }
+
+// DeepCopyInto copies the receiver and writes its value into out.
+func (c *Component) DeepCopyInto(out *Component) {
+	*out = *c
+	if c.Hashes != nil {
+		out.Hashes = make(map[Algorithm]Hash, len(c.Hashes))
+		for k, v := range c.Hashes {
+			out.Hashes[k] = v
+		}
+	}
+	if c.Identifiers != nil {
+		out.Identifiers = make(map[IdentifierType]string, len(c.Identifiers))
+		for k, v := range c.Identifiers {
+			out.Identifiers[k] = v
+		}
+	}
+}
+
+// Validate checks that the identifier types and hash algorithms of the
+// component are registered and that their values are valid.
+func (c *Component) Validate() error {
+	for t, id := range c.Identifiers {
+		if err := t.Validate(id); err != nil {
+			return err
+		}
+	}
+
+	for algo, h := range c.Hashes {
+		if err := algo.Validate(h); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// displayName returns the most descriptive identifier available for the
+// component. It prefers the IRI, falling back to a purl, any other software
+// identifier and finally to a hash.
+func (c *Component) displayName() string {
+	if c.ID != "" {
+		return c.ID
+	}
+
+	if purl, ok := c.Identifiers[PURL]; ok {
+		return purl
+	}
+
+	for _, t := range []IdentifierType{CPE23, CPE22} {
+		if id, ok := c.Identifiers[t]; ok {
+			return id
+		}
+	}
+
+	algos := []string{}
+	for a := range c.Hashes {
+		algos = append(algos, string(a))
+	}
+	sort.Strings(algos)
+	if len(algos) > 0 {
+		return fmt.Sprintf("%s:%s", algos[0], c.Hashes[Algorithm(algos[0])])
+	}
+	return ""
+}
@@ -20,6 +20,16 @@ func TestComponentMatches(t *testing.T) {
 			&Component{ID: "https://example.com/document.spdx.json#node"},
 			true,
 		},
+		"wildcard": {
+			"pkg:apk/wolfi/curl@8.1.2-r0?arch=x86_64",
+			&Component{ID: WildcardProduct},
+			false,
+		},
+		"wildcard empty identifier": {
+			"",
+			&Component{ID: WildcardProduct},
+			false,
+		},
 		"misc identifier": {
 			"madeup-2023-12345",
 			&Component{
@@ -57,6 +67,32 @@ func TestComponentMatches(t *testing.T) {
 			},
 			false,
 		},
+		"cpe with any version": {
+			"cpe:2.3:a:haxx:curl:8.1.2:*:*:*:*:*:*:*",
+			&Component{
+				Identifiers: map[IdentifierType]string{CPE23: "cpe:2.3:a:Haxx:curl:*:*:*:*:*:*:*:*"},
+			},
+			true,
+		},
+		"cpe 2.2 identifier": {
+			"cpe:2.3:a:haxx:curl:8.1.2:*:*:*:*:*:*:*",
+			&Component{
+				Identifiers: map[IdentifierType]string{CPE22: "cpe:/a:haxx:curl:8.1.2"},
+			},
+			true,
+		},
+		"cpe id": {
+			"cpe:2.3:a:haxx:curl:8.1.2:*:*:*:*:*:*:*",
+			&Component{ID: "cpe:2.3:a:haxx:curl:8.1.*:*:*:*:*:*:*:*"},
+			true,
+		},
+		"wrong cpe": {
+			"cpe:2.3:a:haxx:curl:8.1.2:*:*:*:*:*:*:*",
+			&Component{
+				Identifiers: map[IdentifierType]string{CPE23: "cpe:2.3:a:haxx:curl:8.0.0:*:*:*:*:*:*:*"},
+			},
+			false,
+		},
 		"hash": {
 			"77d86e9752cb933569dfa1f693ee4338e65b28b4",
 			&Component{
@@ -0,0 +1,170 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// ConflictPolicy controls how merging handles statements from different
+// documents about the same vulnerability and product.
+type ConflictPolicy int
+
+const (
+	// ConflictKeepAll keeps all statements, letting the VEX history decide
+	// the status. This is the default.
+	ConflictKeepAll ConflictPolicy = iota
+
+	// ConflictLatestWins keeps only the most recent statement about each
+	// vulnerability and product.
+	ConflictLatestWins
+
+	// ConflictAuthorPriority keeps the statement of the author listed first
+	// in the merge options AuthorPriority list. Statements of the same
+	// author are resolved keeping the most recent one.
+	ConflictAuthorPriority
+
+	// ConflictStrict fails the merge when the documents disagree on the
+	// latest status of a vulnerability in a product.
+	ConflictStrict
+)
+
+// ErrConflictingStatements is returned when merging in strict mode finds
+// documents with different statuses for the same vulnerability and product.
+var ErrConflictingStatements = errors.New("conflicting statements")
+
+// statementOrigin records where a statement being merged comes from.
+type statementOrigin struct {
+	doc    int    // Index of the source document
+	author string // Author of the statement or its document
+}
+
+// resolveConflicts applies the conflict policy of the merge options to the
+// statements. Statements listing more than one product are trimmed to the
+// products where they prevail.
+func resolveConflicts(opts *MergeOptions, ss []Statement, origins []statementOrigin) ([]Statement, error) {
+	switch opts.Conflicts {
+	case ConflictKeepAll:
+		return ss, nil
+	case ConflictStrict:
+		return ss, checkConflicts(ss, origins)
+	case ConflictLatestWins, ConflictAuthorPriority:
+	default:
+		return nil, fmt.Errorf("unknown conflict policy %d", opts.Conflicts)
+	}
+
+	rank := func(i int) int {
+		if opts.Conflicts != ConflictAuthorPriority {
+			return 0
+		}
+		if r := slices.Index(opts.AuthorPriority, origins[i].author); r != -1 {
+			return r
+		}
+		return len(opts.AuthorPriority)
+	}
+
+	// prevails returns true if statement i wins over statement j
+	prevails := func(i, j int) bool {
+		if ri, rj := rank(i), rank(j); ri != rj {
+			return ri < rj
+		}
+		if ti, tj := ss[i].Timestamp, ss[j].Timestamp; !ti.Equal(*tj) {
+			return ti.After(*tj)
+		}
+		return i > j
+	}
+
+	winners := map[string]int{}
+	for i := range ss {
+		for j := range ss[i].Products {
+			key := conflictKey(&ss[i], &ss[i].Products[j])
+			if w, ok := winners[key]; !ok || prevails(i, w) {
+				winners[key] = i
+			}
+		}
+	}
+
+	ret := []Statement{}
+	for i := range ss {
+		products := []Product{}
+		for j := range ss[i].Products {
+			if winners[conflictKey(&ss[i], &ss[i].Products[j])] == i {
+				products = append(products, ss[i].Products[j])
+			}
+		}
+		if len(products) == 0 {
+			continue
+		}
+		ss[i].Products = products
+		ret = append(ret, ss[i])
+	}
+	return ret, nil
+}
+
+// checkConflicts returns an error if two documents disagree on the latest
+// status of a vulnerability in a product.
+func checkConflicts(ss []Statement, origins []statementOrigin) error {
+	// Latest statement of each document by vulnerability and product
+	latest := map[string]map[int]int{}
+	for i := range ss {
+		for j := range ss[i].Products {
+			key := conflictKey(&ss[i], &ss[i].Products[j])
+			if latest[key] == nil {
+				latest[key] = map[int]int{}
+			}
+			doc := origins[i].doc
+			if l, ok := latest[key][doc]; !ok || !ss[i].Timestamp.Before(*ss[l].Timestamp) {
+				latest[key][doc] = i
+			}
+		}
+	}
+
+	keys := []string{}
+	for key := range latest {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		docs := []int{}
+		for doc := range latest[key] {
+			docs = append(docs, doc)
+		}
+		sort.Ints(docs)
+		first := latest[key][docs[0]]
+		for _, doc := range docs[1:] {
+			if s := latest[key][doc]; ss[s].Status != ss[first].Status {
+				return fmt.Errorf(
+					"%w: %s is %s in document #%d and %s in document #%d",
+					ErrConflictingStatements, key, ss[first].Status, docs[0], ss[s].Status, doc,
+				)
+			}
+		}
+	}
+	return nil
+}
+
+// conflictKey returns the vulnerability and product pair of a statement
+// product used to find conflicting statements.
+func conflictKey(stmt *Statement, product *Product) string {
+	vuln := stmt.Vulnerability.Name
+	if vuln == "" {
+		vuln = VulnerabilityID(stmt.Vulnerability.ID)
+	}
+	subs := []string{}
+	for i := range product.Subcomponents {
+		subs = append(subs, product.Subcomponents[i].key().String())
+	}
+	sort.Strings(subs)
+
+	key := fmt.Sprintf("%s for %s", vuln.Local(), product.key())
+	if len(subs) > 0 {
+		key += " (" + strings.Join(subs, ", ") + ")"
+	}
+	return key
+}
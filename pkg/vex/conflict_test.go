@@ -0,0 +1,108 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeConflictPolicies(t *testing.T) {
+	t1 := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+	t2 := t1.Add(time.Hour)
+
+	upstream := &VEX{
+		Metadata: Metadata{ID: "upstream", Author: "Upstream", Timestamp: &t2},
+		Statements: []Statement{
+			{
+				Vulnerability: Vulnerability{Name: "CVE-2023-0001"},
+				Products: []Product{
+					{Component: Component{ID: "pkg:apk/wolfi/curl@8.1.0"}},
+					{Component: Component{ID: "pkg:apk/wolfi/git@2.41.0"}},
+				},
+				Status:          StatusAffected,
+				ActionStatement: "Update",
+			},
+		},
+	}
+	vendor := &VEX{
+		Metadata: Metadata{ID: "vendor", Author: "Vendor", Timestamp: &t1},
+		Statements: []Statement{
+			{
+				Vulnerability: Vulnerability{Name: "CVE-2023-0001"},
+				Products:      []Product{{Component: Component{ID: "pkg:apk/wolfi/curl@8.1.0"}}},
+				Status:        StatusUnderInvestigation,
+			},
+			{
+				Vulnerability: Vulnerability{Name: "CVE-2023-0001"},
+				Products:      []Product{{Component: Component{ID: "pkg:apk/wolfi/curl@8.1.0"}}},
+				Status:        StatusNotAffected,
+				Justification: ComponentNotPresent,
+				Timestamp:     &t1,
+			},
+		},
+	}
+	docs := []*VEX{upstream, vendor}
+
+	// statuses returns the status of each product in the merged document
+	statuses := func(doc *VEX) map[string][]Status {
+		ret := map[string][]Status{}
+		for i := range doc.Statements {
+			for _, p := range doc.Statements[i].Products {
+				ret[p.ID] = append(ret[p.ID], doc.Statements[i].Status)
+			}
+		}
+		return ret
+	}
+
+	for m, tc := range map[string]struct {
+		opts      MergeOptions
+		expected  map[string][]Status
+		mustError bool
+	}{
+		"keep all": {
+			opts: MergeOptions{},
+			expected: map[string][]Status{
+				"pkg:apk/wolfi/curl@8.1.0": {StatusUnderInvestigation, StatusNotAffected, StatusAffected},
+				"pkg:apk/wolfi/git@2.41.0": {StatusAffected},
+			},
+		},
+		"latest wins": {
+			opts: MergeOptions{Conflicts: ConflictLatestWins},
+			expected: map[string][]Status{
+				"pkg:apk/wolfi/curl@8.1.0": {StatusAffected},
+				"pkg:apk/wolfi/git@2.41.0": {StatusAffected},
+			},
+		},
+		"author priority": {
+			opts: MergeOptions{Conflicts: ConflictAuthorPriority, AuthorPriority: []string{"Vendor"}},
+			expected: map[string][]Status{
+				"pkg:apk/wolfi/curl@8.1.0": {StatusNotAffected},
+				"pkg:apk/wolfi/git@2.41.0": {StatusAffected},
+			},
+		},
+		"strict": {
+			opts:      MergeOptions{Conflicts: ConflictStrict},
+			mustError: true,
+		},
+	} {
+		opts := tc.opts
+		merged, err := MergeDocumentsWithOptions(&opts, docs)
+		if tc.mustError {
+			require.ErrorIs(t, err, ErrConflictingStatements, m)
+			continue
+		}
+		require.NoError(t, err, m)
+		require.Equal(t, tc.expected, statuses(merged), m)
+	}
+
+	// Changes in the status within a document are not conflicts
+	_, err := MergeDocumentsWithOptions(&MergeOptions{Conflicts: ConflictStrict}, []*VEX{vendor, vendor})
+	require.NoError(t, err)
+
+	// Source documents are not modified when trimming products
+	require.Len(t, upstream.Statements[0].Products, 2)
+}
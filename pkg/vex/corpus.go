@@ -0,0 +1,222 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// Corpus is a read-only collection of documents loaded in bulk. Strings that
+// repeat across the corpus (purls, vulnerability IDs, authors, etc) are
+// interned so that every occurrence shares the same memory, and slices are
+// copied to arrays of their length so the spare capacity left by parsing is
+// garbage collected. Documents in a corpus must not be modified.
+type Corpus struct {
+	docs  []*VEX
+	table *internTable
+}
+
+// internTable deduplicates strings and timestamps.
+type internTable struct {
+	strings map[string]string
+	times   map[time.Time]*time.Time
+}
+
+// NewCorpus returns an empty corpus.
+func NewCorpus() *Corpus {
+	return &Corpus{
+		docs: []*VEX{},
+		table: &internTable{
+			strings: map[string]string{},
+			times:   map[time.Time]*time.Time{},
+		},
+	}
+}
+
+// LoadCorpus reads the OpenVEX documents at paths into a new corpus.
+func LoadCorpus(paths []string) (*Corpus, error) {
+	c := NewCorpus()
+	for _, path := range paths {
+		data, err := os.ReadFile(path) //nolint:gosec // This is supposed to open user-specified paths
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", path, err)
+		}
+		if err := c.AddData(data); err != nil {
+			return nil, fmt.Errorf("loading %s: %w", path, err)
+		}
+	}
+	c.docs = rightSize(c.docs)
+	return c, nil
+}
+
+// AddData parses an OpenVEX document and adds it to the corpus.
+func (c *Corpus) AddData(data []byte) error {
+	doc, err := Parse(data)
+	if err != nil {
+		return err
+	}
+	c.Add(doc)
+	return nil
+}
+
+// Add interns the data of the document and adds it to the corpus. The
+// document is owned by the corpus after it is added.
+func (c *Corpus) Add(doc *VEX) {
+	c.table.internDocument(doc)
+	c.docs = append(c.docs, doc)
+}
+
+// Documents returns the documents in the corpus.
+func (c *Corpus) Documents() []*VEX {
+	return c.docs
+}
+
+// Len returns the number of documents in the corpus.
+func (c *Corpus) Len() int {
+	return len(c.docs)
+}
+
+// Language returns the documents in the corpus published in the language.
+func (c *Corpus) Language(lang string) []*VEX {
+	return FilterByLanguage(c.docs, lang)
+}
+
+// Matches returns the statements of all documents in the corpus that apply
+// to the vulnerability, product and subcomponents.
+func (c *Corpus) Matches(vulnID, product string, subcomponents []string) []Statement {
+	ret := []Statement{}
+	for _, doc := range c.docs {
+		ret = append(ret, doc.Matches(vulnID, product, subcomponents)...)
+	}
+	return ret
+}
+
+// FindByHash returns the statement products and subcomponents of all the
+// documents in the corpus identified by an artifact digest.
+func (c *Corpus) FindByHash(algo Algorithm, hash string) []HashMatch {
+	ret := []HashMatch{}
+	for _, doc := range c.docs {
+		ret = append(ret, doc.FindByHash(algo, hash)...)
+	}
+	return ret
+}
+
+// CoverageReport returns the coverage of each vulnerability in the product
+// by the documents in the corpus. See CoverageReport.
+func (c *Corpus) CoverageReport(vulnIDs []string, product string) []Coverage {
+	return CoverageReport(vulnIDs, product, c.docs)
+}
+
+// Uncovered returns the vulnerabilities in the list without statements
+// applying to the product in the corpus.
+func (c *Corpus) Uncovered(vulnIDs []string, product string) []string {
+	return Uncovered(vulnIDs, product, c.docs)
+}
+
+// rightSize returns a copy of the slice in an array of its length, or the
+// slice itself if it has no spare capacity.
+func rightSize[S ~[]E, E any](s S) S {
+	if cap(s) == len(s) {
+		return s
+	}
+	ret := make(S, len(s))
+	copy(ret, s)
+	return ret
+}
+
+func (t *internTable) str(s string) string {
+	if s == "" {
+		return ""
+	}
+	if interned, ok := t.strings[s]; ok {
+		return interned
+	}
+	t.strings[s] = s
+	return s
+}
+
+func (t *internTable) time(ts *time.Time) *time.Time {
+	if ts == nil {
+		return nil
+	}
+	if interned, ok := t.times[*ts]; ok {
+		return interned
+	}
+	t.times[*ts] = ts
+	return ts
+}
+
+func (t *internTable) internDocument(doc *VEX) {
+	doc.Context = t.str(doc.Context)
+	doc.ID = t.str(doc.ID)
+	doc.Author = t.str(doc.Author)
+	doc.AuthorRole = t.str(doc.AuthorRole)
+	doc.Tooling = t.str(doc.Tooling)
+	doc.Supplier = t.str(doc.Supplier)
+	doc.PreviousDigest = t.str(doc.PreviousDigest)
+	doc.Lang = t.str(doc.Lang)
+	doc.Timestamp = t.time(doc.Timestamp)
+	doc.LastUpdated = t.time(doc.LastUpdated)
+
+	doc.Statements = rightSize(doc.Statements)
+	for i := range doc.Statements {
+		t.internStatement(&doc.Statements[i])
+	}
+}
+
+func (t *internTable) internStatement(stmt *Statement) {
+	stmt.ID = t.str(stmt.ID)
+	stmt.Timestamp = t.time(stmt.Timestamp)
+	stmt.LastUpdated = t.time(stmt.LastUpdated)
+	stmt.ActionStatementTimestamp = t.time(stmt.ActionStatementTimestamp)
+	stmt.Status = Status(t.str(string(stmt.Status)))
+	stmt.StatusNotes = t.str(stmt.StatusNotes)
+	stmt.Justification = Justification(t.str(string(stmt.Justification)))
+	stmt.ImpactStatement = t.str(stmt.ImpactStatement)
+	stmt.ActionStatement = t.str(stmt.ActionStatement)
+	stmt.Lang = t.str(stmt.Lang)
+	stmt.Author = t.str(stmt.Author)
+	stmt.AuthorRole = t.str(stmt.AuthorRole)
+
+	v := &stmt.Vulnerability
+	v.ID = t.str(v.ID)
+	v.Name = VulnerabilityID(t.str(string(v.Name)))
+	v.Description = t.str(v.Description)
+	v.Aliases = rightSize(v.Aliases)
+	for i := range v.Aliases {
+		v.Aliases[i] = VulnerabilityID(t.str(string(v.Aliases[i])))
+	}
+
+	stmt.Products = rightSize(stmt.Products)
+	for i := range stmt.Products {
+		p := &stmt.Products[i]
+		for _, c := range p.components() {
+			t.internComponent(c)
+		}
+		p.Subcomponents = rightSize(p.Subcomponents)
+		for j := range p.Subcomponents {
+			t.internComponent(&p.Subcomponents[j].Component)
+		}
+	}
+
+	if stmt.Annotations != nil {
+		annotations := make(map[string]string, len(stmt.Annotations))
+		for k, val := range stmt.Annotations {
+			annotations[t.str(k)] = t.str(val)
+		}
+		stmt.Annotations = annotations
+	}
+}
+
+func (t *internTable) internComponent(c *Component) {
+	c.ID = t.str(c.ID)
+	for k, id := range c.Identifiers {
+		c.Identifiers[k] = t.str(id)
+	}
+	for k, h := range c.Hashes {
+		c.Hashes[k] = Hash(t.str(string(h)))
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"strings"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCorpus(t *testing.T) {
+	corpus, err := LoadCorpus([]string{"testdata/v020-1.vex.json", "testdata/v020-2.vex.json"})
+	require.NoError(t, err)
+	require.Equal(t, 2, corpus.Len())
+
+	// Build a product ID that does not share memory with the loaded one
+	doc := New()
+	doc.Statements = []Statement{
+		{
+			Vulnerability: Vulnerability{Name: "CVE-1234-5678"},
+			Products: []Product{
+				{Component: Component{ID: strings.Join([]string{"pkg:apk/wolfi", "git@2.41.0-1"}, "/")}},
+			},
+			Status: StatusFixed,
+		},
+	}
+	corpus.Add(&doc)
+	require.Equal(t, 3, corpus.Len())
+
+	docs := corpus.Documents()
+
+	// Repeated strings share their memory
+	require.Equal(t, unsafe.StringData(docs[0].Author), unsafe.StringData(docs[1].Author))
+	require.Equal(t, unsafe.StringData(docs[0].Context), unsafe.StringData(docs[1].Context))
+	require.Equal(t,
+		unsafe.StringData(docs[1].Statements[0].Products[0].ID),
+		unsafe.StringData(docs[2].Statements[0].Products[0].ID),
+	)
+	require.Equal(t, docs[0].Statements[0].Timestamp, docs[1].Statements[0].Timestamp)
+
+	// Slices do not keep spare capacity
+	for _, d := range docs {
+		require.Equal(t, len(d.Statements), cap(d.Statements))
+		for i := range d.Statements {
+			require.Equal(t, len(d.Statements[i].Products), cap(d.Statements[i].Products))
+		}
+	}
+
+	require.Len(t, corpus.Matches("CVE-1234-5678", "pkg:apk/wolfi/git@2.41.0-1", nil), 2)
+	require.Len(t, corpus.Matches("CVE-9876-54321", "pkg:apk/wolfi/bash@1.0.0", nil), 1)
+
+	_, err = LoadCorpus([]string{"testdata/non-existent.json"})
+	require.Error(t, err)
+	require.Error(t, NewCorpus().AddData([]byte("invalid")))
+}
+
+func TestRightSize(t *testing.T) {
+	s := make([]int, 2, 10)
+	s[0], s[1] = 1, 2
+	sized := rightSize(s)
+	require.Equal(t, []int{1, 2}, sized)
+	require.Equal(t, 2, cap(sized))
+	require.NotSame(t, &s[0], &sized[0])
+
+	require.Same(t, &sized[0], &rightSize(sized)[0])
+	require.Nil(t, rightSize([]int(nil)))
+}
+
+func TestCorpusFindByHash(t *testing.T) {
+	digest := "a2b1a4e6b9bd4a1c0d5f1d6c9d7f8c2b3a4e5f6a7b8c9d0e1f2a3b4c5d6e7f80"
+	newDoc := func(id string, products ...Product) *VEX {
+		doc := New()
+		doc.ID = id
+		doc.Statements = []Statement{
+			{Vulnerability: Vulnerability{Name: "CVE-2023-1234"}, Products: products, Status: StatusNotAffected},
+		}
+		return &doc
+	}
+
+	corpus := NewCorpus()
+	corpus.Add(newDoc("hashes", Product{
+		Component: Component{Hashes: map[Algorithm]Hash{SHA256: Hash(strings.ToUpper(digest))}},
+	}))
+	corpus.Add(newDoc("artifact", Product{
+		Component: Component{ID: "pkg:generic/app@1.0"},
+		Artifacts: []Component{{Hashes: map[Algorithm]Hash{SHA256: Hash(digest)}}},
+	}))
+	corpus.Add(newDoc("subcomponent", Product{
+		Component: Component{ID: "pkg:oci/app@sha256%3A" + digest},
+		Subcomponents: []Subcomponent{
+			{Component: Component{Hashes: map[Algorithm]Hash{SHA256: Hash(digest)}}},
+			{Component: Component{Hashes: map[Algorithm]Hash{SHA512: Hash(digest)}}},
+		},
+	}))
+	corpus.Add(newDoc("other", Product{
+		Component: Component{Hashes: map[Algorithm]Hash{SHA1: Hash(digest[:40])}},
+	}))
+
+	matches := corpus.FindByHash(SHA256, digest)
+	require.Len(t, matches, 4)
+	ids := []string{}
+	for _, m := range matches {
+		ids = append(ids, m.Document.ID)
+		require.Equal(t, "CVE-2023-1234", string(m.Statement.Vulnerability.Name))
+		require.NotNil(t, m.Product)
+	}
+	require.Equal(t, []string{"hashes", "artifact", "subcomponent", "subcomponent"}, ids)
+	require.Nil(t, matches[2].Subcomponent)
+	require.NotNil(t, matches[3].Subcomponent)
+
+	require.Len(t, corpus.FindByHash(SHA1, digest[:40]), 1)
+	require.Empty(t, corpus.FindByHash(SHA384, digest))
+}
@@ -0,0 +1,229 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// cpeAttributes is the number of attributes of a CPE name: part, vendor,
+// product, version, update, edition, language, sw_edition, target_sw,
+// target_hw and other.
+const cpeAttributes = 11
+
+// cpeVersion is the index of the version attribute.
+const cpeVersion = 3
+
+// cpeName holds the attributes of a CPE name in the CPE 2.3 formatted string
+// encoding: values keep their backslash escapes so that unescaped * and ?
+// can be told apart from the quoted characters.
+type cpeName [cpeAttributes]string
+
+// parseCPE parses a CPE 2.3 formatted string (cpe:2.3:a:vendor:...) or a
+// CPE 2.2 URI (cpe:/a:vendor:...). Missing attributes are set to ANY.
+func parseCPE(s string) (cpeName, error) {
+	name := cpeName{}
+	var values []string
+	lower := strings.ToLower(s)
+	switch {
+	case strings.HasPrefix(lower, "cpe:2.3:"):
+		values = splitCPE(s[len("cpe:2.3:"):])
+		if len(values) != cpeAttributes {
+			return name, fmt.Errorf("cpe 2.3 names must have %d attributes, found %d", cpeAttributes, len(values))
+		}
+	case strings.HasPrefix(lower, "cpe:/"):
+		var err error
+		values, err = parseCPE22(s[len("cpe:/"):])
+		if err != nil {
+			return name, err
+		}
+	default:
+		return name, errors.New("identifier is not a cpe")
+	}
+
+	for i := range name {
+		name[i] = "*"
+		if i < len(values) && values[i] != "" {
+			name[i] = strings.ToLower(values[i])
+		}
+	}
+	return name, nil
+}
+
+// splitCPE splits the attributes of a CPE 2.3 formatted string at the
+// colons not quoted by a backslash.
+func splitCPE(s string) []string {
+	ret := []string{}
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case ':':
+			ret = append(ret, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(ret, s[start:])
+}
+
+// parseCPE22 returns the attributes of a CPE 2.2 URI (without its cpe:/
+// prefix) in the 2.3 formatted string encoding, unpacking the extended
+// attributes of the edition component.
+func parseCPE22(s string) ([]string, error) {
+	components := strings.Split(s, ":")
+	if len(components) > 7 {
+		return nil, fmt.Errorf("cpe 2.2 names have up to 7 components, found %d", len(components))
+	}
+
+	values := []string{}
+	for i, c := range components {
+		decoded, err := url.PathUnescape(c)
+		if err != nil {
+			return nil, fmt.Errorf("decoding cpe component %q: %w", c, err)
+		}
+		if i == 5 && strings.HasPrefix(decoded, "~") {
+			// Packed edition: ~edition~sw_edition~target_sw~target_hw~other
+			packed := strings.Split(decoded[1:], "~")
+			if len(packed) != 5 {
+				return nil, fmt.Errorf("packed cpe edition %q must have 5 attributes", decoded)
+			}
+			// The language goes between edition and sw_edition
+			lang := ""
+			if len(components) == 7 {
+				if lang, err = url.PathUnescape(components[6]); err != nil {
+					return nil, fmt.Errorf("decoding cpe component %q: %w", components[6], err)
+				}
+			}
+			values = append(values, quoteCPE(packed[0]), quoteCPE(lang))
+			for _, v := range packed[1:] {
+				values = append(values, quoteCPE(v))
+			}
+			return values, nil
+		}
+		values = append(values, quoteCPE(decoded))
+	}
+	return values, nil
+}
+
+// quoteCPE encodes a CPE 2.2 value in the 2.3 formatted string encoding,
+// quoting all the characters that are not alphanumeric, - . or _. The
+// logical values of 2.2 (empty and "-") are kept as they are.
+func quoteCPE(v string) string {
+	if v == "-" {
+		return v
+	}
+	var b strings.Builder
+	for _, r := range v {
+		if !isCPEPlain(r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// isCPEPlain returns true if the character does not need to be quoted in
+// CPE 2.3 formatted strings.
+func isCPEPlain(r rune) bool {
+	return r == '-' || r == '.' || r == '_' ||
+		(r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+}
+
+// unquoteCPE removes the backslash escapes from a CPE 2.3 value.
+func unquoteCPE(v string) string {
+	var b strings.Builder
+	for i := 0; i < len(v); i++ {
+		if v[i] == '\\' && i+1 < len(v) {
+			i++
+		}
+		b.WriteByte(v[i])
+	}
+	return b.String()
+}
+
+// CPEMatches returns true if cpe1 matches the more specific cpe2. Both CPEs
+// can be in the 2.3 formatted string or the 2.2 URI bindings. Attributes are
+// compared case insensitively: ANY values (* or empty) in cpe1 match any
+// value in cpe2, NA values (-) only match NA, and the unquoted * and ?
+// wildcards in cpe1 values match any number of characters or a single one.
+// The version of cpe1 can also be a vers version range (quoting its colon,
+// eg vers\:semver/>=1.0.0|<1.2.0) that matches the versions of cpe2 in the
+// range.
+func CPEMatches(cpe1, cpe2 string) bool {
+	pattern, err := parseCPE(cpe1)
+	if err != nil {
+		return false
+	}
+	name, err := parseCPE(cpe2)
+	if err != nil {
+		return false
+	}
+
+	for i := range pattern {
+		if !cpeValueMatches(pattern[i], name[i], i == cpeVersion) {
+			return false
+		}
+	}
+	return true
+}
+
+// cpeValueMatches returns true if the value of a CPE attribute matches the
+// pattern value.
+func cpeValueMatches(pattern, value string, isVersion bool) bool {
+	switch {
+	case pattern == "*":
+		return true
+	case pattern == "-" || value == "-":
+		return pattern == value
+	case value == "*":
+		// ANY only matches ANY, which was handled above
+		return false
+	}
+
+	if isVersion {
+		if rangeString := unquoteCPE(pattern); strings.HasPrefix(rangeString, "vers:") {
+			vr, err := ParseVersionRange(rangeString)
+			if err != nil {
+				return false
+			}
+			return vr.Contains(unquoteCPE(value))
+		}
+	}
+	return cpeGlob(pattern, unquoteCPE(value))
+}
+
+// cpeGlob matches the text against a quoted CPE value where unquoted * and
+// ? are wildcards.
+func cpeGlob(pattern, text string) bool {
+	for pattern != "" {
+		switch pattern[0] {
+		case '*':
+			for i := len(text); i >= 0; i-- {
+				if cpeGlob(pattern[1:], text[i:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if text == "" {
+				return false
+			}
+			pattern, text = pattern[1:], text[1:]
+			continue
+		case '\\':
+			if len(pattern) > 1 {
+				pattern = pattern[1:]
+			}
+		}
+		if text == "" || pattern[0] != text[0] {
+			return false
+		}
+		pattern, text = pattern[1:], text[1:]
+	}
+	return text == ""
+}
@@ -0,0 +1,80 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCPE(t *testing.T) {
+	for m, tc := range map[string]struct {
+		cpe       string
+		expected  cpeName
+		mustError bool
+	}{
+		"cpe 2.3": {
+			cpe:      "cpe:2.3:a:Haxx:Curl:8.1.0:*:*:*:*:*:*:*",
+			expected: cpeName{"a", "haxx", "curl", "8.1.0", "*", "*", "*", "*", "*", "*", "*"},
+		},
+		"cpe 2.3 quoted colon": {
+			cpe:      `cpe:2.3:a:example:app:vers\:semver/<1.2.0:*:*:*:*:*:*:*`,
+			expected: cpeName{"a", "example", "app", `vers\:semver/<1.2.0`, "*", "*", "*", "*", "*", "*", "*"},
+		},
+		"cpe 2.2": {
+			cpe:      "cpe:/a:haxx:curl:8.1.0",
+			expected: cpeName{"a", "haxx", "curl", "8.1.0", "*", "*", "*", "*", "*", "*", "*"},
+		},
+		"cpe 2.2 encoded": {
+			cpe:      "cpe:/a:microsoft:internet_explorer:8.%2a:sp%3f",
+			expected: cpeName{"a", "microsoft", "internet_explorer", `8.\*`, `sp\?`, "*", "*", "*", "*", "*", "*"},
+		},
+		"cpe 2.2 packed edition": {
+			cpe:      "cpe:/a:hp:insight_diagnostics:7.4.0.1570:-:~~online~win2003~x64~:en",
+			expected: cpeName{"a", "hp", "insight_diagnostics", "7.4.0.1570", "-", "*", "en", "online", "win2003", "x64", "*"},
+		},
+		"not a cpe":             {cpe: "pkg:apk/wolfi/curl@8.1.0", mustError: true},
+		"cpe 2.3 missing attrs": {cpe: "cpe:2.3:a:haxx:curl", mustError: true},
+		"cpe 2.2 bad packing":   {cpe: "cpe:/a:hp:app:1.0:-:~online", mustError: true},
+	} {
+		name, err := parseCPE(tc.cpe)
+		if tc.mustError {
+			require.Error(t, err, m)
+			continue
+		}
+		require.NoError(t, err, m)
+		require.Equal(t, tc.expected, name, m)
+	}
+}
+
+func TestCPEMatches(t *testing.T) {
+	for m, tc := range map[string]struct {
+		cpe1     string
+		cpe2     string
+		expected bool
+	}{
+		"same":                 {"cpe:2.3:a:haxx:curl:8.1.0:*:*:*:*:*:*:*", "cpe:2.3:a:haxx:curl:8.1.0:*:*:*:*:*:*:*", true},
+		"case insensitive":     {"cpe:2.3:a:Haxx:cURL:8.1.0:*:*:*:*:*:*:*", "cpe:2.3:a:haxx:curl:8.1.0:*:*:*:*:*:*:*", true},
+		"any version":          {"cpe:2.3:a:haxx:curl:*:*:*:*:*:*:*:*", "cpe:2.3:a:haxx:curl:8.1.0:*:*:*:*:*:*:*", true},
+		"specific vs any":      {"cpe:2.3:a:haxx:curl:8.1.0:*:*:*:*:*:*:*", "cpe:2.3:a:haxx:curl:*:*:*:*:*:*:*:*", false},
+		"different version":    {"cpe:2.3:a:haxx:curl:8.1.0:*:*:*:*:*:*:*", "cpe:2.3:a:haxx:curl:8.1.1:*:*:*:*:*:*:*", false},
+		"different product":    {"cpe:2.3:a:haxx:curl:*:*:*:*:*:*:*:*", "cpe:2.3:a:haxx:libcurl:8.1.0:*:*:*:*:*:*:*", false},
+		"wildcard":             {"cpe:2.3:a:haxx:curl:8.1.*:*:*:*:*:*:*:*", "cpe:2.3:a:haxx:curl:8.1.2:*:*:*:*:*:*:*", true},
+		"wildcard no match":    {"cpe:2.3:a:haxx:curl:8.1.*:*:*:*:*:*:*:*", "cpe:2.3:a:haxx:curl:8.2.0:*:*:*:*:*:*:*", false},
+		"single char wildcard": {"cpe:2.3:a:haxx:curl:8.1.?:*:*:*:*:*:*:*", "cpe:2.3:a:haxx:curl:8.1.2:*:*:*:*:*:*:*", true},
+		"quoted star":          {`cpe:2.3:a:haxx:curl:8.1.\*:*:*:*:*:*:*:*`, "cpe:2.3:a:haxx:curl:8.1.2:*:*:*:*:*:*:*", false},
+		"na matches na":        {"cpe:2.3:a:haxx:curl:8.1.0:-:*:*:*:*:*:*", "cpe:2.3:a:haxx:curl:8.1.0:-:*:*:*:*:*:*", true},
+		"na vs value":          {"cpe:2.3:a:haxx:curl:8.1.0:-:*:*:*:*:*:*", "cpe:2.3:a:haxx:curl:8.1.0:rc1:*:*:*:*:*:*", false},
+		"any matches na":       {"cpe:2.3:a:haxx:curl:8.1.0:*:*:*:*:*:*:*", "cpe:2.3:a:haxx:curl:8.1.0:-:*:*:*:*:*:*", true},
+		"cpe 2.2 vs 2.3":       {"cpe:/a:haxx:curl", "cpe:2.3:a:haxx:curl:8.1.0:*:*:*:*:*:*:*", true},
+		"cpe 2.3 vs 2.2":       {"cpe:2.3:a:haxx:curl:8.1.0:*:*:*:*:*:*:*", "cpe:/a:haxx:curl:8.1.0", true},
+		"version range":        {`cpe:2.3:a:haxx:curl:vers\:generic/>=8.0.0|<8.2.0:*:*:*:*:*:*:*`, "cpe:2.3:a:haxx:curl:8.1.0:*:*:*:*:*:*:*", true},
+		"out of version range": {`cpe:2.3:a:haxx:curl:vers\:generic/>=8.0.0|<8.2.0:*:*:*:*:*:*:*`, "cpe:2.3:a:haxx:curl:8.2.0:*:*:*:*:*:*:*", false},
+		"invalid":              {"cpe:2.3:a:haxx", "cpe:2.3:a:haxx:curl:8.1.0:*:*:*:*:*:*:*", false},
+		"not a cpe":            {"cpe:2.3:a:haxx:curl:*:*:*:*:*:*:*:*", "pkg:generic/curl@8.1.0", false},
+	} {
+		require.Equal(t, tc.expected, CPEMatches(tc.cpe1, tc.cpe2), m)
+	}
+}
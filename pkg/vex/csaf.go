@@ -0,0 +1,635 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/package-url/packageurl-go"
+
+	"github.com/openvex/go-vex/pkg/csaf"
+)
+
+// csafDefaultNamespace is the publisher namespace used in exported CSAF
+// documents when it cannot be derived from the document ID.
+const csafDefaultNamespace = "https://openvex.dev"
+
+// statusFromCSAFCategory maps all the CSAF product status categories to
+// OpenVEX statuses. Unlike StatusFromCSAF it understands the first and last
+// affected and fixed categories. The recommended category has no OpenVEX
+// equivalent and returns an empty status.
+func statusFromCSAFCategory(category string) Status {
+	switch category {
+	case "first_affected", "last_affected":
+		return StatusAffected
+	case "first_fixed":
+		return StatusFixed
+	default:
+		return StatusFromCSAF(category)
+	}
+}
+
+// FromCSAF converts a CSAF VEX document into an OpenVEX document. The product
+// tree is resolved to build the statement products, each product status
+// bucket is mapped to its OpenVEX status and:
+//
+//   - Flags become the justification of not_affected statements, CSAF flag
+//     labels are the same as the OpenVEX justifications.
+//   - Threats in the impact category become the impact statement of
+//     not_affected statements.
+//   - Remediations become the remediations and action statement of
+//     affected statements.
+//
+// Flags and remediations referencing product groups are not resolved as
+// the product groups are not read from the CSAF document.
+func FromCSAF(csafDoc *csaf.CSAF) (*VEX, error) {
+	if csafDoc == nil {
+		return nil, errors.New("csaf document is nil")
+	}
+
+	ts := csafDoc.Document.Tracking.CurrentReleaseDate
+	v := &VEX{
+		Metadata: Metadata{
+			Context:   ContextLocator(),
+			ID:        csafDoc.Document.Tracking.ID,
+			Author:    csafDoc.Document.Publisher.Name,
+			Timestamp: &ts,
+			Version:   1,
+			Lang:      csafDoc.Document.Lang,
+		},
+		Statements: []Statement{},
+	}
+	if v.Author == "" {
+		v.Author = DefaultAuthor
+	}
+	if g := csafDoc.Document.Tracking.Generator; g != nil && g.Engine.Name != "" {
+		v.Tooling = Tooling{Name: g.Engine.Name, Version: g.Engine.Version}.String()
+	}
+
+	products := csafProducts(csafDoc)
+	for i := range csafDoc.Vulnerabilities {
+		cv := &csafDoc.Vulnerabilities[i]
+		vuln := Vulnerability{Name: VulnerabilityID(cv.CVE)}
+		for _, id := range cv.IDs {
+			if id.Text != "" && id.Text != cv.CVE {
+				vuln.Aliases = append(vuln.Aliases, VulnerabilityID(id.Text))
+			}
+		}
+		if vuln.Name == "" && len(vuln.Aliases) > 0 {
+			vuln.Name, vuln.Aliases = vuln.Aliases[0], vuln.Aliases[1:]
+		}
+		for _, n := range cv.Notes {
+			if n.Category == "description" {
+				vuln.Description = n.Text
+				break
+			}
+		}
+		vuln.Ratings = csafRatings(cv.Scores)
+
+		stmtTime := cv.ReleaseDate
+		if stmtTime.IsZero() {
+			stmtTime = ts
+		}
+
+		categories := []string{}
+		for category := range cv.ProductStatus {
+			categories = append(categories, category)
+		}
+		sort.Strings(categories)
+
+		for _, category := range categories {
+			if category == "recommended" {
+				continue
+			}
+			status := statusFromCSAFCategory(category)
+			if status == "" {
+				return nil, fmt.Errorf("unknown product status %q in %s", category, vuln.Name)
+			}
+
+			for _, productID := range cv.ProductStatus[category] {
+				stmtProducts, ok := products[productID]
+				if !ok {
+					stmtProducts = []Product{{Component: Component{ID: productID}}}
+				}
+
+				stmtTS := stmtTime
+				stmt := Statement{
+					Vulnerability: vuln,
+					Timestamp:     &stmtTS,
+					Products:      stmtProducts,
+					Status:        status,
+					References:    referencesFromCSAF(csafDoc, cv),
+					Credits:       creditsFromCSAF(csafDoc, cv),
+				}
+
+				switch status {
+				case StatusNotAffected:
+					stmt.Justification = csafJustification(cv, productID)
+					stmt.ImpactStatement = csafThreats(cv, productID, "impact")
+				case StatusAffected:
+					remediations := csafRemediations(cv, productID)
+					stmt.ActionStatement = RemediationsActionStatement(remediations)
+					stmt.Remediations = slices.DeleteFunc(remediations, func(r Remediation) bool {
+						return !r.Category.Valid()
+					})
+					if len(stmt.Remediations) == 0 {
+						stmt.Remediations = nil
+					}
+					if stmt.ActionStatement == "" {
+						stmt.ActionStatement = NoActionStatementMsg
+					}
+				default:
+				}
+
+				v.Statements = append(v.Statements, stmt)
+			}
+		}
+	}
+
+	return v, nil
+}
+
+// csafJustification returns the justification flagged for the product.
+func csafJustification(cv *csaf.Vulnerability, productID string) Justification {
+	for _, f := range cv.Flags {
+		if !slices.Contains(f.ProductIDs, productID) {
+			continue
+		}
+		if j := Justification(f.Label); j.Valid() {
+			return j
+		}
+	}
+	return ""
+}
+
+// csafThreats joins the details of the threats of a category that apply
+// to the product.
+func csafThreats(cv *csaf.Vulnerability, productID, category string) string {
+	details := []string{}
+	for _, t := range cv.Threats {
+		if t.Category == category && t.Details != "" && slices.Contains(t.ProductIDs, productID) {
+			details = append(details, t.Details)
+		}
+	}
+	return strings.Join(details, "\n")
+}
+
+// csafRemediations returns the remediations that apply to the product.
+func csafRemediations(cv *csaf.Vulnerability, productID string) []Remediation {
+	remediations := []Remediation{}
+	for i := range cv.Remediations {
+		r := &cv.Remediations[i]
+		if !slices.Contains(r.ProductIDs, productID) {
+			continue
+		}
+		remediation := Remediation{
+			Category: RemediationCategory(r.Category),
+			Details:  r.Details,
+			URL:      r.URL,
+		}
+		if !r.Date.IsZero() {
+			date := r.Date
+			remediation.Date = &date
+		}
+		remediations = append(remediations, remediation)
+	}
+	return remediations
+}
+
+// ToCSAF converts the document into a CSAF 2.0 document conforming to the
+// CSAF VEX profile. Products and subcomponents are written as branches of
+// the product tree, subcomponents are linked to their products with
+// default_component_of relationships. Statements are grouped by
+// vulnerability and their products recorded in the product status buckets:
+//
+//   - not_affected justifications are written as flags and impact
+//     statements as impact threats.
+//   - affected statement remediations are written as CSAF remediations.
+//     Statements without remediations get their action statement written
+//     as a mitigation remediation.
+//
+// The tracking metadata is derived from the document ID, version, timestamps
+// and tooling. The publisher is the name of the author and its namespace is
+// read from the author URI or, failing that, the document ID when they are
+// URLs.
+func (vexDoc *VEX) ToCSAF() (*csaf.CSAF, error) {
+	initial := vexDoc.Timestamp
+	if initial == nil {
+		return nil, errors.New("document has no timestamp")
+	}
+	current := initial
+	if vexDoc.LastUpdated != nil {
+		current = vexDoc.LastUpdated
+	}
+	version := strconv.Itoa(max(vexDoc.Version, 1))
+	author := vexDoc.AuthorDetails()
+	if author.Name == "" {
+		author.Name = vexDoc.Author
+	}
+	namespace := csafNamespace(vexDoc.ID)
+	if ns := csafNamespace(author.URI); ns != csafDefaultNamespace {
+		namespace = ns
+	}
+
+	csafDoc := &csaf.CSAF{
+		Document: csaf.DocumentMetadata{
+			Category:    csaf.CategoryVEX,
+			CSAFVersion: csaf.Version,
+			Title:       "OpenVEX document " + vexDoc.ID,
+			Lang:        vexDoc.Lang,
+			Publisher: csaf.Publisher{
+				Category:  "other",
+				Name:      author.Name,
+				Namespace: namespace,
+			},
+			Tracking: csaf.Tracking{
+				ID:                 vexDoc.ID,
+				InitialReleaseDate: initial.UTC(),
+				CurrentReleaseDate: current.UTC(),
+				Status:             "final",
+				Version:            version,
+				RevisionHistory: []csaf.Revision{
+					{Date: current.UTC(), Number: version, Summary: "Converted from OpenVEX"},
+				},
+			},
+		},
+		Vulnerabilities: []csaf.Vulnerability{},
+	}
+	if vexDoc.Tooling != "" {
+		tooling := vexDoc.ToolingDetails()
+		csafDoc.Document.Tracking.Generator = &csaf.Generator{
+			Engine: csaf.Engine{Name: tooling.Name, Version: tooling.Version},
+		}
+	}
+
+	products := map[string]struct{}{}
+	addProduct := func(c *Component) (string, error) {
+		id := c.ID
+		if id == "" {
+			id = c.Identifiers[PURL]
+		}
+		if id == "" {
+			return "", errors.New("component has no identifier usable as product ID")
+		}
+		if _, ok := products[id]; !ok {
+			products[id] = struct{}{}
+			csafDoc.ProductTree.Branches = append(csafDoc.ProductTree.Branches, csafBranch(id, c))
+		}
+		return id, nil
+	}
+
+	// Add all the products to the tree before indexing it to record the
+	// subcomponents as relationships
+	for i := range vexDoc.Statements {
+		for j := range vexDoc.Statements[i].Products {
+			p := &vexDoc.Statements[i].Products[j]
+			if _, err := addProduct(&p.Component); err != nil {
+				return nil, fmt.Errorf("statement #%d product: %w", i, err)
+			}
+			for k := range p.Subcomponents {
+				if _, err := addProduct(&p.Subcomponents[k].Component); err != nil {
+					return nil, fmt.Errorf("statement #%d subcomponent: %w", i, err)
+				}
+			}
+		}
+	}
+	relationships := csaf.NewRelationshipIndex(&csafDoc.ProductTree)
+
+	vulns := map[VulnerabilityID]int{}
+	for i := range vexDoc.Statements {
+		stmt := &vexDoc.Statements[i]
+		category := statusToCSAF(stmt.Status)
+		if category == "" {
+			return nil, fmt.Errorf("statement #%d has invalid status %q", i, stmt.Status)
+		}
+
+		name := stmt.Vulnerability.Name
+		if name == "" {
+			name = VulnerabilityID(stmt.Vulnerability.ID)
+		}
+		if name == "" {
+			return nil, fmt.Errorf("statement #%d has no vulnerability identifier", i)
+		}
+		idx, ok := vulns[name]
+		if !ok {
+			idx = len(csafDoc.Vulnerabilities)
+			vulns[name] = idx
+			csafDoc.Vulnerabilities = append(csafDoc.Vulnerabilities, csafVulnerability(name, &stmt.Vulnerability))
+		}
+		cv := &csafDoc.Vulnerabilities[idx]
+
+		// Collect the product IDs of the statement. Subcomponents are
+		// recorded as relationships with their product.
+		ids := []string{}
+		for j := range stmt.Products {
+			p := &stmt.Products[j]
+			productID, err := addProduct(&p.Component)
+			if err != nil {
+				return nil, fmt.Errorf("statement #%d product: %w", i, err)
+			}
+			if len(p.Subcomponents) == 0 {
+				ids = append(ids, productID)
+				continue
+			}
+			for k := range p.Subcomponents {
+				subID, err := addProduct(&p.Subcomponents[k].Component)
+				if err != nil {
+					return nil, fmt.Errorf("statement #%d subcomponent: %w", i, err)
+				}
+				relID, err := relationships.Add(csaf.RelationshipDefaultComponentOf, subID, productID)
+				if err != nil {
+					return nil, fmt.Errorf("statement #%d subcomponent: %w", i, err)
+				}
+				ids = append(ids, relID)
+			}
+		}
+
+		addCSAFScores(cv, stmt.Vulnerability.Ratings, ids)
+
+		if cv.ProductStatus == nil {
+			cv.ProductStatus = map[string][]string{}
+		}
+		for _, id := range ids {
+			if !slices.Contains(cv.ProductStatus[category], id) {
+				cv.ProductStatus[category] = append(cv.ProductStatus[category], id)
+			}
+		}
+
+		var ts time.Time
+		if stmt.Timestamp != nil {
+			ts = stmt.Timestamp.UTC()
+		} else {
+			ts = initial.UTC()
+		}
+		if ts.After(cv.ReleaseDate) {
+			cv.ReleaseDate = ts
+		}
+
+		switch stmt.Status {
+		case StatusNotAffected:
+			if stmt.Justification != "" {
+				cv.Flags = append(cv.Flags, csaf.Flag{
+					Label: string(stmt.Justification), Date: ts, ProductIDs: ids,
+				})
+			}
+			if stmt.ImpactStatement != "" {
+				cv.Threats = append(cv.Threats, csaf.ThreatData{
+					Category: "impact", Details: stmt.ImpactStatement, ProductIDs: ids,
+				})
+			}
+		case StatusAffected:
+			if len(stmt.Remediations) > 0 {
+				cv.Remediations = append(cv.Remediations, remediationsToCSAF(stmt, ts, ids)...)
+				break
+			}
+			remediation := csaf.RemediationData{
+				Category: "mitigation", Date: ts, Details: stmt.ActionStatement, ProductIDs: ids,
+			}
+			if stmt.ActionStatement == "" || stmt.ActionStatement == NoActionStatementMsg {
+				remediation.Category = "none_available"
+				remediation.Details = NoActionStatementMsg
+			}
+			cv.Remediations = append(cv.Remediations, remediation)
+		case StatusFixed, StatusUnderInvestigation:
+		}
+
+		if stmt.StatusNotes != "" {
+			cv.Notes = append(cv.Notes, csaf.Note{
+				Category: "details", Title: "Status notes", Text: stmt.StatusNotes,
+			})
+		}
+		for _, r := range stmt.References {
+			if !slices.ContainsFunc(cv.References, func(cr csaf.Reference) bool { return cr.URL == r.URL }) {
+				cv.References = append(cv.References, csaf.Reference{Category: r.Category, Summary: r.Summary, URL: r.URL})
+			}
+		}
+		for _, c := range stmt.Credits {
+			ack := csaf.Acknowledgment{Names: c.Names, Organization: c.Organization, Summary: c.Summary, URLs: c.URLs}
+			if !slices.ContainsFunc(cv.Acknowledgments, func(a csaf.Acknowledgment) bool {
+				return a.Organization == ack.Organization && slices.Equal(a.Names, ack.Names)
+			}) {
+				cv.Acknowledgments = append(cv.Acknowledgments, ack)
+			}
+		}
+	}
+
+	// The VEX profile requires notes in all vulnerabilities
+	for i := range csafDoc.Vulnerabilities {
+		cv := &csafDoc.Vulnerabilities[i]
+		if len(cv.Notes) == 0 {
+			cv.Notes = []csaf.Note{{Category: "summary", Text: "VEX statements for " + cv.CVE}}
+			if cv.CVE == "" {
+				cv.Notes[0].Text = "VEX statements for " + cv.IDs[0].Text
+			}
+		}
+	}
+
+	return csafDoc, nil
+}
+
+// statusToCSAF returns the CSAF product status category of a status.
+func statusToCSAF(s Status) string {
+	switch s {
+	case StatusNotAffected:
+		return "known_not_affected"
+	case StatusAffected:
+		return "known_affected"
+	case StatusFixed:
+		return "fixed"
+	case StatusUnderInvestigation:
+		return "under_investigation"
+	default:
+		return ""
+	}
+}
+
+// csafVulnerability returns a new CSAF vulnerability with the identifiers
+// and description of the OpenVEX vulnerability.
+func csafVulnerability(name VulnerabilityID, vuln *Vulnerability) csaf.Vulnerability {
+	cv := csaf.Vulnerability{}
+	for _, id := range append([]VulnerabilityID{name}, vuln.Aliases...) {
+		if cv.CVE == "" && strings.HasPrefix(string(id), "CVE-") {
+			cv.CVE = string(id)
+			continue
+		}
+		cv.IDs = append(cv.IDs, csaf.TrackingID{SystemName: csafSystemName(id), Text: string(id)})
+	}
+	if vuln.Description != "" {
+		cv.Notes = append(cv.Notes, csaf.Note{Category: "description", Text: vuln.Description})
+	}
+	return cv
+}
+
+// remediationsToCSAF returns the CSAF remediations of the structured
+// remediations of an affected statement. Remediations without a date are
+// dated with the statement timestamp and the action statement is used as
+// the details of the remediations without them. CSAF remediations have no
+// due date, so it is not converted.
+func remediationsToCSAF(stmt *Statement, ts time.Time, ids []string) []csaf.RemediationData {
+	ret := make([]csaf.RemediationData, 0, len(stmt.Remediations))
+	for i := range stmt.Remediations {
+		r := &stmt.Remediations[i]
+		remediation := csaf.RemediationData{
+			Category: string(r.Category), Date: ts, Details: r.Details, URL: r.URL, ProductIDs: ids,
+		}
+		if r.Date != nil {
+			remediation.Date = r.Date.UTC()
+		}
+		if remediation.Details == "" {
+			remediation.Details = stmt.ActionStatement
+		}
+		ret = append(ret, remediation)
+	}
+	return ret
+}
+
+// csafRatings returns the ratings of the CVSS scores of a CSAF vulnerability.
+// As OpenVEX ratings are not tied to products, the scores of all products
+// are returned.
+func csafRatings(scores []csaf.Score) []Rating {
+	var ratings []Rating
+	add := func(r Rating) {
+		if r.Severity == "" {
+			r.Severity = SeverityFromScore(r.Method, r.Score)
+		}
+		if !slices.Contains(ratings, r) {
+			ratings = append(ratings, r)
+		}
+	}
+	for i := range scores {
+		if v3 := &scores[i].CVSSV3; v3.VectorString != "" {
+			method := RatingCVSSv31
+			if v3.Version == "3.0" {
+				method = RatingCVSSv3
+			}
+			add(Rating{
+				Method: method, Vector: v3.VectorString, Score: v3.BaseScore,
+				Severity: strings.ToLower(v3.BaseSeverity),
+			})
+		}
+		if v2 := &scores[i].CVSSV2; v2.VectorString != "" {
+			add(Rating{Method: RatingCVSSv2, Vector: v2.VectorString, Score: v2.BaseScore})
+		}
+	}
+	return ratings
+}
+
+// addCSAFScores adds the CVSS ratings of a vulnerability to the scores of
+// the products. Ratings already in the CSAF vulnerability get the products
+// added to their scores. CSAF 2.0 only supports CVSS v2 and v3 scores, other
+// ratings and ratings without a vector are skipped. The version of ratings
+// without a method is read from their vector.
+func addCSAFScores(cv *csaf.Vulnerability, ratings []Rating, ids []string) {
+	for i := range ratings {
+		r := &ratings[i]
+		if r.Vector == "" {
+			continue
+		}
+		idx := slices.IndexFunc(cv.Scores, func(s csaf.Score) bool {
+			return s.CVSSV3.VectorString == r.Vector || s.CVSSV2.VectorString == r.Vector
+		})
+		if idx != -1 {
+			for _, id := range ids {
+				if !slices.Contains(cv.Scores[idx].ProductIDs, id) {
+					cv.Scores[idx].ProductIDs = append(cv.Scores[idx].ProductIDs, id)
+				}
+			}
+			continue
+		}
+
+		method := r.Method
+		if cvss, err := ParseCVSS(r.Vector); method == "" && err == nil {
+			method = cvss.Method()
+		}
+		severity := r.Severity
+		if severity == "" {
+			severity = SeverityFromScore(method, r.Score)
+		}
+
+		score := csaf.Score{ProductIDs: slices.Clone(ids)}
+		switch method {
+		case RatingCVSSv3, RatingCVSSv31:
+			version := "3.1"
+			if method == RatingCVSSv3 {
+				version = "3.0"
+			}
+			score.CVSSV3 = csaf.CVSSV3{
+				Version: version, VectorString: r.Vector, BaseScore: r.Score,
+				BaseSeverity: strings.ToUpper(severity),
+			}
+		case RatingCVSSv2:
+			score.CVSSV2 = csaf.CVSSV2{Version: "2.0", VectorString: r.Vector, BaseScore: r.Score}
+		default:
+			continue
+		}
+		cv.Scores = append(cv.Scores, score)
+	}
+}
+
+// csafSystemName returns the name of the system that issued a vulnerability
+// identifier, read from the identifier prefix.
+func csafSystemName(id VulnerabilityID) string {
+	if prefix, _, ok := strings.Cut(string(id), "-"); ok && prefix != "" {
+		return prefix
+	}
+	return "OpenVEX"
+}
+
+// csafNamespace returns the publisher namespace for a document ID.
+func csafNamespace(id string) string {
+	u, err := url.Parse(id)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return csafDefaultNamespace
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+// csafBranch returns the product tree branch of a component. Components
+// identified by a purl are nested in a product_version branch under their
+// product_name.
+func csafBranch(id string, c *Component) csaf.ProductBranch {
+	product := csaf.Product{Name: id, ID: id}
+	purl := c.Identifiers[PURL]
+	if purl == "" && strings.HasPrefix(c.ID, "pkg:") {
+		purl = c.ID
+	}
+	if purl != "" {
+		product.IdentificationHelper = map[string]string{"purl": purl}
+	}
+	for _, t := range []IdentifierType{CPE23, CPE22} {
+		if cpe := c.Identifiers[t]; cpe != "" {
+			if product.IdentificationHelper == nil {
+				product.IdentificationHelper = map[string]string{}
+			}
+			product.IdentificationHelper["cpe"] = cpe
+			break
+		}
+	}
+
+	p, err := packageurl.FromString(purl)
+	if purl == "" || err != nil {
+		return csaf.ProductBranch{Category: "product_name", Name: id, Product: product}
+	}
+	name := p.Name
+	if p.Namespace != "" {
+		name = p.Namespace + "/" + p.Name
+	}
+	if p.Version == "" {
+		return csaf.ProductBranch{Category: "product_name", Name: name, Product: product}
+	}
+	return csaf.ProductBranch{
+		Category: "product_name",
+		Name:     name,
+		Branches: []csaf.ProductBranch{
+			{Category: "product_version", Name: p.Version, Product: product},
+		},
+	}
+}
@@ -0,0 +1,271 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/openvex/go-vex/pkg/csaf"
+)
+
+func TestFromCSAF(t *testing.T) {
+	csafDoc, err := csaf.Open("testdata/csaf.json")
+	require.NoError(t, err)
+
+	csafDoc.Vulnerabilities[0].Flags = []csaf.Flag{
+		{Label: "vulnerable_code_not_present", ProductIDs: []string{"CSAFPID-0001"}},
+	}
+	csafDoc.Vulnerabilities[0].ProductStatus["known_affected"] = []string{"CSAFPID-0002"}
+	csafDoc.Vulnerabilities[0].ProductStatus["first_fixed"] = []string{"CSAFPID-0003"}
+	csafDoc.Vulnerabilities[0].ProductStatus["recommended"] = []string{"CSAFPID-0003"}
+	csafDoc.Vulnerabilities[0].Remediations = []csaf.RemediationData{
+		{Category: "vendor_fix", Details: "Update to 4.3", URL: "https://example.com/fix", ProductIDs: []string{"CSAFPID-0002"}},
+		{Category: "workaround", Details: "Disable logging", ProductIDs: []string{"CSAFPID-0002"}},
+	}
+	csafDoc.Vulnerabilities[0].Scores = []csaf.Score{
+		{
+			CVSSV3: csaf.CVSSV3{
+				Version: "3.1", VectorString: "CVSS:3.1/AV:N/AC:H/PR:L/UI:N/S:C/C:L/I:L/A:L",
+				BaseScore: 6.0, BaseSeverity: "MEDIUM",
+			},
+			CVSSV2:     csaf.CVSSV2{Version: "2.0", VectorString: "AV:N/AC:M/Au:N/C:P/I:P/A:P", BaseScore: 6.8},
+			ProductIDs: []string{"CSAFPID-0001"},
+		},
+		{
+			CVSSV3: csaf.CVSSV3{
+				Version: "3.1", VectorString: "CVSS:3.1/AV:N/AC:H/PR:L/UI:N/S:C/C:L/I:L/A:L",
+				BaseScore: 6.0, BaseSeverity: "MEDIUM",
+			},
+			ProductIDs: []string{"CSAFPID-0002"},
+		},
+	}
+
+	doc, err := FromCSAF(csafDoc)
+	require.NoError(t, err)
+	require.Equal(t, "2022-EVD-UC-01-NA-001", doc.ID)
+	require.Equal(t, "Example Company", doc.Author)
+	require.Equal(t, "en", doc.Lang)
+	require.Len(t, doc.Statements, 3)
+
+	// Statements are sorted by status category
+	fixed := doc.Statements[0]
+	require.Equal(t, StatusFixed, fixed.Status)
+	require.Equal(t, "CSAFPID-0003", fixed.Products[0].ID)
+
+	affected := doc.Statements[1]
+	require.Equal(t, StatusAffected, affected.Status)
+	require.Equal(t, "vendor_fix: Update to 4.3 (https://example.com/fix)\nworkaround: Disable logging", affected.ActionStatement)
+	require.Equal(t, []Remediation{
+		{Category: RemediationVendorFix, Details: "Update to 4.3", URL: "https://example.com/fix"},
+		{Category: RemediationWorkaround, Details: "Disable logging"},
+	}, affected.Remediations)
+
+	notAffected := doc.Statements[2]
+	require.Equal(t, StatusNotAffected, notAffected.Status)
+	require.Equal(t, VulnerableCodeNotPresent, notAffected.Justification)
+	require.Equal(t, "Class with vulnerable code was removed before shipping.", notAffected.ImpactStatement)
+	require.Equal(t, "pkg:golang/github.com/go-homedir@v1.2.0", notAffected.Products[0].Identifiers[PURL])
+	require.Contains(t, notAffected.Vulnerability.Description, "nginx 0.7.64")
+	require.NotEmpty(t, notAffected.References)
+	require.NotEmpty(t, notAffected.Credits)
+	require.Equal(t, []Rating{
+		{Method: RatingCVSSv31, Vector: "CVSS:3.1/AV:N/AC:H/PR:L/UI:N/S:C/C:L/I:L/A:L", Score: 6.0, Severity: SeverityMedium},
+		{Method: RatingCVSSv2, Vector: "AV:N/AC:M/Au:N/C:P/I:P/A:P", Score: 6.8, Severity: SeverityMedium},
+	}, notAffected.Vulnerability.Ratings)
+
+	for i := range doc.Statements {
+		require.NoError(t, doc.Statements[i].Validate())
+	}
+
+	csafDoc.Vulnerabilities[0].ProductStatus["bogus"] = []string{"CSAFPID-0001"}
+	_, err = FromCSAF(csafDoc)
+	require.Error(t, err)
+
+	_, err = FromCSAF(nil)
+	require.Error(t, err)
+}
+
+func TestToCSAF(t *testing.T) {
+	ts := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+	later := ts.Add(24 * time.Hour)
+	doc := New()
+	doc.ID = "https://example.com/vex/2023-001"
+	doc.Author = "Example Company"
+	doc.Timestamp = &ts
+	doc.Version = 2
+	doc.Statements = []Statement{
+		{
+			Vulnerability: Vulnerability{
+				Name:        "CVE-2023-1234",
+				Description: "Heap overflow",
+				Aliases:     []VulnerabilityID{"GHSA-aaaa-bbbb-cccc"},
+				Ratings: []Rating{
+					{Vector: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", Score: 9.8},
+					{Method: RatingCVSSv4, Vector: "CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N", Score: 9.3},
+				},
+			},
+			Products: []Product{
+				{Component: Component{ID: "pkg:oci/app@sha256%3A1234"}, Subcomponents: []Subcomponent{
+					{Component: Component{ID: "pkg:golang/example.com/lib@v1.0.0"}},
+				}},
+			},
+			Status:          StatusNotAffected,
+			Justification:   VulnerableCodeNotInExecutePath,
+			ImpactStatement: "The vulnerable function is never called",
+			Timestamp:       &ts,
+		},
+		{
+			Vulnerability: Vulnerability{Name: "CVE-2023-1234", Ratings: []Rating{
+				{Method: RatingCVSSv31, Vector: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", Score: 9.8},
+			}},
+			Products:  []Product{{Component: Component{ID: "pkg:apk/wolfi/lib@1.0.0-r0"}}},
+			Status:    StatusAffected,
+			Timestamp: &later,
+		},
+		{
+			Vulnerability: Vulnerability{Name: "GHSA-dddd-eeee-ffff"},
+			Products:      []Product{{Component: Component{ID: "pkg:apk/wolfi/lib@1.0.0-r0"}}},
+			Status:        StatusFixed,
+			Timestamp:     &ts,
+		},
+	}
+
+	csafDoc, err := doc.ToCSAF()
+	require.NoError(t, err)
+	require.Equal(t, csaf.CategoryVEX, csafDoc.Document.Category)
+	require.Equal(t, "https://example.com", csafDoc.Document.Publisher.Namespace)
+	require.Equal(t, "2", csafDoc.Document.Tracking.Version)
+	require.Equal(t, doc.ID, csafDoc.Document.Tracking.ID)
+	require.NoError(t, csaf.Validate(csafDoc))
+
+	// Product tree
+	require.Len(t, csafDoc.ProductTree.Branches, 3)
+	require.Equal(t, "example.com/lib", csafDoc.ProductTree.Branches[1].Name)
+	require.Equal(t, "v1.0.0", csafDoc.ProductTree.Branches[1].Branches[0].Name)
+	require.Len(t, csafDoc.ProductTree.Relationships, 1)
+	relID := csafDoc.ProductTree.Relationships[0].FullProductName.ID
+
+	// Vulnerabilities
+	require.Len(t, csafDoc.Vulnerabilities, 2)
+	cv := csafDoc.Vulnerabilities[0]
+	require.Equal(t, "CVE-2023-1234", cv.CVE)
+	require.Equal(t, []csaf.TrackingID{{SystemName: "GHSA", Text: "GHSA-aaaa-bbbb-cccc"}}, cv.IDs)
+	require.Equal(t, map[string][]string{
+		"known_not_affected": {relID},
+		"known_affected":     {"pkg:apk/wolfi/lib@1.0.0-r0"},
+	}, cv.ProductStatus)
+	require.Equal(t, later, cv.ReleaseDate)
+	require.Equal(t, "description", cv.Notes[0].Category)
+	require.Equal(t, "none_available", cv.Remediations[0].Category)
+
+	// Scores are merged by vector, CVSS v4 is not supported by CSAF 2.0
+	require.Len(t, cv.Scores, 1)
+	require.Empty(t, cv.Scores[0].CVSSV2)
+	require.Equal(t, csaf.CVSSV3{
+		Version: "3.1", VectorString: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
+		BaseScore: 9.8, BaseSeverity: "CRITICAL",
+	}, cv.Scores[0].CVSSV3)
+	require.Equal(t, []string{relID, "pkg:apk/wolfi/lib@1.0.0-r0"}, cv.Scores[0].ProductIDs)
+	require.Empty(t, csafDoc.Vulnerabilities[1].Scores)
+	require.Empty(t, csafDoc.Vulnerabilities[1].CVE)
+	require.Equal(t, "summary", csafDoc.Vulnerabilities[1].Notes[0].Category)
+
+	// Empty products and restart data are not serialized
+	var b bytes.Buffer
+	require.NoError(t, csafDoc.ToJSON(&b))
+	raw := map[string]any{}
+	require.NoError(t, json.Unmarshal(b.Bytes(), &raw))
+	require.NotContains(t, raw["product_tree"], "product")
+	require.NotContains(t, b.String(), "restart_required")
+
+	// Converting back yields the same statuses
+	back, err := FromCSAF(csafDoc)
+	require.NoError(t, err)
+	statuses := map[Status]int{}
+	for i := range back.Statements {
+		statuses[back.Statements[i].Status]++
+		if back.Statements[i].Status == StatusNotAffected {
+			require.Equal(t, VulnerableCodeNotInExecutePath, back.Statements[i].Justification)
+			require.Equal(t, "The vulnerable function is never called", back.Statements[i].ImpactStatement)
+
+			// The subcomponent survives through the relationship
+			require.True(t, back.Statements[i].Matches("CVE-2023-1234", "pkg:oci/app@sha256%3A1234", []string{"pkg:golang/example.com/lib@v1.0.0"}))
+		}
+	}
+	require.Equal(t, map[Status]int{StatusNotAffected: 1, StatusAffected: 1, StatusFixed: 1}, statuses)
+
+	// Documents without a timestamp cannot be converted
+	doc.Timestamp = nil
+	_, err = doc.ToCSAF()
+	require.Error(t, err)
+}
+
+func TestToCSAFLargeDocument(t *testing.T) {
+	csafDoc, err := csaf.Open("../csaf/testdata/rhsa-2020_1358.json")
+	require.NoError(t, err)
+	doc, err := FromCSAF(csafDoc)
+	require.NoError(t, err)
+
+	// Subcomponents are recorded as relationships without walking the
+	// product tree for each one
+	start := time.Now()
+	converted, err := doc.ToCSAF()
+	require.NoError(t, err)
+	require.Less(t, time.Since(start), 10*time.Second)
+	require.Len(t, converted.ProductTree.Relationships, len(csafDoc.ProductTree.Relationships))
+}
+
+func TestToCSAFRemediations(t *testing.T) {
+	ts := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+	released := ts.Add(-24 * time.Hour)
+	due := ts.Add(30 * 24 * time.Hour)
+	doc := New()
+	doc.ID = "https://example.com/vex/2023-002"
+	doc.Timestamp = &ts
+	doc.WithAuthor(Author{Name: "Example Company", URI: "https://security.example.org/advisories"}, "").
+		WithTooling(Tooling{Name: "vexctl", Version: "v0.2.5"})
+	doc.Statements = []Statement{
+		{
+			Vulnerability:   Vulnerability{Name: "CVE-2023-1234"},
+			Products:        []Product{{Component: Component{ID: "pkg:apk/wolfi/lib@1.0.0-r0"}}},
+			Status:          StatusAffected,
+			ActionStatement: "Update or disable the feature",
+			Remediations: []Remediation{
+				{Category: RemediationVendorFix, Details: "Update to 1.0.1", URL: "https://example.com/1.0.1", Date: &released},
+				{Category: RemediationWorkaround, DueDate: &due},
+			},
+		},
+	}
+
+	csafDoc, err := doc.ToCSAF()
+	require.NoError(t, err)
+	require.Equal(t, "Example Company", csafDoc.Document.Publisher.Name)
+	require.Equal(t, "https://security.example.org", csafDoc.Document.Publisher.Namespace)
+	require.Equal(t, &csaf.Generator{Engine: csaf.Engine{Name: "vexctl", Version: "v0.2.5"}}, csafDoc.Document.Tracking.Generator)
+	require.Len(t, csafDoc.Vulnerabilities, 1)
+	require.Equal(t, []csaf.RemediationData{
+		{
+			Category: "vendor_fix", Date: released, Details: "Update to 1.0.1",
+			URL: "https://example.com/1.0.1", ProductIDs: []string{"pkg:apk/wolfi/lib@1.0.0-r0"},
+		},
+		{
+			Category: "workaround", Date: ts, Details: "Update or disable the feature",
+			ProductIDs: []string{"pkg:apk/wolfi/lib@1.0.0-r0"},
+		},
+	}, csafDoc.Vulnerabilities[0].Remediations)
+
+	back, err := FromCSAF(csafDoc)
+	require.NoError(t, err)
+	require.Equal(t, "vexctl@v0.2.5", back.Tooling)
+	require.Len(t, back.Statements, 1)
+	require.Len(t, back.Statements[0].Remediations, 2)
+	require.Equal(t, RemediationVendorFix, back.Statements[0].Remediations[0].Category)
+	require.Equal(t, released, *back.Statements[0].Remediations[0].Date)
+	require.NoError(t, back.Statements[0].Validate())
+}
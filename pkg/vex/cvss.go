@@ -0,0 +1,256 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// RatingMethod is the scoring method of a severity rating. The values are
+// the ones used by CycloneDX.
+type RatingMethod string
+
+const (
+	RatingCVSSv2  RatingMethod = "CVSSv2"
+	RatingCVSSv3  RatingMethod = "CVSSv3"
+	RatingCVSSv31 RatingMethod = "CVSSv31"
+	RatingCVSSv4  RatingMethod = "CVSSv4"
+	RatingOther   RatingMethod = "other"
+)
+
+// Severity levels of the ratings, as defined in the CVSS specifications.
+const (
+	SeverityNone     = "none"
+	SeverityLow      = "low"
+	SeverityMedium   = "medium"
+	SeverityHigh     = "high"
+	SeverityCritical = "critical"
+)
+
+// Rating is a severity rating of a vulnerability.
+type Rating struct {
+	// Method is the scoring method used to rate the vulnerability.
+	Method RatingMethod `json:"method,omitempty"`
+
+	// Vector is the CVSS vector string of the rating.
+	Vector string `json:"vector,omitempty"`
+
+	// Score is the numerical score of the rating.
+	Score float64 `json:"score,omitempty"`
+
+	// Severity is the textual severity of the rating, eg "high".
+	Severity string `json:"severity,omitempty"`
+
+	// Source is the name of the entity that issued the rating.
+	Source string `json:"source,omitempty"`
+}
+
+// Validate checks the rating data. If the rating has a vector, it must be a
+// valid CVSS vector of the rating method.
+func (r *Rating) Validate() error {
+	if r.Score < 0 || r.Score > 10 {
+		return fmt.Errorf("score %.1f out of range", r.Score)
+	}
+
+	switch r.Severity {
+	case "", SeverityNone, SeverityLow, SeverityMedium, SeverityHigh, SeverityCritical:
+	default:
+		return fmt.Errorf("invalid severity %q", r.Severity)
+	}
+
+	if r.Vector == "" {
+		return nil
+	}
+	cvss, err := ParseCVSS(r.Vector)
+	if err != nil {
+		return fmt.Errorf("invalid vector: %w", err)
+	}
+	if r.Method != "" && r.Method != cvss.Method() {
+		return fmt.Errorf("vector %q does not match the rating method %s", r.Vector, r.Method)
+	}
+	return nil
+}
+
+// CVSS is a parsed CVSS vector string.
+type CVSS struct {
+	// Version is the CVSS version of the vector: 2.0, 3.0, 3.1 or 4.0.
+	Version string
+
+	// Metrics maps the metric abbreviations in the vector to their values.
+	Metrics map[string]string
+
+	// order records the order of the metrics in the vector.
+	order []string
+}
+
+// cvssMetrics lists the allowed values of the metrics of each CVSS version
+// and which of them are base metrics, required in all vectors.
+var cvssMetrics = map[string]struct {
+	base   []string
+	values map[string][]string
+}{
+	"2.0": {
+		base: []string{"AV", "AC", "Au", "C", "I", "A"},
+		values: map[string][]string{
+			"AV": {"L", "A", "N"}, "AC": {"H", "M", "L"}, "Au": {"M", "S", "N"},
+			"C": {"N", "P", "C"}, "I": {"N", "P", "C"}, "A": {"N", "P", "C"},
+			"E": {"U", "POC", "F", "H", "ND"}, "RL": {"OF", "TF", "W", "U", "ND"},
+			"RC": {"UC", "UR", "C", "ND"}, "CDP": {"N", "L", "LM", "MH", "H", "ND"},
+			"TD": {"N", "L", "M", "H", "ND"},
+			"CR": {"L", "M", "H", "ND"}, "IR": {"L", "M", "H", "ND"}, "AR": {"L", "M", "H", "ND"},
+		},
+	},
+	"3.x": {
+		base: []string{"AV", "AC", "PR", "UI", "S", "C", "I", "A"},
+		values: map[string][]string{
+			"AV": {"N", "A", "L", "P"}, "AC": {"L", "H"}, "PR": {"N", "L", "H"},
+			"UI": {"N", "R"}, "S": {"U", "C"},
+			"C": {"H", "L", "N"}, "I": {"H", "L", "N"}, "A": {"H", "L", "N"},
+			"E": {"X", "U", "P", "F", "H"}, "RL": {"X", "O", "T", "W", "U"},
+			"RC": {"X", "U", "R", "C"},
+			"CR": {"X", "L", "M", "H"}, "IR": {"X", "L", "M", "H"}, "AR": {"X", "L", "M", "H"},
+			"MAV": {"X", "N", "A", "L", "P"}, "MAC": {"X", "L", "H"}, "MPR": {"X", "N", "L", "H"},
+			"MUI": {"X", "N", "R"}, "MS": {"X", "U", "C"},
+			"MC": {"X", "N", "L", "H"}, "MI": {"X", "N", "L", "H"}, "MA": {"X", "N", "L", "H"},
+		},
+	},
+	"4.0": {
+		base: []string{"AV", "AC", "AT", "PR", "UI", "VC", "VI", "VA", "SC", "SI", "SA"},
+		values: map[string][]string{
+			"AV": {"N", "A", "L", "P"}, "AC": {"L", "H"}, "AT": {"N", "P"},
+			"PR": {"N", "L", "H"}, "UI": {"N", "P", "A"},
+			"VC": {"H", "L", "N"}, "VI": {"H", "L", "N"}, "VA": {"H", "L", "N"},
+			"SC": {"H", "L", "N"}, "SI": {"H", "L", "N"}, "SA": {"H", "L", "N"},
+			"E":  {"X", "A", "P", "U"},
+			"CR": {"X", "H", "M", "L"}, "IR": {"X", "H", "M", "L"}, "AR": {"X", "H", "M", "L"},
+			"MAV": {"X", "N", "A", "L", "P"}, "MAC": {"X", "L", "H"}, "MAT": {"X", "N", "P"},
+			"MPR": {"X", "N", "L", "H"}, "MUI": {"X", "N", "P", "A"},
+			"MVC": {"X", "H", "L", "N"}, "MVI": {"X", "H", "L", "N"}, "MVA": {"X", "H", "L", "N"},
+			"MSC": {"X", "H", "L", "N"}, "MSI": {"X", "S", "H", "L", "N"}, "MSA": {"X", "S", "H", "L", "N"},
+			"S": {"X", "N", "P"}, "AU": {"X", "N", "Y"}, "R": {"X", "A", "U", "I"},
+			"V": {"X", "D", "C"}, "RE": {"X", "L", "M", "H"},
+			"U": {"X", "Clear", "Green", "Amber", "Red"},
+		},
+	},
+}
+
+// ParseCVSS parses and validates a CVSS vector string. Vectors starting
+// with a CVSS:3.0, CVSS:3.1 or CVSS:4.0 prefix are parsed as that version,
+// vectors without a prefix are parsed as CVSS v2. All the base metrics of
+// the version are required, metrics can only appear once and their values
+// must be valid.
+func ParseCVSS(vector string) (*CVSS, error) {
+	if vector == "" {
+		return nil, errors.New("vector string is empty")
+	}
+
+	cvss := &CVSS{Version: "2.0", Metrics: map[string]string{}}
+	parts := strings.Split(vector, "/")
+	if version, ok := strings.CutPrefix(parts[0], "CVSS:"); ok {
+		switch version {
+		case "3.0", "3.1", "4.0":
+			cvss.Version = version
+		default:
+			return nil, fmt.Errorf("unsupported CVSS version %q", version)
+		}
+		parts = parts[1:]
+	}
+
+	defs := cvssMetrics[cvss.Version]
+	if strings.HasPrefix(cvss.Version, "3.") {
+		defs = cvssMetrics["3.x"]
+	}
+
+	for _, part := range parts {
+		metric, value, ok := strings.Cut(part, ":")
+		if !ok || metric == "" || value == "" {
+			return nil, fmt.Errorf("invalid metric %q", part)
+		}
+		allowed, ok := defs.values[metric]
+		if !ok {
+			return nil, fmt.Errorf("unknown CVSS v%s metric %q", cvss.Version, metric)
+		}
+		if _, ok := cvss.Metrics[metric]; ok {
+			return nil, fmt.Errorf("metric %q is defined more than once", metric)
+		}
+		if !slices.Contains(allowed, value) {
+			return nil, fmt.Errorf("invalid value %q for metric %s", value, metric)
+		}
+		cvss.Metrics[metric] = value
+		cvss.order = append(cvss.order, metric)
+	}
+
+	for _, metric := range defs.base {
+		if _, ok := cvss.Metrics[metric]; !ok {
+			return nil, fmt.Errorf("missing base metric %s", metric)
+		}
+	}
+	return cvss, nil
+}
+
+// ValidateCVSS returns an error if the vector string is not a valid CVSS
+// vector.
+func ValidateCVSS(vector string) error {
+	_, err := ParseCVSS(vector)
+	return err
+}
+
+// Method returns the rating method of the vector's CVSS version.
+func (c *CVSS) Method() RatingMethod {
+	switch c.Version {
+	case "2.0":
+		return RatingCVSSv2
+	case "3.0":
+		return RatingCVSSv3
+	case "3.1":
+		return RatingCVSSv31
+	case "4.0":
+		return RatingCVSSv4
+	default:
+		return RatingOther
+	}
+}
+
+// String returns the vector string.
+func (c *CVSS) String() string {
+	parts := []string{}
+	if c.Version != "2.0" {
+		parts = append(parts, "CVSS:"+c.Version)
+	}
+	for _, metric := range c.order {
+		parts = append(parts, metric+":"+c.Metrics[metric])
+	}
+	return strings.Join(parts, "/")
+}
+
+// SeverityFromScore returns the severity of a score using the qualitative
+// rating scale of the rating method. CVSS v2 does not define the none and
+// critical severities.
+func SeverityFromScore(method RatingMethod, score float64) string {
+	if method == RatingCVSSv2 {
+		switch {
+		case score >= 7:
+			return SeverityHigh
+		case score >= 4:
+			return SeverityMedium
+		default:
+			return SeverityLow
+		}
+	}
+	switch {
+	case score >= 9:
+		return SeverityCritical
+	case score >= 7:
+		return SeverityHigh
+	case score >= 4:
+		return SeverityMedium
+	case score > 0:
+		return SeverityLow
+	default:
+		return SeverityNone
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCVSS(t *testing.T) {
+	for m, tc := range map[string]struct {
+		vector    string
+		version   string
+		method    RatingMethod
+		metrics   int
+		shouldErr bool
+	}{
+		"v2":                 {"AV:N/AC:L/Au:N/C:P/I:P/A:P", "2.0", RatingCVSSv2, 6, false},
+		"v2 temporal":        {"AV:N/AC:L/Au:N/C:P/I:P/A:P/E:POC/RL:OF/RC:C", "2.0", RatingCVSSv2, 9, false},
+		"v3.0":               {"CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", "3.0", RatingCVSSv3, 8, false},
+		"v3.1":               {"CVSS:3.1/AV:N/AC:H/PR:L/UI:N/S:C/C:L/I:L/A:L", "3.1", RatingCVSSv31, 8, false},
+		"v3.1 environmental": {"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H/E:P/MAV:L", "3.1", RatingCVSSv31, 10, false},
+		"v4.0":               {"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N", "4.0", RatingCVSSv4, 11, false},
+		"v4.0 supplemental":  {"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N/AU:Y/U:Red", "4.0", RatingCVSSv4, 13, false},
+		"empty":              {"", "", "", 0, true},
+		"unknown version":    {"CVSS:5.0/AV:N", "", "", 0, true},
+		"missing metric":     {"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H", "", "", 0, true},
+		"invalid value":      {"CVSS:3.1/AV:X/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", "", "", 0, true},
+		"unknown metric":     {"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H/VC:H", "", "", 0, true},
+		"duplicate metric":   {"CVSS:3.1/AV:N/AV:L/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", "", "", 0, true},
+		"malformed":          {"CVSS:3.1/AV:N/AC", "", "", 0, true},
+		"v3 vector as v2":    {"AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", "", "", 0, true},
+	} {
+		t.Run(m, func(t *testing.T) {
+			cvss, err := ParseCVSS(tc.vector)
+			if tc.shouldErr {
+				require.Error(t, err)
+				require.Error(t, ValidateCVSS(tc.vector))
+				return
+			}
+			require.NoError(t, err)
+			require.NoError(t, ValidateCVSS(tc.vector))
+			require.Equal(t, tc.version, cvss.Version)
+			require.Equal(t, tc.method, cvss.Method())
+			require.Len(t, cvss.Metrics, tc.metrics)
+			require.Equal(t, tc.vector, cvss.String())
+		})
+	}
+}
+
+func TestRatingValidate(t *testing.T) {
+	for m, tc := range map[string]struct {
+		rating    Rating
+		shouldErr bool
+	}{
+		"valid":           {Rating{Method: RatingCVSSv31, Vector: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", Score: 9.8, Severity: SeverityCritical}, false},
+		"no method":       {Rating{Vector: "AV:N/AC:L/Au:N/C:P/I:P/A:P", Score: 7.5}, false},
+		"severity only":   {Rating{Method: RatingOther, Severity: SeverityLow}, false},
+		"method mismatch": {Rating{Method: RatingCVSSv3, Vector: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"}, true},
+		"invalid vector":  {Rating{Vector: "CVSS:3.1/AV:N"}, true},
+		"score too high":  {Rating{Score: 10.1}, true},
+		"negative score":  {Rating{Score: -1}, true},
+		"bad severity":    {Rating{Severity: "HIGH"}, true},
+	} {
+		t.Run(m, func(t *testing.T) {
+			err := tc.rating.Validate()
+			if tc.shouldErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestSeverityFromScore(t *testing.T) {
+	for m, tc := range map[string]struct {
+		method   RatingMethod
+		score    float64
+		expected string
+	}{
+		"v3 none":     {RatingCVSSv31, 0, SeverityNone},
+		"v3 low":      {RatingCVSSv31, 3.9, SeverityLow},
+		"v3 medium":   {RatingCVSSv3, 4.0, SeverityMedium},
+		"v3 high":     {RatingCVSSv31, 8.9, SeverityHigh},
+		"v4 critical": {RatingCVSSv4, 9.0, SeverityCritical},
+		"v2 low":      {RatingCVSSv2, 0, SeverityLow},
+		"v2 medium":   {RatingCVSSv2, 6.9, SeverityMedium},
+		"v2 high":     {RatingCVSSv2, 10, SeverityHigh},
+	} {
+		t.Run(m, func(t *testing.T) {
+			require.Equal(t, tc.expected, SeverityFromScore(tc.method, tc.score))
+		})
+	}
+}
+
+func TestVulnerabilityRatingsJSON(t *testing.T) {
+	vuln := Vulnerability{
+		Name: "CVE-2023-1234",
+		Ratings: []Rating{
+			{Method: RatingCVSSv31, Vector: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", Score: 9.8, Severity: SeverityCritical, Source: "NVD"},
+		},
+	}
+	data, err := json.Marshal(&vuln)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"name":"CVE-2023-1234","openvex.dev/ratings":[{"method":"CVSSv31","vector":"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H","score":9.8,"severity":"critical","source":"NVD"}]}`, string(data))
+
+	parsed := Vulnerability{}
+	require.NoError(t, json.Unmarshal(data, &parsed))
+	require.Equal(t, vuln, parsed)
+
+	// Copies do not share the ratings
+	cp := vuln.DeepCopy()
+	cp.Ratings[0].Score = 1
+	require.InDelta(t, 9.8, vuln.Ratings[0].Score, 0.01)
+
+	data, err = json.Marshal(&Vulnerability{Name: "CVE-2023-1234"})
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "ratings")
+}
@@ -0,0 +1,340 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/package-url/packageurl-go"
+
+	"github.com/openvex/go-vex/pkg/cyclonedx"
+)
+
+// cycloneDXAlgorithms maps the hash algorithms to their CycloneDX names.
+// Algorithms not supported by CycloneDX are not listed.
+var cycloneDXAlgorithms = map[Algorithm]string{
+	MD5:        "MD5",
+	SHA1:       "SHA-1",
+	SHA256:     "SHA-256",
+	SHA384:     "SHA-384",
+	SHA512:     "SHA-512",
+	SHA3256:    "SHA3-256",
+	SHA3384:    "SHA3-384",
+	SHA3512:    "SHA3-512",
+	BLAKE2B256: "BLAKE2b-256",
+	BLAKE2B512: "BLAKE2b-512",
+	BLAKE3:     "BLAKE3",
+}
+
+// ToCycloneDX converts the document into a CycloneDX 1.5 BOM. Each statement
+// is written as a vulnerability with its analysis populated from the
+// statement status, justification and texts. Products and subcomponents are
+// listed as BOM components referenced from the vulnerabilities by their
+// bom-ref. If the statement products list subcomponents, the subcomponents
+// are recorded as the affected components. The name and URI of the document
+// author are written as the BOM supplier.
+//
+// The original CycloneDX analysis data preserved in the annotations of
+// imported statements is used when it is consistent with the statement.
+func (vexDoc *VEX) ToCycloneDX() (*cyclonedx.BOM, error) {
+	bom := &cyclonedx.BOM{
+		BOMFormat:       "CycloneDX",
+		SpecVersion:     cyclonedx.SpecVersion,
+		SerialNumber:    cycloneDXSerialNumber(vexDoc.ID),
+		Version:         vexDoc.Version,
+		Metadata:        cyclonedx.Metadata{Timestamp: vexDoc.Timestamp},
+		Components:      []cyclonedx.Component{},
+		Vulnerabilities: []cyclonedx.Vulnerability{},
+	}
+	if bom.Version < 1 {
+		bom.Version = 1
+	}
+	if vexDoc.Author != "" {
+		author := vexDoc.AuthorDetails()
+		bom.Metadata.Supplier = &cyclonedx.Entity{Name: author.Name}
+		if author.Name == "" {
+			bom.Metadata.Supplier.Name = vexDoc.Author
+		}
+		if author.URI != "" {
+			bom.Metadata.Supplier.URL = []string{author.URI}
+		}
+	}
+
+	refs := map[string]struct{}{}
+	addComponent := func(c *Component) (string, error) {
+		ref := c.ID
+		if ref == "" {
+			ref = c.Identifiers[PURL]
+		}
+		if ref == "" {
+			return "", errors.New("component has no identifier usable as bom-ref")
+		}
+		if _, ok := refs[ref]; !ok {
+			refs[ref] = struct{}{}
+			bom.Components = append(bom.Components, componentToCycloneDX(ref, c))
+		}
+		return ref, nil
+	}
+
+	for i := range vexDoc.Statements {
+		stmt := &vexDoc.Statements[i]
+		if !stmt.Status.Valid() {
+			return nil, fmt.Errorf("statement #%d has invalid status %q", i, stmt.Status)
+		}
+
+		cv := cyclonedx.Vulnerability{
+			BOMRef:      fmt.Sprintf("vulnerability-%d", i+1),
+			ID:          string(stmt.Vulnerability.Name),
+			Description: stmt.Vulnerability.Description,
+			Updated:     stmt.Timestamp,
+			Analysis:    analysisToCycloneDX(stmt),
+		}
+		if cv.ID == "" {
+			cv.ID = stmt.Vulnerability.ID
+		}
+		if cv.Updated == nil {
+			cv.Updated = vexDoc.Timestamp
+		}
+		if strings.HasPrefix(stmt.Vulnerability.ID, "http") {
+			cv.Source = &cyclonedx.Source{URL: stmt.Vulnerability.ID}
+		}
+		for _, alias := range stmt.Vulnerability.Aliases {
+			cv.References = append(cv.References, cyclonedx.Reference{ID: string(alias)})
+		}
+		cv.Ratings = ratingsToCycloneDX(stmt.Vulnerability.Ratings)
+
+		for j := range stmt.Products {
+			p := &stmt.Products[j]
+			ref, err := addComponent(&p.Component)
+			if err != nil {
+				return nil, fmt.Errorf("statement #%d product: %w", i, err)
+			}
+			if len(p.Subcomponents) == 0 {
+				cv.Affects = append(cv.Affects, cyclonedx.Affect{Ref: ref})
+				continue
+			}
+			for k := range p.Subcomponents {
+				ref, err := addComponent(&p.Subcomponents[k].Component)
+				if err != nil {
+					return nil, fmt.Errorf("statement #%d subcomponent: %w", i, err)
+				}
+				cv.Affects = append(cv.Affects, cyclonedx.Affect{Ref: ref})
+			}
+		}
+
+		bom.Vulnerabilities = append(bom.Vulnerabilities, cv)
+	}
+
+	return bom, nil
+}
+
+// ratingsToCycloneDX returns the CycloneDX ratings of a vulnerability.
+func ratingsToCycloneDX(ratings []Rating) []cyclonedx.Rating {
+	var ret []cyclonedx.Rating
+	for i := range ratings {
+		r := cyclonedx.Rating{
+			Score:    ratings[i].Score,
+			Severity: ratings[i].Severity,
+			Method:   string(ratings[i].Method),
+			Vector:   ratings[i].Vector,
+		}
+		if ratings[i].Source != "" {
+			r.Source = &cyclonedx.Source{Name: ratings[i].Source}
+		}
+		ret = append(ret, r)
+	}
+	return ret
+}
+
+// ratingsFromCycloneDX returns the ratings of a CycloneDX vulnerability.
+// Severities not defined by CVSS, such as info or unknown, are dropped.
+func ratingsFromCycloneDX(ratings []cyclonedx.Rating) []Rating {
+	var ret []Rating
+	for i := range ratings {
+		r := Rating{
+			Method: RatingMethod(ratings[i].Method),
+			Vector: ratings[i].Vector,
+			Score:  ratings[i].Score,
+		}
+		switch severity := strings.ToLower(ratings[i].Severity); severity {
+		case SeverityNone, SeverityLow, SeverityMedium, SeverityHigh, SeverityCritical:
+			r.Severity = severity
+		}
+		if ratings[i].Source != nil {
+			r.Source = ratings[i].Source.Name
+		}
+		ret = append(ret, r)
+	}
+	return ret
+}
+
+// analysisToCycloneDX returns the CycloneDX analysis of the statement.
+func analysisToCycloneDX(stmt *Statement) *cyclonedx.Analysis {
+	a := &cyclonedx.Analysis{LastUpdated: stmt.LastUpdated}
+	if a.LastUpdated == nil {
+		a.LastUpdated = stmt.Timestamp
+	}
+
+	// Prefer the original state if the statement was imported from CycloneDX
+	// and its status has not changed since.
+	if state := stmt.Annotations[AnnotationCycloneDXState]; state != "" && StatusFromCycloneDX(state) == stmt.Status {
+		a.State = state
+	} else {
+		a.State = statusToCycloneDX(stmt.Status)
+	}
+
+	switch stmt.Status {
+	case StatusNotAffected:
+		if j := stmt.Annotations[AnnotationCycloneDXJustification]; j != "" && JustificationFromCycloneDX(j) == stmt.Justification {
+			a.Justification = j
+		} else {
+			a.Justification = justificationToCycloneDX(stmt.Justification)
+		}
+		a.Detail = stmt.ImpactStatement
+	case StatusAffected:
+		if stmt.ActionStatement != NoActionStatementMsg {
+			a.Detail = stmt.ActionStatement
+		}
+	default:
+		a.Detail = stmt.StatusNotes
+	}
+
+	if response := stmt.Annotations[AnnotationCycloneDXResponse]; response != "" {
+		a.Response = strings.Split(response, ",")
+	} else if stmt.Status == StatusAffected {
+		a.Response = responsesToCycloneDX(stmt.Remediations)
+	}
+	return a
+}
+
+// responsesToCycloneDX returns the CycloneDX analysis responses matching
+// the categories of the remediations.
+func responsesToCycloneDX(remediations []Remediation) []string {
+	var responses []string
+	for i := range remediations {
+		var response string
+		switch remediations[i].Category {
+		case RemediationVendorFix:
+			response = "update"
+		case RemediationWorkaround, RemediationMitigation:
+			response = "workaround_available"
+		case RemediationNoFixPlanned:
+			response = "will_not_fix"
+		case RemediationNoneAvailable:
+			response = "can_not_fix"
+		}
+		if response != "" && !slices.Contains(responses, response) {
+			responses = append(responses, response)
+		}
+	}
+	return responses
+}
+
+// remediationsFromCycloneDX returns the remediations matching CycloneDX
+// analysis responses. Rollbacks have no remediation category and are not
+// converted.
+func remediationsFromCycloneDX(responses []string) []Remediation {
+	var remediations []Remediation
+	for _, response := range responses {
+		var category RemediationCategory
+		switch response {
+		case "update":
+			category = RemediationVendorFix
+		case "workaround_available":
+			category = RemediationWorkaround
+		case "will_not_fix":
+			category = RemediationNoFixPlanned
+		case "can_not_fix":
+			category = RemediationNoneAvailable
+		default:
+			continue
+		}
+		remediations = append(remediations, Remediation{Category: category})
+	}
+	return remediations
+}
+
+// statusToCycloneDX returns the CycloneDX analysis state of a status.
+func statusToCycloneDX(s Status) string {
+	switch s {
+	case StatusNotAffected:
+		return "not_affected"
+	case StatusAffected:
+		return "exploitable"
+	case StatusFixed:
+		return "resolved"
+	default:
+		return "in_triage"
+	}
+}
+
+// justificationToCycloneDX returns the CycloneDX analysis justification
+// closest to a justification.
+func justificationToCycloneDX(j Justification) string {
+	switch j {
+	case ComponentNotPresent, VulnerableCodeNotPresent:
+		return "code_not_present"
+	case VulnerableCodeNotInExecutePath:
+		return "code_not_reachable"
+	case VulnerableCodeCannotBeControlledByAdversary:
+		return "requires_environment"
+	case InlineMitigationsAlreadyExist:
+		return "protected_by_mitigating_control"
+	default:
+		return ""
+	}
+}
+
+// componentToCycloneDX converts a component into a CycloneDX component with
+// the specified bom-ref. The name and version are read from the purl when
+// the component has one.
+func componentToCycloneDX(ref string, c *Component) cyclonedx.Component {
+	ret := cyclonedx.Component{
+		BOMRef: ref,
+		Type:   "library",
+		Name:   ref,
+		Purl:   c.Identifiers[PURL],
+	}
+	if ret.Purl == "" && strings.HasPrefix(c.ID, "pkg:") {
+		ret.Purl = c.ID
+	}
+	if p, err := packageurl.FromString(ret.Purl); err == nil && ret.Purl != "" {
+		ret.Name = p.Name
+		if p.Namespace != "" {
+			ret.Name = p.Namespace + "/" + p.Name
+		}
+		ret.Version = p.Version
+	}
+
+	if cpe := c.Identifiers[CPE23]; cpe != "" {
+		ret.CPE = cpe
+	} else {
+		ret.CPE = c.Identifiers[CPE22]
+	}
+
+	for _, algo := range Algorithms() {
+		name, ok := cycloneDXAlgorithms[Algorithm(algo)]
+		if h, found := c.Hashes[Algorithm(algo)]; ok && found {
+			ret.Hashes = append(ret.Hashes, cyclonedx.Hash{Algorithm: name, Content: string(h)})
+		}
+	}
+	return ret
+}
+
+// cycloneDXSerialNumber returns a deterministic urn:uuid serial number
+// derived from the document ID.
+func cycloneDXSerialNumber(id string) string {
+	if strings.HasPrefix(id, "urn:uuid:") {
+		return id
+	}
+	sum := sha256.Sum256([]byte(id))
+	// Set the version (5-style, name based) and variant bits
+	sum[6] = (sum[6] & 0x0f) | 0x50
+	sum[8] = (sum[8] & 0x3f) | 0x80
+	return fmt.Sprintf("urn:uuid:%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+}
@@ -0,0 +1,126 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/openvex/go-vex/pkg/cyclonedx"
+)
+
+func TestToCycloneDX(t *testing.T) {
+	ts := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+	doc := New()
+	doc.ID = "https://example.com/vex/1"
+	doc.Author = "Example Company <https://example.com>"
+	doc.Timestamp = &ts
+	doc.Statements = []Statement{
+		{
+			Vulnerability: Vulnerability{
+				ID:      "https://nvd.nist.gov/vuln/detail/CVE-2023-1234",
+				Name:    "CVE-2023-1234",
+				Aliases: []VulnerabilityID{"GHSA-xxxx-yyyy-zzzz"},
+				Ratings: []Rating{
+					{Method: RatingCVSSv31, Vector: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:H", Score: 7.5, Severity: SeverityHigh, Source: "NVD"},
+				},
+			},
+			Products: []Product{
+				{
+					Component: Component{
+						ID:     "pkg:oci/app@sha256:abc",
+						Hashes: map[Algorithm]Hash{SHA256: Hash(strings.Repeat("a", 64)), SHA3224: "ignored"},
+					},
+					Subcomponents: []Subcomponent{{Component: Component{ID: "pkg:golang/golang.org/x/net@v0.17.0"}}},
+				},
+			},
+			Status:          StatusNotAffected,
+			Justification:   VulnerableCodeNotInExecutePath,
+			ImpactStatement: "The vulnerable function is never called",
+		},
+		{
+			Vulnerability:   Vulnerability{Name: "CVE-2023-5678"},
+			Products:        []Product{{Component: Component{ID: "pkg:oci/app@sha256:abc"}}},
+			Status:          StatusAffected,
+			ActionStatement: "Update to 2.0",
+			Remediations: []Remediation{
+				{Category: RemediationVendorFix, Details: "Update to 2.0"},
+				{Category: RemediationWorkaround},
+				{Category: RemediationMitigation},
+			},
+		},
+	}
+
+	bom, err := doc.ToCycloneDX()
+	require.NoError(t, err)
+	require.Equal(t, "CycloneDX", bom.BOMFormat)
+	require.Equal(t, "1.5", bom.SpecVersion)
+	require.True(t, strings.HasPrefix(bom.SerialNumber, "urn:uuid:"))
+	require.Equal(t, &cyclonedx.Entity{Name: "Example Company", URL: []string{"https://example.com"}}, bom.Metadata.Supplier)
+
+	// Components are deduplicated
+	require.Len(t, bom.Components, 2)
+	require.Equal(t, "pkg:oci/app@sha256:abc", bom.Components[0].BOMRef)
+	require.Equal(t, "app", bom.Components[0].Name)
+	require.Equal(t, []cyclonedx.Hash{{Algorithm: "SHA-256", Content: strings.Repeat("a", 64)}}, bom.Components[0].Hashes)
+	require.Equal(t, "golang.org/x/net", bom.Components[1].Name)
+	require.Equal(t, "v0.17.0", bom.Components[1].Version)
+
+	require.Len(t, bom.Vulnerabilities, 2)
+	v := bom.Vulnerabilities[0]
+	require.Equal(t, "CVE-2023-1234", v.ID)
+	require.Equal(t, "https://nvd.nist.gov/vuln/detail/CVE-2023-1234", v.Source.URL)
+	require.Equal(t, "not_affected", v.Analysis.State)
+	require.Equal(t, "code_not_reachable", v.Analysis.Justification)
+	require.Equal(t, []cyclonedx.Affect{{Ref: "pkg:golang/golang.org/x/net@v0.17.0"}}, v.Affects)
+	require.Equal(t, []cyclonedx.Rating{{
+		Source: &cyclonedx.Source{Name: "NVD"}, Score: 7.5, Severity: "high",
+		Method: "CVSSv31", Vector: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:H",
+	}}, v.Ratings)
+	require.Empty(t, bom.Vulnerabilities[1].Ratings)
+	require.Equal(t, "exploitable", bom.Vulnerabilities[1].Analysis.State)
+	require.Equal(t, "Update to 2.0", bom.Vulnerabilities[1].Analysis.Detail)
+	require.Equal(t, []string{"update", "workaround_available"}, bom.Vulnerabilities[1].Analysis.Response)
+
+	// The BOM can be read back
+	var buf bytes.Buffer
+	require.NoError(t, bom.ToJSON(&buf))
+	imported, err := ParseCycloneDX(buf.Bytes())
+	require.NoError(t, err)
+	require.Equal(t, doc.Author, imported.Author)
+	require.Len(t, imported.Statements, 2)
+	require.Equal(t, StatusNotAffected, imported.Statements[0].Status)
+	require.Equal(t, VulnerableCodeNotInExecutePath, imported.Statements[0].Justification)
+	require.True(t, imported.Statements[0].Matches("CVE-2023-1234", "pkg:golang/golang.org/x/net@v0.17.0", nil))
+	require.Equal(t, doc.Statements[0].Vulnerability.Ratings, imported.Statements[0].Vulnerability.Ratings)
+	require.Equal(t, []Remediation{
+		{Category: RemediationVendorFix}, {Category: RemediationWorkaround},
+	}, imported.Statements[1].Remediations)
+
+	doc.Statements[0].Status = "bogus"
+	_, err = doc.ToCycloneDX()
+	require.Error(t, err)
+}
+
+func TestToCycloneDXRoundTrip(t *testing.T) {
+	doc, err := OpenCycloneDX("testdata/cyclonedx.json")
+	require.NoError(t, err)
+
+	bom, err := doc.ToCycloneDX()
+	require.NoError(t, err)
+	require.Equal(t, "urn:uuid:3e671687-395b-41f5-a30f-a58921a69b79", bom.SerialNumber)
+
+	original, err := cyclonedx.Open("testdata/cyclonedx.json")
+	require.NoError(t, err)
+	require.Len(t, bom.Vulnerabilities, len(original.Vulnerabilities))
+	for i := range original.Vulnerabilities {
+		require.Equal(t, original.Vulnerabilities[i].Analysis.State, bom.Vulnerabilities[i].Analysis.State)
+		require.Equal(t, original.Vulnerabilities[i].Analysis.Justification, bom.Vulnerabilities[i].Analysis.Justification)
+		require.Equal(t, original.Vulnerabilities[i].Analysis.Response, bom.Vulnerabilities[i].Analysis.Response)
+	}
+}
@@ -0,0 +1,285 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StatementChange is a statement whose contents changed between two
+// versions of a document.
+type StatementChange struct {
+	Old Statement
+	New Statement
+}
+
+// StatusChange is a change in the status of a vulnerability in a product
+// between two versions of a document. The status of each version is the one
+// set by its latest statement about the vulnerability and product.
+type StatusChange struct {
+	// Key names the vulnerability and product, eg "CVE-2023-1234 for
+	// purl:pkg:apk/wolfi/git@2.41.0-r0".
+	Key string
+
+	// Old and New are the statuses in each version, empty when the version
+	// has no statements about the vulnerability in the product.
+	Old Status
+	New Status
+}
+
+// MetadataChange is a document field whose value changed between two
+// versions of a document.
+type MetadataChange struct {
+	// Field is the JSON name of the field
+	Field string
+
+	// Old and New are the values of the field rendered as strings
+	Old string
+	New string
+}
+
+// DocumentDiff lists the changes between two documents. It is used to
+// review the result of an operation, for example a merge or an update of a
+// published document, before publishing it.
+type DocumentDiff struct {
+	// Metadata lists the document fields that changed
+	Metadata []MetadataChange
+
+	// StatusChanges lists the vulnerability and product pairs whose status
+	// changed, sorted by key.
+	StatusChanges []StatusChange
+
+	// Added are the statements only found in the new document
+	Added []Statement
+
+	// Removed are the statements only found in the old document
+	Removed []Statement
+
+	// Changed pairs the statements found in both documents whose contents
+	// differ.
+	Changed []StatementChange
+}
+
+// IsEmpty returns true if the documents have the same metadata and
+// statements.
+func (d *DocumentDiff) IsEmpty() bool {
+	return len(d.Metadata) == 0 && len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// String renders the diff for humans, one change per line.
+func (d *DocumentDiff) String() string {
+	if d.IsEmpty() {
+		return "no changes\n"
+	}
+
+	var b strings.Builder
+	if len(d.Metadata) > 0 {
+		b.WriteString("metadata:\n")
+		for _, c := range d.Metadata {
+			fmt.Fprintf(&b, "  %s: %q -> %q\n", c.Field, c.Old, c.New)
+		}
+	}
+	if len(d.StatusChanges) > 0 {
+		b.WriteString("status changes:\n")
+		for _, c := range d.StatusChanges {
+			fmt.Fprintf(&b, "  %s: %s -> %s\n", c.Key, statusOrNone(c.Old), statusOrNone(c.New))
+		}
+	}
+	if len(d.Added) > 0 {
+		b.WriteString("added statements:\n")
+		for i := range d.Added {
+			fmt.Fprintf(&b, "  + %s\n", d.Added[i].String())
+		}
+	}
+	if len(d.Removed) > 0 {
+		b.WriteString("removed statements:\n")
+		for i := range d.Removed {
+			fmt.Fprintf(&b, "  - %s\n", d.Removed[i].String())
+		}
+	}
+	if len(d.Changed) > 0 {
+		b.WriteString("changed statements:\n")
+		for i := range d.Changed {
+			fmt.Fprintf(&b, "  ~ %s\n    %s\n", d.Changed[i].Old.String(), d.Changed[i].New.String())
+		}
+	}
+	return b.String()
+}
+
+// statusOrNone renders an empty status as "none".
+func statusOrNone(s Status) string {
+	if s == "" {
+		return "none"
+	}
+	return string(s)
+}
+
+// Diff compares two documents. Statements are the same when their data is
+// equal, as in Delta. Statements with different data are paired as changed
+// when they share their ID or, for statements without an ID, when they are
+// about the same vulnerability and products. The rest are reported as added
+// or removed. The diff also lists the changes in the document fields and in
+// the status of each vulnerability and product. A nil oldDoc diffs as an
+// empty document.
+func Diff(oldDoc, newDoc *VEX) *DocumentDiff {
+	diff := &DocumentDiff{
+		Metadata:      diffMetadata(oldDoc, newDoc),
+		StatusChanges: diffStatuses(oldDoc, newDoc),
+		Added:         []Statement{},
+		Removed:       []Statement{},
+		Changed:       []StatementChange{},
+	}
+	var oldStatements []Statement
+	if oldDoc != nil {
+		oldStatements = oldDoc.Statements
+	}
+
+	// Index the old statements not matched yet
+	unmatched := make([]bool, len(oldStatements))
+	byData := map[string][]int{}
+	byKey := map[string][]int{}
+	for i := range oldStatements {
+		unmatched[i] = true
+		data, key := cstringFromStatement(&oldStatements[i]), diffKey(&oldStatements[i])
+		byData[data] = append(byData[data], i)
+		byKey[key] = append(byKey[key], i)
+	}
+	take := func(index map[string][]int, key string) (int, bool) {
+		for _, i := range index[key] {
+			if unmatched[i] {
+				unmatched[i] = false
+				return i, true
+			}
+		}
+		return 0, false
+	}
+
+	// Match identical statements first so they are not taken as changes
+	pending := []int{}
+	for i := range newDoc.Statements {
+		if _, ok := take(byData, cstringFromStatement(&newDoc.Statements[i])); !ok {
+			pending = append(pending, i)
+		}
+	}
+	for _, i := range pending {
+		if j, ok := take(byKey, diffKey(&newDoc.Statements[i])); ok {
+			diff.Changed = append(diff.Changed, StatementChange{Old: oldStatements[j], New: newDoc.Statements[i]})
+			continue
+		}
+		diff.Added = append(diff.Added, newDoc.Statements[i])
+	}
+	for i := range oldStatements {
+		if unmatched[i] {
+			diff.Removed = append(diff.Removed, oldStatements[i])
+		}
+	}
+	return diff
+}
+
+// diffKey returns the key pairing versions of a statement: its ID or, when
+// it has none, its vulnerability and the keys of its products.
+func diffKey(s *Statement) string {
+	if s.ID != "" {
+		return "id:" + s.ID
+	}
+	products := []string{}
+	for i := range s.Products {
+		products = append(products, s.Products[i].key().String())
+	}
+	sort.Strings(products)
+	return "vuln:" + string(s.Vulnerability.Name) + ":" + strings.Join(products, ",")
+}
+
+// diffMetadata returns the document fields that differ between the
+// documents.
+func diffMetadata(oldDoc, newDoc *VEX) []MetadataChange {
+	fields := func(doc *VEX) [][2]string {
+		if doc == nil {
+			doc = &VEX{}
+		}
+		formatTime := func(t *time.Time) string {
+			if t == nil {
+				return ""
+			}
+			return t.UTC().Format(time.RFC3339)
+		}
+		version := ""
+		if doc.Version != 0 {
+			version = strconv.Itoa(doc.Version)
+		}
+		return [][2]string{
+			{"@context", doc.Context},
+			{"@id", doc.ID},
+			{"author", doc.Author},
+			{"role", doc.AuthorRole},
+			{"timestamp", formatTime(doc.Timestamp)},
+			{"last_updated", formatTime(doc.LastUpdated)},
+			{"version", version},
+			{"tooling", doc.Tooling},
+			{"supplier", doc.Supplier},
+			{"openvex.dev/previous_digest", doc.PreviousDigest},
+			{"openvex.dev/lang", doc.Lang},
+		}
+	}
+
+	ret := []MetadataChange{}
+	newFields := fields(newDoc)
+	for i, f := range fields(oldDoc) {
+		if f[1] != newFields[i][1] {
+			ret = append(ret, MetadataChange{Field: f[0], Old: f[1], New: newFields[i][1]})
+		}
+	}
+	return ret
+}
+
+// diffStatuses returns the vulnerability and product pairs whose latest
+// status differs between the documents.
+func diffStatuses(oldDoc, newDoc *VEX) []StatusChange {
+	oldStatuses, newStatuses := latestStatuses(oldDoc), latestStatuses(newDoc)
+	keys := []string{}
+	for key := range oldStatuses {
+		keys = append(keys, key)
+	}
+	for key := range newStatuses {
+		if _, ok := oldStatuses[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	ret := []StatusChange{}
+	for _, key := range keys {
+		if oldStatuses[key] != newStatuses[key] {
+			ret = append(ret, StatusChange{Key: key, Old: oldStatuses[key], New: newStatuses[key]})
+		}
+	}
+	return ret
+}
+
+// latestStatuses returns the status set by the latest statement about each
+// vulnerability and product of the document.
+func latestStatuses(doc *VEX) map[string]Status {
+	ret := map[string]Status{}
+	if doc == nil {
+		return ret
+	}
+	var t time.Time
+	if doc.Timestamp != nil {
+		t = *doc.Timestamp
+	}
+
+	// Sort a copy, the diff must not reorder the documents
+	statements := append([]Statement{}, doc.Statements...)
+	SortStatements(statements, t)
+	for i := range statements {
+		for j := range statements[i].Products {
+			ret[conflictKey(&statements[i], &statements[i].Products[j])] = statements[i].Status
+		}
+	}
+	return ret
+}
@@ -0,0 +1,122 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	stmt := func(id, vuln, product string, status Status) Statement {
+		return Statement{
+			ID:            id,
+			Vulnerability: Vulnerability{Name: VulnerabilityID(vuln)},
+			Products:      []Product{{Component: Component{ID: product}}},
+			Status:        status,
+		}
+	}
+	oldDoc := &VEX{Statements: []Statement{
+		stmt("", "CVE-1", "pkg:apk/wolfi/git", StatusUnderInvestigation),
+		stmt("", "CVE-2", "pkg:apk/wolfi/git", StatusAffected),
+		stmt("https://example.com/stmt/3", "CVE-3", "pkg:apk/wolfi/git", StatusAffected),
+		stmt("", "CVE-4", "pkg:apk/wolfi/git", StatusAffected),
+	}}
+	newDoc := &VEX{Statements: []Statement{
+		stmt("", "CVE-2", "pkg:apk/wolfi/git", StatusAffected),
+		stmt("", "CVE-1", "pkg:apk/wolfi/git", StatusFixed),
+		stmt("https://example.com/stmt/3", "CVE-3", "pkg:apk/wolfi/bash", StatusAffected),
+		stmt("", "CVE-5", "pkg:apk/wolfi/git", StatusAffected),
+	}}
+
+	diff := Diff(oldDoc, newDoc)
+	require.False(t, diff.IsEmpty())
+	require.Len(t, diff.Added, 1)
+	require.Equal(t, VulnerabilityID("CVE-5"), diff.Added[0].Vulnerability.Name)
+	require.Len(t, diff.Removed, 1)
+	require.Equal(t, VulnerabilityID("CVE-4"), diff.Removed[0].Vulnerability.Name)
+	require.Len(t, diff.Changed, 2)
+	require.Equal(t, StatusUnderInvestigation, diff.Changed[0].Old.Status)
+	require.Equal(t, StatusFixed, diff.Changed[0].New.Status)
+	require.Equal(t, "pkg:apk/wolfi/bash", diff.Changed[1].New.Products[0].ID)
+
+	require.True(t, Diff(newDoc, newDoc).IsEmpty())
+	require.Len(t, Diff(nil, newDoc).Added, 4)
+
+	// Attribution and metadata changes are statement changes
+	for m, change := range map[string]func(*Statement){
+		"author":     func(s *Statement) { s.Author = "Vendor PSIRT" },
+		"role":       func(s *Statement) { s.AuthorRole = "Supplier" },
+		"lang":       func(s *Statement) { s.Lang = "de" },
+		"annotation": func(s *Statement) { s.Annotations = map[string]string{"example.com/ticket": "SEC-1234"} },
+		"reference":  func(s *Statement) { s.References = []Reference{{URL: "https://example.com/advisory"}} },
+		"credit":     func(s *Statement) { s.Credits = []Credit{{Organization: "Wolfi"}} },
+		"supplier":   func(s *Statement) { s.Products[0].Supplier = "Organization: Wolfi" },
+	} {
+		changed := &VEX{Statements: []Statement{*newDoc.Statements[0].DeepCopy()}}
+		change(&changed.Statements[0])
+		diff := Diff(&VEX{Statements: newDoc.Statements[:1]}, changed)
+		require.Len(t, diff.Changed, 1, m)
+	}
+}
+
+func TestDiffMetadataAndStatuses(t *testing.T) {
+	before := time.Date(2023, 6, 1, 10, 0, 0, 0, time.UTC)
+	after := before.Add(24 * time.Hour)
+
+	oldDoc := &VEX{
+		Metadata: Metadata{ID: "https://example.com/vex/1", Author: "Wolfi", Version: 1, Timestamp: &before},
+		Statements: []Statement{
+			{
+				Vulnerability: Vulnerability{Name: "CVE-2023-0001"},
+				Products:      []Product{{Component: Component{ID: "pkg:apk/wolfi/git"}}},
+				Status:        StatusUnderInvestigation,
+			},
+			{
+				Vulnerability: Vulnerability{Name: "CVE-2023-0002"},
+				Products:      []Product{{Component: Component{ID: "pkg:apk/wolfi/git"}}},
+				Status:        StatusAffected,
+			},
+		},
+	}
+	newDoc := &VEX{
+		Metadata: Metadata{ID: "https://example.com/vex/1", Author: "Wolfi", Version: 2, Timestamp: &before, LastUpdated: &after},
+		Statements: []Statement{
+			oldDoc.Statements[0],
+			{
+				Vulnerability: Vulnerability{Name: "CVE-2023-0001"},
+				Products:      []Product{{Component: Component{ID: "pkg:apk/wolfi/git"}}},
+				Status:        StatusNotAffected,
+				Justification: ComponentNotPresent,
+				Timestamp:     &after,
+			},
+		},
+	}
+
+	diff := Diff(oldDoc, newDoc)
+	require.Equal(t, []MetadataChange{
+		{Field: "last_updated", Old: "", New: "2023-06-02T10:00:00Z"},
+		{Field: "version", Old: "1", New: "2"},
+	}, diff.Metadata)
+	require.Equal(t, []StatusChange{
+		{Key: "CVE-2023-0001 for purl:pkg:apk/wolfi/git", Old: StatusUnderInvestigation, New: StatusNotAffected},
+		{Key: "CVE-2023-0002 for purl:pkg:apk/wolfi/git", Old: StatusAffected, New: ""},
+	}, diff.StatusChanges)
+
+	rendered := diff.String()
+	require.Contains(t, rendered, "  version: \"1\" -> \"2\"\n")
+	require.Contains(t, rendered, "  CVE-2023-0001 for purl:pkg:apk/wolfi/git: under_investigation -> not_affected\n")
+	require.Contains(t, rendered, "  CVE-2023-0002 for purl:pkg:apk/wolfi/git: affected -> none\n")
+	require.Contains(t, rendered, "added statements:\n  + CVE-2023-0001 not_affected")
+	require.Contains(t, rendered, "removed statements:\n  - CVE-2023-0002 affected")
+
+	// Metadata changes alone make the diff non empty
+	newDoc = &VEX{Metadata: oldDoc.Metadata, Statements: oldDoc.Statements}
+	newDoc.Version = 2
+	require.False(t, Diff(oldDoc, newDoc).IsEmpty())
+	require.Empty(t, Diff(oldDoc, newDoc).StatusChanges)
+	require.Equal(t, "no changes\n", Diff(oldDoc, oldDoc).String())
+}
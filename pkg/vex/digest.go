@@ -0,0 +1,198 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrDigestMismatch is returned when a document does not match the
+// expected digest.
+var ErrDigestMismatch = errors.New("document digest does not match")
+
+// digestAlgorithms are the hash algorithms supported to compute document
+// digests.
+var digestAlgorithms = map[Algorithm]func() hash.Hash{
+	SHA256: sha256.New,
+	SHA384: sha512.New384,
+	SHA512: sha512.New,
+}
+
+// canonicalDateFields are the fields of documents and statements holding
+// dates, normalized to UTC in the canonical encoding.
+var canonicalDateFields = []string{"timestamp", "last_updated", "action_statement_timestamp"}
+
+// Canonicalize returns the canonical JSON encoding of the document: compact
+// JSON with the object keys sorted, all dates normalized to UTC and the
+// statements sorted like SortStatements, by vulnerability and timestamp.
+// Statements that tie keep their document order, as it decides which one
+// is effective (see EffectiveStatement). Documents holding the same data
+// always encode to the same bytes, regardless of the timezones of their
+// dates or the order of statements that don't tie, so the encoding can be
+// hashed or signed.
+func (vexDoc *VEX) Canonicalize() ([]byte, error) {
+	data, err := json.Marshal(vexDoc)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling document: %w", err)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	doc := map[string]any{}
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("decoding document: %w", err)
+	}
+	if err := normalizeDates(doc); err != nil {
+		return nil, err
+	}
+
+	// Sort the statements stably, ties are left in document order
+	statements, _ := doc["statements"].([]any) //nolint:errcheck // nil when there are no statements
+	var documentTimestamp time.Time
+	if vexDoc.Timestamp != nil {
+		documentTimestamp = *vexDoc.Timestamp
+	}
+	order := make([]int, len(statements))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return statementLess(&vexDoc.Statements[order[i]], &vexDoc.Statements[order[j]], documentTimestamp)
+	})
+
+	sorted := make([]any, len(statements))
+	for i, n := range order {
+		if stmt, ok := statements[n].(map[string]any); ok {
+			if err := normalizeDates(stmt); err != nil {
+				return nil, err
+			}
+		}
+		sorted[i] = statements[n]
+	}
+	if statements != nil {
+		doc["statements"] = sorted
+	}
+
+	var b bytes.Buffer
+	if err := writeCanonicalJSON(&b, doc); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// normalizeDates rewrites the date fields of a decoded object in UTC.
+func normalizeDates(obj map[string]any) error {
+	for _, field := range canonicalDateFields {
+		s, ok := obj[field].(string)
+		if !ok || s == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return fmt.Errorf("parsing %s: %w", field, err)
+		}
+		obj[field] = t.UTC().Format(time.RFC3339Nano)
+	}
+	return nil
+}
+
+// writeCanonicalJSON writes the compact encoding of a decoded JSON value
+// with the keys of its objects sorted.
+func writeCanonicalJSON(b *bytes.Buffer, v any) error {
+	switch val := v.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		b.WriteByte('{')
+		for i, k := range keys {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			if err := writeCanonicalJSON(b, k); err != nil {
+				return err
+			}
+			b.WriteByte(':')
+			if err := writeCanonicalJSON(b, val[k]); err != nil {
+				return err
+			}
+		}
+		b.WriteByte('}')
+	case []any:
+		b.WriteByte('[')
+		for i := range val {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			if err := writeCanonicalJSON(b, val[i]); err != nil {
+				return err
+			}
+		}
+		b.WriteByte(']')
+	case json.RawMessage:
+		b.Write(val)
+	default:
+		enc := json.NewEncoder(b)
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(val); err != nil {
+			return fmt.Errorf("encoding value: %w", err)
+		}
+		b.Truncate(b.Len() - 1) // Trailing newline
+	}
+	return nil
+}
+
+// Digest returns the digest of the document computed with the specified
+// algorithm. The digest string is prefixed with the algorithm name, for
+// example "sha-256:3a7bd3e2...". The digest is computed over the canonical
+// encoding returned by Canonicalize so copies of a document fetched from
+// different mirrors get the same digest. Unlike CanonicalHash, the digest
+// covers all the data in the document, including its metadata.
+func (vexDoc *VEX) Digest(algo Algorithm) (string, error) {
+	newHash, ok := digestAlgorithms[algo]
+	if !ok {
+		return "", fmt.Errorf("unsupported digest algorithm %q", algo)
+	}
+
+	data, err := vexDoc.Canonicalize()
+	if err != nil {
+		return "", err
+	}
+
+	h := newHash()
+	if _, err := h.Write(data); err != nil {
+		return "", fmt.Errorf("hashing document: %w", err)
+	}
+	return fmt.Sprintf("%s:%x", algo, h.Sum(nil)), nil
+}
+
+// VerifyDigest checks the document against an expected digest as returned
+// by Digest. If the document does not match, it returns ErrDigestMismatch.
+func (vexDoc *VEX) VerifyDigest(expected string) error {
+	algo, _, ok := strings.Cut(expected, ":")
+	if !ok {
+		return fmt.Errorf("invalid digest %q, expected <algorithm>:<hex value>", expected)
+	}
+
+	digest, err := vexDoc.Digest(Algorithm(algo))
+	if err != nil {
+		return fmt.Errorf("computing document digest: %w", err)
+	}
+
+	if !strings.EqualFold(digest, expected) {
+		return ErrDigestMismatch
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDigest(t *testing.T) {
+	doc, err := Open("testdata/v0.2.0.json")
+	require.NoError(t, err)
+
+	for _, algo := range []Algorithm{SHA256, SHA384, SHA512} {
+		digest, err := doc.Digest(algo)
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(digest, string(algo)+":"))
+
+		// Digests are stable
+		digest2, err := doc.Digest(algo)
+		require.NoError(t, err)
+		require.Equal(t, digest, digest2)
+		require.NoError(t, doc.VerifyDigest(digest))
+	}
+
+	_, err = doc.Digest(MD5)
+	require.Error(t, err)
+}
+
+func TestVerifyDigest(t *testing.T) {
+	doc, err := Open("testdata/v0.2.0.json")
+	require.NoError(t, err)
+
+	digest, err := doc.Digest(SHA256)
+	require.NoError(t, err)
+
+	// Changing metadata changes the digest
+	doc.Author = "Someone Else"
+	require.ErrorIs(t, doc.VerifyDigest(digest), ErrDigestMismatch)
+
+	// Truncating the document changes the digest
+	doc, err = Open("testdata/v0.2.0.json")
+	require.NoError(t, err)
+	doc.Statements = doc.Statements[:1]
+	require.ErrorIs(t, doc.VerifyDigest(digest), ErrDigestMismatch)
+
+	require.Error(t, doc.VerifyDigest("not a digest"))
+	require.Error(t, doc.VerifyDigest("crc32:1234"))
+}
+
+func TestCanonicalize(t *testing.T) {
+	doc, err := Open("testdata/v0.2.0.json")
+	require.NoError(t, err)
+	canonical, err := doc.Canonicalize()
+	require.NoError(t, err)
+	require.NotContains(t, string(canonical), "\n")
+	require.True(t, strings.HasPrefix(string(canonical), `{"@context":"https://openvex.dev/ns/v0.2.0","@id":`))
+
+	// Statement order and timezones do not change the encoding
+	other, err := Open("testdata/v0.2.0.json")
+	require.NoError(t, err)
+	slices.Reverse(other.Statements)
+	ts := other.Timestamp.In(time.FixedZone("UTC+5", 5*60*60))
+	other.Timestamp = &ts
+	for i := range other.Statements {
+		if other.Statements[i].Timestamp != nil {
+			stmtTime := other.Statements[i].Timestamp.In(time.FixedZone("UTC-3", -3*60*60))
+			other.Statements[i].Timestamp = &stmtTime
+		}
+	}
+	otherCanonical, err := other.Canonicalize()
+	require.NoError(t, err)
+	require.Equal(t, string(canonical), string(otherCanonical))
+
+	d1, err := doc.Digest(SHA256)
+	require.NoError(t, err)
+	d2, err := other.Digest(SHA256)
+	require.NoError(t, err)
+	require.Equal(t, d1, d2)
+
+	// The document is not modified
+	require.Equal(t, "CVE-2023-1255", string(doc.Statements[0].Vulnerability.Name))
+
+	// Changing the data changes the encoding
+	other.Statements[0].Status = StatusAffected
+	otherCanonical, err = other.Canonicalize()
+	require.NoError(t, err)
+	require.NotEqual(t, string(canonical), string(otherCanonical))
+}
+
+func TestCanonicalizeKeepsTies(t *testing.T) {
+	// Statements with the same vulnerability and timestamp resolve to the
+	// last one, swapping them changes the effective status and the digest
+	ts := time.Date(2023, 4, 17, 20, 34, 58, 0, time.UTC)
+	doc := &VEX{
+		Metadata: Metadata{ID: "https://example.com/vex-1", Timestamp: &ts},
+		Statements: []Statement{
+			{
+				Vulnerability: Vulnerability{Name: "CVE-2023-0001"},
+				Products:      []Product{{Component: Component{ID: "pkg:oci/wolfi-base"}}},
+				Status:        StatusAffected,
+				Timestamp:     &ts,
+			},
+			{
+				Vulnerability: Vulnerability{Name: "CVE-2023-0001"},
+				Products:      []Product{{Component: Component{ID: "pkg:oci/wolfi-base"}}},
+				Status:        StatusFixed,
+				Timestamp:     &ts,
+			},
+		},
+	}
+	swapped := *doc
+	swapped.Statements = slices.Clone(doc.Statements)
+	slices.Reverse(swapped.Statements)
+
+	effective := func(d *VEX) Status {
+		return d.EffectiveStatement("pkg:oci/wolfi-base", "CVE-2023-0001").Status
+	}
+	require.Equal(t, StatusFixed, effective(doc))
+	require.Equal(t, StatusAffected, effective(&swapped))
+
+	d1, err := doc.Digest(SHA256)
+	require.NoError(t, err)
+	d2, err := swapped.Digest(SHA256)
+	require.NoError(t, err)
+	require.NotEqual(t, d1, d2)
+}
@@ -0,0 +1,114 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// Enricher looks up the data of vulnerabilities to complete documents whose
+// statements only carry the vulnerability identifiers, see VEX.Enrich. The
+// enrich package has an implementation that queries OSV, the NVD and the
+// GitHub advisories.
+type Enricher interface {
+	// LookupVulnerabilities returns the description, aliases and ratings
+	// of the vulnerabilities, indexed by name. Unknown vulnerabilities are
+	// left out of the map. When some lookups fail, the data found for the
+	// rest is returned along with the error.
+	LookupVulnerabilities(ctx context.Context, names []VulnerabilityID) (map[VulnerabilityID]*Vulnerability, error)
+}
+
+// Enrichment records the data filled in a vulnerability by VEX.Enrich.
+type Enrichment struct {
+	// Vulnerability is the name of the enriched vulnerability.
+	Vulnerability VulnerabilityID
+
+	// Description is true if the vulnerability description was filled.
+	Description bool
+
+	// Aliases lists the aliases added to the vulnerability.
+	Aliases []VulnerabilityID
+
+	// Ratings is true if the vulnerability ratings were filled.
+	Ratings bool
+}
+
+// Enrich fills the missing description, aliases and ratings of the
+// statement vulnerabilities with the data looked up by the enricher. Each
+// vulnerability is looked up once, and only when one of its statements lacks
+// some of the data. It returns the data filled in each vulnerability, sorted
+// by name. If the enricher fails, the data it returned is still filled and
+// the error is returned along with the enrichments.
+func (vexDoc *VEX) Enrich(ctx context.Context, enricher Enricher) ([]Enrichment, error) {
+	pending := []VulnerabilityID{}
+	for i := range vexDoc.Statements {
+		v := &vexDoc.Statements[i].Vulnerability
+		if v.Name == "" || (v.Description != "" && len(v.Aliases) > 0 && len(v.Ratings) > 0) {
+			continue
+		}
+		if !slices.Contains(pending, v.Name) {
+			pending = append(pending, v.Name)
+		}
+	}
+	if len(pending) == 0 {
+		return []Enrichment{}, nil
+	}
+
+	found, err := enricher.LookupVulnerabilities(ctx, pending)
+	if err != nil {
+		err = fmt.Errorf("looking up vulnerabilities: %w", err)
+	}
+
+	enrichments := map[VulnerabilityID]*Enrichment{}
+	for i := range vexDoc.Statements {
+		v := &vexDoc.Statements[i].Vulnerability
+		data, ok := found[v.Name]
+		if !ok || data == nil {
+			continue
+		}
+
+		enrichment, ok := enrichments[v.Name]
+		if !ok {
+			enrichment = &Enrichment{Vulnerability: v.Name}
+		}
+
+		if v.Description == "" && data.Description != "" {
+			v.Description = data.Description
+			enrichment.Description = true
+		}
+
+		if len(v.Aliases) == 0 {
+			for _, alias := range data.Aliases {
+				if alias == v.Name || slices.Contains(v.Aliases, alias) {
+					continue
+				}
+				v.Aliases = append(v.Aliases, alias)
+				if !slices.Contains(enrichment.Aliases, alias) {
+					enrichment.Aliases = append(enrichment.Aliases, alias)
+				}
+			}
+		}
+
+		if len(v.Ratings) == 0 && len(data.Ratings) > 0 {
+			v.Ratings = slices.Clone(data.Ratings)
+			enrichment.Ratings = true
+		}
+
+		if enrichment.Description || len(enrichment.Aliases) > 0 || enrichment.Ratings {
+			enrichments[v.Name] = enrichment
+		}
+	}
+
+	ret := make([]Enrichment, 0, len(enrichments))
+	for _, enrichment := range enrichments {
+		ret = append(ret, *enrichment)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Vulnerability < ret[j].Vulnerability
+	})
+	return ret, err
+}
@@ -0,0 +1,85 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeEnricher returns the data of the vulnerabilities in the map and
+// records the lookups.
+type fakeEnricher struct {
+	data    map[VulnerabilityID]*Vulnerability
+	err     error
+	lookups [][]VulnerabilityID
+}
+
+func (fe *fakeEnricher) LookupVulnerabilities(_ context.Context, names []VulnerabilityID) (map[VulnerabilityID]*Vulnerability, error) {
+	fe.lookups = append(fe.lookups, names)
+	ret := map[VulnerabilityID]*Vulnerability{}
+	for _, n := range names {
+		if v, ok := fe.data[n]; ok {
+			ret[n] = v
+		}
+	}
+	return ret, fe.err
+}
+
+func TestEnrich(t *testing.T) {
+	rating := Rating{Method: RatingCVSSv31, Vector: "CVSS:3.1/AV:N/AC:H/PR:N/UI:N/S:U/C:N/I:N/A:H", Score: 5.9, Severity: SeverityMedium}
+	doc := &VEX{
+		Statements: []Statement{
+			{Vulnerability: Vulnerability{Name: "CVE-2023-1255"}, Status: StatusFixed},
+			{Vulnerability: Vulnerability{Name: "CVE-2023-1255"}, Status: StatusAffected},
+			{
+				Vulnerability: Vulnerability{
+					Name: "CVE-2023-2650", Description: "Already here", Aliases: []VulnerabilityID{"GHSA-1234"},
+					Ratings: []Rating{{Severity: SeverityLow}},
+				},
+				Status: StatusFixed,
+			},
+			{Vulnerability: Vulnerability{Name: "CVE-2023-9999"}, Status: StatusFixed},
+		},
+	}
+
+	enricher := &fakeEnricher{data: map[VulnerabilityID]*Vulnerability{
+		"CVE-2023-1255": {
+			Description: "Input buffer over-read in AES-XTS",
+			Aliases:     []VulnerabilityID{"CVE-2023-1255", "GHSA-aaaa"},
+			Ratings:     []Rating{rating},
+		},
+		"CVE-2023-2650": {Description: "Should not be used"},
+	}}
+	enrichments, err := doc.Enrich(context.Background(), enricher)
+	require.NoError(t, err)
+
+	// Complete vulnerabilities are not looked up
+	require.Equal(t, [][]VulnerabilityID{{"CVE-2023-1255", "CVE-2023-9999"}}, enricher.lookups)
+	require.Equal(t, []Enrichment{{
+		Vulnerability: "CVE-2023-1255", Description: true, Aliases: []VulnerabilityID{"GHSA-aaaa"}, Ratings: true,
+	}}, enrichments)
+	for _, i := range []int{0, 1} {
+		v := &doc.Statements[i].Vulnerability
+		require.Equal(t, "Input buffer over-read in AES-XTS", v.Description)
+		require.Equal(t, []VulnerabilityID{"GHSA-aaaa"}, v.Aliases)
+		require.Equal(t, []Rating{rating}, v.Ratings)
+	}
+	require.Equal(t, "Already here", doc.Statements[2].Vulnerability.Description)
+	require.Empty(t, doc.Statements[3].Vulnerability.Description)
+
+	// Data found before a failure is filled
+	doc.Statements[3].Vulnerability.Description = ""
+	enricher = &fakeEnricher{
+		data: map[VulnerabilityID]*Vulnerability{"CVE-2023-9999": {Description: "Found"}},
+		err:  errors.New("source is down"),
+	}
+	enrichments, err = doc.Enrich(context.Background(), enricher)
+	require.Error(t, err)
+	require.Len(t, enrichments, 1)
+	require.Equal(t, "Found", doc.Statements[3].Vulnerability.Description)
+}
@@ -0,0 +1,69 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/openvex/go-vex/pkg/csaf"
+)
+
+// Feed is a source of advisories listing the vulnerabilities that affect
+// software, such as a vulnerability database or a set of CSAF advisories.
+type Feed interface {
+	// Name returns a string identifying the feed.
+	Name() string
+
+	// Vulnerabilities returns the vulnerabilities the feed reports as
+	// affecting the software known by any of the identifiers. Identifier
+	// types the feed does not handle are ignored.
+	Vulnerabilities(ctx context.Context, identifiers []string) ([]VulnerabilityID, error)
+}
+
+// CSAFFeed is a feed of CSAF advisories. It reports the vulnerabilities
+// listing the software among their known affected products.
+type CSAFFeed struct {
+	Documents []*csaf.CSAF
+}
+
+// NewCSAFFeed returns a feed of the CSAF documents.
+func NewCSAFFeed(docs ...*csaf.CSAF) *CSAFFeed {
+	return &CSAFFeed{Documents: docs}
+}
+
+// Name returns the name of the feed.
+func (*CSAFFeed) Name() string { return "csaf" }
+
+// Vulnerabilities returns the vulnerabilities of the advisories that affect
+// any of the identifiers. The advisories are converted with FromCSAF and
+// products are matched using their CSAF product identification helpers.
+func (f *CSAFFeed) Vulnerabilities(_ context.Context, identifiers []string) ([]VulnerabilityID, error) {
+	seen := map[VulnerabilityID]struct{}{}
+	ret := []VulnerabilityID{}
+	for _, doc := range f.Documents {
+		converted, err := FromCSAF(doc)
+		if err != nil {
+			return nil, fmt.Errorf("converting csaf doc: %w", err)
+		}
+		for i := range converted.Statements {
+			stmt := &converted.Statements[i]
+			if stmt.Status != StatusAffected {
+				continue
+			}
+			name := stmt.Vulnerability.Name
+			if _, ok := seen[name]; ok || name == "" {
+				continue
+			}
+			for _, id := range identifiers {
+				if stmt.MatchesProduct(id, "") {
+					seen[name] = struct{}{}
+					ret = append(ret, name)
+					break
+				}
+			}
+		}
+	}
+	return ret, nil
+}
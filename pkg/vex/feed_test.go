@@ -0,0 +1,40 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/openvex/go-vex/pkg/csaf"
+)
+
+func TestCSAFFeed(t *testing.T) {
+	doc := &csaf.CSAF{
+		ProductTree: csaf.ProductBranch{
+			Branches: []csaf.ProductBranch{
+				{Product: csaf.Product{ID: "IMG-1", IdentificationHelper: map[string]string{"purl": "pkg:oci/app@sha256%3Aabc"}}},
+				{Product: csaf.Product{ID: "IMG-2", IdentificationHelper: map[string]string{"purl": "pkg:oci/other@sha256%3Adef"}}},
+			},
+		},
+		Vulnerabilities: []csaf.Vulnerability{
+			{CVE: "CVE-2023-0001", ProductStatus: map[string][]string{"known_affected": {"IMG-1", "IMG-2"}}},
+			{CVE: "CVE-2023-0002", ProductStatus: map[string][]string{"known_not_affected": {"IMG-1"}, "known_affected": {"IMG-2"}}},
+			{IDs: []csaf.TrackingID{{SystemName: "GHSA", Text: "GHSA-aaaa-bbbb-cccc"}}, ProductStatus: map[string][]string{"known_affected": {"IMG-1"}}},
+			{CVE: "CVE-2023-0003", ProductStatus: map[string][]string{"fixed": {"IMG-1"}}},
+		},
+	}
+
+	feed := NewCSAFFeed(doc, doc)
+	require.Equal(t, "csaf", feed.Name())
+	vulns, err := feed.Vulnerabilities(context.Background(), []string{"sha256:abc", "pkg:oci/app@sha256%3Aabc"})
+	require.NoError(t, err)
+	require.Equal(t, []VulnerabilityID{"CVE-2023-0001", "GHSA-aaaa-bbbb-cccc"}, vulns)
+
+	vulns, err = feed.Vulnerabilities(context.Background(), []string{"pkg:oci/missing"})
+	require.NoError(t, err)
+	require.Empty(t, vulns)
+}
@@ -9,14 +9,47 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"time"
+)
+
+// MergeMode selects the semantics used to merge documents.
+type MergeMode int
+
+const (
+	// MergeModeCompat is the default mode. It reproduces the merge and
+	// filter semantics of the OpenVEX tooling (eg vexctl merge) so that
+	// merged documents are interchangeable with the ones produced by
+	// existing tools. Statements are copied as they are, only cascading the
+	// document timestamp, the merged document gets the default or specified
+	// author and vulnerability filters are matched verbatim.
+	MergeModeCompat MergeMode = iota
+
+	// MergeModeAttributed merges documents preserving the language and
+	// author of the source statements and inheriting the language and
+	// author shared by the source documents. Vulnerability filters also
+	// match the aliases of the statement vulnerabilities.
+	MergeModeAttributed
 )
 
 type MergeOptions struct {
-	DocumentID      string   // ID to use in the new document
-	Author          string   // Author to use in the new document
-	AuthorRole      string   // Role of the document author
-	Products        []string // Product IDs to consider
-	Vulnerabilities []string // IDs of vulnerabilities to merge
+	DocumentID      string    // ID to use in the new document
+	Author          string    // Author to use in the new document
+	AuthorRole      string    // Role of the document author
+	Products        []string  // Product IDs to consider
+	Vulnerabilities []string  // IDs of vulnerabilities to merge
+	UnifyAliases    bool      // Merge statements about the same vulnerability under different IDs
+	Mode            MergeMode // Merge semantics to use
+
+	// Conflicts sets how statements from different documents about the
+	// same vulnerability and product are handled.
+	Conflicts ConflictPolicy
+
+	// AuthorPriority lists the authors in order of precedence when using
+	// the ConflictAuthorPriority policy. Authors not listed go last.
+	AuthorPriority []string
+
+	// Clock sets the timestamp of the merged document. See NewWithOptions.
+	Clock Clock
 }
 
 // MergeDocuments is a convenience wrapper over MergeDocumentsWithOptions
@@ -32,16 +65,74 @@ func MergeDocumentsWithOptions(mergeOpts *MergeOptions, docs []*VEX) (*VEX, erro
 		return nil, fmt.Errorf("at least one vex document is required to merge")
 	}
 
+	metas := []*Metadata{}
+	for _, doc := range docs {
+		metas = append(metas, &doc.Metadata)
+	}
+	newDoc := newMergedDocument(mergeOpts, metas)
+	filter := newMergeFilter(mergeOpts)
+	compat := mergeOpts.Mode == MergeModeCompat
+
+	ss := []Statement{}
+	origins := []statementOrigin{}
+
+	for docIdx, doc := range docs {
+		for _, s := range doc.Statements { //nolint:gocritic // this IS supposed to copy
+			if !filter.matches(&s) {
+				continue
+			}
+			origin, err := mergeStatement(&s, &doc.Metadata, &newDoc, compat)
+			if err != nil {
+				return nil, err
+			}
+			origin.doc = docIdx
+			ss = append(ss, s)
+			origins = append(origins, origin)
+		}
+	}
+
+	ss, err := resolveConflicts(mergeOpts, ss, origins)
+	if err != nil {
+		return nil, err
+	}
+
+	if mergeOpts.UnifyAliases {
+		ss = unifyAliases(ss)
+	}
+
+	SortStatements(ss, *newDoc.Timestamp)
+
+	newDoc.Statements = ss
+
+	return &newDoc, nil
+}
+
+// DryRunMerge merges the documents like MergeDocumentsWithOptions and
+// returns the changes as a diff of the current document against the merge
+// result. current is the document the merge would replace, for example the
+// published result of a previous merge, or nil if there is none. Use it to
+// review merges before publishing them.
+func DryRunMerge(mergeOpts *MergeOptions, current *VEX, docs []*VEX) (*DocumentDiff, error) {
+	merged, err := MergeDocumentsWithOptions(mergeOpts, docs)
+	if err != nil {
+		return nil, err
+	}
+	return Diff(current, merged), nil
+}
+
+// newMergedDocument returns the document that gets the statements merged
+// from the documents with the metadata in metas.
+func newMergedDocument(mergeOpts *MergeOptions, metas []*Metadata) VEX {
 	docID := mergeOpts.DocumentID
 	// If no document id is specified we compute a
 	// deterministic ID using the merged docs
 	if docID == "" {
 		ids := []string{}
-		for i, d := range docs {
-			if d.ID == "" {
+		for i, md := range metas {
+			if md.ID == "" {
 				ids = append(ids, fmt.Sprintf("VEX-DOC-%d", i))
 			} else {
-				ids = append(ids, d.ID)
+				ids = append(ids, md.ID)
 			}
 		}
 
@@ -52,9 +143,16 @@ func MergeDocumentsWithOptions(mergeOpts *MergeOptions, docs []*VEX) (*VEX, erro
 		docID = fmt.Sprintf("merged-vex-%x", h.Sum(nil))
 	}
 
-	newDoc := New()
+	newDoc := NewWithOptions(&DocumentOptions{Clock: mergeOpts.Clock})
 
 	newDoc.ID = docID
+
+	compat := mergeOpts.Mode == MergeModeCompat
+
+	// If all documents share an author, the merged document inherits it
+	if author, role, ok := commonAuthor(metas); ok && mergeOpts.Author == "" && !compat {
+		newDoc.Author, newDoc.AuthorRole = author, role
+	}
 	if author := mergeOpts.Author; author != "" {
 		newDoc.Author = author
 	}
@@ -62,62 +160,224 @@ func MergeDocumentsWithOptions(mergeOpts *MergeOptions, docs []*VEX) (*VEX, erro
 		newDoc.AuthorRole = authorRole
 	}
 
-	ss := []Statement{}
+	// If all documents share a language, the merged document inherits it
+	if !compat {
+		newDoc.Lang = metas[0].Lang
+		for _, md := range metas[1:] {
+			if md.Lang != newDoc.Lang {
+				newDoc.Lang = ""
+				break
+			}
+		}
+	}
+	return newDoc
+}
+
+// mergeFilter selects the statements to merge by the products and
+// vulnerabilities listed in the merge options.
+type mergeFilter struct {
+	products map[string]struct{}
+	vulns    map[string]struct{}
+	compat   bool
+}
 
+// newMergeFilter returns the filter of the merge options.
+func newMergeFilter(mergeOpts *MergeOptions) *mergeFilter {
 	// Create an inverse dict of products and vulnerabilities to filter
 	// these will only be used if ids to filter on are defined in the options.
-	iProds := map[string]struct{}{}
-	iVulns := map[string]struct{}{}
+	f := &mergeFilter{
+		products: map[string]struct{}{},
+		vulns:    map[string]struct{}{},
+		compat:   mergeOpts.Mode == MergeModeCompat,
+	}
 	for _, id := range mergeOpts.Products {
-		iProds[id] = struct{}{}
+		f.products[id] = struct{}{}
 	}
 	for _, id := range mergeOpts.Vulnerabilities {
-		iVulns[id] = struct{}{}
+		f.vulns[id] = struct{}{}
 	}
+	return f
+}
 
-	for _, doc := range docs {
-		for _, s := range doc.Statements { //nolint:gocritic // this IS supposed to copy
-			matchesProduct := false
-			for id := range iProds {
-				if s.MatchesProduct(id, "") {
-					matchesProduct = true
-					break
-				}
-			}
-			if len(iProds) > 0 && !matchesProduct {
-				continue
-			}
+// matches returns true if the statement is to be merged.
+func (f *mergeFilter) matches(s *Statement) bool {
+	matchesProduct := false
+	for id := range f.products {
+		if s.MatchesProduct(id, "") {
+			matchesProduct = true
+			break
+		}
+	}
+	if len(f.products) > 0 && !matchesProduct {
+		return false
+	}
 
-			matchesVuln := false
-			for id := range iVulns {
-				if s.Vulnerability.Matches(id) {
-					matchesVuln = true
-					break
-				}
-			}
-			if len(iVulns) > 0 && !matchesVuln {
-				continue
+	matchesVuln := false
+	for id := range f.vulns {
+		if (f.compat && s.Vulnerability.matchesVerbatim(id)) || (!f.compat && s.Vulnerability.Matches(id)) {
+			matchesVuln = true
+			break
+		}
+	}
+	return len(f.vulns) == 0 || matchesVuln
+}
+
+// mergeStatement prepares a statement of the document with metadata md to
+// be merged into newDoc and returns its origin.
+func mergeStatement(s *Statement, md *Metadata, newDoc *VEX, compat bool) (statementOrigin, error) {
+	// If statement does not have a timestamp, cascade
+	// the timestamp down from the document.
+	// See https://github.com/chainguard-dev/vex/issues/49
+	if s.Timestamp == nil {
+		if md.Timestamp == nil {
+			return statementOrigin{}, errors.New("unable to cascade timestamp from doc to timeless statement")
+		}
+		s.Timestamp = md.Timestamp
+	}
+
+	origin := statementOrigin{author: s.Author}
+	if origin.author == "" {
+		origin.author = md.Author
+	}
+
+	if compat {
+		return origin, nil
+	}
+
+	// Preserve the document language in the merged statements
+	if s.Lang == "" {
+		s.Lang = md.Lang
+	}
+
+	// Preserve the original asserter of statements coming from
+	// documents of other authors.
+	if s.Author == "" && (md.Author != newDoc.Author || md.AuthorRole != newDoc.AuthorRole) {
+		s.Author, s.AuthorRole = md.Author, md.AuthorRole
+	}
+	return origin, nil
+}
+
+// commonAuthor returns the author and role shared by all the documents.
+func commonAuthor(metas []*Metadata) (author, role string, ok bool) {
+	author, role = metas[0].Author, metas[0].AuthorRole
+	if author == "" {
+		return "", "", false
+	}
+	for _, md := range metas[1:] {
+		if md.Author != author || md.AuthorRole != role {
+			return "", "", false
+		}
+	}
+	return author, role, true
+}
+
+// unifyAliases groups the statements that refer to the same vulnerability
+// through their names and aliases. All statements in a group are rewritten
+// to use the same name, preferring a CVE identifier, and the union of the
+// group aliases. Statements that become identical are then deduplicated.
+func unifyAliases(ss []Statement) []Statement {
+	// Union-find over the local form of the vulnerability identifiers
+	parent := map[VulnerabilityID]VulnerabilityID{}
+	var find func(VulnerabilityID) VulnerabilityID
+	find = func(id VulnerabilityID) VulnerabilityID {
+		if p, ok := parent[id]; ok && p != id {
+			parent[id] = find(p)
+			return parent[id]
+		}
+		parent[id] = id
+		return id
+	}
+
+	ids := func(v *Vulnerability) []VulnerabilityID {
+		ret := []VulnerabilityID{}
+		for _, id := range append([]VulnerabilityID{v.Name, VulnerabilityID(v.ID)}, v.Aliases...) {
+			if id != "" {
+				ret = append(ret, id.Local())
 			}
+		}
+		return ret
+	}
 
-			// If statement does not have a timestamp, cascade
-			// the timestamp down from the document.
-			// See https://github.com/chainguard-dev/vex/issues/49
-			if s.Timestamp == nil {
-				if doc.Timestamp == nil {
-					return nil, errors.New("unable to cascade timestamp from doc to timeless statement")
-				}
-				s.Timestamp = doc.Timestamp
+	for i := range ss {
+		list := ids(&ss[i].Vulnerability)
+		for _, id := range list {
+			if a, b := find(list[0]), find(id); a != b {
+				parent[b] = a
 			}
+		}
+	}
 
-			ss = append(ss, s)
+	// Collect the members and candidate names of each group
+	members := map[VulnerabilityID][]VulnerabilityID{}
+	names := map[VulnerabilityID][]VulnerabilityID{}
+	for id := range parent {
+		root := find(id)
+		members[root] = append(members[root], id)
+	}
+	for i := range ss {
+		if ss[i].Vulnerability.Name == "" {
+			continue
 		}
+		root := find(ss[i].Vulnerability.Name.Local())
+		names[root] = append(names[root], ss[i].Vulnerability.Name.Local())
 	}
 
-	SortStatements(ss, *newDoc.Timestamp)
+	ret := []Statement{}
+	seen := map[string]struct{}{}
+	for i := range ss {
+		v := &ss[i].Vulnerability
+		list := ids(v)
+		if len(list) == 0 {
+			ret = append(ret, ss[i])
+			continue
+		}
+		root := find(list[0])
+		name := preferredVulnerabilityName(names[root])
+		if name == "" {
+			name = preferredVulnerabilityName(members[root])
+		}
 
-	newDoc.Statements = ss
+		aliases := []VulnerabilityID{}
+		for _, id := range members[root] {
+			if id != name {
+				aliases = append(aliases, id)
+			}
+		}
+		sort.Slice(aliases, func(i, j int) bool { return aliases[i] < aliases[j] })
 
-	return &newDoc, nil
+		if v.ID != "" && VulnerabilityID(v.ID).Local() != name {
+			v.ID = ""
+		}
+		v.Name = name
+		v.Aliases = aliases
+
+		cstring := cstringFromStatement(&ss[i])
+		if _, ok := seen[cstring]; ok {
+			continue
+		}
+		seen[cstring] = struct{}{}
+		ret = append(ret, ss[i])
+	}
+	return ret
+}
+
+// preferredVulnerabilityName picks the name of a group of vulnerability
+// identifiers, preferring CVE identifiers and then the smallest one.
+func preferredVulnerabilityName(ids []VulnerabilityID) VulnerabilityID {
+	var ret VulnerabilityID
+	for _, id := range ids {
+		isCVE := strings.HasPrefix(string(id), "CVE-")
+		retIsCVE := strings.HasPrefix(string(ret), "CVE-")
+		switch {
+		case ret == "":
+			ret = id
+		case isCVE && !retIsCVE:
+			ret = id
+		case isCVE == retIsCVE && id < ret:
+			ret = id
+		}
+	}
+	return ret
 }
 
 // SortDocuments sorts and returns a slice of documents based on their date.
@@ -135,3 +395,117 @@ func SortDocuments(docs []*VEX) []*VEX {
 	})
 	return docs
 }
+
+// Delta computes a new document containing only the statements in newDoc
+// that are not present in oldDoc, either because they were added or because
+// their contents changed. The resulting document inherits the version and
+// timestamps of newDoc and its statements carry over the dates from it so
+// that they stand alone. Passing a nil oldDoc returns all the statements in
+// newDoc.
+func Delta(oldDoc, newDoc *VEX) (*VEX, error) {
+	if newDoc == nil {
+		return nil, errors.New("a new document is required to compute the delta")
+	}
+
+	if newDoc.Timestamp == nil {
+		return nil, errors.New("new document has no timestamp")
+	}
+
+	known := map[string]struct{}{}
+	if oldDoc != nil {
+		if newDoc.Version <= oldDoc.Version {
+			return nil, fmt.Errorf(
+				"new document version (%d) must be higher than the old document version (%d)",
+				newDoc.Version, oldDoc.Version,
+			)
+		}
+
+		for i := range oldDoc.Statements {
+			known[cstringFromStatement(&oldDoc.Statements[i])] = struct{}{}
+		}
+	}
+
+	delta := New()
+	delta.Context = newDoc.Context
+	delta.Author = newDoc.Author
+	delta.AuthorRole = newDoc.AuthorRole
+	delta.Supplier = newDoc.Supplier
+	delta.Tooling = newDoc.Tooling
+	delta.Version = newDoc.Version
+	delta.Timestamp = newDoc.Timestamp
+	delta.LastUpdated = newDoc.LastUpdated
+
+	extracted := newDoc.ExtractStatements()
+	for i := range newDoc.Statements {
+		if _, ok := known[cstringFromStatement(&newDoc.Statements[i])]; ok {
+			continue
+		}
+		delta.Statements = append(delta.Statements, *extracted[i])
+	}
+
+	if _, err := delta.GenerateCanonicalID(); err != nil {
+		return nil, fmt.Errorf("generating delta document ID: %w", err)
+	}
+
+	return &delta, nil
+}
+
+// cstringFromStatement returns a string capturing all the data in a statement
+// used to detect when a statement changes, including its attribution and
+// metadata. Only the timestamps set in the statement are considered, dates
+// inherited from the document are ignored.
+func cstringFromStatement(s *Statement) string {
+	cString := cstringFromVulnerability(s.Vulnerability)
+	cString += fmt.Sprintf(
+		":%s:%s:%s:%s:%s:%s", s.ID, s.Status, s.Justification, s.StatusNotes, s.ImpactStatement, s.ActionStatement,
+	)
+	cString += fmt.Sprintf(":%s:%s:%s", s.Author, s.AuthorRole, s.Lang)
+
+	annotations := []string{}
+	for k, v := range s.Annotations {
+		annotations = append(annotations, fmt.Sprintf(":%s=%s", k, v))
+	}
+	sort.Strings(annotations)
+	cString += strings.Join(annotations, "")
+
+	for _, r := range s.References {
+		cString += fmt.Sprintf(":%s:%s:%s", r.Category, r.Summary, r.URL)
+	}
+	for _, c := range s.Credits {
+		cString += fmt.Sprintf(":%s:%s:%s:%s", strings.Join(c.Names, ","), c.Organization, c.Summary, strings.Join(c.URLs, ","))
+	}
+
+	for _, t := range []*time.Time{s.Timestamp, s.LastUpdated, s.ActionStatementTimestamp} {
+		if t != nil {
+			cString += fmt.Sprintf(":%d", t.Unix())
+		} else {
+			cString += ":"
+		}
+	}
+
+	for i := range s.Remediations {
+		r := &s.Remediations[i]
+		cString += fmt.Sprintf(":%s:%s:%s", r.Category, r.Details, r.URL)
+		for _, t := range []*time.Time{r.Date, r.DueDate} {
+			if t != nil {
+				cString += fmt.Sprintf(":%d", t.Unix())
+			} else {
+				cString += ":"
+			}
+		}
+	}
+
+	prods := []string{}
+	for _, p := range s.Products {
+		prodString := cstringFromComponent(p.Component) + "@" + p.Supplier
+		for _, sc := range p.Subcomponents {
+			prodString += cstringFromComponent(sc.Component) + "@" + sc.Supplier
+		}
+		for _, a := range p.Artifacts {
+			prodString += cstringFromComponent(a) + "@" + a.Supplier
+		}
+		prods = append(prods, prodString)
+	}
+	sort.Strings(prods)
+	return cString + strings.Join(prods, ":")
+}
@@ -6,7 +6,11 @@ SPDX-License-Identifier: Apache-2.0
 package vex
 
 import (
+	"bytes"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -95,3 +99,320 @@ func TestMergeDocumentsWithOptions(t *testing.T) {
 		require.Equal(t, doc.Statements, tc.expectedDoc.Statements)
 	}
 }
+
+func TestDelta(t *testing.T) {
+	ts1 := time.Date(2023, 4, 17, 20, 34, 58, 0, time.UTC)
+	ts2 := time.Date(2023, 4, 18, 20, 34, 58, 0, time.UTC)
+
+	oldDoc := &VEX{
+		Metadata: Metadata{
+			Author:    "Wolfi J Inkinson",
+			Timestamp: &ts1,
+			Version:   1,
+		},
+		Statements: []Statement{
+			{
+				Vulnerability: Vulnerability{Name: "CVE-2014-123456"},
+				Products:      []Product{{Component: Component{ID: "pkg:deb/pkg@1.0"}}},
+				Status:        StatusUnderInvestigation,
+			},
+			{
+				Vulnerability: Vulnerability{Name: "CVE-2014-654321"},
+				Products:      []Product{{Component: Component{ID: "pkg:deb/pkg@1.0"}}},
+				Status:        StatusNotAffected,
+				Justification: ComponentNotPresent,
+			},
+		},
+	}
+
+	newDoc := &VEX{
+		Metadata: Metadata{
+			Author:    "Wolfi J Inkinson",
+			Timestamp: &ts2,
+			Version:   2,
+		},
+		Statements: []Statement{
+			// Unchanged, only the inherited date changes
+			oldDoc.Statements[1],
+			// Status changed
+			{
+				Vulnerability: Vulnerability{Name: "CVE-2014-123456"},
+				Products:      []Product{{Component: Component{ID: "pkg:deb/pkg@1.0"}}},
+				Status:        StatusFixed,
+			},
+			// New statement
+			{
+				Vulnerability:   Vulnerability{Name: "CVE-2014-111111"},
+				Timestamp:       &ts1,
+				Products:        []Product{{Component: Component{ID: "pkg:deb/pkg@1.0"}}},
+				Status:          StatusAffected,
+				ActionStatement: "Update to 2.0",
+			},
+		},
+	}
+
+	delta, err := Delta(oldDoc, newDoc)
+	require.NoError(t, err)
+	require.Equal(t, 2, delta.Version)
+	require.Equal(t, "Wolfi J Inkinson", delta.Author)
+	require.Equal(t, &ts2, delta.Timestamp)
+	require.NotEmpty(t, delta.ID)
+	require.Len(t, delta.Statements, 2)
+	for _, s := range delta.Statements {
+		require.NotNil(t, s.Timestamp)
+		switch s.Vulnerability.Name {
+		case "CVE-2014-123456":
+			require.Equal(t, StatusFixed, s.Status)
+			require.Equal(t, ts2, *s.Timestamp)
+		case "CVE-2014-111111":
+			require.Equal(t, ts1, *s.Timestamp)
+		default:
+			t.Fatalf("unexpected statement in delta: %s", s.Vulnerability.Name)
+		}
+	}
+
+	// Without an old document, all statements are returned
+	delta, err = Delta(nil, newDoc)
+	require.NoError(t, err)
+	require.Len(t, delta.Statements, 3)
+
+	// Changes to the statement metadata are part of the delta
+	newerDoc := *newDoc
+	newerDoc.Version = 3
+	newerDoc.Statements = []Statement{*newDoc.Statements[0].DeepCopy()}
+	newerDoc.Statements[0].Annotations = map[string]string{"example.com/ticket": "SEC-1234"}
+	newerDoc.Statements[0].Credits = []Credit{{Organization: "Wolfi"}}
+	delta, err = Delta(newDoc, &newerDoc)
+	require.NoError(t, err)
+	require.Len(t, delta.Statements, 1)
+
+	// Version must move forward
+	_, err = Delta(newDoc, oldDoc)
+	require.Error(t, err)
+
+	_, err = Delta(oldDoc, nil)
+	require.Error(t, err)
+}
+
+func TestMergeUnifyAliases(t *testing.T) {
+	ts := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+	product := []Product{{Component: Component{ID: "pkg:golang/golang.org/x/net@v0.17.0"}}}
+
+	doc1 := New()
+	doc1.ID = "doc1"
+	doc1.Statements = []Statement{
+		{
+			Vulnerability: Vulnerability{Name: "CVE-2023-44487", Aliases: []VulnerabilityID{"GO-2023-2102"}},
+			Products:      product,
+			Status:        StatusAffected,
+			Timestamp:     &ts,
+		},
+	}
+
+	doc2 := New()
+	doc2.ID = "doc2"
+	doc2.Statements = []Statement{
+		{
+			Vulnerability: Vulnerability{Name: "GHSA-qppj-fm5r-hxr3", Aliases: []VulnerabilityID{"https://nvd.nist.gov/vuln/detail/CVE-2023-44487"}},
+			Products:      product,
+			Status:        StatusAffected,
+			Timestamp:     &ts,
+		},
+		{
+			Vulnerability: Vulnerability{Name: "GHSA-xxxx-yyyy-zzzz"},
+			Products:      product,
+			Status:        StatusFixed,
+			Timestamp:     &ts,
+		},
+	}
+
+	// Without the option the statements are kept as they are
+	merged, err := MergeDocumentsWithOptions(&MergeOptions{}, []*VEX{&doc1, &doc2})
+	require.NoError(t, err)
+	require.Len(t, merged.Statements, 3)
+
+	merged, err = MergeDocumentsWithOptions(&MergeOptions{UnifyAliases: true}, []*VEX{&doc1, &doc2})
+	require.NoError(t, err)
+	require.Len(t, merged.Statements, 2)
+
+	stmt := merged.StatementsByVulnerability("GHSA-qppj-fm5r-hxr3")
+	require.Len(t, stmt, 1)
+	require.Equal(t, VulnerabilityID("CVE-2023-44487"), stmt[0].Vulnerability.Name)
+	require.Equal(t, []VulnerabilityID{"GHSA-qppj-fm5r-hxr3", "GO-2023-2102"}, stmt[0].Vulnerability.Aliases)
+
+	stmt = merged.StatementsByVulnerability("GHSA-xxxx-yyyy-zzzz")
+	require.Len(t, stmt, 1)
+	require.Empty(t, stmt[0].Vulnerability.Aliases)
+
+	// Source documents are not modified
+	require.Equal(t, VulnerabilityID("GHSA-qppj-fm5r-hxr3"), doc2.Statements[0].Vulnerability.Name)
+
+	// The same claim by different authors keeps both attributions
+	doc1.Author = "Upstream Project"
+	doc2.Author = "Vendor PSIRT"
+	merged, err = MergeDocumentsWithOptions(&MergeOptions{UnifyAliases: true, Mode: MergeModeAttributed}, []*VEX{&doc1, &doc2})
+	require.NoError(t, err)
+	stmt = merged.StatementsByVulnerability("CVE-2023-44487")
+	require.Len(t, stmt, 2)
+	authors := []string{}
+	for i := range stmt {
+		author, _ := merged.StatementAuthor(&stmt[i])
+		authors = append(authors, author)
+	}
+	require.ElementsMatch(t, []string{"Upstream Project", "Vendor PSIRT"}, authors)
+}
+
+func TestDryRunMerge(t *testing.T) {
+	ts := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+	newDoc := func(id string, status Status) *VEX {
+		doc := New()
+		doc.ID = id
+		doc.Timestamp = &ts
+		doc.Statements = []Statement{
+			{
+				Vulnerability: Vulnerability{Name: VulnerabilityID("CVE-2023-" + id)},
+				Products:      []Product{{Component: Component{ID: "pkg:generic/test@1.0"}}},
+				Status:        status,
+				Timestamp:     &ts,
+			},
+		}
+		return &doc
+	}
+	opts := &MergeOptions{DocumentID: "https://example.com/vex/merged", Clock: FixedClock(ts)}
+
+	// Without a current document all the statements are added
+	diff, err := DryRunMerge(opts, nil, []*VEX{newDoc("0001", StatusAffected)})
+	require.NoError(t, err)
+	require.Len(t, diff.Added, 1)
+
+	current, err := MergeDocumentsWithOptions(opts, []*VEX{newDoc("0001", StatusAffected)})
+	require.NoError(t, err)
+	sources := []*VEX{newDoc("0001", StatusAffected), newDoc("0002", StatusUnderInvestigation)}
+	diff, err = DryRunMerge(opts, current, sources)
+	require.NoError(t, err)
+	require.Len(t, diff.Added, 1)
+	require.Equal(t, VulnerabilityID("CVE-2023-0002"), diff.Added[0].Vulnerability.Name)
+	require.Empty(t, diff.Removed)
+	require.Empty(t, diff.Changed)
+	require.Len(t, current.Statements, 1)
+
+	_, err = DryRunMerge(opts, current, nil)
+	require.Error(t, err)
+}
+
+func TestMergeStatementAuthors(t *testing.T) {
+	ts := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+	newDoc := func(id, author, role string) *VEX {
+		doc := New()
+		doc.ID = id
+		doc.Author = author
+		doc.AuthorRole = role
+		doc.Statements = []Statement{
+			{
+				Vulnerability: Vulnerability{Name: VulnerabilityID("CVE-2023-" + id)},
+				Products:      []Product{{Component: Component{ID: "pkg:generic/test@1.0"}}},
+				Status:        StatusUnderInvestigation,
+				Timestamp:     &ts,
+			},
+		}
+		return &doc
+	}
+
+	upstream := newDoc("0001", "Upstream Project", "Maintainer")
+	vendor := newDoc("0002", "Vendor PSIRT", "Supplier")
+	delegated := newDoc("0003", "Vendor PSIRT", "Supplier")
+	delegated.Statements[0].Author = "Security Researcher"
+
+	merged, err := MergeDocumentsWithOptions(&MergeOptions{Author: "Vendor PSIRT", AuthorRole: "Supplier", Mode: MergeModeAttributed}, []*VEX{upstream, vendor, delegated})
+	require.NoError(t, err)
+
+	authors := map[VulnerabilityID][2]string{}
+	for i := range merged.Statements {
+		author, role := merged.StatementAuthor(&merged.Statements[i])
+		authors[merged.Statements[i].Vulnerability.Name] = [2]string{author, role}
+	}
+	require.Equal(t, map[VulnerabilityID][2]string{
+		"CVE-2023-0001": {"Upstream Project", "Maintainer"},
+		"CVE-2023-0002": {"Vendor PSIRT", "Supplier"},
+		"CVE-2023-0003": {"Security Researcher", ""},
+	}, authors)
+
+	// Statements from the merged document author are not attributed
+	for i := range merged.Statements {
+		if merged.Statements[i].Vulnerability.Name == "CVE-2023-0002" {
+			require.Empty(t, merged.Statements[i].Author)
+		}
+	}
+
+	// Documents sharing an author produce a document by the same author
+	merged, err = MergeDocumentsWithOptions(&MergeOptions{Mode: MergeModeAttributed}, []*VEX{vendor, newDoc("0004", "Vendor PSIRT", "Supplier")})
+	require.NoError(t, err)
+	require.Equal(t, "Vendor PSIRT", merged.Author)
+	require.Equal(t, "Supplier", merged.AuthorRole)
+	for i := range merged.Statements {
+		require.Empty(t, merged.Statements[i].Author)
+	}
+}
+
+func TestMergeCompatGolden(t *testing.T) {
+	t.Setenv("SOURCE_DATE_EPOCH", "2023-02-01T00:00:00Z")
+
+	docs := []*VEX{}
+	for _, path := range []string{
+		"testdata/v020-1.vex.json",
+		"testdata/v020-2.vex.json",
+		"testdata/merge-vendor.vex.json",
+	} {
+		doc, err := Open(path)
+		require.NoError(t, err)
+		docs = append(docs, doc)
+	}
+
+	for name, tc := range map[string]struct {
+		opts   MergeOptions
+		golden string
+	}{
+		"all statements": {
+			opts:   MergeOptions{Mode: MergeModeCompat},
+			golden: "merge-compat-all.json",
+		},
+		"filtered": {
+			opts: MergeOptions{
+				Mode:            MergeModeCompat,
+				Author:          "Aggregator",
+				AuthorRole:      "Distributor",
+				Vulnerabilities: []string{"CVE-1234-5678"},
+			},
+			golden: "merge-compat-filtered.json",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			opts := tc.opts
+			merged, err := MergeDocumentsWithOptions(&opts, docs)
+			require.NoError(t, err)
+
+			var b bytes.Buffer
+			require.NoError(t, merged.ToJSON(&b))
+
+			expected, err := os.ReadFile(filepath.Join("testdata", "golden", tc.golden))
+			require.NoError(t, err)
+			require.Equal(t, string(expected), b.String())
+
+			// The zero mode is the compatibility mode
+			opts.Mode = 0
+			merged, err = MergeDocumentsWithOptions(&opts, docs)
+			require.NoError(t, err)
+			b.Reset()
+			require.NoError(t, merged.ToJSON(&b))
+			require.Equal(t, string(expected), b.String())
+
+			// The attributed mode attributes the vendor statement to its author
+			opts.Mode = MergeModeAttributed
+			merged, err = MergeDocumentsWithOptions(&opts, docs)
+			require.NoError(t, err)
+			b.Reset()
+			require.NoError(t, merged.ToJSON(&b))
+			require.NotEqual(t, string(expected), b.String())
+		})
+	}
+}
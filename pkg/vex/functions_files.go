@@ -5,16 +5,32 @@ package vex
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log/slog"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
-	"gopkg.in/yaml.v3"
-
 	"github.com/openvex/go-vex/pkg/csaf"
+	"github.com/openvex/go-vex/pkg/cyclonedx"
+	"github.com/openvex/go-vex/pkg/tracing"
+)
+
+const (
+	// AnnotationCycloneDXState preserves the analysis state of statements
+	// imported from CycloneDX documents.
+	AnnotationCycloneDXState = "cyclonedx.org/analysis-state"
+
+	// AnnotationCycloneDXJustification preserves the analysis justification
+	// of statements imported from CycloneDX documents.
+	AnnotationCycloneDXJustification = "cyclonedx.org/analysis-justification"
+
+	// AnnotationCycloneDXResponse preserves the comma separated analysis
+	// responses of statements imported from CycloneDX documents.
+	AnnotationCycloneDXResponse = "cyclonedx.org/analysis-response"
 )
 
 // Load reads the VEX document file at the given path and returns a decoded VEX
@@ -31,24 +47,56 @@ func Load(path string) (*VEX, error) {
 
 // Parse parses an OpenVEX document in the latest version from the data byte array.
 func Parse(data []byte) (*VEX, error) {
-	vexDoc := &VEX{}
-	if err := json.Unmarshal(data, vexDoc); err != nil {
+	return ParseContext(context.Background(), data)
+}
+
+// ParseContext works like Parse but reports a span to the tracer in the
+// context, see the tracing package.
+func ParseContext(ctx context.Context, data []byte) (vexDoc *VEX, err error) {
+	_, span := tracing.Start(ctx, "vex.Parse", tracing.Attr("size", strconv.Itoa(len(data))))
+	defer func() { span.End(err) }()
+
+	vexDoc = &VEX{}
+	if err = decodeJSON(data, vexDoc); err != nil {
 		return nil, fmt.Errorf("%s: %w", errMsgParse, err)
 	}
 	return vexDoc, nil
 }
 
-// OpenYAML opens a VEX file in YAML format.
+// SourceDocument is a VEX document parsed along with the original bytes it
+// was parsed from.
+type SourceDocument struct {
+	*VEX
+
+	// source holds the data passed to ParseWithSource
+	source []byte
+}
+
+// ParseWithSource works like Parse but keeps a copy of the original data
+// next to the document. Use it when parsing signed documents to be able to
+// verify or forward the signed bytes after using the parsed document.
+func ParseWithSource(data []byte) (*SourceDocument, error) {
+	vexDoc, err := Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	return &SourceDocument{VEX: vexDoc, source: bytes.Clone(data)}, nil
+}
+
+// RawSource returns a copy of the original bytes of the document. The raw
+// source is not updated when the document is modified, it always holds the
+// data as it was received.
+func (sd *SourceDocument) RawSource() []byte {
+	return bytes.Clone(sd.source)
+}
+
+// OpenYAML opens a VEX file in YAML format. See ParseYAML.
 func OpenYAML(path string) (*VEX, error) {
 	data, err := os.ReadFile(path) //nolint:gosec // This is supposed to open user-specified paths
 	if err != nil {
 		return nil, fmt.Errorf("opening YAML file: %w", err)
 	}
-	vexDoc := New()
-	if err := yaml.Unmarshal(data, &vexDoc); err != nil {
-		return nil, fmt.Errorf("unmarshalling VEX data: %w", err)
-	}
-	return &vexDoc, nil
+	return ParseYAML(data)
 }
 
 // OpenJSON opens an OpenVEX file in JSON format.
@@ -58,7 +106,7 @@ func OpenJSON(path string) (*VEX, error) {
 		return nil, fmt.Errorf("opening JSON file: %w", err)
 	}
 	vexDoc := New()
-	if err := json.Unmarshal(data, &vexDoc); err != nil {
+	if err := decodeJSON(data, &vexDoc); err != nil {
 		return nil, fmt.Errorf("unmarshalling VEX data: %w", err)
 	}
 	return &vexDoc, nil
@@ -80,53 +128,167 @@ func parseContext(rawDoc []byte) (string, error) {
 	return "", nil
 }
 
-// Open tries to autodetect the vex format and open it
-func Open(path string) (*VEX, error) {
-	data, err := os.ReadFile(path) //nolint:gosec // This is supposed to open user-specified paths
+// ParseVersioned parses an OpenVEX document of any of the supported spec
+// versions. The version is detected from the @context of the document and
+// documents in older versions are upgraded to the current model.
+//
+// Documents in unknown versions, and documents without an OpenVEX context,
+// are parsed as the current version. Use ParseStrict to reject them.
+func ParseVersioned(data []byte) (*VEX, error) {
+	documentContextLocator, err := parseContext(data)
 	if err != nil {
-		return nil, fmt.Errorf("opening VEX file: %w", err)
+		return nil, err
+	}
+	if documentContextLocator == "" {
+		return Parse(data)
 	}
+	return parseVersion(data, documentContextLocator, false)
+}
 
+// ParseStrict works like ParseVersioned but returns an error if the document
+// does not have an OpenVEX context or its spec version is not supported.
+func ParseStrict(data []byte) (*VEX, error) {
 	documentContextLocator, err := parseContext(data)
 	if err != nil {
 		return nil, err
 	}
+	if documentContextLocator == "" {
+		return nil, errors.New("document does not have an OpenVEX context")
+	}
+	return parseVersion(data, documentContextLocator, true)
+}
 
+// parseVersion parses the document with the parser of the spec version in
+// its context locator. When strict is false, documents in unknown versions
+// are parsed as the current version.
+func parseVersion(data []byte, documentContextLocator string, strict bool) (*VEX, error) {
 	if documentContextLocator == ContextLocator() {
 		return Parse(data)
-	} else if documentContextLocator != "" {
-		version := strings.TrimPrefix(documentContextLocator, Context)
-		version = strings.TrimPrefix(version, "/")
+	}
 
-		// If version is nil, then we assume v0.0.1
-		if version == "" {
-			version = "v0.0.1"
-		}
+	version := strings.TrimPrefix(documentContextLocator, Context)
+	version = strings.TrimPrefix(version, "/")
+
+	// If version is nil, then we assume v0.0.1
+	if version == "" {
+		version = "v0.0.1"
+	}
 
-		parser := getLegacyVersionParser(version)
-		if parser == nil {
-			return nil, fmt.Errorf("unable to get parser for version %s", version)
+	parser := getLegacyVersionParser(version)
+	if parser == nil {
+		if !strict {
+			return Parse(data)
 		}
+		return nil, fmt.Errorf("unable to get parser for version %s", version)
+	}
 
-		doc, err := parser(data)
+	doc, err := parser(data)
+	if err != nil {
+		return nil, fmt.Errorf("parsing document: %w", err)
+	}
+
+	return doc, nil
+}
+
+// Open tries to autodetect the vex format and open it. It works like OpenAny
+// except for CSAF documents, which are opened with OpenCSAF and only list
+// the products with identification helpers. Use OpenAny to convert them
+// with FromCSAF.
+func Open(path string) (*VEX, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // This is supposed to open user-specified paths
+	if err != nil {
+		return nil, fmt.Errorf("opening VEX file: %w", err)
+	}
+
+	if isCSAF(data) {
+		doc, err := OpenCSAF(path, []string{})
 		if err != nil {
-			return nil, fmt.Errorf("parsing document: %w", err)
+			return nil, fmt.Errorf("attempting to open csaf doc: %w", err)
 		}
-
 		return doc, nil
 	}
 
-	if bytes.Contains(data, []byte(`"csaf_version"`)) {
-		slog.Info("Abriendo CSAF")
+	doc, err := ParseAny(data)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	return doc, nil
+}
 
-		doc, err := OpenCSAF(path, []string{})
+// OpenAny opens a VEX document in any of the formats supported by ParseAny.
+func OpenAny(path string) (*VEX, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // This is supposed to open user-specified paths
+	if err != nil {
+		return nil, fmt.Errorf("opening VEX file: %w", err)
+	}
+
+	doc, err := ParseAny(data)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	return doc, nil
+}
+
+// ParseAny detects the format of a VEX document and parses it into a VEX
+// object. It supports OpenVEX documents in any of the supported spec versions
+// (see ParseStrict), CycloneDX documents (see FromCycloneDX) and CSAF VEX
+// documents (see FromCSAF).
+func ParseAny(data []byte) (*VEX, error) {
+	format, documentContextLocator, err := detectFormat(data)
+	if err != nil {
+		return nil, err
+	}
+
+	switch format {
+	case FormatOpenVEX:
+		return parseVersion(data, documentContextLocator, true)
+	case formatCycloneDX:
+		doc, err := ParseCycloneDX(data)
+		if err != nil {
+			return nil, fmt.Errorf("attempting to open cyclonedx doc: %w", err)
+		}
+		return doc, nil
+	default:
+		csafDoc, err := csaf.Parse(data)
+		if err != nil {
+			return nil, fmt.Errorf("attempting to open csaf doc: %w", err)
+		}
+		doc, err := FromCSAF(csafDoc)
 		if err != nil {
 			return nil, fmt.Errorf("attempting to open csaf doc: %w", err)
 		}
 		return doc, nil
 	}
+}
+
+// formatCycloneDX identifies CycloneDX documents in the format detection of
+// ParseAny. The Matcher does not support them.
+const formatCycloneDX DocumentFormat = "cyclonedx"
 
-	return nil, fmt.Errorf("unable to detect document format reading %s", path)
+// detectFormat returns the format of the document as detected by ParseAny
+// and, for OpenVEX documents, their context locator.
+func detectFormat(data []byte) (DocumentFormat, string, error) {
+	documentContextLocator, err := parseContext(data)
+	if err != nil {
+		return "", "", err
+	}
+
+	switch {
+	case documentContextLocator != "":
+		return FormatOpenVEX, documentContextLocator, nil
+	case bytes.Contains(data, []byte(`"bomFormat"`)):
+		return formatCycloneDX, "", nil
+	case bytes.Contains(data, []byte(`"csaf_version"`)):
+		return FormatCSAF, "", nil
+	default:
+		return "", "", errors.New("unable to detect document format")
+	}
+}
+
+// isCSAF returns true if ParseAny detects the data as a CSAF document.
+func isCSAF(data []byte) bool {
+	format, _, err := detectFormat(data)
+	return err == nil && format == FormatCSAF
 }
 
 // OpenCSAF opens a CSAF document and builds a VEX object from it.
@@ -171,6 +333,7 @@ func OpenCSAF(path string, products []string) (*VEX, error) {
 			Author:     "",
 			AuthorRole: "",
 			Timestamp:  &time.Time{},
+			Lang:       csafDoc.Document.Lang,
 		},
 		Statements: []Statement{},
 	}
@@ -198,6 +361,8 @@ func OpenCSAF(path string, products []string) (*VEX, error) {
 
 					v.Statements = append(v.Statements, Statement{
 						Vulnerability:   Vulnerability{Name: VulnerabilityID(csafDoc.Vulnerabilities[i].CVE)},
+						References:      referencesFromCSAF(csafDoc, &csafDoc.Vulnerabilities[i]),
+						Credits:         creditsFromCSAF(csafDoc, &csafDoc.Vulnerabilities[i]),
 						Status:          StatusFromCSAF(status),
 						Justification:   "", // Justifications are not machine readable in csaf, it seems
 						ActionStatement: just,
@@ -217,6 +382,234 @@ func OpenCSAF(path string, products []string) (*VEX, error) {
 	return v, nil
 }
 
+// OpenCycloneDX opens a CycloneDX document and builds a VEX object from the
+// analysis of its vulnerabilities. See FromCycloneDX for details.
+func OpenCycloneDX(path string) (*VEX, error) {
+	bom, err := cyclonedx.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening cyclonedx doc: %w", err)
+	}
+	return FromCycloneDX(bom)
+}
+
+// ParseCycloneDX parses the data of a CycloneDX document and builds a VEX
+// object from the analysis of its vulnerabilities. See FromCycloneDX for
+// details.
+func ParseCycloneDX(data []byte) (*VEX, error) {
+	bom, err := cyclonedx.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("parsing cyclonedx doc: %w", err)
+	}
+	return FromCycloneDX(bom)
+}
+
+// FromCycloneDX builds a VEX object from the analysis of the vulnerabilities
+// in a CycloneDX BOM. Affected components nested in the BOM metadata component
+// are listed as its subcomponents. As the CycloneDX analysis states and
+// justifications are richer than their OpenVEX counterparts, the original
+// values are preserved in the statement annotations.
+func FromCycloneDX(bom *cyclonedx.BOM) (*VEX, error) {
+	components := map[string]Component{}
+	bomComponents := bom.ListComponents()
+	for i := range bomComponents {
+		if bomComponents[i].BOMRef != "" {
+			components[bomComponents[i].BOMRef] = ComponentFromCycloneDX(&bomComponents[i])
+		}
+	}
+
+	var root *Component
+	if bom.Metadata.Component != nil {
+		c := ComponentFromCycloneDX(bom.Metadata.Component)
+		root = &c
+	}
+
+	v := &VEX{
+		Metadata: Metadata{
+			ID:        bom.SerialNumber,
+			Timestamp: bom.Metadata.Timestamp,
+			Version:   bom.Version,
+		},
+		Statements: []Statement{},
+	}
+	if v.Timestamp == nil {
+		v.Timestamp = &time.Time{}
+	}
+	if bom.Metadata.Supplier != nil {
+		author := Author{Name: bom.Metadata.Supplier.Name}
+		if len(bom.Metadata.Supplier.URL) > 0 {
+			author.URI = bom.Metadata.Supplier.URL[0]
+		}
+		v.Author = author.String()
+	}
+
+	for i := range bom.Vulnerabilities {
+		cv := &bom.Vulnerabilities[i]
+		stmt := Statement{
+			Vulnerability: Vulnerability{
+				Name:        VulnerabilityID(cv.ID),
+				Description: cv.Description,
+				Ratings:     ratingsFromCycloneDX(cv.Ratings),
+			},
+			Timestamp: cv.Updated,
+			Status:    StatusUnderInvestigation,
+		}
+		if cv.Source != nil && cv.Source.URL != "" {
+			stmt.References = append(stmt.References, Reference{Category: "external", Summary: cv.Source.Name, URL: cv.Source.URL})
+		}
+		for _, r := range cv.References {
+			stmt.Vulnerability.Aliases = append(stmt.Vulnerability.Aliases, VulnerabilityID(r.ID))
+			if r.Source != nil && r.Source.URL != "" {
+				stmt.References = append(stmt.References, Reference{Category: "external", Summary: r.Source.Name, URL: r.Source.URL})
+			}
+		}
+
+		if a := cv.Analysis; a != nil {
+			stmt.Status = StatusFromCycloneDX(a.State)
+			if stmt.Status == "" {
+				return nil, fmt.Errorf("invalid analysis state %q in %s", a.State, cv.ID)
+			}
+			stmt.Annotations = cycloneDXAnnotations(a)
+			if a.LastUpdated != nil {
+				stmt.Timestamp = a.LastUpdated
+			}
+
+			switch stmt.Status {
+			case StatusNotAffected:
+				stmt.Justification = JustificationFromCycloneDX(a.Justification)
+				stmt.ImpactStatement = a.Detail
+				if stmt.Justification == "" && stmt.ImpactStatement == "" {
+					stmt.ImpactStatement = fmt.Sprintf("CycloneDX analysis: %s %s", a.State, a.Justification)
+				}
+			case StatusAffected:
+				stmt.ActionStatement = a.Detail
+				stmt.Remediations = remediationsFromCycloneDX(a.Response)
+				if stmt.ActionStatement == "" && len(a.Response) > 0 {
+					stmt.ActionStatement = "Response: " + strings.Join(a.Response, ", ")
+				}
+				if stmt.ActionStatement == "" {
+					stmt.ActionStatement = NoActionStatementMsg
+				}
+			default:
+				stmt.StatusNotes = a.Detail
+			}
+		}
+
+		for _, affect := range cv.Affects {
+			// BOM-Links reference the component after the fragment separator
+			ref := affect.Ref
+			if _, fragment, ok := strings.Cut(ref, "#"); ok && strings.HasPrefix(ref, "urn:cdx:") {
+				ref = fragment
+			}
+
+			c, ok := components[ref]
+			if !ok {
+				c = Component{ID: ref}
+			}
+
+			if root != nil && ref != bom.Metadata.Component.BOMRef {
+				stmt.Products = append(stmt.Products, Product{
+					Component:     *root,
+					Subcomponents: []Subcomponent{{Component: c}},
+				})
+				continue
+			}
+			stmt.Products = append(stmt.Products, Product{Component: c})
+		}
+
+		v.Statements = append(v.Statements, stmt)
+	}
+
+	return v, nil
+}
+
+// cycloneDXAnnotations returns the annotations preserving the original
+// CycloneDX analysis data of a statement.
+func cycloneDXAnnotations(a *cyclonedx.Analysis) map[string]string {
+	ret := map[string]string{}
+	if a.State != "" {
+		ret[AnnotationCycloneDXState] = a.State
+	}
+	if a.Justification != "" {
+		ret[AnnotationCycloneDXJustification] = a.Justification
+	}
+	if len(a.Response) > 0 {
+		ret[AnnotationCycloneDXResponse] = strings.Join(a.Response, ",")
+	}
+	if len(ret) == 0 {
+		return nil
+	}
+	return ret
+}
+
+// ComponentFromCycloneDX converts a CycloneDX component into a VEX
+// component. The purl is used as the component ID when defined.
+func ComponentFromCycloneDX(c *cyclonedx.Component) Component {
+	ret := Component{ID: c.Purl}
+	if ret.ID == "" {
+		ret.ID = c.BOMRef
+	}
+
+	if c.Purl != "" || c.CPE != "" {
+		ret.Identifiers = map[IdentifierType]string{}
+		if c.Purl != "" {
+			ret.Identifiers[PURL] = c.Purl
+		}
+		if strings.HasPrefix(c.CPE, "cpe:2.3:") {
+			ret.Identifiers[CPE23] = c.CPE
+		} else if c.CPE != "" {
+			ret.Identifiers[CPE22] = c.CPE
+		}
+	}
+
+	for _, h := range c.Hashes {
+		algo := Algorithm(strings.ToLower(h.Algorithm))
+		if algo == "sha-1" {
+			algo = SHA1
+		}
+		if !algo.Valid() {
+			continue
+		}
+		if ret.Hashes == nil {
+			ret.Hashes = map[Algorithm]Hash{}
+		}
+		ret.Hashes[algo] = Hash(h.Content)
+	}
+	return ret
+}
+
+// referencesFromCSAF returns the references of a CSAF vulnerability followed
+// by the references of the whole document.
+func referencesFromCSAF(csafDoc *csaf.CSAF, vuln *csaf.Vulnerability) []Reference {
+	var ret []Reference
+	for _, refs := range [][]csaf.Reference{vuln.References, csafDoc.Document.References} {
+		for _, r := range refs {
+			ret = append(ret, Reference{
+				Category: r.Category,
+				Summary:  r.Summary,
+				URL:      r.URL,
+			})
+		}
+	}
+	return ret
+}
+
+// creditsFromCSAF returns the acknowledgments of a CSAF vulnerability
+// followed by the acknowledgments of the whole document.
+func creditsFromCSAF(csafDoc *csaf.CSAF, vuln *csaf.Vulnerability) []Credit {
+	var ret []Credit
+	for _, acks := range [][]csaf.Acknowledgment{vuln.Acknowledgments, csafDoc.Document.Acknowledgments} {
+		for _, a := range acks {
+			ret = append(ret, Credit{
+				Names:        a.Names,
+				Organization: a.Organization,
+				Summary:      a.Summary,
+				URLs:         a.URLs,
+			})
+		}
+	}
+	return ret
+}
+
 // MergeFilesWithOptions opens a list of vex documents and after parsing them
 // merges them into a single file using the specified merge options.
 func MergeFilesWithOptions(mergeOpts *MergeOptions, filePaths []string) (*VEX, error) {
@@ -63,6 +63,14 @@ func TestLoadCSAF(t *testing.T) {
 	require.Equal(t, "CVE-2009-4487", string(vexDoc.Statements[0].Vulnerability.Name))
 	require.Equal(t, StatusNotAffected, vexDoc.Statements[0].Status)
 	require.Equal(t, "2022-EVD-UC-01-NA-001", vexDoc.ID)
+
+	require.Equal(t, []Reference{
+		{Category: "external", Summary: "CVE-2009-4487", URL: "https://nvd.nist.gov/vuln/detail/CVE-2009-4487"},
+	}, vexDoc.Statements[0].References)
+	require.Equal(t, []Credit{
+		{Names: []string{"Jane Doe"}, Summary: "Reported the vulnerability."},
+		{Organization: "Example Company PSIRT", Summary: "Coordinated the disclosure."},
+	}, vexDoc.Statements[0].Credits)
 }
 
 func TestOpenCSAF(t *testing.T) {
@@ -78,9 +86,75 @@ func TestOpenCSAF(t *testing.T) {
 		require.NoError(t, err)
 		require.NotNil(t, doc)
 		require.Len(t, doc.Statements, tc.len)
+		require.Equal(t, "en", doc.Lang)
 	}
 }
 
+func TestOpenCycloneDX(t *testing.T) {
+	doc, err := OpenCycloneDX("testdata/cyclonedx.json")
+	require.NoError(t, err)
+	require.Equal(t, "urn:uuid:3e671687-395b-41f5-a30f-a58921a69b79", doc.ID)
+	require.Equal(t, "Example Company", doc.Author)
+	require.Len(t, doc.Statements, 2)
+
+	stmt := doc.Statements[0]
+	require.Equal(t, StatusNotAffected, stmt.Status)
+	require.Equal(t, VulnerableCodeNotInExecutePath, stmt.Justification)
+	require.Equal(t, "not_affected", stmt.Annotations[AnnotationCycloneDXState])
+	require.Equal(t, "code_not_reachable", stmt.Annotations[AnnotationCycloneDXJustification])
+	require.Len(t, stmt.References, 2)
+	require.Equal(t, "https://nvd.nist.gov/vuln/detail/CVE-2020-25649", stmt.References[0].URL)
+	require.Equal(t, "The application never deserializes XML.", stmt.ImpactStatement)
+	require.Equal(t, []VulnerabilityID{"GHSA-288c-cq4h-88gq"}, stmt.Vulnerability.Aliases)
+	require.NotNil(t, stmt.Timestamp)
+	require.True(t, stmt.Matches(
+		"CVE-2020-25649", "pkg:generic/example-app@1.2.0",
+		[]string{"pkg:maven/com.fasterxml.jackson.core/jackson-databind@2.10.0"},
+	))
+
+	stmt = doc.Statements[1]
+	require.Equal(t, StatusAffected, stmt.Status)
+	require.Equal(t, "Update to 2.14.0", stmt.ActionStatement)
+	require.Len(t, stmt.Products, 1)
+	require.Empty(t, stmt.Products[0].Subcomponents)
+
+	for _, s := range doc.Statements {
+		require.NoError(t, s.Validate())
+	}
+}
+
+func TestParseWithSource(t *testing.T) {
+	data, err := os.ReadFile("testdata/v020-1.vex.json")
+	require.NoError(t, err)
+
+	doc, err := ParseWithSource(data)
+	require.NoError(t, err)
+	require.Equal(t, data, doc.RawSource())
+
+	// The raw source is not affected by changes to the document or the
+	// returned copies
+	doc.Author = "Someone Else"
+	raw := doc.RawSource()
+	raw[0] = 'X'
+	require.Equal(t, data, doc.RawSource())
+
+	_, err = ParseWithSource([]byte("invalid"))
+	require.Error(t, err)
+}
+
+func TestParseCycloneDX(t *testing.T) {
+	data, err := os.ReadFile("testdata/cyclonedx.json")
+	require.NoError(t, err)
+	doc, err := ParseCycloneDX(data)
+	require.NoError(t, err)
+	require.Len(t, doc.Statements, 2)
+	require.Equal(t, StatusAffected, doc.Statements[1].Status)
+	require.Equal(t, "exploitable", doc.Statements[1].Annotations[AnnotationCycloneDXState])
+
+	_, err = ParseCycloneDX([]byte(`{"bomFormat":"CycloneDX","vulnerabilities":[{"id":"CVE-1","analysis":{"state":"bogus"}}]}`))
+	require.Error(t, err)
+}
+
 func TestOpen(t *testing.T) {
 	for m, tc := range map[string]struct {
 		path      string
@@ -90,6 +164,7 @@ func TestOpen(t *testing.T) {
 		"OpenVEX v0.0.1 (no version)": {"testdata/v0.0.1-noversion.json", false},
 		"OpenVEX v0.2.0":              {"testdata/v0.2.0.json", false},
 		"CSAF document":               {"testdata/csaf.json", false},
+		"CycloneDX document":          {"testdata/cyclonedx.json", false},
 	} {
 		doc, err := Open(tc.path)
 		if tc.shouldErr {
@@ -101,3 +176,39 @@ func TestOpen(t *testing.T) {
 		require.NotNil(t, doc, m)
 	}
 }
+
+func TestParseAny(t *testing.T) {
+	for m, tc := range map[string]struct {
+		path       string
+		statements int
+	}{
+		"OpenVEX v0.0.1":     {"testdata/v0.0.1.json", 1},
+		"OpenVEX v0.2.0":     {"testdata/v0.2.0.json", 5},
+		"CSAF document":      {"testdata/csaf.json", 1},
+		"CycloneDX document": {"testdata/cyclonedx.json", 2},
+	} {
+		data, err := os.ReadFile(tc.path)
+		require.NoError(t, err, m)
+		doc, err := ParseAny(data)
+		require.NoError(t, err, m)
+		require.Len(t, doc.Statements, tc.statements, m)
+
+		opened, err := OpenAny(tc.path)
+		require.NoError(t, err, m)
+		require.Equal(t, doc, opened, m)
+	}
+
+	// Open keeps opening CSAF documents with OpenCSAF
+	opened, err := Open("testdata/csaf.json")
+	require.NoError(t, err)
+	csafDoc, err := OpenCSAF("testdata/csaf.json", []string{})
+	require.NoError(t, err)
+	require.Equal(t, csafDoc, opened)
+
+	_, err = ParseAny([]byte(`{"statements": []}`))
+	require.Error(t, err)
+	_, err = ParseAny([]byte(`not json`))
+	require.Error(t, err)
+	_, err = OpenAny("testdata/non-existent.json")
+	require.Error(t, err)
+}
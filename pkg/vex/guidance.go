@@ -0,0 +1,138 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+// justificationsData is the database of guidance about the justifications
+// defined in the spec.
+//
+//go:embed justifications.json
+var justificationsData []byte
+
+// Strength rates how conclusively a justification proves that a product is
+// not affected.
+type Strength string
+
+const (
+	StrengthStrong   Strength = "strong"
+	StrengthModerate Strength = "moderate"
+	StrengthWeak     Strength = "weak"
+)
+
+// JustificationGuidance is the metadata about how to use a justification.
+type JustificationGuidance struct {
+	// Statuses lists the statuses the justification can be used with.
+	Statuses []Status `json:"statuses"`
+
+	// Strength rates how conclusive the justification is.
+	Strength Strength `json:"strength"`
+
+	// RecommendedFields lists the JSON names of the statement fields that
+	// should be filled to support the justification.
+	RecommendedFields []string `json:"recommended_fields"`
+
+	// Subcomponents is true if the justification refers to code in the
+	// product subcomponents, which should be listed in the statement.
+	Subcomponents bool `json:"subcomponents"`
+
+	// CISA is the name of the justification in the CISA VEX status
+	// justifications guidance.
+	CISA string `json:"cisa"`
+
+	// Guidance is a short explanation of when to use the justification.
+	Guidance string `json:"guidance"`
+}
+
+// guidance is the parsed justification database.
+var guidance = func() map[Justification]JustificationGuidance {
+	db := map[Justification]JustificationGuidance{}
+	if err := json.Unmarshal(justificationsData, &db); err != nil {
+		panic(fmt.Sprintf("parsing justification guidance: %v", err))
+	}
+	return db
+}()
+
+// Guidance returns the metadata of the justification. The second value is
+// false if the justification is not one defined in the spec.
+func (j Justification) Guidance() (JustificationGuidance, bool) {
+	g, ok := guidance[j]
+	return g, ok
+}
+
+// CheckSeverity classifies the problems found by CheckJustification.
+type CheckSeverity string
+
+const (
+	// SeverityError flags statements that are invalid.
+	SeverityError CheckSeverity = "error"
+
+	// SeverityWarning flags statements that are valid but do not follow
+	// the justification guidance.
+	SeverityWarning CheckSeverity = "warning"
+)
+
+// CheckResult is a problem found by CheckJustification.
+type CheckResult struct {
+	Severity CheckSeverity
+	Message  string
+}
+
+// CheckJustification evaluates whether the statement justification is
+// appropriate for its status and products and returns the problems found.
+// An empty list means the statement follows the guidance.
+func (stmt *Statement) CheckJustification() []CheckResult {
+	results := []CheckResult{}
+	addResult := func(severity CheckSeverity, format string, args ...any) {
+		results = append(results, CheckResult{Severity: severity, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if stmt.Justification == "" {
+		if stmt.Status == StatusNotAffected {
+			if stmt.ImpactStatement == "" {
+				addResult(SeverityError, "status %q requires a justification or impact statement", stmt.Status)
+			} else {
+				addResult(SeverityWarning, "status %q should use a machine readable justification", stmt.Status)
+			}
+		}
+		return results
+	}
+
+	g, ok := stmt.Justification.Guidance()
+	if !ok {
+		addResult(SeverityError, "unknown justification %q", stmt.Justification)
+		return results
+	}
+
+	if !slices.Contains(g.Statuses, stmt.Status) {
+		addResult(SeverityError, "justification %q cannot be used with status %q", stmt.Justification, stmt.Status)
+		return results
+	}
+
+	for _, field := range g.RecommendedFields {
+		if field == "impact_statement" && stmt.ImpactStatement == "" {
+			addResult(SeverityWarning, "justification %q should be supported by an impact statement", stmt.Justification)
+		}
+	}
+
+	if g.Subcomponents {
+		hasSubcomponents := false
+		for i := range stmt.Products {
+			if len(stmt.Products[i].Subcomponents) > 0 {
+				hasSubcomponents = true
+				break
+			}
+		}
+		if !hasSubcomponents {
+			addResult(SeverityWarning, "justification %q refers to a subcomponent but the statement lists none", stmt.Justification)
+		}
+	}
+
+	return results
+}
@@ -0,0 +1,90 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJustificationGuidance(t *testing.T) {
+	for _, j := range Justifications() {
+		g, ok := Justification(j).Guidance()
+		require.True(t, ok, j)
+		require.Equal(t, []Status{StatusNotAffected}, g.Statuses, j)
+		require.NotEmpty(t, g.CISA, j)
+		require.NotEmpty(t, g.Guidance, j)
+	}
+
+	g, ok := ComponentNotPresent.Guidance()
+	require.True(t, ok)
+	require.Equal(t, StrengthStrong, g.Strength)
+
+	_, ok = Justification("made_up").Guidance()
+	require.False(t, ok)
+}
+
+func TestCheckJustification(t *testing.T) {
+	withSubcomponent := []Product{
+		{
+			Component:     Component{ID: "pkg:oci/image"},
+			Subcomponents: []Subcomponent{{Component{ID: "pkg:golang/example.com/lib@v1.0.0"}}},
+		},
+	}
+
+	for testCase, tc := range map[string]struct {
+		sut      Statement
+		expected []CheckSeverity
+	}{
+		"strong justification": {
+			sut:      Statement{Status: StatusNotAffected, Justification: ComponentNotPresent},
+			expected: []CheckSeverity{},
+		},
+		"missing justification": {
+			sut:      Statement{Status: StatusNotAffected},
+			expected: []CheckSeverity{SeverityError},
+		},
+		"impact statement only": {
+			sut:      Statement{Status: StatusNotAffected, ImpactStatement: "Not used"},
+			expected: []CheckSeverity{SeverityWarning},
+		},
+		"wrong status": {
+			sut:      Statement{Status: StatusAffected, Justification: ComponentNotPresent},
+			expected: []CheckSeverity{SeverityError},
+		},
+		"unknown justification": {
+			sut:      Statement{Status: StatusNotAffected, Justification: "made_up"},
+			expected: []CheckSeverity{SeverityError},
+		},
+		"weak justification without impact statement": {
+			sut:      Statement{Status: StatusNotAffected, Justification: InlineMitigationsAlreadyExist},
+			expected: []CheckSeverity{SeverityWarning},
+		},
+		"not in execute path without subcomponents": {
+			sut: Statement{
+				Status: StatusNotAffected, Justification: VulnerableCodeNotInExecutePath, ImpactStatement: "Not called",
+			},
+			expected: []CheckSeverity{SeverityWarning},
+		},
+		"not in execute path complete": {
+			sut: Statement{
+				Status: StatusNotAffected, Justification: VulnerableCodeNotInExecutePath, ImpactStatement: "Not called",
+				Products: withSubcomponent,
+			},
+			expected: []CheckSeverity{},
+		},
+		"affected without justification": {
+			sut:      Statement{Status: StatusAffected, ActionStatement: "Update"},
+			expected: []CheckSeverity{},
+		},
+	} {
+		severities := []CheckSeverity{}
+		for _, r := range tc.sut.CheckJustification() {
+			require.NotEmpty(t, r.Message, testCase)
+			severities = append(severities, r.Severity)
+		}
+		require.Equal(t, tc.expected, severities, testCase)
+	}
+}
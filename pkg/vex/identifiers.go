@@ -0,0 +1,228 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/package-url/packageurl-go"
+)
+
+// IdentifierTypeOptions defines the behavior of a software identifier type
+// that can be used in the Identifiers map of a component.
+type IdentifierTypeOptions struct {
+	// Validate checks that an identifier string is well formed. If nil, any
+	// string is considered valid.
+	Validate func(identifier string) error
+
+	// Matches is an optional function that returns true if an identifier in a
+	// component matches the identifier being queried. When not defined, only
+	// exact string matches are considered.
+	Matches func(componentIdentifier, identifier string) bool
+}
+
+// HashValidator is a function that checks that a hash value is well formed
+// for an algorithm.
+type HashValidator func(Hash) error
+
+var (
+	registryMutex   sync.RWMutex
+	identifierTypes = map[IdentifierType]IdentifierTypeOptions{
+		PURL: {
+			Validate: validatePurl,
+			Matches: func(componentIdentifier, identifier string) bool {
+				return strings.HasPrefix(identifier, "pkg:") && PurlMatches(componentIdentifier, identifier)
+			},
+		},
+		CPE22: {Validate: prefixValidator("cpe:/"), Matches: CPEMatches},
+		CPE23: {Validate: prefixValidator("cpe:2.3:"), Matches: CPEMatches},
+	}
+	algorithms = map[Algorithm]HashValidator{
+		MD5:        hexValidator(16),
+		SHA1:       hexValidator(20),
+		SHA256:     hexValidator(32),
+		SHA384:     hexValidator(48),
+		SHA512:     hexValidator(64),
+		SHA3224:    hexValidator(28),
+		SHA3256:    hexValidator(32),
+		SHA3384:    hexValidator(48),
+		SHA3512:    hexValidator(64),
+		BLAKE2S256: hexValidator(32),
+		BLAKE2B256: hexValidator(32),
+		BLAKE2B512: hexValidator(64),
+		BLAKE3:     hexValidator(0),
+	}
+)
+
+// RegisterIdentifierType registers a new software identifier type to extend
+// the identifiers understood by the library. Validation and matching of
+// components honor the functions defined in the registered options.
+// Registering an identifier type that already exists returns an error.
+func RegisterIdentifierType(t IdentifierType, opts IdentifierTypeOptions) error {
+	if t == "" {
+		return errors.New("identifier type cannot be empty")
+	}
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+	if _, ok := identifierTypes[t]; ok {
+		return fmt.Errorf("identifier type %q is already registered", t)
+	}
+	identifierTypes[t] = opts
+	return nil
+}
+
+// RegisterAlgorithm registers a new hash algorithm. The validator function
+// is optional, when nil any hash value is accepted. Registering an algorithm
+// that already exists returns an error.
+func RegisterAlgorithm(a Algorithm, validator HashValidator) error {
+	if a == "" {
+		return errors.New("algorithm cannot be empty")
+	}
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+	if _, ok := algorithms[a]; ok {
+		return fmt.Errorf("algorithm %q is already registered", a)
+	}
+	algorithms[a] = validator
+	return nil
+}
+
+// IdentifierTypes returns the sorted list of the registered identifier types.
+func IdentifierTypes() []string {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+	ret := []string{}
+	for t := range identifierTypes {
+		ret = append(ret, string(t))
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// Algorithms returns the sorted list of the registered hash algorithms.
+func Algorithms() []string {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+	ret := []string{}
+	for a := range algorithms {
+		ret = append(ret, string(a))
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// Valid returns true if the identifier type is registered.
+func (t IdentifierType) Valid() bool {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+	_, ok := identifierTypes[t]
+	return ok
+}
+
+// Validate checks that the identifier type is registered and that the
+// identifier string is valid for it.
+func (t IdentifierType) Validate(identifier string) error {
+	registryMutex.RLock()
+	opts, ok := identifierTypes[t]
+	registryMutex.RUnlock()
+	if !ok {
+		return fmt.Errorf("unknown identifier type %q, must be one of [%s]", t, strings.Join(IdentifierTypes(), ", "))
+	}
+	if opts.Validate == nil {
+		return nil
+	}
+	if err := opts.Validate(identifier); err != nil {
+		return fmt.Errorf("invalid %s identifier %q: %w", t, identifier, err)
+	}
+	return nil
+}
+
+// matches returns true if the component identifier of type t matches the
+// queried identifier using the type matcher, if it has one registered.
+func (t IdentifierType) matches(componentIdentifier, identifier string) bool {
+	registryMutex.RLock()
+	opts, ok := identifierTypes[t]
+	registryMutex.RUnlock()
+	if !ok || opts.Matches == nil {
+		return false
+	}
+	return opts.Matches(componentIdentifier, identifier)
+}
+
+// hasMatcher returns true if the identifier type has a registered matching
+// function, that is if its identifiers can match strings other than
+// themselves.
+func (t IdentifierType) hasMatcher() bool {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+	return identifierTypes[t].Matches != nil
+}
+
+// Valid returns true if the algorithm is registered.
+func (a Algorithm) Valid() bool {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+	_, ok := algorithms[a]
+	return ok
+}
+
+// Validate checks that the algorithm is registered and that the hash value
+// is valid for it.
+func (a Algorithm) Validate(h Hash) error {
+	registryMutex.RLock()
+	validator, ok := algorithms[a]
+	registryMutex.RUnlock()
+	if !ok {
+		return fmt.Errorf("unknown hash algorithm %q, must be one of [%s]", a, strings.Join(Algorithms(), ", "))
+	}
+	if validator == nil {
+		return nil
+	}
+	if err := validator(h); err != nil {
+		return fmt.Errorf("invalid %s hash %q: %w", a, h, err)
+	}
+	return nil
+}
+
+// validatePurl checks the identifier is a valid package URL.
+func validatePurl(identifier string) error {
+	if _, err := packageurl.FromString(identifier); err != nil {
+		return fmt.Errorf("parsing purl: %w", err)
+	}
+	return nil
+}
+
+// prefixValidator returns a function that checks that identifiers start with
+// the specified prefix.
+func prefixValidator(prefix string) func(string) error {
+	return func(identifier string) error {
+		if !strings.HasPrefix(identifier, prefix) {
+			return fmt.Errorf("identifier must start with %q", prefix)
+		}
+		return nil
+	}
+}
+
+// hexValidator returns a HashValidator that checks the hash is an hex
+// encoded string of size bytes. If size is zero, any length is accepted.
+func hexValidator(size int) HashValidator {
+	return func(h Hash) error {
+		data, err := hex.DecodeString(string(h))
+		if err != nil {
+			return errors.New("hash is not hex encoded")
+		}
+		if size > 0 && len(data) != size {
+			return fmt.Errorf("hash must be %d bytes long, got %d", size, len(data))
+		}
+		if len(data) == 0 {
+			return errors.New("hash is empty")
+		}
+		return nil
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestComponentValidate(t *testing.T) {
+	for testCase, tc := range map[string]struct {
+		sut       *Component
+		shouldErr bool
+	}{
+		"valid": {
+			sut: &Component{
+				Identifiers: map[IdentifierType]string{
+					PURL:  "pkg:apk/alpine/libcrypto3@3.0.8-r3",
+					CPE23: "cpe:2.3:a:openssl:openssl:3.0.8:*:*:*:*:*:*:*",
+				},
+				Hashes: map[Algorithm]Hash{
+					SHA256: "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+				},
+			},
+		},
+		"invalid purl": {
+			sut:       &Component{Identifiers: map[IdentifierType]string{PURL: "libcrypto3"}},
+			shouldErr: true,
+		},
+		"invalid cpe": {
+			sut:       &Component{Identifiers: map[IdentifierType]string{CPE22: "cpe:2.3:a:openssl"}},
+			shouldErr: true,
+		},
+		"unknown identifier type": {
+			sut:       &Component{Identifiers: map[IdentifierType]string{"swid": "example"}},
+			shouldErr: true,
+		},
+		"short hash": {
+			sut:       &Component{Hashes: map[Algorithm]Hash{SHA256: "e3b0c44298fc1c14"}},
+			shouldErr: true,
+		},
+		"unknown algorithm": {
+			sut:       &Component{Hashes: map[Algorithm]Hash{"crc32": "cbf43926"}},
+			shouldErr: true,
+		},
+	} {
+		err := tc.sut.Validate()
+		if tc.shouldErr {
+			require.Error(t, err, testCase)
+		} else {
+			require.NoError(t, err, testCase)
+		}
+	}
+}
+
+func TestRegisterCustomTypes(t *testing.T) {
+	internal := IdentifierType("x-acme")
+	crc := Algorithm("x-crc32")
+	t.Cleanup(func() {
+		registryMutex.Lock()
+		delete(identifierTypes, internal)
+		delete(algorithms, crc)
+		registryMutex.Unlock()
+	})
+
+	require.NoError(t, RegisterIdentifierType(internal, IdentifierTypeOptions{
+		Validate: func(s string) error {
+			if !strings.HasPrefix(s, "acme:") {
+				return errors.New("not an acme identifier")
+			}
+			return nil
+		},
+		// Match acme identifiers regardless of their case
+		Matches: strings.EqualFold,
+	}))
+	require.Error(t, RegisterIdentifierType(internal, IdentifierTypeOptions{}))
+	require.Error(t, RegisterIdentifierType(PURL, IdentifierTypeOptions{}))
+	require.NoError(t, RegisterAlgorithm(crc, nil))
+	require.Error(t, RegisterAlgorithm(SHA256, nil))
+
+	require.True(t, internal.Valid())
+	require.True(t, crc.Valid())
+	require.Contains(t, IdentifierTypes(), string(internal))
+	require.Contains(t, Algorithms(), string(crc))
+
+	c := &Component{
+		Identifiers: map[IdentifierType]string{internal: "acme:widget"},
+		Hashes:      map[Algorithm]Hash{crc: "cbf43926"},
+	}
+	require.NoError(t, c.Validate())
+	require.True(t, c.Matches("ACME:Widget"))
+	require.False(t, c.Matches("acme:gadget"))
+
+	c.Identifiers[internal] = "widget"
+	require.Error(t, c.Validate())
+}
@@ -0,0 +1,349 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Index summarizes a corpus of VEX documents to let consumers find the
+// documents relevant to a product or vulnerability without downloading
+// the whole corpus.
+type Index struct {
+	// Timestamp records when the index was generated.
+	Timestamp *time.Time `json:"timestamp"`
+
+	// Documents lists the entries of the indexed documents.
+	Documents []IndexEntry `json:"documents"`
+}
+
+// IndexEntry captures the data of a document in the corpus index.
+type IndexEntry struct {
+	// ID is the @id of the indexed document.
+	ID string `json:"@id"`
+
+	// Location is the slash separated path of the document relative to the
+	// corpus root.
+	Location string `json:"location"`
+
+	// Digest is the SHA-256 digest of the document as computed by VEX.Digest.
+	Digest string `json:"digest"`
+
+	// Version is the document version.
+	Version int `json:"version"`
+
+	// LastUpdated is the last update date of the document, or its timestamp
+	// if it has never been updated.
+	LastUpdated *time.Time `json:"last_updated,omitempty"`
+
+	// Lang is the language tag of the document, if it defines one.
+	Lang string `json:"lang,omitempty"`
+
+	// Products lists the canonical keys of the product IRIs, software
+	// identifiers and hashes covered by the document statements, as
+	// returned by IdentifierKey.String.
+	Products []string `json:"products"`
+
+	// Wildcard is true when the document has statements about products that
+	// can match identifiers without a common key, such as CPEs or the
+	// WildcardProduct. These documents are relevant to any product.
+	Wildcard bool `json:"wildcard,omitempty"`
+
+	// Vulnerabilities lists the vulnerability names and aliases covered by
+	// the document statements.
+	Vulnerabilities []string `json:"vulnerabilities"`
+}
+
+// DocumentFetcher retrieves the raw data of a document from its location in
+// the corpus.
+type DocumentFetcher func(location string) ([]byte, error)
+
+// GenerateIndex walks the directory at root and indexes all the OpenVEX
+// documents found in JSON files. See GenerateIndexWithOptions.
+func GenerateIndex(root string) (*Index, error) {
+	return GenerateIndexWithOptions(nil, root)
+}
+
+// IndexOptions configure the generation of indexes.
+type IndexOptions struct {
+	// Clock sets the timestamp of the index. Defaults to the current time.
+	Clock Clock
+}
+
+// GenerateIndexWithOptions walks the directory at root and indexes all the
+// OpenVEX documents found in JSON files. Files that are not OpenVEX
+// documents in the current spec version are skipped. The index is
+// timestamped with the time of the options clock.
+func GenerateIndexWithOptions(opts *IndexOptions, root string) (*Index, error) {
+	if opts == nil {
+		opts = &IndexOptions{}
+	}
+	now := clockNow(opts.Clock)
+	index := &Index{
+		Timestamp: &now,
+		Documents: []IndexEntry{},
+	}
+
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || filepath.Ext(path) != ".json" {
+			return nil
+		}
+
+		data, err := os.ReadFile(path) //nolint:gosec // This is supposed to open user-specified paths
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", path, err)
+		}
+
+		if context, err := parseContext(data); err != nil || context != ContextLocator() {
+			slog.Debug("skipping file, not an OpenVEX document", "path", path)
+			return nil
+		}
+
+		doc, err := Parse(data)
+		if err != nil {
+			return fmt.Errorf("parsing %s: %w", path, err)
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return fmt.Errorf("computing document location: %w", err)
+		}
+
+		entry, err := doc.indexEntry(filepath.ToSlash(rel))
+		if err != nil {
+			return fmt.Errorf("indexing %s: %w", path, err)
+		}
+		index.Documents = append(index.Documents, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("walking corpus directory: %w", err)
+	}
+
+	sort.Slice(index.Documents, func(i, j int) bool {
+		return index.Documents[i].Location < index.Documents[j].Location
+	})
+
+	return index, nil
+}
+
+// indexEntry builds the index entry of the document.
+func (vexDoc *VEX) indexEntry(location string) (IndexEntry, error) {
+	digest, err := vexDoc.Digest(SHA256)
+	if err != nil {
+		return IndexEntry{}, fmt.Errorf("computing digest: %w", err)
+	}
+
+	lastUpdated := vexDoc.LastUpdated
+	if lastUpdated == nil {
+		lastUpdated = vexDoc.Timestamp
+	}
+
+	products := map[string]struct{}{}
+	vulns := map[string]struct{}{}
+	wildcard := false
+	for i := range vexDoc.Statements {
+		stmt := &vexDoc.Statements[i]
+		if _, w := statementProductKeys(stmt); w {
+			wildcard = true
+		}
+		for _, id := range append([]VulnerabilityID{VulnerabilityID(stmt.Vulnerability.Name)}, stmt.Vulnerability.Aliases...) {
+			if id != "" {
+				vulns[string(id)] = struct{}{}
+			}
+		}
+
+		for j := range stmt.Products {
+			for _, c := range stmt.Products[j].components() {
+				for _, k := range c.CanonicalKeys() {
+					products[k.String()] = struct{}{}
+				}
+			}
+		}
+	}
+
+	return IndexEntry{
+		ID:              vexDoc.ID,
+		Location:        location,
+		Digest:          digest,
+		Version:         vexDoc.Version,
+		LastUpdated:     lastUpdated,
+		Lang:            vexDoc.Lang,
+		Products:        sortedKeys(products),
+		Wildcard:        wildcard,
+		Vulnerabilities: sortedKeys(vulns),
+	}, nil
+}
+
+// sortedKeys returns the sorted keys of a string set.
+func sortedKeys(set map[string]struct{}) []string {
+	ret := make([]string, 0, len(set))
+	for k := range set {
+		ret = append(ret, k)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// LoadIndex reads a corpus index from the file at path.
+func LoadIndex(path string) (*Index, error) {
+	data, err := os.ReadFile(path) //nolint:gosec // This is supposed to open user-specified paths
+	if err != nil {
+		return nil, fmt.Errorf("reading index file: %w", err)
+	}
+
+	index := &Index{}
+	if err := json.Unmarshal(data, index); err != nil {
+		return nil, fmt.Errorf("parsing index: %w", err)
+	}
+	return index, nil
+}
+
+// ToJSON serializes the index to JSON and writes it to the passed writer.
+func (index *Index) ToJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+
+	if err := enc.Encode(index); err != nil {
+		return fmt.Errorf("encoding index: %w", err)
+	}
+	return nil
+}
+
+// Relevant returns the index entries of the documents that may contain
+// statements about the vulnerability and product. An empty vulnerability
+// or product matches any document.
+func (index *Index) Relevant(vulnID, product string) []IndexEntry {
+	ret := []IndexEntry{}
+	for i := range index.Documents {
+		if index.Documents[i].matches(vulnID, product) {
+			ret = append(ret, index.Documents[i])
+		}
+	}
+	return ret
+}
+
+// Language returns the index entries of the documents published in the
+// language.
+func (index *Index) Language(lang string) []IndexEntry {
+	ret := []IndexEntry{}
+	for i := range index.Documents {
+		if index.Documents[i].Lang != "" && LanguageMatches(index.Documents[i].Lang, lang) {
+			ret = append(ret, index.Documents[i])
+		}
+	}
+	return ret
+}
+
+// matches returns true if the entry covers the vulnerability and product.
+// Vulnerabilities are compared by their local identifiers and the product
+// keys are matched like VEX.Matches matches the product identifiers.
+func (entry *IndexEntry) matches(vulnID, product string) bool {
+	if vulnID != "" && !entry.coversVulnerability(vulnID) {
+		return false
+	}
+	if product == "" || entry.Wildcard {
+		return true
+	}
+	keys := queryKeys(product)
+	for _, p := range entry.Products {
+		if slices.Contains(keys, p) {
+			return true
+		}
+		typ, value, _ := strings.Cut(p, ":")
+		switch typ {
+		case KeyTypePurl:
+			if PurlMatches(value, product) {
+				return true
+			}
+		case KeyTypeCPE22, KeyTypeCPE23:
+			// Entries of indexes generated before the wildcard flag
+			if CPEMatches(value, product) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// coversVulnerability returns true if the entry lists the vulnerability
+// under any of its identifiers.
+func (entry *IndexEntry) coversVulnerability(vulnID string) bool {
+	local := VulnerabilityID(vulnID).Local()
+	for _, v := range entry.Vulnerabilities {
+		if VulnerabilityID(v).Local() == local {
+			return true
+		}
+	}
+	return false
+}
+
+// queryKeys returns the keys of the entry products that match the product
+// exactly. A bare digest matches the hashes of any algorithm, so it is
+// keyed like HashKey keys a hash with each of the registered algorithms.
+func queryKeys(product string) []string {
+	key := CanonicalKey(product)
+	ret := []string{key.String()}
+	if key.Type != KeyTypeHash || strings.Contains(key.Value, ":") {
+		return ret
+	}
+	for _, a := range Algorithms() {
+		ret = append(ret, HashKey(Algorithm(a), Hash(key.Value)).String())
+	}
+	return ret
+}
+
+// Fetch retrieves and parses the documents relevant to the vulnerability
+// and product using the fetcher. Each document is verified against the
+// digest recorded in the index before being returned.
+func (index *Index) Fetch(fetcher DocumentFetcher, vulnID, product string) ([]*VEX, error) {
+	if fetcher == nil {
+		return nil, errors.New("no document fetcher defined")
+	}
+
+	docs := []*VEX{}
+	for _, entry := range index.Relevant(vulnID, product) {
+		data, err := fetcher(entry.Location)
+		if err != nil {
+			return nil, fmt.Errorf("fetching %s: %w", entry.Location, err)
+		}
+
+		doc, err := Parse(data)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %w", entry.Location, err)
+		}
+
+		if err := doc.VerifyDigest(entry.Digest); err != nil {
+			return nil, fmt.Errorf("verifying %s: %w", entry.Location, err)
+		}
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
+
+// DirectoryFetcher returns a DocumentFetcher that reads documents from a
+// corpus stored in the local directory at root.
+func DirectoryFetcher(root string) DocumentFetcher {
+	return func(location string) ([]byte, error) {
+		if !filepath.IsLocal(filepath.FromSlash(location)) {
+			return nil, fmt.Errorf("document location %q is outside of the corpus", location)
+		}
+		return os.ReadFile(filepath.Join(root, filepath.FromSlash(location)))
+	}
+}
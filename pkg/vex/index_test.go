@@ -0,0 +1,156 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIndex(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "nested"), 0o755))
+
+	writeDoc := func(path, vuln, product string) {
+		doc := New()
+		doc.ID = "https://example.com/vex/" + vuln
+		doc.Statements = []Statement{
+			{
+				Vulnerability: Vulnerability{Name: VulnerabilityID(vuln), Aliases: []VulnerabilityID{"GHSA-" + VulnerabilityID(vuln)}},
+				Products:      []Product{{Component: Component{ID: product}}},
+				Timestamp:     doc.Timestamp,
+				Status:        StatusFixed,
+			},
+		}
+		var b bytes.Buffer
+		require.NoError(t, doc.ToJSON(&b))
+		require.NoError(t, os.WriteFile(filepath.Join(root, path), b.Bytes(), 0o600))
+	}
+
+	writeDoc("one.json", "CVE-2023-0001", "pkg:apk/wolfi/git@2.41.0-r0")
+	writeDoc("nested/two.json", "CVE-2023-0002", "pkg:apk/wolfi/curl@8.1.0-r0?arch=x86_64")
+	require.NoError(t, os.WriteFile(filepath.Join(root, "other.json"), []byte(`{"name":"not vex"}`), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "README.md"), []byte(`# corpus`), 0o600))
+
+	index, err := GenerateIndex(root)
+	require.NoError(t, err)
+	require.Len(t, index.Documents, 2)
+	require.Equal(t, "nested/two.json", index.Documents[0].Location)
+	require.Equal(t, []string{"CVE-2023-0002", "GHSA-CVE-2023-0002"}, index.Documents[0].Vulnerabilities)
+	require.Equal(t, "one.json", index.Documents[1].Location)
+
+	// Roundtrip the index through its file
+	var b bytes.Buffer
+	require.NoError(t, index.ToJSON(&b))
+	indexPath := filepath.Join(t.TempDir(), "index.json")
+	require.NoError(t, os.WriteFile(indexPath, b.Bytes(), 0o600))
+	index, err = LoadIndex(indexPath)
+	require.NoError(t, err)
+
+	for testCase, tc := range map[string]struct {
+		vuln     string
+		product  string
+		expected []string
+	}{
+		"all":               {expected: []string{"nested/two.json", "one.json"}},
+		"by vulnerability":  {vuln: "CVE-2023-0001", expected: []string{"one.json"}},
+		"by alias":          {vuln: "GHSA-CVE-2023-0002", expected: []string{"nested/two.json"}},
+		"by product":        {product: "pkg:apk/wolfi/git@2.41.0-r0", expected: []string{"one.json"}},
+		"by qualified purl": {product: "pkg:apk/wolfi/curl@8.1.0-r0?arch=x86_64&distro=wolfi", expected: []string{"nested/two.json"}},
+		"no match":          {vuln: "CVE-2023-0001", product: "pkg:apk/wolfi/curl@8.1.0-r0", expected: []string{}},
+	} {
+		locations := []string{}
+		for _, e := range index.Relevant(tc.vuln, tc.product) {
+			locations = append(locations, e.Location)
+		}
+		require.Equal(t, tc.expected, locations, testCase)
+	}
+
+	fetched := []string{}
+	fetcher := func(location string) ([]byte, error) {
+		fetched = append(fetched, location)
+		return DirectoryFetcher(root)(location)
+	}
+	docs, err := index.Fetch(fetcher, "CVE-2023-0001", "")
+	require.NoError(t, err)
+	require.Len(t, docs, 1)
+	require.Equal(t, "https://example.com/vex/CVE-2023-0001", docs[0].ID)
+	require.Equal(t, []string{"one.json"}, fetched)
+
+	// Tampered documents fail verification
+	writeDoc("one.json", "CVE-2023-0001", "pkg:apk/wolfi/git@2.42.0-r0")
+	_, err = index.Fetch(DirectoryFetcher(root), "CVE-2023-0001", "")
+	require.ErrorIs(t, err, ErrDigestMismatch)
+
+	_, err = DirectoryFetcher(root)("../index.json")
+	require.Error(t, err)
+}
+
+func TestIndexEntryMatches(t *testing.T) {
+	digest := "a1b2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f90"
+	for m, tc := range map[string]struct {
+		component Component
+		vuln      string
+		product   string
+	}{
+		"wildcard":     {component: Component{ID: WildcardProduct}, product: "pkg:apk/wolfi/git@2.41.0-r0"},
+		"cpe":          {component: Component{ID: "cpe:2.3:a:acme:widget:*:*:*:*:*:*:*:*"}, product: "cpe:2.3:a:acme:widget:1.0:*:*:*:*:*:*:*"},
+		"cpe id":       {component: Component{Identifiers: map[IdentifierType]string{CPE23: "cpe:2.3:a:acme:widget:*:*:*:*:*:*:*:*"}}, product: "cpe:2.3:a:acme:widget:1.0:*:*:*:*:*:*:*"},
+		"bare digest":  {component: Component{Hashes: map[Algorithm]Hash{SHA256: Hash(digest)}}, product: digest},
+		"purl":         {component: Component{ID: "pkg:apk/wolfi/git@2.41.0-r0"}, product: "pkg:apk/wolfi/git@2.41.0-r0?arch=x86_64"},
+		"vuln iri":     {component: Component{ID: "pkg:apk/wolfi/git@2.41.0-r0"}, vuln: "https://nvd.nist.gov/vuln/detail/CVE-2023-0001"},
+		"alias iri":    {component: Component{ID: "pkg:apk/wolfi/git@2.41.0-r0"}, vuln: "https://github.com/advisories/GHSA-aaaa-bbbb-cccc"},
+		"vuln and cpe": {component: Component{ID: "cpe:/a:acme:widget"}, vuln: "CVE-2023-0001", product: "cpe:/a:acme:widget:1.0"},
+	} {
+		doc := New()
+		doc.Statements = []Statement{{
+			Vulnerability: Vulnerability{Name: "CVE-2023-0001", Aliases: []VulnerabilityID{"GHSA-aaaa-bbbb-cccc"}},
+			Products:      []Product{{Component: tc.component}},
+			Status:        StatusFixed,
+		}}
+		vuln := tc.vuln
+		if vuln == "" {
+			vuln = "CVE-2023-0001"
+		}
+		product := tc.product
+		if product == "" {
+			product = "pkg:apk/wolfi/git@2.41.0-r0"
+		}
+		require.NotEmpty(t, doc.MatchesWithOptions(vuln, product, nil, &MatchOptions{Wildcards: true}), m)
+
+		entry, err := doc.indexEntry("doc.json")
+		require.NoError(t, err, m)
+		require.True(t, entry.matches(vuln, product), m)
+		require.False(t, entry.matches("CVE-2023-9999", product), m)
+	}
+}
+
+func TestGenerateIndexWithOptions(t *testing.T) {
+	root := t.TempDir()
+	doc := New()
+	doc.ID = "https://example.com/vex/CVE-2023-0001"
+	doc.Statements = []Statement{{
+		Vulnerability: Vulnerability{Name: "CVE-2023-0001"},
+		Products:      []Product{{Component: Component{ID: "pkg:apk/wolfi/git@2.41.0-r0"}}},
+		Status:        StatusFixed,
+	}}
+	var b bytes.Buffer
+	require.NoError(t, doc.ToJSON(&b))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "one.json"), b.Bytes(), 0o600))
+
+	now := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+	var outputs [2]bytes.Buffer
+	for i := range outputs {
+		index, err := GenerateIndexWithOptions(&IndexOptions{Clock: FixedClock(now)}, root)
+		require.NoError(t, err)
+		require.Equal(t, now, *index.Timestamp)
+		require.NoError(t, index.ToJSON(&outputs[i]))
+	}
+	require.Equal(t, outputs[0].String(), outputs[1].String())
+}
@@ -0,0 +1,59 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestJSONRoundTrip runs against the encoding/json implementation by default
+// and against the encoding/json/v2 one when built with -tags jsonv2.
+func TestJSONRoundTrip(t *testing.T) {
+	ts := time.Date(2023, 4, 17, 20, 34, 58, 0, time.FixedZone("CST", -6*60*60))
+	doc := New()
+	doc.ID = "https://openvex.dev/docs/example/vex-9fb3463de1b57"
+	doc.Author = "Wolfi J Inkinson"
+	doc.Timestamp = &ts
+	doc.Statements = []Statement{
+		{
+			Vulnerability: Vulnerability{Name: "CVE-2023-1255"},
+			Timestamp:     &ts,
+			Products: []Product{
+				{
+					Component: Component{
+						ID: "pkg:apk/wolfi/git@2.39.0-r1?arch=x86_64",
+						Hashes: map[Algorithm]Hash{
+							SHA256: "2e5ba6fd5a5fb9a7ae4a1e0d3e4e0d7a1a7b7b6f4b4b9b4c2b6c1c1c1c1c1c1c",
+							SHA1:   "1e5ba6fd5a5fb9a7ae4a1e0d3e4e0d7a1a7b7b6f",
+						},
+					},
+				},
+			},
+			Status:        StatusNotAffected,
+			Justification: ComponentNotPresent,
+		},
+	}
+
+	var b bytes.Buffer
+	require.NoError(t, doc.ToJSON(&b))
+	require.Contains(t, b.String(), `"timestamp": "2023-04-18T02:34:58Z"`)
+
+	// Encoding must be deterministic
+	var b2 bytes.Buffer
+	require.NoError(t, doc.ToJSON(&b2))
+	require.Equal(t, b.String(), b2.String())
+
+	parsed, err := Parse(b.Bytes())
+	require.NoError(t, err)
+	require.Equal(t, doc.ID, parsed.ID)
+	require.Equal(t, doc.Author, parsed.Author)
+	require.True(t, doc.Timestamp.Equal(*parsed.Timestamp))
+	require.Len(t, parsed.Statements, 1)
+	require.Equal(t, doc.Statements[0].Products, parsed.Statements[0].Products)
+	require.Equal(t, doc.Statements[0].Justification, parsed.Statements[0].Justification)
+}
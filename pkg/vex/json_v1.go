@@ -0,0 +1,27 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build !go1.27 || !jsonv2 || !goexperiment.jsonv2
+
+package vex
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// encodeJSON writes the indented JSON encoding of v to w. This is the default
+// implementation built on encoding/json. Building with Go 1.27 or later,
+// GOEXPERIMENT=jsonv2 and the jsonv2 tag switches to the encoding/json/v2
+// implementation.
+func encodeJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(v)
+}
+
+// decodeJSON parses the JSON-encoded data and stores the result in v.
+func decodeJSON(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,82 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+//go:build go1.27 && jsonv2 && goexperiment.jsonv2
+
+package vex
+
+import (
+	"encoding/json/jsontext"
+	jsonv2 "encoding/json/v2"
+	"io"
+	"time"
+)
+
+// jsonOptions are the encoding/json/v2 options used to read and write
+// documents. Map keys are sorted to get deterministic output and field
+// names are matched case-insensitively to preserve the v1 parsing behavior.
+var jsonOptions = jsonv2.JoinOptions(
+	jsonv2.Deterministic(true),
+	jsonv2.MatchCaseInsensitiveNames(true),
+	jsontext.EscapeForHTML(false),
+)
+
+// encodeJSON streams the indented JSON encoding of v to w using the
+// encoding/json/v2 APIs.
+func encodeJSON(w io.Writer, v any) error {
+	enc := jsontext.NewEncoder(w, jsonOptions, jsontext.WithIndent("  "))
+	return jsonv2.MarshalEncode(enc, v, jsonOptions)
+}
+
+// decodeJSON parses the JSON-encoded data and stores the result in v.
+func decodeJSON(data []byte, v any) error {
+	return jsonv2.Unmarshal(data, v, jsonOptions)
+}
+
+// MarshalJSONTo streams the document to the jsontext encoder. It normalizes
+// the timezones in all dates to Zulu, just as MarshalJSON does.
+func (vexDoc *VEX) MarshalJSONTo(enc *jsontext.Encoder) error {
+	type alias VEX
+	var ts, lu string
+
+	if vexDoc.Timestamp != nil {
+		ts = vexDoc.Timestamp.UTC().Format(time.RFC3339)
+	}
+	if vexDoc.LastUpdated != nil {
+		lu = vexDoc.LastUpdated.UTC().Format(time.RFC3339)
+	}
+
+	return jsonv2.MarshalEncode(enc, &struct {
+		*alias
+		TimeZonedTimestamp   string `json:"timestamp"`
+		TimeZonedLastUpdated string `json:"last_updated,omitempty"`
+	}{
+		TimeZonedTimestamp:   ts,
+		TimeZonedLastUpdated: lu,
+		alias:                (*alias)(vexDoc),
+	}, jsonOptions)
+}
+
+// MarshalJSONTo streams the statement to the jsontext encoder, normalizing
+// the timezones in all dates to Zulu.
+func (stmt *Statement) MarshalJSONTo(enc *jsontext.Encoder) error {
+	type alias Statement
+	var ts, lu string
+
+	if stmt.Timestamp != nil {
+		ts = stmt.Timestamp.UTC().Format(time.RFC3339Nano)
+	}
+	if stmt.LastUpdated != nil {
+		lu = stmt.LastUpdated.UTC().Format(time.RFC3339Nano)
+	}
+
+	return jsonv2.MarshalEncode(enc, &struct {
+		*alias
+		TimeZonedTimestamp   string `json:"timestamp,omitempty"`
+		TimeZonedLastUpdated string `json:"last_updated,omitempty"`
+	}{
+		alias:                (*alias)(stmt),
+		TimeZonedTimestamp:   ts,
+		TimeZonedLastUpdated: lu,
+	}, jsonOptions)
+}
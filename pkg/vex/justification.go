@@ -76,3 +76,21 @@ func (j Justification) Valid() bool {
 		return false
 	}
 }
+
+// JustificationFromCycloneDX returns the justification equivalent to a
+// CycloneDX analysis justification. CycloneDX justifications without a
+// close equivalent return an empty string.
+func JustificationFromCycloneDX(justification string) Justification {
+	switch justification {
+	case "code_not_present":
+		return VulnerableCodeNotPresent
+	case "code_not_reachable":
+		return VulnerableCodeNotInExecutePath
+	case "requires_configuration", "requires_dependency", "requires_environment":
+		return VulnerableCodeCannotBeControlledByAdversary
+	case "protected_by_compiler", "protected_at_runtime", "protected_at_perimeter", "protected_by_mitigating_control":
+		return InlineMitigationsAlreadyExist
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,165 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/package-url/packageurl-go"
+)
+
+// Types of identifier keys. Purls and CPEs use their IdentifierType names.
+const (
+	KeyTypePurl  = string(PURL)
+	KeyTypeCPE22 = string(CPE22)
+	KeyTypeCPE23 = string(CPE23)
+	KeyTypeHash  = "hash"
+	KeyTypeIRI   = "iri"
+	KeyTypeOther = "other"
+)
+
+// minHashLength is the minimum number of hex characters of a bare string
+// to be considered a hash.
+const minHashLength = 32
+
+// IdentifierKey is the canonical form of a product identifier. Identifiers
+// that refer to the same software in different notations reduce to the
+// same key, so keys can be compared to deduplicate identifiers.
+type IdentifierKey struct {
+	// Type is the kind of identifier, one of the KeyType* constants
+	Type string
+
+	// Value is the normalized identifier
+	Value string
+}
+
+// String returns the key as type:value
+func (k IdentifierKey) String() string {
+	return k.Type + ":" + k.Value
+}
+
+// CanonicalKey reduces an identifier to its canonical key:
+//
+//   - Purls are normalized to their canonical string with sorted qualifiers.
+//   - CPEs are lowercased.
+//   - Hashes prefixed by their algorithm (eg sha256:... or sha-256:...) are
+//     reduced to the registered algorithm name and the lowercase hex value,
+//     as HashKey does. Bare hex strings keep only the lowercase hex value
+//     as their algorithm is unknown.
+//   - IRIs get their scheme and host lowercased.
+//
+// Any other identifier is kept as is, trimming surrounding whitespace.
+func CanonicalKey(identifier string) IdentifierKey {
+	identifier = strings.TrimSpace(identifier)
+	lower := strings.ToLower(identifier)
+
+	switch {
+	case strings.HasPrefix(identifier, "pkg:"):
+		p, err := packageurl.FromString(identifier)
+		if err != nil {
+			return IdentifierKey{Type: KeyTypePurl, Value: identifier}
+		}
+		sort.Slice(p.Qualifiers, func(i, j int) bool {
+			return p.Qualifiers[i].Key < p.Qualifiers[j].Key
+		})
+		return IdentifierKey{Type: KeyTypePurl, Value: p.ToString()}
+	case strings.HasPrefix(lower, "cpe:2.3:"):
+		return IdentifierKey{Type: KeyTypeCPE23, Value: lower}
+	case strings.HasPrefix(lower, "cpe:/"):
+		return IdentifierKey{Type: KeyTypeCPE22, Value: lower}
+	}
+
+	if h, ok := hashValue(identifier); ok {
+		return IdentifierKey{Type: KeyTypeHash, Value: h}
+	}
+
+	if u, err := url.Parse(identifier); err == nil && u.Scheme != "" && (u.Host != "" || u.Opaque != "") {
+		u.Scheme = strings.ToLower(u.Scheme)
+		u.Host = strings.ToLower(u.Host)
+		return IdentifierKey{Type: KeyTypeIRI, Value: u.String()}
+	}
+
+	return IdentifierKey{Type: KeyTypeOther, Value: identifier}
+}
+
+// HashKey returns the canonical key of a hash computed with the algorithm.
+// The key value is the registered name of the algorithm and the lowercase
+// hex value (eg sha-256:...), so equal values of different algorithms are
+// different keys.
+func HashKey(algo Algorithm, h Hash) IdentifierKey {
+	name, ok := registeredAlgorithm(string(algo))
+	if !ok {
+		name = strings.ToLower(string(algo))
+	}
+	return IdentifierKey{Type: KeyTypeHash, Value: name + ":" + strings.ToLower(string(h))}
+}
+
+// hashValue returns the key value of a hash written as a bare hex string or
+// prefixed by the name of a registered algorithm.
+func hashValue(identifier string) (string, bool) {
+	value, prefix := identifier, ""
+	if algo, hex, ok := strings.Cut(identifier, ":"); ok {
+		name, ok := registeredAlgorithm(algo)
+		if !ok {
+			return "", false
+		}
+		value, prefix = hex, name+":"
+	} else if len(value) < minHashLength {
+		return "", false
+	}
+	if value == "" {
+		return "", false
+	}
+	for _, c := range value {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", c) {
+			return "", false
+		}
+	}
+	return prefix + strings.ToLower(value), true
+}
+
+// registeredAlgorithm returns the registered name of an algorithm written
+// with or without the dash (eg sha256 or sha-256).
+func registeredAlgorithm(name string) (string, bool) {
+	name = strings.ReplaceAll(strings.ToLower(name), "-", "")
+	for _, a := range Algorithms() {
+		if strings.ReplaceAll(a, "-", "") == name {
+			return a, true
+		}
+	}
+	return "", false
+}
+
+// CanonicalKeys returns the canonical keys of all the identifiers of the
+// component: its IRI, software identifiers and hashes. The keys are sorted
+// and deduplicated.
+func (c *Component) CanonicalKeys() []IdentifierKey {
+	set := map[IdentifierKey]struct{}{}
+	if c.ID != "" {
+		set[CanonicalKey(c.ID)] = struct{}{}
+	}
+	for _, id := range c.Identifiers {
+		set[CanonicalKey(id)] = struct{}{}
+	}
+	for algo, h := range c.Hashes {
+		set[HashKey(algo, h)] = struct{}{}
+	}
+
+	ret := []IdentifierKey{}
+	for k := range set {
+		ret = append(ret, k)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].String() < ret[j].String()
+	})
+	return ret
+}
+
+// key returns the canonical key of the most descriptive identifier of the
+// component, following the preference of displayName.
+func (c *Component) key() IdentifierKey {
+	return CanonicalKey(c.displayName())
+}
@@ -0,0 +1,56 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanonicalKey(t *testing.T) {
+	digest := "124c7d2707904eea7431fffe91522a01e5a861a624ee31d03372cc1d138a3126"
+	for m, tc := range map[string]struct {
+		identifier string
+		expected   IdentifierKey
+	}{
+		"purl":              {"pkg:apk/wolfi/curl@8.1.0?distro=wolfi&arch=x86_64", IdentifierKey{KeyTypePurl, "pkg:apk/wolfi/curl@8.1.0?arch=x86_64&distro=wolfi"}},
+		"cpe 2.3":           {"cpe:2.3:a:Haxx:Curl:8.1.0:*:*:*:*:*:*:*", IdentifierKey{KeyTypeCPE23, "cpe:2.3:a:haxx:curl:8.1.0:*:*:*:*:*:*:*"}},
+		"cpe 2.2":           {"cpe:/a:Haxx:curl:8.1.0", IdentifierKey{KeyTypeCPE22, "cpe:/a:haxx:curl:8.1.0"}},
+		"bare hash":         {digest, IdentifierKey{KeyTypeHash, digest}},
+		"uppercase hash":    {"SHA256:" + digest, IdentifierKey{KeyTypeHash, "sha-256:" + digest}},
+		"algorithm hash":    {"sha-256:" + digest, IdentifierKey{KeyTypeHash, "sha-256:" + digest}},
+		"other algorithm":   {"sha3-256:" + digest, IdentifierKey{KeyTypeHash, "sha3-256:" + digest}},
+		"iri":               {"HTTPS://Example.com/Products/App", IdentifierKey{KeyTypeIRI, "https://example.com/Products/App"}},
+		"urn":               {"urn:uuid:0b6a5e6e-9f5d-4c7b-8a8e-4c3e4f0b8e1a", IdentifierKey{KeyTypeIRI, "urn:uuid:0b6a5e6e-9f5d-4c7b-8a8e-4c3e4f0b8e1a"}},
+		"short hex":         {"deadbeef", IdentifierKey{KeyTypeOther, "deadbeef"}},
+		"unknown algorithm": {"foo:" + digest, IdentifierKey{KeyTypeIRI, "foo:" + digest}},
+		"other":             {" my-product ", IdentifierKey{KeyTypeOther, "my-product"}},
+	} {
+		require.Equal(t, tc.expected, CanonicalKey(tc.identifier), m)
+	}
+}
+
+func TestComponentCanonicalKeys(t *testing.T) {
+	digest := "124c7d2707904eea7431fffe91522a01e5a861a624ee31d03372cc1d138a3126"
+	c := Component{
+		ID: "pkg:oci/app?tag=latest&arch=amd64",
+		Identifiers: map[IdentifierType]string{
+			PURL: "pkg:oci/app?arch=amd64&tag=latest",
+		},
+		Hashes: map[Algorithm]Hash{SHA256: Hash(digest)},
+	}
+	require.Equal(t, []IdentifierKey{
+		{KeyTypeHash, "sha-256:" + digest},
+		{KeyTypePurl, "pkg:oci/app?arch=amd64&tag=latest"},
+	}, c.CanonicalKeys())
+}
+
+func TestHashKey(t *testing.T) {
+	digest := "124c7d2707904eea7431fffe91522a01e5a861a624ee31d03372cc1d138a3126"
+	require.Equal(t, IdentifierKey{KeyTypeHash, "sha-256:" + digest}, HashKey(SHA256, Hash(strings.ToUpper(digest))))
+	require.Equal(t, CanonicalKey("sha256:"+digest), HashKey(SHA256, Hash(digest)))
+	require.NotEqual(t, HashKey(SHA256, Hash(digest)), HashKey(SHA3256, Hash(digest)))
+}
@@ -0,0 +1,128 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DefaultLanguage is the language used to look up labels when a catalog for
+// the requested language is not registered or is missing a message.
+const DefaultLanguage = "en"
+
+// Catalog is a message catalog mapping status and justification values to
+// human readable labels in a single language.
+type Catalog map[string]string
+
+var (
+	catalogsMutex sync.RWMutex
+	catalogs      = map[string]Catalog{
+		"en": {
+			string(StatusNotAffected):                           "Not affected",
+			string(StatusAffected):                              "Affected",
+			string(StatusFixed):                                 "Fixed",
+			string(StatusUnderInvestigation):                    "Under investigation",
+			string(ComponentNotPresent):                         "Component not present",
+			string(VulnerableCodeNotPresent):                    "Vulnerable code not present",
+			string(VulnerableCodeNotInExecutePath):              "Vulnerable code not in execute path",
+			string(VulnerableCodeCannotBeControlledByAdversary): "Vulnerable code cannot be controlled by adversary",
+			string(InlineMitigationsAlreadyExist):               "Inline mitigations already exist",
+		},
+		"es": {
+			string(StatusNotAffected):                           "No afectado",
+			string(StatusAffected):                              "Afectado",
+			string(StatusFixed):                                 "Corregido",
+			string(StatusUnderInvestigation):                    "En investigación",
+			string(ComponentNotPresent):                         "El componente no está presente",
+			string(VulnerableCodeNotPresent):                    "El código vulnerable no está presente",
+			string(VulnerableCodeNotInExecutePath):              "El código vulnerable no está en la ruta de ejecución",
+			string(VulnerableCodeCannotBeControlledByAdversary): "El código vulnerable no puede ser controlado por un adversario",
+			string(InlineMitigationsAlreadyExist):               "Ya existen mitigaciones integradas",
+		},
+		"de": {
+			string(StatusNotAffected):                           "Nicht betroffen",
+			string(StatusAffected):                              "Betroffen",
+			string(StatusFixed):                                 "Behoben",
+			string(StatusUnderInvestigation):                    "In Untersuchung",
+			string(ComponentNotPresent):                         "Komponente nicht vorhanden",
+			string(VulnerableCodeNotPresent):                    "Verwundbarer Code nicht vorhanden",
+			string(VulnerableCodeNotInExecutePath):              "Verwundbarer Code nicht im Ausführungspfad",
+			string(VulnerableCodeCannotBeControlledByAdversary): "Verwundbarer Code kann nicht von Angreifern kontrolliert werden",
+			string(InlineMitigationsAlreadyExist):               "Integrierte Gegenmaßnahmen bereits vorhanden",
+		},
+		"fr": {
+			string(StatusNotAffected):                           "Non affecté",
+			string(StatusAffected):                              "Affecté",
+			string(StatusFixed):                                 "Corrigé",
+			string(StatusUnderInvestigation):                    "En cours d'analyse",
+			string(ComponentNotPresent):                         "Composant absent",
+			string(VulnerableCodeNotPresent):                    "Code vulnérable absent",
+			string(VulnerableCodeNotInExecutePath):              "Code vulnérable hors du chemin d'exécution",
+			string(VulnerableCodeCannotBeControlledByAdversary): "Code vulnérable non contrôlable par un attaquant",
+			string(InlineMitigationsAlreadyExist):               "Mesures d'atténuation intégrées déjà présentes",
+		},
+	}
+)
+
+// RegisterCatalog registers a message catalog for a language, replacing any
+// catalog previously registered for it. Languages are identified by their
+// BCP 47 tag (eg "es" or "pt-BR").
+func RegisterCatalog(lang string, catalog Catalog) {
+	catalogsMutex.Lock()
+	defer catalogsMutex.Unlock()
+	catalogs[normalizeLanguage(lang)] = catalog
+}
+
+// Languages returns the sorted list of languages with a registered catalog.
+func Languages() []string {
+	catalogsMutex.RLock()
+	defer catalogsMutex.RUnlock()
+	ret := []string{}
+	for lang := range catalogs {
+		ret = append(ret, lang)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// normalizeLanguage lowercases a language tag and uses dashes as separator.
+func normalizeLanguage(lang string) string {
+	return strings.ToLower(strings.ReplaceAll(lang, "_", "-"))
+}
+
+// label looks up the label of a value in the catalog for lang. If the exact
+// language is not found, the base language is tried (eg "es" for "es-MX")
+// and finally the default language. If no label is found the raw value is
+// returned.
+func label(lang, value string) string {
+	lang = normalizeLanguage(lang)
+	candidates := []string{lang}
+	if base, _, ok := strings.Cut(lang, "-"); ok {
+		candidates = append(candidates, base)
+	}
+	candidates = append(candidates, DefaultLanguage)
+
+	catalogsMutex.RLock()
+	defer catalogsMutex.RUnlock()
+	for _, l := range candidates {
+		if msg, ok := catalogs[l][value]; ok {
+			return msg
+		}
+	}
+	return value
+}
+
+// Label returns the human readable label of the status in the specified
+// language.
+func (s Status) Label(lang string) string {
+	return label(lang, string(s))
+}
+
+// Label returns the human readable label of the justification in the
+// specified language.
+func (j Justification) Label(lang string) string {
+	return label(lang, string(j))
+}
@@ -0,0 +1,44 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLabels(t *testing.T) {
+	for testCase, tc := range map[string]struct {
+		lang     string
+		value    interface{ Label(string) string }
+		expected string
+	}{
+		"english status":        {"en", StatusNotAffected, "Not affected"},
+		"spanish justification": {"es", ComponentNotPresent, "El componente no está presente"},
+		"regional fallback":     {"es-MX", StatusFixed, "Corregido"},
+		"underscore tag":        {"de_DE", StatusAffected, "Betroffen"},
+		"unknown language":      {"xx", StatusUnderInvestigation, "Under investigation"},
+		"unknown value":         {"en", Status("exploited"), "exploited"},
+		"default on empty lang": {"", InlineMitigationsAlreadyExist, "Inline mitigations already exist"},
+	} {
+		require.Equal(t, tc.expected, tc.value.Label(tc.lang), testCase)
+	}
+}
+
+func TestRegisterCatalog(t *testing.T) {
+	RegisterCatalog("pt-BR", Catalog{
+		string(StatusNotAffected): "Não afetado",
+	})
+	t.Cleanup(func() {
+		catalogsMutex.Lock()
+		delete(catalogs, "pt-br")
+		catalogsMutex.Unlock()
+	})
+
+	require.Contains(t, Languages(), "pt-br")
+	require.Equal(t, "Não afetado", StatusNotAffected.Label("pt-BR"))
+	// Missing messages fall back to the default language
+	require.Equal(t, "Fixed", StatusFixed.Label("pt-BR"))
+}
@@ -0,0 +1,37 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import "strings"
+
+// LanguageMatches returns true if the BCP 47 language tag matches the
+// requested language. Tags are compared case-insensitively and a language
+// without a region matches all its regional variants, eg "en" matches
+// "en-US" but "en-US" does not match "en-GB".
+func LanguageMatches(tag, lang string) bool {
+	tag = strings.ToLower(tag)
+	lang = strings.ToLower(lang)
+	return tag == lang || strings.HasPrefix(tag, lang+"-")
+}
+
+// StatementLang returns the language of a statement in the document. If the
+// statement does not define one, it inherits the language of the document.
+func (vexDoc *VEX) StatementLang(stmt *Statement) string {
+	if stmt.Lang != "" {
+		return stmt.Lang
+	}
+	return vexDoc.Lang
+}
+
+// FilterByLanguage returns the documents published in the specified language.
+// Documents without a language are not returned.
+func FilterByLanguage(docs []*VEX, lang string) []*VEX {
+	ret := []*VEX{}
+	for _, doc := range docs {
+		if doc.Lang != "" && LanguageMatches(doc.Lang, lang) {
+			ret = append(ret, doc)
+		}
+	}
+	return ret
+}
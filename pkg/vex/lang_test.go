@@ -0,0 +1,86 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLanguageMatches(t *testing.T) {
+	for m, tc := range map[string]struct {
+		tag      string
+		lang     string
+		expected bool
+	}{
+		"same":                {"en", "en", true},
+		"different case":      {"en-US", "en-us", true},
+		"region variant":      {"en-US", "en", true},
+		"different region":    {"en-US", "en-GB", false},
+		"region vs base":      {"en", "en-US", false},
+		"different language":  {"de", "en", false},
+		"prefix not a subtag": {"enx", "en", false},
+	} {
+		require.Equal(t, tc.expected, LanguageMatches(tc.tag, tc.lang), m)
+	}
+}
+
+func TestLanguageMetadata(t *testing.T) {
+	ts := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	newDoc := func(id, lang string) *VEX {
+		doc := New()
+		doc.ID = id
+		doc.Lang = lang
+		doc.Statements = []Statement{
+			{
+				Vulnerability: Vulnerability{Name: "CVE-2023-1234"},
+				Products:      []Product{{Component: Component{ID: "pkg:generic/test@1.0"}}},
+				Status:        StatusUnderInvestigation,
+				Timestamp:     &ts,
+			},
+		}
+		return &doc
+	}
+
+	en := newDoc("en", "en-US")
+	de := newDoc("de", "de")
+	none := newDoc("none", "")
+	de.Statements[0].Lang = "de-AT"
+
+	require.Equal(t, "en-US", en.StatementLang(&en.Statements[0]))
+	require.Equal(t, "de-AT", de.StatementLang(&de.Statements[0]))
+
+	// Filtering
+	require.Equal(t, []*VEX{en}, FilterByLanguage([]*VEX{en, de, none}, "en"))
+	require.Empty(t, FilterByLanguage([]*VEX{en, de, none}, "fr"))
+
+	corpus := NewCorpus()
+	corpus.Add(en)
+	corpus.Add(de)
+	require.Equal(t, []*VEX{de}, corpus.Language("DE"))
+
+	// Merging preserves the language of each statement
+	merged, err := MergeDocumentsWithOptions(&MergeOptions{Mode: MergeModeAttributed}, []*VEX{en, de})
+	require.NoError(t, err)
+	require.Empty(t, merged.Lang)
+	langs := []string{}
+	for i := range merged.Statements {
+		langs = append(langs, merged.StatementLang(&merged.Statements[i]))
+	}
+	require.ElementsMatch(t, []string{"en-US", "de-AT"}, langs)
+
+	merged, err = MergeDocumentsWithOptions(&MergeOptions{Mode: MergeModeAttributed}, []*VEX{en, newDoc("en2", "en-US")})
+	require.NoError(t, err)
+	require.Equal(t, "en-US", merged.Lang)
+
+	// Language survives serialization
+	data, err := merged.MarshalJSON()
+	require.NoError(t, err)
+	parsed, err := Parse(data)
+	require.NoError(t, err)
+	require.Equal(t, "en-US", parsed.Lang)
+	require.Equal(t, "en-US", parsed.Statements[0].Lang)
+}
@@ -0,0 +1,287 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SubcomponentMatching controls how products listing subcomponents are
+// matched by queries that don't name any subcomponent.
+type SubcomponentMatching int
+
+const (
+	// SubcomponentsLenient matches products listing subcomponents when the
+	// query does not name a subcomponent. This is the default and the way
+	// Matches has always behaved. Results are flagged as product level
+	// matches as the statement is more specific than the query.
+	SubcomponentsLenient SubcomponentMatching = iota
+
+	// SubcomponentsStrict only matches products listing subcomponents when
+	// the query names one of them.
+	SubcomponentsStrict
+)
+
+// MatchLevel captures how specific a statement match is.
+type MatchLevel int
+
+const (
+	// NoMatch means the statement does not apply to the query.
+	NoMatch MatchLevel = iota
+
+	// ProductLevelMatch means the statement matched the queried product but
+	// it applies to subcomponents that were not named in the query.
+	ProductLevelMatch
+
+	// FullMatch means the statement matched the product and, if it lists
+	// any, the queried subcomponents.
+	FullMatch
+)
+
+// MatchOptions configure the matching of statements.
+type MatchOptions struct {
+	// Subcomponents sets how queries without subcomponents are matched.
+	Subcomponents SubcomponentMatching
+
+	// Resolver is an optional resolver used to match alternative
+	// identifiers of the product and subcomponents.
+	Resolver IdentifierResolver
+
+	// Aliases is an optional resolver queried for the aliases of the
+	// vulnerability when it does not match a statement, so statements about
+	// any of its aliases apply to the query. Lookups use Context.
+	Aliases AliasResolver
+
+	// Context is passed to the Aliases resolver. Defaults to
+	// context.Background.
+	Context context.Context
+
+	// AliasError is called with the error when the Aliases resolver fails.
+	// The query then only matches the statements about the vulnerability
+	// itself. Failed lookups are not cached, so it may be called once per
+	// statement. Errors are logged when it is nil.
+	AliasError func(error)
+
+	// Distro sets how the distro qualifiers of OS package purls are
+	// compared. Defaults to DistroStrict.
+	Distro DistroMatching
+
+	// Sanitize runs the query identifiers through SanitizeIdentifier before
+	// matching. Queries with identifiers that can't be sanitized don't
+	// match any statement.
+	Sanitize bool
+
+	// Versions sets how the versions of purls are compared. Defaults to
+	// VersionsRange.
+	Versions VersionMatching
+
+	// IgnoreQualifiers disregards the qualifiers of purls when matching,
+	// except for vers which is controlled by Versions.
+	IgnoreQualifiers bool
+
+	// CaseInsensitiveIdentifiers compares the identifiers, IDs and hashes
+	// of the components ignoring their case.
+	CaseInsensitiveIdentifiers bool
+
+	// Wildcards matches products with the WildcardProduct ID against any
+	// identifier. It is off by default: a wildcard statement applies to
+	// every product, whoever made it, so enable it only to evaluate
+	// documents from authors trusted to speak for all the queried products.
+	Wildcards bool
+
+	// Clock sets the time statements are evaluated at. Statements that
+	// expired at that time (see Statement.SetValidUntil) don't match.
+	// Defaults to the current time.
+	Clock Clock
+}
+
+// purlOptions returns the purl matching options derived from the match
+// options.
+func (opts *MatchOptions) purlOptions() *PurlMatchOptions {
+	if opts == nil || (opts.Distro == DistroStrict && opts.Versions == VersionsRange && !opts.IgnoreQualifiers) {
+		return nil
+	}
+	return &PurlMatchOptions{
+		Distro:           opts.Distro,
+		Versions:         opts.Versions,
+		IgnoreQualifiers: opts.IgnoreQualifiers,
+	}
+}
+
+// foldCase lowercases the identifier when matching case insensitively.
+func (opts *MatchOptions) foldCase(identifier string) string {
+	if opts == nil || !opts.CaseInsensitiveIdentifiers {
+		return identifier
+	}
+	return strings.ToLower(identifier)
+}
+
+// MatchKind is the field of a component that matched a query identifier.
+type MatchKind string
+
+const (
+	// MatchByID means the component ID matched the query.
+	MatchByID MatchKind = "id"
+
+	// MatchByIdentifier means one of the component identifiers matched.
+	MatchByIdentifier MatchKind = "identifier"
+
+	// MatchByHash means one of the component hashes matched.
+	MatchByHash MatchKind = "hash"
+
+	// MatchByWildcard means the component is the WildcardProduct, see
+	// MatchOptions.Wildcards.
+	MatchByWildcard MatchKind = "wildcard"
+)
+
+// IdentifierMatch describes how a component matched a query identifier.
+type IdentifierMatch struct {
+	// Kind is the field of the component that matched.
+	Kind MatchKind
+
+	// Type is the type of the matched identifier. It is also set when the
+	// component ID is a purl or a CPE.
+	Type IdentifierType
+
+	// Algorithm is the algorithm of the matched hash.
+	Algorithm Algorithm
+
+	// Value is the component value that matched.
+	Value string
+
+	// Query is the identifier that matched the component. It is an
+	// identifier returned by the resolver when the queried one did not
+	// match directly.
+	Query string
+}
+
+// StatementMatch is a statement returned by MatchesWithOptions along with
+// the level at which it matched the query and the reasons it matched.
+type StatementMatch struct {
+	Statement Statement
+	Level     MatchLevel
+
+	// Product is the statement product that matched the query.
+	Product Product
+
+	// ProductMatch describes how the product matched the queried product.
+	ProductMatch IdentifierMatch
+
+	// Subcomponent is the product subcomponent that matched the queried
+	// subcomponents. It is nil when the product lists no subcomponents or
+	// the query named none.
+	Subcomponent *Subcomponent
+
+	// SubcomponentMatch describes how the subcomponent matched.
+	SubcomponentMatch IdentifierMatch
+
+	// Timestamp is the effective timestamp of the statement: its own
+	// timestamp or, if it has none, the timestamp of the document.
+	Timestamp time.Time
+}
+
+// MatchLevel returns how specifically the statement matches the
+// vulnerability, product and subcomponents.
+func (stmt *Statement) MatchLevel(vuln, product string, subcomponents []string, opts *MatchOptions) MatchLevel {
+	return stmt.match(vuln, product, subcomponents, opts).Level
+}
+
+// match implements MatchLevel, returning the product and subcomponent that
+// matched the query. The statement and timestamp of the result are not set.
+func (stmt *Statement) match(vuln, product string, subcomponents []string, opts *MatchOptions) StatementMatch {
+	if opts == nil {
+		opts = &MatchOptions{}
+	}
+
+	if opts.Sanitize {
+		var err error
+		if vuln, product, subcomponents, err = sanitizeQuery(vuln, product, subcomponents); err != nil {
+			return StatementMatch{}
+		}
+	}
+
+	if stmt.Expired(clockNow(opts.Clock)) {
+		return StatementMatch{}
+	}
+
+	if !stmt.Vulnerability.Matches(vuln) && !stmt.Vulnerability.matchesAny(resolveAliases(opts, vuln)) {
+		return StatementMatch{}
+	}
+
+	ret := StatementMatch{Level: NoMatch}
+	for i := range stmt.Products {
+		p := &stmt.Products[i]
+		pm, ok := p.identityMatch(product, opts)
+		if !ok {
+			continue
+		}
+
+		if len(p.Subcomponents) == 0 {
+			return StatementMatch{Level: FullMatch, Product: *p, ProductMatch: pm}
+		}
+
+		if len(subcomponents) == 0 {
+			if opts.Subcomponents == SubcomponentsLenient && ret.Level == NoMatch {
+				ret = StatementMatch{Level: ProductLevelMatch, Product: *p, ProductMatch: pm}
+			}
+			continue
+		}
+
+		if sc, sm, ok := p.subcomponentMatch(subcomponents, opts); ok {
+			return StatementMatch{Level: FullMatch, Product: *p, ProductMatch: pm, Subcomponent: sc, SubcomponentMatch: sm}
+		}
+	}
+	return ret
+}
+
+// MatchesWithOptions returns the statements in the document that apply to
+// the product and vulnerability with the level at which they matched. The
+// statements are sorted according to the VEX history.
+func (vexDoc *VEX) MatchesWithOptions(vulnID, product string, subcomponents []string, opts *MatchOptions) []StatementMatch {
+	var t time.Time
+	if vexDoc.Timestamp != nil {
+		t = *vexDoc.Timestamp
+	}
+
+	matches := []StatementMatch{}
+
+	// Sanitize the query once instead of once per statement
+	if opts != nil && opts.Sanitize {
+		var err error
+		if vulnID, product, subcomponents, err = sanitizeQuery(vulnID, product, subcomponents); err != nil {
+			return matches
+		}
+		o := *opts
+		o.Sanitize = false
+		opts = &o
+	}
+
+	// Look up the aliases once instead of once per statement
+	if opts != nil && opts.Aliases != nil {
+		o := *opts
+		o.Aliases = MemoizeAliasResolver(opts.Aliases)
+		opts = &o
+	}
+
+	for i := range vexDoc.Statements {
+		m := vexDoc.Statements[i].match(vulnID, product, subcomponents, opts)
+		if m.Level == NoMatch {
+			continue
+		}
+		m.Statement = vexDoc.Statements[i]
+		m.Timestamp = t
+		if m.Statement.Timestamp != nil {
+			m.Timestamp = *m.Statement.Timestamp
+		}
+		matches = append(matches, m)
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return statementLess(&matches[i].Statement, &matches[j].Statement, t)
+	})
+	return matches
+}
@@ -0,0 +1,187 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchLevel(t *testing.T) {
+	image := "pkg:oci/alpine@sha256%3A124c7d2707904eea7431fffe91522a01e5a861a624ee31d03372cc1d138a3126"
+	libssl := "pkg:apk/alpine/libssl@3.0.8-r3"
+	withSubcomponents := &Statement{
+		Vulnerability: Vulnerability{Name: "CVE-2023-1255"},
+		Products: []Product{
+			{
+				Component:     Component{ID: image},
+				Subcomponents: []Subcomponent{{Component{ID: libssl}}},
+			},
+		},
+	}
+	productOnly := &Statement{
+		Vulnerability: Vulnerability{Name: "CVE-2023-1255"},
+		Products:      []Product{{Component: Component{ID: image}}},
+	}
+
+	for testCase, tc := range map[string]struct {
+		sut           *Statement
+		subcomponents []string
+		opts          *MatchOptions
+		expected      MatchLevel
+	}{
+		"lenient query without subcomponents": {withSubcomponents, nil, nil, ProductLevelMatch},
+		"strict query without subcomponents":  {withSubcomponents, nil, &MatchOptions{Subcomponents: SubcomponentsStrict}, NoMatch},
+		"query with subcomponent":             {withSubcomponents, []string{libssl}, nil, FullMatch},
+		"strict query with subcomponent":      {withSubcomponents, []string{libssl}, &MatchOptions{Subcomponents: SubcomponentsStrict}, FullMatch},
+		"query with other subcomponent":       {withSubcomponents, []string{"pkg:apk/alpine/busybox@1.36.0-r9"}, nil, NoMatch},
+		"no subcomponents in statement":       {productOnly, nil, &MatchOptions{Subcomponents: SubcomponentsStrict}, FullMatch},
+		"no subcomponents, query with one":    {productOnly, []string{libssl}, nil, FullMatch},
+	} {
+		require.Equal(t, tc.expected, tc.sut.MatchLevel("CVE-2023-1255", image, tc.subcomponents, tc.opts), testCase)
+	}
+
+	require.Equal(t, NoMatch, productOnly.MatchLevel("CVE-2023-0001", image, nil, nil))
+}
+
+func TestMatchesWithOptions(t *testing.T) {
+	now := time.Now()
+	before := now.Add(-time.Hour)
+	image := "pkg:oci/alpine@sha256%3A124c7d2707904eea7431fffe91522a01e5a861a624ee31d03372cc1d138a3126"
+	doc := &VEX{
+		Metadata: Metadata{Timestamp: &now},
+		Statements: []Statement{
+			{
+				Vulnerability: Vulnerability{Name: "CVE-2023-1255"},
+				Timestamp:     &now,
+				Products: []Product{
+					{
+						Component:     Component{ID: image},
+						Subcomponents: []Subcomponent{{Component{ID: "pkg:apk/alpine/libssl@3.0.8-r3"}}},
+					},
+				},
+				Status: StatusFixed,
+			},
+			{
+				Vulnerability: Vulnerability{Name: "CVE-2023-1255"},
+				Timestamp:     &before,
+				Products:      []Product{{Component: Component{ID: image}}},
+				Status:        StatusAffected,
+			},
+		},
+	}
+
+	matches := doc.MatchesWithOptions("CVE-2023-1255", image, nil, nil)
+	require.Len(t, matches, 2)
+	require.Equal(t, StatusAffected, matches[0].Statement.Status)
+	require.Equal(t, FullMatch, matches[0].Level)
+	require.Equal(t, StatusFixed, matches[1].Statement.Status)
+	require.Equal(t, ProductLevelMatch, matches[1].Level)
+
+	matches = doc.MatchesWithOptions("CVE-2023-1255", image, nil, &MatchOptions{Subcomponents: SubcomponentsStrict})
+	require.Len(t, matches, 1)
+	require.Equal(t, StatusAffected, matches[0].Statement.Status)
+}
+
+func TestMatchLevelIdentifiers(t *testing.T) {
+	stmt := &Statement{
+		Vulnerability: Vulnerability{Name: "CVE-2023-0001"},
+		Products: []Product{
+			{Component: Component{ID: "pkg:apk/wolfi/git@2.41.0-r0?arch=x86_64"}},
+			{Component: Component{
+				ID:     "https://example.com/products/Curl",
+				Hashes: map[Algorithm]Hash{SHA256: "E1A9A5C4F3F6C2A8B1D1C9EC2C5B6E8F0A9B4E2B8C3D1F2A6E7B9C0D1E2F3A4B"},
+			}},
+		},
+	}
+
+	for m, tc := range map[string]struct {
+		product  string
+		opts     *MatchOptions
+		expected MatchLevel
+	}{
+		"default":                      {"pkg:apk/wolfi/git@2.41.0-r0?arch=x86_64", nil, FullMatch},
+		"other version":                {"pkg:apk/wolfi/git@2.42.0-r0?arch=x86_64", nil, NoMatch},
+		"any version":                  {"pkg:apk/wolfi/git@2.42.0-r0?arch=x86_64", &MatchOptions{Versions: VersionsAny}, FullMatch},
+		"other arch":                   {"pkg:apk/wolfi/git@2.41.0-r0?arch=aarch64", nil, NoMatch},
+		"ignore qualifiers":            {"pkg:apk/wolfi/git@2.41.0-r0?arch=aarch64", &MatchOptions{IgnoreQualifiers: true}, FullMatch},
+		"different case id":            {"https://example.com/products/curl", nil, NoMatch},
+		"case insensitive id":          {"https://example.com/products/curl", &MatchOptions{CaseInsensitiveIdentifiers: true}, FullMatch},
+		"case insensitive hash":        {"e1a9a5c4f3f6c2a8b1d1c9ec2c5b6e8f0a9b4e2b8c3d1f2a6e7b9c0d1e2f3a4b", &MatchOptions{CaseInsensitiveIdentifiers: true}, FullMatch},
+		"case insensitive purl":        {"pkg:apk/wolfi/Git@2.41.0-r0?arch=x86_64", &MatchOptions{CaseInsensitiveIdentifiers: true}, FullMatch},
+		"case insensitive other value": {"https://example.com/products/wget", &MatchOptions{CaseInsensitiveIdentifiers: true}, NoMatch},
+	} {
+		require.Equal(t, tc.expected, stmt.MatchLevel("CVE-2023-0001", tc.product, nil, tc.opts), m)
+	}
+}
+
+func TestMatchesWithOptionsReasons(t *testing.T) {
+	issued := time.Date(2023, 6, 1, 10, 0, 0, 0, time.UTC)
+	stmtTime := issued.Add(time.Hour)
+	image := "pkg:oci/alpine@sha256%3A124c7d2707904eea7431fffe91522a01e5a861a624ee31d03372cc1d138a3126"
+	doc := &VEX{
+		Metadata: Metadata{Timestamp: &issued},
+		Statements: []Statement{
+			{
+				Vulnerability: Vulnerability{Name: "CVE-2023-1255"},
+				Products: []Product{{
+					Component: Component{ID: image},
+					Subcomponents: []Subcomponent{{Component{
+						Identifiers: map[IdentifierType]string{PURL: "pkg:apk/alpine/libssl3@3.0.8-r3"},
+						Hashes:      map[Algorithm]Hash{SHA256: "E1A9A5C4F3F6C2A8B1D1C9EC2C5B6E8F0A9B4E2B8C3D1F2A6E7B9C0D1E2F3A4B"},
+					}}},
+				}},
+				Status:        StatusNotAffected,
+				Justification: VulnerableCodeNotPresent,
+			},
+			{
+				Vulnerability: Vulnerability{Name: "CVE-2023-1255"},
+				Timestamp:     &stmtTime,
+				Products: []Product{{Component: Component{
+					Hashes: map[Algorithm]Hash{SHA512: "0000", SHA256: "124c7d2707904eea7431fffe91522a01e5a861a624ee31d03372cc1d138a3126"},
+				}}},
+				Status: StatusAffected,
+			},
+		},
+	}
+
+	// Purl in the component ID and purl identifier in the subcomponent
+	matches := doc.MatchesWithOptions("CVE-2023-1255", image, []string{"pkg:apk/alpine/libssl3@3.0.8-r3"}, nil)
+	require.Len(t, matches, 1)
+	require.Equal(t, FullMatch, matches[0].Level)
+	require.Equal(t, issued, matches[0].Timestamp)
+	require.Equal(t, image, matches[0].Product.ID)
+	require.Equal(t, IdentifierMatch{Kind: MatchByID, Type: PURL, Value: image, Query: image}, matches[0].ProductMatch)
+	require.NotNil(t, matches[0].Subcomponent)
+	require.Equal(t, IdentifierMatch{
+		Kind: MatchByIdentifier, Type: PURL, Value: "pkg:apk/alpine/libssl3@3.0.8-r3", Query: "pkg:apk/alpine/libssl3@3.0.8-r3",
+	}, matches[0].SubcomponentMatch)
+
+	// Case insensitive hash in the subcomponent, the original value is reported
+	matches = doc.MatchesWithOptions(
+		"CVE-2023-1255", image, []string{"e1a9a5c4f3f6c2a8b1d1c9ec2c5b6e8f0a9b4e2b8c3d1f2a6e7b9c0d1e2f3a4b"},
+		&MatchOptions{CaseInsensitiveIdentifiers: true},
+	)
+	require.Len(t, matches, 1)
+	require.Equal(t, MatchByHash, matches[0].SubcomponentMatch.Kind)
+	require.Equal(t, SHA256, matches[0].SubcomponentMatch.Algorithm)
+	require.Equal(t, "E1A9A5C4F3F6C2A8B1D1C9EC2C5B6E8F0A9B4E2B8C3D1F2A6E7B9C0D1E2F3A4B", matches[0].SubcomponentMatch.Value)
+
+	// Product level match and hash matched through the resolver
+	resolver := func(string) ([]string, error) {
+		return []string{"124c7d2707904eea7431fffe91522a01e5a861a624ee31d03372cc1d138a3126"}, nil
+	}
+	matches = doc.MatchesWithOptions("CVE-2023-1255", image, nil, &MatchOptions{Resolver: resolver})
+	require.Len(t, matches, 2)
+	require.Equal(t, ProductLevelMatch, matches[0].Level)
+	require.Nil(t, matches[0].Subcomponent)
+	require.Equal(t, FullMatch, matches[1].Level)
+	require.Equal(t, stmtTime, matches[1].Timestamp)
+	require.Equal(t, IdentifierMatch{
+		Kind: MatchByHash, Algorithm: SHA256, Value: "124c7d2707904eea7431fffe91522a01e5a861a624ee31d03372cc1d138a3126",
+		Query: "124c7d2707904eea7431fffe91522a01e5a861a624ee31d03372cc1d138a3126",
+	}, matches[1].ProductMatch)
+}
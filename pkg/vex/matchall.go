@@ -0,0 +1,159 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// Query asks for the statements applying to a vulnerability in a product.
+type Query struct {
+	Vulnerability string
+	Product       string
+	Subcomponents []string
+}
+
+// MatchAllOptions control the evaluation of queries by MatchAll.
+type MatchAllOptions struct {
+	// MatchOptions configure the matching of the statements.
+	MatchOptions
+
+	// Workers is the number of queries evaluated concurrently. It defaults
+	// to GOMAXPROCS.
+	Workers int
+}
+
+// MatchResult is the result of a query.
+type MatchResult struct {
+	// Query is the evaluated query.
+	Query Query
+
+	// Statements are the statements of all the documents that apply to the
+	// query, sorted by date. Statements without a timestamp get the one of
+	// their document.
+	Statements []Statement
+
+	// Status is the status of the latest statement, empty when no statement
+	// applies.
+	Status Status
+}
+
+// MatchAll evaluates the queries against the documents with a pool of
+// workers and returns the results in the order of the queries. Each result
+// aggregates the statements of all the documents, like CoverageReport.
+//
+// With the default match options, queries without subcomponents are
+// matched using a StatementIndex of each document, built once before
+// evaluating them. The documents must not be modified while MatchAll runs.
+//
+// Aliases are looked up with ctx unless the options set a context. MatchAll
+// fails if a lookup fails and the options set no AliasError handler.
+func MatchAll(ctx context.Context, docs []*VEX, queries []Query, opts *MatchAllOptions) ([]MatchResult, error) {
+	if opts == nil {
+		opts = &MatchAllOptions{}
+	}
+	workers := opts.Workers
+	if workers < 1 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	var indexes []*StatementIndex
+	matchOpts := opts.MatchOptions
+	if matchOpts.isDefault() {
+		indexes = make([]*StatementIndex, len(docs))
+		for i, doc := range docs {
+			indexes[i] = doc.BuildIndex()
+		}
+	}
+	var aliasErr error
+	var aliasMutex sync.Mutex
+	if matchOpts.Aliases != nil {
+		// Share the alias lookups among the workers
+		matchOpts.Aliases = MemoizeAliasResolver(matchOpts.Aliases)
+		if matchOpts.Context == nil {
+			matchOpts.Context = ctx
+		}
+		if matchOpts.AliasError == nil {
+			matchOpts.AliasError = func(err error) {
+				aliasMutex.Lock()
+				defer aliasMutex.Unlock()
+				if aliasErr == nil {
+					aliasErr = err
+				}
+			}
+		}
+	}
+
+	results := make([]MatchResult, len(queries))
+	ch := make(chan int)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range ch {
+				results[i] = matchQuery(docs, indexes, &queries[i], &matchOpts)
+			}
+		}()
+	}
+
+	for i := range queries {
+		if ctx.Err() != nil {
+			break
+		}
+		ch <- i
+	}
+	close(ch)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("matching queries: %w", err)
+	}
+	if aliasErr != nil {
+		return nil, fmt.Errorf("matching queries: %w", aliasErr)
+	}
+	return results, nil
+}
+
+// isDefault returns true if the options match statements like VEX.Matches.
+func (opts *MatchOptions) isDefault() bool {
+	return opts.Subcomponents == SubcomponentsLenient && opts.Resolver == nil &&
+		opts.Aliases == nil && opts.Distro == DistroStrict && !opts.Sanitize &&
+		opts.Versions == VersionsRange && !opts.IgnoreQualifiers && !opts.CaseInsensitiveIdentifiers &&
+		!opts.Wildcards && opts.Clock == nil
+}
+
+// matchQuery evaluates a query against the documents. indexes holds the
+// statement index of each document, when set it is used for queries
+// without subcomponents.
+func matchQuery(docs []*VEX, indexes []*StatementIndex, q *Query, opts *MatchOptions) MatchResult {
+	res := MatchResult{Query: *q, Statements: []Statement{}}
+	for i, doc := range docs {
+		var stmts []Statement
+		if indexes != nil && len(q.Subcomponents) == 0 {
+			stmts = indexes[i].Matches(q.Vulnerability, q.Product)
+		} else {
+			for _, m := range doc.MatchesWithOptions(q.Vulnerability, q.Product, q.Subcomponents, opts) {
+				stmts = append(stmts, m.Statement)
+			}
+		}
+		for j := range stmts {
+			// Cascade the document timestamp to sort across documents
+			if stmts[j].Timestamp == nil && doc.Timestamp != nil {
+				ts := *doc.Timestamp
+				stmts[j].Timestamp = &ts
+			}
+		}
+		res.Statements = append(res.Statements, stmts...)
+	}
+	if len(res.Statements) > 0 {
+		SortStatements(res.Statements, time.Time{})
+		res.Status = res.Statements[len(res.Statements)-1].Status
+	}
+	return res
+}
@@ -0,0 +1,86 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchAll(t *testing.T) {
+	now := time.Now()
+	before := now.Add(-time.Hour)
+
+	older := New()
+	older.Timestamp = &before
+	newer := New()
+	newer.Timestamp = &now
+	for i := range 20 {
+		older.Statements = append(older.Statements, Statement{
+			Vulnerability: Vulnerability{Name: VulnerabilityID(fmt.Sprintf("CVE-2023-%04d", i))},
+			Products:      []Product{{Component: Component{ID: fmt.Sprintf("pkg:apk/wolfi/pkg%d", i)}}},
+			Status:        StatusAffected,
+		})
+		if i%2 == 0 {
+			newer.Statements = append(newer.Statements, Statement{
+				Vulnerability: Vulnerability{Name: VulnerabilityID(fmt.Sprintf("CVE-2023-%04d", i))},
+				Products: []Product{{
+					Component:     Component{ID: fmt.Sprintf("pkg:apk/wolfi/pkg%d@1.0.0", i)},
+					Subcomponents: []Subcomponent{{Component: Component{ID: "pkg:golang/example.com/lib@v1.0.0"}}},
+				}},
+				Status: StatusFixed,
+			})
+		}
+	}
+	docs := []*VEX{&older, &newer}
+
+	queries := []Query{}
+	for i := range 20 {
+		for j := range 20 {
+			queries = append(queries, Query{
+				Vulnerability: fmt.Sprintf("CVE-2023-%04d", i),
+				Product:       fmt.Sprintf("pkg:apk/wolfi/pkg%d@1.0.0", j),
+			})
+		}
+	}
+	queries = append(queries, Query{
+		Vulnerability: "CVE-2023-0002",
+		Product:       "pkg:apk/wolfi/pkg2@1.0.0",
+		Subcomponents: []string{"pkg:golang/example.com/lib@v1.0.0"},
+	})
+
+	results, err := MatchAll(context.Background(), docs, queries, &MatchAllOptions{Workers: 4})
+	require.NoError(t, err)
+	require.Len(t, results, len(queries))
+
+	// Results are the same as the sequential coverage report
+	for i := range queries[:400] {
+		q := &queries[i]
+		expected := CoverageReport([]string{q.Vulnerability}, q.Product, docs)[0]
+		require.Equal(t, *q, results[i].Query)
+		require.Equal(t, expected.Status, results[i].Status, q)
+		require.Equal(t, expected.Statements, results[i].Statements, q)
+	}
+
+	require.Equal(t, StatusFixed, results[2*20+2].Status)
+	require.Equal(t, StatusAffected, results[3*20+3].Status)
+	require.Empty(t, results[1].Status)
+	require.Equal(t, StatusFixed, results[400].Status)
+
+	// Other match options evaluate the documents without the index
+	unindexed, err := MatchAll(context.Background(), docs, queries, &MatchAllOptions{
+		MatchOptions: MatchOptions{IgnoreQualifiers: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, results, unindexed)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = MatchAll(ctx, docs, queries, nil)
+	require.ErrorIs(t, err, context.Canceled)
+}
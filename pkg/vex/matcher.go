@@ -0,0 +1,174 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/openvex/go-vex/pkg/csaf"
+)
+
+// DocumentFormat identifies the format of the documents evaluated by a
+// Matcher.
+type DocumentFormat string
+
+const (
+	FormatOpenVEX DocumentFormat = "openvex"
+	FormatCSAF    DocumentFormat = "csaf"
+)
+
+// Match is a statement that applies to a query evaluated by a Matcher. CSAF
+// data is normalized into an OpenVEX statement.
+type Match struct {
+	// Format is the format of the document where the match was found.
+	Format DocumentFormat
+
+	// DocumentID is the @id of the OpenVEX document or the tracking ID of
+	// the CSAF document.
+	DocumentID string
+
+	// Statement is the matching statement.
+	Statement Statement
+}
+
+// Matcher evaluates vulnerability and product queries against a mixed corpus
+// of OpenVEX and CSAF documents. CSAF documents are converted with FromCSAF
+// when added, so their statements are the same ones the conversion returns.
+type Matcher struct {
+	vexDocs  []*VEX
+	csafDocs []*VEX
+}
+
+// NewMatcher returns a new matcher loaded with no documents.
+func NewMatcher() *Matcher {
+	return &Matcher{
+		vexDocs:  []*VEX{},
+		csafDocs: []*VEX{},
+	}
+}
+
+// AddVEX adds OpenVEX documents to the matcher.
+func (m *Matcher) AddVEX(docs ...*VEX) {
+	m.vexDocs = append(m.vexDocs, docs...)
+}
+
+// AddCSAF converts CSAF documents to OpenVEX and adds them to the matcher.
+// If a document fails to convert, none of the documents are added.
+func (m *Matcher) AddCSAF(docs ...*csaf.CSAF) error {
+	converted := make([]*VEX, 0, len(docs))
+	for _, doc := range docs {
+		v, err := FromCSAF(doc)
+		if err != nil {
+			return fmt.Errorf("converting csaf doc: %w", err)
+		}
+		converted = append(converted, v)
+	}
+	m.csafDocs = append(m.csafDocs, converted...)
+	return nil
+}
+
+// AddFile detects the format of the document at path and adds it to the
+// matcher. OpenVEX documents in older spec versions are upgraded when read.
+func (m *Matcher) AddFile(path string) error {
+	data, err := os.ReadFile(path) //nolint:gosec // This is supposed to open user-specified paths
+	if err != nil {
+		return fmt.Errorf("opening document: %w", err)
+	}
+
+	if isCSAF(data) {
+		doc, err := csaf.Parse(data)
+		if err != nil {
+			return fmt.Errorf("opening csaf doc: %w", err)
+		}
+		return m.AddCSAF(doc)
+	}
+
+	doc, err := Open(path)
+	if err != nil {
+		return err
+	}
+	m.AddVEX(doc)
+	return nil
+}
+
+// Matches returns the statements in all the documents of the matcher that
+// apply to the vulnerability, product and subcomponents.
+func (m *Matcher) Matches(vulnID, product string, subcomponents []string) []Match {
+	ret := []Match{}
+	for _, doc := range m.vexDocs {
+		for _, stmt := range doc.Matches(vulnID, product, subcomponents) {
+			ret = append(ret, Match{
+				Format:     FormatOpenVEX,
+				DocumentID: doc.ID,
+				Statement:  stmt,
+			})
+		}
+	}
+
+	for _, doc := range m.csafDocs {
+		for _, stmt := range doc.Matches(vulnID, product, subcomponents) {
+			ret = append(ret, Match{
+				Format:     FormatCSAF,
+				DocumentID: doc.ID,
+				Statement:  stmt,
+			})
+		}
+	}
+	return ret
+}
+
+// csafProducts indexes the products in the CSAF product tree by their
+// product ID. Products defined by relationships are also listed as the
+// platform they end up in with the base product as subcomponent, eg "X as
+// component of Y installed on Z" is also listed as Z with subcomponent X.
+// Products listed in several branches get the identifiers of all of them.
+func csafProducts(doc *csaf.CSAF) map[string][]Product {
+	resolver := csaf.NewProductResolver(&doc.ProductTree)
+	ret := map[string][]Product{}
+	for _, p := range doc.ProductTree.ListProducts() {
+		if rp, err := resolver.Resolve(p.ID); err == nil {
+			p = rp.Product
+		}
+		ret[p.ID] = []Product{{Component: componentFromCSAF(p)}}
+	}
+
+	for _, r := range doc.ProductTree.Relationships {
+		products := []Product{{Component: componentFromCSAF(r.FullProductName)}}
+		if rp, err := resolver.Resolve(r.FullProductName.ID); err == nil {
+			products = append(products, Product{
+				Component:     componentFromCSAF(rp.Platform().Product),
+				Subcomponents: []Subcomponent{{Component: componentFromCSAF(rp.Base().Product)}},
+			})
+		}
+		ret[r.FullProductName.ID] = products
+	}
+	return ret
+}
+
+// componentFromCSAF builds a component from a CSAF product, using its
+// identification helpers as software identifiers.
+func componentFromCSAF(p csaf.Product) Component {
+	c := Component{ID: p.ID}
+	for helper, value := range p.IdentificationHelper {
+		var t IdentifierType
+		switch helper {
+		case "purl":
+			t = PURL
+		case "cpe":
+			t = CPE23
+			if strings.HasPrefix(value, "cpe:/") {
+				t = CPE22
+			}
+		default:
+			continue
+		}
+		if c.Identifiers == nil {
+			c.Identifiers = map[IdentifierType]string{}
+		}
+		c.Identifiers[t] = value
+	}
+	return c
+}
@@ -0,0 +1,131 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/openvex/go-vex/pkg/csaf"
+)
+
+func TestMatcher(t *testing.T) {
+	m := NewMatcher()
+	require.NoError(t, m.AddFile("testdata/csaf.json"))
+	require.NoError(t, m.AddFile("testdata/v020-1.vex.json"))
+	require.Error(t, m.AddFile("testdata/non-existent.json"))
+
+	// CSAF documents are matched as converted by FromCSAF
+	matches := m.Matches("CVE-2009-4487", "pkg:golang/github.com/go-homedir@v1.2.0", nil)
+	require.Len(t, matches, 1)
+	require.Equal(t, FormatCSAF, matches[0].Format)
+	require.Equal(t, "2022-EVD-UC-01-NA-001", matches[0].DocumentID)
+	require.Equal(t, StatusNotAffected, matches[0].Statement.Status)
+	require.Equal(t, "Class with vulnerable code was removed before shipping.", matches[0].Statement.ImpactStatement)
+
+	require.Len(t, m.Matches("CVE-2009-4487", "CSAFPID-0001", nil), 1)
+	require.Empty(t, m.Matches("CVE-2009-4487", "pkg:golang/github.com/go-homedir@v1.3.0", nil))
+
+	// OpenVEX documents are matched as usual
+	matches = m.Matches("CVE-9876-54321", "pkg:apk/wolfi/bash@1.0.0", nil)
+	require.Len(t, matches, 1)
+	require.Equal(t, FormatOpenVEX, matches[0].Format)
+
+	// Relationships match as product + subcomponent
+	require.NoError(t, m.AddCSAF(&csaf.CSAF{
+		Document: csaf.DocumentMetadata{Tracking: csaf.Tracking{ID: "RHSA-TEST"}},
+		ProductTree: csaf.ProductBranch{
+			Branches: []csaf.ProductBranch{
+				{Product: csaf.Product{ID: "rhel-8", IdentificationHelper: map[string]string{"cpe": "cpe:/o:redhat:enterprise_linux:8"}}},
+				{Product: csaf.Product{ID: "qemu-kvm", IdentificationHelper: map[string]string{"purl": "pkg:rpm/redhat/qemu-kvm@4.1.0"}}},
+				{Product: csaf.Product{ID: "seabios", IdentificationHelper: map[string]string{"purl": "pkg:rpm/redhat/seabios@1.12.0"}}},
+			},
+			Relationships: []csaf.Relationship{
+				{
+					Category:            "default_component_of",
+					FullProductName:     csaf.Product{ID: "rhel-8:qemu-kvm"},
+					ProductRef:          "qemu-kvm",
+					RelatesToProductRef: "rhel-8",
+				},
+				{
+					// Nested relationship, resolves to rhel-8 with seabios
+					Category:            "installed_with",
+					FullProductName:     csaf.Product{ID: "rhel-8:qemu-kvm:seabios"},
+					ProductRef:          "seabios",
+					RelatesToProductRef: "rhel-8:qemu-kvm",
+				},
+			},
+		},
+		Vulnerabilities: []csaf.Vulnerability{
+			{
+				CVE:           "CVE-2020-1711",
+				IDs:           []csaf.TrackingID{{SystemName: "Red Hat Bugzilla ID", Text: "1794290"}},
+				ProductStatus: map[string][]string{"fixed": {"rhel-8:qemu-kvm"}},
+			},
+			{
+				CVE:           "CVE-2019-0000",
+				ProductStatus: map[string][]string{"fixed": {"rhel-8:qemu-kvm:seabios"}},
+			},
+			{
+				CVE:           "CVE-2021-0001",
+				ProductStatus: map[string][]string{"first_affected": {"rhel-8:qemu-kvm"}},
+			},
+		},
+	}))
+
+	for testCase, tc := range map[string]struct {
+		vuln          string
+		product       string
+		subcomponents []string
+		expected      int
+	}{
+		"full product name":  {"CVE-2020-1711", "rhel-8:qemu-kvm", nil, 1},
+		"parent product":     {"CVE-2020-1711", "cpe:/o:redhat:enterprise_linux:8", nil, 1},
+		"parent and child":   {"CVE-2020-1711", "rhel-8", []string{"pkg:rpm/redhat/qemu-kvm@4.1.0"}, 1},
+		"alias":              {"1794290", "rhel-8", nil, 1},
+		"other subcomponent": {"CVE-2020-1711", "rhel-8", []string{"pkg:rpm/redhat/libvirt@4.5.0"}, 0},
+		"child alone":        {"CVE-2020-1711", "qemu-kvm", nil, 0},
+		"nested":             {"CVE-2019-0000", "rhel-8", []string{"pkg:rpm/redhat/seabios@1.12.0"}, 1},
+		"nested other":       {"CVE-2019-0000", "rhel-8", []string{"pkg:rpm/redhat/qemu-kvm@4.1.0"}, 0},
+	} {
+		matches := m.Matches(tc.vuln, tc.product, tc.subcomponents)
+		require.Len(t, matches, tc.expected, testCase)
+		for _, match := range matches {
+			require.Equal(t, "RHSA-TEST", match.DocumentID, testCase)
+			require.Equal(t, StatusFixed, match.Statement.Status, testCase)
+		}
+	}
+
+	// All the product status categories are converted
+	matches = m.Matches("CVE-2021-0001", "rhel-8:qemu-kvm", nil)
+	require.Len(t, matches, 1)
+	require.Equal(t, StatusAffected, matches[0].Statement.Status)
+
+	// Documents that fail to convert are not added
+	require.Error(t, m.AddCSAF(&csaf.CSAF{Vulnerabilities: []csaf.Vulnerability{
+		{CVE: "CVE-2021-0002", ProductStatus: map[string][]string{"unknown": {"rhel-8"}}},
+	}}))
+	require.Empty(t, m.Matches("CVE-2021-0002", "rhel-8", nil))
+}
+
+func TestMatcherAddFileFormat(t *testing.T) {
+	// OpenVEX documents carrying CSAF fields are not read as CSAF
+	doc := genTestDoc(t)
+	doc.Context = ContextLocator()
+	doc.Statements[0].Annotations = map[string]string{"csaf_version": "2.0"}
+	path := filepath.Join(t.TempDir(), "doc.json")
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	require.NoError(t, doc.ToJSON(f))
+	require.NoError(t, f.Close())
+
+	m := NewMatcher()
+	require.NoError(t, m.AddFile(path))
+	matches := m.Matches("CVE-1234-5678", doc.Statements[0].Products[0].ID, []string{"pkg:apk/wolfi/bash@1.0.0"})
+	require.Len(t, matches, 1)
+	require.Equal(t, FormatOpenVEX, matches[0].Format)
+}
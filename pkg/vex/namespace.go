@@ -0,0 +1,96 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// VulnerabilityNamespace maps the local identifiers of a vulnerability
+// tracking system to the IRIs that locate them.
+type VulnerabilityNamespace struct {
+	// Prefix is the prefix of the local identifiers in the namespace,
+	// eg "CVE-".
+	Prefix string
+
+	// IRIs lists the IRI prefixes that are prepended to the local
+	// identifiers to form their IRIs. The first one is used when
+	// de-localizing identifiers, the rest are only recognized.
+	IRIs []string
+}
+
+var (
+	namespaceMutex          sync.RWMutex
+	vulnerabilityNamespaces = []VulnerabilityNamespace{
+		{Prefix: "CVE-", IRIs: []string{"https://nvd.nist.gov/vuln/detail/", "https://www.cve.org/CVERecord?id="}},
+		{Prefix: "GHSA-", IRIs: []string{"https://github.com/advisories/"}},
+		{Prefix: "GO-", IRIs: []string{"https://pkg.go.dev/vuln/"}},
+		{Prefix: "OSV-", IRIs: []string{"https://osv.dev/vulnerability/"}},
+	}
+)
+
+// RegisterVulnerabilityNamespace registers a new vulnerability namespace to
+// extend the identifiers that can be normalized. Registering a namespace with
+// a prefix that already exists returns an error.
+func RegisterVulnerabilityNamespace(ns VulnerabilityNamespace) error {
+	if ns.Prefix == "" {
+		return errors.New("namespace prefix cannot be empty")
+	}
+	if len(ns.IRIs) == 0 {
+		return errors.New("namespace must define at least one IRI prefix")
+	}
+	namespaceMutex.Lock()
+	defer namespaceMutex.Unlock()
+	for _, existing := range vulnerabilityNamespaces {
+		if existing.Prefix == ns.Prefix {
+			return fmt.Errorf("vulnerability namespace %q is already registered", ns.Prefix)
+		}
+	}
+	vulnerabilityNamespaces = append(vulnerabilityNamespaces, ns)
+	return nil
+}
+
+// IRI returns the full IRI of the vulnerability identifier (ie de-localizes
+// it). If the identifier is already an IRI it is returned unchanged. If it
+// does not belong to a registered namespace, IRI returns an empty string.
+func (id VulnerabilityID) IRI() string {
+	if id.isIRI() {
+		return string(id)
+	}
+	namespaceMutex.RLock()
+	defer namespaceMutex.RUnlock()
+	for _, ns := range vulnerabilityNamespaces {
+		if strings.HasPrefix(string(id), ns.Prefix) {
+			return ns.IRIs[0] + string(id)
+		}
+	}
+	return ""
+}
+
+// Local returns the local identifier of a vulnerability IRI. Identifiers
+// that are not IRIs of a registered namespace are returned unchanged.
+func (id VulnerabilityID) Local() VulnerabilityID {
+	if !id.isIRI() {
+		return id
+	}
+	namespaceMutex.RLock()
+	defer namespaceMutex.RUnlock()
+	for _, ns := range vulnerabilityNamespaces {
+		for _, iri := range ns.IRIs {
+			local, ok := strings.CutPrefix(string(id), iri)
+			if ok && strings.HasPrefix(local, ns.Prefix) {
+				return VulnerabilityID(local)
+			}
+		}
+	}
+	return id
+}
+
+// isIRI returns true if the identifier looks like an http(s) IRI.
+func (id VulnerabilityID) isIRI() bool {
+	return strings.HasPrefix(string(id), "https://") || strings.HasPrefix(string(id), "http://")
+}
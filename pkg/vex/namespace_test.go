@@ -0,0 +1,66 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVulnerabilityIDNormalization(t *testing.T) {
+	for m, tc := range map[string]struct {
+		id    VulnerabilityID
+		iri   string
+		local VulnerabilityID
+	}{
+		"CVE":             {"CVE-2023-1234", "https://nvd.nist.gov/vuln/detail/CVE-2023-1234", "CVE-2023-1234"},
+		"CVE IRI":         {"https://nvd.nist.gov/vuln/detail/CVE-2023-1234", "https://nvd.nist.gov/vuln/detail/CVE-2023-1234", "CVE-2023-1234"},
+		"CVE.org IRI":     {"https://www.cve.org/CVERecord?id=CVE-2023-1234", "https://www.cve.org/CVERecord?id=CVE-2023-1234", "CVE-2023-1234"},
+		"GHSA":            {"GHSA-92xj-mqp7-vmcj", "https://github.com/advisories/GHSA-92xj-mqp7-vmcj", "GHSA-92xj-mqp7-vmcj"},
+		"unknown":         {"MY-VULN-1", "", "MY-VULN-1"},
+		"unknown IRI":     {"https://example.com/vuln/1", "https://example.com/vuln/1", "https://example.com/vuln/1"},
+		"mismatched path": {"https://github.com/advisories/CVE-2023-1234", "https://github.com/advisories/CVE-2023-1234", "https://github.com/advisories/CVE-2023-1234"},
+	} {
+		require.Equal(t, tc.iri, tc.id.IRI(), m)
+		require.Equal(t, tc.local, tc.id.Local(), m)
+	}
+}
+
+func TestVulnerabilityMatchesIRI(t *testing.T) {
+	v := Vulnerability{
+		Name:    "CVE-2023-1234",
+		Aliases: []VulnerabilityID{"https://github.com/advisories/GHSA-92xj-mqp7-vmcj"},
+	}
+	require.True(t, v.Matches("CVE-2023-1234"))
+	require.True(t, v.Matches("https://nvd.nist.gov/vuln/detail/CVE-2023-1234"))
+	require.True(t, v.Matches("https://www.cve.org/CVERecord?id=CVE-2023-1234"))
+	require.True(t, v.Matches("GHSA-92xj-mqp7-vmcj"))
+	require.False(t, v.Matches("CVE-2023-9999"))
+
+	v = Vulnerability{ID: "https://nvd.nist.gov/vuln/detail/CVE-2023-1234"}
+	require.True(t, v.Matches("CVE-2023-1234"))
+}
+
+func TestRegisterVulnerabilityNamespace(t *testing.T) {
+	namespaceMutex.Lock()
+	registered := slices.Clone(vulnerabilityNamespaces)
+	namespaceMutex.Unlock()
+	t.Cleanup(func() {
+		namespaceMutex.Lock()
+		vulnerabilityNamespaces = registered
+		namespaceMutex.Unlock()
+	})
+
+	require.Error(t, RegisterVulnerabilityNamespace(VulnerabilityNamespace{}))
+	require.Error(t, RegisterVulnerabilityNamespace(VulnerabilityNamespace{Prefix: "TEST-"}))
+	require.Error(t, RegisterVulnerabilityNamespace(VulnerabilityNamespace{Prefix: "CVE-", IRIs: []string{"https://example.com/"}}))
+
+	require.NoError(t, RegisterVulnerabilityNamespace(VulnerabilityNamespace{
+		Prefix: "TEST-", IRIs: []string{"https://example.com/vulns/"},
+	}))
+	require.Equal(t, "https://example.com/vulns/TEST-1", VulnerabilityID("TEST-1").IRI())
+	require.Equal(t, VulnerabilityID("TEST-1"), VulnerabilityID("https://example.com/vulns/TEST-1").Local())
+}
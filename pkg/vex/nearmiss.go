@@ -0,0 +1,222 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/package-url/packageurl-go"
+)
+
+// Fields reported in near misses.
+const (
+	// NearMissVersion means the purls only differ in their version or the
+	// queried version is out of the version range of the statement.
+	NearMissVersion = "version"
+
+	// NearMissQualifiers means the purls only differ in their qualifiers.
+	NearMissQualifiers = "qualifiers"
+
+	// NearMissHashes means the queried digest is one of the statement hashes
+	// but under a different algorithm or notation.
+	NearMissHashes = "hashes"
+
+	// NearMissSubcomponents means the product matched but none of the
+	// queried subcomponents is listed in the statement.
+	NearMissSubcomponents = "subcomponents"
+)
+
+// NearMiss describes why a statement about the queried vulnerability almost
+// applied to the queried product or subcomponents.
+type NearMiss struct {
+	// Statement is the statement that did not match
+	Statement Statement
+
+	// Component is the identifier of the statement product or subcomponent
+	// that almost matched.
+	Component string
+
+	// Field is the field that differs, one of the NearMiss* constants
+	Field string
+
+	// Expected is the value of the field in the statement
+	Expected string
+
+	// Actual is the value of the field in the query
+	Actual string
+}
+
+// NearMisses returns diagnostics about the statements in the document that
+// are about the vulnerability but do not apply to the product and
+// subcomponents because of small differences: a different package version,
+// a qualifier mismatch, the same digest under another algorithm or
+// unlisted subcomponents.
+func (vexDoc *VEX) NearMisses(vulnID, product string, subcomponents []string, opts *MatchOptions) []NearMiss {
+	ret := []NearMiss{}
+	for i := range vexDoc.Statements {
+		ret = append(ret, vexDoc.Statements[i].NearMisses(vulnID, product, subcomponents, opts)...)
+	}
+	return ret
+}
+
+// NearMisses returns the differences that keep the statement from applying
+// to the product and subcomponents. It returns nothing if the statement
+// matches or is about another vulnerability.
+func (stmt *Statement) NearMisses(vuln, product string, subcomponents []string, opts *MatchOptions) []NearMiss {
+	if opts == nil {
+		opts = &MatchOptions{}
+	}
+	if opts.Sanitize {
+		var err error
+		if vuln, product, subcomponents, err = sanitizeQuery(vuln, product, subcomponents); err != nil {
+			return nil
+		}
+	}
+
+	if !stmt.Vulnerability.Matches(vuln) || stmt.MatchLevel(vuln, product, subcomponents, opts) != NoMatch {
+		return nil
+	}
+
+	ret := []NearMiss{}
+	add := func(c *Component, field, expected, actual string) {
+		ret = append(ret, NearMiss{
+			Statement: *stmt, Component: c.displayName(), Field: field, Expected: expected, Actual: actual,
+		})
+	}
+
+	for i := range stmt.Products {
+		p := &stmt.Products[i]
+		if !p.identityMatches(product, opts) {
+			for _, c := range p.components() {
+				for _, d := range componentNearMisses(c, product, opts) {
+					add(c, d[0], d[1], d[2])
+				}
+			}
+			continue
+		}
+
+		// The product matched, look for subcomponents that almost did
+		found := false
+		for j := range p.Subcomponents {
+			for _, sc := range subcomponents {
+				for _, d := range componentNearMisses(&p.Subcomponents[j].Component, sc, opts) {
+					add(&p.Subcomponents[j].Component, d[0], d[1], d[2])
+					found = true
+				}
+			}
+		}
+		if !found && len(p.Subcomponents) > 0 {
+			listed := []string{}
+			for j := range p.Subcomponents {
+				listed = append(listed, p.Subcomponents[j].displayName())
+			}
+			add(&p.Component, NearMissSubcomponents, strings.Join(listed, ", "), strings.Join(subcomponents, ", "))
+		}
+	}
+	return ret
+}
+
+// componentNearMisses returns the field, expected and actual values of the
+// differences between a component and an identifier that almost matches it.
+func componentNearMisses(c *Component, identifier string, opts *MatchOptions) [][3]string {
+	ret := [][3]string{}
+
+	purls := []string{}
+	if strings.HasPrefix(c.ID, "pkg:") {
+		purls = append(purls, c.ID)
+	}
+	if purl := c.Identifiers[PURL]; purl != "" && purl != c.ID {
+		purls = append(purls, purl)
+	}
+	for _, purl := range purls {
+		if d, ok := purlNearMiss(purl, identifier, opts.purlOptions()); ok {
+			ret = append(ret, d)
+		}
+	}
+
+	// The digest in the query, either as algo:hex or as the version of an
+	// OCI purl.
+	digest := identifier
+	if strings.HasPrefix(identifier, "pkg:oci/") {
+		if p, err := packageurl.FromString(identifier); err == nil {
+			digest = p.Version
+		}
+	}
+	_, hex, ok := strings.Cut(digest, ":")
+	if !ok || strings.HasPrefix(digest, "pkg:") {
+		return ret
+	}
+	algos := []string{}
+	for algo := range c.Hashes {
+		algos = append(algos, string(algo))
+	}
+	sort.Strings(algos)
+	for _, algo := range algos {
+		if h := c.Hashes[Algorithm(algo)]; strings.EqualFold(string(h), hex) {
+			ret = append(ret, [3]string{NearMissHashes, algo + ":" + string(h), digest})
+		}
+	}
+	return ret
+}
+
+// purlNearMiss compares a statement purl to a queried purl of the same
+// package and returns the field, expected and actual values of the first
+// difference found.
+func purlNearMiss(purl1, purl2 string, opts *PurlMatchOptions) ([3]string, bool) {
+	if !strings.HasPrefix(purl2, "pkg:") || PurlMatchesWithOptions(purl1, purl2, opts) {
+		return [3]string{}, false
+	}
+	p1, err := packageurl.FromString(purl1)
+	if err != nil {
+		return [3]string{}, false
+	}
+	p2, err := packageurl.FromString(purl2)
+	if err != nil {
+		return [3]string{}, false
+	}
+	if p1.Type != p2.Type || p1.Namespace != p2.Namespace || p1.Name != p2.Name {
+		return [3]string{}, false
+	}
+
+	if opts == nil {
+		opts = &PurlMatchOptions{}
+	}
+
+	p1q := p1.Qualifiers.Map()
+	p2q := p2.Qualifiers.Map()
+	if vers, ok := p1q[VersQualifier]; ok && p2q[VersQualifier] != vers && opts.Versions == VersionsRange {
+		return [3]string{NearMissVersion, vers, p2.Version}, true
+	}
+	if (p1.Version != "" || opts.Versions == VersionsExact) && p1.Version != p2.Version && opts.Versions != VersionsAny {
+		return [3]string{NearMissVersion, p1.Version, p2.Version}, true
+	}
+	if opts.IgnoreQualifiers {
+		return [3]string{}, false
+	}
+
+	_, isDistroPackage := distroPurlTypes[p1.Type]
+	keys := []string{}
+	for k := range p1q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	expected, actual := []string{}, []string{}
+	for _, k := range keys {
+		if k == VersQualifier && opts.Versions != VersionsExact {
+			continue
+		}
+		v2, ok := p2q[k]
+		if isDistroPackage && k == "distro" && distroMatches(p1q[k], v2, ok, opts.Distro) {
+			continue
+		}
+		if ok && v2 == p1q[k] {
+			continue
+		}
+		expected = append(expected, k+"="+p1q[k])
+		actual = append(actual, k+"="+v2)
+	}
+	return [3]string{NearMissQualifiers, strings.Join(expected, "&"), strings.Join(actual, "&")}, len(expected) > 0
+}
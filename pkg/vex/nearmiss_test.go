@@ -0,0 +1,93 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNearMisses(t *testing.T) {
+	digest := "124c7d2707904eea7431fffe91522a01e5a861a624ee31d03372cc1d138a3126"
+	doc := &VEX{
+		Statements: []Statement{
+			{
+				Vulnerability: Vulnerability{Name: "CVE-2023-1255"},
+				Products: []Product{
+					{Component: Component{ID: "pkg:apk/wolfi/libssl@3.0.8-r3?arch=x86_64"}},
+					{Component: Component{ID: "pkg:apk/wolfi/curl?vers=vers:apk%2F%3C8.1.0"}},
+					{Component: Component{Hashes: map[Algorithm]Hash{SHA256: Hash(digest)}}},
+					{
+						Component:     Component{ID: "pkg:oci/app"},
+						Subcomponents: []Subcomponent{{Component{ID: "pkg:golang/example.com/lib@v1.0.0"}}},
+					},
+				},
+				Status: StatusUnderInvestigation,
+			},
+		},
+	}
+
+	for m, tc := range map[string]struct {
+		product       string
+		subcomponents []string
+		expected      []NearMiss
+	}{
+		"different version": {
+			product: "pkg:apk/wolfi/libssl@3.0.8-r4?arch=x86_64",
+			expected: []NearMiss{{
+				Component: "pkg:apk/wolfi/libssl@3.0.8-r3?arch=x86_64", Field: NearMissVersion, Expected: "3.0.8-r3", Actual: "3.0.8-r4",
+			}},
+		},
+		"qualifier mismatch": {
+			product: "pkg:apk/wolfi/libssl@3.0.8-r3?arch=aarch64",
+			expected: []NearMiss{{
+				Component: "pkg:apk/wolfi/libssl@3.0.8-r3?arch=x86_64", Field: NearMissQualifiers, Expected: "arch=x86_64", Actual: "arch=aarch64",
+			}},
+		},
+		"out of range": {
+			product: "pkg:apk/wolfi/curl@8.1.0-r0",
+			expected: []NearMiss{{
+				Component: "pkg:apk/wolfi/curl?vers=vers:apk%2F%3C8.1.0", Field: NearMissVersion, Expected: "vers:apk/<8.1.0", Actual: "8.1.0-r0",
+			}},
+		},
+		"digest notation": {
+			product: "sha256:" + digest,
+			expected: []NearMiss{{
+				Component: "sha-256:" + digest, Field: NearMissHashes, Expected: "sha-256:" + digest, Actual: "sha256:" + digest,
+			}},
+		},
+		"digest in oci purl": {
+			product: "pkg:oci/other@sha256%3A" + digest,
+			expected: []NearMiss{{
+				Component: "sha-256:" + digest, Field: NearMissHashes, Expected: "sha-256:" + digest, Actual: "sha256:" + digest,
+			}},
+		},
+		"subcomponent version": {
+			product:       "pkg:oci/app",
+			subcomponents: []string{"pkg:golang/example.com/lib@v1.0.1"},
+			expected: []NearMiss{{
+				Component: "pkg:golang/example.com/lib@v1.0.0", Field: NearMissVersion, Expected: "v1.0.0", Actual: "v1.0.1",
+			}},
+		},
+		"unlisted subcomponent": {
+			product:       "pkg:oci/app",
+			subcomponents: []string{"pkg:golang/example.com/other@v1.0.0"},
+			expected: []NearMiss{{
+				Component: "pkg:oci/app", Field: NearMissSubcomponents, Expected: "pkg:golang/example.com/lib@v1.0.0", Actual: "pkg:golang/example.com/other@v1.0.0",
+			}},
+		},
+		"different package": {product: "pkg:apk/wolfi/git@2.41.0", expected: []NearMiss{}},
+		"matching product":  {product: "pkg:apk/wolfi/libssl@3.0.8-r3?arch=x86_64", expected: []NearMiss{}},
+	} {
+		misses := doc.NearMisses("CVE-2023-1255", tc.product, tc.subcomponents, nil)
+		for i := range misses {
+			misses[i].Statement = Statement{}
+		}
+		require.Equal(t, tc.expected, misses, m)
+	}
+
+	// Statements about other vulnerabilities are not near misses
+	require.Empty(t, doc.NearMisses("CVE-2023-0001", "pkg:apk/wolfi/libssl@3.0.8-r4?arch=x86_64", nil, nil))
+}
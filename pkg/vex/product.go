@@ -3,6 +3,11 @@
 
 package vex
 
+import (
+	"fmt"
+	"strings"
+)
+
 // Product abstracts the VEX product into a struct that can identify software
 // through various means. The main one is the ID field which contains an IRI
 // identifying the product, possibly pointing to another document with more data,
@@ -11,6 +16,62 @@ package vex
 type Product struct {
 	Component
 	Subcomponents []Subcomponent `json:"subcomponents,omitempty"`
+
+	// Artifacts optionally lists the forms in which the product is shipped
+	// (eg a container image, a tarball and a language package), each one
+	// with its own identifiers and hashes. Any of the artifacts matches the
+	// product, so a single statement covers all of them.
+	//
+	// Artifacts are not part of the OpenVEX spec. They are serialized under
+	// the namespaced openvex.dev/artifacts extension property, which other
+	// implementations ignore.
+	Artifacts []Component `json:"openvex.dev/artifacts,omitempty"`
+}
+
+// NewMultiArtifactProduct returns a product identified by id that is shipped
+// as the specified artifacts.
+func NewMultiArtifactProduct(id string, artifacts ...Component) Product {
+	return Product{
+		Component: Component{ID: id},
+		Artifacts: artifacts,
+	}
+}
+
+// DeepCopyInto copies the receiver and writes its value into out.
+func (p *Product) DeepCopyInto(out *Product) {
+	*out = *p
+	p.Component.DeepCopyInto(&out.Component)
+	if p.Subcomponents != nil {
+		out.Subcomponents = make([]Subcomponent, len(p.Subcomponents))
+		for i := range p.Subcomponents {
+			p.Subcomponents[i].Component.DeepCopyInto(&out.Subcomponents[i].Component)
+		}
+	}
+	if p.Artifacts != nil {
+		out.Artifacts = make([]Component, len(p.Artifacts))
+		for i := range p.Artifacts {
+			p.Artifacts[i].DeepCopyInto(&out.Artifacts[i])
+		}
+	}
+}
+
+// AddArtifact adds an artifact form of the product, identified by its IRI
+// or purl and optionally by its hashes.
+func (p *Product) AddArtifact(id string, hashes map[Algorithm]Hash) {
+	artifact := Component{ID: id, Hashes: hashes}
+	if strings.HasPrefix(id, "pkg:") {
+		artifact.Identifiers = map[IdentifierType]string{PURL: id}
+	}
+	p.Artifacts = append(p.Artifacts, artifact)
+}
+
+// components returns the product component followed by its artifacts.
+func (p *Product) components() []*Component {
+	ret := []*Component{&p.Component}
+	for i := range p.Artifacts {
+		ret = append(ret, &p.Artifacts[i])
+	}
+	return ret
 }
 
 // Subcomponents are nested entries that list the product's components that are
@@ -23,23 +84,40 @@ type Subcomponent struct {
 // Product returns true if an identifier and subcomponent identifier match any
 // of the identifiers in the product and subcomponents.
 func (p *Product) Matches(identifier, subIdentifier string) bool {
-	if !p.Component.Matches(identifier) {
-		return false
-	}
+	return p.MatchesWithResolver(identifier, subIdentifier, nil)
+}
 
-	// If the product has no subcomponents or no subcomponent was specified,
-	// matching the product part is enough:
-	if len(p.Subcomponents) == 0 || subIdentifier == "" {
-		return true
+// MatchesWithOptions works like Matches but takes several subcomponent
+// identifiers and matches them using the settings in the options.
+//
+// The subcomponent identifiers are alternative identifiers of the same
+// queried component, eg its purl and its sha-256 hash. The product matches
+// if any of them matches any of its subcomponents. When the product lists
+// no subcomponents, or no subcomponent identifiers are passed, only the
+// product identifier is matched.
+func (p *Product) MatchesWithOptions(identifier string, subIdentifiers []string, opts *MatchOptions) bool {
+	if opts == nil {
+		opts = &MatchOptions{}
 	}
+	return p.matchesWithOptions(identifier, subIdentifiers, opts)
+}
 
-	for _, s := range p.Subcomponents {
-		if s.Matches(subIdentifier) {
-			return true
+// Validate checks the product and its subcomponents are valid.
+func (p *Product) Validate() error {
+	if err := p.Component.Validate(); err != nil {
+		return err
+	}
+	for i := range p.Subcomponents {
+		if err := p.Subcomponents[i].Validate(); err != nil {
+			return fmt.Errorf("invalid subcomponent: %w", err)
 		}
 	}
-
-	return false
+	for i := range p.Artifacts {
+		if err := p.Artifacts[i].Validate(); err != nil {
+			return fmt.Errorf("invalid artifact: %w", err)
+		}
+	}
+	return nil
 }
 
 type (
@@ -4,6 +4,7 @@
 package vex
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -95,3 +96,80 @@ func TestProductMatches(t *testing.T) {
 		require.Equal(t, tc.mustMach, tc.sut.Matches(tc.product, tc.subcomponent), "failed: %s", testCase)
 	}
 }
+
+func TestMultiArtifactProduct(t *testing.T) {
+	product := NewMultiArtifactProduct(
+		"https://example.com/products/widget",
+		Component{
+			ID:     "pkg:oci/widget@sha256%3A124c7d2707904eea7431fffe91522a01e5a861a624ee31d03372cc1d138a3126",
+			Hashes: map[Algorithm]Hash{SHA256: "124c7d2707904eea7431fffe91522a01e5a861a624ee31d03372cc1d138a3126"},
+		},
+	)
+	product.AddArtifact("pkg:npm/widget@1.0.0", nil)
+	product.AddArtifact("https://example.com/downloads/widget-1.0.0.tar.gz", map[Algorithm]Hash{
+		SHA256: "a1b2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f90",
+	})
+	product.Subcomponents = []Subcomponent{{Component{ID: "pkg:npm/lodash@4.17.20"}}}
+	require.NoError(t, product.Validate())
+
+	for testCase, tc := range map[string]struct {
+		product      string
+		subcomponent string
+		mustMatch    bool
+	}{
+		"product IRI":           {"https://example.com/products/widget", "", true},
+		"image purl":            {"pkg:oci/widget@sha256%3A124c7d2707904eea7431fffe91522a01e5a861a624ee31d03372cc1d138a3126?tag=latest", "", true},
+		"image hash":            {"124c7d2707904eea7431fffe91522a01e5a861a624ee31d03372cc1d138a3126", "", true},
+		"language package":      {"pkg:npm/widget@1.0.0", "pkg:npm/lodash@4.17.20", true},
+		"tarball hash":          {"a1b2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f90", "", true},
+		"other version":         {"pkg:npm/widget@2.0.0", "", false},
+		"artifact, other child": {"pkg:npm/widget@1.0.0", "pkg:npm/lodash@4.17.21", false},
+	} {
+		require.Equal(t, tc.mustMatch, product.Matches(tc.product, tc.subcomponent), testCase)
+	}
+
+	doc := New()
+	doc.Statements = []Statement{
+		{Vulnerability: Vulnerability{Name: "CVE-2021-23337"}, Products: []Product{product}, Status: StatusFixed},
+	}
+	require.Len(t, doc.StatementsByProduct()["purl:pkg:npm/widget@1.0.0"], 1)
+
+	// Artifacts are serialized under the namespaced extension property
+	var b bytes.Buffer
+	require.NoError(t, doc.ToJSON(&b))
+	require.Contains(t, b.String(), `"openvex.dev/artifacts": [`)
+	require.NotContains(t, b.String(), `"artifacts":`)
+	parsed, err := Parse(b.Bytes())
+	require.NoError(t, err)
+	require.Equal(t, product.Artifacts, parsed.Statements[0].Products[0].Artifacts)
+
+	product.Artifacts[1].ID = "pkg:npm/widget@1.0.0?bad=%%"
+	product.Artifacts[1].Identifiers[PURL] = product.Artifacts[1].ID
+	require.Error(t, product.Validate())
+}
+
+func TestProductMatchesSubcomponentIdentifiers(t *testing.T) {
+	image := "pkg:oci/alpine@sha256%3A124c7d2707904eea7431fffe91522a01e5a861a624ee31d03372cc1d138a3126"
+	sut := &Product{
+		Component: Component{ID: image},
+		Subcomponents: []Subcomponent{
+			{Component{Hashes: map[Algorithm]Hash{SHA256: "e1a9a5c4f3f6c2a8b1d1c9ec2c5b6e8f0a9b4e2b8c3d1f2a6e7b9c0d1e2f3a4b"}}},
+		},
+	}
+
+	for m, tc := range map[string]struct {
+		identifiers []string
+		mustMatch   bool
+	}{
+		"no identifiers":       {nil, true},
+		"empty identifier":     {[]string{""}, true},
+		"purl only":            {[]string{"pkg:apk/alpine/libcrypto3@3.0.8-r3"}, false},
+		"hash only":            {[]string{"e1a9a5c4f3f6c2a8b1d1c9ec2c5b6e8f0a9b4e2b8c3d1f2a6e7b9c0d1e2f3a4b"}, true},
+		"purl and hash":        {[]string{"pkg:apk/alpine/libcrypto3@3.0.8-r3", "e1a9a5c4f3f6c2a8b1d1c9ec2c5b6e8f0a9b4e2b8c3d1f2a6e7b9c0d1e2f3a4b"}, true},
+		"purl and other hash":  {[]string{"pkg:apk/alpine/libcrypto3@3.0.8-r3", "0000"}, false},
+		"empty and other purl": {[]string{"", "pkg:apk/alpine/libssl3@3.0.8-r3"}, false},
+	} {
+		require.Equal(t, tc.mustMatch, sut.MatchesWithOptions(image, tc.identifiers, nil), m)
+	}
+	require.False(t, sut.MatchesWithOptions("pkg:oci/debian", []string{"e1a9a5c4f3f6c2a8b1d1c9ec2c5b6e8f0a9b4e2b8c3d1f2a6e7b9c0d1e2f3a4b"}, nil))
+}
@@ -0,0 +1,199 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"strings"
+
+	"github.com/package-url/packageurl-go"
+)
+
+// DistroMatching controls how the distro qualifiers of OS package purls are
+// compared when matching.
+type DistroMatching int
+
+const (
+	// DistroStrict requires the distro qualifiers to be identical. This is
+	// the default and the way PurlMatches has always behaved.
+	DistroStrict DistroMatching = iota
+
+	// DistroSameMajor matches distro qualifiers of the same distribution and
+	// major release, eg alpine-3.18 and alpine-3.19.
+	DistroSameMajor
+
+	// DistroIgnore disregards the distro qualifier when matching.
+	DistroIgnore
+)
+
+// distroPurlTypes are the purl types of OS packages that are built for a
+// distribution release.
+var distroPurlTypes = map[string]struct{}{
+	packageurl.TypeApk:    {},
+	packageurl.TypeDebian: {},
+	packageurl.TypeRPM:    {},
+}
+
+// VersionMatching controls how the versions of purls are compared when
+// matching.
+type VersionMatching int
+
+const (
+	// VersionsRange matches purls with the same version and lets purls
+	// without a version, or with a vers qualifier, match the versions they
+	// cover. This is the default and the way PurlMatches has always behaved.
+	VersionsRange VersionMatching = iota
+
+	// VersionsExact requires the versions to be identical. Purls without a
+	// version only match other purls without a version and vers qualifiers
+	// are compared as any other qualifier.
+	VersionsExact
+
+	// VersionsAny disregards the versions and vers qualifiers when matching.
+	VersionsAny
+)
+
+// PurlMatchOptions configure the purl matching functions.
+type PurlMatchOptions struct {
+	// Distro sets how the distro qualifiers of apk, deb and rpm purls
+	// are compared.
+	Distro DistroMatching
+
+	// Versions sets how the purl versions are compared.
+	Versions VersionMatching
+
+	// IgnoreQualifiers disregards all the qualifiers of the purls except
+	// vers, which is controlled by Versions.
+	IgnoreQualifiers bool
+}
+
+// PurlMatchesWithOptions returns true if purl1 matches the more specific
+// purl2. It works like PurlMatches but the comparison of the versions, the
+// qualifiers and the distro qualifier of OS packages is controlled by the
+// options. Unless IgnoreQualifiers is set, the arch and os qualifiers of OS
+// packages are always compared strictly.
+func PurlMatchesWithOptions(purl1, purl2 string, opts *PurlMatchOptions) bool {
+	if opts == nil {
+		opts = &PurlMatchOptions{}
+	}
+
+	p1, err := packageurl.FromString(purl1)
+	if err != nil {
+		return false
+	}
+	p2, err := packageurl.FromString(purl2)
+	if err != nil {
+		return false
+	}
+
+	if p1.Type != p2.Type {
+		return false
+	}
+
+	if p1.Namespace != p2.Namespace {
+		return false
+	}
+
+	if p1.Name != p2.Name {
+		return false
+	}
+
+	p1q := p1.Qualifiers.Map()
+	p2q := p2.Qualifiers.Map()
+
+	if !versionMatches(&p1, &p2, p1q, p2q, opts.Versions) {
+		return false
+	}
+
+	if opts.IgnoreQualifiers {
+		return true
+	}
+
+	_, isDistroPackage := distroPurlTypes[p1.Type]
+
+	// All qualifiers in p1 must be in p2 to match
+	for k, v1 := range p1q {
+		if k == VersQualifier && opts.Versions != VersionsExact {
+			continue
+		}
+		v2, ok := p2q[k]
+		if isDistroPackage && k == "distro" {
+			if !distroMatches(v1, v2, ok, opts.Distro) {
+				return false
+			}
+			continue
+		}
+		if !ok || v1 != v2 {
+			return false
+		}
+	}
+	return true
+}
+
+// versionMatches compares the versions of two purls. The qualifier maps are
+// passed to avoid parsing them again.
+func versionMatches(p1, p2 *packageurl.PackageURL, p1q, p2q map[string]string, mode VersionMatching) bool {
+	switch mode {
+	case VersionsAny:
+		return true
+	case VersionsExact:
+		return p1.Version == p2.Version
+	}
+
+	if p1.Version != "" && p2.Version == "" {
+		return false
+	}
+
+	if p1.Version != p2.Version && p1.Version != "" && p2.Version != "" {
+		return false
+	}
+
+	// A version range in p1 must contain the version of p2
+	if vers, ok := p1q[VersQualifier]; ok && p2q[VersQualifier] != vers {
+		if p2.Version == "" {
+			return false
+		}
+		vr, err := ParseVersionRange(vers)
+		if err != nil || !vr.Contains(p2.Version) {
+			return false
+		}
+	}
+	return true
+}
+
+// distroMatches compares two distro qualifier values. found is false when
+// the more specific purl does not have a distro qualifier.
+func distroMatches(distro1, distro2 string, found bool, mode DistroMatching) bool {
+	switch mode {
+	case DistroIgnore:
+		return true
+	case DistroSameMajor:
+		if !found {
+			return false
+		}
+		if distro1 == distro2 {
+			return true
+		}
+		name1, major1 := splitDistro(distro1)
+		name2, major2 := splitDistro(distro2)
+		return major1 != "" && name1 == name2 && major1 == major2
+	default:
+		return found && distro1 == distro2
+	}
+}
+
+// splitDistro splits a distro qualifier such as "alpine-3.18" or "rhel-9.2"
+// into the distribution name and its major version. If the value has no
+// numeric version, the major version is returned empty.
+func splitDistro(distro string) (name, major string) {
+	i := strings.LastIndex(distro, "-")
+	if i == -1 {
+		return distro, ""
+	}
+	name, version := distro[:i], distro[i+1:]
+	if version == "" || version[0] < '0' || version[0] > '9' {
+		return distro, ""
+	}
+	major, _, _ = strings.Cut(version, ".")
+	return name, major
+}
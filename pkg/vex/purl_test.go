@@ -0,0 +1,79 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPurlMatchesWithOptions(t *testing.T) {
+	for m, tc := range map[string]struct {
+		purl1    string
+		purl2    string
+		mode     DistroMatching
+		expected bool
+	}{
+		"strict same distro":         {"pkg:apk/alpine/curl@8.4.0-r0?distro=alpine-3.18", "pkg:apk/alpine/curl@8.4.0-r0?distro=alpine-3.18", DistroStrict, true},
+		"strict different minor":     {"pkg:apk/alpine/curl@8.4.0-r0?distro=alpine-3.18", "pkg:apk/alpine/curl@8.4.0-r0?distro=alpine-3.19", DistroStrict, false},
+		"same major different minor": {"pkg:apk/alpine/curl@8.4.0-r0?distro=alpine-3.18", "pkg:apk/alpine/curl@8.4.0-r0?distro=alpine-3.19", DistroSameMajor, true},
+		"same major different major": {"pkg:rpm/redhat/openssl@3.0.7?distro=rhel-8.6", "pkg:rpm/redhat/openssl@3.0.7?distro=rhel-9.2", DistroSameMajor, false},
+		"same major different name":  {"pkg:rpm/fedora/openssl@3.0.7?distro=fedora-38", "pkg:rpm/fedora/openssl@3.0.7?distro=rhel-38", DistroSameMajor, false},
+		"same major missing distro":  {"pkg:deb/debian/curl@7.88.1?distro=debian-12", "pkg:deb/debian/curl@7.88.1", DistroSameMajor, false},
+		"same major codename":        {"pkg:deb/debian/curl@7.88.1?distro=bookworm", "pkg:deb/debian/curl@7.88.1?distro=bullseye", DistroSameMajor, false},
+		"same major equal codename":  {"pkg:deb/debian/curl@7.88.1?distro=bookworm", "pkg:deb/debian/curl@7.88.1?distro=bookworm", DistroSameMajor, true},
+		"ignore different distro":    {"pkg:deb/debian/curl@7.88.1?distro=debian-11", "pkg:deb/debian/curl@7.88.1?distro=debian-12", DistroIgnore, true},
+		"ignore missing distro":      {"pkg:deb/debian/curl@7.88.1?distro=debian-11", "pkg:deb/debian/curl@7.88.1", DistroIgnore, true},
+		"ignore keeps arch":          {"pkg:deb/debian/curl@7.88.1?arch=amd64&distro=debian-11", "pkg:deb/debian/curl@7.88.1?arch=arm64&distro=debian-12", DistroIgnore, false},
+		"ignore only os packages":    {"pkg:oci/curl?distro=debian-11", "pkg:oci/curl?distro=debian-12", DistroIgnore, false},
+	} {
+		require.Equal(t, tc.expected, PurlMatchesWithOptions(tc.purl1, tc.purl2, &PurlMatchOptions{Distro: tc.mode}), m)
+	}
+}
+
+func TestMatchLevelDistro(t *testing.T) {
+	stmt := Statement{
+		Vulnerability: Vulnerability{Name: "CVE-2023-38545"},
+		Products: []Product{
+			{
+				Component: Component{
+					Identifiers: map[IdentifierType]string{PURL: "pkg:apk/alpine/curl@8.4.0-r0?distro=alpine-3.18"},
+				},
+			},
+		},
+		Status: StatusFixed,
+	}
+	query := "pkg:apk/alpine/curl@8.4.0-r0?distro=alpine-3.19"
+
+	require.False(t, stmt.Matches("CVE-2023-38545", query, nil))
+	require.Equal(t, NoMatch, stmt.MatchLevel("CVE-2023-38545", query, nil, &MatchOptions{}))
+	require.Equal(t, FullMatch, stmt.MatchLevel("CVE-2023-38545", query, nil, &MatchOptions{Distro: DistroSameMajor}))
+	require.Equal(t, FullMatch, stmt.MatchLevel("CVE-2023-38545", query, nil, &MatchOptions{Distro: DistroIgnore}))
+}
+
+func TestPurlMatchesVersionsAndQualifiers(t *testing.T) {
+	for m, tc := range map[string]struct {
+		purl1    string
+		purl2    string
+		opts     PurlMatchOptions
+		expected bool
+	}{
+		"range versionless":            {"pkg:apk/wolfi/git", "pkg:apk/wolfi/git@2.41.0-r0", PurlMatchOptions{}, true},
+		"range vers qualifier":         {"pkg:apk/wolfi/git?vers=vers:apk/>=2.40.0|<2.42.0", "pkg:apk/wolfi/git@2.41.0-r0", PurlMatchOptions{}, true},
+		"exact same version":           {"pkg:apk/wolfi/git@2.41.0-r0", "pkg:apk/wolfi/git@2.41.0-r0", PurlMatchOptions{Versions: VersionsExact}, true},
+		"exact versionless":            {"pkg:apk/wolfi/git", "pkg:apk/wolfi/git@2.41.0-r0", PurlMatchOptions{Versions: VersionsExact}, false},
+		"exact vers qualifier":         {"pkg:apk/wolfi/git?vers=vers:apk/>=2.40.0|<2.42.0", "pkg:apk/wolfi/git@2.41.0-r0", PurlMatchOptions{Versions: VersionsExact}, false},
+		"exact both versionless":       {"pkg:apk/wolfi/git", "pkg:apk/wolfi/git", PurlMatchOptions{Versions: VersionsExact}, true},
+		"any different version":        {"pkg:apk/wolfi/git@2.40.0-r0", "pkg:apk/wolfi/git@2.41.0-r0", PurlMatchOptions{Versions: VersionsAny}, true},
+		"any vers out of range":        {"pkg:apk/wolfi/git?vers=vers:apk/<2.40.0", "pkg:apk/wolfi/git@2.41.0-r0", PurlMatchOptions{Versions: VersionsAny}, true},
+		"any different name":           {"pkg:apk/wolfi/git@2.41.0-r0", "pkg:apk/wolfi/curl@2.41.0-r0", PurlMatchOptions{Versions: VersionsAny}, false},
+		"qualifiers compared":          {"pkg:apk/wolfi/git@2.41.0-r0?arch=x86_64", "pkg:apk/wolfi/git@2.41.0-r0?arch=aarch64", PurlMatchOptions{}, false},
+		"ignore qualifiers":            {"pkg:apk/wolfi/git@2.41.0-r0?arch=x86_64", "pkg:apk/wolfi/git@2.41.0-r0?arch=aarch64", PurlMatchOptions{IgnoreQualifiers: true}, true},
+		"ignore qualifiers distro":     {"pkg:deb/debian/curl@7.88.1?distro=debian-11", "pkg:deb/debian/curl@7.88.1", PurlMatchOptions{IgnoreQualifiers: true}, true},
+		"ignore qualifiers keeps vers": {"pkg:apk/wolfi/git?vers=vers:apk/<2.40.0", "pkg:apk/wolfi/git@2.41.0-r0", PurlMatchOptions{IgnoreQualifiers: true}, false},
+	} {
+		require.Equal(t, tc.expected, PurlMatchesWithOptions(tc.purl1, tc.purl2, &tc.opts), m)
+	}
+}
@@ -0,0 +1,177 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+const (
+	// AnnotationInternal marks a statement as internal-only when set to
+	// "true". Internal statements are dropped from public views.
+	AnnotationInternal = "openvex.dev/internal"
+
+	// AnnotationInternalFields holds a comma separated list of the JSON
+	// names of the statement fields that are internal-only. These fields
+	// are stripped from the statement in public views. See RedactableFields
+	// for the list of supported fields. Products listing internal
+	// subcomponents are removed, as the statement does not apply to the
+	// whole product.
+	AnnotationInternalFields = "openvex.dev/internal-fields"
+)
+
+// RedactableFields returns the JSON names of the statement fields that can
+// be marked as internal-only.
+func RedactableFields() []string {
+	return []string{
+		"status_notes",
+		"impact_statement",
+		"action_statement",
+		"openvex.dev/remediations",
+		"vulnerability.description",
+		"subcomponents",
+	}
+}
+
+// PublicView returns a copy of the document without the statements and
+// fields marked as internal-only. The internal annotations are removed from
+// the statements, other annotations such as the validity window are kept.
+// Statements changed by the redaction lose their signature annotations, as
+// their signatures would no longer verify. The document gets a new ID
+// computed from its public contents and is not linked to the previous
+// internal version (see LinkPrevious). The version is carried over from the
+// original document so public views keep moving forward as the internal
+// document is updated.
+//
+// Redacting fields can make statements invalid, for example removing the
+// impact statement of a not_affected statement without a justification. To
+// keep the document valid, redacted action statements are replaced with a
+// generic message and an error is returned if any statement ends up
+// invalid. Statements left without products after removing the ones with
+// internal subcomponents are dropped.
+func (vexDoc *VEX) PublicView() (*VEX, error) {
+	public := &VEX{
+		Metadata:   vexDoc.Metadata,
+		Statements: []Statement{},
+	}
+	public.ID = ""
+	public.PreviousDigest = ""
+
+	for i := range vexDoc.Statements {
+		if vexDoc.Statements[i].Annotations[AnnotationInternal] == "true" {
+			continue
+		}
+
+		stmt := vexDoc.Statements[i].DeepCopy()
+		if err := stmt.redact(); err != nil {
+			return nil, fmt.Errorf("redacting statement #%d: %w", i, err)
+		}
+		if len(stmt.Products) == 0 && len(vexDoc.Statements[i].Products) > 0 {
+			continue
+		}
+		stmt.stripInternalAnnotations()
+		if err := vexDoc.dropStaleSignature(&vexDoc.Statements[i], stmt); err != nil {
+			return nil, fmt.Errorf("checking signature of statement #%d: %w", i, err)
+		}
+
+		if err := stmt.Validate(); err != nil {
+			return nil, fmt.Errorf("statement #%d is invalid after redaction: %w", i, err)
+		}
+		public.Statements = append(public.Statements, *stmt)
+	}
+
+	if public.Timestamp != nil {
+		if _, err := public.GenerateCanonicalID(); err != nil {
+			return nil, fmt.Errorf("generating public document ID: %w", err)
+		}
+	}
+
+	return public, nil
+}
+
+// dropStaleSignature removes the signature annotations of the redacted
+// statement if its signed payload differs from the one of the original.
+func (vexDoc *VEX) dropStaleSignature(original, redacted *Statement) error {
+	if _, ok := redacted.Annotations[AnnotationSignature]; !ok {
+		return nil
+	}
+
+	before, err := vexDoc.statementPayload(original)
+	if err != nil {
+		return err
+	}
+	after, err := vexDoc.statementPayload(redacted)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(before, after) {
+		return nil
+	}
+
+	delete(redacted.Annotations, AnnotationSignature)
+	delete(redacted.Annotations, AnnotationSignatureKeyID)
+	if len(redacted.Annotations) == 0 {
+		redacted.Annotations = nil
+	}
+	return nil
+}
+
+// stripInternalAnnotations removes the openvex.dev/internal annotations of
+// the statement.
+func (stmt *Statement) stripInternalAnnotations() {
+	for k := range stmt.Annotations {
+		if strings.HasPrefix(k, AnnotationInternal) {
+			delete(stmt.Annotations, k)
+		}
+	}
+	if len(stmt.Annotations) == 0 {
+		stmt.Annotations = nil
+	}
+}
+
+// redact strips the fields listed in the internal fields annotation.
+func (stmt *Statement) redact() error {
+	list := stmt.Annotations[AnnotationInternalFields]
+	if list == "" {
+		return nil
+	}
+
+	for _, field := range strings.Split(list, ",") {
+		switch strings.TrimSpace(field) {
+		case "":
+			continue
+		case "status_notes":
+			stmt.StatusNotes = ""
+		case "impact_statement":
+			stmt.ImpactStatement = ""
+		case "action_statement":
+			if stmt.ActionStatement != "" {
+				stmt.ActionStatement = NoActionStatementMsg
+			}
+			stmt.Remediations = nil
+		case "openvex.dev/remediations":
+			stmt.Remediations = nil
+		case "vulnerability.description":
+			stmt.Vulnerability.Description = ""
+		case "subcomponents":
+			// Listing the product without its subcomponents would widen
+			// the statement to the whole product.
+			products := []Product{}
+			for i := range stmt.Products {
+				if len(stmt.Products[i].Subcomponents) == 0 {
+					products = append(products, stmt.Products[i])
+				}
+			}
+			stmt.Products = products
+		default:
+			return fmt.Errorf(
+				"unsupported internal field %q, must be one of [%s]",
+				field, strings.Join(RedactableFields(), ", "),
+			)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublicView(t *testing.T) {
+	ts := time.Date(2023, 4, 17, 20, 34, 58, 0, time.UTC)
+	doc := &VEX{
+		Metadata: Metadata{
+			ID:             "https://example.com/internal/vex-1",
+			Author:         "Wolfi J Inkinson",
+			Timestamp:      &ts,
+			Version:        3,
+			PreviousDigest: "sha-256:0f1e2d3c4b5a69788796a5b4c3d2e1f00f1e2d3c4b5a69788796a5b4c3d2e1f0",
+		},
+		Statements: []Statement{
+			{
+				Vulnerability: Vulnerability{Name: "CVE-2014-123456"},
+				Products:      []Product{{Component: Component{ID: "pkg:deb/pkg@1.0"}}},
+				Status:        StatusUnderInvestigation,
+				Annotations:   map[string]string{AnnotationInternal: "true"},
+			},
+			{
+				Vulnerability: Vulnerability{Name: "CVE-2014-654321", Description: "Embargoed details"},
+				Products: []Product{
+					{
+						Component: Component{ID: "pkg:deb/pkg@1.0"},
+						Subcomponents: []Subcomponent{
+							{Component: Component{ID: "pkg:deb/internal-lib@1.0"}},
+						},
+					},
+					{Component: Component{ID: "pkg:deb/other@1.0"}},
+				},
+				Status:          StatusAffected,
+				StatusNotes:     "Ticket SEC-1234",
+				ActionStatement: "Contact alice@example.com",
+				Remediations:    []Remediation{{Category: RemediationWorkaround, Details: "Ask alice@example.com"}},
+				Annotations: map[string]string{
+					AnnotationInternalFields: "status_notes, action_statement,vulnerability.description,subcomponents",
+				},
+			},
+			{
+				Vulnerability: Vulnerability{Name: "CVE-2014-111111"},
+				Products:      []Product{{Component: Component{ID: "pkg:deb/pkg@1.0"}}},
+				Status:        StatusFixed,
+			},
+		},
+	}
+
+	public, err := doc.PublicView()
+	require.NoError(t, err)
+	require.Len(t, public.Statements, 2)
+	require.NotEqual(t, doc.ID, public.ID)
+	require.NotEmpty(t, public.ID)
+	require.Empty(t, public.PreviousDigest)
+	require.Equal(t, 3, public.Version)
+
+	stmts := public.StatementsByVulnerability("CVE-2014-654321")
+	require.Len(t, stmts, 1)
+	s := stmts[0]
+	require.Empty(t, s.StatusNotes)
+	require.Empty(t, s.Vulnerability.Description)
+	require.Len(t, s.Products, 1)
+	require.Equal(t, "pkg:deb/other@1.0", s.Products[0].ID)
+	require.Equal(t, NoActionStatementMsg, s.ActionStatement)
+	require.Empty(t, s.Remediations)
+	require.Nil(t, s.Annotations)
+
+	// The original document is not modified
+	require.Len(t, doc.Statements, 3)
+	require.Equal(t, "Ticket SEC-1234", doc.Statements[1].StatusNotes)
+	require.Len(t, doc.Statements[1].Products[0].Subcomponents, 1)
+	require.Len(t, doc.Statements[1].Remediations, 1)
+	require.Equal(t, "https://example.com/internal/vex-1", doc.ID)
+
+	// Statements scoped to internal subcomponents are not widened to the
+	// whole product
+	doc.Statements = []Statement{
+		{
+			Vulnerability: Vulnerability{Name: "CVE-2014-123456"},
+			Products: []Product{{
+				Component:     Component{ID: "pkg:deb/pkg@1.0"},
+				Subcomponents: []Subcomponent{{Component: Component{ID: "pkg:deb/internal-lib@1.0"}}},
+			}},
+			Status:        StatusNotAffected,
+			Justification: VulnerableCodeNotPresent,
+			Annotations:   map[string]string{AnnotationInternalFields: "subcomponents"},
+		},
+	}
+	public, err = doc.PublicView()
+	require.NoError(t, err)
+	require.Empty(t, public.Statements)
+	require.Empty(t, public.Matches("CVE-2014-123456", "pkg:deb/pkg@1.0", []string{"pkg:deb/openssl@3.0"}))
+	require.Len(t, doc.Matches("CVE-2014-123456", "pkg:deb/pkg@1.0", []string{"pkg:deb/internal-lib@1.0"}), 1)
+
+	// Redactions that break the statement are errors
+	doc.Statements = []Statement{
+		{
+			Vulnerability:   Vulnerability{Name: "CVE-2014-123456"},
+			Products:        []Product{{Component: Component{ID: "pkg:deb/pkg@1.0"}}},
+			Status:          StatusNotAffected,
+			ImpactStatement: "Internal reasons",
+			Annotations:     map[string]string{AnnotationInternalFields: "impact_statement"},
+		},
+	}
+	_, err = doc.PublicView()
+	require.Error(t, err)
+
+	doc.Statements[0].Annotations[AnnotationInternalFields] = "author"
+	_, err = doc.PublicView()
+	require.Error(t, err)
+}
+
+func TestPublicViewKeepsValidity(t *testing.T) {
+	ts := time.Date(2023, 4, 17, 20, 34, 58, 0, time.UTC)
+	doc := &VEX{
+		Metadata: Metadata{ID: "https://example.com/internal/vex-1", Timestamp: &ts},
+		Statements: []Statement{
+			{
+				Vulnerability:   Vulnerability{Name: "CVE-2014-123456"},
+				Products:        []Product{{Component: Component{ID: "pkg:deb/pkg@1.0"}}},
+				Status:          StatusNotAffected,
+				Justification:   InlineMitigationsAlreadyExist,
+				ImpactStatement: "Temporary mitigation, see SEC-1234",
+				Annotations: map[string]string{
+					AnnotationInternalFields: "impact_statement",
+					"example.com/ticket":     "SEC-1234",
+				},
+			},
+		},
+	}
+	doc.Statements[0].SetValidUntil(ts.Add(24 * time.Hour))
+
+	public, err := doc.PublicView()
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		AnnotationValidUntil: "2023-04-18T20:34:58Z",
+		"example.com/ticket": "SEC-1234",
+	}, public.Statements[0].Annotations)
+
+	for _, at := range []time.Time{ts, ts.Add(12 * time.Hour), ts.Add(48 * time.Hour)} {
+		require.Len(t, public.MatchesAt(at, "CVE-2014-123456", "pkg:deb/pkg@1.0", nil),
+			len(doc.MatchesAt(at, "CVE-2014-123456", "pkg:deb/pkg@1.0", nil)), at)
+	}
+	require.Empty(t, public.MatchesAt(ts.Add(48*time.Hour), "CVE-2014-123456", "pkg:deb/pkg@1.0", nil))
+}
+
+func TestPublicViewSignatures(t *testing.T) {
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	ts := time.Date(2023, 4, 17, 20, 34, 58, 0, time.UTC)
+	doc := &VEX{
+		Metadata: Metadata{ID: "https://example.com/internal/vex-1", Author: "Wolfi J Inkinson", Timestamp: &ts},
+		Statements: []Statement{
+			{
+				Vulnerability:   Vulnerability{Name: "CVE-2014-123456"},
+				Products:        []Product{{Component: Component{ID: "pkg:deb/pkg@1.0"}}},
+				Status:          StatusAffected,
+				StatusNotes:     "Ticket SEC-1234",
+				ActionStatement: "Update to 1.1",
+				Annotations:     map[string]string{AnnotationInternalFields: "status_notes"},
+			},
+			{
+				Vulnerability: Vulnerability{Name: "CVE-2014-654321"},
+				Products:      []Product{{Component: Component{ID: "pkg:deb/pkg@1.0"}}},
+				Status:        StatusFixed,
+			},
+		},
+	}
+	require.NoError(t, doc.SignStatements(key, "key-1"))
+
+	public, err := doc.PublicView()
+	require.NoError(t, err)
+	require.Len(t, public.Statements, 2)
+
+	// The redacted statement is no longer signed, the untouched one still
+	// verifies
+	require.ErrorIs(t, public.VerifyStatement(0, pub), ErrUnsignedStatement)
+	require.Nil(t, public.Statements[0].Annotations)
+	require.NoError(t, public.VerifyStatement(1, pub))
+	require.Equal(t, "key-1", public.Statements[1].Annotations[AnnotationSignatureKeyID])
+}
@@ -0,0 +1,47 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+// Reference points to an external resource with more information about a
+// statement, such as an advisory or a fix.
+type Reference struct {
+	// Category classifies the reference, for example "self" when pointing
+	// to the source document or "external" for third party resources.
+	Category string `json:"category,omitempty"`
+
+	// Summary is a short description of the referenced resource.
+	Summary string `json:"summary,omitempty"`
+
+	// URL locates the referenced resource.
+	URL string `json:"url"`
+}
+
+// Credit recognizes the people and organizations that contributed to the
+// discovery or handling of a vulnerability.
+type Credit struct {
+	// Names lists the people being credited.
+	Names []string `json:"names,omitempty"`
+
+	// Organization is the organization being credited.
+	Organization string `json:"organization,omitempty"`
+
+	// Summary describes the contribution being credited.
+	Summary string `json:"summary,omitempty"`
+
+	// URLs point to resources about the credited parties or their work.
+	URLs []string `json:"urls,omitempty"`
+}
+
+// DeepCopyInto copies the receiver and writes its value into out.
+func (c *Credit) DeepCopyInto(out *Credit) {
+	*out = *c
+	if c.Names != nil {
+		out.Names = make([]string, len(c.Names))
+		copy(out.Names, c.Names)
+	}
+	if c.URLs != nil {
+		out.URLs = make([]string, len(c.URLs))
+		copy(out.URLs, c.URLs)
+	}
+}
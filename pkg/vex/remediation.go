@@ -0,0 +1,128 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// RemediationCategory classifies a remediation. The categories are the ones
+// defined for CSAF remediations.
+type RemediationCategory string
+
+const (
+	// RemediationVendorFix is a fix issued by the vendor, such as an update.
+	RemediationVendorFix RemediationCategory = "vendor_fix"
+
+	// RemediationWorkaround is a configuration or deployment change that
+	// avoids the vulnerability.
+	RemediationWorkaround RemediationCategory = "workaround"
+
+	// RemediationMitigation reduces the impact or the likelihood of the
+	// vulnerability being exploited without removing it.
+	RemediationMitigation RemediationCategory = "mitigation"
+
+	// RemediationNoFixPlanned means there is no fix and none will be issued.
+	RemediationNoFixPlanned RemediationCategory = "no_fix_planned"
+
+	// RemediationNoneAvailable means there is currently no remediation.
+	RemediationNoneAvailable RemediationCategory = "none_available"
+)
+
+// RemediationCategories returns the valid remediation categories.
+func RemediationCategories() []string {
+	return []string{
+		string(RemediationVendorFix),
+		string(RemediationWorkaround),
+		string(RemediationMitigation),
+		string(RemediationNoFixPlanned),
+		string(RemediationNoneAvailable),
+	}
+}
+
+// Valid returns true if the category is one of the known remediation
+// categories.
+func (c RemediationCategory) Valid() bool {
+	switch c {
+	case RemediationVendorFix, RemediationWorkaround, RemediationMitigation,
+		RemediationNoFixPlanned, RemediationNoneAvailable:
+		return true
+	default:
+		return false
+	}
+}
+
+// Remediation is a machine readable action to remediate or mitigate the
+// vulnerability of an affected statement. Remediations complement the free
+// form action statement.
+type Remediation struct {
+	// Category classifies the remediation.
+	Category RemediationCategory `json:"category"`
+
+	// Details describe the remediation, eg the version fixing the
+	// vulnerability or the steps of a workaround.
+	Details string `json:"details,omitempty"`
+
+	// URL points to more information about the remediation, such as the
+	// fix release or the workaround instructions.
+	URL string `json:"url,omitempty"`
+
+	// Date is the time the remediation was made available.
+	Date *time.Time `json:"date,omitempty"`
+
+	// DueDate is the time the remediation is expected to be available.
+	DueDate *time.Time `json:"due_date,omitempty"`
+}
+
+// Validate checks the remediation data.
+func (r *Remediation) Validate() error {
+	if !r.Category.Valid() {
+		return fmt.Errorf("invalid remediation category %q, must be one of [%s]", r.Category, strings.Join(RemediationCategories(), ", "))
+	}
+	if r.URL != "" {
+		if u, err := url.Parse(r.URL); err != nil || u.Scheme == "" {
+			return fmt.Errorf("invalid remediation URL %q", r.URL)
+		}
+	}
+	return nil
+}
+
+// String returns a one line text description of the remediation, in the
+// form "category: details (url)".
+func (r *Remediation) String() string {
+	s := string(r.Category)
+	if r.Details != "" {
+		s += ": " + r.Details
+	}
+	if r.URL != "" {
+		s += " (" + r.URL + ")"
+	}
+	return s
+}
+
+// DeepCopyInto copies the receiver and writes its value into out.
+func (r *Remediation) DeepCopyInto(out *Remediation) {
+	*out = *r
+	if r.Date != nil {
+		out.Date = new(time.Time)
+		*out.Date = *r.Date
+	}
+	if r.DueDate != nil {
+		out.DueDate = new(time.Time)
+		*out.DueDate = *r.DueDate
+	}
+}
+
+// RemediationsActionStatement returns an action statement describing the
+// remediations, one per line.
+func RemediationsActionStatement(remediations []Remediation) string {
+	lines := make([]string, 0, len(remediations))
+	for i := range remediations {
+		lines = append(lines, remediations[i].String())
+	}
+	return strings.Join(lines, "\n")
+}
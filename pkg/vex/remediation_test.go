@@ -0,0 +1,59 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemediationValidate(t *testing.T) {
+	for m, tc := range map[string]struct {
+		remediation Remediation
+		shouldErr   bool
+	}{
+		"vendor fix":       {Remediation{Category: RemediationVendorFix, Details: "Update to 1.2", URL: "https://example.com/1.2"}, false},
+		"none available":   {Remediation{Category: RemediationNoneAvailable}, false},
+		"no fix planned":   {Remediation{Category: RemediationNoFixPlanned}, false},
+		"missing category": {Remediation{Details: "Update"}, true},
+		"bad category":     {Remediation{Category: "patch"}, true},
+		"relative url":     {Remediation{Category: RemediationWorkaround, URL: "docs/workaround"}, true},
+	} {
+		t.Run(m, func(t *testing.T) {
+			err := tc.remediation.Validate()
+			if tc.shouldErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestRemediationsActionStatement(t *testing.T) {
+	require.Empty(t, RemediationsActionStatement(nil))
+	require.Equal(t,
+		"vendor_fix: Update to 1.2 (https://example.com/1.2)\nworkaround: Disable the plugin\nnone_available",
+		RemediationsActionStatement([]Remediation{
+			{Category: RemediationVendorFix, Details: "Update to 1.2", URL: "https://example.com/1.2"},
+			{Category: RemediationWorkaround, Details: "Disable the plugin"},
+			{Category: RemediationNoneAvailable},
+		}),
+	)
+}
+
+func TestRemediationJSON(t *testing.T) {
+	due := time.Date(2023, 7, 1, 0, 0, 0, 0, time.UTC)
+	r := Remediation{Category: RemediationVendorFix, Details: "Update to 1.2", DueDate: &due}
+	data, err := json.Marshal(&r)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"category":"vendor_fix","details":"Update to 1.2","due_date":"2023-07-01T00:00:00Z"}`, string(data))
+
+	parsed := Remediation{}
+	require.NoError(t, json.Unmarshal(data, &parsed))
+	require.Equal(t, r, parsed)
+}
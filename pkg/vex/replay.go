@@ -0,0 +1,198 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"slices"
+	"sort"
+)
+
+// ReplayOp is the corpus call performed by a replayed query.
+type ReplayOp string
+
+const (
+	// ReplayMatches queries the statements matching a vulnerability, product
+	// and subcomponents with Corpus.Matches.
+	ReplayMatches ReplayOp = "matches"
+
+	// ReplayEffectiveStatus queries the effective status of a vulnerability
+	// in a product with Corpus.EffectiveStatus.
+	ReplayEffectiveStatus ReplayOp = "effective_status"
+)
+
+// ReplayOutcome is the result of a query in a workload.
+type ReplayOutcome struct {
+	// Statuses are the statuses of the statements returned by a matches
+	// query, in the order they were returned.
+	Statuses []Status `json:"statuses,omitempty"`
+
+	// Status is the status returned by an effective status query. It is
+	// empty when no statement applies.
+	Status Status `json:"status,omitempty"`
+}
+
+// ReplayQuery is a recorded corpus call and its expected outcome.
+type ReplayQuery struct {
+	Op            ReplayOp      `json:"op"`
+	Vulnerability string        `json:"vulnerability"`
+	Product       string        `json:"product"`
+	Subcomponents []string      `json:"subcomponents,omitempty"`
+	Expected      ReplayOutcome `json:"expected"`
+}
+
+// Workload is a sequence of queries recorded against a corpus. Workloads
+// are serialized as JSON to be replayed later, usually after upgrading the
+// library, to catch changes in the matching behavior.
+type Workload struct {
+	// Seed is the seed used to generate the queries
+	Seed int64 `json:"seed"`
+
+	// Queries are the recorded queries in the order they are replayed
+	Queries []ReplayQuery `json:"queries"`
+}
+
+// ReplayMismatch is a replayed query whose outcome differs from the
+// recorded one.
+type ReplayMismatch struct {
+	// Index is the position of the query in the workload
+	Index int
+
+	// Query is the replayed query, including its expected outcome
+	Query ReplayQuery
+
+	// Got is the outcome of replaying the query
+	Got ReplayOutcome
+}
+
+func (m *ReplayMismatch) String() string {
+	q := &m.Query
+	if q.Op == ReplayEffectiveStatus {
+		return fmt.Sprintf("query #%d: %s of %s in %s: expected %q, got %q",
+			m.Index, q.Op, q.Vulnerability, q.Product, q.Expected.Status, m.Got.Status)
+	}
+	return fmt.Sprintf("query #%d: %s of %s in %s %v: expected %v, got %v",
+		m.Index, q.Op, q.Vulnerability, q.Product, q.Subcomponents, q.Expected.Statuses, m.Got.Statuses)
+}
+
+// EffectiveStatus returns the latest status of the vulnerability in the
+// product across all the documents in the corpus, or an empty status if no
+// statement applies to it.
+func (c *Corpus) EffectiveStatus(vulnID, product string) Status {
+	return c.CoverageReport([]string{vulnID}, product)[0].Status
+}
+
+// RecordWorkload generates a workload of n queries from the vulnerabilities,
+// products and subcomponents in the corpus and records their outcomes. The
+// queries are picked at random but the same seed and corpus always produce
+// the same workload. Vulnerabilities and products are combined freely, so
+// the workload exercises both matching and non-matching queries.
+func (c *Corpus) RecordWorkload(seed int64, n int) *Workload {
+	vulns, products, subcomponents := c.replayVocabulary()
+	w := &Workload{Seed: seed, Queries: []ReplayQuery{}}
+	if len(vulns) == 0 || len(products) == 0 {
+		return w
+	}
+
+	rnd := rand.New(rand.NewSource(seed)) //nolint:gosec // Workloads need to be reproducible, not secure
+	for range n {
+		q := ReplayQuery{
+			Op:            ReplayMatches,
+			Vulnerability: vulns[rnd.Intn(len(vulns))],
+			Product:       products[rnd.Intn(len(products))],
+		}
+		if rnd.Intn(2) == 0 {
+			q.Op = ReplayEffectiveStatus
+		} else if subs := subcomponents[q.Product]; len(subs) > 0 && rnd.Intn(2) == 0 {
+			q.Subcomponents = []string{subs[rnd.Intn(len(subs))]}
+		}
+		q.Expected = c.replayQuery(&q)
+		w.Queries = append(w.Queries, q)
+	}
+	return w
+}
+
+// Replay runs the queries of the workload against the corpus and returns
+// those whose outcome differs from the recorded one.
+func (c *Corpus) Replay(w *Workload) []ReplayMismatch {
+	ret := []ReplayMismatch{}
+	for i := range w.Queries {
+		got := c.replayQuery(&w.Queries[i])
+		if got.Status != w.Queries[i].Expected.Status ||
+			!slices.Equal(got.Statuses, w.Queries[i].Expected.Statuses) {
+			ret = append(ret, ReplayMismatch{Index: i, Query: w.Queries[i], Got: got})
+		}
+	}
+	return ret
+}
+
+// replayQuery runs a query against the corpus.
+func (c *Corpus) replayQuery(q *ReplayQuery) ReplayOutcome {
+	ret := ReplayOutcome{}
+	switch q.Op {
+	case ReplayEffectiveStatus:
+		ret.Status = c.EffectiveStatus(q.Vulnerability, q.Product)
+	case ReplayMatches:
+		for _, stmt := range c.Matches(q.Vulnerability, q.Product, q.Subcomponents) {
+			ret.Statuses = append(ret.Statuses, stmt.Status)
+		}
+	}
+	return ret
+}
+
+// replayVocabulary returns the sorted vulnerability and product identifiers
+// in the corpus and the subcomponents listed for each product.
+func (c *Corpus) replayVocabulary() (vulns, products []string, subcomponents map[string][]string) {
+	vulnSet := map[string]struct{}{}
+	subSet := map[string]map[string]struct{}{}
+	for _, doc := range c.docs {
+		for i := range doc.Statements {
+			stmt := &doc.Statements[i]
+			vulnSet[string(stmt.Vulnerability.Name)] = struct{}{}
+			for _, p := range stmt.Products {
+				if p.ID == "" {
+					continue
+				}
+				if _, ok := subSet[p.ID]; !ok {
+					subSet[p.ID] = map[string]struct{}{}
+				}
+				for _, sc := range p.Subcomponents {
+					if sc.ID != "" {
+						subSet[p.ID][sc.ID] = struct{}{}
+					}
+				}
+			}
+		}
+	}
+
+	subcomponents = map[string][]string{}
+	for p, subs := range subSet {
+		products = append(products, p)
+		subcomponents[p] = sortedKeys(subs)
+	}
+	sort.Strings(products)
+	return sortedKeys(vulnSet), products, subcomponents
+}
+
+// ReadWorkload decodes a JSON workload.
+func ReadWorkload(r io.Reader) (*Workload, error) {
+	w := &Workload{}
+	if err := json.NewDecoder(r).Decode(w); err != nil {
+		return nil, fmt.Errorf("decoding workload: %w", err)
+	}
+	return w, nil
+}
+
+// WriteJSON writes the workload as JSON.
+func (w *Workload) WriteJSON(out io.Writer) error {
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(w); err != nil {
+		return fmt.Errorf("encoding workload: %w", err)
+	}
+	return nil
+}
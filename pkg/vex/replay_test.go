@@ -0,0 +1,55 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCorpusEffectiveStatus(t *testing.T) {
+	corpus, err := LoadCorpus([]string{"testdata/v020-1.vex.json", "testdata/v020-2.vex.json"})
+	require.NoError(t, err)
+	require.Equal(t, StatusUnderInvestigation, corpus.EffectiveStatus("CVE-1234-5678", "pkg:apk/wolfi/git@2.41.0-1"))
+	require.Equal(t, Status(""), corpus.EffectiveStatus("CVE-0000-0000", "pkg:apk/wolfi/git@2.41.0-1"))
+}
+
+func TestReplay(t *testing.T) {
+	corpus, err := LoadCorpus([]string{"testdata/v020-1.vex.json", "testdata/v020-2.vex.json"})
+	require.NoError(t, err)
+
+	w := corpus.RecordWorkload(42, 50)
+	require.Len(t, w.Queries, 50)
+	require.Equal(t, w, corpus.RecordWorkload(42, 50))
+	require.Empty(t, corpus.Replay(w))
+
+	// Workloads survive serialization
+	var b bytes.Buffer
+	require.NoError(t, w.WriteJSON(&b))
+	read, err := ReadWorkload(&b)
+	require.NoError(t, err)
+	require.Empty(t, corpus.Replay(read))
+
+	// A later statement changes the outcome of the queries about it
+	ts := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	doc := New()
+	doc.Timestamp = &ts
+	doc.Statements = []Statement{{
+		Vulnerability: Vulnerability{Name: VulnerabilityID(w.Queries[0].Vulnerability)},
+		Products:      []Product{{Component: Component{ID: w.Queries[0].Product}}},
+		Status:        StatusUnderInvestigation,
+	}}
+	corpus.Add(&doc)
+	mismatches := corpus.Replay(w)
+	require.NotEmpty(t, mismatches)
+	require.Equal(t, 0, mismatches[0].Index)
+	require.Contains(t, mismatches[0].String(), w.Queries[0].Vulnerability)
+
+	require.Empty(t, NewCorpus().RecordWorkload(1, 10).Queries)
+	_, err = ReadWorkload(bytes.NewReader([]byte("{")))
+	require.Error(t, err)
+}
@@ -0,0 +1,244 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// IdentifierResolver is a function that lazily resolves a software identifier
+// into alternative identifiers that describe the same piece of software. For
+// example, a resolver can turn a floating container image tag into the digest
+// it currently points to or translate an internal alias into a purl.
+//
+// Resolvers are only invoked at match time when the original identifier does
+// not match, so expensive lookups are only performed when needed.
+type IdentifierResolver func(identifier string) ([]string, error)
+
+// MemoizeResolver wraps an IdentifierResolver and returns a new one that
+// caches the results of each lookup. Failed lookups are not cached. The
+// returned resolver is safe for concurrent use as long as the wrapped
+// resolver is.
+func MemoizeResolver(resolver IdentifierResolver) IdentifierResolver {
+	if resolver == nil {
+		return nil
+	}
+	var mtx sync.Mutex
+	cache := map[string][]string{}
+
+	return func(identifier string) ([]string, error) {
+		mtx.Lock()
+		ids, ok := cache[identifier]
+		mtx.Unlock()
+		if ok {
+			return ids, nil
+		}
+
+		ids, err := resolver(identifier)
+		if err != nil {
+			return nil, err
+		}
+
+		mtx.Lock()
+		cache[identifier] = ids
+		mtx.Unlock()
+		return ids, nil
+	}
+}
+
+// resolveIdentifier calls the resolver on identifier and returns the
+// resolved list. If the resolver fails, the error is logged and the
+// identifier is considered unresolvable.
+func resolveIdentifier(resolver IdentifierResolver, identifier string) []string {
+	if resolver == nil || identifier == "" {
+		return nil
+	}
+	ids, err := resolver(identifier)
+	if err != nil {
+		slog.Debug("unable to resolve identifier", "identifier", identifier, "error", err)
+		return nil
+	}
+	return ids
+}
+
+// MatchesWithResolver works like Matches but, if the identifier does not
+// match the component, it invokes the resolver to get alternative
+// identifiers and tries to match those.
+func (c *Component) MatchesWithResolver(identifier string, resolver IdentifierResolver) bool {
+	return c.matchesWithOptions(identifier, &MatchOptions{Resolver: resolver})
+}
+
+// matchesWithOptions matches the identifier and, if it does not match, the
+// identifiers returned by the resolver in the options.
+func (c *Component) matchesWithOptions(identifier string, opts *MatchOptions) bool {
+	_, ok := c.matchWithOptions(identifier, opts)
+	return ok
+}
+
+// matchWithOptions implements matchesWithOptions, returning which field of
+// the component matched. The query of the returned match is the resolved
+// identifier when the match comes from the resolver.
+func (c *Component) matchWithOptions(identifier string, opts *MatchOptions) (IdentifierMatch, bool) {
+	if c.ID == WildcardProduct {
+		return IdentifierMatch{Kind: MatchByWildcard, Value: c.ID, Query: identifier}, opts.Wildcards && identifier != ""
+	}
+
+	purlOpts := opts.purlOptions()
+	sut := c
+	if opts.CaseInsensitiveIdentifiers {
+		lc := c.toLower()
+		sut = &lc
+	}
+
+	if m, ok := sut.match(opts.foldCase(identifier), purlOpts); ok {
+		return c.originalMatch(m), true
+	}
+
+	for _, id := range resolveIdentifier(opts.Resolver, identifier) {
+		if m, ok := sut.match(opts.foldCase(id), purlOpts); ok {
+			return c.originalMatch(m), true
+		}
+	}
+	return IdentifierMatch{}, false
+}
+
+// originalMatch sets the value of a match to the original value in the
+// component, which differs from the matched one when matching ignores case.
+func (c *Component) originalMatch(m IdentifierMatch) IdentifierMatch {
+	switch m.Kind {
+	case MatchByID:
+		m.Value = c.ID
+	case MatchByIdentifier:
+		m.Value = c.Identifiers[m.Type]
+	case MatchByHash:
+		m.Value = string(c.Hashes[m.Algorithm])
+	}
+	return m
+}
+
+// MatchesWithResolver works like Matches but uses the resolver to lazily
+// resolve the product and subcomponent identifiers when they don't match
+// directly.
+func (p *Product) MatchesWithResolver(identifier, subIdentifier string, resolver IdentifierResolver) bool {
+	return p.matchesWithOptions(identifier, []string{subIdentifier}, &MatchOptions{Resolver: resolver})
+}
+
+// matchesWithOptions implements MatchesWithOptions and MatchesWithResolver
+// using the resolver and purl matching settings in the options. Empty
+// subcomponent identifiers are ignored.
+func (p *Product) matchesWithOptions(identifier string, subIdentifiers []string, opts *MatchOptions) bool {
+	if !p.identityMatches(identifier, opts) {
+		return false
+	}
+	_, _, ok := p.subcomponentMatch(subIdentifiers, opts)
+	return ok
+}
+
+// subcomponentMatch returns the subcomponent matching any of the identifiers
+// and how it matched. It returns true with a nil subcomponent when the
+// product has no subcomponents or there are no identifiers to match.
+func (p *Product) subcomponentMatch(subIdentifiers []string, opts *MatchOptions) (*Subcomponent, IdentifierMatch, bool) {
+	if len(p.Subcomponents) == 0 {
+		return nil, IdentifierMatch{}, true
+	}
+
+	query := false
+	for _, id := range subIdentifiers {
+		if id == "" {
+			continue
+		}
+		query = true
+		for i := range p.Subcomponents {
+			if m, ok := p.Subcomponents[i].matchWithOptions(id, opts); ok {
+				return &p.Subcomponents[i], m, true
+			}
+		}
+	}
+
+	return nil, IdentifierMatch{}, !query
+}
+
+// identityMatches returns true if the identifier matches the product
+// component or any of its artifacts.
+func (p *Product) identityMatches(identifier string, opts *MatchOptions) bool {
+	_, ok := p.identityMatch(identifier, opts)
+	return ok
+}
+
+// identityMatch works like identityMatches but also returns how the
+// product component or artifact matched.
+func (p *Product) identityMatch(identifier string, opts *MatchOptions) (IdentifierMatch, bool) {
+	for _, c := range p.components() {
+		if m, ok := c.matchWithOptions(identifier, opts); ok {
+			return m, true
+		}
+	}
+	return IdentifierMatch{}, false
+}
+
+// MatchesWithResolver returns true if the statement matches the vulnerability,
+// product and subcomponents. Identifiers that don't match are passed
+// through the resolver to try to match alternative identifiers. Statements
+// that expired (see SetValidUntil) don't match.
+func (stmt *Statement) MatchesWithResolver(vuln, product string, subcomponents []string, resolver IdentifierResolver) bool {
+	return stmt.matchesWithResolverOptions(vuln, product, subcomponents, &MatchOptions{Resolver: resolver})
+}
+
+// matchesWithResolverOptions implements MatchesWithResolver with the
+// resolver of the options, checking if the statement expired at the time
+// of their clock.
+func (stmt *Statement) matchesWithResolverOptions(vuln, product string, subcomponents []string, opts *MatchOptions) bool {
+	if stmt.Expired(clockNow(opts.Clock)) {
+		return false
+	}
+	return stmt.matchesWithResolver(vuln, product, subcomponents, opts.Resolver)
+}
+
+// matchesWithResolver implements MatchesWithResolver without checking if
+// the statement expired.
+func (stmt *Statement) matchesWithResolver(vuln, product string, subcomponents []string, resolver IdentifierResolver) bool {
+	if !stmt.Vulnerability.Matches(vuln) {
+		return false
+	}
+
+	for i := range stmt.Products {
+		if len(subcomponents) == 0 {
+			if stmt.Products[i].MatchesWithResolver(product, "", resolver) {
+				return true
+			}
+		}
+
+		for _, sc := range subcomponents {
+			if stmt.Products[i].MatchesWithResolver(product, sc, resolver) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// MatchesWithResolver returns the statements in the document that apply to
+// the product and vulnerability, resolving identifiers lazily with the
+// passed resolver. Wrap the resolver with MemoizeResolver to avoid resolving
+// the same identifier more than once.
+func (vexDoc *VEX) MatchesWithResolver(vulnID, product string, subcomponents []string, resolver IdentifierResolver) []Statement {
+	statements := vexDoc.Statements
+	var t time.Time
+	if vexDoc.Timestamp != nil {
+		t = *vexDoc.Timestamp
+	}
+
+	matches := []Statement{}
+
+	for i := len(statements) - 1; i >= 0; i-- {
+		if statements[i].MatchesWithResolver(vulnID, product, subcomponents, resolver) {
+			matches = append(matches, statements[i])
+		}
+	}
+
+	SortStatements(matches, t)
+	return matches
+}
@@ -0,0 +1,114 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchesWithResolver(t *testing.T) {
+	digest := "pkg:oci/alpine@sha256%3A124c7d2707904eea7431fffe91522a01e5a861a624ee31d03372cc1d138a3126"
+	resolver := func(identifier string) ([]string, error) {
+		switch identifier {
+		case "alpine:latest":
+			return []string{digest}, nil
+		case "internal-libssl":
+			return []string{"pkg:apk/alpine/libssl@3.0.8-r3"}, nil
+		}
+		return nil, errors.New("unknown identifier")
+	}
+
+	for testCase, tc := range map[string]struct {
+		sut          *Product
+		product      string
+		subcomponent string
+		resolver     IdentifierResolver
+		mustMatch    bool
+	}{
+		"direct match does not need resolver": {
+			sut:       &Product{Component: Component{ID: digest}},
+			product:   digest,
+			mustMatch: true,
+		},
+		"no resolver": {
+			sut:       &Product{Component: Component{ID: digest}},
+			product:   "alpine:latest",
+			mustMatch: false,
+		},
+		"product resolved": {
+			sut:       &Product{Component: Component{ID: digest}},
+			product:   "alpine:latest",
+			resolver:  resolver,
+			mustMatch: true,
+		},
+		"subcomponent resolved": {
+			sut: &Product{
+				Component: Component{ID: digest},
+				Subcomponents: []Subcomponent{
+					{Component{ID: "pkg:apk/alpine/libssl@3.0.8-r3"}},
+				},
+			},
+			product:      "alpine:latest",
+			subcomponent: "internal-libssl",
+			resolver:     resolver,
+			mustMatch:    true,
+		},
+		"resolver error": {
+			sut:       &Product{Component: Component{ID: digest}},
+			product:   "debian:latest",
+			resolver:  resolver,
+			mustMatch: false,
+		},
+	} {
+		require.Equal(t, tc.mustMatch, tc.sut.MatchesWithResolver(tc.product, tc.subcomponent, tc.resolver), "failed: %s", testCase)
+	}
+}
+
+func TestMemoizeResolver(t *testing.T) {
+	calls := 0
+	resolver := MemoizeResolver(func(identifier string) ([]string, error) {
+		calls++
+		return []string{"pkg:deb/pkg@1.0"}, nil
+	})
+
+	doc := &VEX{
+		Statements: []Statement{
+			{
+				Vulnerability: Vulnerability{Name: "CVE-2014-123456"},
+				Products:      []Product{{Component: Component{ID: "pkg:deb/pkg@1.0"}}},
+				Status:        StatusNotAffected,
+			},
+			{
+				Vulnerability: Vulnerability{Name: "CVE-2014-123456"},
+				Products:      []Product{{Component: Component{ID: "pkg:deb/pkg@1.0"}}},
+				Status:        StatusFixed,
+			},
+		},
+	}
+
+	require.Len(t, doc.MatchesWithResolver("CVE-2014-123456", "my-pkg", nil, resolver), 2)
+	require.Equal(t, 1, calls)
+	require.Nil(t, MemoizeResolver(nil))
+
+	// Failures are not cached, the lookup is retried
+	calls = 0
+	resolver = MemoizeResolver(func(identifier string) ([]string, error) {
+		calls++
+		if calls == 1 {
+			return nil, errors.New("lookup failed")
+		}
+		return []string{"pkg:deb/pkg@1.0"}, nil
+	})
+	_, err := resolver("my-pkg")
+	require.Error(t, err)
+	ids, err := resolver("my-pkg")
+	require.NoError(t, err)
+	require.Equal(t, []string{"pkg:deb/pkg@1.0"}, ids)
+	_, err = resolver("my-pkg")
+	require.NoError(t, err)
+	require.Equal(t, 2, calls)
+}
@@ -0,0 +1,197 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxIdentifierLength is the maximum length in bytes of an identifier
+// accepted by SanitizeIdentifier.
+const MaxIdentifierLength = 4096
+
+var (
+	// ErrEmptyIdentifier is returned when an identifier is empty after
+	// sanitization.
+	ErrEmptyIdentifier = errors.New("identifier is empty")
+
+	// ErrIdentifierTooLong is returned when an identifier exceeds
+	// MaxIdentifierLength.
+	ErrIdentifierTooLong = fmt.Errorf("identifier is longer than %d bytes", MaxIdentifierLength)
+
+	// ErrInvalidUTF8 is returned when an identifier is not valid UTF-8.
+	ErrInvalidUTF8 = errors.New("identifier is not valid UTF-8")
+)
+
+// SanitizeIdentifier cleans an identifier received from an untrusted source
+// before using it for matching or storing it. It rejects identifiers that are
+// not valid UTF-8, that are too long or that contain malformed or control
+// character percent-encodings. Control characters and surrounding whitespace
+// are removed.
+//
+// Percent-encoded identifiers other than purls and IRIs are decoded. Purls
+// and IRIs are kept encoded as the encoding is part of their syntax.
+func SanitizeIdentifier(identifier string) (string, error) {
+	if len(identifier) > MaxIdentifierLength {
+		return "", ErrIdentifierTooLong
+	}
+	if !utf8.ValidString(identifier) {
+		return "", ErrInvalidUTF8
+	}
+
+	identifier = strings.TrimSpace(strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, identifier))
+	if identifier == "" {
+		return "", ErrEmptyIdentifier
+	}
+
+	if !strings.Contains(identifier, "%") {
+		return identifier, nil
+	}
+
+	decoded, err := url.PathUnescape(identifier)
+	if err != nil {
+		return "", fmt.Errorf("invalid percent-encoding in identifier: %w", err)
+	}
+	if !utf8.ValidString(decoded) {
+		return "", ErrInvalidUTF8
+	}
+	if strings.IndexFunc(decoded, unicode.IsControl) != -1 {
+		return "", errors.New("identifier encodes control characters")
+	}
+
+	if strings.HasPrefix(identifier, "pkg:") || VulnerabilityID(identifier).isIRI() {
+		return identifier, nil
+	}
+
+	decoded = strings.TrimSpace(decoded)
+	if decoded == "" {
+		return "", ErrEmptyIdentifier
+	}
+	return decoded, nil
+}
+
+// SanitizeVulnerabilityID sanitizes a vulnerability identifier received from
+// an untrusted source. See SanitizeIdentifier for details.
+func SanitizeVulnerabilityID(id string) (VulnerabilityID, error) {
+	s, err := SanitizeIdentifier(id)
+	if err != nil {
+		return "", err
+	}
+	return VulnerabilityID(s), nil
+}
+
+// SanitizeIdentifiers sanitizes the vulnerability and software identifiers
+// in the document statements in place. It is intended to be called before
+// storing documents received from untrusted sources. Optional identifiers
+// that are empty are left untouched.
+func (vexDoc *VEX) SanitizeIdentifiers() error {
+	for i := range vexDoc.Statements {
+		if err := vexDoc.Statements[i].sanitizeIdentifiers(); err != nil {
+			return fmt.Errorf("statement #%d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (stmt *Statement) sanitizeIdentifiers() error {
+	v := &stmt.Vulnerability
+	if err := sanitizeOptional(&v.ID); err != nil {
+		return fmt.Errorf("sanitizing vulnerability @id: %w", err)
+	}
+
+	name := string(v.Name)
+	if err := sanitizeOptional(&name); err != nil {
+		return fmt.Errorf("sanitizing vulnerability name: %w", err)
+	}
+	v.Name = VulnerabilityID(name)
+
+	for i := range v.Aliases {
+		alias, err := SanitizeVulnerabilityID(string(v.Aliases[i]))
+		if err != nil {
+			return fmt.Errorf("sanitizing vulnerability alias: %w", err)
+		}
+		v.Aliases[i] = alias
+	}
+
+	for i := range stmt.Products {
+		p := &stmt.Products[i]
+		if err := p.Component.sanitizeIdentifiers(); err != nil {
+			return fmt.Errorf("sanitizing product: %w", err)
+		}
+		for j := range p.Artifacts {
+			if err := p.Artifacts[j].sanitizeIdentifiers(); err != nil {
+				return fmt.Errorf("sanitizing artifact: %w", err)
+			}
+		}
+		for j := range p.Subcomponents {
+			if err := p.Subcomponents[j].sanitizeIdentifiers(); err != nil {
+				return fmt.Errorf("sanitizing subcomponent: %w", err)
+			}
+		}
+	}
+	return nil
+}
+
+func (c *Component) sanitizeIdentifiers() error {
+	if err := sanitizeOptional(&c.ID); err != nil {
+		return err
+	}
+	for t, id := range c.Identifiers {
+		s, err := SanitizeIdentifier(id)
+		if err != nil {
+			return fmt.Errorf("%s identifier: %w", t, err)
+		}
+		c.Identifiers[t] = s
+	}
+	for a, h := range c.Hashes {
+		s, err := SanitizeIdentifier(string(h))
+		if err != nil {
+			return fmt.Errorf("%s hash: %w", a, err)
+		}
+		c.Hashes[a] = Hash(s)
+	}
+	return nil
+}
+
+// sanitizeOptional sanitizes the string in place unless it is empty.
+func sanitizeOptional(s *string) error {
+	if *s == "" {
+		return nil
+	}
+	sanitized, err := SanitizeIdentifier(*s)
+	if err != nil {
+		return err
+	}
+	*s = sanitized
+	return nil
+}
+
+// sanitizeQuery sanitizes the identifiers of a match query. It returns an
+// error if any of them, including the subcomponents, can't be sanitized.
+func sanitizeQuery(vuln, product string, subcomponents []string) (cleanVuln, cleanProduct string, cleanSubs []string, err error) {
+	if cleanVuln, err = SanitizeIdentifier(vuln); err != nil {
+		return "", "", nil, fmt.Errorf("sanitizing vulnerability: %w", err)
+	}
+	if cleanProduct, err = SanitizeIdentifier(product); err != nil {
+		return "", "", nil, fmt.Errorf("sanitizing product: %w", err)
+	}
+	for _, sc := range subcomponents {
+		s, err := SanitizeIdentifier(sc)
+		if err != nil {
+			return "", "", nil, fmt.Errorf("sanitizing subcomponent %q: %w", sc, err)
+		}
+		cleanSubs = append(cleanSubs, s)
+	}
+	return cleanVuln, cleanProduct, cleanSubs, nil
+}
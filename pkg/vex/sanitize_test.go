@@ -0,0 +1,88 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSanitizeIdentifier(t *testing.T) {
+	for m, tc := range map[string]struct {
+		identifier string
+		expected   string
+		shouldErr  bool
+	}{
+		"clean":                 {"CVE-2023-1234", "CVE-2023-1234", false},
+		"whitespace":            {"  CVE-2023-1234\n", "CVE-2023-1234", false},
+		"control characters":    {"CVE-2023\x00-12\x1b34", "CVE-2023-1234", false},
+		"percent-encoded":       {"CVE%2D2023%2D1234", "CVE-2023-1234", false},
+		"purl kept encoded":     {"pkg:npm/%40angular/core@1.0.0", "pkg:npm/%40angular/core@1.0.0", false},
+		"IRI kept encoded":      {"https://example.com/vuln%201", "https://example.com/vuln%201", false},
+		"empty":                 {"", "", true},
+		"only control":          {"\x00\x01", "", true},
+		"invalid utf-8":         {"CVE-\xff", "", true},
+		"too long":              {strings.Repeat("a", MaxIdentifierLength+1), "", true},
+		"malformed escape":      {"CVE%2", "", true},
+		"encoded control":       {"pkg:npm/foo%00@1.0", "", true},
+		"encoded invalid utf-8": {"CVE%FF", "", true},
+	} {
+		res, err := SanitizeIdentifier(tc.identifier)
+		if tc.shouldErr {
+			require.Error(t, err, m)
+			continue
+		}
+		require.NoError(t, err, m)
+		require.Equal(t, tc.expected, res, m)
+	}
+}
+
+func TestSanitizeIdentifiers(t *testing.T) {
+	doc := New()
+	doc.Statements = []Statement{
+		{
+			Vulnerability: Vulnerability{Name: " CVE-2023-1234\t", Aliases: []VulnerabilityID{"GHSA%2Dxxxx"}},
+			Products: []Product{
+				{
+					Component:     Component{ID: "pkg:apk/wolfi/git@2.41.0\x00"},
+					Subcomponents: []Subcomponent{{Component: Component{Identifiers: map[IdentifierType]string{PURL: "\npkg:golang/foo@1.0"}}}},
+				},
+			},
+			Status: StatusUnderInvestigation,
+		},
+	}
+	require.NoError(t, doc.SanitizeIdentifiers())
+	stmt := doc.Statements[0]
+	require.Equal(t, VulnerabilityID("CVE-2023-1234"), stmt.Vulnerability.Name)
+	require.Equal(t, []VulnerabilityID{"GHSA-xxxx"}, stmt.Vulnerability.Aliases)
+	require.Equal(t, "pkg:apk/wolfi/git@2.41.0", stmt.Products[0].ID)
+	require.Equal(t, "pkg:golang/foo@1.0", stmt.Products[0].Subcomponents[0].Identifiers[PURL])
+
+	doc.Statements[0].Vulnerability.Aliases = []VulnerabilityID{"CVE%ZZ"}
+	require.Error(t, doc.SanitizeIdentifiers())
+}
+
+func TestMatchLevelSanitize(t *testing.T) {
+	stmt := Statement{
+		Vulnerability: Vulnerability{Name: "CVE-2023-1234"},
+		Products:      []Product{{Component: Component{ID: "pkg:apk/wolfi/git@2.41.0"}}},
+		Status:        StatusFixed,
+	}
+	require.Equal(t, NoMatch, stmt.MatchLevel("CVE-2023-1234\n", "pkg:apk/wolfi/git@2.41.0", nil, &MatchOptions{}))
+	require.Equal(t, FullMatch, stmt.MatchLevel("CVE-2023-1234\n", " pkg:apk/wolfi/git@2.41.0", nil, &MatchOptions{Sanitize: true}))
+	require.Equal(t, NoMatch, stmt.MatchLevel("CVE%2", "pkg:apk/wolfi/git@2.41.0", nil, &MatchOptions{Sanitize: true}))
+	require.Equal(t, FullMatch, stmt.MatchLevel("CVE-2023-1234", "pkg:apk/wolfi/git@2.41.0", []string{"pkg:golang/a@v1"}, &MatchOptions{Sanitize: true}))
+	require.Equal(t, NoMatch, stmt.MatchLevel("CVE-2023-1234", "pkg:apk/wolfi/git@2.41.0", []string{"\xff"}, &MatchOptions{Sanitize: true}))
+
+	doc := New()
+	doc.Statements = []Statement{stmt}
+	require.Len(t, doc.MatchesWithOptions("CVE%2D2023%2D1234", "pkg:apk/wolfi/git@2.41.0\x00", nil, &MatchOptions{Sanitize: true}), 1)
+	require.Empty(t, doc.MatchesWithOptions("\xff", "pkg:apk/wolfi/git@2.41.0", nil, &MatchOptions{Sanitize: true}))
+	require.Empty(t, doc.MatchesWithOptions("CVE-2023-1234", "pkg:apk/wolfi/git@2.41.0", []string{"pkg:golang/a@v1", "\xff"}, &MatchOptions{Sanitize: true}))
+
+	_, _, _, err := sanitizeQuery("CVE-2023-1234", "pkg:apk/wolfi/git@2.41.0", []string{"\xff"})
+	require.Error(t, err)
+}
@@ -0,0 +1,228 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/openvex/go-vex/pkg/cyclonedx"
+	"github.com/openvex/go-vex/pkg/spdx"
+)
+
+// Formats of the SBOMs read by MatchSBOM.
+const (
+	SBOMFormatSPDX      = "spdx"
+	SBOMFormatCycloneDX = "cyclonedx"
+)
+
+// SBOMComponent is a piece of software listed in an SBOM.
+type SBOMComponent struct {
+	Name    string
+	Version string
+	Purl    string
+	CPE     string
+	Hashes  map[Algorithm]Hash
+
+	// Root is true for the components the SBOM describes, that is the
+	// CycloneDX metadata component or the packages an SPDX document
+	// DESCRIBES.
+	Root bool
+}
+
+// SBOMMatch lists the statements that apply to a component of an SBOM.
+type SBOMMatch struct {
+	Component SBOMComponent
+
+	// Statements are the statements about the component, sorted like the
+	// results of VEX.Matches.
+	Statements []Statement
+}
+
+// MatchSBOM reads an SPDX or CycloneDX SBOM in JSON format and returns the
+// statements of the document that apply to each of its components. Format
+// must be SBOMFormatSPDX, SBOMFormatCycloneDX or empty to detect it.
+//
+// Components are matched by their purl, CPE and hashes. A statement applies
+// to a component when one of its products is the component, or when one of
+// its products is a component described by the SBOM and lists the
+// component in its subcomponents. Every component of the SBOM is returned,
+// with an empty list of statements if none apply to it.
+func MatchSBOM(doc *VEX, sbom io.Reader, format string) ([]SBOMMatch, error) {
+	data, err := io.ReadAll(sbom)
+	if err != nil {
+		return nil, fmt.Errorf("reading sbom: %w", err)
+	}
+
+	if format == "" {
+		switch {
+		case bytes.Contains(data, []byte(`"bomFormat"`)):
+			format = SBOMFormatCycloneDX
+		case bytes.Contains(data, []byte(`"spdxVersion"`)):
+			format = SBOMFormatSPDX
+		default:
+			return nil, errors.New("unable to detect sbom format")
+		}
+	}
+
+	var components []SBOMComponent
+	switch format {
+	case SBOMFormatCycloneDX:
+		bom, err := cyclonedx.Parse(data)
+		if err != nil {
+			return nil, fmt.Errorf("parsing sbom: %w", err)
+		}
+		components = cycloneDXComponents(bom)
+	case SBOMFormatSPDX:
+		spdxDoc, err := spdx.Parse(data)
+		if err != nil {
+			return nil, fmt.Errorf("parsing sbom: %w", err)
+		}
+		components = spdxComponents(spdxDoc)
+	default:
+		return nil, fmt.Errorf("unsupported sbom format %q", format)
+	}
+
+	roots := []string{}
+	for i := range components {
+		if components[i].Root {
+			roots = append(roots, components[i].identifiers()...)
+		}
+	}
+
+	var t time.Time
+	if doc.Timestamp != nil {
+		t = *doc.Timestamp
+	}
+
+	ret := make([]SBOMMatch, 0, len(components))
+	for i := range components {
+		ids := components[i].identifiers()
+		parents := roots
+		if components[i].Root {
+			parents = nil
+		}
+
+		match := SBOMMatch{Component: components[i], Statements: []Statement{}}
+		for j := range doc.Statements {
+			if doc.Statements[j].appliesToComponent(ids, parents) {
+				match.Statements = append(match.Statements, doc.Statements[j])
+			}
+		}
+		SortStatements(match.Statements, t)
+		ret = append(ret, match)
+	}
+	return ret, nil
+}
+
+// appliesToComponent returns true if a product of the statement is one of
+// the identifiers, or if it is one of the parents and lists one of the
+// identifiers as a subcomponent.
+func (stmt *Statement) appliesToComponent(ids, parents []string) bool {
+	for i := range stmt.Products {
+		p := &stmt.Products[i]
+		for _, id := range ids {
+			if p.Matches(id, "") {
+				return true
+			}
+		}
+		if len(p.Subcomponents) == 0 || len(ids) == 0 {
+			continue
+		}
+		for _, parent := range parents {
+			if p.MatchesWithOptions(parent, ids, nil) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// identifiers returns the strings that identify the component in VEX
+// statements.
+func (c *SBOMComponent) identifiers() []string {
+	ret := []string{}
+	for _, id := range []string{c.Purl, c.CPE} {
+		if id != "" {
+			ret = append(ret, id)
+		}
+	}
+	for _, h := range c.Hashes {
+		ret = append(ret, string(h))
+	}
+	return ret
+}
+
+// cycloneDXComponents returns the components of a CycloneDX BOM.
+func cycloneDXComponents(bom *cyclonedx.BOM) []SBOMComponent {
+	ret := []SBOMComponent{}
+	components := bom.ListComponents()
+	for i := range components {
+		c := &components[i]
+		component := SBOMComponent{
+			Name:    c.Name,
+			Version: c.Version,
+			Purl:    c.Purl,
+			CPE:     c.CPE,
+			Hashes:  map[Algorithm]Hash{},
+			Root:    i == 0 && bom.Metadata.Component != nil,
+		}
+		for _, h := range c.Hashes {
+			if algo, ok := sbomAlgorithm(h.Algorithm); ok {
+				component.Hashes[algo] = Hash(strings.ToLower(h.Content))
+			}
+		}
+		ret = append(ret, component)
+	}
+	return ret
+}
+
+// spdxComponents returns the packages of an SPDX document.
+func spdxComponents(doc *spdx.Document) []SBOMComponent {
+	roots := map[string]struct{}{}
+	for _, p := range doc.DescribedPackages() {
+		roots[p.ID] = struct{}{}
+	}
+
+	ret := []SBOMComponent{}
+	for i := range doc.Packages {
+		p := &doc.Packages[i]
+		_, root := roots[p.ID]
+		component := SBOMComponent{
+			Name:    p.Name,
+			Version: p.VersionInfo,
+			Purl:    p.Purl(),
+			CPE:     p.CPE(),
+			Hashes:  map[Algorithm]Hash{},
+			Root:    root,
+		}
+		for _, c := range p.Checksums {
+			if algo, ok := sbomAlgorithm(c.Algorithm); ok {
+				component.Hashes[algo] = Hash(strings.ToLower(c.Value))
+			}
+		}
+		ret = append(ret, component)
+	}
+	return ret
+}
+
+// sbomAlgorithm returns the registered algorithm of a hash algorithm name
+// used in SBOMs. Names are compared ignoring case and dashes, so both the
+// CycloneDX (SHA-256) and SPDX (SHA256) spellings are understood.
+func sbomAlgorithm(name string) (Algorithm, bool) {
+	normalize := func(s string) string {
+		return strings.ToLower(strings.ReplaceAll(s, "-", ""))
+	}
+	name = normalize(name)
+	for _, algo := range Algorithms() {
+		if normalize(algo) == name {
+			return Algorithm(algo), true
+		}
+	}
+	return "", false
+}
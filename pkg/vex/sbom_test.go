@@ -0,0 +1,147 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testSPDX = `{
+  "spdxVersion": "SPDX-2.3",
+  "SPDXID": "SPDXRef-DOCUMENT",
+  "documentDescribes": ["SPDXRef-image"],
+  "packages": [
+    {
+      "SPDXID": "SPDXRef-image",
+      "name": "wolfi-base",
+      "externalRefs": [{"referenceCategory": "PACKAGE-MANAGER", "referenceType": "purl", "referenceLocator": "pkg:oci/wolfi-base"}]
+    },
+    {
+      "SPDXID": "SPDXRef-git",
+      "name": "git",
+      "versionInfo": "2.41.0-r0",
+      "externalRefs": [{"referenceCategory": "PACKAGE-MANAGER", "referenceType": "purl", "referenceLocator": "pkg:apk/wolfi/git@2.41.0-r0"}]
+    },
+    {
+      "SPDXID": "SPDXRef-openssl",
+      "name": "openssl",
+      "versionInfo": "3.1.2-r0",
+      "checksums": [{"algorithm": "SHA256", "checksumValue": "E1A9A5C4F3F6C2A8B1D1C9EC2C5B6E8F0A9B4E2B8C3D1F2A6E7B9C0D1E2F3A4B"}],
+      "externalRefs": [{"referenceCategory": "SECURITY", "referenceType": "cpe23Type", "referenceLocator": "cpe:2.3:a:openssl:openssl:3.1.2:*:*:*:*:*:*:*"}]
+    }
+  ]
+}`
+
+const testCycloneDX = `{
+  "bomFormat": "CycloneDX",
+  "specVersion": "1.5",
+  "metadata": {
+    "component": {"bom-ref": "image", "name": "wolfi-base", "purl": "pkg:oci/wolfi-base"}
+  },
+  "components": [
+    {"bom-ref": "git", "name": "git", "version": "2.41.0-r0", "purl": "pkg:apk/wolfi/git@2.41.0-r0"},
+    {
+      "bom-ref": "openssl",
+      "name": "openssl",
+      "version": "3.1.2-r0",
+      "cpe": "cpe:2.3:a:openssl:openssl:3.1.2:*:*:*:*:*:*:*",
+      "hashes": [{"alg": "SHA-256", "content": "e1a9a5c4f3f6c2a8b1d1c9ec2c5b6e8f0a9b4e2b8c3d1f2a6e7b9c0d1e2f3a4b"}]
+    }
+  ]
+}`
+
+func TestMatchSBOM(t *testing.T) {
+	now := time.Now()
+	doc := New()
+	doc.Timestamp = &now
+	doc.Statements = []Statement{
+		{
+			ID:            "image",
+			Vulnerability: Vulnerability{Name: "CVE-2023-0001"},
+			Products:      []Product{{Component: Component{ID: "pkg:oci/wolfi-base"}}},
+			Status:        StatusUnderInvestigation,
+		},
+		{
+			ID:            "git-in-image",
+			Vulnerability: Vulnerability{Name: "CVE-2023-0002"},
+			Products: []Product{{
+				Component:     Component{ID: "pkg:oci/wolfi-base"},
+				Subcomponents: []Subcomponent{{Component: Component{ID: "pkg:apk/wolfi/git"}}},
+			}},
+			Status:        StatusNotAffected,
+			Justification: VulnerableCodeNotInExecutePath,
+		},
+		{
+			ID:            "git",
+			Vulnerability: Vulnerability{Name: "CVE-2023-0003"},
+			Products:      []Product{{Component: Component{ID: "pkg:apk/wolfi/git"}}},
+			Status:        StatusFixed,
+		},
+		{
+			ID:            "openssl-cpe",
+			Vulnerability: Vulnerability{Name: "CVE-2023-0004"},
+			Products: []Product{{Component: Component{
+				Identifiers: map[IdentifierType]string{CPE23: "cpe:2.3:a:openssl:openssl:3.1.*:*:*:*:*:*:*:*"},
+			}}},
+			Status: StatusAffected,
+		},
+		{
+			ID:            "openssl-hash",
+			Vulnerability: Vulnerability{Name: "CVE-2023-0005"},
+			Products: []Product{{Component: Component{
+				Hashes: map[Algorithm]Hash{SHA256: "e1a9a5c4f3f6c2a8b1d1c9ec2c5b6e8f0a9b4e2b8c3d1f2a6e7b9c0d1e2f3a4b"},
+			}}},
+			Status: StatusNotAffected, Justification: ComponentNotPresent,
+		},
+		{
+			ID:            "curl",
+			Vulnerability: Vulnerability{Name: "CVE-2023-0006"},
+			Products:      []Product{{Component: Component{ID: "pkg:apk/wolfi/curl"}}},
+			Status:        StatusAffected,
+		},
+	}
+
+	for m, tc := range map[string]struct {
+		sbom   string
+		format string
+	}{
+		"spdx":               {testSPDX, SBOMFormatSPDX},
+		"spdx detected":      {testSPDX, ""},
+		"cyclonedx":          {testCycloneDX, SBOMFormatCycloneDX},
+		"cyclonedx detected": {testCycloneDX, ""},
+	} {
+		t.Run(m, func(t *testing.T) {
+			matches, err := MatchSBOM(&doc, strings.NewReader(tc.sbom), tc.format)
+			require.NoError(t, err)
+			require.Len(t, matches, 3)
+
+			ids := map[string][]string{}
+			for _, match := range matches {
+				for i := range match.Statements {
+					ids[match.Component.Name] = append(ids[match.Component.Name], match.Statements[i].ID)
+				}
+			}
+			require.Equal(t, map[string][]string{
+				"wolfi-base": {"image", "git-in-image"},
+				"git":        {"git-in-image", "git"},
+				"openssl":    {"openssl-cpe", "openssl-hash"},
+			}, ids)
+
+			require.True(t, matches[0].Component.Root)
+			require.False(t, matches[1].Component.Root)
+			require.Equal(t, Hash("e1a9a5c4f3f6c2a8b1d1c9ec2c5b6e8f0a9b4e2b8c3d1f2a6e7b9c0d1e2f3a4b"), matches[2].Component.Hashes[SHA256])
+		})
+	}
+
+	_, err := MatchSBOM(&doc, strings.NewReader(testSPDX), SBOMFormatCycloneDX)
+	require.Error(t, err)
+	_, err = MatchSBOM(&doc, strings.NewReader(testSPDX), "syft")
+	require.Error(t, err)
+	_, err = MatchSBOM(&doc, strings.NewReader(`{}`), "")
+	require.Error(t, err)
+}
@@ -0,0 +1,94 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/openvex/go-vex/pkg/internal/jsonschema"
+)
+
+// schemaData is the OpenVEX JSON schema documents are validated against.
+//
+//go:embed openvex_json_schema.json
+var schemaData []byte
+
+// schema is the parsed OpenVEX JSON schema.
+var schema = jsonschema.MustParse("OpenVEX", schemaData)
+
+// ValidationError is a violation of the OpenVEX schema found in a document.
+type ValidationError struct {
+	// Pointer is the JSON pointer to the offending value
+	Pointer string
+
+	// Message describes the violation
+	Message string
+}
+
+// Error returns the pointer and the message of the violation.
+func (e *ValidationError) Error() string {
+	pointer := e.Pointer
+	if pointer == "" {
+		pointer = "(document)"
+	}
+	return fmt.Sprintf("%s: %s", pointer, e.Message)
+}
+
+// SchemaError is returned when a document does not conform to the OpenVEX
+// JSON schema. It lists all the violations found.
+type SchemaError struct {
+	Errors []*ValidationError
+}
+
+// Error returns the messages of all the violations.
+func (e *SchemaError) Error() string {
+	msgs := []string{}
+	for _, err := range e.Errors {
+		msgs = append(msgs, err.Error())
+	}
+	return "document does not conform to the OpenVEX schema: " + strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the violations.
+func (e *SchemaError) Unwrap() []error {
+	ret := []error{}
+	for _, err := range e.Errors {
+		ret = append(ret, err)
+	}
+	return ret
+}
+
+// ValidateBytes validates a serialized document against the OpenVEX JSON
+// schema. If the document does not conform to the schema, the returned
+// error is a *SchemaError listing the violations.
+func ValidateBytes(data []byte) error {
+	doc, err := jsonschema.Decode(data)
+	if err != nil {
+		return err
+	}
+
+	violations := schema.Validate(doc)
+	if len(violations) == 0 {
+		return nil
+	}
+	errs := make([]*ValidationError, len(violations))
+	for i := range violations {
+		errs[i] = &ValidationError{Pointer: violations[i].Pointer, Message: violations[i].Message}
+	}
+	return &SchemaError{Errors: errs}
+}
+
+// Validate checks that the document conforms to the OpenVEX JSON schema.
+// If it does not, the returned error is a *SchemaError listing the
+// violations.
+func (vexDoc *VEX) Validate() error {
+	data, err := json.Marshal(vexDoc)
+	if err != nil {
+		return fmt.Errorf("marshaling document: %w", err)
+	}
+	return ValidateBytes(data)
+}
@@ -0,0 +1,83 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateBytes(t *testing.T) {
+	valid, err := os.ReadFile("testdata/v0.2.0.json")
+	require.NoError(t, err)
+	require.NoError(t, ValidateBytes(valid))
+
+	header := `"@context": "https://openvex.dev/ns/v0.2.0", "@id": "https://openvex.dev/docs/example", "version": 1`
+	for m, tc := range map[string]struct {
+		doc      string
+		pointers []string
+	}{
+		"missing author": {
+			doc:      `{` + header + `, "timestamp": "2023-01-01T00:00:00Z", "statements": [{"vulnerability": {"name": "CVE-2023-1234"}, "products": [{"@id": "pkg:apk/wolfi/git"}], "status": "fixed"}]}`,
+			pointers: []string{""},
+		},
+		"invalid status": {
+			doc:      `{` + header + `, "author": "Me", "timestamp": "2023-01-01T00:00:00Z", "statements": [{"vulnerability": {"name": "CVE-2023-1234"}, "products": [{"@id": "pkg:apk/wolfi/git"}], "status": "maybe"}]}`,
+			pointers: []string{"/statements/0/status"},
+		},
+		"not affected without justification": {
+			doc:      `{` + header + `, "author": "Me", "timestamp": "2023-01-01T00:00:00Z", "statements": [{"vulnerability": {"name": "CVE-2023-1234"}, "products": [{"@id": "pkg:apk/wolfi/git"}], "status": "not_affected"}]}`,
+			pointers: []string{"/statements/0"},
+		},
+		"invalid timestamp": {
+			doc:      `{` + header + `, "author": "Me", "timestamp": "yesterday", "statements": [{"vulnerability": {"name": "CVE-2023-1234"}, "products": [{"@id": "pkg:apk/wolfi/git"}], "status": "fixed"}]}`,
+			pointers: []string{"/timestamp"},
+		},
+		"product without identifiers": {
+			doc:      `{` + header + `, "author": "Me", "timestamp": "2023-01-01T00:00:00Z", "statements": [{"vulnerability": {"name": "CVE-2023-1234"}, "products": [{"subcomponents": [{"@id": "pkg:golang/a~b/c"}]}], "status": "fixed"}]}`,
+			pointers: []string{"/statements/0/products/0"},
+		},
+		"no statements": {
+			doc:      `{` + header + `, "author": "Me", "timestamp": "2023-01-01T00:00:00Z", "statements": []}`,
+			pointers: []string{"/statements"},
+		},
+	} {
+		err := ValidateBytes([]byte(tc.doc))
+		var schemaErr *SchemaError
+		require.ErrorAs(t, err, &schemaErr, m)
+		pointers := []string{}
+		for _, e := range schemaErr.Errors {
+			pointers = append(pointers, e.Pointer)
+		}
+		require.Equal(t, tc.pointers, pointers, m)
+	}
+
+	err = ValidateBytes([]byte(`{"@context": `))
+	require.ErrorContains(t, err, "parsing document")
+}
+
+func TestValidate(t *testing.T) {
+	doc, err := Open("testdata/v0.2.0.json")
+	require.NoError(t, err)
+	require.NoError(t, doc.Validate())
+
+	ts := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	doc = &VEX{Metadata: Metadata{
+		Context: ContextLocator(), ID: "https://openvex.dev/docs/example", Author: "Me", Timestamp: &ts, Version: 1,
+	}}
+	doc.Statements = []Statement{{
+		Vulnerability: Vulnerability{Name: "CVE-2023-1234"},
+		Products:      []Product{{Component: Component{ID: "pkg:apk/wolfi/git"}}},
+		Status:        StatusAffected,
+	}}
+	err = doc.Validate()
+	var schemaErr *SchemaError
+	require.ErrorAs(t, err, &schemaErr)
+	require.Len(t, schemaErr.Errors, 1)
+	require.Equal(t, "/statements/0", schemaErr.Errors[0].Pointer)
+	require.Contains(t, schemaErr.Errors[0].Message, "action_statement")
+}
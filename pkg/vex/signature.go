@@ -0,0 +1,293 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+const (
+	// AnnotationSignature holds the base64 encoded detached signature of a
+	// statement, computed over its canonical payload.
+	AnnotationSignature = "openvex.dev/signature"
+
+	// AnnotationSignatureKeyID optionally identifies the key that signed
+	// the statement.
+	AnnotationSignatureKeyID = "openvex.dev/signature-keyid"
+)
+
+var (
+	// ErrUnsignedStatement is returned when verifying a statement without a
+	// signature.
+	ErrUnsignedStatement = errors.New("statement is not signed")
+
+	// ErrInvalidSignature is returned when a statement signature does not
+	// verify with the key.
+	ErrInvalidSignature = errors.New("statement signature is invalid")
+
+	// ErrUnknownKey is returned when there is no key to verify a statement
+	// signature.
+	ErrUnknownKey = errors.New("no key to verify the statement signature")
+)
+
+// KeyResolver returns the public key to verify the signature of a statement
+// made by the author and signed with the key ID. The key ID is empty when
+// the statement does not record it. Resolvers return a nil key when they do
+// not know the signer.
+type KeyResolver func(keyID, author string) (crypto.PublicKey, error)
+
+// StaticKeys returns a KeyResolver looking up the keys by key ID in a map.
+// Signatures without a key ID are verified with the key stored under the
+// empty ID, if any.
+func StaticKeys(keys map[string]crypto.PublicKey) KeyResolver {
+	return func(keyID, _ string) (crypto.PublicKey, error) {
+		return keys[keyID], nil
+	}
+}
+
+// StatementProvenance attributes a statement to its author and records the
+// result of verifying its signature.
+type StatementProvenance struct {
+	// Index is the position of the statement in the document.
+	Index int
+
+	// Author and AuthorRole are the statement author, inherited from the
+	// document when the statement does not set it.
+	Author     string
+	AuthorRole string
+
+	// KeyID is the ID of the key that signed the statement, if recorded.
+	KeyID string
+
+	// Signed is true if the statement has a signature.
+	Signed bool
+
+	// Err is nil when the signature verifies. Otherwise it is or wraps
+	// ErrUnsignedStatement, ErrUnknownKey or ErrInvalidSignature.
+	Err error
+}
+
+// Verified returns true if the statement signature verified.
+func (p *StatementProvenance) Verified() bool {
+	return p.Signed && p.Err == nil
+}
+
+// SignedPayload returns the canonical encoding of the statement signed by
+// SignStatement. The statement is completed with the data it inherits from
+// the document, its timestamp, author and language, so the payload does not
+// change when that data is made explicit in the statement. The signature
+// annotations are not part of the payload.
+func (vexDoc *VEX) SignedPayload(i int) ([]byte, error) {
+	if i < 0 || i >= len(vexDoc.Statements) {
+		return nil, fmt.Errorf("statement #%d not found in document", i)
+	}
+	return vexDoc.statementPayload(&vexDoc.Statements[i])
+}
+
+// statementPayload implements SignedPayload for a statement inheriting the
+// data of the document.
+func (vexDoc *VEX) statementPayload(s *Statement) ([]byte, error) {
+	stmt := s.DeepCopy()
+	if stmt.Timestamp == nil {
+		stmt.Timestamp = vexDoc.Timestamp
+	}
+	if stmt.Author == "" {
+		stmt.Author, stmt.AuthorRole = vexDoc.Author, vexDoc.AuthorRole
+	}
+	if stmt.Lang == "" {
+		stmt.Lang = vexDoc.Lang
+	}
+	delete(stmt.Annotations, AnnotationSignature)
+	delete(stmt.Annotations, AnnotationSignatureKeyID)
+	if len(stmt.Annotations) == 0 {
+		stmt.Annotations = nil
+	}
+
+	data, err := json.Marshal(stmt)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling statement: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	obj := map[string]any{}
+	if err := dec.Decode(&obj); err != nil {
+		return nil, fmt.Errorf("decoding statement: %w", err)
+	}
+	if err := normalizeDates(obj); err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	if err := writeCanonicalJSON(&b, obj); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// SignStatement signs the statement at index i of the document and stores
+// the detached signature and the optional key ID in the statement
+// annotations. The timestamp, author and language the statement inherits
+// from the document are first set in the statement, so the signature keeps
+// verifying when the statement is merged into documents with other
+// metadata in any merge mode. Ed25519, ECDSA and RSA (PKCS #1 v1.5) signers are supported,
+// ECDSA and RSA signatures are computed over the SHA-256 digest of the
+// payload.
+//
+// Signatures cover a single statement so they can be verified independently
+// of the document, for example after the statement is republished in a
+// merged document. Changing the statement, including rewriting its
+// vulnerability when unifying aliases, invalidates the signature.
+func (vexDoc *VEX) SignStatement(i int, signer crypto.Signer, keyID string) error {
+	if i < 0 || i >= len(vexDoc.Statements) {
+		return fmt.Errorf("statement #%d not found in document", i)
+	}
+	vexDoc.freezeInherited(&vexDoc.Statements[i])
+
+	payload, err := vexDoc.SignedPayload(i)
+	if err != nil {
+		return err
+	}
+
+	digest, opts, err := signatureDigest(signer.Public(), payload)
+	if err != nil {
+		return err
+	}
+	sig, err := signer.Sign(rand.Reader, digest, opts)
+	if err != nil {
+		return fmt.Errorf("signing statement #%d: %w", i, err)
+	}
+
+	stmt := &vexDoc.Statements[i]
+	if stmt.Annotations == nil {
+		stmt.Annotations = map[string]string{}
+	}
+	stmt.Annotations[AnnotationSignature] = base64.StdEncoding.EncodeToString(sig)
+	if keyID != "" {
+		stmt.Annotations[AnnotationSignatureKeyID] = keyID
+	} else {
+		delete(stmt.Annotations, AnnotationSignatureKeyID)
+	}
+	return nil
+}
+
+// freezeInherited sets the timestamp, author and language the statement
+// inherits from the document in the statement.
+func (vexDoc *VEX) freezeInherited(stmt *Statement) {
+	if stmt.Timestamp == nil && vexDoc.Timestamp != nil {
+		ts := *vexDoc.Timestamp
+		stmt.Timestamp = &ts
+	}
+	if stmt.Author == "" {
+		stmt.Author, stmt.AuthorRole = vexDoc.Author, vexDoc.AuthorRole
+	}
+	if stmt.Lang == "" {
+		stmt.Lang = vexDoc.Lang
+	}
+}
+
+// SignStatements signs all the statements of the document, see
+// SignStatement.
+func (vexDoc *VEX) SignStatements(signer crypto.Signer, keyID string) error {
+	for i := range vexDoc.Statements {
+		if err := vexDoc.SignStatement(i, signer, keyID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// VerifyStatement checks the signature of the statement at index i with
+// the public key. It returns ErrUnsignedStatement if the statement has no
+// signature and ErrInvalidSignature if it does not verify.
+func (vexDoc *VEX) VerifyStatement(i int, key crypto.PublicKey) error {
+	payload, err := vexDoc.SignedPayload(i)
+	if err != nil {
+		return err
+	}
+
+	encoded, ok := vexDoc.Statements[i].Annotations[AnnotationSignature]
+	if !ok {
+		return ErrUnsignedStatement
+	}
+	sig, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return fmt.Errorf("decoding signature: %w", err)
+	}
+
+	digest, _, err := signatureDigest(key, payload)
+	if err != nil {
+		return err
+	}
+
+	var valid bool
+	switch k := key.(type) {
+	case ed25519.PublicKey:
+		valid = ed25519.Verify(k, digest, sig)
+	case *ecdsa.PublicKey:
+		valid = ecdsa.VerifyASN1(k, digest, sig)
+	case *rsa.PublicKey:
+		valid = rsa.VerifyPKCS1v15(k, crypto.SHA256, digest, sig) == nil
+	}
+	if !valid {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// signatureDigest returns the data to sign for a key type and the signer
+// options to sign it with.
+func signatureDigest(key crypto.PublicKey, payload []byte) ([]byte, crypto.SignerOpts, error) {
+	switch key.(type) {
+	case ed25519.PublicKey:
+		return payload, crypto.Hash(0), nil
+	case *ecdsa.PublicKey, *rsa.PublicKey:
+		sum := sha256.Sum256(payload)
+		return sum[:], crypto.SHA256, nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported key type %T", key)
+	}
+}
+
+// VerifyStatements checks the signatures of all the statements in the
+// document, looking up the key of each one with the resolver. It returns the
+// provenance of every statement in document order, so assertions in
+// documents merged from several authors can be attributed to their signers
+// and those that do not verify can be told apart.
+func (vexDoc *VEX) VerifyStatements(resolver KeyResolver) []StatementProvenance {
+	ret := make([]StatementProvenance, 0, len(vexDoc.Statements))
+	for i := range vexDoc.Statements {
+		stmt := &vexDoc.Statements[i]
+		p := StatementProvenance{Index: i, KeyID: stmt.Annotations[AnnotationSignatureKeyID]}
+		p.Author, p.AuthorRole = vexDoc.StatementAuthor(stmt)
+		_, p.Signed = stmt.Annotations[AnnotationSignature]
+
+		if !p.Signed {
+			p.Err = ErrUnsignedStatement
+			ret = append(ret, p)
+			continue
+		}
+
+		key, err := resolver(p.KeyID, p.Author)
+		switch {
+		case err != nil:
+			p.Err = fmt.Errorf("resolving key %q: %w", p.KeyID, err)
+		case key == nil:
+			p.Err = ErrUnknownKey
+		default:
+			p.Err = vexDoc.VerifyStatement(i, key)
+		}
+		ret = append(ret, p)
+	}
+	return ret
+}
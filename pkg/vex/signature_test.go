@@ -0,0 +1,180 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// signatureDocument returns a document by the author with a statement about
+// the vulnerability.
+func signatureDocument(author, vuln string) *VEX {
+	ts := time.Date(2023, 1, 1, 0, 0, 0, 0, time.FixedZone("EST", -5*3600))
+	return &VEX{
+		Metadata: Metadata{
+			Context: ContextLocator(), ID: "https://example.com/" + author, Author: author, Version: 1, Timestamp: &ts,
+		},
+		Statements: []Statement{{
+			Vulnerability: Vulnerability{Name: VulnerabilityID(vuln)},
+			Products:      []Product{{Component: Component{ID: "pkg:apk/wolfi/git@2.41.0-r1"}}},
+			Status:        StatusFixed,
+		}},
+	}
+}
+
+func TestSignStatement(t *testing.T) {
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	for m, signer := range map[string]crypto.Signer{
+		"ed25519": edKey,
+		"ecdsa":   ecKey,
+		"rsa":     rsaKey,
+	} {
+		doc := signatureDocument("Third Party", "CVE-2023-1234")
+		require.ErrorIs(t, doc.VerifyStatement(0, signer.Public()), ErrUnsignedStatement, m)
+		require.NoError(t, doc.SignStatements(signer, "key-1"), m)
+		require.Equal(t, "key-1", doc.Statements[0].Annotations[AnnotationSignatureKeyID], m)
+		require.NoError(t, doc.VerifyStatement(0, signer.Public()), m)
+
+		// Tampering with the statement breaks the signature
+		doc.Statements[0].Status = StatusNotAffected
+		require.ErrorIs(t, doc.VerifyStatement(0, signer.Public()), ErrInvalidSignature, m)
+	}
+
+	// Keys must match the signature
+	doc := signatureDocument("Third Party", "CVE-2023-1234")
+	require.NoError(t, doc.SignStatement(0, edKey, ""))
+	require.NotContains(t, doc.Statements[0].Annotations, AnnotationSignatureKeyID)
+	otherPub, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	require.ErrorIs(t, doc.VerifyStatement(0, otherPub), ErrInvalidSignature)
+	require.Error(t, doc.VerifyStatement(0, "not a key"))
+	require.Error(t, doc.VerifyStatement(1, edKey.Public()))
+}
+
+func TestSignedStatementsInMergedDocuments(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	thirdParty := signatureDocument("Third Party", "CVE-2023-1234")
+	require.NoError(t, thirdParty.SignStatements(key, ""))
+	own := signatureDocument("Aggregator", "CVE-2023-5678")
+
+	merged, err := MergeDocumentsWithOptions(&MergeOptions{Author: "Aggregator", Mode: MergeModeAttributed}, []*VEX{own, thirdParty})
+	require.NoError(t, err)
+	require.NotEqual(t, thirdParty.Timestamp, merged.Timestamp)
+
+	found := false
+	for i := range merged.Statements {
+		if merged.Statements[i].Vulnerability.Name != "CVE-2023-1234" {
+			require.ErrorIs(t, merged.VerifyStatement(i, key.Public()), ErrUnsignedStatement)
+			continue
+		}
+		found = true
+		require.NoError(t, merged.VerifyStatement(i, key.Public()))
+	}
+	require.True(t, found)
+}
+
+func TestSignedStatementsInCompatMerges(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	thirdParty := signatureDocument("Third Party", "CVE-2023-1234")
+	thirdParty.Lang = "en"
+	require.NoError(t, thirdParty.SignStatements(key, "third-party"))
+	own := signatureDocument("Aggregator", "CVE-2023-5678")
+
+	// The default merge mode does not carry the document metadata over to
+	// the statements, signing made it explicit.
+	merged, err := MergeDocuments([]*VEX{own, thirdParty})
+	require.NoError(t, err)
+	require.NotEqual(t, "Third Party", merged.Author)
+
+	provenance := merged.VerifyStatements(StaticKeys(map[string]crypto.PublicKey{"third-party": key.Public()}))
+	found := false
+	for _, p := range provenance {
+		if merged.Statements[p.Index].Vulnerability.Name != "CVE-2023-1234" {
+			continue
+		}
+		found = true
+		require.True(t, p.Verified())
+		require.Equal(t, "Third Party", p.Author)
+	}
+	require.True(t, found)
+}
+
+func TestVerifyStatements(t *testing.T) {
+	_, vendorKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	_, distroKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	_, unknownKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	vendor := signatureDocument("Vendor", "CVE-2023-0001")
+	require.NoError(t, vendor.SignStatements(vendorKey, "vendor"))
+	distro := signatureDocument("Distro", "CVE-2023-0002")
+	require.NoError(t, distro.SignStatements(distroKey, "distro"))
+	tampered := signatureDocument("Distro", "CVE-2023-0003")
+	require.NoError(t, tampered.SignStatements(distroKey, "distro"))
+	tampered.Statements[0].Status = StatusAffected
+	tampered.Statements[0].ActionStatement = "Update"
+	stranger := signatureDocument("Stranger", "CVE-2023-0004")
+	require.NoError(t, stranger.SignStatements(unknownKey, "stranger"))
+	unsigned := signatureDocument("Aggregator", "CVE-2023-0005")
+
+	merged, err := MergeDocumentsWithOptions(
+		&MergeOptions{Author: "Aggregator", Mode: MergeModeAttributed}, []*VEX{vendor, distro, tampered, stranger, unsigned},
+	)
+	require.NoError(t, err)
+
+	keys := StaticKeys(map[string]crypto.PublicKey{"vendor": vendorKey.Public(), "distro": distroKey.Public()})
+	provenance := merged.VerifyStatements(keys)
+	require.Len(t, provenance, len(merged.Statements))
+
+	byVuln := map[VulnerabilityID]*StatementProvenance{}
+	for i := range provenance {
+		byVuln[merged.Statements[provenance[i].Index].Vulnerability.Name] = &provenance[i]
+	}
+
+	require.True(t, byVuln["CVE-2023-0001"].Verified())
+	require.Equal(t, "Vendor", byVuln["CVE-2023-0001"].Author)
+	require.Equal(t, "vendor", byVuln["CVE-2023-0001"].KeyID)
+	require.True(t, byVuln["CVE-2023-0002"].Verified())
+	require.Equal(t, "Distro", byVuln["CVE-2023-0002"].Author)
+
+	tamperedProvenance := byVuln["CVE-2023-0003"]
+	require.True(t, tamperedProvenance.Signed)
+	require.False(t, tamperedProvenance.Verified())
+	require.ErrorIs(t, tamperedProvenance.Err, ErrInvalidSignature)
+	strangerProvenance := byVuln["CVE-2023-0004"]
+	require.ErrorIs(t, strangerProvenance.Err, ErrUnknownKey)
+	unsignedProvenance := byVuln["CVE-2023-0005"]
+	require.False(t, unsignedProvenance.Signed)
+	require.ErrorIs(t, unsignedProvenance.Err, ErrUnsignedStatement)
+
+	// Resolver errors are reported per statement
+	failing := func(string, string) (crypto.PublicKey, error) { return nil, errors.New("keyring unavailable") }
+	provenance = merged.VerifyStatements(failing)
+	for i := range provenance {
+		require.Error(t, provenance[i].Err)
+		require.False(t, provenance[i].Verified())
+	}
+}
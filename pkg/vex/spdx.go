@@ -0,0 +1,106 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"strings"
+
+	"github.com/openvex/go-vex/pkg/spdx"
+)
+
+// spdxElement is an SPDX package indexed by NewSPDXResolver.
+type spdxElement struct {
+	iri         string
+	identifiers []string
+}
+
+// NewSPDXResolver returns an IdentifierResolver that translates between the
+// IRIs of the packages in the SPDX documents (the document namespace, # and
+// the package SPDXID) and their purls, CPEs and hashes. This lets statements
+// written against SPDX element IRIs match queries expressed as purls, and
+// statements about purls match queries with SPDX element IRIs:
+//
+//   - An element IRI resolves to the purl, CPE and hashes of the package.
+//   - A reference to an element of an external document
+//     (DocumentRef-name:SPDXRef-element) resolves to the element IRI, using
+//     the external document references of the documents, and to the
+//     package identifiers if the external document is loaded too.
+//   - A purl, CPE or hash resolves to the IRIs of the packages it
+//     identifies. Purls resolve to the packages with a purl that matches
+//     them, as in PurlMatches.
+//
+// Wrap the resolver with MemoizeResolver when matching many statements.
+func NewSPDXResolver(docs ...*spdx.Document) IdentifierResolver {
+	elements := map[string]*spdxElement{}
+	order := []*spdxElement{}
+	for _, doc := range docs {
+		if doc == nil {
+			continue
+		}
+		for i := range doc.Packages {
+			p := &doc.Packages[i]
+			iri := doc.ElementIRI(p.ID)
+			if iri == "" {
+				continue
+			}
+			e := &spdxElement{iri: iri, identifiers: []string{}}
+			for _, id := range []string{p.Purl(), p.CPE()} {
+				if id != "" {
+					e.identifiers = append(e.identifiers, id)
+				}
+			}
+			for _, c := range p.Checksums {
+				e.identifiers = append(e.identifiers, strings.ToLower(c.Value))
+			}
+			elements[iri] = e
+			order = append(order, e)
+		}
+	}
+
+	return func(identifier string) ([]string, error) {
+		if e, ok := elements[identifier]; ok {
+			return e.identifiers, nil
+		}
+
+		if strings.HasPrefix(identifier, "DocumentRef-") {
+			for _, doc := range docs {
+				if doc == nil {
+					continue
+				}
+				if iri := doc.ElementIRI(identifier); iri != "" {
+					ret := []string{iri}
+					if e, ok := elements[iri]; ok {
+						ret = append(ret, e.identifiers...)
+					}
+					return ret, nil
+				}
+			}
+			return nil, nil
+		}
+
+		ret := []string{}
+		for _, e := range order {
+			for _, id := range e.identifiers {
+				if spdxIdentifierMatches(id, identifier) {
+					ret = append(ret, e.iri)
+					break
+				}
+			}
+		}
+		return ret, nil
+	}
+}
+
+// spdxIdentifierMatches returns true if the identifier of a package matches
+// the queried identifier.
+func spdxIdentifierMatches(packageIdentifier, identifier string) bool {
+	switch {
+	case strings.HasPrefix(packageIdentifier, "pkg:"):
+		return strings.HasPrefix(identifier, "pkg:") && PurlMatches(packageIdentifier, identifier)
+	case strings.HasPrefix(packageIdentifier, "cpe:"):
+		return strings.HasPrefix(identifier, "cpe:") && CPEMatches(packageIdentifier, identifier)
+	default:
+		return packageIdentifier == strings.ToLower(identifier)
+	}
+}
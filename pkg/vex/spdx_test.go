@@ -0,0 +1,84 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/openvex/go-vex/pkg/spdx"
+)
+
+func TestSPDXResolver(t *testing.T) {
+	image, err := spdx.Parse([]byte(`{
+  "spdxVersion": "SPDX-2.3",
+  "SPDXID": "SPDXRef-DOCUMENT",
+  "documentNamespace": "https://example.com/spdx/image",
+  "externalDocumentRefs": [{
+    "externalDocumentId": "DocumentRef-git",
+    "spdxDocument": "https://example.com/spdx/git",
+    "checksum": {"algorithm": "SHA1", "checksumValue": "d6a770ba38583ed4bb4525bd96e50461655d2759"}
+  }],
+  "packages": [{
+    "SPDXID": "SPDXRef-Package-openssl",
+    "name": "openssl",
+    "checksums": [{"algorithm": "SHA256", "checksumValue": "E1A9A5C4F3F6C2A8B1D1C9EC2C5B6E8F0A9B4E2B8C3D1F2A6E7B9C0D1E2F3A4B"}],
+    "externalRefs": [{"referenceCategory": "PACKAGE-MANAGER", "referenceType": "purl", "referenceLocator": "pkg:apk/wolfi/openssl@3.1.2-r0"}]
+  }]
+}`))
+	require.NoError(t, err)
+
+	git, err := spdx.Parse([]byte(`{
+  "spdxVersion": "SPDX-2.3",
+  "SPDXID": "SPDXRef-DOCUMENT",
+  "documentNamespace": "https://example.com/spdx/git",
+  "packages": [{
+    "SPDXID": "SPDXRef-Package-git",
+    "name": "git",
+    "externalRefs": [{"referenceCategory": "PACKAGE-MANAGER", "referenceType": "purl", "referenceLocator": "pkg:apk/wolfi/git@2.41.0-r0"}]
+  }]
+}`))
+	require.NoError(t, err)
+
+	resolver := NewSPDXResolver(image, nil, git)
+	for m, tc := range map[string]struct {
+		identifier string
+		expected   []string
+	}{
+		"element iri": {
+			"https://example.com/spdx/image#SPDXRef-Package-openssl",
+			[]string{"pkg:apk/wolfi/openssl@3.1.2-r0", "e1a9a5c4f3f6c2a8b1d1c9ec2c5b6e8f0a9b4e2b8c3d1f2a6e7b9c0d1e2f3a4b"},
+		},
+		"external element": {
+			"DocumentRef-git:SPDXRef-Package-git",
+			[]string{"https://example.com/spdx/git#SPDXRef-Package-git", "pkg:apk/wolfi/git@2.41.0-r0"},
+		},
+		"purl":            {"pkg:apk/wolfi/git@2.41.0-r0", []string{"https://example.com/spdx/git#SPDXRef-Package-git"}},
+		"qualified purl":  {"pkg:apk/wolfi/git@2.41.0-r0?arch=x86_64", []string{"https://example.com/spdx/git#SPDXRef-Package-git"}},
+		"generic purl":    {"pkg:apk/wolfi/git", []string{}},
+		"hash":            {"E1A9A5C4F3F6C2A8B1D1C9EC2C5B6E8F0A9B4E2B8C3D1F2A6E7B9C0D1E2F3A4B", []string{"https://example.com/spdx/image#SPDXRef-Package-openssl"}},
+		"unknown element": {"DocumentRef-curl:SPDXRef-Package-curl", nil},
+	} {
+		ids, err := resolver(tc.identifier)
+		require.NoError(t, err, m)
+		require.Equal(t, tc.expected, ids, m)
+	}
+
+	// Statements about element IRIs match purls and vice versa
+	stmt := Statement{
+		Vulnerability: Vulnerability{Name: "CVE-2023-0001"},
+		Products: []Product{
+			{Component: Component{ID: "https://example.com/spdx/image#SPDXRef-Package-openssl"}},
+			{Component: Component{ID: "pkg:apk/wolfi/git"}},
+		},
+		Status:        StatusNotAffected,
+		Justification: ComponentNotPresent,
+	}
+	require.False(t, stmt.Matches("CVE-2023-0001", "pkg:apk/wolfi/openssl@3.1.2-r0", nil))
+	require.True(t, stmt.MatchesWithResolver("CVE-2023-0001", "pkg:apk/wolfi/openssl@3.1.2-r0", nil, resolver))
+	require.True(t, stmt.MatchesWithResolver("CVE-2023-0001", "https://example.com/spdx/git#SPDXRef-Package-git", nil, resolver))
+	require.True(t, stmt.MatchesWithResolver("CVE-2023-0001", "DocumentRef-git:SPDXRef-Package-git", nil, resolver))
+	require.False(t, stmt.MatchesWithResolver("CVE-2023-0001", "pkg:apk/wolfi/curl@8.1.0", nil, resolver))
+}
@@ -0,0 +1,112 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+)
+
+// StandaloneOptions control how a statement is wrapped into a standalone
+// document.
+type StandaloneOptions struct {
+	// Product is the identifier that matched the statement. When set, only
+	// the statement products matching it are kept.
+	Product string
+
+	// Identifiers are resolved identifiers of the product that are added
+	// to the kept products.
+	Identifiers map[IdentifierType]string
+
+	// Hashes are resolved hashes of the product that are added to the kept
+	// products.
+	Hashes map[Algorithm]Hash
+}
+
+// Standalone wraps a statement of the document into a minimal, self-contained
+// OpenVEX document that can be forwarded to downstream consumers without the
+// rest of the source document. The new document carries the authorship data
+// of the source and the statement inherits its timestamp and language if it
+// does not define its own. The statement is copied, the source document is
+// not modified.
+func (vexDoc *VEX) Standalone(stmt *Statement, opts *StandaloneOptions) (*VEX, error) {
+	if stmt == nil {
+		return nil, errors.New("statement is nil")
+	}
+	if opts == nil {
+		opts = &StandaloneOptions{}
+	}
+
+	s := stmt.DeepCopy()
+	if s.Timestamp == nil {
+		if vexDoc.Timestamp == nil {
+			return nil, errors.New("unable to cascade timestamp from doc to timeless statement")
+		}
+		ts := *vexDoc.Timestamp
+		s.Timestamp = &ts
+	}
+	if s.Lang == "" {
+		s.Lang = vexDoc.Lang
+	}
+
+	products := []Product{}
+	for i := range s.Products {
+		if opts.Product != "" && !s.Products[i].identityMatches(opts.Product, &MatchOptions{}) {
+			continue
+		}
+		products = append(products, standaloneProduct(&s.Products[i], opts))
+	}
+	if len(products) == 0 {
+		return nil, fmt.Errorf("statement has no products matching %q", opts.Product)
+	}
+	s.Products = products
+
+	doc := New()
+	doc.Author, doc.AuthorRole = vexDoc.StatementAuthor(stmt)
+	doc.Supplier = vexDoc.Supplier
+	doc.Tooling = vexDoc.Tooling
+	doc.Lang = s.Lang
+	ts := *s.Timestamp
+	doc.Timestamp = &ts
+	doc.Statements = []Statement{*s}
+
+	if _, err := doc.GenerateCanonicalID(); err != nil {
+		return nil, fmt.Errorf("generating document ID: %w", err)
+	}
+	return &doc, nil
+}
+
+// standaloneProduct copies the product adding the resolved identifiers and
+// hashes in the options.
+func standaloneProduct(p *Product, opts *StandaloneOptions) Product {
+	ret := *p
+	ret.Identifiers = maps.Clone(p.Identifiers)
+	ret.Hashes = maps.Clone(p.Hashes)
+	ret.Subcomponents = append([]Subcomponent(nil), p.Subcomponents...)
+	ret.Artifacts = append([]Component(nil), p.Artifacts...)
+
+	if len(opts.Identifiers) > 0 {
+		if ret.Identifiers == nil {
+			ret.Identifiers = map[IdentifierType]string{}
+		}
+		for t, id := range opts.Identifiers {
+			if _, ok := ret.Identifiers[t]; !ok {
+				ret.Identifiers[t] = id
+			}
+		}
+	}
+
+	if len(opts.Hashes) > 0 {
+		if ret.Hashes == nil {
+			ret.Hashes = map[Algorithm]Hash{}
+		}
+		for a, h := range opts.Hashes {
+			if _, ok := ret.Hashes[a]; !ok {
+				ret.Hashes[a] = h
+			}
+		}
+	}
+	return ret
+}
@@ -0,0 +1,72 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStandalone(t *testing.T) {
+	ts := time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)
+	doc := New()
+	doc.ID = "https://example.com/vex/source"
+	doc.Author = "Example PSIRT"
+	doc.Lang = "en"
+	doc.Timestamp = &ts
+	doc.Statements = []Statement{
+		{
+			Vulnerability: Vulnerability{Name: "CVE-2023-1234"},
+			Products: []Product{
+				{Component: Component{ID: "pkg:oci/app@sha256:abc"}},
+				{Component: Component{ID: "pkg:oci/other@sha256:def"}},
+			},
+			Status:        StatusNotAffected,
+			Justification: ComponentNotPresent,
+		},
+	}
+
+	matches := doc.Matches("CVE-2023-1234", "pkg:oci/app@sha256:abc", nil)
+	require.Len(t, matches, 1)
+
+	standalone, err := doc.Standalone(&matches[0], &StandaloneOptions{
+		Product:     "pkg:oci/app@sha256:abc",
+		Identifiers: map[IdentifierType]string{PURL: "pkg:oci/app@sha256:abc?repository_url=example.com/app"},
+		Hashes:      map[Algorithm]Hash{SHA256: "2d711642b726b04401627ca9fbac32f5c8530fb1903cc4db02258717921a4881"},
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, standalone.ID)
+	require.NotEqual(t, doc.ID, standalone.ID)
+	require.Equal(t, "Example PSIRT", standalone.Author)
+	require.Equal(t, "en", standalone.Lang)
+	require.Len(t, standalone.Statements, 1)
+
+	stmt := standalone.Statements[0]
+	require.Equal(t, ts, *stmt.Timestamp)
+	require.Equal(t, "en", stmt.Lang)
+	require.Len(t, stmt.Products, 1)
+	require.Equal(t, "pkg:oci/app@sha256:abc", stmt.Products[0].ID)
+	require.Equal(t, Hash("2d711642b726b04401627ca9fbac32f5c8530fb1903cc4db02258717921a4881"), stmt.Products[0].Hashes[SHA256])
+	require.NoError(t, stmt.Validate())
+
+	// The source document is not modified
+	require.Len(t, doc.Statements[0].Products, 2)
+	require.Nil(t, doc.Statements[0].Products[0].Hashes)
+	require.Nil(t, doc.Statements[0].Timestamp)
+
+	// The standalone document round trips
+	var buf bytes.Buffer
+	require.NoError(t, standalone.ToJSON(&buf))
+	parsed, err := Parse(buf.Bytes())
+	require.NoError(t, err)
+	require.True(t, parsed.Statements[0].Matches("CVE-2023-1234", "pkg:oci/app@sha256:abc", nil))
+
+	_, err = doc.Standalone(&matches[0], &StandaloneOptions{Product: "pkg:oci/missing"})
+	require.Error(t, err)
+	_, err = doc.Standalone(nil, nil)
+	require.Error(t, err)
+}
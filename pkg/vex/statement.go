@@ -5,6 +5,7 @@ package vex
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"sort"
 	"strings"
@@ -61,6 +62,42 @@ type Statement struct {
 	// SHOULD describe actions to remediate or mitigate [vul_id].
 	ActionStatement          string     `json:"action_statement,omitempty"`
 	ActionStatementTimestamp *time.Time `json:"action_statement_timestamp,omitempty"`
+
+	// The fields below extend the OpenVEX spec. They are serialized under
+	// openvex.dev/ prefixed properties so they can't clash with properties
+	// added in future revisions of the spec.
+
+	// Remediations optionally structure the actions of affected statements,
+	// such as vendor fixes or workarounds with their URLs and dates. They
+	// are not part of the OpenVEX spec and complement the ActionStatement.
+	Remediations []Remediation `json:"openvex.dev/remediations,omitempty"`
+
+	// Annotations are optional key/value pairs to attach arbitrary data to
+	// the statement, for example to mark it as internal. They are not part
+	// of the OpenVEX spec. Public views of a document (see VEX.PublicView)
+	// drop the internal annotations and the signatures of the statements
+	// they change, and keep the rest.
+	Annotations map[string]string `json:"openvex.dev/annotations,omitempty"`
+
+	// References are optional links to resources with more information
+	// about the statement, such as the advisories it was converted from.
+	References []Reference `json:"openvex.dev/references,omitempty"`
+
+	// Credits optionally preserve the attribution of the people and
+	// organizations involved in handling the vulnerability.
+	Credits []Credit `json:"openvex.dev/credits,omitempty"`
+
+	// Lang is an optional BCP 47 language tag of the statement texts. When
+	// empty, the statement inherits the language of its document.
+	Lang string `json:"openvex.dev/lang,omitempty"`
+
+	// Author optionally identifies the author of the statement when it is
+	// not the document author, eg in documents aggregating statements from
+	// several sources. When empty, the document author made the statement.
+	Author string `json:"openvex.dev/author,omitempty"`
+
+	// AuthorRole describes the role of the statement Author.
+	AuthorRole string `json:"openvex.dev/role,omitempty"`
 }
 
 // Validate checks to see whether the given Statement is valid. If it's not, an
@@ -133,6 +170,29 @@ func (stmt *Statement) Validate() error {
 		}
 	}
 
+	if err := stmt.validateValidity(); err != nil {
+		return err
+	}
+
+	if len(stmt.Remediations) > 0 && stmt.Status != StatusAffected {
+		return fmt.Errorf("remediations should not be set when using status %q", stmt.Status)
+	}
+	for i := range stmt.Remediations {
+		if err := stmt.Remediations[i].Validate(); err != nil {
+			return fmt.Errorf("invalid remediation: %w", err)
+		}
+	}
+
+	if stmt.AuthorRole != "" && stmt.Author == "" {
+		return errors.New("statement author role is set but the statement has no author")
+	}
+
+	for i := range stmt.Products {
+		if err := stmt.Products[i].Validate(); err != nil {
+			return fmt.Errorf("invalid product: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -141,58 +201,47 @@ func (stmt *Statement) Validate() error {
 // The documentTimestamp parameter is needed because statements without timestamps inherit the timestamp of the document.
 func SortStatements(stmts []Statement, documentTimestamp time.Time) {
 	sort.SliceStable(stmts, func(i, j int) bool {
-		// TODO: Add methods for aliases
-		vulnComparison := strings.Compare(string(stmts[i].Vulnerability.Name), string(stmts[j].Vulnerability.Name))
-		if vulnComparison != 0 {
-			// i.e. different vulnerabilities; sort by string comparison
-			return vulnComparison < 0
-		}
+		return statementLess(&stmts[i], &stmts[j], documentTimestamp)
+	})
+}
 
-		// i.e. the same vulnerability; sort statements by timestamp
+// statementLess reports whether statement a sorts before b in the VEX
+// history. See SortStatements.
+func statementLess(a, b *Statement, documentTimestamp time.Time) bool {
+	// TODO: Add methods for aliases
+	vulnComparison := strings.Compare(string(a.Vulnerability.Name), string(b.Vulnerability.Name))
+	if vulnComparison != 0 {
+		// i.e. different vulnerabilities; sort by string comparison
+		return vulnComparison < 0
+	}
 
-		iTime := stmts[i].Timestamp
-		if iTime == nil || iTime.IsZero() {
-			iTime = &documentTimestamp
-		}
+	// i.e. the same vulnerability; sort statements by timestamp
 
-		jTime := stmts[j].Timestamp
-		if jTime == nil || jTime.IsZero() {
-			jTime = &documentTimestamp
-		}
+	iTime := a.Timestamp
+	if iTime == nil || iTime.IsZero() {
+		iTime = &documentTimestamp
+	}
 
-		if iTime == nil {
-			return false
-		}
+	jTime := b.Timestamp
+	if jTime == nil || jTime.IsZero() {
+		jTime = &documentTimestamp
+	}
 
-		if jTime == nil {
-			return true
-		}
+	if iTime == nil {
+		return false
+	}
 
-		return iTime.Before(*jTime)
-	})
+	if jTime == nil {
+		return true
+	}
+
+	return iTime.Before(*jTime)
 }
 
 // Matches returns true if the statement matches the specified vulnerability
 // identifier, the VEX product and any of the identifiers from the received list.
 func (stmt *Statement) Matches(vuln, product string, subcomponents []string) bool {
-	if !stmt.Vulnerability.Matches(vuln) {
-		return false
-	}
-
-	for i := range stmt.Products {
-		if len(subcomponents) == 0 {
-			if stmt.Products[i].Matches(product, "") {
-				return true
-			}
-		}
-
-		for _, sc := range subcomponents {
-			if stmt.Products[i].Matches(product, sc) {
-				return true
-			}
-		}
-	}
-	return false
+	return stmt.MatchesWithResolver(vuln, product, subcomponents, nil)
 }
 
 // MatchesProduct returns true if the statement matches the identifier string
@@ -206,6 +255,31 @@ func (stmt *Statement) MatchesProduct(identifier, subidentifier string) bool {
 	return false
 }
 
+// String returns a one-line, human readable summary of the statement, for
+// example: "CVE-2023-1255 not_affected for pkg:apk/alpine/libssl3@3.0.8-r3:
+// vulnerable_code_not_present".
+func (stmt *Statement) String() string {
+	products := []string{}
+	for i := range stmt.Products {
+		products = append(products, stmt.Products[i].displayName())
+	}
+
+	vuln := string(stmt.Vulnerability.Name)
+	if vuln == "" {
+		vuln = stmt.Vulnerability.ID
+	}
+
+	s := fmt.Sprintf("%s %s", vuln, stmt.Status)
+	if len(products) > 0 {
+		s += fmt.Sprintf(" for %s", strings.Join(products, ", "))
+	}
+
+	if stmt.Justification != "" {
+		s += fmt.Sprintf(": %s", stmt.Justification)
+	}
+	return s
+}
+
 // MarshalJSON the document object overrides its marshaling function to normalize
 // the timezones in all dates to Zulu.
 func (stmt *Statement) MarshalJSON() ([]byte, error) {
@@ -221,7 +295,7 @@ func (stmt *Statement) MarshalJSON() ([]byte, error) {
 
 	return json.Marshal(&struct {
 		*alias
-		TimeZonedTimestamp   string `json:"timestamp"`
+		TimeZonedTimestamp   string `json:"timestamp,omitempty"`
 		TimeZonedLastUpdated string `json:"last_updated,omitempty"`
 	}{
 		alias:                (*alias)(stmt),
@@ -230,42 +304,58 @@ func (stmt *Statement) MarshalJSON() ([]byte, error) {
 	})
 }
 
-// DeepCopyInto copies the receiver and writes its value into out.
+// DeepCopyInto copies the receiver and writes its value into out. The
+// copy shares no memory with the receiver.
 func (stmt *Statement) DeepCopyInto(out *Statement) {
 	*out = *stmt
+	out.Timestamp = copyTime(stmt.Timestamp)
+	out.LastUpdated = copyTime(stmt.LastUpdated)
+	out.ActionStatementTimestamp = copyTime(stmt.ActionStatementTimestamp)
 
-	if stmt.Timestamp != nil {
-		*out = *stmt
-		out.Timestamp = new(time.Time)
-		*out.Timestamp = *stmt.Timestamp
+	out.Vulnerability = Vulnerability{}
+	stmt.Vulnerability.DeepCopyInto(&out.Vulnerability)
+
+	if stmt.Products != nil {
+		out.Products = make([]Product, len(stmt.Products))
+		for i := range stmt.Products {
+			stmt.Products[i].DeepCopyInto(&out.Products[i])
+		}
 	}
 
-	if stmt.LastUpdated != nil {
-		*out = *stmt
-		out.LastUpdated = new(time.Time)
-		*out.LastUpdated = *stmt.LastUpdated
+	if stmt.Remediations != nil {
+		out.Remediations = make([]Remediation, len(stmt.Remediations))
+		for i := range stmt.Remediations {
+			stmt.Remediations[i].DeepCopyInto(&out.Remediations[i])
+		}
 	}
 
-	if stmt.Products != nil {
-		*out = *stmt
-		out.Products = make([]Product, len(stmt.Products))
-		copy(out.Products, stmt.Products)
+	if stmt.Annotations != nil {
+		out.Annotations = make(map[string]string, len(stmt.Annotations))
+		for k, v := range stmt.Annotations {
+			out.Annotations[k] = v
+		}
 	}
 
-	*out = *stmt
-	out.Vulnerability = Vulnerability{}
-	stmt.Vulnerability.DeepCopyInto(&out.Vulnerability)
+	if stmt.References != nil {
+		out.References = make([]Reference, len(stmt.References))
+		copy(out.References, stmt.References)
+	}
 
-	if stmt.Justification != "" {
-		*out = *stmt
-		out.Justification = stmt.Justification
+	if stmt.Credits != nil {
+		out.Credits = make([]Credit, len(stmt.Credits))
+		for i := range stmt.Credits {
+			stmt.Credits[i].DeepCopyInto(&out.Credits[i])
+		}
 	}
+}
 
-	if stmt.ActionStatementTimestamp != nil {
-		*out = *stmt
-		out.ActionStatementTimestamp = new(time.Time)
-		*out.ActionStatementTimestamp = *stmt.ActionStatementTimestamp
+// copyTime returns a copy of a time pointer.
+func copyTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
 	}
+	ret := *t
+	return &ret
 }
 
 // DeepCopy copies the receiver and returns a new Statement.
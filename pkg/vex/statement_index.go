@@ -0,0 +1,181 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/package-url/packageurl-go"
+)
+
+// StatementIndex speeds up matching many products against a document. It
+// indexes the statements by vulnerability identifier and normalized product
+// identifier so each query only evaluates the statements that can apply to
+// it, instead of scanning the whole document like VEX.Matches.
+//
+// The index holds a copy of the statement list of the document. Changes to
+// the document after building the index are not reflected in it.
+type StatementIndex struct {
+	statements []Statement
+	timestamp  time.Time
+	index      *productIndex
+}
+
+// BuildIndex indexes the statements of the document.
+func (vexDoc *VEX) BuildIndex() *StatementIndex {
+	si := &StatementIndex{
+		statements: slices.Clone(vexDoc.Statements),
+		index:      newProductIndex(),
+	}
+	if vexDoc.Timestamp != nil {
+		si.timestamp = *vexDoc.Timestamp
+	}
+	for i := range si.statements {
+		si.index.add(i, &si.statements[i])
+	}
+	return si
+}
+
+// Matches returns the statements that apply to the vulnerability and the
+// product. It returns the same statements, in the same order, as
+// VEX.Matches with no subcomponents.
+func (si *StatementIndex) Matches(vulnID, product string) []Statement {
+	candidates := si.index.candidates(vulnID, product)
+
+	// Collect the matches in reverse document order before sorting them,
+	// like VEX.Matches does, so that ties are resolved the same way.
+	matches := []Statement{}
+	for i := len(candidates) - 1; i >= 0; i-- {
+		if stmt := &si.statements[candidates[i]]; stmt.Matches(vulnID, product, nil) {
+			matches = append(matches, *stmt)
+		}
+	}
+
+	SortStatements(matches, si.timestamp)
+	return matches
+}
+
+// productIndex maps the vulnerabilities and products of statements to their
+// positions in a list.
+type productIndex struct {
+	// entries indexes the statements by vulnerability and product key.
+	entries map[indexKey][]int
+
+	// unindexed lists, by vulnerability, the statements about products
+	// identified by CPEs or other identifiers with a matching function,
+	// which have to be evaluated on every query.
+	unindexed map[VulnerabilityID][]int
+}
+
+// indexKey is the index key of a statement: a local vulnerability
+// identifier and a product identifier, hash or purl package name.
+type indexKey struct {
+	vuln    VulnerabilityID
+	product string
+}
+
+func newProductIndex() *productIndex {
+	return &productIndex{
+		entries:   map[indexKey][]int{},
+		unindexed: map[VulnerabilityID][]int{},
+	}
+}
+
+// add indexes the statement at position n under each of its vulnerability
+// identifiers.
+func (idx *productIndex) add(n int, stmt *Statement) {
+	products, wildcard := statementProductKeys(stmt)
+	for _, vuln := range vulnerabilityKeys(&stmt.Vulnerability) {
+		if wildcard {
+			idx.unindexed[vuln] = append(idx.unindexed[vuln], n)
+		}
+		for _, p := range products {
+			key := indexKey{vuln: vuln, product: p}
+			idx.entries[key] = append(idx.entries[key], n)
+		}
+	}
+}
+
+// candidates returns the sorted positions of the statements that may apply
+// to the vulnerability and product. The statements still need to be
+// matched to the query.
+func (idx *productIndex) candidates(vulnID, product string) []int {
+	vuln := VulnerabilityID(vulnID).Local()
+	ret := slices.Clone(idx.unindexed[vuln])
+	for _, p := range productKeys(product) {
+		ret = append(ret, idx.entries[indexKey{vuln: vuln, product: p}]...)
+	}
+	slices.Sort(ret)
+	return slices.Compact(ret)
+}
+
+// vulnerabilityKeys returns the local identifiers of the vulnerability.
+func vulnerabilityKeys(v *Vulnerability) []VulnerabilityID {
+	ret := []VulnerabilityID{}
+	seen := map[VulnerabilityID]struct{}{}
+	for _, id := range append([]VulnerabilityID{VulnerabilityID(v.ID), v.Name}, v.Aliases...) {
+		if id == "" {
+			continue
+		}
+		local := id.Local()
+		if _, ok := seen[local]; ok {
+			continue
+		}
+		seen[local] = struct{}{}
+		ret = append(ret, local)
+	}
+	return ret
+}
+
+// statementProductKeys returns the index keys of the products in the
+// statement. wildcard is true when a product has identifiers that can match
+// strings without a common key, such as CPEs or the WildcardProduct, and must
+// be evaluated on every query.
+func statementProductKeys(stmt *Statement) (keys []string, wildcard bool) {
+	keys = []string{}
+	seen := map[string]struct{}{}
+	add := func(ids ...string) {
+		for _, id := range ids {
+			if _, ok := seen[id]; ok || id == "" {
+				continue
+			}
+			seen[id] = struct{}{}
+			keys = append(keys, id)
+		}
+	}
+
+	for i := range stmt.Products {
+		for _, c := range stmt.Products[i].components() {
+			if c.ID != "" {
+				add(productKeys(c.ID)...)
+				wildcard = wildcard || strings.HasPrefix(c.ID, "cpe:") || c.ID == WildcardProduct
+			}
+			for t, id := range c.Identifiers {
+				add(productKeys(id)...)
+				wildcard = wildcard || (t != PURL && t.hasMatcher())
+			}
+			for _, h := range c.Hashes {
+				add(string(h))
+			}
+		}
+	}
+	return keys, wildcard
+}
+
+// productKeys returns the keys a product identifier is indexed with: the
+// identifier itself and, for purls, the package name without version and
+// qualifiers, as generic purls match the more specific ones.
+func productKeys(identifier string) []string {
+	ret := []string{identifier}
+	if !strings.HasPrefix(identifier, "pkg:") {
+		return ret
+	}
+	p, err := packageurl.FromString(identifier)
+	if err != nil {
+		return ret
+	}
+	return append(ret, packageurl.NewPackageURL(p.Type, p.Namespace, p.Name, "", nil, "").ToString())
+}
@@ -0,0 +1,113 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatementIndexMatches(t *testing.T) {
+	now := time.Now()
+	later := now.Add(time.Hour)
+
+	doc := New()
+	doc.Timestamp = &now
+	doc.Statements = []Statement{
+		{
+			ID:            "generic",
+			Vulnerability: Vulnerability{Name: "CVE-2023-0001", Aliases: []VulnerabilityID{"GHSA-xxxx-yyyy-zzzz"}},
+			Products:      []Product{{Component: Component{ID: "pkg:apk/wolfi/git"}}},
+			Status:        StatusUnderInvestigation,
+		},
+		{
+			ID:            "versioned",
+			Vulnerability: Vulnerability{Name: "CVE-2023-0001"},
+			Products: []Product{{
+				Component:     Component{ID: "pkg:oci/wolfi-base"},
+				Subcomponents: []Subcomponent{{Component: Component{ID: "pkg:apk/wolfi/git@2.41.0-r0"}}},
+			}, {
+				Component: Component{ID: "pkg:apk/wolfi/git@2.41.0-r0"},
+			}},
+			Status:        StatusNotAffected,
+			Justification: VulnerableCodeNotPresent,
+			Timestamp:     &later,
+		},
+		{
+			ID:            "same-time",
+			Vulnerability: Vulnerability{Name: "CVE-2023-0001"},
+			Products:      []Product{{Component: Component{ID: "pkg:apk/wolfi/git@2.41.0-r0"}}},
+			Status:        StatusAffected,
+			Timestamp:     &later,
+		},
+		{
+			ID:            "cpe",
+			Vulnerability: Vulnerability{ID: "https://nvd.nist.gov/vuln/detail/CVE-2023-0002"},
+			Products: []Product{{Component: Component{
+				Identifiers: map[IdentifierType]string{CPE23: "cpe:2.3:a:openssl:openssl:3.1.*:*:*:*:*:*:*:*"},
+			}}},
+			Status: StatusFixed,
+		},
+		{
+			ID:            "hash",
+			Vulnerability: Vulnerability{Name: "CVE-2023-0003"},
+			Products: []Product{{Component: Component{
+				Hashes: map[Algorithm]Hash{SHA256: "e1a9a5c4f3f6c2a8b1d1c9ec2c5b6e8f0a9b4e2b8c3d1f2a6e7b9c0d1e2f3a4b"},
+			}}},
+			Status: StatusUnderInvestigation,
+		},
+		{
+			ID:            "wildcard",
+			Vulnerability: Vulnerability{Name: "CVE-2023-0004"},
+			Products:      []Product{{Component: Component{ID: WildcardProduct}}},
+			Status:        StatusNotAffected,
+			Justification: ComponentNotPresent,
+		},
+	}
+
+	index := doc.BuildIndex()
+	for _, vuln := range []string{
+		"CVE-2023-0001", "GHSA-xxxx-yyyy-zzzz", "CVE-2023-0002",
+		"https://nvd.nist.gov/vuln/detail/CVE-2023-0002", "CVE-2023-0003", "CVE-2023-0004", "CVE-2023-9999",
+	} {
+		for _, product := range []string{
+			"pkg:apk/wolfi/git@2.41.0-r0", "pkg:apk/wolfi/git@2.42.0-r0", "pkg:apk/wolfi/git",
+			"pkg:oci/wolfi-base", "pkg:apk/wolfi/curl@8.1.0",
+			"cpe:2.3:a:openssl:openssl:3.1.2:*:*:*:*:*:*:*", "cpe:2.3:a:openssl:openssl:3.0.2:*:*:*:*:*:*:*",
+			"e1a9a5c4f3f6c2a8b1d1c9ec2c5b6e8f0a9b4e2b8c3d1f2a6e7b9c0d1e2f3a4b",
+		} {
+			require.Equal(t, doc.Matches(vuln, product, nil), index.Matches(vuln, product), fmt.Sprintf("%s %s", vuln, product))
+		}
+	}
+
+	// Spot check the results, not only their consistency
+	matches := index.Matches("CVE-2023-0001", "pkg:apk/wolfi/git@2.41.0-r0")
+	require.Len(t, matches, 3)
+	require.Equal(t, "generic", matches[0].ID)
+	require.Len(t, index.Matches("GHSA-xxxx-yyyy-zzzz", "pkg:apk/wolfi/git@2.42.0-r0"), 1)
+	require.Len(t, index.Matches("CVE-2023-0002", "cpe:2.3:a:openssl:openssl:3.1.2:*:*:*:*:*:*:*"), 1)
+	require.Empty(t, index.Matches("CVE-2023-0002", "cpe:2.3:a:openssl:openssl:3.0.2:*:*:*:*:*:*:*"))
+	require.Empty(t, index.Matches("CVE-2023-0004", "pkg:apk/wolfi/curl@8.1.0"))
+
+	// The index is not affected by changes to the document
+	doc.Statements = doc.Statements[:1]
+	require.Len(t, index.Matches("CVE-2023-0001", "pkg:apk/wolfi/git@2.41.0-r0"), 3)
+}
+
+func TestStatementIndexDocument(t *testing.T) {
+	doc, err := Load("testdata/v020-1.vex.json")
+	require.NoError(t, err)
+
+	index := doc.BuildIndex()
+	for i := range doc.Statements {
+		vuln := string(doc.Statements[i].Vulnerability.Name)
+		for j := range doc.Statements[i].Products {
+			product := doc.Statements[i].Products[j].ID
+			require.Equal(t, doc.Matches(vuln, product, nil), index.Matches(vuln, product))
+		}
+	}
+}
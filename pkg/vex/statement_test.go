@@ -0,0 +1,167 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatementString(t *testing.T) {
+	for testCase, tc := range map[string]struct {
+		sut      *Statement
+		expected string
+	}{
+		"justification": {
+			sut: &Statement{
+				Vulnerability: Vulnerability{Name: "CVE-2023-1255"},
+				Products: []Product{
+					{Component: Component{ID: "pkg:oci/alpine@sha256%3A124c7d2707904eea7431fffe91522a01e5a861a624ee31d03372cc1d138a3126"}},
+				},
+				Status:        StatusNotAffected,
+				Justification: VulnerableCodeNotPresent,
+			},
+			expected: "CVE-2023-1255 not_affected for pkg:oci/alpine@sha256%3A124c7d2707904eea7431fffe91522a01e5a861a624ee31d03372cc1d138a3126: vulnerable_code_not_present",
+		},
+		"multiple products": {
+			sut: &Statement{
+				Vulnerability: Vulnerability{Name: "CVE-2023-1255"},
+				Products: []Product{
+					{Component: Component{ID: "pkg:apk/wolfi/git@2.39.0-r1"}},
+					{Component: Component{Identifiers: map[IdentifierType]string{PURL: "pkg:apk/wolfi/bash@5.2"}}},
+					{Component: Component{Hashes: map[Algorithm]Hash{SHA256: "abc123"}}},
+				},
+				Status: StatusFixed,
+			},
+			expected: "CVE-2023-1255 fixed for pkg:apk/wolfi/git@2.39.0-r1, pkg:apk/wolfi/bash@5.2, sha-256:abc123",
+		},
+		"no products": {
+			sut: &Statement{
+				Vulnerability: Vulnerability{ID: "https://nvd.nist.gov/vuln/detail/CVE-2023-1255"},
+				Status:        StatusUnderInvestigation,
+			},
+			expected: "https://nvd.nist.gov/vuln/detail/CVE-2023-1255 under_investigation",
+		},
+	} {
+		require.Equal(t, tc.expected, tc.sut.String(), testCase)
+	}
+}
+
+func TestValidateStatementAuthor(t *testing.T) {
+	stmt := Statement{
+		Vulnerability: Vulnerability{Name: "CVE-2023-1255"},
+		Products:      []Product{{Component: Component{ID: "pkg:apk/wolfi/git@2.39.0-r1"}}},
+		Status:        StatusFixed,
+		AuthorRole:    "Maintainer",
+	}
+	require.Error(t, stmt.Validate())
+
+	stmt.Author = "Upstream Project"
+	require.NoError(t, stmt.Validate())
+}
+
+func TestValidateStatementRemediations(t *testing.T) {
+	stmt := Statement{
+		Vulnerability:   Vulnerability{Name: "CVE-2023-1255"},
+		Products:        []Product{{Component: Component{ID: "pkg:apk/wolfi/git@2.39.0-r1"}}},
+		Status:          StatusAffected,
+		ActionStatement: "Update to 2.39.1",
+		Remediations:    []Remediation{{Category: RemediationVendorFix, Details: "Update to 2.39.1"}},
+	}
+	require.NoError(t, stmt.Validate())
+
+	stmt.Remediations[0].Category = "patch"
+	require.Error(t, stmt.Validate())
+
+	stmt.Remediations[0].Category = RemediationVendorFix
+	stmt.Status = StatusFixed
+	stmt.ActionStatement = ""
+	require.Error(t, stmt.Validate())
+
+	// Copies do not share the remediations
+	ts := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+	stmt.Remediations[0].DueDate = &ts
+	cp := stmt.DeepCopy()
+	cp.Remediations[0].Details = "changed"
+	*cp.Remediations[0].DueDate = ts.Add(time.Hour)
+	require.Equal(t, "Update to 2.39.1", stmt.Remediations[0].Details)
+	require.Equal(t, ts, *stmt.Remediations[0].DueDate)
+}
+
+func TestStatementDeepCopy(t *testing.T) {
+	ts := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+	stmt := Statement{
+		Vulnerability: Vulnerability{Name: "CVE-2023-1255", Aliases: []VulnerabilityID{"GHSA-xxxx-xxxx-xxxx"}},
+		Timestamp:     &ts,
+		Products: []Product{{
+			Component: Component{
+				ID:          "pkg:oci/git",
+				Hashes:      map[Algorithm]Hash{SHA256: "abc"},
+				Identifiers: map[IdentifierType]string{PURL: "pkg:oci/git"},
+			},
+			Subcomponents: []Subcomponent{{Component: Component{ID: "pkg:apk/wolfi/git@2.39.0-r1"}}},
+		}},
+		Status: StatusUnderInvestigation,
+	}
+
+	cp := stmt.DeepCopy()
+	require.Equal(t, stmt, *cp)
+
+	*cp.Timestamp = ts.Add(time.Hour)
+	cp.Vulnerability.Aliases[0] = "changed"
+	cp.Products[0].ID = "changed"
+	cp.Products[0].Hashes[SHA256] = "changed"
+	cp.Products[0].Identifiers[PURL] = "changed"
+	cp.Products[0].Subcomponents[0].ID = "changed"
+
+	require.Equal(t, ts, *stmt.Timestamp)
+	require.Equal(t, VulnerabilityID("GHSA-xxxx-xxxx-xxxx"), stmt.Vulnerability.Aliases[0])
+	require.Equal(t, "pkg:oci/git", stmt.Products[0].ID)
+	require.Equal(t, Hash("abc"), stmt.Products[0].Hashes[SHA256])
+	require.Equal(t, "pkg:oci/git", stmt.Products[0].Identifiers[PURL])
+	require.Equal(t, "pkg:apk/wolfi/git@2.39.0-r1", stmt.Products[0].Subcomponents[0].ID)
+}
+
+func TestStatementExtensionsJSON(t *testing.T) {
+	stmt := Statement{
+		Vulnerability:   Vulnerability{Name: "CVE-2023-1234", Ratings: []Rating{{Method: "CVSSv31", Score: 9.8}}},
+		Products:        []Product{{Component: Component{ID: "pkg:apk/wolfi/git@2.41.0"}}},
+		Status:          StatusAffected,
+		ActionStatement: "Update to 2.42.0",
+		Remediations:    []Remediation{{Category: RemediationVendorFix, Details: "Update"}},
+		Annotations:     map[string]string{"example.com/ticket": "SEC-1234"},
+		References:      []Reference{{URL: "https://example.com/advisory"}},
+		Credits:         []Credit{{Organization: "Wolfi"}},
+		Lang:            "en",
+		Author:          "Vendor PSIRT",
+		AuthorRole:      "Supplier",
+	}
+	data, err := json.Marshal(&stmt)
+	require.NoError(t, err)
+
+	fields := map[string]json.RawMessage{}
+	require.NoError(t, json.Unmarshal(data, &fields))
+	for _, k := range []string{
+		"openvex.dev/remediations", "openvex.dev/annotations", "openvex.dev/references",
+		"openvex.dev/credits", "openvex.dev/lang", "openvex.dev/author", "openvex.dev/role",
+	} {
+		require.Contains(t, fields, k)
+	}
+	for _, k := range []string{"remediations", "annotations", "references", "credits", "lang", "author", "role"} {
+		require.NotContains(t, fields, k)
+	}
+	require.Contains(t, string(fields["vulnerability"]), `"openvex.dev/ratings"`)
+
+	parsed := Statement{}
+	require.NoError(t, json.Unmarshal(data, &parsed))
+	require.Equal(t, stmt, parsed)
+
+	data, err = json.Marshal(&Metadata{PreviousDigest: "sha256:1234", Lang: "en"})
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"openvex.dev/previous_digest":"sha256:1234"`)
+	require.Contains(t, string(data), `"openvex.dev/lang":"en"`)
+}
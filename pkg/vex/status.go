@@ -64,3 +64,20 @@ func StatusFromCSAF(csafStatus string) Status {
 		return ""
 	}
 }
+
+// StatusFromCycloneDX returns a vex status from the CycloneDX analysis state.
+// States that don't map to a status return an empty string.
+func StatusFromCycloneDX(state string) Status {
+	switch state {
+	case "not_affected", "false_positive":
+		return StatusNotAffected
+	case "resolved", "resolved_with_pedigree":
+		return StatusFixed
+	case "in_triage", "":
+		return StatusUnderInvestigation
+	case "exploitable":
+		return StatusAffected
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// Store is a collection of documents that grows over time and can be
+// queried concurrently, eg by a long running service receiving documents
+// while answering queries. Documents are copied when they are added and
+// their statements are indexed by vulnerability and product as in the
+// SuppressionEngine. All the methods are safe for concurrent use.
+type Store struct {
+	mutex  sync.RWMutex
+	docs   []*VEX
+	ids    map[string]int
+	engine *SuppressionEngine
+}
+
+// NewStore returns a store holding the documents.
+func NewStore(docs ...*VEX) *Store {
+	s := &Store{
+		docs:   []*VEX{},
+		ids:    map[string]int{},
+		engine: NewSuppressionEngine(),
+	}
+	for _, doc := range docs {
+		s.Add(doc)
+	}
+	return s
+}
+
+// Add adds a copy of the document to the store. A document with the @id of
+// one already in the store replaces it if it is newer, that is if it has a
+// higher version or, with the same version, a later update. Add returns
+// false if the document was not added because it is nil or not newer than
+// the stored one.
+func (s *Store) Add(doc *VEX) bool {
+	if doc == nil {
+		return false
+	}
+	cp := copyDocument(doc)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if n, ok := s.ids[cp.ID]; ok && cp.ID != "" {
+		if !newerDocument(cp, s.docs[n]) {
+			return false
+		}
+		// The index cannot drop statements, rebuild it with the new
+		// version of the document.
+		s.docs[n] = cp
+		s.engine = NewSuppressionEngine(s.docs...)
+		return true
+	}
+
+	if cp.ID != "" {
+		s.ids[cp.ID] = len(s.docs)
+	}
+	s.docs = append(s.docs, cp)
+	s.engine.add(cp)
+	return true
+}
+
+// copyDocument returns a copy of the document with its own statements.
+func copyDocument(doc *VEX) *VEX {
+	cp := *doc
+	cp.Timestamp = copyTime(doc.Timestamp)
+	cp.LastUpdated = copyTime(doc.LastUpdated)
+	cp.Statements = make([]Statement, len(doc.Statements))
+	for i := range doc.Statements {
+		doc.Statements[i].DeepCopyInto(&cp.Statements[i])
+	}
+	return &cp
+}
+
+// newerDocument returns true if a is a newer version of b.
+func newerDocument(a, b *VEX) bool {
+	if a.Version != b.Version {
+		return a.Version > b.Version
+	}
+	return documentUpdate(a).After(documentUpdate(b))
+}
+
+// documentUpdate returns the time of the last update of a document.
+func documentUpdate(doc *VEX) time.Time {
+	switch {
+	case doc.LastUpdated != nil:
+		return *doc.LastUpdated
+	case doc.Timestamp != nil:
+		return *doc.Timestamp
+	default:
+		return time.Time{}
+	}
+}
+
+// StatusOf returns the status of the vulnerability in the product according
+// to the latest statement applying to them across all the documents, and a
+// copy of the statement. It returns an empty status and a nil statement if
+// no statement applies.
+func (s *Store) StatusOf(vulnID, product string) (Status, *Statement) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	_, stmt := s.engine.Suppress(vulnID, product)
+	if stmt == nil {
+		return "", nil
+	}
+	return stmt.Status, stmt.DeepCopy()
+}
+
+// Matches returns copies of the statements of all the documents that apply
+// to the vulnerability and product, sorted from oldest to newest.
+func (s *Store) Matches(vulnID, product string) []Statement {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	matches := []*suppressionEntry{}
+	for _, n := range s.engine.index.candidates(vulnID, product) {
+		if entry := &s.engine.entries[n]; entry.stmt.Matches(vulnID, product, nil) {
+			matches = append(matches, entry)
+		}
+	}
+	slices.SortStableFunc(matches, func(a, b *suppressionEntry) int {
+		return a.time.Compare(b.time)
+	})
+
+	ret := make([]Statement, len(matches))
+	for i, entry := range matches {
+		entry.stmt.DeepCopyInto(&ret[i])
+	}
+	return ret
+}
+
+// Documents returns the documents in the store. The documents are shared
+// with the store and must not be modified.
+func (s *Store) Documents() []*VEX {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return slices.Clone(s.docs)
+}
+
+// Len returns the number of documents in the store.
+func (s *Store) Len() int {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return len(s.docs)
+}
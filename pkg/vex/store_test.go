@@ -0,0 +1,121 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore(t *testing.T) {
+	now := time.Now()
+	before := now.Add(-time.Hour)
+
+	older := New()
+	older.ID = "https://example.com/vex/older"
+	older.Timestamp = &before
+	older.Statements = []Statement{
+		{
+			Vulnerability: Vulnerability{Name: "CVE-2023-0001"},
+			Products:      []Product{{Component: Component{ID: "pkg:apk/wolfi/git@2.41.0-r0"}}},
+			Status:        StatusAffected,
+		},
+	}
+
+	newer := New()
+	newer.ID = "https://example.com/vex/newer"
+	newer.Timestamp = &now
+	newer.Statements = []Statement{
+		{
+			Vulnerability: Vulnerability{Name: "CVE-2023-0001"},
+			Products:      []Product{{Component: Component{ID: "pkg:apk/wolfi/git"}}},
+			Status:        StatusFixed,
+		},
+	}
+
+	s := NewStore(&older)
+	status, stmt := s.StatusOf("CVE-2023-0001", "pkg:apk/wolfi/git@2.41.0-r0")
+	require.Equal(t, StatusAffected, status)
+	require.NotNil(t, stmt)
+
+	status, stmt = s.StatusOf("CVE-2023-9999", "pkg:apk/wolfi/git@2.41.0-r0")
+	require.Empty(t, status)
+	require.Nil(t, stmt)
+
+	// Documents are copied when added
+	older.Statements[0].Status = StatusNotAffected
+	older.Statements[0].Products[0].ID = "pkg:apk/wolfi/curl@8.1.0-r0"
+	*older.Timestamp = now
+	status, _ = s.StatusOf("CVE-2023-0001", "pkg:apk/wolfi/git@2.41.0-r0")
+	require.Equal(t, StatusAffected, status)
+	require.Equal(t, now.Add(-time.Hour), *s.Documents()[0].Timestamp)
+
+	require.True(t, s.Add(&newer))
+	require.False(t, s.Add(nil))
+	require.Equal(t, 2, s.Len())
+	status, _ = s.StatusOf("CVE-2023-0001", "pkg:apk/wolfi/git@2.41.0-r0")
+	require.Equal(t, StatusFixed, status)
+
+	matches := s.Matches("CVE-2023-0001", "pkg:apk/wolfi/git@2.41.0-r0")
+	require.Len(t, matches, 2)
+	require.Equal(t, StatusAffected, matches[0].Status)
+	require.Equal(t, StatusFixed, matches[1].Status)
+
+	// A new version of a document replaces the stored one
+	update := New()
+	update.ID = newer.ID
+	update.Version = newer.Version + 1
+	update.Timestamp = &now
+	update.Statements = []Statement{
+		{
+			Vulnerability: Vulnerability{Name: "CVE-2023-0001"},
+			Products:      []Product{{Component: Component{ID: "pkg:apk/wolfi/git"}}},
+			Status:        StatusUnderInvestigation,
+		},
+	}
+	require.True(t, s.Add(&update))
+	require.Equal(t, 2, s.Len())
+	status, _ = s.StatusOf("CVE-2023-0001", "pkg:apk/wolfi/git@2.41.0-r0")
+	require.Equal(t, StatusUnderInvestigation, status)
+
+	// Older versions are ignored
+	require.False(t, s.Add(&newer))
+	status, _ = s.StatusOf("CVE-2023-0001", "pkg:apk/wolfi/git@2.41.0-r0")
+	require.Equal(t, StatusUnderInvestigation, status)
+}
+
+func TestStoreConcurrency(t *testing.T) {
+	s := NewStore()
+	var wg sync.WaitGroup
+	for i := range 10 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			doc := New()
+			doc.ID = fmt.Sprintf("https://example.com/vex/%d", i)
+			doc.Statements = []Statement{{
+				Vulnerability: Vulnerability{Name: VulnerabilityID(fmt.Sprintf("CVE-2023-%04d", i))},
+				Products:      []Product{{Component: Component{ID: "pkg:apk/wolfi/git"}}},
+				Status:        StatusFixed,
+			}}
+			s.Add(&doc)
+		}()
+		go func() {
+			defer wg.Done()
+			s.StatusOf(fmt.Sprintf("CVE-2023-%04d", i), "pkg:apk/wolfi/git@2.41.0-r0")
+			s.Matches(fmt.Sprintf("CVE-2023-%04d", i), "pkg:apk/wolfi/git")
+		}()
+	}
+	wg.Wait()
+
+	require.Equal(t, 10, s.Len())
+	for i := range 10 {
+		status, _ := s.StatusOf(fmt.Sprintf("CVE-2023-%04d", i), "pkg:apk/wolfi/git@2.41.0-r0")
+		require.Equal(t, StatusFixed, status)
+	}
+}
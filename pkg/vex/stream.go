@@ -0,0 +1,375 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"bytes"
+	"container/heap"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrUnsortedShard is returned when merging shards whose statements are not
+// sorted by timestamp.
+var ErrUnsortedShard = errors.New("shard statements are not sorted by timestamp")
+
+// StatementReader reads the statements of a document one at a time, keeping
+// only the statement being read in memory.
+type StatementReader struct {
+	// Metadata is the metadata of the document
+	Metadata Metadata
+
+	dec  *json.Decoder
+	done bool
+}
+
+// NewStatementReader returns a reader of the statements of the document in
+// r. The document is read twice: first to get its metadata, which may come
+// after the statements, then to stream the statements.
+func NewStatementReader(r io.ReadSeeker) (*StatementReader, error) {
+	md, err := readStreamMetadata(json.NewDecoder(r))
+	if err != nil {
+		return nil, fmt.Errorf("reading document metadata: %w", err)
+	}
+
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("rewinding document: %w", err)
+	}
+	sr := &StatementReader{Metadata: *md, dec: json.NewDecoder(r)}
+	found, err := seekStatements(sr.dec)
+	if err != nil {
+		return nil, fmt.Errorf("looking for statements: %w", err)
+	}
+	sr.done = !found
+	return sr, nil
+}
+
+// Next returns the next statement of the document. It returns io.EOF when
+// there are no more statements.
+func (sr *StatementReader) Next() (*Statement, error) {
+	if sr.done {
+		return nil, io.EOF
+	}
+	if !sr.dec.More() {
+		sr.done = true
+		if _, err := sr.dec.Token(); err != nil {
+			return nil, fmt.Errorf("reading end of statements: %w", err)
+		}
+		return nil, io.EOF
+	}
+	stmt := &Statement{}
+	if err := sr.dec.Decode(stmt); err != nil {
+		return nil, fmt.Errorf("decoding statement: %w", err)
+	}
+	return stmt, nil
+}
+
+// readStreamMetadata reads the fields of a document other than its
+// statements, which are skipped.
+func readStreamMetadata(dec *json.Decoder) (*Metadata, error) {
+	if err := expectDelim(dec, '{'); err != nil {
+		return nil, err
+	}
+	fields := map[string]json.RawMessage{}
+	for dec.More() {
+		key, err := readKey(dec)
+		if err != nil {
+			return nil, err
+		}
+		if key == "statements" {
+			if err := skipValue(dec); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return nil, fmt.Errorf("decoding %s: %w", key, err)
+		}
+		fields[key] = raw
+	}
+
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling metadata: %w", err)
+	}
+	md := &Metadata{}
+	if err := decodeJSON(data, md); err != nil {
+		return nil, fmt.Errorf("decoding metadata: %w", err)
+	}
+	return md, nil
+}
+
+// seekStatements advances the decoder into the statements array of the
+// document. It returns false if the document has no statements.
+func seekStatements(dec *json.Decoder) (bool, error) {
+	if err := expectDelim(dec, '{'); err != nil {
+		return false, err
+	}
+	for dec.More() {
+		key, err := readKey(dec)
+		if err != nil {
+			return false, err
+		}
+		if key != "statements" {
+			if err := skipValue(dec); err != nil {
+				return false, err
+			}
+			continue
+		}
+
+		t, err := dec.Token()
+		if err != nil {
+			return false, err
+		}
+		switch t {
+		case json.Delim('['):
+			return true, nil
+		case nil:
+			return false, nil
+		default:
+			return false, fmt.Errorf("statements are not a list: %v", t)
+		}
+	}
+	return false, nil
+}
+
+// expectDelim reads the next token, failing if it is not the delimiter.
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	t, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if t != delim {
+		return fmt.Errorf("expected %s, got %v", delim, t)
+	}
+	return nil
+}
+
+// readKey reads the next object key.
+func readKey(dec *json.Decoder) (string, error) {
+	t, err := dec.Token()
+	if err != nil {
+		return "", err
+	}
+	key, ok := t.(string)
+	if !ok {
+		return "", fmt.Errorf("expected object key, got %v", t)
+	}
+	return key, nil
+}
+
+// skipValue reads the next value without decoding it.
+func skipValue(dec *json.Decoder) error {
+	depth := 0
+	for {
+		t, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch t {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+// StatementWriter writes a document one statement at a time. The output is
+// the same as serializing the whole document with ToJSON.
+type StatementWriter struct {
+	w      io.Writer
+	suffix []byte
+	n      int
+}
+
+// NewStatementWriter writes the metadata of a document to w and returns a
+// writer for its statements. The document is complete once the writer is
+// closed.
+func NewStatementWriter(w io.Writer, md *Metadata) (*StatementWriter, error) {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(&VEX{Metadata: *md, Statements: []Statement{}}); err != nil {
+		return nil, fmt.Errorf("encoding document metadata: %w", err)
+	}
+
+	// Split the document where the statements go
+	marker := []byte(`"statements": [`)
+	i := bytes.Index(b.Bytes(), marker)
+	if i == -1 {
+		return nil, errors.New("statements not found in the encoded document")
+	}
+	i += len(marker)
+	if _, err := w.Write(b.Bytes()[:i]); err != nil {
+		return nil, fmt.Errorf("writing document metadata: %w", err)
+	}
+	return &StatementWriter{w: w, suffix: bytes.Clone(b.Bytes()[i:])}, nil
+}
+
+// Write adds a statement to the document.
+func (sw *StatementWriter) Write(stmt *Statement) error {
+	var b bytes.Buffer
+	if sw.n > 0 {
+		b.WriteString(",")
+	}
+	b.WriteString("\n    ")
+	enc := json.NewEncoder(&b)
+	enc.SetIndent("    ", "  ")
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(stmt); err != nil {
+		return fmt.Errorf("encoding statement: %w", err)
+	}
+	b.Truncate(b.Len() - 1) // Trailing newline
+
+	if _, err := sw.w.Write(b.Bytes()); err != nil {
+		return fmt.Errorf("writing statement: %w", err)
+	}
+	sw.n++
+	return nil
+}
+
+// Close writes the end of the document. It does not close the underlying
+// writer.
+func (sw *StatementWriter) Close() error {
+	if sw.n > 0 {
+		if _, err := io.WriteString(sw.w, "\n  "); err != nil {
+			return fmt.Errorf("writing end of statements: %w", err)
+		}
+	}
+	if _, err := sw.w.Write(sw.suffix); err != nil {
+		return fmt.Errorf("writing end of document: %w", err)
+	}
+	return nil
+}
+
+// MergeShards merges the statements of a number of documents into a new
+// document streamed to w, so that corpora larger than the available memory
+// can be merged. Only the next statement of each shard is kept in memory.
+//
+// The statements of every shard must be sorted by timestamp, as they are in
+// documents written by MergeShards, otherwise it fails with
+// ErrUnsortedShard. Shards are combined in a k-way merge by timestamp,
+// statements with the same timestamp are written in the order of their
+// shards. Statements without a timestamp take the one of their document.
+//
+// The merge options are applied as in MergeDocumentsWithOptions, except
+// UnifyAliases and conflict policies other than ConflictKeepAll which need
+// all the statements at once and are not supported.
+func MergeShards(mergeOpts *MergeOptions, w io.Writer, shards []*StatementReader) error {
+	if len(shards) == 0 {
+		return errors.New("at least one shard is required to merge")
+	}
+	compat := mergeOpts.Mode == MergeModeCompat
+	if mergeOpts.UnifyAliases {
+		return errors.New("unifying aliases is not supported when merging shards")
+	}
+	if mergeOpts.Conflicts != ConflictKeepAll {
+		return errors.New("conflict policies are not supported when merging shards")
+	}
+
+	metas := []*Metadata{}
+	for _, sr := range shards {
+		metas = append(metas, &sr.Metadata)
+	}
+	newDoc := newMergedDocument(mergeOpts, metas)
+	filter := newMergeFilter(mergeOpts)
+
+	// next reads the next statement to merge from the shard
+	next := func(h *shardHead) (bool, error) {
+		for {
+			stmt, err := h.reader.Next()
+			if errors.Is(err, io.EOF) {
+				return false, nil
+			}
+			if err != nil {
+				return false, fmt.Errorf("reading shard #%d: %w", h.shard, err)
+			}
+			if !filter.matches(stmt) {
+				continue
+			}
+			if _, err := mergeStatement(stmt, &h.reader.Metadata, &newDoc, compat); err != nil {
+				return false, fmt.Errorf("merging statement from shard #%d: %w", h.shard, err)
+			}
+			if h.stmt != nil && stmt.Timestamp.Before(*h.stmt.Timestamp) {
+				return false, fmt.Errorf("shard #%d: %w", h.shard, ErrUnsortedShard)
+			}
+			h.stmt = stmt
+			return true, nil
+		}
+	}
+
+	heads := &shardHeap{}
+	for i, sr := range shards {
+		h := &shardHead{reader: sr, shard: i}
+		ok, err := next(h)
+		if err != nil {
+			return err
+		}
+		if ok {
+			heads.list = append(heads.list, h)
+		}
+	}
+	heap.Init(heads)
+
+	sw, err := NewStatementWriter(w, &newDoc.Metadata)
+	if err != nil {
+		return err
+	}
+	for len(heads.list) > 0 {
+		h := heads.list[0]
+		if err := sw.Write(h.stmt); err != nil {
+			return err
+		}
+		ok, err := next(h)
+		if err != nil {
+			return err
+		}
+		if ok {
+			heap.Fix(heads, 0)
+		} else {
+			heap.Pop(heads)
+		}
+	}
+	return sw.Close()
+}
+
+// shardHead is the next statement to merge from a shard.
+type shardHead struct {
+	reader *StatementReader
+	shard  int
+	stmt   *Statement
+}
+
+// shardHeap orders the shards by the timestamp of their next statement.
+type shardHeap struct {
+	list []*shardHead
+}
+
+func (h *shardHeap) Len() int { return len(h.list) }
+
+func (h *shardHeap) Less(i, j int) bool {
+	ti, tj := *h.list[i].stmt.Timestamp, *h.list[j].stmt.Timestamp
+	if !ti.Equal(tj) {
+		return ti.Before(tj)
+	}
+	return h.list[i].shard < h.list[j].shard
+}
+
+func (h *shardHeap) Swap(i, j int) { h.list[i], h.list[j] = h.list[j], h.list[i] }
+
+func (h *shardHeap) Push(x any) { h.list = append(h.list, x.(*shardHead)) } //nolint:forcetypeassert // Only shard heads are pushed
+
+func (h *shardHeap) Pop() any {
+	last := h.list[len(h.list)-1]
+	h.list = h.list[:len(h.list)-1]
+	return last
+}
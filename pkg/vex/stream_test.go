@@ -0,0 +1,165 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// shardDocument returns a serialized document with a statement about each
+// of the vulnerabilities, issued the hours after midnight in the list.
+func shardDocument(t *testing.T, id string, vulns []string, hours []int) *bytes.Reader {
+	t.Helper()
+	ts := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	doc := &VEX{Metadata: Metadata{
+		Context: ContextLocator(), ID: id, Author: "Shard Author", Version: 1, Timestamp: &ts,
+	}}
+	for i, v := range vulns {
+		stmtTime := ts.Add(time.Duration(hours[i]) * time.Hour)
+		doc.Statements = append(doc.Statements, Statement{
+			Vulnerability: Vulnerability{Name: VulnerabilityID(v)},
+			Products:      []Product{{Component: Component{ID: "pkg:apk/wolfi/git"}}},
+			Status:        StatusUnderInvestigation,
+			Timestamp:     &stmtTime,
+		})
+	}
+	var b bytes.Buffer
+	require.NoError(t, doc.ToJSON(&b))
+	return bytes.NewReader(b.Bytes())
+}
+
+func TestStatementReader(t *testing.T) {
+	doc, err := Open("testdata/v0.2.0.json")
+	require.NoError(t, err)
+	var b bytes.Buffer
+	require.NoError(t, doc.ToJSON(&b))
+
+	// ToJSON writes the document timestamp after the statements
+	sr, err := NewStatementReader(bytes.NewReader(b.Bytes()))
+	require.NoError(t, err)
+	require.Equal(t, doc.ID, sr.Metadata.ID)
+	require.True(t, doc.Timestamp.Truncate(time.Second).Equal(*sr.Metadata.Timestamp))
+
+	n := 0
+	for {
+		stmt, err := sr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		require.Equal(t, doc.Statements[n].Vulnerability.Name, stmt.Vulnerability.Name)
+		n++
+	}
+	require.Equal(t, len(doc.Statements), n)
+
+	_, err = NewStatementReader(bytes.NewReader([]byte(`["statements"]`)))
+	require.Error(t, err)
+}
+
+func TestStatementWriter(t *testing.T) {
+	doc, err := Open("testdata/v0.2.0.json")
+	require.NoError(t, err)
+	var expected bytes.Buffer
+	require.NoError(t, doc.ToJSON(&expected))
+
+	var b bytes.Buffer
+	sw, err := NewStatementWriter(&b, &doc.Metadata)
+	require.NoError(t, err)
+	for i := range doc.Statements {
+		require.NoError(t, sw.Write(&doc.Statements[i]))
+	}
+	require.NoError(t, sw.Close())
+	require.Equal(t, expected.String(), b.String())
+
+	// Documents without statements
+	expected.Reset()
+	b.Reset()
+	doc.Statements = []Statement{}
+	require.NoError(t, doc.ToJSON(&expected))
+	sw, err = NewStatementWriter(&b, &doc.Metadata)
+	require.NoError(t, err)
+	require.NoError(t, sw.Close())
+	require.Equal(t, expected.String(), b.String())
+}
+
+func TestMergeShards(t *testing.T) {
+	for m, tc := range map[string]struct {
+		shards   []*bytes.Reader
+		opts     *MergeOptions
+		author   string
+		expected []VulnerabilityID
+		err      error
+	}{
+		"interleaved": {
+			shards: []*bytes.Reader{
+				shardDocument(t, "shard-1", []string{"CVE-1", "CVE-3", "CVE-5"}, []int{1, 3, 5}),
+				shardDocument(t, "shard-2", []string{"CVE-2", "CVE-4"}, []int{2, 4}),
+				shardDocument(t, "shard-3", []string{"CVE-0", "CVE-6"}, []int{0, 6}),
+			},
+			opts:     &MergeOptions{Mode: MergeModeAttributed},
+			author:   "Shard Author",
+			expected: []VulnerabilityID{"CVE-0", "CVE-1", "CVE-2", "CVE-3", "CVE-4", "CVE-5", "CVE-6"},
+		},
+		"ties in shard order": {
+			shards: []*bytes.Reader{
+				shardDocument(t, "shard-1", []string{"CVE-B"}, []int{1}),
+				shardDocument(t, "shard-2", []string{"CVE-A"}, []int{1}),
+			},
+			opts:     &MergeOptions{Mode: MergeModeAttributed},
+			author:   "Shard Author",
+			expected: []VulnerabilityID{"CVE-B", "CVE-A"},
+		},
+		"filtered": {
+			shards: []*bytes.Reader{
+				shardDocument(t, "shard-1", []string{"CVE-1", "CVE-3"}, []int{1, 3}),
+				shardDocument(t, "shard-2", []string{"CVE-2"}, []int{2}),
+			},
+			opts:     &MergeOptions{Vulnerabilities: []string{"CVE-3", "CVE-2"}},
+			author:   DefaultAuthor,
+			expected: []VulnerabilityID{"CVE-2", "CVE-3"},
+		},
+		"unsorted": {
+			shards: []*bytes.Reader{
+				shardDocument(t, "shard-1", []string{"CVE-1", "CVE-3"}, []int{3, 1}),
+			},
+			opts: &MergeOptions{},
+			err:  ErrUnsortedShard,
+		},
+	} {
+		readers := []*StatementReader{}
+		for _, s := range tc.shards {
+			sr, err := NewStatementReader(s)
+			require.NoError(t, err, m)
+			readers = append(readers, sr)
+		}
+
+		var b bytes.Buffer
+		err := MergeShards(tc.opts, &b, readers)
+		if tc.err != nil {
+			require.ErrorIs(t, err, tc.err, m)
+			continue
+		}
+		require.NoError(t, err, m)
+
+		doc, err := Parse(b.Bytes())
+		require.NoError(t, err, m)
+		require.Equal(t, tc.author, doc.Author, m)
+		vulns := []VulnerabilityID{}
+		for i := range doc.Statements {
+			vulns = append(vulns, doc.Statements[i].Vulnerability.Name)
+		}
+		require.Equal(t, tc.expected, vulns, m)
+	}
+
+	sr, err := NewStatementReader(shardDocument(t, "shard-1", []string{"CVE-1"}, []int{1}))
+	require.NoError(t, err)
+	require.Error(t, MergeShards(&MergeOptions{UnifyAliases: true}, io.Discard, []*StatementReader{sr}))
+	require.Error(t, MergeShards(&MergeOptions{}, io.Discard, nil))
+}
@@ -0,0 +1,143 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Summary captures aggregate counts about the contents of a VEX document.
+type Summary struct {
+	// Statements is the total number of statements in the document.
+	Statements int
+
+	// Vulnerabilities is the number of distinct vulnerabilities.
+	Vulnerabilities int
+
+	// Products is the number of distinct products.
+	Products int
+
+	// Statuses counts the statements by their status.
+	Statuses map[Status]int
+
+	// Justifications counts the statements by their justification.
+	Justifications map[Justification]int
+}
+
+// Summary returns the aggregate counts of the statements in the document.
+func (vexDoc *VEX) Summary() Summary {
+	summary := Summary{
+		Statuses:       map[Status]int{},
+		Justifications: map[Justification]int{},
+	}
+
+	vulns := map[string]struct{}{}
+	products := map[IdentifierKey]struct{}{}
+	for i := range vexDoc.Statements {
+		stmt := &vexDoc.Statements[i]
+		summary.Statements++
+		summary.Statuses[stmt.Status]++
+		if stmt.Justification != "" {
+			summary.Justifications[stmt.Justification]++
+		}
+
+		vulns[cstringFromVulnerability(stmt.Vulnerability)] = struct{}{}
+		for j := range stmt.Products {
+			products[stmt.Products[j].key()] = struct{}{}
+		}
+	}
+
+	summary.Vulnerabilities = len(vulns)
+	summary.Products = len(products)
+	return summary
+}
+
+// String renders the summary in a single line, listing the status counts
+// in the order they are defined in the spec.
+func (s Summary) String() string {
+	counts := []string{}
+	for _, status := range Statuses() {
+		if n := s.Statuses[Status(status)]; n > 0 {
+			counts = append(counts, fmt.Sprintf("%d %s", n, status))
+		}
+	}
+
+	str := fmt.Sprintf(
+		"%d statements, %d vulnerabilities, %d products", s.Statements, s.Vulnerabilities, s.Products,
+	)
+	if len(counts) > 0 {
+		str += fmt.Sprintf(" (%s)", strings.Join(counts, ", "))
+	}
+	return str
+}
+
+// Stats extends the document Summary with metrics useful to track the
+// health of a VEX program.
+type Stats struct {
+	Summary
+
+	// Oldest is the earliest statement timestamp in the document.
+	Oldest *time.Time
+
+	// Newest is the latest statement timestamp in the document.
+	Newest *time.Time
+
+	// MissingFields maps the JSON name of the optional statement fields to
+	// the percentage of statements that don't define them.
+	MissingFields map[string]float64
+}
+
+// Stats computes the document statistics. Statements without a timestamp
+// inherit the document date when computing the oldest and newest dates.
+func (vexDoc *VEX) Stats() Stats {
+	stats := Stats{
+		Summary:       vexDoc.Summary(),
+		MissingFields: map[string]float64{},
+	}
+
+	missing := map[string]int{}
+	for i := range vexDoc.Statements {
+		stmt := &vexDoc.Statements[i]
+
+		ts := stmt.Timestamp
+		if ts == nil {
+			ts = vexDoc.Timestamp
+		}
+		if ts != nil {
+			if stats.Oldest == nil || ts.Before(*stats.Oldest) {
+				stats.Oldest = ts
+			}
+			if stats.Newest == nil || ts.After(*stats.Newest) {
+				stats.Newest = ts
+			}
+		}
+
+		for field, isMissing := range map[string]bool{
+			"@id":                        stmt.ID == "",
+			"timestamp":                  stmt.Timestamp == nil,
+			"last_updated":               stmt.LastUpdated == nil,
+			"status_notes":               stmt.StatusNotes == "",
+			"impact_statement":           stmt.ImpactStatement == "",
+			"action_statement":           stmt.ActionStatement == "",
+			"action_statement_timestamp": stmt.ActionStatementTimestamp == nil,
+			"vulnerability.@id":          stmt.Vulnerability.ID == "",
+			"vulnerability.description":  stmt.Vulnerability.Description == "",
+			"vulnerability.aliases":      len(stmt.Vulnerability.Aliases) == 0,
+		} {
+			n := missing[field]
+			if isMissing {
+				n++
+			}
+			missing[field] = n
+		}
+	}
+
+	for field, n := range missing {
+		stats.MissingFields[field] = float64(n) * 100 / float64(stats.Statements)
+	}
+
+	return stats
+}
@@ -0,0 +1,100 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummary(t *testing.T) {
+	doc := &VEX{
+		Statements: []Statement{
+			{
+				Vulnerability: Vulnerability{Name: "CVE-2014-123456"},
+				Products:      []Product{{Component: Component{ID: "pkg:deb/pkg@1.0"}}},
+				Status:        StatusNotAffected,
+				Justification: ComponentNotPresent,
+			},
+			{
+				Vulnerability: Vulnerability{Name: "CVE-2014-123456"},
+				Products:      []Product{{Component: Component{ID: "pkg:deb/pkg@2.0"}}},
+				Status:        StatusFixed,
+			},
+			{
+				Vulnerability: Vulnerability{Name: "CVE-2014-654321"},
+				Products: []Product{
+					{Component: Component{ID: "pkg:deb/pkg@1.0"}},
+					{Component: Component{ID: "pkg:deb/pkg@2.0"}},
+				},
+				Status:        StatusNotAffected,
+				Justification: VulnerableCodeNotPresent,
+			},
+		},
+	}
+
+	summary := doc.Summary()
+	require.Equal(t, 3, summary.Statements)
+	require.Equal(t, 2, summary.Vulnerabilities)
+	require.Equal(t, 2, summary.Products)
+	require.Equal(t, map[Status]int{StatusNotAffected: 2, StatusFixed: 1}, summary.Statuses)
+	require.Equal(t, map[Justification]int{ComponentNotPresent: 1, VulnerableCodeNotPresent: 1}, summary.Justifications)
+	require.Equal(t, "3 statements, 2 vulnerabilities, 2 products (2 not_affected, 1 fixed)", summary.String())
+}
+
+func TestStats(t *testing.T) {
+	date1 := time.Date(2023, 4, 17, 20, 34, 58, 0, time.UTC)
+	date2 := time.Date(2023, 4, 18, 20, 34, 58, 0, time.UTC)
+	date3 := time.Date(2023, 4, 19, 20, 34, 58, 0, time.UTC)
+
+	doc := &VEX{
+		Metadata: Metadata{Timestamp: &date2},
+		Statements: []Statement{
+			{
+				ID:            "https://example.com/statement-1",
+				Vulnerability: Vulnerability{Name: "CVE-2014-123456", Description: "Bad bug"},
+				Timestamp:     &date1,
+				Products:      []Product{{Component: Component{ID: "pkg:deb/pkg@1.0"}}},
+				Status:        StatusNotAffected,
+				Justification: ComponentNotPresent,
+			},
+			{
+				Vulnerability: Vulnerability{Name: "CVE-2014-654321"},
+				Timestamp:     &date3,
+				Products:      []Product{{Component: Component{ID: "pkg:deb/pkg@1.0"}}},
+				Status:        StatusFixed,
+			},
+			{
+				Vulnerability: Vulnerability{Name: "CVE-2014-111111"},
+				Products:      []Product{{Component: Component{ID: "pkg:deb/pkg@1.0"}}},
+				Status:        StatusFixed,
+			},
+			{
+				Vulnerability: Vulnerability{Name: "CVE-2014-222222"},
+				Products:      []Product{{Component: Component{ID: "pkg:deb/pkg@1.0"}}},
+				Status:        StatusUnderInvestigation,
+				StatusNotes:   "Checking with upstream",
+			},
+		},
+	}
+
+	stats := doc.Stats()
+	require.Equal(t, 4, stats.Statements)
+	require.Equal(t, 4, stats.Vulnerabilities)
+	require.Equal(t, 1, stats.Products)
+	require.Equal(t, 2, stats.Statuses[StatusFixed])
+	require.Equal(t, &date1, stats.Oldest)
+	require.Equal(t, &date3, stats.Newest)
+	require.InDelta(t, 75.0, stats.MissingFields["@id"], 0.01)
+	require.InDelta(t, 50.0, stats.MissingFields["timestamp"], 0.01)
+	require.InDelta(t, 75.0, stats.MissingFields["status_notes"], 0.01)
+	require.InDelta(t, 75.0, stats.MissingFields["vulnerability.description"], 0.01)
+	require.InDelta(t, 100.0, stats.MissingFields["last_updated"], 0.01)
+
+	empty := (&VEX{}).Stats()
+	require.Nil(t, empty.Oldest)
+	require.Zero(t, empty.MissingFields["@id"])
+}
@@ -0,0 +1,84 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import "time"
+
+// SuppressionEngine answers whether scanner findings are waived by a set of
+// VEX documents. The statements are indexed by vulnerability and product
+// when the engine is created, so each query only evaluates the few
+// statements that can apply to it instead of every statement in every
+// document. The documents must not be modified after creating the engine.
+type SuppressionEngine struct {
+	entries []suppressionEntry
+	index   *productIndex
+}
+
+// suppressionEntry is an indexed statement.
+type suppressionEntry struct {
+	stmt *Statement
+
+	// time is the effective timestamp of the statement, inherited from the
+	// document when the statement has none.
+	time time.Time
+}
+
+// NewSuppressionEngine indexes the statements of the documents and returns
+// a new engine to query them.
+func NewSuppressionEngine(docs ...*VEX) *SuppressionEngine {
+	e := &SuppressionEngine{
+		entries: []suppressionEntry{},
+		index:   newProductIndex(),
+	}
+
+	for _, doc := range docs {
+		e.add(doc)
+	}
+	return e
+}
+
+// add indexes the statements of a document.
+func (e *SuppressionEngine) add(doc *VEX) {
+	if doc == nil {
+		return
+	}
+	for i := range doc.Statements {
+		entry := suppressionEntry{stmt: &doc.Statements[i]}
+		switch {
+		case entry.stmt.Timestamp != nil:
+			entry.time = *entry.stmt.Timestamp
+		case doc.Timestamp != nil:
+			entry.time = *doc.Timestamp
+		}
+		e.index.add(len(e.entries), entry.stmt)
+		e.entries = append(e.entries, entry)
+	}
+}
+
+// Suppress returns true if the finding of the vulnerability in the product
+// is waived by the VEX data, that is if the latest statement applying to
+// them across all documents is not_affected or fixed. The applying statement
+// is returned even when it does not suppress the finding, nil is only
+// returned when there are no statements about the vulnerability in the
+// product. Statements match the product as in VEX.Matches, so expired
+// statements are left out, and when they have the same timestamp the last
+// one wins as in VEX.EffectiveStatement.
+func (e *SuppressionEngine) Suppress(vulnID, productID string) (bool, *Statement) {
+	var latest *suppressionEntry
+	for _, n := range e.index.candidates(vulnID, productID) {
+		// Candidates are in insertion order, so only statements with a
+		// later or equal timestamp can replace the current one.
+		entry := &e.entries[n]
+		if latest != nil && entry.time.Before(latest.time) {
+			continue
+		}
+		if entry.stmt.Matches(vulnID, productID, nil) {
+			latest = entry
+		}
+	}
+	if latest == nil {
+		return false, nil
+	}
+	return latest.stmt.Status == StatusNotAffected || latest.stmt.Status == StatusFixed, latest.stmt
+}
@@ -0,0 +1,143 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSuppressionEngine(t *testing.T) {
+	now := time.Now()
+	before := now.Add(-time.Hour)
+
+	older := New()
+	older.Timestamp = &before
+	older.Statements = []Statement{
+		{
+			ID:            "older-1",
+			Vulnerability: Vulnerability{Name: "CVE-2023-0001"},
+			Products:      []Product{{Component: Component{ID: "pkg:apk/wolfi/git@2.41.0-r0"}}},
+			Status:        StatusAffected,
+		},
+		{
+			ID:            "older-2",
+			Vulnerability: Vulnerability{Name: "CVE-2023-0002", Aliases: []VulnerabilityID{"GHSA-xxxx-yyyy-zzzz"}},
+			Products:      []Product{{Component: Component{ID: "pkg:apk/wolfi/git"}}},
+			Status:        StatusNotAffected,
+			Justification: ComponentNotPresent,
+		},
+		{
+			ID:            "older-3",
+			Vulnerability: Vulnerability{Name: "CVE-2023-0003"},
+			Products: []Product{{Component: Component{
+				Identifiers: map[IdentifierType]string{CPE23: "cpe:2.3:a:openssl:openssl:3.1.*:*:*:*:*:*:*:*"},
+			}}},
+			Status: StatusFixed,
+		},
+		{
+			ID:            "older-4",
+			Vulnerability: Vulnerability{Name: "CVE-2023-0004"},
+			Products: []Product{{Component: Component{
+				Hashes: map[Algorithm]Hash{SHA256: "e1a9a5c4f3f6c2a8b1d1c9ec2c5b6e8f0a9b4e2b8c3d1f2a6e7b9c0d1e2f3a4b"},
+			}}},
+			Status: StatusUnderInvestigation,
+		},
+	}
+
+	newer := New()
+	newer.Timestamp = &now
+	newer.Statements = []Statement{
+		{
+			ID:            "newer-1",
+			Vulnerability: Vulnerability{Name: "CVE-2023-0001"},
+			Products:      []Product{{Component: Component{ID: "pkg:apk/wolfi/git@2.41.0-r0"}}},
+			Status:        StatusNotAffected,
+			Justification: VulnerableCodeNotPresent,
+		},
+	}
+
+	engine := NewSuppressionEngine(&older, nil, &newer)
+	for m, tc := range map[string]struct {
+		vuln       string
+		product    string
+		suppressed bool
+		statement  string
+	}{
+		"latest statement across documents": {"CVE-2023-0001", "pkg:apk/wolfi/git@2.41.0-r0", true, "newer-1"},
+		"generic purl":                      {"CVE-2023-0002", "pkg:apk/wolfi/git@2.41.0-r0?arch=x86_64", true, "older-2"},
+		"alias":                             {"GHSA-xxxx-yyyy-zzzz", "pkg:apk/wolfi/git@2.41.0-r0", true, "older-2"},
+		"vulnerability iri":                 {"https://nvd.nist.gov/vuln/detail/CVE-2023-0002", "pkg:apk/wolfi/git@2.41.0-r0", true, "older-2"},
+		"cpe":                               {"CVE-2023-0003", "cpe:2.3:a:openssl:openssl:3.1.2:*:*:*:*:*:*:*", true, "older-3"},
+		"cpe out of range":                  {"CVE-2023-0003", "cpe:2.3:a:openssl:openssl:3.0.2:*:*:*:*:*:*:*", false, ""},
+		"hash":                              {"CVE-2023-0004", "e1a9a5c4f3f6c2a8b1d1c9ec2c5b6e8f0a9b4e2b8c3d1f2a6e7b9c0d1e2f3a4b", false, "older-4"},
+		"other version":                     {"CVE-2023-0001", "pkg:apk/wolfi/git@2.42.0-r0", false, ""},
+		"other package":                     {"CVE-2023-0002", "pkg:apk/wolfi/curl@8.1.0", false, ""},
+		"unknown vulnerability":             {"CVE-2023-9999", "pkg:apk/wolfi/git@2.41.0-r0", false, ""},
+	} {
+		suppressed, stmt := engine.Suppress(tc.vuln, tc.product)
+		require.Equal(t, tc.suppressed, suppressed, m)
+		if tc.statement == "" {
+			require.Nil(t, stmt, m)
+			continue
+		}
+		require.NotNil(t, stmt, m)
+		require.Equal(t, tc.statement, stmt.ID, m)
+	}
+}
+
+func TestSuppressionEngineSameTimestamp(t *testing.T) {
+	// Statements with the same timestamp resolve to the last one, like
+	// EffectiveStatement does.
+	doc := New()
+	now := time.Now()
+	doc.Timestamp = &now
+	doc.Statements = []Statement{
+		{
+			ID:            "first",
+			Vulnerability: Vulnerability{Name: "CVE-2023-0001"},
+			Products:      []Product{{Component: Component{ID: "pkg:oci/wolfi-base"}}},
+			Status:        StatusNotAffected,
+			Justification: ComponentNotPresent,
+		},
+		{
+			ID:            "second",
+			Vulnerability: Vulnerability{Name: "CVE-2023-0001"},
+			Products:      []Product{{Component: Component{ID: "pkg:oci/wolfi-base"}}},
+			Status:        StatusAffected,
+		},
+	}
+
+	suppressed, stmt := NewSuppressionEngine(&doc).Suppress("CVE-2023-0001", "pkg:oci/wolfi-base")
+	require.False(t, suppressed)
+	require.NotNil(t, stmt)
+	require.Equal(t, "second", stmt.ID)
+}
+
+func TestSuppressionEngineWildcard(t *testing.T) {
+	// A wildcard statement from another author does not suppress findings
+	// on products it did not make
+	ts := time.Date(2023, 4, 17, 20, 34, 58, 0, time.UTC)
+	thirdParty := &VEX{
+		Metadata: Metadata{ID: "https://example.com/vex-1", Author: "Someone Else", Timestamp: &ts},
+		Statements: []Statement{{
+			Vulnerability: Vulnerability{Name: "CVE-2023-0001"},
+			Products:      []Product{{Component: Component{ID: WildcardProduct}}},
+			Status:        StatusNotAffected,
+			Justification: ComponentNotPresent,
+		}},
+	}
+
+	suppressed, stmt := NewSuppressionEngine(thirdParty).Suppress("CVE-2023-0001", "pkg:oci/wolfi-base")
+	require.False(t, suppressed)
+	require.Nil(t, stmt)
+	require.Empty(t, thirdParty.Matches("CVE-2023-0001", "pkg:oci/wolfi-base", nil))
+
+	// Wildcards only match when enabled
+	matches := thirdParty.MatchesWithOptions("CVE-2023-0001", "pkg:oci/wolfi-base", nil, &MatchOptions{Wildcards: true})
+	require.Len(t, matches, 1)
+	require.Equal(t, MatchByWildcard, matches[0].ProductMatch.Kind)
+}
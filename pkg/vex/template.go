@@ -0,0 +1,167 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/package-url/packageurl-go"
+)
+
+// TemplateOptions define the templates used to fill the texts of generated
+// statements. Templates use the text/template syntax and are executed with
+// a TemplateData value, eg:
+//
+//	"{{ .ProductName }} is not affected by {{ .Vulnerability }}"
+type TemplateOptions struct {
+	// ImpactStatement is the template of the impact statement of
+	// not_affected statements.
+	ImpactStatement string
+
+	// ActionStatement is the template of the action statement of affected
+	// statements.
+	ActionStatement string
+
+	// Variables are caller defined values exposed to the templates as .Vars
+	Variables map[string]string
+
+	// StatementVariables is an optional function returning variables
+	// specific to a statement, eg the version fixing the vulnerability. Its
+	// values override those in Variables.
+	StatementVariables func(stmt *Statement) map[string]string
+
+	// Overwrite replaces the existing texts of the statements. When false,
+	// only empty texts are filled.
+	Overwrite bool
+}
+
+// TemplateData is the data available to the statement templates.
+type TemplateData struct {
+	// Vulnerability is the name of the statement vulnerability
+	Vulnerability string
+
+	// Aliases lists the vulnerability aliases
+	Aliases []string
+
+	// Product is the identifier of the first product in the statement
+	Product string
+
+	// ProductName and ProductVersion are read from the product purl. When
+	// the product is not identified by a purl, ProductName is the product
+	// identifier and ProductVersion is empty.
+	ProductName    string
+	ProductVersion string
+
+	// Status and Justification of the statement
+	Status        string
+	Justification string
+
+	// Vars holds the variables defined by the caller
+	Vars map[string]string
+}
+
+// ApplyTemplates fills the impact and action statements of the document
+// statements by executing the templates in the options. Templates are only
+// executed for the statements where the field applies: impact statements for
+// not_affected statements and action statements for affected ones.
+func (vexDoc *VEX) ApplyTemplates(opts *TemplateOptions) error {
+	if opts == nil {
+		return errors.New("no template options specified")
+	}
+
+	impact, err := parseTemplate("impact_statement", opts.ImpactStatement)
+	if err != nil {
+		return err
+	}
+	action, err := parseTemplate("action_statement", opts.ActionStatement)
+	if err != nil {
+		return err
+	}
+
+	for i := range vexDoc.Statements {
+		stmt := &vexDoc.Statements[i]
+
+		var tmpl *template.Template
+		var field *string
+		switch stmt.Status {
+		case StatusNotAffected:
+			tmpl, field = impact, &stmt.ImpactStatement
+		case StatusAffected:
+			tmpl, field = action, &stmt.ActionStatement
+		default:
+			continue
+		}
+
+		if tmpl == nil || (*field != "" && *field != NoActionStatementMsg && !opts.Overwrite) {
+			continue
+		}
+
+		var sb strings.Builder
+		if err := tmpl.Execute(&sb, templateData(stmt, opts)); err != nil {
+			return fmt.Errorf("executing %s template for statement #%d: %w", tmpl.Name(), i, err)
+		}
+		*field = sb.String()
+	}
+	return nil
+}
+
+// parseTemplate parses a statement template. Empty templates return nil.
+func parseTemplate(name, text string) (*template.Template, error) {
+	if text == "" {
+		return nil, nil
+	}
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s template: %w", name, err)
+	}
+	return tmpl, nil
+}
+
+// templateData builds the data passed to the templates for a statement.
+func templateData(stmt *Statement, opts *TemplateOptions) *TemplateData {
+	data := &TemplateData{
+		Vulnerability: string(stmt.Vulnerability.Name),
+		Aliases:       []string{},
+		Status:        string(stmt.Status),
+		Justification: string(stmt.Justification),
+		Vars:          map[string]string{},
+	}
+	if data.Vulnerability == "" {
+		data.Vulnerability = stmt.Vulnerability.ID
+	}
+	for _, a := range stmt.Vulnerability.Aliases {
+		data.Aliases = append(data.Aliases, string(a))
+	}
+
+	if len(stmt.Products) > 0 {
+		p := &stmt.Products[0]
+		data.Product = p.ID
+		if data.Product == "" {
+			data.Product = p.Identifiers[PURL]
+		}
+		data.ProductName = data.Product
+
+		purl := p.Identifiers[PURL]
+		if purl == "" {
+			purl = p.ID
+		}
+		if parsed, err := packageurl.FromString(purl); err == nil && strings.HasPrefix(purl, "pkg:") {
+			data.ProductName = parsed.Name
+			data.ProductVersion = parsed.Version
+		}
+	}
+
+	for k, v := range opts.Variables {
+		data.Vars[k] = v
+	}
+	if opts.StatementVariables != nil {
+		for k, v := range opts.StatementVariables(stmt) {
+			data.Vars[k] = v
+		}
+	}
+	return data
+}
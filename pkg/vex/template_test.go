@@ -0,0 +1,71 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyTemplates(t *testing.T) {
+	newDoc := func() *VEX {
+		doc := New()
+		doc.Statements = []Statement{
+			{
+				Vulnerability: Vulnerability{Name: "CVE-2023-1234"},
+				Products:      []Product{{Component: Component{ID: "pkg:apk/wolfi/curl@8.1.0-r0"}}},
+				Status:        StatusNotAffected,
+				Justification: VulnerableCodeNotInExecutePath,
+			},
+			{
+				Vulnerability: Vulnerability{Name: "CVE-2023-5678"},
+				Products:      []Product{{Component: Component{ID: "pkg:apk/wolfi/curl@8.1.0-r0"}}},
+				Status:        StatusAffected,
+			},
+			{
+				Vulnerability:   Vulnerability{Name: "CVE-2023-9999"},
+				Products:        []Product{{Component: Component{ID: "my-product"}}},
+				Status:          StatusAffected,
+				ActionStatement: "Existing text",
+			},
+			{
+				Vulnerability: Vulnerability{Name: "CVE-2023-0000"},
+				Products:      []Product{{Component: Component{ID: "my-product"}}},
+				Status:        StatusFixed,
+			},
+		}
+		return &doc
+	}
+
+	opts := &TemplateOptions{
+		ImpactStatement: "{{ .ProductName }} {{ .ProductVersion }} does not call the code affected by {{ .Vulnerability }}",
+		ActionStatement: "Update {{ .ProductName }} to {{ .Vars.fixed }} to fix {{ .Vulnerability }} ({{ .Vars.team }})",
+		Variables:       map[string]string{"team": "PSIRT", "fixed": "latest"},
+		StatementVariables: func(stmt *Statement) map[string]string {
+			if stmt.Vulnerability.Name == "CVE-2023-5678" {
+				return map[string]string{"fixed": "8.4.0-r0"}
+			}
+			return nil
+		},
+	}
+
+	doc := newDoc()
+	require.NoError(t, doc.ApplyTemplates(opts))
+	require.Equal(t, "curl 8.1.0-r0 does not call the code affected by CVE-2023-1234", doc.Statements[0].ImpactStatement)
+	require.Equal(t, "Update curl to 8.4.0-r0 to fix CVE-2023-5678 (PSIRT)", doc.Statements[1].ActionStatement)
+	require.Equal(t, "Existing text", doc.Statements[2].ActionStatement)
+	require.Empty(t, doc.Statements[3].ActionStatement)
+	require.Empty(t, doc.Statements[3].ImpactStatement)
+
+	opts.Overwrite = true
+	doc = newDoc()
+	require.NoError(t, doc.ApplyTemplates(opts))
+	require.Equal(t, "Update my-product to latest to fix CVE-2023-9999 (PSIRT)", doc.Statements[2].ActionStatement)
+
+	// Errors
+	require.Error(t, newDoc().ApplyTemplates(nil))
+	require.Error(t, newDoc().ApplyTemplates(&TemplateOptions{ImpactStatement: "{{ .Broken"}))
+	require.Error(t, newDoc().ApplyTemplates(&TemplateOptions{ActionStatement: "{{ .Vars.missing }}"}))
+}
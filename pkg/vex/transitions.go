@@ -0,0 +1,170 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/package-url/packageurl-go"
+)
+
+// TransitionError is a problem found in a statement when checking the
+// status timeline of a document.
+type TransitionError struct {
+	// Statement is the index of the offending statement in the document
+	Statement int
+
+	// Key names the vulnerability and product of the timeline, it is empty
+	// for problems with the statement fields.
+	Key string
+
+	// From and To are the statuses of the transition, empty for problems
+	// with the statement fields.
+	From Status
+	To   Status
+
+	// Message describes the problem
+	Message string
+}
+
+// Error returns the statement and the description of the problem.
+func (e *TransitionError) Error() string {
+	if e.Key == "" {
+		return fmt.Sprintf("statement #%d: %s", e.Statement, e.Message)
+	}
+	return fmt.Sprintf("statement #%d: %s: %s -> %s: %s", e.Statement, e.Key, e.From, e.To, e.Message)
+}
+
+// StatusTransitionError is returned by ValidateStatusTransitions. It lists
+// all the problems found in the document.
+type StatusTransitionError struct {
+	Errors []*TransitionError
+}
+
+// Error returns the messages of all the problems.
+func (e *StatusTransitionError) Error() string {
+	msgs := []string{}
+	for _, err := range e.Errors {
+		msgs = append(msgs, err.Error())
+	}
+	return "inconsistent status timeline: " + strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the problems.
+func (e *StatusTransitionError) Unwrap() []error {
+	ret := []error{}
+	for _, err := range e.Errors {
+		ret = append(ret, err)
+	}
+	return ret
+}
+
+// ValidateStatusTransitions checks the consistency of the status timeline
+// of each vulnerability in each product of the document. It reports:
+//
+//   - Statements lacking the fields required by their status, or setting
+//     fields that don't apply to it, as checked by Statement.Validate.
+//   - Versions of a product (a purl or CPE with a version, or an artifact
+//     identified by its hashes) that go from fixed back to affected or
+//     under_investigation. A fix applies to the product version, so a new
+//     assessment needs a statement about a new version.
+//   - Statements with the same timestamp that assign different statuses to
+//     the vulnerability in the product, leaving the VEX history ambiguous.
+//
+// Statements inherit the document timestamp when they have none. If any
+// problems are found, the returned error is a *StatusTransitionError
+// listing all of them.
+func (vexDoc *VEX) ValidateStatusTransitions() error {
+	errs := []*TransitionError{}
+
+	type entry struct {
+		index int
+		stmt  *Statement
+		time  time.Time // Zero when neither statement nor document have one
+	}
+	timelines := map[string][]entry{}
+	versioned := map[string]bool{}
+	for i := range vexDoc.Statements {
+		stmt := &vexDoc.Statements[i]
+		var ts time.Time
+		switch {
+		case stmt.Timestamp != nil:
+			ts = *stmt.Timestamp
+		case vexDoc.Timestamp != nil:
+			ts = *vexDoc.Timestamp
+		}
+		if err := stmt.Validate(); err != nil {
+			errs = append(errs, &TransitionError{Statement: i, Message: err.Error()})
+		}
+		for j := range stmt.Products {
+			key := conflictKey(stmt, &stmt.Products[j])
+			timelines[key] = append(timelines[key], entry{index: i, stmt: stmt, time: ts})
+			versioned[key] = stmt.Products[j].isVersioned()
+		}
+	}
+
+	keys := []string{}
+	for key := range timelines {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		timeline := timelines[key]
+		sort.SliceStable(timeline, func(i, j int) bool {
+			return timeline[i].time.Before(timeline[j].time)
+		})
+
+		fixed := timeline[0].stmt.Status == StatusFixed
+		for i := 1; i < len(timeline); i++ {
+			prev, cur := timeline[i-1].stmt, timeline[i].stmt
+			if prev.Status == cur.Status {
+				continue
+			}
+			fail := func(msg string) {
+				errs = append(errs, &TransitionError{
+					Statement: timeline[i].index, Key: key, From: prev.Status, To: cur.Status, Message: msg,
+				})
+			}
+
+			switch {
+			case !timeline[i].time.IsZero() && timeline[i].time.Equal(timeline[i-1].time):
+				fail("statements with the same timestamp set different statuses")
+			case fixed && versioned[key] && (cur.Status == StatusAffected || cur.Status == StatusUnderInvestigation):
+				fail("a fixed product version cannot be affected again, issue a statement about a new version")
+			}
+			fixed = fixed || cur.Status == StatusFixed
+		}
+	}
+
+	if len(errs) > 0 {
+		sort.SliceStable(errs, func(i, j int) bool { return errs[i].Statement < errs[j].Statement })
+		return &StatusTransitionError{Errors: errs}
+	}
+	return nil
+}
+
+// isVersioned returns true if the product identifies a single version of
+// the software: it has hashes or a purl or CPE naming a version.
+func (p *Product) isVersioned() bool {
+	if len(p.Hashes) > 0 {
+		return true
+	}
+	for _, id := range []string{p.ID, p.Identifiers[PURL], p.Identifiers[CPE23], p.Identifiers[CPE22]} {
+		switch {
+		case strings.HasPrefix(id, "pkg:"):
+			if purl, err := packageurl.FromString(id); err == nil && purl.Version != "" {
+				return true
+			}
+		case strings.HasPrefix(id, "cpe:"):
+			if name, err := parseCPE(id); err == nil && name[cpeVersion] != "*" && name[cpeVersion] != "-" {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,137 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateStatusTransitions(t *testing.T) {
+	t0 := time.Date(2023, 1, 8, 18, 2, 3, 0, time.UTC)
+	at := func(h int) *time.Time {
+		ts := t0.Add(time.Duration(h) * time.Hour)
+		return &ts
+	}
+	stmt := func(product string, status Status, ts *time.Time) Statement {
+		s := Statement{
+			Vulnerability: Vulnerability{Name: "CVE-2023-1234"},
+			Products:      []Product{{Component: Component{ID: product}}},
+			Status:        status,
+			Timestamp:     ts,
+		}
+		switch status {
+		case StatusNotAffected:
+			s.Justification = ComponentNotPresent
+		case StatusAffected:
+			s.ActionStatement = "Update to the latest version"
+		case StatusFixed, StatusUnderInvestigation:
+		}
+		return s
+	}
+	versioned, unversioned := "pkg:apk/wolfi/git@2.41.0-r1", "pkg:apk/wolfi/git"
+
+	for m, tc := range map[string]struct {
+		statements []Statement
+		errors     []int // Index of the statements with problems
+	}{
+		"valid timeline": {
+			statements: []Statement{
+				stmt(versioned, StatusUnderInvestigation, at(0)),
+				stmt(versioned, StatusAffected, at(1)),
+				stmt(versioned, StatusFixed, at(2)),
+			},
+		},
+		"fixed to under investigation": {
+			statements: []Statement{
+				stmt(versioned, StatusFixed, at(0)),
+				stmt(versioned, StatusUnderInvestigation, at(1)),
+			},
+			errors: []int{1},
+		},
+		"fixed to affected after not affected": {
+			statements: []Statement{
+				stmt(versioned, StatusAffected, at(3)),
+				stmt(versioned, StatusFixed, at(1)),
+				stmt(versioned, StatusNotAffected, at(2)),
+			},
+			errors: []int{0},
+		},
+		"unversioned product regression": {
+			statements: []Statement{
+				stmt(unversioned, StatusFixed, at(0)),
+				stmt(unversioned, StatusAffected, at(1)),
+			},
+		},
+		"new version after fix": {
+			statements: []Statement{
+				stmt(versioned, StatusFixed, at(0)),
+				stmt("pkg:apk/wolfi/git@2.42.0-r0", StatusAffected, at(1)),
+			},
+		},
+		"same timestamp": {
+			statements: []Statement{
+				stmt(versioned, StatusAffected, at(0)),
+				stmt(versioned, StatusNotAffected, at(0)),
+			},
+			errors: []int{1},
+		},
+		"inherited timestamp": {
+			statements: []Statement{
+				stmt(versioned, StatusAffected, nil),
+				stmt(versioned, StatusNotAffected, &t0),
+			},
+			errors: []int{1},
+		},
+		"missing fields": {
+			statements: []Statement{
+				{
+					Vulnerability: Vulnerability{Name: "CVE-2023-1234"},
+					Products:      []Product{{Component: Component{ID: versioned}}},
+					Status:        StatusNotAffected,
+				},
+			},
+			errors: []int{0},
+		},
+	} {
+		doc := New()
+		doc.Timestamp = &t0
+		doc.Statements = tc.statements
+		err := doc.ValidateStatusTransitions()
+		if len(tc.errors) == 0 {
+			require.NoError(t, err, m)
+			continue
+		}
+
+		var transitionErr *StatusTransitionError
+		require.ErrorAs(t, err, &transitionErr, m)
+		indexes := []int{}
+		for _, e := range transitionErr.Errors {
+			indexes = append(indexes, e.Statement)
+		}
+		require.Equal(t, tc.errors, indexes, m)
+	}
+}
+
+func TestProductIsVersioned(t *testing.T) {
+	for m, tc := range map[string]struct {
+		product  Product
+		expected bool
+	}{
+		"purl with version":    {Product{Component: Component{ID: "pkg:apk/wolfi/git@2.41.0-r1"}}, true},
+		"purl without version": {Product{Component: Component{ID: "pkg:apk/wolfi/git"}}, false},
+		"cpe with version": {
+			Product{Component: Component{Identifiers: map[IdentifierType]string{CPE23: "cpe:2.3:a:git:git:2.41.0:*:*:*:*:*:*:*"}}}, true,
+		},
+		"cpe any version": {
+			Product{Component: Component{Identifiers: map[IdentifierType]string{CPE23: "cpe:2.3:a:git:git:*:*:*:*:*:*:*:*"}}}, false,
+		},
+		"hashes": {Product{Component: Component{Hashes: map[Algorithm]Hash{SHA1: "77d86e9752cb933569dfa1f693ee4338e65b28b4"}}}, true},
+		"iri":    {Product{Component: Component{ID: "https://example.com/product"}}, false},
+	} {
+		require.Equal(t, tc.expected, tc.product.isVersioned(), m)
+	}
+}
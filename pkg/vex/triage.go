@@ -0,0 +1,180 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Finding captures a vulnerability reported on a product, for example by a
+// security scanner. The subcomponent is optional and identifies the piece
+// of software in the product where the vulnerability was found.
+type Finding struct {
+	Vulnerability string
+	Product       string
+	Subcomponent  string
+}
+
+// TriageOptions control the document generated from scanner findings.
+type TriageOptions struct {
+	DocumentID string // ID to use in the new document
+	Author     string // Author to use in the new document
+	AuthorRole string // Role of the document author
+	Clock      Clock  // Sets the document timestamp, see NewWithOptions
+}
+
+// GenerateTriageDocument is a convenience wrapper over
+// GenerateTriageDocumentWithOptions that does not take options.
+func GenerateTriageDocument(findings []Finding, docs []*VEX) (*VEX, error) {
+	return GenerateTriageDocumentWithOptions(&TriageOptions{}, findings, docs)
+}
+
+// GenerateTriageDocumentWithOptions returns a new document with an
+// under_investigation statement for each finding that is not covered by
+// any of the statements in the existing documents. Findings of the same
+// vulnerability in the same product are grouped into a single statement
+// listing all their subcomponents.
+func GenerateTriageDocumentWithOptions(opts *TriageOptions, findings []Finding, docs []*VEX) (*VEX, error) {
+	newDoc := NewWithOptions(&DocumentOptions{Clock: opts.Clock})
+	if opts.Author != "" {
+		newDoc.Author = opts.Author
+	}
+	if opts.AuthorRole != "" {
+		newDoc.AuthorRole = opts.AuthorRole
+	}
+
+	// index records the position of the statement generated for each
+	// vulnerability and product pair.
+	index := map[string]int{}
+	seen := map[Finding]struct{}{}
+
+	for _, f := range findings {
+		if f.Vulnerability == "" || f.Product == "" {
+			return nil, errors.New("findings must specify a vulnerability and a product")
+		}
+
+		if _, ok := seen[f]; ok {
+			continue
+		}
+		seen[f] = struct{}{}
+
+		if findingCovered(f, docs) {
+			continue
+		}
+
+		key := fmt.Sprintf("%s:%s", f.Vulnerability, f.Product)
+		i, ok := index[key]
+		if !ok {
+			newDoc.Statements = append(newDoc.Statements, Statement{
+				Vulnerability: Vulnerability{Name: VulnerabilityID(f.Vulnerability)},
+				Products: []Product{
+					{Component: Component{ID: f.Product}},
+				},
+				Status: StatusUnderInvestigation,
+			})
+			i = len(newDoc.Statements) - 1
+			index[key] = i
+		}
+
+		if f.Subcomponent != "" {
+			newDoc.Statements[i].Products[0].Subcomponents = append(
+				newDoc.Statements[i].Products[0].Subcomponents,
+				Subcomponent{Component: Component{ID: f.Subcomponent}},
+			)
+		}
+	}
+
+	newDoc.ID = opts.DocumentID
+	if newDoc.ID == "" {
+		if _, err := newDoc.GenerateCanonicalID(); err != nil {
+			return nil, fmt.Errorf("generating document ID: %w", err)
+		}
+	}
+
+	return &newDoc, nil
+}
+
+// findingCovered returns true if any of the statements in the documents
+// applies to the finding.
+func findingCovered(f Finding, docs []*VEX) bool {
+	subcomponents := []string{}
+	if f.Subcomponent != "" {
+		subcomponents = append(subcomponents, f.Subcomponent)
+	}
+
+	for _, doc := range docs {
+		if len(doc.Matches(f.Vulnerability, f.Product, subcomponents)) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Coverage records the VEX data available for a vulnerability in a product.
+type Coverage struct {
+	// Vulnerability is the vulnerability as it was queried
+	Vulnerability string
+
+	// Status is the latest status of the vulnerability in the product. It is
+	// empty when there is no data about the vulnerability.
+	Status Status
+
+	// Statements are the statements that apply to the vulnerability and
+	// product sorted by their timestamp.
+	Statements []Statement
+}
+
+// HasData returns true if any statement applies to the vulnerability.
+func (c *Coverage) HasData() bool {
+	return len(c.Statements) > 0
+}
+
+// CoverageReport returns the coverage of each vulnerability in the product
+// by the documents. Vulnerabilities without any applicable statement are
+// returned with no status, telling them apart from those with an explicit
+// status. The report follows the order of the vulnerability list, skipping
+// duplicates.
+func CoverageReport(vulnIDs []string, product string, docs []*VEX) []Coverage {
+	ret := []Coverage{}
+	seen := map[string]struct{}{}
+	for _, id := range vulnIDs {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+
+		c := Coverage{Vulnerability: id, Statements: []Statement{}}
+		for _, doc := range docs {
+			stmts := doc.Matches(id, product, nil)
+			for i := range stmts {
+				// Cascade the document timestamp to sort across documents
+				if stmts[i].Timestamp == nil && doc.Timestamp != nil {
+					ts := *doc.Timestamp
+					stmts[i].Timestamp = &ts
+				}
+			}
+			c.Statements = append(c.Statements, stmts...)
+		}
+		if len(c.Statements) > 0 {
+			SortStatements(c.Statements, time.Time{})
+			c.Status = c.Statements[len(c.Statements)-1].Status
+		}
+		ret = append(ret, c)
+	}
+	return ret
+}
+
+// Uncovered returns the vulnerabilities in the list for which none of the
+// documents has a statement applying to the product.
+func Uncovered(vulnIDs []string, product string, docs []*VEX) []string {
+	ret := []string{}
+	for _, c := range CoverageReport(vulnIDs, product, docs) {
+		if !c.HasData() {
+			ret = append(ret, c.Vulnerability)
+		}
+	}
+	return ret
+}
@@ -0,0 +1,116 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateTriageDocument(t *testing.T) {
+	existing := &VEX{
+		Statements: []Statement{
+			{
+				Vulnerability: Vulnerability{Name: "CVE-2014-123456"},
+				Products:      []Product{{Component: Component{ID: "pkg:oci/alpine"}}},
+				Status:        StatusNotAffected,
+				Justification: ComponentNotPresent,
+			},
+		},
+	}
+
+	findings := []Finding{
+		// Covered by the existing document
+		{Vulnerability: "CVE-2014-123456", Product: "pkg:oci/alpine@sha256%3A124c7d27", Subcomponent: "pkg:apk/alpine/libssl3@3.0.8-r3"},
+		// Two subcomponents, same vulnerability and product
+		{Vulnerability: "CVE-2014-654321", Product: "pkg:oci/alpine@sha256%3A124c7d27", Subcomponent: "pkg:apk/alpine/libssl3@3.0.8-r3"},
+		{Vulnerability: "CVE-2014-654321", Product: "pkg:oci/alpine@sha256%3A124c7d27", Subcomponent: "pkg:apk/alpine/libcrypto3@3.0.8-r3"},
+		// Duplicate finding
+		{Vulnerability: "CVE-2014-654321", Product: "pkg:oci/alpine@sha256%3A124c7d27", Subcomponent: "pkg:apk/alpine/libcrypto3@3.0.8-r3"},
+		// No subcomponent
+		{Vulnerability: "CVE-2014-111111", Product: "pkg:oci/alpine@sha256%3A124c7d27"},
+	}
+
+	doc, err := GenerateTriageDocumentWithOptions(&TriageOptions{Author: "Triage Bot"}, findings, []*VEX{existing})
+	require.NoError(t, err)
+	require.Equal(t, "Triage Bot", doc.Author)
+	require.NotEmpty(t, doc.ID)
+	require.Len(t, doc.Statements, 2)
+
+	for _, s := range doc.Statements {
+		require.Equal(t, StatusUnderInvestigation, s.Status)
+		require.NoError(t, s.Validate())
+		require.Len(t, s.Products, 1)
+		switch s.Vulnerability.Name {
+		case "CVE-2014-654321":
+			require.Len(t, s.Products[0].Subcomponents, 2)
+		case "CVE-2014-111111":
+			require.Empty(t, s.Products[0].Subcomponents)
+		default:
+			t.Fatalf("unexpected statement for %s", s.Vulnerability.Name)
+		}
+	}
+
+	_, err = GenerateTriageDocument([]Finding{{Vulnerability: "CVE-2014-111111"}}, nil)
+	require.Error(t, err)
+}
+
+func TestCoverageReport(t *testing.T) {
+	t1 := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	t2 := t1.Add(time.Hour)
+	product := "pkg:oci/alpine@sha256%3A124c7d27"
+	docs := []*VEX{
+		{
+			Metadata: Metadata{Timestamp: &t2},
+			Statements: []Statement{
+				{
+					Vulnerability: Vulnerability{Name: "CVE-2014-123456"},
+					Products:      []Product{{Component: Component{ID: "pkg:oci/alpine"}}},
+					Status:        StatusNotAffected,
+					Justification: ComponentNotPresent,
+				},
+			},
+		},
+		{
+			Metadata: Metadata{Timestamp: &t1},
+			Statements: []Statement{
+				{
+					Vulnerability: Vulnerability{Name: "CVE-2014-123456"},
+					Products:      []Product{{Component: Component{ID: product}}},
+					Status:        StatusUnderInvestigation,
+				},
+				{
+					Vulnerability: Vulnerability{Name: "CVE-2014-654321"},
+					Products:      []Product{{Component: Component{ID: product}}},
+					Status:        StatusAffected,
+				},
+				{
+					Vulnerability: Vulnerability{Name: "CVE-2014-111111"},
+					Products:      []Product{{Component: Component{ID: "pkg:oci/debian"}}},
+					Status:        StatusFixed,
+				},
+			},
+		},
+	}
+
+	vulns := []string{"CVE-2014-123456", "CVE-2014-654321", "CVE-2014-111111", "CVE-2014-222222", "CVE-2014-111111"}
+	report := CoverageReport(vulns, product, docs)
+	require.Len(t, report, 4)
+
+	// The latest statement across documents sets the status
+	require.Equal(t, StatusNotAffected, report[0].Status)
+	require.Len(t, report[0].Statements, 2)
+	require.Equal(t, StatusAffected, report[1].Status)
+	require.True(t, report[1].HasData())
+
+	// Vulnerabilities without statements for the product have no data
+	for _, c := range report[2:] {
+		require.False(t, c.HasData(), c.Vulnerability)
+		require.Empty(t, c.Status)
+	}
+
+	require.Equal(t, []string{"CVE-2014-111111", "CVE-2014-222222"}, Uncovered(vulns, product, docs))
+}
@@ -0,0 +1,83 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+// Update returns the next version of the document with the new statements.
+// See UpdateWithOptions.
+func (vexDoc *VEX) Update(newStatements ...Statement) *VEX {
+	return vexDoc.UpdateWithOptions(nil, newStatements...)
+}
+
+// DryRunUpdate computes the changes UpdateWithOptions would make to the
+// document and returns them as a diff of the document against its next
+// version, without returning the new version. Use it to review updates
+// before publishing them.
+func (vexDoc *VEX) DryRunUpdate(opts *DocumentOptions, newStatements ...Statement) *DocumentDiff {
+	return Diff(vexDoc, vexDoc.UpdateWithOptions(opts, newStatements...))
+}
+
+// UpdateWithOptions returns the next version of the document with the new
+// statements, following the update semantics of the OpenVEX spec. The
+// original document is not modified.
+//
+// The new version keeps the @id and timestamp of the document, increments
+// its version and sets its last_updated date to the time of the options
+// clock (or, when nil, to the time in SOURCE_DATE_EPOCH or the current time).
+//
+// A new statement with the same @id as an existing one supersedes it: it
+// replaces the existing statement, keeps its original timestamp if it does
+// not define one, and gets the update time as its last_updated date. The
+// rest of the new statements are appended to the document, timestamped
+// with the update time when they have no timestamp so that they sort after
+// the statements they update. Existing statements are kept to preserve the
+// history of the document.
+//
+// The previous_digest field is cleared, use LinkPrevious to chain the new
+// version to the original document.
+func (vexDoc *VEX) UpdateWithOptions(opts *DocumentOptions, newStatements ...Statement) *VEX {
+	now := documentTime(opts.clock())
+
+	doc := &VEX{
+		Metadata:   vexDoc.Metadata,
+		Statements: make([]Statement, 0, len(vexDoc.Statements)+len(newStatements)),
+	}
+	if vexDoc.Timestamp != nil {
+		ts := *vexDoc.Timestamp
+		doc.Timestamp = &ts
+	}
+	doc.Version++
+	doc.LastUpdated = &now
+	doc.PreviousDigest = ""
+
+	byID := map[string]int{}
+	for i := range vexDoc.Statements {
+		doc.Statements = append(doc.Statements, *vexDoc.Statements[i].DeepCopy())
+		if id := vexDoc.Statements[i].ID; id != "" {
+			byID[id] = i
+		}
+	}
+
+	for i := range newStatements {
+		stmt := newStatements[i].DeepCopy()
+		if j, ok := byID[stmt.ID]; ok && stmt.ID != "" {
+			if stmt.Timestamp == nil {
+				stmt.Timestamp = doc.Statements[j].Timestamp
+			}
+			if stmt.LastUpdated == nil {
+				stmt.LastUpdated = &now
+			}
+			doc.Statements[j] = *stmt
+			continue
+		}
+
+		if stmt.Timestamp == nil {
+			stmt.Timestamp = &now
+		}
+		if stmt.ID != "" {
+			byID[stmt.ID] = len(doc.Statements)
+		}
+		doc.Statements = append(doc.Statements, *stmt)
+	}
+	return doc
+}
@@ -0,0 +1,132 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateWithOptions(t *testing.T) {
+	issued := time.Date(2023, 6, 1, 10, 0, 0, 0, time.UTC)
+	stmtTime := issued.Add(time.Hour)
+	updated := issued.Add(48 * time.Hour)
+
+	doc := NewWithOptions(&DocumentOptions{Clock: FixedClock(issued)})
+	doc.ID = "https://example.com/vex/1"
+	doc.PreviousDigest = "sha-256:0000"
+	doc.Statements = []Statement{
+		{
+			ID:            "https://example.com/vex/1#git",
+			Vulnerability: Vulnerability{Name: "CVE-2023-0001"},
+			Products:      []Product{{Component: Component{ID: "pkg:apk/wolfi/git@2.41.0-r0"}}},
+			Status:        StatusUnderInvestigation,
+			Timestamp:     &stmtTime,
+		},
+		{
+			Vulnerability: Vulnerability{Name: "CVE-2023-0002"},
+			Products:      []Product{{Component: Component{ID: "pkg:apk/wolfi/git@2.41.0-r0"}}},
+			Status:        StatusAffected,
+		},
+	}
+
+	newDoc := doc.UpdateWithOptions(&DocumentOptions{Clock: FixedClock(updated)},
+		Statement{
+			ID:            "https://example.com/vex/1#git",
+			Vulnerability: Vulnerability{Name: "CVE-2023-0001"},
+			Products:      []Product{{Component: Component{ID: "pkg:apk/wolfi/git@2.41.0-r0"}}},
+			Status:        StatusNotAffected,
+			Justification: VulnerableCodeNotPresent,
+		},
+		Statement{
+			Vulnerability: Vulnerability{Name: "CVE-2023-0002"},
+			Products:      []Product{{Component: Component{ID: "pkg:apk/wolfi/git@2.41.0-r0"}}},
+			Status:        StatusFixed,
+		},
+	)
+
+	// Document metadata
+	require.Equal(t, doc.ID, newDoc.ID)
+	require.Equal(t, 2, newDoc.Version)
+	require.Equal(t, issued, *newDoc.Timestamp)
+	require.Equal(t, updated, *newDoc.LastUpdated)
+	require.Empty(t, newDoc.PreviousDigest)
+
+	// The statement with the same ID is superseded
+	require.Len(t, newDoc.Statements, 3)
+	require.Equal(t, StatusNotAffected, newDoc.Statements[0].Status)
+	require.Equal(t, stmtTime, *newDoc.Statements[0].Timestamp)
+	require.Equal(t, updated, *newDoc.Statements[0].LastUpdated)
+
+	// Statements without IDs are appended after the ones they update
+	require.Equal(t, StatusAffected, newDoc.Statements[1].Status)
+	require.Equal(t, StatusFixed, newDoc.Statements[2].Status)
+	require.Equal(t, updated, *newDoc.Statements[2].Timestamp)
+	require.Equal(t, StatusFixed, newDoc.EffectiveStatement("pkg:apk/wolfi/git@2.41.0-r0", "CVE-2023-0002").Status)
+
+	// The original document is not modified
+	require.Equal(t, 1, doc.Version)
+	require.Nil(t, doc.LastUpdated)
+	require.Equal(t, "sha-256:0000", doc.PreviousDigest)
+	require.Len(t, doc.Statements, 2)
+	require.Equal(t, StatusUnderInvestigation, doc.Statements[0].Status)
+
+	// Versions chain to their predecessor
+	require.NoError(t, newDoc.LinkPrevious(&doc, SHA256))
+	require.NoError(t, VerifyChain([]*VEX{&doc, newDoc}))
+}
+
+func TestUpdate(t *testing.T) {
+	t.Setenv("SOURCE_DATE_EPOCH", "1700000000")
+	doc := New()
+	newDoc := doc.Update()
+	require.Equal(t, doc.Version+1, newDoc.Version)
+	require.Equal(t, time.Unix(1700000000, 0).UTC(), newDoc.LastUpdated.UTC())
+	require.Empty(t, newDoc.Statements)
+}
+
+func TestDryRunUpdate(t *testing.T) {
+	issued := time.Date(2023, 6, 1, 10, 0, 0, 0, time.UTC)
+	doc := NewWithOptions(&DocumentOptions{Clock: FixedClock(issued)})
+	doc.ID = "https://example.com/vex/1"
+	doc.Statements = []Statement{
+		{
+			ID:            "https://example.com/vex/1#git",
+			Vulnerability: Vulnerability{Name: "CVE-2023-0001"},
+			Products:      []Product{{Component: Component{ID: "pkg:apk/wolfi/git@2.41.0-r0"}}},
+			Status:        StatusUnderInvestigation,
+		},
+	}
+
+	diff := doc.DryRunUpdate(&DocumentOptions{Clock: FixedClock(issued.Add(time.Hour))},
+		Statement{
+			ID:            "https://example.com/vex/1#git",
+			Vulnerability: Vulnerability{Name: "CVE-2023-0001"},
+			Products:      []Product{{Component: Component{ID: "pkg:apk/wolfi/git@2.41.0-r0"}}},
+			Status:        StatusFixed,
+		},
+		Statement{
+			Vulnerability: Vulnerability{Name: "CVE-2023-0002"},
+			Products:      []Product{{Component: Component{ID: "pkg:apk/wolfi/git@2.41.0-r0"}}},
+			Status:        StatusFixed,
+		},
+	)
+	require.Len(t, diff.Changed, 1)
+	require.Equal(t, StatusFixed, diff.Changed[0].New.Status)
+	require.Len(t, diff.Added, 1)
+	require.Empty(t, diff.Removed)
+	fields := []string{}
+	for _, c := range diff.Metadata {
+		fields = append(fields, c.Field)
+	}
+	require.ElementsMatch(t, []string{"last_updated", "version"}, fields)
+
+	// The document is not modified
+	require.Equal(t, 1, doc.Version)
+	require.Nil(t, doc.LastUpdated)
+	require.Len(t, doc.Statements, 1)
+	require.Equal(t, StatusUnderInvestigation, doc.Statements[0].Status)
+}
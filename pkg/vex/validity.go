@@ -0,0 +1,111 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// AnnotationValidUntil is the statement annotation recording the time, in
+// RFC 3339 format, when the statement stops applying. The OpenVEX spec has
+// no validity window, so it is kept out of the statement fields.
+const AnnotationValidUntil = "openvex.dev/valid-until"
+
+// ValidUntil returns the end of the validity window of the statement, or nil
+// if the statement does not expire. It fails if the annotation is not a
+// valid RFC 3339 time.
+func (stmt *Statement) ValidUntil() (*time.Time, error) {
+	value, ok := stmt.Annotations[AnnotationValidUntil]
+	if !ok {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("parsing %s annotation: %w", AnnotationValidUntil, err)
+	}
+	return &t, nil
+}
+
+// SetValidUntil sets the time when the statement stops applying, eg for a
+// temporary mitigation. The statement applies from its timestamp until t.
+// Expired statements are left out when matching statements.
+func (stmt *Statement) SetValidUntil(t time.Time) {
+	if stmt.Annotations == nil {
+		stmt.Annotations = map[string]string{}
+	}
+	stmt.Annotations[AnnotationValidUntil] = t.UTC().Format(time.RFC3339Nano)
+}
+
+// Expired returns true if the statement has a validity window that ended
+// at or before t. Statements with an invalid validity annotation are
+// considered expired.
+func (stmt *Statement) Expired(t time.Time) bool {
+	until, err := stmt.ValidUntil()
+	if err != nil {
+		return true
+	}
+	return until != nil && !t.Before(*until)
+}
+
+// validateValidity checks that the validity annotation of the statement is
+// a valid time after the statement timestamp.
+func (stmt *Statement) validateValidity() error {
+	until, err := stmt.ValidUntil()
+	if err != nil {
+		return err
+	}
+	if until != nil && stmt.Timestamp != nil && !until.After(*stmt.Timestamp) {
+		return errors.New("statement validity ends before its timestamp")
+	}
+	return nil
+}
+
+// ValidAt returns true if the statement applies at time t, that is if it
+// was issued at or before t and has not expired. Statements without a
+// timestamp are dated with documentTimestamp, a zero documentTimestamp
+// means the statement date is unknown and is considered issued.
+func (stmt *Statement) ValidAt(t, documentTimestamp time.Time) bool {
+	issued := documentTimestamp
+	if stmt.Timestamp != nil {
+		issued = *stmt.Timestamp
+	}
+	if issued.After(t) {
+		return false
+	}
+	return !stmt.Expired(t)
+}
+
+// MatchesAt returns the statements of the document that apply to the
+// vulnerability and product at time t, sorted by date. Statements issued
+// after t or expired at t are left out, this answers what the status of a
+// product was as of a past date. The document is not modified.
+func (vexDoc *VEX) MatchesAt(t time.Time, vulnID, product string, subcomponents []string) []Statement {
+	var docTime time.Time
+	if vexDoc.Timestamp != nil {
+		docTime = *vexDoc.Timestamp
+	}
+
+	matches := []Statement{}
+	for i := range vexDoc.Statements {
+		stmt := &vexDoc.Statements[i]
+		if !stmt.ValidAt(t, docTime) || !stmt.matchesWithResolver(vulnID, product, subcomponents, nil) {
+			continue
+		}
+		matches = append(matches, *stmt.DeepCopy())
+	}
+	SortStatements(matches, docTime)
+	return matches
+}
+
+// EffectiveStatementAt returns the latest statement of the document that
+// applies to the vulnerability and product at time t, or nil if none does.
+func (vexDoc *VEX) EffectiveStatementAt(t time.Time, vulnID, product string) *Statement {
+	matches := vexDoc.MatchesAt(t, vulnID, product, nil)
+	if len(matches) == 0 {
+		return nil
+	}
+	return &matches[len(matches)-1]
+}
@@ -0,0 +1,156 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchesAt(t *testing.T) {
+	day := func(d int) *time.Time {
+		ts := time.Date(2023, 6, d, 0, 0, 0, 0, time.UTC)
+		return &ts
+	}
+
+	doc := New()
+	doc.Timestamp = day(1)
+	doc.Statements = []Statement{
+		{
+			Vulnerability: Vulnerability{Name: "CVE-2023-1234"},
+			Products:      []Product{{Component: Component{ID: "pkg:oci/app"}}},
+			Status:        StatusUnderInvestigation,
+		},
+		{
+			// Temporary mitigation until the fix ships
+			Vulnerability: Vulnerability{Name: "CVE-2023-1234"},
+			Products:      []Product{{Component: Component{ID: "pkg:oci/app"}}},
+			Status:        StatusNotAffected,
+			Justification: InlineMitigationsAlreadyExist,
+			Timestamp:     day(5),
+			Annotations:   map[string]string{AnnotationValidUntil: "2023-06-10T00:00:00Z"},
+		},
+		{
+			Vulnerability: Vulnerability{Name: "CVE-2023-1234"},
+			Products:      []Product{{Component: Component{ID: "pkg:oci/app"}}},
+			Status:        StatusFixed,
+			Timestamp:     day(20),
+		},
+	}
+
+	for m, tc := range map[string]struct {
+		at       time.Time
+		statuses []Status
+	}{
+		"before the document": {day(1).Add(-time.Second), []Status{}},
+		"document date":       {*day(1), []Status{StatusUnderInvestigation}},
+		"mitigated":           {*day(7), []Status{StatusUnderInvestigation, StatusNotAffected}},
+		"mitigation expired":  {*day(10), []Status{StatusUnderInvestigation}},
+		"fixed":               {*day(25), []Status{StatusUnderInvestigation, StatusFixed}},
+	} {
+		statuses := []Status{}
+		for _, stmt := range doc.MatchesAt(tc.at, "CVE-2023-1234", "pkg:oci/app", nil) {
+			statuses = append(statuses, stmt.Status)
+		}
+		require.Equal(t, tc.statuses, statuses, m)
+
+		effective := doc.EffectiveStatementAt(tc.at, "CVE-2023-1234", "pkg:oci/app")
+		if len(tc.statuses) == 0 {
+			require.Nil(t, effective, m)
+			continue
+		}
+		require.NotNil(t, effective, m)
+		require.Equal(t, tc.statuses[len(tc.statuses)-1], effective.Status, m)
+	}
+
+	require.Empty(t, doc.MatchesAt(*day(7), "CVE-2023-9999", "pkg:oci/app", nil))
+
+	// The document statements are not modified
+	require.Nil(t, doc.Statements[0].Timestamp)
+	require.Equal(t, StatusUnderInvestigation, doc.Statements[0].Status)
+}
+
+func TestStatementValidity(t *testing.T) {
+	ts := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+	until := ts.AddDate(0, 1, 0)
+	stmt := Statement{Timestamp: &ts}
+	stmt.SetValidUntil(until)
+
+	require.False(t, stmt.Expired(ts))
+	require.True(t, stmt.Expired(until))
+	require.False(t, (&Statement{}).Expired(until))
+
+	require.True(t, stmt.ValidAt(ts, time.Time{}))
+	require.False(t, stmt.ValidAt(ts.Add(-time.Second), time.Time{}))
+	require.False(t, stmt.ValidAt(until, time.Time{}))
+	require.True(t, (&Statement{}).ValidAt(ts, time.Time{}))
+	require.False(t, (&Statement{}).ValidAt(ts, until))
+
+	// The validity window is written as an annotation
+	data, err := json.Marshal(&stmt)
+	require.NoError(t, err)
+	require.NotContains(t, string(data), `"valid_until"`)
+	var parsed Statement
+	require.NoError(t, json.Unmarshal(data, &parsed))
+	parsedUntil, err := parsed.ValidUntil()
+	require.NoError(t, err)
+	require.NotNil(t, parsedUntil)
+	require.True(t, until.Equal(*parsedUntil))
+
+	// Invalid windows fail validation and the statement never applies
+	stmt.Annotations[AnnotationValidUntil] = "next week"
+	_, err = stmt.ValidUntil()
+	require.Error(t, err)
+	require.True(t, stmt.Expired(ts))
+	require.Error(t, stmt.validateValidity())
+
+	stmt.SetValidUntil(ts.Add(-time.Hour))
+	require.Error(t, stmt.validateValidity())
+}
+
+func TestExpiredStatements(t *testing.T) {
+	now := time.Now()
+	doc := New()
+	doc.Timestamp = &now
+	doc.Statements = []Statement{
+		{
+			Vulnerability: Vulnerability{Name: "CVE-2023-1234"},
+			Products:      []Product{{Component: Component{ID: "pkg:oci/app"}}},
+			Status:        StatusAffected,
+		},
+		{
+			Vulnerability: Vulnerability{Name: "CVE-2023-1234"},
+			Products:      []Product{{Component: Component{ID: "pkg:oci/app"}}},
+			Status:        StatusNotAffected,
+			Justification: InlineMitigationsAlreadyExist,
+		},
+	}
+	doc.Statements[1].SetValidUntil(now.Add(-time.Minute))
+
+	// Expired statements are left out of the default matching paths
+	require.Len(t, doc.Matches("CVE-2023-1234", "pkg:oci/app", nil), 1)
+	require.Equal(t, StatusAffected, doc.EffectiveStatement("pkg:oci/app", "CVE-2023-1234").Status)
+	suppressed, stmt := NewSuppressionEngine(&doc).Suppress("CVE-2023-1234", "pkg:oci/app")
+	require.False(t, suppressed)
+	require.Equal(t, StatusAffected, stmt.Status)
+	require.Len(t, doc.BuildIndex().Matches("CVE-2023-1234", "pkg:oci/app"), 1)
+	status, _ := NewStore(&doc).StatusOf("CVE-2023-1234", "pkg:oci/app")
+	require.Equal(t, StatusAffected, status)
+	require.Len(t, doc.MatchesWithOptions("CVE-2023-1234", "pkg:oci/app", nil, nil), 1)
+	query := []Query{{Vulnerability: "CVE-2023-1234", Product: "pkg:oci/app"}}
+	results, err := MatchAll(context.Background(), []*VEX{&doc}, query, nil)
+	require.NoError(t, err)
+	require.Equal(t, StatusAffected, results[0].Status)
+
+	// The clock in the match options sets the time they are evaluated at
+	opts := &MatchOptions{Clock: FixedClock(now.Add(-time.Hour))}
+	require.Len(t, doc.MatchesWithOptions("CVE-2023-1234", "pkg:oci/app", nil, opts), 2)
+	results, err = MatchAll(context.Background(), []*VEX{&doc}, query, &MatchAllOptions{MatchOptions: *opts})
+	require.NoError(t, err)
+	require.Equal(t, StatusNotAffected, results[0].Status)
+}
@@ -0,0 +1,315 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// VersQualifier is the purl qualifier holding a vers version range. Product
+// purls with this qualifier match any version contained in the range.
+const VersQualifier = "vers"
+
+// VersionConstraint is a single comparator and version of a version range.
+type VersionConstraint struct {
+	// Comparator is one of =, !=, <, <=, > or >=. Star constraints matching
+	// all versions have an empty comparator and version.
+	Comparator string
+	Version    string
+}
+
+// VersionRange is a version range expressed in the vers specification, for
+// example vers:apk/>=8.1.0|<8.2.0
+//
+// https://github.com/package-url/purl-spec/blob/master/VERSION-RANGE-SPEC.rst
+type VersionRange struct {
+	// Scheme is the versioning scheme of the range, usually the purl type.
+	Scheme      string
+	Constraints []VersionConstraint
+}
+
+// ParseVersionRange parses a vers string into a version range.
+func ParseVersionRange(vers string) (*VersionRange, error) {
+	vers = strings.ReplaceAll(vers, " ", "")
+	rest, ok := strings.CutPrefix(vers, "vers:")
+	if !ok {
+		return nil, errors.New("version range does not start with vers:")
+	}
+	scheme, constraints, ok := strings.Cut(rest, "/")
+	if !ok || scheme == "" {
+		return nil, errors.New("version range has no versioning scheme")
+	}
+	if constraints == "" {
+		return nil, errors.New("version range has no constraints")
+	}
+
+	vr := &VersionRange{Scheme: strings.ToLower(scheme)}
+	if constraints == "*" {
+		vr.Constraints = []VersionConstraint{{}}
+		return vr, nil
+	}
+
+	for _, c := range strings.Split(constraints, "|") {
+		constraint := VersionConstraint{Comparator: "="}
+		for _, comparator := range []string{"!=", "<=", ">=", "<", ">", "="} {
+			if v, ok := strings.CutPrefix(c, comparator); ok {
+				constraint.Comparator, c = comparator, v
+				break
+			}
+		}
+		if c == "" {
+			return nil, fmt.Errorf("constraint %q has no version", constraint.Comparator)
+		}
+		constraint.Version = c
+		vr.Constraints = append(vr.Constraints, constraint)
+	}
+	return vr, nil
+}
+
+// String returns the vers representation of the range.
+func (vr *VersionRange) String() string {
+	constraints := []string{}
+	for _, c := range vr.Constraints {
+		if c.Comparator == "" {
+			constraints = append(constraints, "*")
+			continue
+		}
+		comparator := c.Comparator
+		if comparator == "=" {
+			comparator = ""
+		}
+		constraints = append(constraints, comparator+c.Version)
+	}
+	return "vers:" + vr.Scheme + "/" + strings.Join(constraints, "|")
+}
+
+// Contains returns true if the version is in the range. Versions are compared
+// using the rules of the range versioning scheme, following the algorithm in
+// the vers specification.
+func (vr *VersionRange) Contains(version string) bool {
+	ranges := []VersionConstraint{}
+	excluded := false
+	for _, c := range vr.Constraints {
+		switch c.Comparator {
+		case "":
+			return true
+		case "=":
+			if compareVersions(vr.Scheme, version, c.Version) == 0 {
+				return true
+			}
+		case "!=":
+			if compareVersions(vr.Scheme, version, c.Version) == 0 {
+				return false
+			}
+			excluded = true
+		default:
+			ranges = append(ranges, c)
+		}
+	}
+
+	// A range of only != constraints contains all other versions
+	if len(ranges) == 0 {
+		return excluded
+	}
+
+	slices.SortStableFunc(ranges, func(a, b VersionConstraint) int {
+		return compareVersions(vr.Scheme, a.Version, b.Version)
+	})
+
+	if first := ranges[0]; isUpperBound(first.Comparator) && satisfies(vr.Scheme, version, first) {
+		return true
+	}
+	if last := ranges[len(ranges)-1]; !isUpperBound(last.Comparator) && satisfies(vr.Scheme, version, last) {
+		return true
+	}
+	for i := 0; i+1 < len(ranges); i++ {
+		lower, upper := ranges[i], ranges[i+1]
+		if isUpperBound(lower.Comparator) || !isUpperBound(upper.Comparator) {
+			continue
+		}
+		if satisfies(vr.Scheme, version, lower) && satisfies(vr.Scheme, version, upper) {
+			return true
+		}
+	}
+	return false
+}
+
+// isUpperBound returns true if the comparator is < or <=.
+func isUpperBound(comparator string) bool {
+	return comparator == "<" || comparator == "<="
+}
+
+// satisfies returns true if the version satisfies a range constraint.
+func satisfies(scheme, version string, c VersionConstraint) bool {
+	cmp := compareVersions(scheme, version, c.Version)
+	switch c.Comparator {
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	default:
+		return cmp == 0
+	}
+}
+
+// semverSchemes are the versioning schemes whose versions follow semver,
+// where prerelease versions sort before the release.
+var semverSchemes = map[string]struct{}{
+	"semver": {},
+	"npm":    {},
+	"golang": {},
+	"cargo":  {},
+	"nuget":  {},
+}
+
+// apkPrereleases rewrites the apk prerelease suffixes to sort before the
+// release.
+var apkPrereleases = strings.NewReplacer("_alpha", "~alpha", "_beta", "~beta", "_pre", "~pre", "_rc", "~rc")
+
+// compareVersions compares two versions of a versioning scheme. It returns
+// 0 if they are equal, a negative number if a is lower and a positive
+// number if a is higher than b.
+//
+// Versions are compared segment by segment, numeric segments are compared
+// as numbers and alphabetic ones lexically. Debian and RPM epochs are
+// honored and a tilde sorts before anything, even the end of the version.
+// In semver schemes prereleases sort before their release.
+func compareVersions(scheme, a, b string) int {
+	if a == b {
+		return 0
+	}
+
+	switch scheme {
+	case "apk":
+		return compareSegments(apkPrereleases.Replace(a), apkPrereleases.Replace(b))
+	case "deb", "rpm":
+		epochA, restA := splitEpoch(a)
+		epochB, restB := splitEpoch(b)
+		if epochA != epochB {
+			return epochA - epochB
+		}
+		return compareSegments(restA, restB)
+	}
+
+	if _, ok := semverSchemes[scheme]; ok {
+		a, b = strings.TrimPrefix(a, "v"), strings.TrimPrefix(b, "v")
+		a, _, _ = strings.Cut(a, "+")
+		b, _, _ = strings.Cut(b, "+")
+		coreA, preA, hasPreA := strings.Cut(a, "-")
+		coreB, preB, hasPreB := strings.Cut(b, "-")
+		if cmp := compareSegments(coreA, coreB); cmp != 0 {
+			return cmp
+		}
+		switch {
+		case hasPreA && !hasPreB:
+			return -1
+		case !hasPreA && hasPreB:
+			return 1
+		default:
+			return compareSegments(preA, preB)
+		}
+	}
+
+	return compareSegments(a, b)
+}
+
+// splitEpoch splits the epoch from a debian or rpm version.
+func splitEpoch(version string) (epoch int, rest string) {
+	e, rest, ok := strings.Cut(version, ":")
+	if !ok {
+		return 0, version
+	}
+	n, err := strconv.Atoi(e)
+	if err != nil {
+		return 0, version
+	}
+	return n, rest
+}
+
+// compareSegments compares two versions by splitting them into alternating
+// numeric and non-numeric segments. Separators are ignored except for the
+// tilde which sorts before everything.
+func compareSegments(a, b string) int {
+	for a != "" || b != "" {
+		// Tildes sort before anything, including the end of the string
+		tildeA, tildeB := strings.HasPrefix(a, "~"), strings.HasPrefix(b, "~")
+		switch {
+		case tildeA && tildeB:
+			a, b = a[1:], b[1:]
+			continue
+		case tildeA:
+			return -1
+		case tildeB:
+			return 1
+		}
+
+		a = strings.TrimLeft(a, ".-_+")
+		b = strings.TrimLeft(b, ".-_+")
+		if a == "" || b == "" {
+			switch {
+			case a == "" && b == "":
+				return 0
+			case a == "":
+				if strings.HasPrefix(b, "~") {
+					return 1
+				}
+				return -1
+			default:
+				if strings.HasPrefix(a, "~") {
+					return -1
+				}
+				return 1
+			}
+		}
+
+		var segA, segB string
+		segA, a = nextSegment(a)
+		segB, b = nextSegment(b)
+		numA, numB := isDigit(segA[0]), isDigit(segB[0])
+		switch {
+		case numA && numB:
+			segA = strings.TrimLeft(segA, "0")
+			segB = strings.TrimLeft(segB, "0")
+			if len(segA) != len(segB) {
+				return len(segA) - len(segB)
+			}
+			if cmp := strings.Compare(segA, segB); cmp != 0 {
+				return cmp
+			}
+		case numA:
+			return 1
+		case numB:
+			return -1
+		default:
+			if cmp := strings.Compare(segA, segB); cmp != 0 {
+				return cmp
+			}
+		}
+	}
+	return 0
+}
+
+// nextSegment returns the leading numeric or alphabetic segment of a version
+// and the rest of the string.
+func nextSegment(version string) (segment, rest string) {
+	digit := isDigit(version[0])
+	i := 1
+	for i < len(version) && isDigit(version[i]) == digit && !strings.ContainsRune(".-_+~", rune(version[i])) {
+		i++
+	}
+	return version[:i], version[i:]
+}
+
+// isDigit returns true if the byte is an ASCII digit.
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,95 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseVersionRange(t *testing.T) {
+	for m, tc := range map[string]struct {
+		vers      string
+		expected  *VersionRange
+		mustError bool
+	}{
+		"range": {
+			vers: "vers:apk/>=8.1.0|<8.2.0",
+			expected: &VersionRange{Scheme: "apk", Constraints: []VersionConstraint{
+				{Comparator: ">=", Version: "8.1.0"}, {Comparator: "<", Version: "8.2.0"},
+			}},
+		},
+		"equal and not equal": {
+			vers: "vers:npm/1.0.0|!=1.2.0",
+			expected: &VersionRange{Scheme: "npm", Constraints: []VersionConstraint{
+				{Comparator: "=", Version: "1.0.0"}, {Comparator: "!=", Version: "1.2.0"},
+			}},
+		},
+		"star":           {vers: "vers:deb/*", expected: &VersionRange{Scheme: "deb", Constraints: []VersionConstraint{{}}}},
+		"no prefix":      {vers: "apk/>=8.1.0", mustError: true},
+		"no scheme":      {vers: "vers:/>=8.1.0", mustError: true},
+		"no constraints": {vers: "vers:apk/", mustError: true},
+		"no version":     {vers: "vers:apk/>=", mustError: true},
+	} {
+		vr, err := ParseVersionRange(tc.vers)
+		if tc.mustError {
+			require.Error(t, err, m)
+			continue
+		}
+		require.NoError(t, err, m)
+		require.Equal(t, tc.expected, vr, m)
+		require.Equal(t, tc.vers, vr.String(), m)
+	}
+}
+
+func TestVersionRangeContains(t *testing.T) {
+	for m, tc := range map[string]struct {
+		vers     string
+		version  string
+		expected bool
+	}{
+		"in range":               {"vers:apk/>=8.1.0|<8.2.0", "8.1.5-r0", true},
+		"lower bound":            {"vers:apk/>=8.1.0|<8.2.0", "8.1.0", true},
+		"upper bound":            {"vers:apk/>=8.1.0|<8.2.0", "8.2.0", false},
+		"below range":            {"vers:apk/>=8.1.0|<8.2.0", "8.0.9", false},
+		"numeric segments":       {"vers:apk/<8.10.0", "8.9.0", true},
+		"apk revision":           {"vers:apk/<8.1.0-r2", "8.1.0-r10", false},
+		"apk prerelease":         {"vers:apk/<8.1.0", "8.1.0_rc1", true},
+		"all versions before":    {"vers:deb/<2.0", "1.9.9", true},
+		"deb epoch":              {"vers:deb/<2.0", "1:1.0", false},
+		"deb tilde":              {"vers:deb/>=2.0", "2.0~rc1", false},
+		"semver prerelease":      {"vers:golang/>=v1.2.0", "v1.2.0-rc.1", false},
+		"semver release":         {"vers:golang/>=v1.2.0|<v1.3.0", "v1.2.4", true},
+		"exact version":          {"vers:npm/1.0.0|2.0.0", "2.0.0", true},
+		"excluded version":       {"vers:npm/!=1.0.0", "1.0.0", false},
+		"not excluded version":   {"vers:npm/!=1.0.0", "1.0.1", true},
+		"star":                   {"vers:npm/*", "1.0.0", true},
+		"second interval":        {"vers:pypi/>=1.0|<1.5|>=2.0|<2.5", "2.1", true},
+		"between intervals":      {"vers:pypi/>=1.0|<1.5|>=2.0|<2.5", "1.7", false},
+		"open ended upper range": {"vers:pypi/<1.0|>=2.0", "3.0", true},
+	} {
+		vr, err := ParseVersionRange(tc.vers)
+		require.NoError(t, err, m)
+		require.Equal(t, tc.expected, vr.Contains(tc.version), m)
+	}
+}
+
+func TestPurlMatchesVersionRange(t *testing.T) {
+	for m, tc := range map[string]struct {
+		purl1    string
+		purl2    string
+		expected bool
+	}{
+		"version in range":     {"pkg:apk/wolfi/curl?vers=vers:apk%2F%3E%3D8.1.0%7C%3C8.2.0", "pkg:apk/wolfi/curl@8.1.2-r0", true},
+		"version out of range": {"pkg:apk/wolfi/curl?vers=vers:apk%2F%3E%3D8.1.0%7C%3C8.2.0", "pkg:apk/wolfi/curl@8.2.0-r0", false},
+		"no version":           {"pkg:apk/wolfi/curl?vers=vers:apk%2F%3E%3D8.1.0%7C%3C8.2.0", "pkg:apk/wolfi/curl", false},
+		"same range":           {"pkg:apk/wolfi/curl?vers=vers:apk%2F%3E%3D8.1.0", "pkg:apk/wolfi/curl?vers=vers:apk%2F%3E%3D8.1.0", true},
+		"other qualifiers":     {"pkg:apk/wolfi/curl?arch=x86_64&vers=vers:apk%2F%3C9", "pkg:apk/wolfi/curl@8.1.2-r0?arch=aarch64", false},
+		"invalid range":        {"pkg:apk/wolfi/curl?vers=8.1.0", "pkg:apk/wolfi/curl@8.1.0", false},
+		"different name":       {"pkg:apk/wolfi/curl?vers=vers:apk%2F%3C9", "pkg:apk/wolfi/git@8.1.0", false},
+	} {
+		require.Equal(t, tc.expected, PurlMatches(tc.purl1, tc.purl2), m)
+	}
+}
@@ -8,16 +8,16 @@ package vex
 import (
 	"crypto/sha256"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 	"os"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
 	"time"
-
-	"github.com/package-url/packageurl-go"
 )
 
 const (
@@ -96,18 +96,44 @@ type Metadata struct {
 
 	// Supplier is an optional field.
 	Supplier string `json:"supplier,omitempty"`
+
+	// PreviousDigest is an optional link to the previous version of the
+	// document. It holds the digest of the predecessor as returned by
+	// VEX.Digest, chaining the document history to make it tamper-evident.
+	// It extends the spec and is serialized as openvex.dev/previous_digest.
+	PreviousDigest string `json:"openvex.dev/previous_digest,omitempty"`
+
+	// Lang is an optional BCP 47 language tag identifying the language of
+	// the human readable text in the document, eg "en" or "de-DE". It
+	// extends the spec and is serialized as openvex.dev/lang.
+	Lang string `json:"openvex.dev/lang,omitempty"`
 }
 
 // New returns a new, initialized VEX document.
 func New() VEX {
-	now := time.Now()
-	t, err := DateFromEnv()
-	if err != nil {
-		slog.Warn(err.Error())
-	}
-	if t != nil {
-		now = *t
+	return NewWithOptions(nil)
+}
+
+// DocumentOptions configure the creation and update of documents.
+type DocumentOptions struct {
+	// Clock sets the timestamp of new documents and the last_updated date
+	// of updated ones. When nil, documents get the time set in
+	// SOURCE_DATE_EPOCH or, if unset, the current time.
+	Clock Clock
+}
+
+// clock returns the clock of the options, nil if they are not set.
+func (opts *DocumentOptions) clock() Clock {
+	if opts == nil {
+		return nil
 	}
+	return opts.Clock
+}
+
+// NewWithOptions returns a new, initialized VEX document timestamped with
+// the time of the options clock.
+func NewWithOptions(opts *DocumentOptions) VEX {
+	now := documentTime(opts.clock())
 	return VEX{
 		Metadata: Metadata{
 			Context:    ContextLocator(),
@@ -120,13 +146,41 @@ func New() VEX {
 	}
 }
 
+// EncodeOptions configure the serialization of documents.
+type EncodeOptions struct {
+	// GenerateID fills a missing document @id with the canonical ID computed
+	// by GenerateCanonicalID. The document being serialized is not modified.
+	GenerateID bool
+}
+
 // ToJSON serializes the VEX document to JSON and writes it to the passed writer.
 func (vexDoc *VEX) ToJSON(w io.Writer) error {
-	enc := json.NewEncoder(w)
-	enc.SetIndent("", "  ")
-	enc.SetEscapeHTML(false)
+	return vexDoc.ToJSONWithOptions(w, nil)
+}
+
+// ToJSONWithOptions works like ToJSON but serializes the document with the
+// passed options.
+func (vexDoc *VEX) ToJSONWithOptions(w io.Writer, opts *EncodeOptions) error {
+	if opts == nil {
+		opts = &EncodeOptions{}
+	}
 
-	if err := enc.Encode(vexDoc); err != nil {
+	doc := vexDoc
+	if opts.GenerateID && vexDoc.ID == "" {
+		// Computing the ID sorts the statements, hash a copy and serialize
+		// them in their original order
+		hashed := *vexDoc
+		hashed.Statements = slices.Clone(vexDoc.Statements)
+		id, err := hashed.GenerateCanonicalID()
+		if err != nil {
+			return fmt.Errorf("generating document ID: %w", err)
+		}
+		c := *vexDoc
+		c.ID = id
+		doc = &c
+	}
+
+	if err := encodeJSON(w, doc); err != nil {
 		return fmt.Errorf("encoding vex document: %w", err)
 	}
 	return nil
@@ -176,6 +230,16 @@ func (vexDoc *VEX) EffectiveStatement(product, vulnID string) (s *Statement) {
 	return nil
 }
 
+// StatementAuthor returns the author and role of a statement in the document.
+// If the statement does not define its author, it is attributed to the author
+// of the document.
+func (vexDoc *VEX) StatementAuthor(stmt *Statement) (author, role string) {
+	if stmt.Author != "" {
+		return stmt.Author, stmt.AuthorRole
+	}
+	return vexDoc.Author, vexDoc.AuthorRole
+}
+
 // StatementFromID returns a statement for a given vulnerability if there is one.
 //
 // Deprecated: vex.StatementFromID is deprecated and will be removed in an upcoming version
@@ -193,22 +257,7 @@ func (vexDoc *VEX) StatementFromID(id string) *Statement {
 // vulnerability. That is, the statement that contains the latest data with
 // impact data of a vulnerability on a given product.
 func (vexDoc *VEX) Matches(vulnID, product string, subcomponents []string) []Statement {
-	statements := vexDoc.Statements
-	var t time.Time
-	if vexDoc.Timestamp != nil {
-		t = *vexDoc.Timestamp
-	}
-
-	matches := []Statement{}
-
-	for i := len(statements) - 1; i >= 0; i-- {
-		if statements[i].Matches(vulnID, product, subcomponents) {
-			matches = append(matches, statements[i])
-		}
-	}
-
-	SortStatements(matches, t)
-	return matches
+	return vexDoc.MatchesWithResolver(vulnID, product, subcomponents, nil)
 }
 
 // CanonicalHash returns a hash representing the state of impact statements
@@ -216,6 +265,10 @@ func (vexDoc *VEX) Matches(vulnID, product string, subcomponents []string) []Sta
 // statements are not modified. Changes in extra information and metadata
 // will not alter the hash.
 func (vexDoc *VEX) CanonicalHash() (string, error) {
+	if vexDoc.Timestamp == nil {
+		return "", errors.New("document has no timestamp")
+	}
+
 	// Here's the algo:
 
 	// 1. Start with the document date. In unixtime to avoid format variance.
@@ -253,6 +306,9 @@ func (vexDoc *VEX) CanonicalHash() (string, error) {
 					prodString += cstringFromComponent(sc.Component)
 				}
 			}
+			for _, a := range p.Artifacts {
+				prodString += cstringFromComponent(a)
+			}
 			prods = append(prods, prodString)
 		}
 		sort.Strings(prods)
@@ -273,13 +329,20 @@ func (vexDoc *VEX) CanonicalHash() (string, error) {
 func cstringFromComponent(c Component) string {
 	s := fmt.Sprintf(":%s", c.ID)
 
+	// Maps are sorted to ensure the string is predictable
+	hashes := []string{}
 	for algo, val := range c.Hashes {
-		s += fmt.Sprintf(":%s@%s", algo, val)
+		hashes = append(hashes, fmt.Sprintf(":%s@%s", algo, val))
 	}
+	sort.Strings(hashes)
+	s += strings.Join(hashes, "")
 
+	identifiers := []string{}
 	for t, id := range c.Identifiers {
-		s += fmt.Sprintf(":%s@%s", t, id)
+		identifiers = append(identifiers, fmt.Sprintf(":%s@%s", t, id))
 	}
+	sort.Strings(identifiers)
+	s += strings.Join(identifiers, "")
 
 	return s
 }
@@ -351,66 +414,141 @@ func ContextLocator() string {
 // are made when matching:
 //
 //   - If purl1 does not have a version, it will match any version in purl2
+//   - If purl1 has a vers qualifier holding a version range, it matches any
+//     version of purl2 contained in the range (eg vers:apk/>=8.1.0|<8.2.0).
 //   - If purl1 has qualifers, purl2 must have the same set of qualifiers to match.
 //   - Inversely, purl2 can have any number of qualifiers not found on purl1 and
 //     still match.
 //   - If any of the purls is invalid, the function returns false.
 //
-// Purl version ranges are not supported yet but they will be in a future version
-// of this matching function.
+// Use PurlMatchesWithOptions to relax the matching of distro qualifiers in
+// OS packages.
 func PurlMatches(purl1, purl2 string) bool {
-	p1, err := packageurl.FromString(purl1)
-	if err != nil {
-		return false
-	}
-	p2, err := packageurl.FromString(purl2)
-	if err != nil {
-		return false
-	}
+	return PurlMatchesWithOptions(purl1, purl2, &PurlMatchOptions{})
+}
 
-	if p1.Type != p2.Type {
-		return false
+// StatementsByVulnerability returns a list of statements that apply to a
+// vulnerability ID. These are guaranteed to be ordered according to the VEX
+// history.
+func (vexDoc *VEX) StatementsByVulnerability(id string) []Statement {
+	ret := []Statement{}
+	for i := range vexDoc.Statements {
+		if vexDoc.Statements[i].Vulnerability.Matches(id) {
+			ret = append(ret, vexDoc.Statements[i])
+		}
 	}
+	SortStatements(ret, *vexDoc.Timestamp)
+	return ret
+}
 
-	if p1.Namespace != p2.Namespace {
-		return false
+// VulnerabilityHistory returns the statements about the vulnerability in the
+// product in chronological order, showing how its assessment evolved, for
+// example from under_investigation to affected to fixed. The returned
+// statements are copies with the timestamp inherited from the document when
+// they don't have one. Statements with the same timestamp keep their order
+// in the document.
+func (vexDoc *VEX) VulnerabilityHistory(vuln, product string) []Statement {
+	ret := []Statement{}
+	for i := range vexDoc.Statements {
+		if !vexDoc.Statements[i].Matches(vuln, product, nil) {
+			continue
+		}
+		stmt := vexDoc.Statements[i].DeepCopy()
+		if stmt.Timestamp == nil && vexDoc.Timestamp != nil {
+			ts := *vexDoc.Timestamp
+			stmt.Timestamp = &ts
+		}
+		ret = append(ret, *stmt)
 	}
 
-	if p1.Name != p2.Name {
-		return false
+	sort.SliceStable(ret, func(i, j int) bool {
+		if ret[i].Timestamp == nil || ret[j].Timestamp == nil {
+			return ret[i].Timestamp == nil && ret[j].Timestamp != nil
+		}
+		return ret[i].Timestamp.Before(*ret[j].Timestamp)
+	})
+	return ret
+}
+
+// StatementsByProduct returns a map indexing the document statements by the
+// identifiers of the products they reference. Statements are indexed under
+// the product IRI and all its software identifiers, including those of its
+// artifacts. Identifiers are reduced to the string of their CanonicalKey
+// (type:value) so that equivalent identifiers are grouped under the same
+// key; look up products with CanonicalKey(product).String(). The statements
+// in each list are sorted according to the VEX history.
+func (vexDoc *VEX) StatementsByProduct() map[string][]Statement {
+	var t time.Time
+	if vexDoc.Timestamp != nil {
+		t = *vexDoc.Timestamp
 	}
 
-	if p1.Version != "" && p2.Version == "" {
-		return false
+	ret := map[string][]Statement{}
+	for i := range vexDoc.Statements {
+		keys := map[string]struct{}{}
+		for j := range vexDoc.Statements[i].Products {
+			for _, c := range vexDoc.Statements[i].Products[j].components() {
+				if c.ID != "" {
+					keys[CanonicalKey(c.ID).String()] = struct{}{}
+				}
+				for _, id := range c.Identifiers {
+					keys[CanonicalKey(id).String()] = struct{}{}
+				}
+			}
+		}
+
+		for k := range keys {
+			ret[k] = append(ret[k], vexDoc.Statements[i])
+		}
 	}
 
-	if p1.Version != p2.Version && p1.Version != "" && p2.Version != "" {
-		return false
+	for k := range ret {
+		SortStatements(ret[k], t)
 	}
+	return ret
+}
 
-	p1q := p1.Qualifiers.Map()
-	p2q := p2.Qualifiers.Map()
+// HashMatch is a statement product that references an artifact digest.
+type HashMatch struct {
+	// Document is the document containing the statement
+	Document *VEX
 
-	// All qualifiers in p1 must be in p2 to match
-	for k, v1 := range p1q {
-		if v2, ok := p2q[k]; !ok || v1 != v2 {
-			return false
-		}
-	}
-	return true
+	// Statement is the statement referencing the digest
+	Statement *Statement
+
+	// Product is the product identified by the digest or the product of the
+	// subcomponent identified by it.
+	Product *Product
+
+	// Subcomponent is set when the digest identifies a subcomponent
+	Subcomponent *Subcomponent
 }
 
-// StatementsByVulnerability returns a list of statements that apply to a
-// vulnerability ID. These are guaranteed to be ordered according to the VEX
-// history.
-func (vexDoc *VEX) StatementsByVulnerability(id string) []Statement {
-	ret := []Statement{}
+// FindByHash returns the statement products and subcomponents identified
+// by an artifact digest. The digest is compared to the hashes of products,
+// their artifacts and subcomponents. Hex digests are compared case
+// insensitively.
+func (vexDoc *VEX) FindByHash(algo Algorithm, hash string) []HashMatch {
+	ret := []HashMatch{}
 	for i := range vexDoc.Statements {
-		if vexDoc.Statements[i].Vulnerability.Matches(id) {
-			ret = append(ret, vexDoc.Statements[i])
+		stmt := &vexDoc.Statements[i]
+		for j := range stmt.Products {
+			p := &stmt.Products[j]
+			for _, c := range p.components() {
+				if c.hasDigest(algo, hash) {
+					ret = append(ret, HashMatch{Document: vexDoc, Statement: stmt, Product: p})
+					break
+				}
+			}
+			for k := range p.Subcomponents {
+				if p.Subcomponents[k].hasDigest(algo, hash) {
+					ret = append(ret, HashMatch{
+						Document: vexDoc, Statement: stmt, Product: p, Subcomponent: &p.Subcomponents[k],
+					})
+				}
+			}
 		}
 	}
-	SortStatements(ret, *vexDoc.Timestamp)
 	return ret
 }
 
@@ -4,6 +4,7 @@
 package vex
 
 import (
+	"bytes"
 	"testing"
 	"time"
 
@@ -271,6 +272,75 @@ func TestGenerateCanonicalID(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, tc.expectedID, id)
 	}
+
+	// Documents without a timestamp cannot be hashed
+	doc := genTestDoc(t)
+	doc.Timestamp = nil
+	_, err := doc.GenerateCanonicalID()
+	require.Error(t, err)
+}
+
+func TestToJSONWithOptions(t *testing.T) {
+	for m, tc := range map[string]struct {
+		prepare    func(*VEX)
+		opts       *EncodeOptions
+		expectedID string
+		shouldErr  bool
+	}{
+		"no options": {
+			prepare:    func(_ *VEX) {},
+			expectedID: "",
+		},
+		"generate id": {
+			prepare:    func(_ *VEX) {},
+			opts:       &EncodeOptions{GenerateID: true},
+			expectedID: "https://openvex.dev/docs/public/vex-8ed99017785c3b43219018c7c50353c031cdaaf1c7efc146c683b0ce57123cf6",
+		},
+		"generate id, unsorted statements": {
+			prepare: func(v *VEX) {
+				stmt := v.Statements[0]
+				stmt.Vulnerability = Vulnerability{Name: "CVE-0000-0001"}
+				v.Statements = append(v.Statements, stmt)
+			},
+			opts:       &EncodeOptions{GenerateID: true},
+			expectedID: "https://openvex.dev/docs/public/vex-be0661023eda7255800d65499f0702ce0b3c7df8e31f7a0980748d05e45b188f",
+		},
+		"existing id": {
+			prepare:    func(v *VEX) { v.ID = "VEX-ID-THAT-ALREADY-EXISTED" },
+			opts:       &EncodeOptions{GenerateID: true},
+			expectedID: "VEX-ID-THAT-ALREADY-EXISTED",
+		},
+		"no timestamp": {
+			prepare:   func(v *VEX) { v.Timestamp = nil },
+			opts:      &EncodeOptions{GenerateID: true},
+			shouldErr: true,
+		},
+	} {
+		doc := genTestDoc(t)
+		tc.prepare(&doc)
+		originalID := doc.ID
+
+		var b bytes.Buffer
+		err := doc.ToJSONWithOptions(&b, tc.opts)
+		if tc.shouldErr {
+			require.Error(t, err, m)
+			continue
+		}
+		require.NoError(t, err, m)
+
+		parsed, err := Parse(b.Bytes())
+		require.NoError(t, err, m)
+		require.Equal(t, tc.expectedID, parsed.ID, m)
+
+		// The statements are serialized in their original order
+		require.Len(t, parsed.Statements, len(doc.Statements), m)
+		for i := range doc.Statements {
+			require.Equal(t, doc.Statements[i].Vulnerability.Name, parsed.Statements[i].Vulnerability.Name, m)
+		}
+
+		// The serialized document is not modified
+		require.Equal(t, originalID, doc.ID, m)
+	}
 }
 
 func TestPurlMatches(t *testing.T) {
@@ -442,3 +512,98 @@ func TestExtractStatements(t *testing.T) {
 		})
 	}
 }
+
+func TestStatementsByProduct(t *testing.T) {
+	date1 := time.Date(2023, 4, 17, 20, 34, 58, 0, time.UTC)
+	date2 := time.Date(2023, 4, 18, 20, 34, 58, 0, time.UTC)
+	doc := &VEX{
+		Metadata: Metadata{Timestamp: &date1},
+		Statements: []Statement{
+			{
+				Vulnerability: Vulnerability{Name: "CVE-2014-123456"},
+				Timestamp:     &date2,
+				Products: []Product{
+					{Component: Component{ID: "pkg:apk/wolfi/git@2.39.0-r1?arch=x86_64&distro=wolfi"}},
+				},
+				Status: StatusFixed,
+			},
+			{
+				Vulnerability: Vulnerability{Name: "CVE-2014-123456"},
+				Products: []Product{
+					{Component: Component{
+						ID:          "https://example.com/sbom.spdx.json#git",
+						Identifiers: map[IdentifierType]string{PURL: "pkg:apk/wolfi/git@2.39.0-r1?distro=wolfi&arch=x86_64"},
+					}},
+				},
+				Status: StatusUnderInvestigation,
+			},
+			{
+				Vulnerability: Vulnerability{Name: "CVE-2014-654321"},
+				Products: []Product{
+					{Component: Component{ID: "pkg:apk/wolfi/bash@5.2"}},
+				},
+				Status: StatusAffected,
+			},
+		},
+	}
+
+	byProduct := doc.StatementsByProduct()
+	require.Len(t, byProduct, 3)
+
+	git := byProduct[CanonicalKey("pkg:apk/wolfi/git@2.39.0-r1?distro=wolfi&arch=x86_64").String()]
+	require.Len(t, git, 2)
+	require.Equal(t, StatusUnderInvestigation, git[0].Status)
+	require.Equal(t, StatusFixed, git[1].Status)
+
+	require.Len(t, byProduct["iri:https://example.com/sbom.spdx.json#git"], 1)
+	require.Len(t, byProduct["purl:pkg:apk/wolfi/bash@5.2"], 1)
+}
+
+func TestVulnerabilityHistory(t *testing.T) {
+	docTime := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+	t1 := docTime.Add(-48 * time.Hour)
+	t2 := docTime.Add(-24 * time.Hour)
+	product := "pkg:apk/wolfi/curl@8.1.0-r0"
+	doc := &VEX{
+		Metadata: Metadata{Timestamp: &docTime},
+		Statements: []Statement{
+			{
+				Vulnerability:   Vulnerability{Name: "CVE-2023-0001"},
+				Products:        []Product{{Component: Component{ID: product}}},
+				Status:          StatusAffected,
+				ActionStatement: "Update",
+				Timestamp:       &t2,
+			},
+			{
+				Vulnerability: Vulnerability{Name: "CVE-2023-0001"},
+				Products:      []Product{{Component: Component{ID: product}}},
+				Status:        StatusFixed,
+			},
+			{
+				Vulnerability: Vulnerability{Name: "GHSA-aaaa-bbbb-cccc", Aliases: []VulnerabilityID{"CVE-2023-0001"}},
+				Products:      []Product{{Component: Component{ID: product}}},
+				Status:        StatusUnderInvestigation,
+				Timestamp:     &t1,
+			},
+			{
+				Vulnerability: Vulnerability{Name: "CVE-2023-0001"},
+				Products:      []Product{{Component: Component{ID: "pkg:apk/wolfi/git@2.41.0-r0"}}},
+				Status:        StatusNotAffected,
+				Justification: ComponentNotPresent,
+				Timestamp:     &t1,
+			},
+		},
+	}
+
+	history := doc.VulnerabilityHistory("CVE-2023-0001", product)
+	statuses := []Status{}
+	for i := range history {
+		statuses = append(statuses, history[i].Status)
+	}
+	require.Equal(t, []Status{StatusUnderInvestigation, StatusAffected, StatusFixed}, statuses)
+	require.Equal(t, docTime, *history[2].Timestamp)
+
+	// The document is not modified
+	require.Nil(t, doc.Statements[1].Timestamp)
+	require.Empty(t, doc.VulnerabilityHistory("CVE-2023-0002", product))
+}
@@ -20,6 +20,12 @@ type Vulnerability struct {
 	// Aliases is a list of other vulnerability identifier strings that
 	// locate the vulnerability in other tracking systems.
 	Aliases []VulnerabilityID `json:"aliases,omitempty"`
+
+	// Ratings lists the severity ratings of the vulnerability, such as its
+	// CVSS scores. Ratings are not part of the OpenVEX spec, they capture the
+	// scoring data of documents converted from other formats and are
+	// serialized as openvex.dev/ratings.
+	Ratings []Rating `json:"openvex.dev/ratings,omitempty"`
 }
 
 // VulnerabilityID is a string that captures a vulnerability identifier. It is
@@ -27,15 +33,33 @@ type Vulnerability struct {
 // tracking systems.
 type VulnerabilityID string
 
-// Matches returns true if the vulnerability's name or aliases matches the
-// identifier string.
+// Matches returns true if the vulnerability's ID, name or aliases matches the
+// identifier string. Identifiers are normalized to their local form before
+// comparing, so a local identifier matches its IRI and vice versa.
 func (v *Vulnerability) Matches(identifier string) bool {
-	// Identifier is an IRI, not te vuln ID. To match against the vuln.ID
-	// we should normalize the identifier to the full IRI (ie de-localize)
-	if v.ID == identifier {
+	if v.ID == identifier || string(v.Name) == identifier {
+		return true
+	}
+
+	local := VulnerabilityID(identifier).Local()
+	if v.ID != "" && VulnerabilityID(v.ID).Local() == local {
 		return true
 	}
-	if string(v.Name) == identifier {
+	if v.Name != "" && v.Name.Local() == local {
+		return true
+	}
+	for _, id := range v.Aliases {
+		if id.Local() == local {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesVerbatim returns true if the vulnerability's ID, name or aliases are
+// equal to the identifier, without normalizing them.
+func (v *Vulnerability) matchesVerbatim(identifier string) bool {
+	if v.ID == identifier || string(v.Name) == identifier {
 		return true
 	}
 	for _, id := range v.Aliases {
@@ -61,6 +85,14 @@ func (v *Vulnerability) DeepCopyInto(out *Vulnerability) {
 	}
 	out.ID = v.ID
 	out.Name = v.Name
-	out.Aliases = v.Aliases
+	out.Aliases = nil
+	if v.Aliases != nil {
+		out.Aliases = make([]VulnerabilityID, len(v.Aliases))
+		copy(out.Aliases, v.Aliases)
+	}
 	out.Description = v.Description
+	if v.Ratings != nil {
+		out.Ratings = make([]Rating, len(v.Ratings))
+		copy(out.Ratings, v.Ratings)
+	}
 }
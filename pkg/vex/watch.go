@@ -0,0 +1,218 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// TriageEvent reports a vulnerability affecting a product in the inventory
+// for which there is no VEX data yet.
+type TriageEvent struct {
+	// Product is the product in the inventory
+	Product *Product
+
+	// Vulnerability is the vulnerability as reported by the feed
+	Vulnerability VulnerabilityID
+
+	// Feed is the name of the feed reporting the vulnerability
+	Feed string
+
+	// Time is the time of the poll that found the vulnerability
+	Time time.Time
+}
+
+// WatchOptions configure a Watcher.
+type WatchOptions struct {
+	// Feeds are the vulnerability feeds polled by the watcher
+	Feeds []Feed
+
+	// Products is the inventory of products to triage. Feeds are queried
+	// with the ID, identifiers and hashes of each product.
+	Products []Product
+
+	// Documents returns the VEX documents used to check if vulnerabilities
+	// are covered. It is called on every poll so documents written as
+	// vulnerabilities get triaged are taken into account. When nil, all
+	// vulnerabilities reported by the feeds are triage work.
+	Documents func() []*VEX
+
+	// Interval is the time between polls. It defaults to one hour.
+	Interval time.Duration
+
+	// IgnoreExisting records the vulnerabilities found in the first poll
+	// without emitting events so that only vulnerabilities published after
+	// the watcher starts are reported.
+	IgnoreExisting bool
+
+	// Clock sets the time of the events. It defaults to the system clock.
+	Clock Clock
+}
+
+// DefaultWatchInterval is the time between polls when the options do not
+// set one.
+const DefaultWatchInterval = time.Hour
+
+// Watcher periodically polls vulnerability feeds for the products in an
+// inventory and emits a TriageEvent for each new vulnerability affecting a
+// product without VEX statements about it. Each vulnerability is reported
+// once per product.
+type Watcher struct {
+	Options WatchOptions
+
+	mutex    sync.Mutex
+	reported map[watchKey]struct{}
+	polls    int
+}
+
+// watchKey identifies a vulnerability reported for a product.
+type watchKey struct {
+	product int
+	vuln    VulnerabilityID
+}
+
+// NewWatcher returns a watcher configured with the options.
+func NewWatcher(opts *WatchOptions) *Watcher {
+	return &Watcher{
+		Options:  *opts,
+		reported: map[watchKey]struct{}{},
+	}
+}
+
+// Poll queries the feeds once and returns the events for the vulnerabilities
+// that were not reported in earlier polls and lack VEX coverage. Failing
+// feeds do not stop the poll, their errors are returned along with the
+// events found in the rest of the feeds.
+func (w *Watcher) Poll(ctx context.Context) ([]TriageEvent, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	var docs []*VEX
+	if w.Options.Documents != nil {
+		docs = w.Options.Documents()
+	}
+
+	now := clockNow(w.Options.Clock)
+	events := []TriageEvent{}
+	errs := []error{}
+
+	// Keys are only recorded as reported once the events are returned
+	found := map[watchKey]struct{}{}
+	for i := range w.Options.Products {
+		product := &w.Options.Products[i]
+		identifiers := productIdentifiers(product)
+		for _, feed := range w.Options.Feeds {
+			vulns, err := feed.Vulnerabilities(ctx, identifiers)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("querying %s feed: %w", feed.Name(), err))
+				continue
+			}
+			for _, vuln := range vulns {
+				key := watchKey{product: i, vuln: vuln}
+				if _, ok := w.reported[key]; ok || covered(docs, vuln, identifiers) {
+					continue
+				}
+				if _, ok := found[key]; ok {
+					continue
+				}
+				found[key] = struct{}{}
+				events = append(events, TriageEvent{
+					Product: product, Vulnerability: vuln, Feed: feed.Name(), Time: now,
+				})
+			}
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	for key := range found {
+		w.reported[key] = struct{}{}
+	}
+	w.polls++
+	if w.polls == 1 && w.Options.IgnoreExisting {
+		events = []TriageEvent{}
+	}
+	return events, errors.Join(errs...)
+}
+
+// Run polls the feeds at the configured interval, starting right away, and
+// calls fn with every event until the context is canceled. Feed errors are
+// logged and do not stop the watcher.
+func (w *Watcher) Run(ctx context.Context, fn func(TriageEvent)) error {
+	interval := w.Options.Interval
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		events, err := w.Poll(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			slog.Warn("polling vulnerability feeds", "error", err)
+		}
+		for _, e := range events {
+			fn(e)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Watch runs the watcher in the background and returns a channel with its
+// events. The channel is closed when the context is canceled.
+func (w *Watcher) Watch(ctx context.Context) <-chan TriageEvent {
+	ch := make(chan TriageEvent)
+	go func() {
+		defer close(ch)
+		w.Run(ctx, func(e TriageEvent) { //nolint:errcheck // Run only returns when the context is done
+			select {
+			case ch <- e:
+			case <-ctx.Done():
+			}
+		})
+	}()
+	return ch
+}
+
+// productIdentifiers returns the ID, identifiers and hashes of a product.
+func productIdentifiers(p *Product) []string {
+	ret := []string{}
+	if p.ID != "" {
+		ret = append(ret, p.ID)
+	}
+	for _, id := range p.Identifiers {
+		ret = append(ret, id)
+	}
+	for _, h := range p.Hashes {
+		ret = append(ret, string(h))
+	}
+	return ret
+}
+
+// covered returns true if any of the documents has a statement about the
+// vulnerability in the product known by any of the identifiers.
+func covered(docs []*VEX, vuln VulnerabilityID, identifiers []string) bool {
+	for _, doc := range docs {
+		for _, id := range identifiers {
+			if len(doc.Matches(string(vuln), id, nil)) > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,138 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testFeed reports the vulnerabilities listed for each identifier.
+type testFeed struct {
+	mutex sync.Mutex
+	vulns map[string][]VulnerabilityID
+	err   error
+}
+
+func (*testFeed) Name() string { return "test" }
+
+func (f *testFeed) Vulnerabilities(_ context.Context, identifiers []string) ([]VulnerabilityID, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	ret := []VulnerabilityID{}
+	for _, id := range identifiers {
+		ret = append(ret, f.vulns[id]...)
+	}
+	return ret, nil
+}
+
+func (f *testFeed) add(id string, vuln VulnerabilityID) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.vulns[id] = append(f.vulns[id], vuln)
+}
+
+// eventVulns returns the vulnerabilities of the events.
+func eventVulns(events []TriageEvent) []VulnerabilityID {
+	ret := []VulnerabilityID{}
+	for _, e := range events {
+		ret = append(ret, e.Vulnerability)
+	}
+	slices.Sort(ret)
+	return ret
+}
+
+func TestWatcherPoll(t *testing.T) {
+	feed := &testFeed{vulns: map[string][]VulnerabilityID{
+		"pkg:apk/wolfi/git@2.41.0-r1": {"CVE-2023-0001", "CVE-2023-0002"},
+		"pkg:apk/wolfi/bash@5.2":      {"CVE-2023-0003"},
+	}}
+	doc := New()
+	doc.Statements = []Statement{{
+		Vulnerability: Vulnerability{Name: "CVE-2023-0002"},
+		Products:      []Product{{Component: Component{ID: "pkg:apk/wolfi/git@2.41.0-r1"}}},
+		Status:        StatusNotAffected,
+		Justification: ComponentNotPresent,
+	}}
+
+	products := []Product{
+		{Component: Component{ID: "pkg:apk/wolfi/git@2.41.0-r1"}},
+		{Component: Component{ID: "bash", Identifiers: map[IdentifierType]string{PURL: "pkg:apk/wolfi/bash@5.2"}}},
+	}
+	pollTime := time.Date(2023, 1, 8, 18, 2, 3, 0, time.UTC)
+	w := NewWatcher(&WatchOptions{
+		Feeds: []Feed{feed}, Products: products, Documents: func() []*VEX { return []*VEX{&doc} },
+		Clock: FixedClock(pollTime),
+	})
+
+	events, err := w.Poll(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []VulnerabilityID{"CVE-2023-0001", "CVE-2023-0003"}, eventVulns(events))
+	require.Equal(t, "test", events[0].Feed)
+	require.Equal(t, pollTime, events[0].Time)
+
+	// Vulnerabilities are reported once
+	feed.add("pkg:apk/wolfi/git@2.41.0-r1", "CVE-2023-0004")
+	events, err = w.Poll(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []VulnerabilityID{"CVE-2023-0004"}, eventVulns(events))
+	require.Equal(t, "pkg:apk/wolfi/git@2.41.0-r1", events[0].Product.ID)
+
+	// Feed errors are returned
+	feed.err = errors.New("feed down")
+	_, err = w.Poll(context.Background())
+	require.ErrorIs(t, err, feed.err)
+	feed.err = nil
+
+	// Vulnerabilities found by canceled polls are reported by the next one
+	feed.add("pkg:apk/wolfi/git@2.41.0-r1", "CVE-2023-0006")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = w.Poll(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+	events, err = w.Poll(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []VulnerabilityID{"CVE-2023-0006"}, eventVulns(events))
+
+	// Existing vulnerabilities can be ignored
+	w = NewWatcher(&WatchOptions{Feeds: []Feed{feed}, Products: products, IgnoreExisting: true})
+	events, err = w.Poll(context.Background())
+	require.NoError(t, err)
+	require.Empty(t, events)
+	feed.add("pkg:apk/wolfi/bash@5.2", "CVE-2023-0005")
+	events, err = w.Poll(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []VulnerabilityID{"CVE-2023-0005"}, eventVulns(events))
+}
+
+func TestWatcherWatch(t *testing.T) {
+	feed := &testFeed{vulns: map[string][]VulnerabilityID{
+		"pkg:apk/wolfi/git@2.41.0-r1": {"CVE-2023-0001"},
+	}}
+	w := NewWatcher(&WatchOptions{
+		Feeds:    []Feed{feed},
+		Products: []Product{{Component: Component{ID: "pkg:apk/wolfi/git@2.41.0-r1"}}},
+		Interval: 10 * time.Millisecond,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := w.Watch(ctx)
+	require.Equal(t, VulnerabilityID("CVE-2023-0001"), (<-ch).Vulnerability)
+
+	feed.add("pkg:apk/wolfi/git@2.41.0-r1", "CVE-2023-0002")
+	require.Equal(t, VulnerabilityID("CVE-2023-0002"), (<-ch).Vulnerability)
+
+	cancel()
+	for range ch { //nolint:revive // Drain the channel until it is closed
+	}
+}
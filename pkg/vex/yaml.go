@@ -0,0 +1,97 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// YAML documents are transcoded to and from JSON so that they use the same
+// field names and value normalization as the JSON serialization of OpenVEX.
+
+// ParseYAML parses an OpenVEX document in YAML format. The YAML fields are
+// the same as the fields in the JSON serialization of the document.
+func ParseYAML(data []byte) (*VEX, error) {
+	var raw any
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("unmarshalling VEX data: %w", err)
+	}
+
+	jsonData, err := json.Marshal(jsonValue(raw))
+	if err != nil {
+		return nil, fmt.Errorf("transcoding YAML data: %w", err)
+	}
+
+	vexDoc := New()
+	if err := decodeJSON(jsonData, &vexDoc); err != nil {
+		return nil, fmt.Errorf("unmarshalling VEX data: %w", err)
+	}
+	return &vexDoc, nil
+}
+
+// ToYAML serializes the document as YAML and writes it to the passed writer.
+// Multi-line strings, such as long impact statements, are written as literal
+// blocks to keep them readable.
+func (vexDoc *VEX) ToYAML(w io.Writer) error {
+	var buf bytes.Buffer
+	if err := encodeJSON(&buf, vexDoc); err != nil {
+		return fmt.Errorf("encoding vex document: %w", err)
+	}
+
+	// Decoding the JSON into a node preserves the order of the fields
+	var node yaml.Node
+	if err := yaml.Unmarshal(buf.Bytes(), &node); err != nil {
+		return fmt.Errorf("transcoding vex document: %w", err)
+	}
+	resetYAMLStyle(&node)
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return fmt.Errorf("encoding vex document: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return fmt.Errorf("encoding vex document: %w", err)
+	}
+	return nil
+}
+
+// resetYAMLStyle clears the JSON flow and quoting styles from the node tree
+// to let the encoder choose the YAML block styles.
+func resetYAMLStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, n := range node.Content {
+		resetYAMLStyle(n)
+	}
+}
+
+// jsonValue converts the maps in a value decoded from YAML to maps with
+// string keys that can be marshaled to JSON.
+func jsonValue(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		for k, e := range val {
+			val[k] = jsonValue(e)
+		}
+		return val
+	case map[any]any:
+		m := make(map[string]any, len(val))
+		for k, e := range val {
+			m[fmt.Sprint(k)] = jsonValue(e)
+		}
+		return m
+	case []any:
+		for i, e := range val {
+			val[i] = jsonValue(e)
+		}
+		return val
+	default:
+		return v
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright 2023 The OpenVEX Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package vex
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseYAML(t *testing.T) {
+	doc, err := ParseYAML([]byte(`
+"@context": https://openvex.dev/ns/v0.2.0
+"@id": https://example.com/vex/1
+author: Wolfi J Inkinson
+timestamp: 2023-06-01T10:00:00Z
+version: 2
+statements:
+  - vulnerability:
+      name: CVE-2023-0001
+    products:
+      - "@id": pkg:apk/wolfi/git@2.41.0-r0
+        subcomponents:
+          - "@id": pkg:apk/wolfi/openssl@3.1.2-r0
+    status: not_affected
+    justification: vulnerable_code_not_present
+    impact_statement: |
+      The vulnerable function is not compiled in.
+      See the build flags of the package.
+    action_statement_timestamp: 2023-06-02T10:00:00Z
+`))
+	require.NoError(t, err)
+	require.Equal(t, "https://example.com/vex/1", doc.ID)
+	require.Equal(t, 2, doc.Version)
+	require.Equal(t, time.Date(2023, 6, 1, 10, 0, 0, 0, time.UTC), doc.Timestamp.UTC())
+	require.Len(t, doc.Statements, 1)
+	require.Equal(t, "pkg:apk/wolfi/openssl@3.1.2-r0", doc.Statements[0].Products[0].Subcomponents[0].ID)
+	require.Equal(t, "The vulnerable function is not compiled in.\nSee the build flags of the package.\n", doc.Statements[0].ImpactStatement)
+	require.NotNil(t, doc.Statements[0].ActionStatementTimestamp)
+
+	_, err = ParseYAML([]byte("statements: [\n"))
+	require.Error(t, err)
+}
+
+func TestToYAML(t *testing.T) {
+	ts := time.Date(2023, 6, 1, 10, 0, 0, 0, time.UTC)
+	doc := NewWithOptions(&DocumentOptions{Clock: FixedClock(ts)})
+	doc.ID = "https://example.com/vex/1"
+	doc.Author = "Wolfi J Inkinson"
+	doc.Statements = []Statement{
+		{
+			Vulnerability:   Vulnerability{Name: "CVE-2023-0001", Aliases: []VulnerabilityID{"GHSA-xxxx-xxxx-xxxx"}},
+			Products:        []Product{{Component: Component{ID: "pkg:apk/wolfi/git@2.41.0-r0"}}},
+			Status:          StatusNotAffected,
+			Justification:   VulnerableCodeNotPresent,
+			ImpactStatement: "The vulnerable function is not compiled in.\nSee the build flags of the package.",
+			StatusNotes:     "true",
+			Timestamp:       &ts,
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, doc.ToYAML(&buf))
+	require.Contains(t, buf.String(), "impact_statement: |-\n")
+	require.Contains(t, buf.String(), "'@id': https://example.com/vex/1\n")
+
+	// Round trip
+	parsed, err := ParseYAML(buf.Bytes())
+	require.NoError(t, err)
+	require.Equal(t, doc.ID, parsed.ID)
+	require.Equal(t, doc.Statements[0].ImpactStatement, parsed.Statements[0].ImpactStatement)
+	require.Equal(t, "true", parsed.Statements[0].StatusNotes)
+	require.Equal(t, doc.Statements[0].Vulnerability, parsed.Statements[0].Vulnerability)
+	require.Equal(t, ts, parsed.Statements[0].Timestamp.UTC())
+
+	var jsonDoc, yamlDoc bytes.Buffer
+	require.NoError(t, doc.ToJSON(&jsonDoc))
+	require.NoError(t, parsed.ToJSON(&yamlDoc))
+	require.JSONEq(t, jsonDoc.String(), yamlDoc.String())
+}